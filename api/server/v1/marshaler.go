@@ -155,6 +155,10 @@ func (x *ReadRequest) UnmarshalJSON(data []byte) error {
 		case "sort":
 			x.Sort = value
 			continue
+		case "lookup":
+			// not decoding it here and let it decode during lookup parsing
+			x.Lookup = value
+			continue
 		case "options":
 			v = &x.Options
 		default:
@@ -207,6 +211,10 @@ func (x *SearchRequest) UnmarshalJSON(data []byte) error {
 			// delaying the vector deserialization
 			x.Vector = value
 			continue
+		case "highlight":
+			// delaying the highlight deserialization
+			x.Highlight = value
+			continue
 		case "include_fields":
 			v = &x.IncludeFields
 		case "exclude_fields":
@@ -1212,9 +1220,10 @@ func (x *FacetStats) MarshalJSON() ([]byte, error) {
 }
 
 type SearchHitMetadata struct {
-	CreatedAt *time.Time `json:"created_at,omitempty"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty"`
-	Match     *Match     `json:"match,omitempty"`
+	CreatedAt  *time.Time   `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time   `json:"updated_at,omitempty"`
+	Match      *Match       `json:"match,omitempty"`
+	Highlights []*Highlight `json:"highlights,omitempty"`
 }
 
 type Metadata struct {
@@ -1259,6 +1268,7 @@ func CreateMDFromSearchMD(x *SearchHitMeta) *SearchHitMetadata {
 		md.UpdatedAt = &tm
 	}
 	md.Match = x.Match
+	md.Highlights = x.Highlights
 
 	return &md
 }
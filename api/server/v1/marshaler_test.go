@@ -77,6 +77,18 @@ func TestJSONEncoding(t *testing.T) {
 		require.NoError(t, err)
 		require.JSONEq(t, `{"hits":[],"facets":{"myField":{"counts":[{"count":32,"value":"adidas"}],"stats":{"avg":40,"count":50}}},"meta":{"found":1234, "matched_fields":null, "total_pages":0,"page":{"current":2,"size":10}}}`, string(r))
 	})
+
+	t.Run("marshal SearchFacet without stats", func(t *testing.T) {
+		facet := &SearchFacet{
+			Counts: []*FacetCount{{
+				Count: 5,
+				Value: "cheap",
+			}},
+		}
+		r, err := jsoniter.Marshal(facet)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"counts":[{"count":5,"value":"cheap"}],"stats":null}`, string(r))
+	})
 }
 
 func TestUsage_MarshalJSON(t *testing.T) {
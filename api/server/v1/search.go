@@ -364,6 +364,10 @@ func (x *SearchIndexRequest) UnmarshalJSON(data []byte) error {
 			// delaying the vector deserialization
 			x.Vector = value
 			continue
+		case "highlight":
+			// delaying the highlight deserialization
+			x.Highlight = value
+			continue
 		default:
 			continue
 		}
@@ -44,6 +44,12 @@ const (
 	BuildCollectionIndexMethodName = apiMethodPrefix + "BuildCollectionIndex"
 	ExplainMethodName              = apiMethodPrefix + "Explain"
 
+	VerifyCollectionSearchMethodName          = apiMethodPrefix + "VerifyCollectionSearch"
+	GetCollectionSearchVerificationMethodName = apiMethodPrefix + "GetCollectionSearchVerification"
+
+	QueryMethodName        = apiMethodPrefix + "Query"
+	ExplainQueryMethodName = apiMethodPrefix + "ExplainQuery"
+
 	SearchMethodName = apiMethodPrefix + "Search"
 	ImportMethodName = apiMethodPrefix + "Import"
 
@@ -93,14 +99,37 @@ const (
 	BillingGetUsageMethodName = billingMethodPrefix + "GetUsage"
 
 	// Cache.
-	CreateCacheMethodName = cacheMethodPrefix + "CreateCache"
-	ListCachesMethodName  = cacheMethodPrefix + "ListCaches"
-	DeleteCacheMethodName = cacheMethodPrefix + "DeleteCache"
-	SetMethodName         = cacheMethodPrefix + "Set"
-	GetSetMethodName      = cacheMethodPrefix + "GetSet"
-	GetMethodName         = cacheMethodPrefix + "Get"
-	DelMethodName         = cacheMethodPrefix + "Del"
-	KeysMethodName        = cacheMethodPrefix + "Keys"
+	CreateCacheMethodName   = cacheMethodPrefix + "CreateCache"
+	ListCachesMethodName    = cacheMethodPrefix + "ListCaches"
+	DeleteCacheMethodName   = cacheMethodPrefix + "DeleteCache"
+	SetMethodName           = cacheMethodPrefix + "Set"
+	GetSetMethodName        = cacheMethodPrefix + "GetSet"
+	GetMethodName           = cacheMethodPrefix + "Get"
+	DelMethodName           = cacheMethodPrefix + "Del"
+	KeysMethodName          = cacheMethodPrefix + "Keys"
+	HSetMethodName          = cacheMethodPrefix + "HSet"
+	HGetMethodName          = cacheMethodPrefix + "HGet"
+	HGetAllMethodName       = cacheMethodPrefix + "HGetAll"
+	HDelMethodName          = cacheMethodPrefix + "HDel"
+	LPushMethodName         = cacheMethodPrefix + "LPush"
+	RPushMethodName         = cacheMethodPrefix + "RPush"
+	LPopMethodName          = cacheMethodPrefix + "LPop"
+	RPopMethodName          = cacheMethodPrefix + "RPop"
+	LRangeMethodName        = cacheMethodPrefix + "LRange"
+	SAddMethodName          = cacheMethodPrefix + "SAdd"
+	SRemMethodName          = cacheMethodPrefix + "SRem"
+	SMembersMethodName      = cacheMethodPrefix + "SMembers"
+	SIsMemberMethodName     = cacheMethodPrefix + "SIsMember"
+	ZAddMethodName          = cacheMethodPrefix + "ZAdd"
+	ZRemMethodName          = cacheMethodPrefix + "ZRem"
+	ZRangeMethodName        = cacheMethodPrefix + "ZRange"
+	ZRangeByScoreMethodName = cacheMethodPrefix + "ZRangeByScore"
+	IncrMethodName          = cacheMethodPrefix + "Incr"
+	IncrByMethodName        = cacheMethodPrefix + "IncrBy"
+	DecrMethodName          = cacheMethodPrefix + "Decr"
+	ExpireMethodName        = cacheMethodPrefix + "Expire"
+	PersistMethodName       = cacheMethodPrefix + "Persist"
+	TTLMethodName           = cacheMethodPrefix + "TTL"
 
 	// Health.
 	HealthMethodName = "/HealthAPI/Health"
@@ -130,6 +159,20 @@ const (
 	ReadMessagesMethodName      = realtimeMethodPrefix + "ReadMessages"
 	MessagesMethodName          = realtimeMethodPrefix + "Messages"
 	ListSubscriptionsMethodName = realtimeMethodPrefix + "ListSubscriptions"
+	GetPresenceMethodName       = realtimeMethodPrefix + "GetPresence"
+
+	CreateOrUpdateChannelPolicyMethodName  = realtimeMethodPrefix + "CreateOrUpdateChannelPolicy"
+	ListChannelPoliciesMethodName          = realtimeMethodPrefix + "ListChannelPolicies"
+	DeleteChannelPolicyMethodName          = realtimeMethodPrefix + "DeleteChannelPolicy"
+	UpdateChannelRetentionMethodName       = realtimeMethodPrefix + "UpdateChannelRetention"
+	GetChannelRetentionMethodName          = realtimeMethodPrefix + "GetChannelRetention"
+	CreateOrUpdateChannelBridgeMethodName  = realtimeMethodPrefix + "CreateOrUpdateChannelBridge"
+	ListChannelBridgesMethodName           = realtimeMethodPrefix + "ListChannelBridges"
+	DeleteChannelBridgeMethodName          = realtimeMethodPrefix + "DeleteChannelBridge"
+	CreateOrUpdateChannelWebhookMethodName = realtimeMethodPrefix + "CreateOrUpdateChannelWebhook"
+	ListChannelWebhooksMethodName          = realtimeMethodPrefix + "ListChannelWebhooks"
+	DeleteChannelWebhookMethodName         = realtimeMethodPrefix + "DeleteChannelWebhook"
+	ListWebhookDeadLettersMethodName       = realtimeMethodPrefix + "ListWebhookDeadLetters"
 
 	// Search.
 	CreateOrUpdateIndexMethodName = searchMethodPrefix + "CreateOrUpdateIndex"
@@ -144,13 +187,22 @@ const (
 	SearchDelete                  = searchMethodPrefix + "Delete"
 	SearchDeleteByQuery           = searchMethodPrefix + "DeleteByQuery"
 	SearchSearch                  = searchMethodPrefix + "Search"
+
+	CreateOrUpdateSynonymMethodName  = searchMethodPrefix + "CreateOrUpdateSynonym"
+	ListSynonymsMethodName           = searchMethodPrefix + "ListSynonyms"
+	DeleteSynonymMethodName          = searchMethodPrefix + "DeleteSynonym"
+	CreateOrUpdateCurationMethodName = searchMethodPrefix + "CreateOrUpdateCuration"
+	ListCurationsMethodName          = searchMethodPrefix + "ListCurations"
+	DeleteCurationMethodName         = searchMethodPrefix + "DeleteCuration"
+	GetQueryAnalyticsMethodName      = searchMethodPrefix + "GetQueryAnalytics"
+	SuggestMethodName                = searchMethodPrefix + "Suggest"
 )
 
 func IsTxSupported(ctx context.Context) bool {
 	m, _ := grpc.Method(ctx)
 	switch m {
 	case InsertMethodName, ReplaceMethodName, UpdateMethodName, DeleteMethodName, ReadMethodName,
-		CommitTransactionMethodName, RollbackTransactionMethodName,
+		QueryMethodName, CommitTransactionMethodName, RollbackTransactionMethodName,
 		DropCollectionMethodName, ListCollectionsMethodName, CreateOrUpdateCollectionMethodName:
 		return true
 	default:
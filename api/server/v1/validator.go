@@ -16,8 +16,12 @@ package api
 
 import (
 	"regexp"
+	"strings"
 )
 
+// maxSuggestions is the maximum number of suggestions returned by a suggest request.
+const maxSuggestions = 100
+
 var validNamePattern = regexp.MustCompile("^[a-zA-Z_]+[a-zA-Z0-9_-]+$")
 
 type Validator interface {
@@ -162,6 +166,20 @@ func (*DescribeDatabaseRequest) Validate() error {
 	return nil
 }
 
+func (x *VerifyCollectionSearchRequest) Validate() error {
+	if err := isValidCollectionAndDatabase(x.Collection, x.Project); err != nil {
+		return err
+	}
+	if x.IntervalSeconds < 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'interval_seconds' should not be negative")
+	}
+	return nil
+}
+
+func (x *GetCollectionSearchVerificationRequest) Validate() error {
+	return isValidCollectionAndDatabase(x.Collection, x.Project)
+}
+
 func (x *CreateProjectRequest) Validate() error {
 	return isValidDatabase(x.Project)
 }
@@ -253,6 +271,106 @@ func (x *GetDocumentRequest) Validate() error {
 	return nil
 }
 
+func (x *CreateOrUpdateSynonymRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if x.Synonym == nil || len(x.Synonym.Id) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "synonym 'id' is a required field")
+	}
+	if len(x.Synonym.Synonyms) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'synonyms' is a required field")
+	}
+	if len(x.Synonym.Root) == 0 && len(x.Synonym.Synonyms) < 2 {
+		return Errorf(Code_INVALID_ARGUMENT, "multi-way synonym needs at least two 'synonyms'")
+	}
+	return nil
+}
+
+func (x *ListSynonymsRequest) Validate() error {
+	return isValidProjectAndSearchIndex(x.Project, x.Index)
+}
+
+func (x *DeleteSynonymRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if len(x.Id) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'id' is a required field")
+	}
+	return nil
+}
+
+func (x *CreateOrUpdateCurationRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if x.Curation == nil || len(x.Curation.Id) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "curation 'id' is a required field")
+	}
+	if len(x.Curation.Query) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'query' is a required field")
+	}
+	if x.Curation.Match != "exact" && x.Curation.Match != "contains" {
+		return Errorf(Code_INVALID_ARGUMENT, "'match' should be either 'exact' or 'contains'")
+	}
+	if len(x.Curation.Pinned) == 0 && len(x.Curation.Hidden) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "curation needs either 'pinned' or 'hidden' documents")
+	}
+	for _, p := range x.Curation.Pinned {
+		if p == nil || len(p.Id) == 0 || p.Position < 1 {
+			return Errorf(Code_INVALID_ARGUMENT, "pinned document needs an 'id' and a 'position' starting from 1")
+		}
+	}
+	return nil
+}
+
+func (x *ListCurationsRequest) Validate() error {
+	return isValidProjectAndSearchIndex(x.Project, x.Index)
+}
+
+func (x *DeleteCurationRequest) Validate() error {
+	if err := isValidProjectAndSearchIndex(x.Project, x.Index); err != nil {
+		return err
+	}
+
+	if len(x.Id) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'id' is a required field")
+	}
+	return nil
+}
+
+func (x *GetQueryAnalyticsRequest) Validate() error {
+	if err := isValidProjectAndIndexOrCollection(x.Project, x.Index, x.Collection); err != nil {
+		return err
+	}
+
+	if x.Type != "top" && x.Type != "zero_results" && x.Type != "slowest" {
+		return Errorf(Code_INVALID_ARGUMENT, "'type' should be one of 'top', 'zero_results' or 'slowest'")
+	}
+	if x.StartTime != nil && x.EndTime != nil && x.StartTime.AsTime().After(x.EndTime.AsTime()) {
+		return Errorf(Code_INVALID_ARGUMENT, "'start_time' should be before 'end_time'")
+	}
+	return isValidPaginationParam("limit", int(x.Limit))
+}
+
+func (x *SuggestRequest) Validate() error {
+	if err := isValidProjectAndIndexOrCollection(x.Project, x.Index, x.Collection); err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(x.Prefix)) == 0 {
+		return Errorf(Code_INVALID_ARGUMENT, "'prefix' is a required field")
+	}
+	if x.Size < 0 || x.Size > maxSuggestions {
+		return Errorf(Code_INVALID_ARGUMENT, "'size' should be between 0 and %d", maxSuggestions)
+	}
+	return nil
+}
+
 func isValidCollection(name string) error {
 	if len(name) == 0 {
 		return Errorf(Code_INVALID_ARGUMENT, "invalid collection name")
@@ -299,6 +417,23 @@ func isValidProjectAndSearchIndex(project string, index string) error {
 	return isValidSearchIndexName(index)
 }
 
+// isValidProjectAndIndexOrCollection validates the requests that run either on a search index or on the search index
+// of a collection.
+func isValidProjectAndIndexOrCollection(project string, index string, collection string) error {
+	if err := isValidDatabase(project); err != nil {
+		return err
+	}
+
+	switch {
+	case len(index) > 0 && len(collection) > 0:
+		return Errorf(Code_INVALID_ARGUMENT, "only one of 'index' or 'collection' can be set")
+	case len(collection) > 0:
+		return isValidCollection(collection)
+	default:
+		return isValidSearchIndexName(index)
+	}
+}
+
 func isValidPaginationParam(param string, value int) error {
 	if value < 0 {
 		return Errorf(Code_INVALID_ARGUMENT, "invalid value for `%s`", param)
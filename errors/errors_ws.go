@@ -44,6 +44,35 @@ func InternalWS(format string, args ...any) *api.ErrorEvent {
 	return Errorf(CloseInternalServerErr, format, args...)
 }
 
+func PolicyViolationWS(format string, args ...any) *api.ErrorEvent {
+	return Errorf(ClosePolicyViolation, format, args...)
+}
+
+// InvalidArgumentWS is returned when the request of the device is invalid.
+func InvalidArgumentWS(format string, args ...any) *api.ErrorEvent {
+	return Errorf(CloseInvalidFramePayloadData, format, args...)
+}
+
+// ToWSError converts the error to an error event, the code of a Tigris error is mapped to the closest close code.
+func ToWSError(err error) *api.ErrorEvent {
+	var tErr *api.TigrisError
+	if !As(err, &tErr) {
+		return InternalWS(err.Error())
+	}
+
+	code := CloseInternalServerErr
+	switch tErr.Code {
+	case api.Code_INVALID_ARGUMENT, api.Code_NOT_FOUND:
+		code = CloseInvalidFramePayloadData
+	case api.Code_PERMISSION_DENIED, api.Code_UNAUTHENTICATED:
+		code = ClosePolicyViolation
+	case api.Code_RESOURCE_EXHAUSTED, api.Code_UNAVAILABLE:
+		code = CloseTryAgainLater
+	}
+
+	return Errorf(code, "%s", tErr.Message)
+}
+
 func Errorf(c WSErrorCode, format string, a ...any) *api.ErrorEvent {
 	if c == CodeOK {
 		return nil
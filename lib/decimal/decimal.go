@@ -0,0 +1,294 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decimal
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MaxPrecision is the maximum number of significant digits, same as the IEEE 754 decimal128.
+const MaxPrecision = 34
+
+var (
+	ErrInvalidDecimal = fmt.Errorf("invalid decimal")
+	ErrDivisionByZero = fmt.Errorf("division by zero")
+
+	bigTen = big.NewInt(10)
+)
+
+// Decimal is an exact decimal number i.e. coef * 10^-scale.
+type Decimal struct {
+	coef  *big.Int
+	scale int32
+}
+
+func New(coef int64, scale int32) Decimal {
+	return Decimal{coef: big.NewInt(coef), scale: scale}
+}
+
+// Parse parses a decimal of the form [+-]digits[.digits][e[+-]digits].
+func Parse(s string) (Decimal, error) {
+	mantissa, exp := s, int64(0)
+	if idx := strings.IndexAny(s, "eE"); idx >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[idx+1:], 10, 32); err != nil {
+			return Decimal{}, ErrInvalidDecimal
+		}
+		mantissa = s[:idx]
+	}
+
+	digits := mantissa
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		digits = digits[1:]
+	}
+
+	intPart, fracPart := digits, ""
+	if idx := strings.IndexByte(digits, '.'); idx >= 0 {
+		intPart, fracPart = digits[:idx], digits[idx+1:]
+	}
+	if len(intPart)+len(fracPart) == 0 || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, ErrInvalidDecimal
+	}
+
+	coef, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Decimal{}, ErrInvalidDecimal
+	}
+	if mantissa[0] == '-' {
+		coef.Neg(coef)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale > 1<<20 || scale < -(1<<20) {
+		return Decimal{}, ErrInvalidDecimal
+	}
+
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (d Decimal) value() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+// Precision returns the number of digits of the coefficient.
+func (d Decimal) Precision() int {
+	if d.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(d.value()).String())
+}
+
+// IntegerDigits returns the number of digits before the decimal point.
+func (d Decimal) IntegerDigits() int {
+	digits := d.Precision() - int(d.scale)
+	if digits < 0 || d.Sign() == 0 {
+		return 0
+	}
+	return digits
+}
+
+// rescale returns the decimal with a larger scale, the value is unchanged.
+func (d Decimal) rescale(scale int32) Decimal {
+	if scale <= d.scale {
+		return d
+	}
+
+	factor := new(big.Int).Exp(bigTen, big.NewInt(int64(scale-d.scale)), nil)
+	return Decimal{coef: new(big.Int).Mul(d.value(), factor), scale: scale}
+}
+
+func align(a Decimal, b Decimal) (Decimal, Decimal) {
+	if a.scale < b.scale {
+		return a.rescale(b.scale), b
+	}
+	return a, b.rescale(a.scale)
+}
+
+// Cmp compares the decimals numerically, 1.50 is equal to 1.5.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.value().Cmp(b.value())
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{coef: new(big.Int).Add(a.value(), b.value()), scale: a.scale}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{coef: new(big.Int).Sub(a.value(), b.value()), scale: a.scale}
+}
+
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.value(), o.value()), scale: d.scale + o.scale}
+}
+
+// Quo divides the decimals and rounds the result to the scale using the banker's rounding i.e. half to even.
+func (d Decimal) Quo(o Decimal, scale int32) (Decimal, error) {
+	if o.Sign() == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+
+	// d / o = (d.coef * 10^(scale + o.scale - d.scale)) / o.coef * 10^-scale, one more digit is kept for rounding
+	num := new(big.Int).Set(d.value())
+	shift := int64(scale) + int64(o.scale) - int64(d.scale) + 1
+	if shift >= 0 {
+		num.Mul(num, new(big.Int).Exp(bigTen, big.NewInt(shift), nil))
+	}
+	den := new(big.Int).Set(o.value())
+	if shift < 0 {
+		den.Mul(den, new(big.Int).Exp(bigTen, big.NewInt(-shift), nil))
+	}
+
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	result := Decimal{coef: q, scale: scale + 1}
+	if r.Sign() != 0 {
+		// the remainder only matters when the extra digit is exactly 5, it means the result is above the half
+		result = Decimal{coef: q.Mul(q, bigTen).Add(q, big.NewInt(int64(r.Sign()))), scale: scale + 2}
+	}
+
+	return result.Round(scale), nil
+}
+
+// Round rounds the decimal to the scale using the banker's rounding i.e. half to even.
+func (d Decimal) Round(scale int32) Decimal {
+	if scale >= d.scale {
+		return d.rescale(scale)
+	}
+
+	factor := new(big.Int).Exp(bigTen, big.NewInt(int64(d.scale-scale)), nil)
+	q, r := new(big.Int).QuoRem(d.value(), factor, new(big.Int))
+
+	// compare twice the remainder with the factor to decide the rounding
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	c := twice.Cmp(factor)
+	if c > 0 || (c == 0 && q.Bit(0) == 1) {
+		if d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	return Decimal{coef: q, scale: scale}
+}
+
+// String returns the decimal with exactly Scale digits after the decimal point.
+func (d Decimal) String() string {
+	if d.scale <= 0 {
+		return d.rescale(0).value().String()
+	}
+
+	abs := new(big.Int).Abs(d.value()).String()
+	if len(abs) <= int(d.scale) {
+		abs = strings.Repeat("0", int(d.scale)-len(abs)+1) + abs
+	}
+
+	sign := ""
+	if d.Sign() < 0 {
+		sign = "-"
+	}
+
+	point := len(abs) - int(d.scale)
+	return sign + abs[:point] + "." + abs[point:]
+}
+
+// Float64 returns the nearest float, it is only meant for the places where an approximate value is acceptable.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// Normalize removes the trailing zeros of the fraction, so that the equal decimals have the same representation.
+func (d Decimal) Normalize() Decimal {
+	if d.Sign() == 0 {
+		return Decimal{coef: new(big.Int)}
+	}
+
+	coef, scale := new(big.Int).Set(d.value()), d.scale
+	r := new(big.Int)
+	for {
+		q, rem := new(big.Int).QuoRem(coef, bigTen, r)
+		if rem.Sign() != 0 {
+			break
+		}
+		coef, scale = q, scale-1
+	}
+
+	return Decimal{coef: coef, scale: scale}
+}
+
+// Encode returns the bytes that sort in the same order as the decimals. The value is written as the sign, the
+// exponent and the digits of 0.d1d2...dn * 10^exponent. For the negative values, the exponent and the digits are
+// inverted and terminated so that the larger magnitude sorts first.
+func (d Decimal) Encode() []byte {
+	const (
+		negative = 0x01
+		zero     = 0x02
+		positive = 0x03
+	)
+
+	n := d.Normalize()
+	if n.Sign() == 0 {
+		return []byte{zero}
+	}
+
+	digits := new(big.Int).Abs(n.value()).String()
+	// 0.d1d2...dn * 10^exponent
+	exponent := uint32(int64(len(digits)) - int64(n.scale) + 1<<31)
+
+	out := make([]byte, 0, 6+len(digits))
+	if n.Sign() > 0 {
+		out = append(out, positive, byte(exponent>>24), byte(exponent>>16), byte(exponent>>8), byte(exponent))
+		for i := 0; i < len(digits); i++ {
+			out = append(out, digits[i]-'0'+1)
+		}
+		return out
+	}
+
+	exponent = ^exponent
+	out = append(out, negative, byte(exponent>>24), byte(exponent>>16), byte(exponent>>8), byte(exponent))
+	for i := 0; i < len(digits); i++ {
+		out = append(out, 0xFE-(digits[i]-'0'))
+	}
+
+	return append(out, 0xFF)
+}
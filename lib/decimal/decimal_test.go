@@ -0,0 +1,115 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decimal
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParse(t *testing.T, s string) Decimal {
+	d, err := Parse(s)
+	assert.NoError(t, err, s)
+	return d
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		input     string
+		str       string
+		precision int
+		scale     int32
+	}{
+		{"0", "0", 1, 0},
+		{"12.50", "12.50", 4, 2},
+		{"-0.001", "-0.001", 1, 3},
+		{"+7", "7", 1, 0},
+		{".5", "0.5", 1, 1},
+		{"1.5e3", "1500", 2, -2},
+		{"25e-3", "0.025", 2, 3},
+		{"123456789012345678901234567890.1234", "123456789012345678901234567890.1234", 34, 4},
+	}
+	for _, c := range cases {
+		d := mustParse(t, c.input)
+		assert.Equal(t, c.str, d.String(), c.input)
+		assert.Equal(t, c.precision, d.Precision(), c.input)
+		assert.Equal(t, c.scale, d.Scale(), c.input)
+	}
+
+	for _, invalid := range []string{"", "-", ".", "1.2.3", "abc", "1e", "1,5", "0x10", "1e99999999"} {
+		_, err := Parse(invalid)
+		assert.Equal(t, ErrInvalidDecimal, err, invalid)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := mustParse(t, "0.1"), mustParse(t, "0.2")
+	assert.Equal(t, "0.3", a.Add(b).String())
+	assert.Equal(t, 0, a.Add(b).Cmp(mustParse(t, "0.30")))
+	assert.Equal(t, "-0.1", a.Sub(b).String())
+	assert.Equal(t, "0.02", a.Mul(b).String())
+	assert.Equal(t, 1, b.Cmp(a))
+	assert.Equal(t, -1, a.Cmp(b))
+
+	cases := []struct {
+		a, b   string
+		scale  int32
+		result string
+	}{
+		{"10", "3", 2, "3.33"},
+		{"20", "3", 2, "6.67"},
+		{"-20", "3", 2, "-6.67"},
+		{"1", "8", 2, "0.12"},
+		{"3", "8", 2, "0.38"},
+		{"1.0001", "8", 3, "0.125"},
+		{"-1", "8", 2, "-0.12"},
+		{"100", "0.25", 0, "400"},
+		{"1", "3", 0, "0"},
+	}
+	for _, c := range cases {
+		q, err := mustParse(t, c.a).Quo(mustParse(t, c.b), c.scale)
+		assert.NoError(t, err)
+		assert.Equal(t, c.result, q.String(), "%s / %s", c.a, c.b)
+	}
+
+	_, err := a.Quo(New(0, 0), 2)
+	assert.Equal(t, ErrDivisionByZero, err)
+
+	assert.Equal(t, "2.34", mustParse(t, "2.345").Round(2).String())
+	assert.Equal(t, "2.36", mustParse(t, "2.355").Round(2).String())
+	assert.Equal(t, "-2.36", mustParse(t, "-2.355").Round(2).String())
+	assert.Equal(t, "2.50", mustParse(t, "2.5").Round(2).String())
+}
+
+func TestEncodeOrder(t *testing.T) {
+	sorted := []string{
+		"-1000", "-99.99", "-1.5", "-1.25", "-1", "-0.5", "-0.001", "0",
+		"0.001", "0.5", "1", "1.25", "1.5", "99.99", "1000", "123456789012345678901234567890",
+	}
+
+	encoded := make([][]byte, len(sorted))
+	for i, s := range sorted {
+		encoded[i] = mustParse(t, s).Encode()
+	}
+	shuffled := append([][]byte{}, encoded...)
+	sort.Slice(shuffled, func(i, j int) bool { return bytes.Compare(shuffled[i], shuffled[j]) < 0 })
+	assert.Equal(t, encoded, shuffled)
+
+	assert.Equal(t, mustParse(t, "1.5").Encode(), mustParse(t, "1.500").Encode())
+	assert.Equal(t, mustParse(t, "0").Encode(), mustParse(t, "-0.00").Encode())
+}
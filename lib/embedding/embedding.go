@@ -0,0 +1,119 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// HashProviderName is the name under which the HashProvider is always registered.
+const HashProviderName = "hash"
+
+var (
+	ErrUnknownProvider    = fmt.Errorf("unknown embedding provider")
+	ErrInvalidDimensions  = fmt.Errorf("embedding dimensions need to be positive")
+	ErrDimensionsMismatch = fmt.Errorf("embedding provider returned a vector of unexpected dimensions")
+)
+
+// Provider computes the vector representation of texts.
+type Provider interface {
+	// Embed returns one vector of the requested dimensions for every text, in the same order as the texts.
+	Embed(ctx context.Context, texts []string, dimensions int) ([][]float64, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{
+		HashProviderName: HashProvider{},
+	}
+)
+
+// Register makes the provider available under the name, registering a name twice replaces the previous provider.
+func Register(name string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	providers[name] = provider
+}
+
+// Get returns the provider registered under the name.
+func Get(name string) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownProvider, name)
+	}
+
+	return p, nil
+}
+
+// HashProvider is a deterministic provider that doesn't need any model. Every word of the text is hashed to one of the
+// dimensions and the resulting vector is normalized, so texts sharing words are closer to each other. It is meant for
+// tests and local development.
+type HashProvider struct{}
+
+func (HashProvider) Embed(_ context.Context, texts []string, dimensions int) ([][]float64, error) {
+	if dimensions <= 0 {
+		return nil, ErrInvalidDimensions
+	}
+
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = hashText(text, dimensions)
+	}
+
+	return vectors, nil
+}
+
+func hashText(text string, dimensions int) []float64 {
+	vector := make([]float64, dimensions)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, w := range words {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(w))
+		sum := h.Sum64()
+
+		// the top bit decides the sign so that the collisions are cancelling out instead of adding up
+		sign := 1.0
+		if sum>>63 == 1 {
+			sign = -1.0
+		}
+		vector[(sum&math.MaxInt64)%uint64(dimensions)] += sign
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+
+	return vector
+}
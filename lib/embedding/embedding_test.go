@@ -0,0 +1,106 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func dot(a, b []float64) float64 {
+	var d float64
+	for i := range a {
+		d += a[i] * b[i]
+	}
+	return d
+}
+
+func TestHashProvider(t *testing.T) {
+	p, err := Get(HashProviderName)
+	assert.NoError(t, err)
+
+	texts := []string{"The quick brown fox", "the QUICK brown fox!", "a quick brown dog", "stock market report", ""}
+	vectors, err := p.Embed(context.Background(), texts, 64)
+	assert.NoError(t, err)
+	assert.Len(t, vectors, len(texts))
+
+	again, err := p.Embed(context.Background(), texts, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, vectors, again)
+
+	for _, v := range vectors[:4] {
+		assert.Len(t, v, 64)
+		assert.InDelta(t, 1.0, math.Sqrt(dot(v, v)), 1e-9)
+	}
+	assert.Equal(t, make([]float64, 64), vectors[4])
+
+	assert.InDelta(t, 1.0, dot(vectors[0], vectors[1]), 1e-9)
+	assert.Greater(t, dot(vectors[0], vectors[2]), dot(vectors[0], vectors[3]))
+
+	_, err = p.Embed(context.Background(), texts, 0)
+	assert.Equal(t, ErrInvalidDimensions, err)
+}
+
+func TestRegistry(t *testing.T) {
+	_, err := Get("missing")
+	assert.True(t, errors.Is(err, ErrUnknownProvider))
+
+	Register("test_registry", HashProvider{})
+	p, err := Get("test_registry")
+	assert.NoError(t, err)
+	assert.Equal(t, HashProvider{}, p)
+}
+
+func TestHTTPProvider(t *testing.T) {
+	var received httpRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, jsoniter.Unmarshal(body, &received))
+
+		if received.Input[0] == "fail" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte("slow down"))
+			return
+		}
+
+		// reply out of order to check that the index is respected
+		_, _ = w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewHTTPProvider(srv.URL, "secret", "test-model", 0)
+	vectors, err := p.Embed(context.Background(), []string{"a", "b"}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{{1, 0}, {0, 1}}, vectors)
+	assert.Equal(t, httpRequest{Model: "test-model", Input: []string{"a", "b"}, Dimensions: 2}, received)
+
+	_, err = p.Embed(context.Background(), []string{"a", "b"}, 3)
+	assert.Equal(t, ErrDimensionsMismatch, err)
+
+	_, err = p.Embed(context.Background(), []string{"a"}, 2)
+	assert.EqualError(t, err, "embedding provider returned 2 vectors for 1 texts")
+
+	_, err = p.Embed(context.Background(), []string{"fail", "b"}, 2)
+	assert.EqualError(t, err, "embedding provider returned status 429: slow down")
+}
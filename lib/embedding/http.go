@@ -0,0 +1,125 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// HTTPProvider calls an embedding service speaking the OpenAI compatible embeddings API. The request is
+//
+//	{"model": "<model>", "input": ["text", ...], "dimensions": <dimensions>}
+//
+// and the response is expected to have a "data" array with an "embedding" and an "index" for every input.
+type HTTPProvider struct {
+	URL     string
+	AuthKey string
+	Model   string
+	client  *http.Client
+}
+
+// NewHTTPProvider returns a provider calling the url, the auth key is sent as a bearer token if set.
+func NewHTTPProvider(url string, authKey string, model string, timeout time.Duration) *HTTPProvider {
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	return &HTTPProvider{
+		URL:     url,
+		AuthKey: authKey,
+		Model:   model,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+type httpRequest struct {
+	Model      string   `json:"model,omitempty"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions"`
+}
+
+type httpResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (p *HTTPProvider) Embed(ctx context.Context, texts []string, dimensions int) ([][]float64, error) {
+	if dimensions <= 0 {
+		return nil, ErrInvalidDimensions
+	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	body, err := jsoniter.Marshal(httpRequest{Model: p.Model, Input: texts, Dimensions: dimensions})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(p.AuthKey) > 0 {
+		req.Header.Set("Authorization", "Bearer "+p.AuthKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding provider returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var decoded httpResponse
+	if err = jsoniter.Unmarshal(respBody, &decoded); err != nil {
+		return nil, err
+	}
+	if len(decoded.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding provider returned %d vectors for %d texts", len(decoded.Data), len(texts))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range decoded.Data {
+		if d.Index < 0 || d.Index >= len(texts) || vectors[d.Index] != nil {
+			return nil, fmt.Errorf("embedding provider returned an invalid index %d", d.Index)
+		}
+		if len(d.Embedding) != dimensions {
+			return nil, ErrDimensionsMismatch
+		}
+		vectors[d.Index] = d.Embedding
+	}
+
+	return vectors, nil
+}
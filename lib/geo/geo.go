@@ -0,0 +1,280 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/buger/jsonparser"
+)
+
+const (
+	// EarthRadius is the mean radius of the earth in meters.
+	EarthRadius = 6371008.8
+
+	// bitsPerDimension is the precision of the encoded point, 26 bits for each of latitude and longitude is less
+	// than a meter at the equator.
+	bitsPerDimension = 26
+)
+
+var ErrInvalidPoint = fmt.Errorf("point needs to be an object with numeric 'lat' in [-90, 90] and 'lon' in [-180, 180]")
+
+// Point is a location on the earth, latitude and longitude are in degrees.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// ParsePoint parses a JSON object of the form {"lat": <latitude>, "lon": <longitude>}.
+func ParsePoint(data []byte) (Point, error) {
+	var (
+		p              Point
+		hasLat, hasLon bool
+	)
+
+	err := jsonparser.ObjectEach(data, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+		if dataType != jsonparser.Number {
+			return ErrInvalidPoint
+		}
+
+		v, err := jsonparser.ParseFloat(value)
+		if err != nil {
+			return ErrInvalidPoint
+		}
+
+		switch string(key) {
+		case "lat":
+			p.Lat, hasLat = v, true
+		case "lon":
+			p.Lon, hasLon = v, true
+		default:
+			return ErrInvalidPoint
+		}
+		return nil
+	})
+	if err != nil || !hasLat || !hasLon || !p.Valid() {
+		return Point{}, ErrInvalidPoint
+	}
+
+	return p, nil
+}
+
+// Valid returns true if the latitude and longitude are in range.
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// DistanceTo returns the great-circle distance in meters using the haversine formula.
+func (p Point) DistanceTo(o Point) float64 {
+	lat1, lat2 := toRadians(p.Lat), toRadians(o.Lat)
+	dLat, dLon := lat2-lat1, toRadians(o.Lon-p.Lon)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Rect is a bounding box. A box crossing the antimeridian has Min.Lon greater than Max.Lon.
+type Rect struct {
+	Min Point
+	Max Point
+}
+
+func (r Rect) Contains(p Point) bool {
+	if p.Lat < r.Min.Lat || p.Lat > r.Max.Lat {
+		return false
+	}
+	if r.Min.Lon <= r.Max.Lon {
+		return p.Lon >= r.Min.Lon && p.Lon <= r.Max.Lon
+	}
+
+	return p.Lon >= r.Min.Lon || p.Lon <= r.Max.Lon
+}
+
+// CircleBound returns the bounding box of the circle with the center and the radius in meters.
+func CircleBound(center Point, radius float64) Rect {
+	dLat := toDegrees(radius / EarthRadius)
+	minLat, maxLat := center.Lat-dLat, center.Lat+dLat
+	if minLat <= -90 || maxLat >= 90 {
+		// the circle contains a pole, so all the longitudes are covered
+		return Rect{
+			Min: Point{Lat: math.Max(minLat, -90), Lon: -180},
+			Max: Point{Lat: math.Min(maxLat, 90), Lon: 180},
+		}
+	}
+
+	dLon := toDegrees(math.Asin(math.Min(1, math.Sin(radius/EarthRadius)/math.Cos(toRadians(center.Lat)))))
+	if dLon >= 180 || radius/EarthRadius >= math.Pi/2 {
+		return Rect{Min: Point{Lat: minLat, Lon: -180}, Max: Point{Lat: maxLat, Lon: 180}}
+	}
+
+	minLon, maxLon := center.Lon-dLon, center.Lon+dLon
+	if minLon < -180 {
+		minLon += 360
+	}
+	if maxLon > 180 {
+		maxLon -= 360
+	}
+
+	return Rect{Min: Point{Lat: minLat, Lon: minLon}, Max: Point{Lat: maxLat, Lon: maxLon}}
+}
+
+// Polygon is a closed shape, the last vertex is connected to the first one. The edges are treated as straight lines
+// on the latitude/longitude plane, so a polygon can't cross the antimeridian.
+type Polygon []Point
+
+// Contains uses ray casting to check if the point is inside the polygon.
+func (poly Polygon) Contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(poly)-1; i < len(poly); j, i = i, i+1 {
+		a, b := poly[i], poly[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+func (poly Polygon) Bound() Rect {
+	r := Rect{Min: Point{Lat: 90, Lon: 180}, Max: Point{Lat: -90, Lon: -180}}
+	for _, p := range poly {
+		r.Min.Lat, r.Max.Lat = math.Min(r.Min.Lat, p.Lat), math.Max(r.Max.Lat, p.Lat)
+		r.Min.Lon, r.Max.Lon = math.Min(r.Min.Lon, p.Lon), math.Max(r.Max.Lon, p.Lon)
+	}
+
+	return r
+}
+
+// Encode interleaves the bits of the longitude and the latitude of the point, similar to geohash. Points that are
+// close to each other mostly share the prefix, which allows a bounding box to be scanned as a few ranges of the
+// encoded values.
+func Encode(p Point) int64 {
+	return int64(interleave(lonIndex(p.Lon), latIndex(p.Lat), bitsPerDimension))
+}
+
+// Range is a range of the encoded values, Start is inclusive and End is exclusive.
+type Range struct {
+	Start int64
+	End   int64
+}
+
+// Cover returns the ranges of the encoded values covering the bounding box. The cells of the finest level that needs
+// no more than maxCells cells are used, the adjacent cells are merged into a single range. The ranges may cover
+// points outside the box, so the points still need to be checked against the exact shape.
+func Cover(r Rect, maxCells int) []Range {
+	if r.Min.Lon > r.Max.Lon {
+		// crossing the antimeridian, cover both the sides separately
+		west := Rect{Min: r.Min, Max: Point{Lat: r.Max.Lat, Lon: 180}}
+		east := Rect{Min: Point{Lat: r.Min.Lat, Lon: -180}, Max: r.Max}
+		return merge(append(cover(west, maxCells/2), cover(east, maxCells-maxCells/2)...))
+	}
+
+	return merge(cover(r, maxCells))
+}
+
+func cover(r Rect, maxCells int) []Range {
+	minLon, maxLon := lonIndex(r.Min.Lon), lonIndex(r.Max.Lon)
+	minLat, maxLat := latIndex(r.Min.Lat), latIndex(r.Max.Lat)
+
+	level := bitsPerDimension
+	for ; level > 0; level-- {
+		shift := uint(bitsPerDimension - level)
+		cells := uint64((maxLon>>shift)-(minLon>>shift)+1) * uint64((maxLat>>shift)-(minLat>>shift)+1)
+		if cells <= uint64(maxCells) {
+			break
+		}
+	}
+
+	shift := uint(bitsPerDimension - level)
+	width := uint(2 * (bitsPerDimension - level))
+
+	var ranges []Range
+	for lon := minLon >> shift; lon <= maxLon>>shift; lon++ {
+		for lat := minLat >> shift; lat <= maxLat>>shift; lat++ {
+			prefix := interleave(lon, lat, level)
+			ranges = append(ranges, Range{Start: int64(prefix << width), End: int64((prefix + 1) << width)})
+		}
+	}
+
+	return ranges
+}
+
+func merge(ranges []Range) []Range {
+	if len(ranges) == 0 {
+		return ranges
+	}
+
+	for i := 1; i < len(ranges); i++ {
+		for j := i; j > 0 && ranges[j].Start < ranges[j-1].Start; j-- {
+			ranges[j], ranges[j-1] = ranges[j-1], ranges[j]
+		}
+	}
+
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			if r.End > last.End {
+				last.End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+func interleave(lon uint32, lat uint32, bits int) uint64 {
+	var result uint64
+	for i := bits - 1; i >= 0; i-- {
+		result = result<<1 | uint64(lon>>uint(i)&1)
+		result = result<<1 | uint64(lat>>uint(i)&1)
+	}
+
+	return result
+}
+
+func lonIndex(lon float64) uint32 {
+	return toIndex((lon + 180) / 360)
+}
+
+func latIndex(lat float64) uint32 {
+	return toIndex((lat + 90) / 180)
+}
+
+func toIndex(fraction float64) uint32 {
+	const maxIndex = 1<<bitsPerDimension - 1
+
+	idx := math.Floor(fraction * (1 << bitsPerDimension))
+	switch {
+	case idx < 0:
+		return 0
+	case idx > maxIndex:
+		return maxIndex
+	default:
+		return uint32(idx)
+	}
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func toDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
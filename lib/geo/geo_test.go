@@ -0,0 +1,110 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	sf     = Point{Lat: 37.7749, Lon: -122.4194}
+	oak    = Point{Lat: 37.8044, Lon: -122.2712}
+	la     = Point{Lat: 34.0522, Lon: -118.2437}
+	fiji   = Point{Lat: -17.7134, Lon: 178.0650}
+	samoa  = Point{Lat: -13.7590, Lon: -172.1046}
+	sydney = Point{Lat: -33.8688, Lon: 151.2093}
+)
+
+func covered(ranges []Range, p Point) bool {
+	h := Encode(p)
+	for _, r := range ranges {
+		if h >= r.Start && h < r.End {
+			return true
+		}
+	}
+	return false
+}
+
+func TestParsePoint(t *testing.T) {
+	p, err := ParsePoint([]byte(`{"lat": 37.7749, "lon": -122.4194}`))
+	assert.NoError(t, err)
+	assert.Equal(t, sf, p)
+
+	for _, invalid := range []string{
+		`{"lat": 37.7749}`,
+		`{"lat": 91, "lon": 0}`,
+		`{"lat": 0, "lon": -181}`,
+		`{"lat": "1", "lon": 0}`,
+		`{"lat": 1, "lon": 0, "alt": 10}`,
+		`[1, 2]`,
+	} {
+		_, err = ParsePoint([]byte(invalid))
+		assert.Equal(t, ErrInvalidPoint, err, invalid)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	assert.InDelta(t, 13_400, sf.DistanceTo(oak), 200)
+	assert.InDelta(t, 559_000, sf.DistanceTo(la), 2_000)
+	assert.InDelta(t, 0, sf.DistanceTo(sf), 0.001)
+}
+
+func TestShapes(t *testing.T) {
+	bayArea := Rect{Min: Point{Lat: 37, Lon: -123}, Max: Point{Lat: 38.5, Lon: -121.5}}
+	assert.True(t, bayArea.Contains(sf))
+	assert.True(t, bayArea.Contains(oak))
+	assert.False(t, bayArea.Contains(la))
+
+	pacific := Rect{Min: Point{Lat: -20, Lon: 170}, Max: Point{Lat: -10, Lon: -170}}
+	assert.True(t, pacific.Contains(fiji))
+	assert.True(t, pacific.Contains(samoa))
+	assert.False(t, pacific.Contains(sydney))
+
+	triangle := Polygon{{Lat: 33, Lon: -124}, {Lat: 39, Lon: -124}, {Lat: 39, Lon: -118}}
+	assert.True(t, triangle.Contains(sf))
+	assert.False(t, triangle.Contains(la))
+	assert.Equal(t, Rect{Min: Point{Lat: 33, Lon: -124}, Max: Point{Lat: 39, Lon: -118}}, triangle.Bound())
+
+	bound := CircleBound(sf, 20_000)
+	assert.True(t, bound.Contains(oak))
+	assert.False(t, bound.Contains(la))
+
+	bound = CircleBound(fiji, 1_500_000)
+	assert.Greater(t, bound.Min.Lon, bound.Max.Lon)
+	assert.True(t, bound.Contains(samoa))
+}
+
+func TestCover(t *testing.T) {
+	bayArea := Rect{Min: Point{Lat: 37, Lon: -123}, Max: Point{Lat: 38.5, Lon: -121.5}}
+	ranges := Cover(bayArea, 32)
+	assert.LessOrEqual(t, len(ranges), 32)
+	assert.True(t, covered(ranges, sf))
+	assert.True(t, covered(ranges, oak))
+	assert.False(t, covered(ranges, la))
+	for i := 1; i < len(ranges); i++ {
+		assert.Greater(t, ranges[i].Start, ranges[i-1].End)
+	}
+
+	pacific := Rect{Min: Point{Lat: -20, Lon: 170}, Max: Point{Lat: -10, Lon: -170}}
+	ranges = Cover(pacific, 32)
+	assert.True(t, covered(ranges, fiji))
+	assert.True(t, covered(ranges, samoa))
+	assert.False(t, covered(ranges, sydney))
+
+	world := Rect{Min: Point{Lat: -90, Lon: -180}, Max: Point{Lat: 90, Lon: 180}}
+	assert.Equal(t, []Range{{Start: 0, End: 1 << 52}}, Cover(world, 32))
+}
@@ -27,6 +27,7 @@ import (
 
 const (
 	EQ       = "$eq"
+	NE       = "$ne"
 	GT       = "$gt"
 	LT       = "$lt"
 	GTE      = "$gte"
@@ -69,6 +70,10 @@ func NewMatcher(key string, v value.Value) (ValueMatcher, error) {
 		return &EqualityMatcher{
 			Value: v,
 		}, nil
+	case NE:
+		return &NotEqualMatcher{
+			Value: v,
+		}, nil
 	case GT:
 		return &GreaterThanMatcher{
 			Value: v,
@@ -153,6 +158,46 @@ func (e *EqualityMatcher) String() string {
 	return fmt.Sprintf("{$eq:%v}", e.Value)
 }
 
+// NotEqualMatcher implements "$ne" operand.
+type NotEqualMatcher struct {
+	Value value.Value
+}
+
+func (n *NotEqualMatcher) GetValue() value.Value {
+	return n.Value
+}
+
+func (n *NotEqualMatcher) Matches(input value.Value) bool {
+	res, _ := input.CompareTo(n.Value)
+	return res != 0
+}
+
+// ArrMatches returns true for "NotEqualMatcher" if none of the array elements is equal to "v".
+func (n *NotEqualMatcher) ArrMatches(arr []any) bool {
+	for _, element := range arr {
+		if nestedArr, ok := element.([]any); ok {
+			// array of array
+			for _, ne := range nestedArr {
+				if value.AnyCompare(ne, n.Value) == 0 {
+					return false
+				}
+			}
+		} else if value.AnyCompare(element, n.Value) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (*NotEqualMatcher) Type() string {
+	return "$ne"
+}
+
+func (n *NotEqualMatcher) String() string {
+	return fmt.Sprintf("{$ne:%v}", n.Value)
+}
+
 // GreaterThanMatcher implements "$gt" operand.
 type GreaterThanMatcher struct {
 	Value value.Value
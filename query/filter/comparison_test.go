@@ -84,6 +84,14 @@ func TestArrMatcher(t *testing.T) {
 			[]any{"orange", "apple"},
 			mustMatcher(EQ, value.NewStringValue("apple1", nil)),
 			false,
+		}, {
+			[]any{"orange", "apple"},
+			mustMatcher(NE, value.NewStringValue("apple", nil)),
+			false,
+		}, {
+			[]any{"orange", "apple"},
+			mustMatcher(NE, value.NewStringValue("apple1", nil)),
+			true,
 		},
 	}
 	for _, c := range cases {
@@ -16,12 +16,14 @@ package filter
 
 import (
 	"bytes"
+	"sort"
 	"strings"
 
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/container"
 	"github.com/tigrisdata/tigris/query/expression"
 	"github.com/tigrisdata/tigris/schema"
 	ulog "github.com/tigrisdata/tigris/util/log"
@@ -111,6 +113,142 @@ func None(reqFilter []byte) bool {
 	return len(reqFilter) == 0 || bytes.Equal(reqFilter, filterNone)
 }
 
+// WithoutField returns the filter without the conditions on the field that are and-ed with the rest of the filter i.e.
+// the selectors on the field, recursively in the $and items, and the $or having only conditions on the field.
+func WithoutField(reqFilter []byte, field string) ([]byte, error) {
+	if None(reqFilter) {
+		return reqFilter, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	err := jsonparser.ObjectEach(reqFilter, func(k []byte, v []byte, jsonDataType jsonparser.ValueType, offset int) error {
+		switch string(k) {
+		case field:
+			return nil
+		case string(OrOP):
+			if itemsOnlyField(v, field) {
+				return nil
+			}
+		case string(AndOP):
+			var items [][]byte
+			var err error
+			_, arrErr := jsonparser.ArrayEach(v, func(item []byte, _ jsonparser.ValueType, _ int, _ error) {
+				if err != nil {
+					return
+				}
+
+				var without []byte
+				if without, err = WithoutField(item, field); err == nil && !None(without) {
+					items = append(items, without)
+				}
+			})
+			if err != nil {
+				return err
+			}
+			if arrErr != nil {
+				return arrErr
+			}
+			if len(items) == 0 {
+				return nil
+			}
+			v = append(append([]byte{'['}, bytes.Join(items, []byte{','})...), ']')
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('"')
+		buf.Write(k)
+		buf.WriteString(`":`)
+		if jsonDataType == jsonparser.String {
+			// the quotes are stripped from the string values
+			buf.WriteByte('"')
+			buf.Write(v)
+			buf.WriteByte('"')
+		} else {
+			buf.Write(v)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// Fields returns the sorted names of the fields having conditions in the filter, the logical operators are walked
+// recursively.
+func Fields(reqFilter []byte) ([]string, error) {
+	if None(reqFilter) {
+		return nil, nil
+	}
+
+	fields := container.NewHashSet()
+	if err := collectFields(reqFilter, &fields); err != nil {
+		return nil, err
+	}
+
+	names := fields.ToList()
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func collectFields(reqFilter []byte, fields *container.HashSet) error {
+	return jsonparser.ObjectEach(reqFilter, func(k []byte, v []byte, _ jsonparser.ValueType, _ int) error {
+		switch string(k) {
+		case string(AndOP), string(OrOP):
+			var err error
+			_, arrErr := jsonparser.ArrayEach(v, func(item []byte, _ jsonparser.ValueType, _ int, _ error) {
+				if err == nil {
+					err = collectFields(item, fields)
+				}
+			})
+			if err != nil {
+				return err
+			}
+			return arrErr
+		default:
+			fields.Insert(string(k))
+		}
+
+		return nil
+	})
+}
+
+// onlyField returns true if all the conditions of the filter are on the field.
+func onlyField(reqFilter []byte, field string) bool {
+	only, empty := true, true
+	err := jsonparser.ObjectEach(reqFilter, func(k []byte, v []byte, _ jsonparser.ValueType, _ int) error {
+		empty = false
+		switch string(k) {
+		case field:
+		case string(AndOP), string(OrOP):
+			only = only && itemsOnlyField(v, field)
+		default:
+			only = false
+		}
+
+		return nil
+	})
+
+	return err == nil && !empty && only
+}
+
+// itemsOnlyField returns true if all the conditions of the items of a logical operator are on the field.
+func itemsOnlyField(items []byte, field string) bool {
+	only := true
+	_, err := jsonparser.ArrayEach(items, func(item []byte, _ jsonparser.ValueType, _ int, _ error) {
+		only = only && onlyField(item, field)
+	})
+
+	return err == nil && only
+}
+
 type Factory struct {
 	fields    []*schema.QueryableField
 	collation *value.Collation
@@ -315,6 +453,10 @@ func (factory *Factory) ParseSelector(k []byte, v []byte, dataType jsonparser.Va
 
 		return NewSelector(parent, field, NewEqualityMatcher(val), factory.collation), nil
 	case jsonparser.Object:
+		if field.DataType == schema.GeoPointType {
+			return NewGeoFilter(field, v)
+		}
+
 		valueMatcher, likeMatcher, collation, err := buildValueMatcher(v, field, factory.collation, factory.buildForSecondaryIndex)
 		if err != nil {
 			return nil, err
@@ -357,7 +499,7 @@ func buildValueMatcher(input jsoniter.RawMessage, field *schema.QueryableField,
 		}
 
 		switch string(key) {
-		case EQ, GT, GTE, LT, LTE:
+		case EQ, NE, GT, GTE, LT, LTE:
 			switch dataType {
 			case jsonparser.Boolean, jsonparser.Number, jsonparser.String, jsonparser.Null, jsonparser.Array:
 				tigrisType := toTigrisType(field, dataType)
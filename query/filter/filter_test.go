@@ -183,3 +183,48 @@ func TestFiltersWithCollation(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, filters)
 }
+
+func TestWithoutField(t *testing.T) {
+	cases := []struct {
+		filter   string
+		expected string
+	}{
+		{``, ``},
+		{`{}`, `{}`},
+		{`{"brand": "nike"}`, `{}`},
+		{`{"brand": "nike", "price": {"$gt": 10}}`, `{"price":{"$gt": 10}}`},
+		{`{"$or": [{"brand": "nike"}, {"brand": "adidas"}], "price": 10}`, `{"price":10}`},
+		{`{"$or": [{"brand": "nike"}, {"price": 10}]}`, `{"$or":[{"brand": "nike"}, {"price": 10}]}`},
+		{`{"$and": [{"brand": "nike"}, {"price": 10, "brand": "adidas"}, {"$or": [{"brand": "a"}, {"brand": "b"}]}]}`, `{"$and":[{"price":10}]}`},
+		{`{"$and": [{"brand": {"$eq": "nike"}}], "color": "red"}`, `{"color":"red"}`},
+	}
+	for _, c := range cases {
+		actual, err := WithoutField([]byte(c.filter), "brand")
+		require.NoError(t, err)
+		require.Equal(t, c.expected, string(actual), c.filter)
+	}
+
+	_, err := WithoutField([]byte(`{"brand": `), "brand")
+	require.Error(t, err)
+}
+
+func TestFields(t *testing.T) {
+	cases := []struct {
+		filter   string
+		expected []string
+	}{
+		{``, nil},
+		{`{}`, nil},
+		{`{"brand": "nike", "price": {"$gt": 10}}`, []string{"brand", "price"}},
+		{`{"$or": [{"brand": "nike"}, {"color": "red"}], "price": 10}`, []string{"brand", "color", "price"}},
+		{`{"$and": [{"brand": "nike"}, {"$or": [{"a.b": 1}, {"brand": "adidas"}]}]}`, []string{"a.b", "brand"}},
+	}
+	for _, c := range cases {
+		actual, err := Fields([]byte(c.filter))
+		require.NoError(t, err)
+		require.Equal(t, c.expected, actual, c.filter)
+	}
+
+	_, err := Fields([]byte(`{"$or": [{"brand": `))
+	require.Error(t, err)
+}
@@ -0,0 +1,288 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/geo"
+	"github.com/tigrisdata/tigris/schema"
+	ulog "github.com/tigrisdata/tigris/util/log"
+)
+
+const (
+	NEAR   = "$near"
+	WITHIN = "$within"
+)
+
+// maxCoverCells is the maximum number of geohash cells used to cover the area of a geo filter when the secondary
+// index is used, adjacent cells are merged so the number of ranges scanned is usually lower.
+const maxCoverCells = 32
+
+// GeoFilter matches the documents with a geopoint inside an area. The area is either a circle i.e.
+//
+//	{"location": {"$near": {"lat": 37.77, "lon": -122.41, "radius": 1000}}}
+//
+// where radius is in meters, or a box or a polygon i.e.
+//
+//	{"location": {"$within": {"box": [{"lat": 37.7, "lon": -122.5}, {"lat": 37.8, "lon": -122.3}]}}}
+//	{"location": {"$within": {"polygon": [{"lat": 37.7, "lon": -122.5}, {"lat": 37.8, "lon": -122.5}, ...]}}}
+//
+// The box is defined by the south-west and the north-east corners.
+type GeoFilter struct {
+	Field *schema.QueryableField
+	Op    string
+
+	Center  geo.Point
+	Radius  float64
+	Box     *geo.Rect
+	Polygon geo.Polygon
+}
+
+// NewGeoFilter parses the value of the geo operator applied on a geopoint field.
+func NewGeoFilter(field *schema.QueryableField, input jsoniter.RawMessage) (*GeoFilter, error) {
+	f := &GeoFilter{Field: field}
+
+	var err error
+	count := 0
+	parsingErr := jsonparser.ObjectEach(input, func(key []byte, v []byte, dataType jsonparser.ValueType, _ int) error {
+		count++
+		switch f.Op = string(key); {
+		case f.Op != NEAR && f.Op != WITHIN:
+			err = errors.InvalidArgument("only '%s' and '%s' are supported on geopoint field '%s'", NEAR, WITHIN, field.Name())
+		case dataType != jsonparser.Object:
+			err = errors.InvalidArgument("'%s' needs to be an object", f.Op)
+		case f.Op == NEAR:
+			err = f.parseNear(v)
+		default:
+			err = f.parseWithin(v)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if parsingErr != nil {
+		return nil, errors.InvalidArgument("unable to parse the geo filter on field '%s'", field.Name())
+	}
+	if count != 1 {
+		return nil, errors.InvalidArgument("geo filter on field '%s' needs exactly one of '%s' or '%s'", field.Name(), NEAR, WITHIN)
+	}
+
+	return f, nil
+}
+
+func (f *GeoFilter) parseNear(input []byte) error {
+	var err error
+	if f.Center.Lat, err = jsonparser.GetFloat(input, "lat"); err != nil {
+		return errors.InvalidArgument("'%s' needs numeric 'lat'", NEAR)
+	}
+	if f.Center.Lon, err = jsonparser.GetFloat(input, "lon"); err != nil {
+		return errors.InvalidArgument("'%s' needs numeric 'lon'", NEAR)
+	}
+	if !f.Center.Valid() {
+		return errors.InvalidArgument("'%s' has 'lat' or 'lon' out of range", NEAR)
+	}
+	if f.Radius, err = jsonparser.GetFloat(input, "radius"); err != nil || f.Radius <= 0 {
+		return errors.InvalidArgument("'%s' needs a positive 'radius' in meters", NEAR)
+	}
+
+	return nil
+}
+
+func (f *GeoFilter) parseWithin(input []byte) error {
+	if box, dt, _, _ := jsonparser.Get(input, "box"); dt != jsonparser.NotExist {
+		points, err := parsePoints(box)
+		if err != nil {
+			return err
+		}
+		if len(points) != 2 || points[0].Lat > points[1].Lat {
+			return errors.InvalidArgument("'box' needs the south-west and the north-east corners")
+		}
+
+		f.Box = &geo.Rect{Min: points[0], Max: points[1]}
+		return nil
+	}
+
+	if polygon, dt, _, _ := jsonparser.Get(input, "polygon"); dt != jsonparser.NotExist {
+		points, err := parsePoints(polygon)
+		if err != nil {
+			return err
+		}
+		if len(points) < 3 {
+			return errors.InvalidArgument("'polygon' needs at least three points")
+		}
+
+		f.Polygon = points
+		return nil
+	}
+
+	return errors.InvalidArgument("'%s' needs either 'box' or 'polygon'", WITHIN)
+}
+
+func parsePoints(input []byte) ([]geo.Point, error) {
+	var (
+		points []geo.Point
+		err    error
+	)
+	_, parsingErr := jsonparser.ArrayEach(input, func(v []byte, _ jsonparser.ValueType, _ int, _ error) {
+		if err != nil {
+			return
+		}
+
+		var p geo.Point
+		if p, err = geo.ParsePoint(v); err != nil {
+			err = errors.InvalidArgument(err.Error())
+			return
+		}
+		points = append(points, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if parsingErr != nil {
+		return nil, errors.InvalidArgument("expected an array of points")
+	}
+
+	return points, nil
+}
+
+// Contains returns true if the point is inside the area of the filter.
+func (f *GeoFilter) Contains(p geo.Point) bool {
+	switch {
+	case f.Box != nil:
+		return f.Box.Contains(p)
+	case len(f.Polygon) > 0:
+		return f.Polygon.Contains(p)
+	default:
+		return f.Center.DistanceTo(p) <= f.Radius
+	}
+}
+
+// Bound returns the bounding box of the area of the filter.
+func (f *GeoFilter) Bound() geo.Rect {
+	switch {
+	case f.Box != nil:
+		return *f.Box
+	case len(f.Polygon) > 0:
+		return f.Polygon.Bound()
+	default:
+		return geo.CircleBound(f.Center, f.Radius)
+	}
+}
+
+// Ranges returns the ranges of the encoded geopoints that need to be scanned in the secondary index.
+func (f *GeoFilter) Ranges() []geo.Range {
+	return geo.Cover(f.Bound(), maxCoverCells)
+}
+
+// Matches returns true if the geopoint of the document is inside the area.
+func (f *GeoFilter) Matches(doc []byte, metadata []byte) bool {
+	docValue, dtp, err := getJSONField(doc, metadata, f.Field.FieldName, f.Field.KeyPath())
+	if dtp == jsonparser.NotExist || dtp == jsonparser.Null {
+		return false
+	}
+	if ulog.E(err) {
+		return false
+	}
+
+	p, err := geo.ParsePoint(docValue)
+	if err != nil {
+		return false
+	}
+
+	return f.Contains(p)
+}
+
+// MatchesDoc is used on the documents returned by the search store, which already applied the filter.
+func (f *GeoFilter) MatchesDoc(doc map[string]any) bool {
+	v, ok := doc[f.Field.Name()]
+	if !ok {
+		return true
+	}
+
+	raw, err := jsoniter.Marshal(v)
+	if err != nil {
+		return true
+	}
+
+	p, err := geo.ParsePoint(raw)
+	if err != nil {
+		return true
+	}
+
+	return f.Contains(p)
+}
+
+func (f *GeoFilter) ToSearchFilter() string {
+	switch {
+	case f.Box != nil && f.Box.Min.Lon > f.Box.Max.Lon:
+		// the search backend has no notion of a polygon crossing the antimeridian, so the box is split into the
+		// parts on either side of it
+		west := geo.Rect{Min: f.Box.Min, Max: geo.Point{Lat: f.Box.Max.Lat, Lon: 180}}
+		east := geo.Rect{Min: geo.Point{Lat: f.Box.Min.Lat, Lon: -180}, Max: f.Box.Max}
+		return fmt.Sprintf("(%s || %s)", f.polygonSearchFilter(boxPoints(west)), f.polygonSearchFilter(boxPoints(east)))
+	case f.Box != nil:
+		return f.polygonSearchFilter(boxPoints(*f.Box))
+	case len(f.Polygon) > 0:
+		return f.polygonSearchFilter(f.Polygon)
+	default:
+		return fmt.Sprintf("%s:(%s, %s, %s km)", f.Field.InMemoryName(), formatFloat(f.Center.Lat),
+			formatFloat(f.Center.Lon), formatFloat(f.Radius/1000))
+	}
+}
+
+func (f *GeoFilter) polygonSearchFilter(points []geo.Point) string {
+	coordinates := make([]string, 0, 2*len(points))
+	for _, p := range points {
+		coordinates = append(coordinates, formatFloat(p.Lat), formatFloat(p.Lon))
+	}
+
+	return fmt.Sprintf("%s:(%s)", f.Field.InMemoryName(), strings.Join(coordinates, ", "))
+}
+
+// boxPoints returns the corners of the box starting from the south-west one.
+func boxPoints(r geo.Rect) []geo.Point {
+	return []geo.Point{
+		r.Min,
+		{Lat: r.Max.Lat, Lon: r.Min.Lon},
+		r.Max,
+		{Lat: r.Min.Lat, Lon: r.Max.Lon},
+	}
+}
+
+func (f *GeoFilter) IsSearchIndexed() bool {
+	return f.Field.SearchIndexed
+}
+
+func (f *GeoFilter) String() string {
+	switch {
+	case f.Box != nil:
+		return fmt.Sprintf("{%v:{%s:box%v}}", f.Field.Name(), f.Op, *f.Box)
+	case len(f.Polygon) > 0:
+		return fmt.Sprintf("{%v:{%s:polygon%v}}", f.Field.Name(), f.Op, f.Polygon)
+	default:
+		return fmt.Sprintf("{%v:{%s:%v,%v}}", f.Field.Name(), f.Op, f.Center, f.Radius)
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,140 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/lib/geo"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/value"
+)
+
+var geoFields = []*schema.QueryableField{
+	{FieldName: "name", DataType: schema.StringType},
+	{FieldName: "loc", InMemoryAlias: "loc", DataType: schema.GeoPointType, SearchIndexed: true},
+}
+
+func TestGeoFilter(t *testing.T) {
+	sf := []byte(`{"name": "sf", "loc": {"lat": 37.7749, "lon": -122.4194}}`)
+	oakland := []byte(`{"name": "oakland", "loc": {"lat": 37.8044, "lon": -122.2712}}`)
+	la := []byte(`{"name": "la", "loc": {"lat": 34.0522, "lon": -118.2437}}`)
+	missing := []byte(`{"name": "missing"}`)
+
+	cases := []struct {
+		filter  string
+		search  string
+		matches []bool
+	}{
+		{
+			`{"loc": {"$near": {"lat": 37.7749, "lon": -122.4194, "radius": 20000}}}`,
+			`loc:(37.7749, -122.4194, 20 km)`,
+			[]bool{true, true, false, false},
+		}, {
+			`{"loc": {"$near": {"lat": 37.7749, "lon": -122.4194, "radius": 5000}}}`,
+			`loc:(37.7749, -122.4194, 5 km)`,
+			[]bool{true, false, false, false},
+		}, {
+			`{"loc": {"$within": {"box": [{"lat": 33, "lon": -123}, {"lat": 37.8, "lon": -118}]}}}`,
+			`loc:(33, -123, 37.8, -123, 37.8, -118, 33, -118)`,
+			[]bool{true, false, true, false},
+		}, {
+			`{"loc": {"$within": {"polygon": [{"lat": 33, "lon": -124}, {"lat": 39, "lon": -124}, {"lat": 39, "lon": -118}]}}}`,
+			`loc:(33, -124, 39, -124, 39, -118)`,
+			[]bool{true, true, false, false},
+		}, {
+			`{"loc": {"$within": {"box": [{"lat": 30, "lon": 170}, {"lat": 40, "lon": -120}]}}}`,
+			`(loc:(30, 170, 40, 170, 40, 180, 30, 180) || loc:(30, -180, 40, -180, 40, -120, 30, -120))`,
+			[]bool{true, true, false, false},
+		},
+	}
+
+	for _, c := range cases {
+		filters := testFilters(t, geoFields, []byte(c.filter), false)
+		require.Len(t, filters, 1)
+
+		f, ok := filters[0].(*GeoFilter)
+		require.True(t, ok)
+		require.True(t, f.IsSearchIndexed())
+		require.Equal(t, c.search, f.ToSearchFilter())
+
+		for i, doc := range [][]byte{sf, oakland, la, missing} {
+			require.Equal(t, c.matches[i], f.Matches(doc, nil), "%s %s", c.filter, doc)
+		}
+
+		require.True(t, f.MatchesDoc(map[string]any{"name": "missing"}))
+	}
+}
+
+func TestGeoFilterInLogical(t *testing.T) {
+	fields := []*schema.QueryableField{
+		{FieldName: "name", InMemoryAlias: "name", DataType: schema.StringType, SearchIndexed: true},
+		geoFields[1],
+	}
+	filters := testFilters(t, fields, []byte(`{"name": "sf", "loc": {"$near": {"lat": 37.7749, "lon": -122.4194, "radius": 5000}}}`), false)
+	require.Equal(t, `name:=`+"`sf`"+` && loc:(37.7749, -122.4194, 5 km)`, NewWrappedFilter(filters).SearchFilter())
+}
+
+func TestGeoFilterErrors(t *testing.T) {
+	factory := NewFactory(geoFields, nil)
+
+	cases := []struct {
+		filter string
+		err    string
+	}{
+		{`{"loc": {"$eq": 1}}`, "only '$near' and '$within' are supported on geopoint field 'loc'"},
+		{`{"loc": {"$near": {"lat": 37.7, "lon": -122.4}}}`, "'$near' needs a positive 'radius' in meters"},
+		{`{"loc": {"$near": {"lat": 97.7, "lon": -122.4, "radius": 10}}}`, "'$near' has 'lat' or 'lon' out of range"},
+		{`{"loc": {"$near": {"lat": 37.7, "lon": -122.4, "radius": 10}, "$within": {"box": [{"lat": 1, "lon": 1}, {"lat": 2, "lon": 2}]}}}`, "needs exactly one of"},
+		{`{"loc": {"$within": {"box": [{"lat": 38, "lon": -123}, {"lat": 37, "lon": -122}]}}}`, "'box' needs the south-west and the north-east corners"},
+		{`{"loc": {"$within": {"polygon": [{"lat": 38, "lon": -123}, {"lat": 37, "lon": -122}]}}}`, "'polygon' needs at least three points"},
+		{`{"loc": {"$within": {"polygon": [{"lat": 38}, {"lat": 37, "lon": -122}, {"lat": 37, "lon": -121}]}}}`, "point needs to be an object"},
+		{`{"loc": {"$within": {}}}`, "'$within' needs either 'box' or 'polygon'"},
+		{`{"name": {"$near": {"lat": 37.7, "lon": -122.4, "radius": 10}}}`, "expression is not supported inside comparison operator $near"},
+	}
+
+	for _, c := range cases {
+		_, err := factory.Factorize([]byte(c.filter))
+		require.ErrorContains(t, err, c.err, c.filter)
+	}
+}
+
+func TestGeoQueryPlan(t *testing.T) {
+	filters := testFilters(t, geoFields, []byte(`{"$and": [{"name": "sf"}, {"loc": {"$near": {"lat": 37.7749, "lon": -122.4194, "radius": 20000}}}]}`), false)
+
+	_, err := QueryPlanFromGeoFilter(filters, geoFields[:1], dummyEncodeFunc, dummyBuildIndexParts, SecondaryIndex)
+	require.Error(t, err)
+
+	plan, err := QueryPlanFromGeoFilter(filters, geoFields, dummyEncodeFunc, dummyBuildIndexParts, SecondaryIndex)
+	require.NoError(t, err)
+	require.Equal(t, MULTIRANGE, plan.QueryType)
+	require.Equal(t, "loc", plan.FieldName)
+	require.True(t, plan.Ascending)
+
+	ranges := filters[0].(LogicalFilter).GetFilters()[1].(*GeoFilter).Ranges()
+	require.Len(t, plan.Keys, 2*len(ranges))
+	for i, r := range ranges {
+		require.Equal(t, dummyBuildIndexParts("loc", value.NewIntValue(r.Start)), plan.Keys[2*i].IndexParts())
+		require.Equal(t, dummyBuildIndexParts("loc", value.NewIntValue(r.End)), plan.Keys[2*i+1].IndexParts())
+	}
+
+	hash := geo.Encode(geo.Point{Lat: 37.8044, Lon: -122.2712})
+	covered := false
+	for _, r := range ranges {
+		covered = covered || (hash >= r.Start && hash < r.End)
+	}
+	require.True(t, covered)
+}
@@ -51,6 +51,8 @@ const (
 	EQUAL QueryPlanType = iota
 	RANGE
 	FULLRANGE
+	// MULTIRANGE is a list of ranges, the keys are the start and the end of each range.
+	MULTIRANGE
 )
 
 func IndexTypeSecondary(indexType IndexType) bool {
@@ -465,6 +467,10 @@ func QueryPlanFromSort(sortFields *[]tsort.SortField, indexableFields []*schema.
 	if field == nil {
 		return nil, errors.InvalidArgument("Sort field is not indexed")
 	}
+	if field.DataType == schema.GeoPointType || (*sortFields)[0].Origin != nil {
+		// the geopoint index is ordered by the geohash and not by the distance
+		return nil, errors.InvalidArgument("Sort by distance can't use the secondary index")
+	}
 
 	min, err := encoder(buildIndexParts(field.FieldName, value.MinOrderValue())...)
 	if err != nil {
@@ -487,3 +493,51 @@ func QueryPlanFromSort(sortFields *[]tsort.SortField, indexableFields []*schema.
 
 	return plan, nil
 }
+
+// QueryPlanFromGeoFilter returns a plan scanning the geohash ranges covering the area of a geo filter on an indexed
+// geopoint field. Only the filters at the top level or inside a top level $and are considered. The ranges cover a
+// slightly larger area, so the filter still needs to be applied on the documents read using this plan.
+func QueryPlanFromGeoFilter(filters []Filter, indexableFields []*schema.QueryableField, encoder KeyEncodingFunc, buildIndexParts BuildIndexPartsFunc, indexType IndexType) (*QueryPlan, error) {
+	for _, f := range filters {
+		var candidates []Filter
+		switch ff := f.(type) {
+		case *GeoFilter:
+			candidates = append(candidates, ff)
+		case LogicalFilter:
+			if ff.Type() == AndOP {
+				candidates = ff.GetFilters()
+			}
+		}
+
+		for _, candidate := range candidates {
+			geoFilter, ok := candidate.(*GeoFilter)
+			if !ok {
+				continue
+			}
+
+			for _, field := range indexableFields {
+				if field.FieldName != geoFilter.Field.FieldName || field.DataType != schema.GeoPointType {
+					continue
+				}
+
+				var planKeys []keys.Key
+				for _, r := range geoFilter.Ranges() {
+					start, err := encoder(buildIndexParts(field.FieldName, value.NewIntValue(r.Start))...)
+					if err != nil {
+						return nil, err
+					}
+					end, err := encoder(buildIndexParts(field.FieldName, value.NewIntValue(r.End))...)
+					if err != nil {
+						return nil, err
+					}
+					planKeys = append(planKeys, start, end)
+				}
+
+				plan := NewQueryPlan(MULTIRANGE, field.FieldName, field.DataType, planKeys, indexType)
+				return &plan, nil
+			}
+		}
+	}
+
+	return nil, errors.InvalidArgument("no geo filter on an indexed field")
+}
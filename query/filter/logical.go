@@ -201,19 +201,20 @@ func (o *OrFilter) String() string {
 type searchSerializer struct{}
 
 func (sz *searchSerializer) serialize(searchToken string, filters []Filter) []string {
-	var selectors []*Selector
+	var leaves []Filter
 	var logical []LogicalFilter
 	for _, f := range filters {
 		switch conv := f.(type) {
-		case *Selector:
-			selectors = append(selectors, conv)
 		case LogicalFilter:
-			logical = append(logical, f.(LogicalFilter))
+			logical = append(logical, conv)
+		default:
+			// selectors and the geo filters
+			leaves = append(leaves, f)
 		}
 	}
 
 	var str string
-	for i, s := range selectors {
+	for i, s := range leaves {
 		// first "&&" all selectors
 		if i != 0 {
 			str += searchToken
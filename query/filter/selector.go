@@ -75,6 +75,16 @@ func (s *Selector) MatchesDoc(doc map[string]any) bool {
 		if ulog.E(err) {
 			return true
 		}
+	case schema.DecimalType:
+		// search store filters on the float, so the exact comparison is applied here
+		str, ok := v.(string)
+		if !ok {
+			return true
+		}
+		var err error
+		if val, err = value.NewDecimalValue(str); ulog.E(err) {
+			return true
+		}
 	default:
 		// as this method is only intended for indexing store, so we only apply filter for string and double type
 		// otherwise we rely on indexing store to only return valid results.
@@ -137,6 +147,8 @@ func (s *Selector) ToSearchFilter() string {
 	switch s.Matcher.Type() {
 	case EQ:
 		op = "%s:=%v"
+	case NE:
+		op = "%s:!=%v"
 	case GT:
 		op = "%s:>%v"
 	case GTE:
@@ -149,8 +161,8 @@ func (s *Selector) ToSearchFilter() string {
 
 	v := s.Matcher.GetValue()
 	switch s.Field.DataType {
-	case schema.DoubleType:
-		// for double, we pass string in the filter to search backend
+	case schema.DoubleType, schema.DecimalType:
+		// for double and decimal, we pass string in the filter to search backend
 		return fmt.Sprintf(op, s.Field.InMemoryName(), v.String())
 	case schema.DateTimeType:
 		// encode into int64
@@ -117,6 +117,35 @@ func TestSelector(t *testing.T) {
 	require.Equal(t, true, s.Matches(doc, nil))
 }
 
+func TestDecimalSelector(t *testing.T) {
+	fields := []*schema.QueryableField{
+		{FieldName: "amount", InMemoryAlias: "amount", DataType: schema.DecimalType, SearchIndexed: true},
+	}
+	doc := []byte(`{"amount": "0.30"}`)
+
+	cases := []struct {
+		filter   string
+		search   string
+		expMatch bool
+	}{
+		{`{"amount": "0.3"}`, "amount:=0.3", true},
+		{`{"amount": 0.3}`, "amount:=0.3", true},
+		{`{"amount": {"$gt": "0.29999999999999999999"}}`, "amount:>0.29999999999999999999", true},
+		{`{"amount": {"$lt": "0.30000000000000000001"}}`, "amount:<0.30000000000000000001", true},
+		{`{"amount": {"$gt": "0.30000000000000000001"}}`, "amount:>0.30000000000000000001", false},
+		{`{"amount": {"$ne": "0.3"}}`, "amount:!=0.3", false},
+		{`{"amount": {"$ne": "0.31"}}`, "amount:!=0.31", true},
+	}
+	for _, c := range cases {
+		filters := testFilters(t, fields, []byte(c.filter), false)
+		require.Len(t, filters, 1)
+		s := filters[0].(*Selector)
+		require.Equal(t, c.expMatch, s.Matches(doc, nil), c.filter)
+		require.Equal(t, c.expMatch, s.MatchesDoc(map[string]any{"amount": "0.30"}), c.filter)
+		require.Equal(t, c.search, s.ToSearchFilter(), c.filter)
+	}
+}
+
 func mustNewValue(t *testing.T, ty schema.FieldType, v []byte) value.Value {
 	val, err := value.NewValue(ty, v)
 	if err != nil {
@@ -0,0 +1,221 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package read
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+const (
+	// maxLookups is the maximum number of lookups allowed in a single read request.
+	maxLookups = 4
+	// defaultLookupLimit is the maximum number of foreign documents embedded per document if the lookup
+	// doesn't explicitly set the limit.
+	defaultLookupLimit = 100
+)
+
+// Lookup is a cross-collection lookup of a read request. For every document returned by the read, the documents
+// of the "From" collection whose "ForeignField" is equal to the "LocalField" of the document are embedded as an
+// array under the "As" field.
+type Lookup struct {
+	From         string
+	LocalField   string
+	ForeignField string
+	As           string
+	Limit        int64
+	Fields       jsoniter.RawMessage
+	FieldFactory *FieldFactory
+}
+
+func newLookup(input jsoniter.RawMessage) (*Lookup, error) {
+	type lookupValue struct {
+		From         string              `json:"from"`
+		LocalField   string              `json:"local_field"`
+		ForeignField string              `json:"foreign_field"`
+		As           string              `json:"as"`
+		Limit        int64               `json:"limit"`
+		Fields       jsoniter.RawMessage `json:"fields"`
+	}
+
+	var v lookupValue
+	if err := jsoniter.Unmarshal(input, &v); err != nil {
+		return nil, errors.InvalidArgument("Invalid value for `lookup` %s", err.Error())
+	}
+
+	switch {
+	case len(v.From) == 0:
+		return nil, errors.InvalidArgument("`from` is required in lookup")
+	case len(v.LocalField) == 0:
+		return nil, errors.InvalidArgument("`local_field` is required in lookup")
+	case len(v.ForeignField) == 0:
+		return nil, errors.InvalidArgument("`foreign_field` is required in lookup")
+	case v.Limit < 0:
+		return nil, errors.InvalidArgument("`limit` in lookup can't be negative")
+	}
+
+	if len(v.As) == 0 {
+		v.As = v.From
+	}
+	if v.Limit == 0 {
+		v.Limit = defaultLookupLimit
+	}
+
+	factory, err := BuildFields(v.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Lookup{
+		From:         v.From,
+		LocalField:   v.LocalField,
+		ForeignField: v.ForeignField,
+		As:           v.As,
+		Limit:        v.Limit,
+		Fields:       v.Fields,
+		FieldFactory: factory,
+	}, nil
+}
+
+// UnmarshalLookup expects a json array input. Examples:
+//
+//	[{"from": "orders", "local_field": "id", "foreign_field": "customer_id", "as": "orders"}]
+//	[{"from": "orders", "local_field": "id", "foreign_field": "customer_id", "fields": {"total": true}, "limit": 10}]
+func UnmarshalLookup(input jsoniter.RawMessage) ([]*Lookup, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	var (
+		err     error
+		lookups []*Lookup
+		aliases = make(map[string]struct{})
+	)
+	_, err2 := jsonparser.ArrayEach(input, func(item []byte, vt jsonparser.ValueType, offset int, err1 error) {
+		if err != nil {
+			return
+		}
+		if err1 != nil {
+			err = err1
+			return
+		}
+
+		if vt != jsonparser.Object {
+			err = errors.InvalidArgument("Invalid value for `%s`", "lookup")
+			return
+		}
+
+		if len(lookups) >= maxLookups {
+			err = errors.InvalidArgument("Lookup can support up to `%d` collections only", maxLookups)
+			return
+		}
+
+		var l *Lookup
+		if l, err = newLookup(item); err != nil {
+			return
+		}
+		if _, ok := aliases[l.As]; ok {
+			err = errors.InvalidArgument("duplicate lookup alias '%s'", l.As)
+			return
+		}
+		aliases[l.As] = struct{}{}
+
+		lookups = append(lookups, l)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err2 != nil {
+		return nil, errors.InvalidArgument("Invalid value for `lookup` %s", err2.Error())
+	}
+
+	return lookups, nil
+}
+
+// BuildFilter returns the filter that needs to be applied on the "From" collection to find the documents matching
+// the document. In case the local field is an array, documents matching any of the array element are returned. The
+// boolean is false if the document doesn't have a value for the local field, in which case there is nothing to look up.
+func (l *Lookup) BuildFilter(document []byte) ([]byte, bool, error) {
+	value, dataType, _, err := jsonparser.Get(document, strings.Split(l.LocalField, ".")...)
+	if err == jsonparser.KeyPathNotFoundError {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch dataType {
+	case jsonparser.Null, jsonparser.Object:
+		return nil, false, nil
+	case jsonparser.Array:
+		var elements [][]byte
+		_, err = jsonparser.ArrayEach(value, func(item []byte, vt jsonparser.ValueType, _ int, _ error) {
+			if vt == jsonparser.Null || vt == jsonparser.Object || vt == jsonparser.Array {
+				return
+			}
+			elements = append(elements, l.selector(item, vt))
+		})
+		if err != nil {
+			return nil, false, err
+		}
+
+		switch len(elements) {
+		case 0:
+			return nil, false, nil
+		case 1:
+			return elements[0], true, nil
+		}
+
+		var buf bytes.Buffer
+		_, _ = buf.WriteString(`{"$or":[`)
+		_, _ = buf.Write(bytes.Join(elements, []byte(",")))
+		_, _ = buf.WriteString(`]}`)
+		return buf.Bytes(), true, nil
+	default:
+		return l.selector(value, dataType), true, nil
+	}
+}
+
+func (l *Lookup) selector(value []byte, dataType jsonparser.ValueType) []byte {
+	obj := &JSONObject{Value: value, DataType: dataType}
+
+	var buf bytes.Buffer
+	_, _ = buf.WriteString(`{"`)
+	_, _ = buf.WriteString(l.ForeignField)
+	_, _ = buf.WriteString(`":`)
+	_, _ = buf.Write(obj.GetValue())
+	_, _ = buf.WriteString(`}`)
+
+	return buf.Bytes()
+}
+
+// Embed sets the matched documents as an array under the "As" field of the document.
+func (l *Lookup) Embed(document []byte, matches [][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+	_, _ = buf.WriteString("[")
+	_, _ = buf.Write(bytes.Join(matches, []byte(",")))
+	_, _ = buf.WriteString("]")
+
+	embedded, err := jsonparser.Set(document, buf.Bytes(), strings.Split(l.As, ".")...)
+	if err != nil {
+		return nil, errors.Internal("failed to embed lookup '%s' %s", l.As, err.Error())
+	}
+
+	return embedded, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package read
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalLookup(t *testing.T) {
+	lookups, err := UnmarshalLookup(nil)
+	require.NoError(t, err)
+	require.Nil(t, lookups)
+
+	lookups, err = UnmarshalLookup([]byte(`[{"from": "orders", "local_field": "id", "foreign_field": "customer_id"}]`))
+	require.NoError(t, err)
+	require.Len(t, lookups, 1)
+	require.Equal(t, "orders", lookups[0].From)
+	require.Equal(t, "orders", lookups[0].As)
+	require.Equal(t, int64(defaultLookupLimit), lookups[0].Limit)
+	require.Empty(t, lookups[0].FieldFactory.Include)
+
+	lookups, err = UnmarshalLookup([]byte(`[{"from": "orders", "local_field": "id", "foreign_field": "customer_id", "as": "o", "limit": 5, "fields": {"total": true}}]`))
+	require.NoError(t, err)
+	require.Equal(t, "o", lookups[0].As)
+	require.Equal(t, int64(5), lookups[0].Limit)
+	require.Len(t, lookups[0].FieldFactory.Include, 1)
+
+	_, err = UnmarshalLookup([]byte(`[{"from": "orders", "local_field": "id"}]`))
+	require.ErrorContains(t, err, "`foreign_field` is required in lookup")
+
+	_, err = UnmarshalLookup([]byte(`[{"from": "orders", "local_field": "id", "foreign_field": "a"}, {"from": "orders", "local_field": "id", "foreign_field": "b"}]`))
+	require.ErrorContains(t, err, "duplicate lookup alias 'orders'")
+
+	_, err = UnmarshalLookup([]byte(`{"from": "orders"}`))
+	require.Error(t, err)
+}
+
+func TestLookupBuildFilter(t *testing.T) {
+	l := &Lookup{LocalField: "customer.id", ForeignField: "customer_id"}
+
+	cases := []struct {
+		document  []byte
+		expFilter []byte
+		expFound  bool
+	}{
+		{[]byte(`{"customer": {"id": 1}}`), []byte(`{"customer_id":1}`), true},
+		{[]byte(`{"customer": {"id": "a\"b"}}`), []byte(`{"customer_id":"a\"b"}`), true},
+		{[]byte(`{"customer": {"id": [1, null, 2]}}`), []byte(`{"$or":[{"customer_id":1},{"customer_id":2}]}`), true},
+		{[]byte(`{"customer": {"id": ["a"]}}`), []byte(`{"customer_id":"a"}`), true},
+		{[]byte(`{"customer": {"id": null}}`), nil, false},
+		{[]byte(`{"customer": {"id": []}}`), nil, false},
+		{[]byte(`{"name": "a"}`), nil, false},
+	}
+	for _, c := range cases {
+		f, found, err := l.BuildFilter(c.document)
+		require.NoError(t, err)
+		require.Equal(t, c.expFound, found, string(c.document))
+		require.Equal(t, string(c.expFilter), string(f))
+	}
+}
+
+func TestLookupEmbed(t *testing.T) {
+	l := &Lookup{As: "orders"}
+
+	doc, err := l.Embed([]byte(`{"id":1}`), [][]byte{[]byte(`{"total":10}`), []byte(`{"total":20}`)})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":1,"orders":[{"total":10},{"total":20}]}`, string(doc))
+
+	doc, err = l.Embed([]byte(`{"id":1}`), nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":1,"orders":[]}`, string(doc))
+}
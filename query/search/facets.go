@@ -15,14 +15,31 @@
 package search
 
 import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/query/filter"
+	"github.com/tigrisdata/tigris/schema"
 )
 
 const (
 	defaultFacetSize = 10
 )
 
+const (
+	// FacetTypeValue counts the documents for each value of the field, it is the default.
+	FacetTypeValue = "value"
+	// FacetTypeRange counts the documents for each of the ranges of the field.
+	FacetTypeRange = "range"
+	// FacetTypeStats only returns the stats of a numeric field.
+	FacetTypeStats = "stats"
+)
+
 type Facets struct {
 	Fields []FacetField
 }
@@ -31,27 +48,239 @@ type FacetField struct {
 	Name string
 	Type string
 	Size int
+	// Ranges are the buckets of a range facet, they are returned in the same order.
+	Ranges []FacetRange
+	// ExcludeFilter is set to count the facet without the filter on the field itself, WrappedF is then the filter of
+	// the query without the conditions on the field.
+	ExcludeFilter bool
+	WrappedF      *filter.WrappedFilter
+
+	// dateRanges is set if the bounds of the ranges are dates.
+	dateRanges bool
+}
+
+// FacetRange counts the values from From inclusive to To exclusive, a nil bound is unbounded.
+type FacetRange struct {
+	Key  string
+	From *float64
+	To   *float64
 }
 
 func NewFacetField(name string, value jsoniter.RawMessage) (FacetField, error) {
+	type facetRangeValue struct {
+		Key  string
+		From jsoniter.RawMessage
+		To   jsoniter.RawMessage
+	}
+
 	type facetValue struct {
-		Type string
-		Size int
+		Type          string
+		Size          int
+		Ranges        []facetRangeValue
+		ExcludeFilter bool `json:"exclude_filter"`
 	}
 
 	var v facetValue
 	if err := jsoniter.Unmarshal(value, &v); err != nil {
 		return FacetField{}, err
 	}
+	if len(v.Type) == 0 {
+		v.Type = FacetTypeValue
+	}
 	if v.Size == 0 {
 		v.Size = defaultFacetSize
 	}
 
-	return FacetField{
-		Name: name,
-		Type: v.Type,
-		Size: v.Size,
-	}, nil
+	field := FacetField{
+		Name:          name,
+		Type:          v.Type,
+		Size:          v.Size,
+		ExcludeFilter: v.ExcludeFilter,
+	}
+
+	switch v.Type {
+	case FacetTypeValue:
+		if len(v.Ranges) > 0 {
+			return FacetField{}, errors.InvalidArgument("Ranges are only supported for the range facets, field `%s`", name)
+		}
+	case FacetTypeRange:
+		if len(v.Ranges) == 0 {
+			return FacetField{}, errors.InvalidArgument("Range facet on `%s` needs at least one range", name)
+		}
+
+		now := time.Now()
+		for _, r := range v.Ranges {
+			var (
+				fr               FacetRange
+				fromDate, toDate bool
+				err              error
+			)
+			if len(r.From) == 0 && len(r.To) == 0 {
+				return FacetField{}, errors.InvalidArgument("Range of the facet `%s` needs a from or a to", name)
+			}
+			if len(r.From) > 0 {
+				if fr.From, fromDate, err = parseFacetBound(r.From, now); err != nil {
+					return FacetField{}, err
+				}
+			}
+			if len(r.To) > 0 {
+				if fr.To, toDate, err = parseFacetBound(r.To, now); err != nil {
+					return FacetField{}, err
+				}
+			}
+			if (len(r.From) > 0 && len(r.To) > 0 && fromDate != toDate) ||
+				(len(field.Ranges) > 0 && field.dateRanges != (fromDate || toDate)) {
+				return FacetField{}, errors.InvalidArgument("Ranges of the facet `%s` cannot mix dates and numbers", name)
+			}
+			if fr.From != nil && fr.To != nil && *fr.From >= *fr.To {
+				return FacetField{}, errors.InvalidArgument("Range of the facet `%s` should have from less than to", name)
+			}
+
+			fr.Key = r.Key
+			if len(fr.Key) == 0 {
+				// like "10-100" or "*-10" for an unbounded range
+				fr.Key = rangeKeyBound(r.From) + "-" + rangeKeyBound(r.To)
+			}
+			field.dateRanges = fromDate || toDate
+			field.Ranges = append(field.Ranges, fr)
+		}
+		// all the ranges are returned
+		field.Size = len(field.Ranges)
+	case FacetTypeStats:
+		if len(v.Ranges) > 0 {
+			return FacetField{}, errors.InvalidArgument("Ranges are only supported for the range facets, field `%s`", name)
+		}
+		// only the stats are returned
+		field.Size = 0
+	default:
+		return FacetField{}, errors.InvalidArgument("Unsupported facet type `%s` for field `%s`", v.Type, name)
+	}
+
+	return field, nil
+}
+
+// parseFacetBound returns the number of a bound, a date bound is either in RFC 3339 format or relative to now like
+// "now-7d" and is converted to Unix nanoseconds like the dates in the search indexes.
+func parseFacetBound(raw jsoniter.RawMessage, now time.Time) (*float64, bool, error) {
+	var str string
+	if err := jsoniter.Unmarshal(raw, &str); err != nil {
+		var n float64
+		if err = jsoniter.Unmarshal(raw, &n); err != nil {
+			return nil, false, errors.InvalidArgument("Range bound `%s` should be a number or a date", string(raw))
+		}
+		return &n, false, nil
+	}
+
+	var (
+		t   time.Time
+		err error
+	)
+	if strings.HasPrefix(str, "now") {
+		t, err = relativeDate(now, str)
+	} else {
+		t, err = time.Parse(time.RFC3339Nano, str)
+	}
+	if err != nil {
+		return nil, false, errors.InvalidArgument("Range bound `%s` should be a number or a date", str)
+	}
+
+	n := float64(t.UnixNano())
+	return &n, true, nil
+}
+
+func rangeKeyBound(raw jsoniter.RawMessage) string {
+	if len(raw) == 0 {
+		return "*"
+	}
+
+	return strings.Trim(string(raw), `"`)
+}
+
+// relativeDate parses "now" with an optional offset made of a sign, a count and one of the units "m", "h", "d", "w",
+// "M" or "y", for example "now-1M" is a month ago.
+func relativeDate(now time.Time, str string) (time.Time, error) {
+	offset := strings.TrimPrefix(str, "now")
+	if len(offset) == 0 {
+		return now, nil
+	}
+	if len(offset) < 3 || (offset[0] != '-' && offset[0] != '+') {
+		return time.Time{}, errors.InvalidArgument("invalid relative date `%s`", str)
+	}
+
+	count, err := strconv.Atoi(offset[1 : len(offset)-1])
+	if err != nil {
+		return time.Time{}, err
+	}
+	if offset[0] == '-' {
+		count = -count
+	}
+
+	switch offset[len(offset)-1] {
+	case 'm':
+		return now.Add(time.Duration(count) * time.Minute), nil
+	case 'h':
+		return now.Add(time.Duration(count) * time.Hour), nil
+	case 'd':
+		return now.AddDate(0, 0, count), nil
+	case 'w':
+		return now.AddDate(0, 0, 7*count), nil
+	case 'M':
+		return now.AddDate(0, count, 0), nil
+	case 'y':
+		return now.AddDate(count, 0, 0), nil
+	}
+
+	return time.Time{}, errors.InvalidArgument("invalid relative date `%s`", str)
+}
+
+func (f *FacetField) IsRange() bool {
+	return f.Type == FacetTypeRange
+}
+
+// HasOwnFilter returns true if the facet is counted with a different filter than the query.
+func (f *FacetField) HasOwnFilter() bool {
+	return f.WrappedF != nil
+}
+
+// ValidateType checks that the type of the facet is supported for the field. The bounds of the ranges on the integer
+// fields are rounded up as the ranges are counted by filtering on the field.
+func (f *FacetField) ValidateType(field *schema.QueryableField) error {
+	if f.Type == FacetTypeValue {
+		return nil
+	}
+
+	dataType := field.DataType
+	if dataType == schema.ArrayType {
+		dataType = field.SubType
+	}
+
+	switch dataType {
+	case schema.Int32Type, schema.Int64Type, schema.DoubleType, schema.DecimalType:
+		if f.dateRanges {
+			return errors.InvalidArgument("Range bounds of the facet `%s` should be numbers", f.Name)
+		}
+	case schema.DateTimeType:
+		if f.Type == FacetTypeStats || field.DataType == schema.ArrayType {
+			return errors.InvalidArgument("Cannot generate %s facets for `%s`", f.Type, f.Name)
+		}
+		if len(f.Ranges) > 0 && !f.dateRanges {
+			return errors.InvalidArgument("Range bounds of the facet `%s` should be dates", f.Name)
+		}
+	default:
+		return errors.InvalidArgument("Cannot generate %s facets for `%s`. Only supported for numeric fields", f.Type, f.Name)
+	}
+
+	if dataType == schema.Int32Type || dataType == schema.Int64Type || dataType == schema.DateTimeType {
+		for i := range f.Ranges {
+			for _, bound := range []*float64{f.Ranges[i].From, f.Ranges[i].To} {
+				if bound != nil {
+					*bound = math.Ceil(*bound)
+				}
+			}
+		}
+	}
+
+	return nil
 }
 
 func UnmarshalFacet(input jsoniter.RawMessage) (Facets, error) {
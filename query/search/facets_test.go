@@ -0,0 +1,85 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+func TestUnmarshalFacet(t *testing.T) {
+	facets, err := UnmarshalFacet([]byte(`{
+		"brand": {"size": 5, "exclude_filter": true},
+		"rating": {"type": "stats"},
+		"price": {"type": "range", "ranges": [{"key": "cheap", "to": 10.5}, {"from": 10.5}]}
+	}`))
+	require.NoError(t, err)
+	require.Len(t, facets.Fields, 3)
+
+	require.Equal(t, FacetField{Name: "brand", Type: FacetTypeValue, Size: 5, ExcludeFilter: true}, facets.Fields[0])
+	require.Equal(t, FacetField{Name: "rating", Type: FacetTypeStats}, facets.Fields[1])
+
+	price := facets.Fields[2]
+	require.True(t, price.IsRange())
+	require.Equal(t, 2, price.Size)
+	require.Equal(t, "cheap", price.Ranges[0].Key)
+	require.Nil(t, price.Ranges[0].From)
+	require.Equal(t, 10.5, *price.Ranges[0].To)
+	require.Equal(t, "10.5-*", price.Ranges[1].Key)
+	require.Equal(t, 10.5, *price.Ranges[1].From)
+	require.Nil(t, price.Ranges[1].To)
+
+	// the bounds are rounded up for the integer fields
+	require.NoError(t, price.ValidateType(&schema.QueryableField{FieldName: "price", DataType: schema.Int64Type}))
+	require.Equal(t, 11.0, *price.Ranges[0].To)
+	require.Equal(t, 11.0, *price.Ranges[1].From)
+
+	for _, invalid := range []string{
+		`{"a": {"type": "histogram"}}`,
+		`{"a": {"type": "range"}}`,
+		`{"a": {"type": "range", "ranges": [{"key": "all"}]}}`,
+		`{"a": {"type": "range", "ranges": [{"from": 10, "to": 5}]}}`,
+		`{"a": {"type": "range", "ranges": [{"from": "now-1d", "to": 5}]}}`,
+		`{"a": {"type": "range", "ranges": [{"from": "yesterday"}]}}`,
+		`{"a": {"ranges": [{"from": 1}]}}`,
+	} {
+		_, err = UnmarshalFacet([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestFacetField_DateRanges(t *testing.T) {
+	facets, err := UnmarshalFacet([]byte(`{"created_at": {"type": "range", "ranges": [
+		{"key": "this_week", "from": "now-1w"},
+		{"to": "2023-01-01T00:00:00Z"}
+	]}}`))
+	require.NoError(t, err)
+
+	f := facets.Fields[0]
+	require.InDelta(t, float64(time.Now().AddDate(0, 0, -7).UnixNano()), *f.Ranges[0].From, float64(time.Minute))
+	require.Equal(t, float64(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()), *f.Ranges[1].To)
+	require.Equal(t, "*-2023-01-01T00:00:00Z", f.Ranges[1].Key)
+
+	require.NoError(t, f.ValidateType(&schema.QueryableField{FieldName: "created_at", DataType: schema.DateTimeType}))
+	require.Error(t, f.ValidateType(&schema.QueryableField{FieldName: "created_at", DataType: schema.DoubleType}))
+	require.Error(t, f.ValidateType(&schema.QueryableField{FieldName: "created_at", DataType: schema.StringType}))
+
+	stats := FacetField{Name: "created_at", Type: FacetTypeStats}
+	require.Error(t, stats.ValidateType(&schema.QueryableField{FieldName: "created_at", DataType: schema.DateTimeType}))
+	require.NoError(t, stats.ValidateType(&schema.QueryableField{FieldName: "ratings", DataType: schema.ArrayType, SubType: schema.Int32Type}))
+}
@@ -0,0 +1,85 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	defaultHighlightPreTag        = "<mark>"
+	defaultHighlightPostTag       = "</mark>"
+	defaultHighlightSnippetLength = 4
+)
+
+// Highlight configures the highlighting of the tokens matching the query in the hits.
+type Highlight struct {
+	// Fields are the fields to highlight, the search fields of the query are highlighted if it is empty.
+	Fields []string `json:"fields"`
+	// FullFields are highlighted entirely in addition to the snippet around the matched tokens.
+	FullFields []string `json:"full_fields"`
+	PreTag     string   `json:"pre_tag"`
+	PostTag    string   `json:"post_tag"`
+	// SnippetLength is the number of tokens surrounding the matched tokens on each side in the snippets.
+	SnippetLength *int `json:"snippet_length"`
+}
+
+// UnmarshalHighlight returns nil if the highlighting is not requested.
+func UnmarshalHighlight(input jsoniter.RawMessage) (*Highlight, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	var h Highlight
+	if err := jsoniter.Unmarshal(input, &h); err != nil {
+		return nil, err
+	}
+
+	return &h, nil
+}
+
+func (h *Highlight) GetPreTag() string {
+	if len(h.PreTag) == 0 {
+		return defaultHighlightPreTag
+	}
+
+	return h.PreTag
+}
+
+func (h *Highlight) GetPostTag() string {
+	if len(h.PostTag) == 0 {
+		return defaultHighlightPostTag
+	}
+
+	return h.PostTag
+}
+
+func (h *Highlight) GetSnippetLength() int {
+	if h.SnippetLength == nil {
+		return defaultHighlightSnippetLength
+	}
+
+	return *h.SnippetLength
+}
+
+func (h *Highlight) ToSearchFields() string {
+	return strings.Join(h.Fields, ",")
+}
+
+func (h *Highlight) ToSearchFullFields() string {
+	return strings.Join(h.FullFields, ",")
+}
@@ -16,6 +16,7 @@ package search
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/tigrisdata/tigris/query/filter"
 	"github.com/tigrisdata/tigris/query/read"
@@ -37,6 +38,7 @@ type Query struct {
 	SortOrder      *sort.Ordering
 	GroupBy        GroupBy
 	VectorS        VectorSearch
+	Highlight      *Highlight
 }
 
 func (q *Query) ToSearchFacetSize() int {
@@ -54,14 +56,19 @@ func (q *Query) ToSearchFacetSize() int {
 	return maxSize
 }
 
+// ToSearchFacets returns the facets counted with the query, the range facets and the facets having their own filter
+// are counted separately.
 func (q *Query) ToSearchFacets() string {
 	if len(q.Facets.Fields) == 0 {
 		return ""
 	}
 
 	var facets string
-	for i, f := range q.Facets.Fields {
-		if i != 0 {
+	for _, f := range q.Facets.Fields {
+		if f.IsRange() || f.HasOwnFilter() {
+			continue
+		}
+		if len(facets) > 0 {
 			facets += ","
 		}
 		facets += f.Name
@@ -95,6 +102,12 @@ func (q *Query) ToSortFields() string {
 		if f.MissingValuesFirst {
 			missingValue = "first"
 		}
+		if f.Origin != nil {
+			sortBy += fmt.Sprintf("%s(%s, %s):asc", f.Name, strconv.FormatFloat(f.Origin.Lat, 'f', -1, 64),
+				strconv.FormatFloat(f.Origin.Lon, 'f', -1, 64))
+			continue
+		}
+
 		order := "desc"
 		if f.Ascending {
 			order = "asc"
@@ -148,6 +161,10 @@ func (q *Query) HasNoSearchFilter() bool {
 	return q.NoSearchFilter != nil
 }
 
+func (q *Query) IsHighlighted() bool {
+	return q.Highlight != nil
+}
+
 type Builder struct {
 	query *Query
 }
@@ -212,6 +229,11 @@ func (b *Builder) VectorSearch(vecSearch VectorSearch) *Builder {
 	return b
 }
 
+func (b *Builder) Highlight(h *Highlight) *Builder {
+	b.query.Highlight = h
+	return b
+}
+
 func (b *Builder) PageSize(s int) *Builder {
 	b.query.PageSize = s
 	return b
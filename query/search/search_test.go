@@ -19,6 +19,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/lib/geo"
 	"github.com/tigrisdata/tigris/query/filter"
 	"github.com/tigrisdata/tigris/query/sort"
 	"github.com/tigrisdata/tigris/schema"
@@ -64,6 +65,15 @@ func TestQuery_ToSortFields(t *testing.T) {
 		assert.Equal(t, "field_1(missing_values: last):asc", sortBy)
 	})
 
+	t.Run("with near sort", func(t *testing.T) {
+		ordering := &sort.Ordering{
+			{Name: "location", Ascending: true, Origin: &geo.Point{Lat: 37.77, Lon: -122.41}},
+			{Name: "field_1"},
+		}
+		q := NewBuilder().SortOrder(ordering).Build()
+		assert.Equal(t, "location(37.77, -122.41):asc,field_1(missing_values: last):desc", q.ToSortFields())
+	})
+
 	t.Run("with 3 sort orders", func(t *testing.T) {
 		ordering := &sort.Ordering{
 			{Name: "field_1", Ascending: true},
@@ -78,3 +88,28 @@ func TestQuery_ToSortFields(t *testing.T) {
 		assert.Equal(t, expected, sortBy)
 	})
 }
+
+func TestUnmarshalHighlight(t *testing.T) {
+	h, err := UnmarshalHighlight(nil)
+	require.NoError(t, err)
+	require.Nil(t, h)
+	require.False(t, NewBuilder().Highlight(h).Build().IsHighlighted())
+
+	h, err = UnmarshalHighlight([]byte(`{"fields": ["title", "body"], "full_fields": ["title"]}`))
+	require.NoError(t, err)
+	require.Equal(t, "title,body", h.ToSearchFields())
+	require.Equal(t, "title", h.ToSearchFullFields())
+	require.Equal(t, "<mark>", h.GetPreTag())
+	require.Equal(t, "</mark>", h.GetPostTag())
+	require.Equal(t, 4, h.GetSnippetLength())
+	require.True(t, NewBuilder().Highlight(h).Build().IsHighlighted())
+
+	h, err = UnmarshalHighlight([]byte(`{"pre_tag": "<b>", "post_tag": "</b>", "snippet_length": 0}`))
+	require.NoError(t, err)
+	require.Equal(t, "<b>", h.GetPreTag())
+	require.Equal(t, "</b>", h.GetPostTag())
+	require.Equal(t, 0, h.GetSnippetLength())
+
+	_, err = UnmarshalHighlight([]byte(`{"fields": "title"}`))
+	require.Error(t, err)
+}
@@ -15,10 +15,12 @@
 package search
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/lib/embedding"
 )
 
 const (
@@ -30,6 +32,9 @@ type VectorSearch struct {
 	VectorF    string
 	VectorV    []float64
 	RawVectorV []byte
+	// Text is set when the query is a raw text instead of a vector, the vector is then computed by the embedding
+	// provider of the field.
+	Text string
 }
 
 func UnmarshalVectorSearch(input jsoniter.RawMessage) (VectorSearch, error) {
@@ -50,6 +55,11 @@ func UnmarshalVectorSearch(input jsoniter.RawMessage) (VectorSearch, error) {
 				return err
 			}
 			g.TopK = int(val)
+		} else if jsonDataType == jsonparser.String {
+			if g.Text, err = jsonparser.ParseString(v); err != nil {
+				return err
+			}
+			g.VectorF = string(k)
 		} else {
 			if err = jsoniter.Unmarshal(v, &g.VectorV); err != nil {
 				return err
@@ -65,3 +75,19 @@ func UnmarshalVectorSearch(input jsoniter.RawMessage) (VectorSearch, error) {
 
 	return g, nil
 }
+
+// Embed computes the query vector from the text of the query using the embedding provider of the field.
+func (v *VectorSearch) Embed(ctx context.Context, provider embedding.Provider, dimensions int) error {
+	vectors, err := provider.Embed(ctx, []string{v.Text}, dimensions)
+	if err != nil {
+		return err
+	}
+	if len(vectors) != 1 {
+		return embedding.ErrDimensionsMismatch
+	}
+
+	v.VectorV = vectors[0]
+	v.RawVectorV, err = jsoniter.Marshal(v.VectorV)
+
+	return err
+}
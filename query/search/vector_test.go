@@ -15,9 +15,11 @@
 package search
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/lib/embedding"
 )
 
 func TestUnmarshalVectorSearch(t *testing.T) {
@@ -34,3 +36,19 @@ func TestUnmarshalVectorSearch(t *testing.T) {
 	require.Error(t, err)
 	require.Empty(t, vs.VectorF)
 }
+
+func TestVectorSearchText(t *testing.T) {
+	vs, err := UnmarshalVectorSearch([]byte(`{"vec": "red \"shoes\"", "top_k": 3}`))
+	require.NoError(t, err)
+	require.Equal(t, VectorSearch{
+		VectorF: "vec",
+		Text:    `red "shoes"`,
+		TopK:    3,
+	}, vs)
+
+	require.NoError(t, vs.Embed(context.Background(), embedding.HashProvider{}, 4))
+	expected, err := embedding.HashProvider{}.Embed(context.Background(), []string{`red "shoes"`}, 4)
+	require.NoError(t, err)
+	require.Equal(t, expected[0], vs.VectorV)
+	require.NotEmpty(t, vs.RawVectorV)
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/geo"
 )
 
 // TODO: Update this to 3 once https://github.com/typesense/typesense/issues/690 is resolved.
@@ -26,6 +27,8 @@ const maxSortOrders = 2
 const (
 	ASC  = "$asc"
 	DESC = "$desc"
+	// NEAR sorts a geopoint field by the distance from a point, the nearest first.
+	NEAR = "$near"
 )
 
 type Ordering = []SortField
@@ -38,11 +41,17 @@ type SortField struct {
 	// Optional; True if missing/empty/null values to be presented at the top of sort order,
 	// else they are sorted to the end by default
 	MissingValuesFirst bool
+	// Optional; Set when a geopoint field is sorted by the distance from this point
+	Origin *geo.Point
 }
 
 func newSortField(order jsoniter.RawMessage) (SortField, error) {
 	var s SortField
 	err := jsonparser.ObjectEach(order, func(k []byte, v []byte, vt jsonparser.ValueType, offset int) error {
+		if vt == jsonparser.Object {
+			return s.parseNear(k, v)
+		}
+
 		switch string(v) {
 		case ASC:
 			s.Ascending = true
@@ -61,9 +70,28 @@ func newSortField(order jsoniter.RawMessage) (SortField, error) {
 	return s, nil
 }
 
+// parseNear parses the distance sort i.e. {"location": {"$near": {"lat": 37.77, "lon": -122.41}}}.
+func (s *SortField) parseNear(k []byte, v []byte) error {
+	origin, dt, _, err := jsonparser.Get(v, NEAR)
+	if err != nil || dt != jsonparser.Object {
+		return errors.InvalidArgument("Sort order can only be `%s`, `%s` or `%s`", ASC, DESC, NEAR)
+	}
+
+	p, err := geo.ParsePoint(origin)
+	if err != nil {
+		return errors.InvalidArgument("Invalid `%s` sort, %s", NEAR, err.Error())
+	}
+
+	s.Name = string(k)
+	s.Ascending = true
+	s.Origin = &p
+	return nil
+}
+
 // UnmarshalSort expects a json array input. Examples:
 //
 //	[{"field_1": "$asc"}, {"field_2": "$desc"}]
+//	[{"location": {"$near": {"lat": 37.77, "lon": -122.41}}}]
 //	[]
 func UnmarshalSort(input jsoniter.RawMessage) (*Ordering, error) {
 	if len(input) == 0 {
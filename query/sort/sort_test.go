@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/tigrisdata/tigris/lib/geo"
 )
 
 func TestUnmarshalSort(t *testing.T) {
@@ -94,6 +95,20 @@ func TestUnmarshalSort(t *testing.T) {
 		assert.Nil(t, sort)
 	})
 
+	t.Run("with near sort", func(t *testing.T) {
+		sort, err := UnmarshalSort([]byte(`[{"location":{"$near":{"lat":37.77,"lon":-122.41}}}]`))
+		assert.NoError(t, err)
+		assert.Exactly(t, Ordering{
+			{Name: "location", Ascending: true, Origin: &geo.Point{Lat: 37.77, Lon: -122.41}},
+		}, *sort)
+
+		_, err = UnmarshalSort([]byte(`[{"location":{"$far":{"lat":37.77,"lon":-122.41}}}]`))
+		assert.ErrorContains(t, err, "Sort order can only be `$asc`, `$desc` or `$near`")
+
+		_, err = UnmarshalSort([]byte(`[{"location":{"$near":{"lat":97.77,"lon":-122.41}}}]`))
+		assert.ErrorContains(t, err, "Invalid `$near` sort")
+	})
+
 	t.Run("Unmarshal 4 sort orders", func(t *testing.T) {
 		rawInput := []byte(`[{"field_1":"$asc"},{"field_2":"$desc"},{"field_3":"$asc"},{"field_4":"$asc"}]`)
 		sort, err := UnmarshalSort(rawInput)
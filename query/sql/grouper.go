@@ -0,0 +1,469 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	gosort "sort"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/decimal"
+)
+
+// MaxGroups is the maximum number of groups an aggregate query can produce, the groups are kept in memory until all
+// the documents are read.
+const MaxGroups = 10000
+
+// Grouper evaluates an aggregate query over the documents matching the filter. Documents are added one by one and
+// the result is produced once all the documents are added.
+type Grouper struct {
+	query  *Query
+	groups map[string]*group
+	order  []*group
+	// decimals are the decimal fields with their scale, these are aggregated using the exact arithmetic.
+	decimals map[string]int32
+}
+
+type group struct {
+	keys         []jsoniter.RawMessage
+	accumulators []*accumulator
+}
+
+func NewGrouper(query *Query) *Grouper {
+	return &Grouper{
+		query:  query,
+		groups: make(map[string]*group),
+	}
+}
+
+// WithDecimalFields sets the decimal fields of the collection along with their scale. The values of these fields are
+// strings, SUM and AVG of these fields are exact and MIN, MAX and ORDER BY compare them numerically.
+func (g *Grouper) WithDecimalFields(scales map[string]int32) *Grouper {
+	g.decimals = scales
+	return g
+}
+
+// Add adds a document to the group it belongs to.
+func (g *Grouper) Add(document []byte) error {
+	keys := make([]jsoniter.RawMessage, len(g.query.GroupBy))
+	var groupKey bytes.Buffer
+	for i, field := range g.query.GroupBy {
+		keys[i] = getValue(document, field)
+		_, _ = groupKey.Write(keys[i])
+		_ = groupKey.WriteByte(0)
+	}
+
+	grp, ok := g.groups[groupKey.String()]
+	if !ok {
+		if len(g.groups) >= MaxGroups {
+			return errors.InvalidArgument("aggregate query exceeds the maximum of %d groups", MaxGroups)
+		}
+		grp = g.newGroup(keys)
+		g.groups[groupKey.String()] = grp
+	}
+
+	for _, acc := range grp.accumulators {
+		if err := acc.add(document); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *Grouper) newGroup(keys []jsoniter.RawMessage) *group {
+	grp := &group{keys: keys}
+	for _, p := range g.query.Projections {
+		if len(p.Func) > 0 {
+			scale, isDecimal := g.decimals[p.Field]
+			grp.accumulators = append(grp.accumulators, &accumulator{fn: p.Func, field: p.Field, decimal: isDecimal, scale: scale})
+		}
+	}
+	g.order = append(g.order, grp)
+
+	return grp
+}
+
+// Result returns one document per group after applying ORDER BY, OFFSET and LIMIT of the query. An aggregate query
+// without GROUP BY always returns a single document, even if no document matched the filter.
+func (g *Grouper) Result() ([][]byte, error) {
+	if len(g.order) == 0 && len(g.query.GroupBy) == 0 {
+		g.newGroup(nil)
+	}
+
+	rows := make([][]byte, 0, len(g.order))
+	for _, grp := range g.order {
+		row, err := g.build(grp)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	if len(g.query.OrderBy) > 0 {
+		decimalColumns := g.decimalColumns()
+		gosort.SliceStable(rows, func(i, j int) bool {
+			for _, o := range g.query.OrderBy {
+				a, b := getColumn(rows[i], o.Field), getColumn(rows[j], o.Field)
+				var c int
+				if decimalColumns[o.Field] {
+					c = compareDecimals(a, b)
+				} else {
+					c = compareValues(a, b)
+				}
+				if c == 0 {
+					continue
+				}
+				if o.Ascending || isNull(a) || isNull(b) {
+					// null is last irrespective of the order
+					return c < 0
+				}
+				return c > 0
+			}
+			return false
+		})
+	}
+
+	if g.query.Offset >= int64(len(rows)) {
+		return nil, nil
+	}
+	rows = rows[g.query.Offset:]
+	if g.query.Limit > 0 && g.query.Limit < int64(len(rows)) {
+		rows = rows[:g.query.Limit]
+	}
+
+	return rows, nil
+}
+
+// decimalColumns returns the columns of the result that have decimal values.
+func (g *Grouper) decimalColumns() map[string]bool {
+	columns := make(map[string]bool)
+	for _, p := range g.query.Projections {
+		if _, ok := g.decimals[p.Field]; ok && p.Func != FuncCount {
+			columns[p.Name()] = true
+		}
+	}
+
+	return columns
+}
+
+func (g *Grouper) build(grp *group) ([]byte, error) {
+	keyIdx := make(map[string]int, len(g.query.GroupBy))
+	for i, field := range g.query.GroupBy {
+		keyIdx[field] = i
+	}
+
+	var (
+		buf    bytes.Buffer
+		accIdx int
+	)
+	_ = buf.WriteByte('{')
+	for i, p := range g.query.Projections {
+		if i > 0 {
+			_ = buf.WriteByte(',')
+		}
+
+		var value jsoniter.RawMessage
+		if len(p.Func) > 0 {
+			value = grp.accumulators[accIdx].result()
+			accIdx++
+		} else {
+			value = grp.keys[keyIdx[p.Field]]
+		}
+
+		key, err := jsoniter.Marshal(p.Name())
+		if err != nil {
+			return nil, err
+		}
+		_, _ = buf.Write(key)
+		_ = buf.WriteByte(':')
+		_, _ = buf.Write(value)
+	}
+	_ = buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+type accumulator struct {
+	fn    string
+	field string
+
+	count    int64
+	sum      float64
+	intSum   int64
+	floatSum bool
+	value    jsoniter.RawMessage
+
+	decimal    bool
+	scale      int32
+	decimalSum decimal.Decimal
+}
+
+func (a *accumulator) add(document []byte) error {
+	if a.field == "*" {
+		a.count++
+		return nil
+	}
+
+	value, dataType, _, err := jsonparser.Get(document, strings.Split(a.field, ".")...)
+	if err == jsonparser.KeyPathNotFoundError || dataType == jsonparser.Null {
+		// like SQL, missing and null values are ignored by the aggregate functions
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if a.decimal && a.fn != FuncCount {
+		return a.addDecimal(value)
+	}
+
+	switch a.fn {
+	case FuncCount:
+		a.count++
+	case FuncSum, FuncAvg:
+		if dataType != jsonparser.Number {
+			return errors.InvalidArgument("%s is only supported on numeric fields, '%s' is not a number", a.fn, a.field)
+		}
+		a.count++
+		return a.addNumber(value)
+	case FuncMin, FuncMax:
+		if dataType == jsonparser.String {
+			value = jsonString(value)
+		}
+		if a.value == nil {
+			a.value = value
+			return nil
+		}
+		c := compareValues(value, a.value)
+		if (a.fn == FuncMin && c < 0) || (a.fn == FuncMax && c > 0) {
+			a.value = value
+		}
+	}
+
+	return nil
+}
+
+func (a *accumulator) addDecimal(value []byte) error {
+	d, err := decimal.Parse(string(value))
+	if err != nil {
+		return errors.InvalidArgument("'%s' of field '%s' is not a valid decimal", value, a.field)
+	}
+
+	switch a.fn {
+	case FuncSum, FuncAvg:
+		if a.count == 0 {
+			a.decimalSum = d
+		} else {
+			a.decimalSum = a.decimalSum.Add(d)
+		}
+		a.count++
+	case FuncMin, FuncMax:
+		value = jsonString(value)
+		if a.value == nil {
+			a.value = value
+			return nil
+		}
+		c := compareDecimals(value, a.value)
+		if (a.fn == FuncMin && c < 0) || (a.fn == FuncMax && c > 0) {
+			a.value = value
+		}
+	}
+
+	return nil
+}
+
+// addNumber keeps the sum as an integer as long as all the values are integers, so that the sum of an integer field
+// is returned as an integer. An integer SUM that overflows is an error, whereas AVG continues with the float sum as
+// its result is a float anyway.
+func (a *accumulator) addNumber(value []byte) error {
+	if !a.floatSum {
+		if i, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+			sum := a.intSum + i
+			if (i > 0 && sum < a.intSum) || (i < 0 && sum > a.intSum) {
+				if a.fn == FuncSum {
+					return errors.InvalidArgument("SUM of field '%s' overflows the 64-bit integer range", a.field)
+				}
+				a.floatSum = true
+				a.sum = float64(a.intSum) + float64(i)
+				return nil
+			}
+			a.intSum = sum
+			return nil
+		}
+		a.floatSum = true
+		a.sum = float64(a.intSum)
+	}
+
+	f, _ := strconv.ParseFloat(string(value), 64)
+	a.sum += f
+
+	return nil
+}
+
+func (a *accumulator) result() jsoniter.RawMessage {
+	if a.decimal {
+		switch a.fn {
+		case FuncSum:
+			if a.count == 0 {
+				return jsoniter.RawMessage("null")
+			}
+			return jsonString([]byte(a.decimalSum.Round(a.scale).String()))
+		case FuncAvg:
+			if a.count == 0 {
+				return jsoniter.RawMessage("null")
+			}
+			// the average is rounded to the scale of the field
+			avg, _ := a.decimalSum.Quo(decimal.New(a.count, 0), a.scale)
+			return jsonString([]byte(avg.String()))
+		}
+	}
+
+	switch a.fn {
+	case FuncCount:
+		return jsoniter.RawMessage(strconv.FormatInt(a.count, 10))
+	case FuncSum:
+		if a.count == 0 {
+			return jsoniter.RawMessage("null")
+		}
+		if !a.floatSum {
+			return jsoniter.RawMessage(strconv.FormatInt(a.intSum, 10))
+		}
+		return jsoniter.RawMessage(strconv.FormatFloat(a.sum, 'g', -1, 64))
+	case FuncAvg:
+		if a.count == 0 {
+			return jsoniter.RawMessage("null")
+		}
+		total := a.sum
+		if !a.floatSum {
+			total = float64(a.intSum)
+		}
+		return jsoniter.RawMessage(strconv.FormatFloat(total/float64(a.count), 'g', -1, 64))
+	default:
+		if a.value == nil {
+			return jsoniter.RawMessage("null")
+		}
+		return a.value
+	}
+}
+
+// getValue returns the value of the field as JSON, null if the field is not present in the document.
+func getValue(document []byte, field string) jsoniter.RawMessage {
+	value, dataType, _, err := jsonparser.Get(document, strings.Split(field, ".")...)
+	if err != nil {
+		return jsoniter.RawMessage("null")
+	}
+	if dataType == jsonparser.String {
+		return jsonString(value)
+	}
+
+	return value
+}
+
+// getColumn returns the value of the column of a result row. Unlike a document field, the name of the column is
+// never treated as a path.
+func getColumn(row []byte, name string) jsoniter.RawMessage {
+	value, dataType, _, err := jsonparser.Get(row, name)
+	if err != nil {
+		return jsoniter.RawMessage("null")
+	}
+	if dataType == jsonparser.String {
+		return jsonString(value)
+	}
+
+	return value
+}
+
+// jsonString adds back the quotes that are stripped by jsonparser for the string values.
+func jsonString(value []byte) jsoniter.RawMessage {
+	quoted := make([]byte, 0, len(value)+2)
+	quoted = append(quoted, '"')
+	quoted = append(quoted, value...)
+	return append(quoted, '"')
+}
+
+func isNull(value jsoniter.RawMessage) bool {
+	return bytes.Equal(value, []byte("null"))
+}
+
+// compareValues compares two JSON values. Numbers are compared numerically and strings lexicographically, null is
+// ordered after all the other values.
+func compareValues(a jsoniter.RawMessage, b jsoniter.RawMessage) int {
+	aNull, bNull := isNull(a), isNull(b)
+	switch {
+	case aNull && bNull:
+		return 0
+	case aNull:
+		return 1
+	case bNull:
+		return -1
+	}
+
+	// integers are compared exactly, the float64 conversion loses the precision beyond 2^53
+	ai, aErr := strconv.ParseInt(string(a), 10, 64)
+	bi, bErr := strconv.ParseInt(string(b), 10, 64)
+	if aErr == nil && bErr == nil {
+		switch {
+		case ai < bi:
+			return -1
+		case ai > bi:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	af, aErr := strconv.ParseFloat(string(a), 64)
+	bf, bErr := strconv.ParseFloat(string(b), 64)
+	if aErr == nil && bErr == nil {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	if len(a) > 0 && a[0] == '"' && len(b) > 0 && b[0] == '"' {
+		var as, bs string
+		if jsoniter.Unmarshal(a, &as) == nil && jsoniter.Unmarshal(b, &bs) == nil {
+			return strings.Compare(as, bs)
+		}
+	}
+
+	return bytes.Compare(a, b)
+}
+
+// compareDecimals compares two decimal values, null is ordered after all the other values like in compareValues.
+func compareDecimals(a jsoniter.RawMessage, b jsoniter.RawMessage) int {
+	var as, bs string
+	if jsoniter.Unmarshal(a, &as) != nil || jsoniter.Unmarshal(b, &bs) != nil {
+		return compareValues(a, b)
+	}
+
+	ad, aErr := decimal.Parse(as)
+	bd, bErr := decimal.Parse(bs)
+	if aErr != nil || bErr != nil {
+		return compareValues(a, b)
+	}
+
+	return ad.Cmp(bd)
+}
@@ -0,0 +1,151 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var orders = []string{
+	`{"city": "SF", "amount": 10, "item": "b"}`,
+	`{"city": "NY", "amount": 2.5, "item": "a"}`,
+	`{"city": "SF", "amount": 5, "item": "c"}`,
+	`{"city": "LA", "item": "d"}`,
+	`{"amount": 1, "item": "e"}`,
+}
+
+func runGroup(t *testing.T, query string, docs []string) []string {
+	q, err := Translate(query)
+	require.NoError(t, err)
+
+	g := NewGrouper(q)
+	for _, d := range docs {
+		require.NoError(t, g.Add([]byte(d)))
+	}
+
+	rows, err := g.Result()
+	require.NoError(t, err)
+
+	var result []string
+	for _, r := range rows {
+		result = append(result, string(r))
+	}
+	return result
+}
+
+func TestGrouper(t *testing.T) {
+	t.Run("no_group_by", func(t *testing.T) {
+		require.Equal(t, []string{
+			`{"count":5,"count_amount":4,"sum_amount":18.5,"avg_amount":4.625,"min_item":"a","max_amount":10}`,
+		}, runGroup(t, "SELECT count(*), count(amount), sum(amount), avg(amount), min(item), max(amount) FROM orders", orders))
+	})
+
+	t.Run("no_rows", func(t *testing.T) {
+		require.Equal(t, []string{
+			`{"count":0,"total":null,"min_item":null}`,
+		}, runGroup(t, "SELECT count(*), sum(amount) AS total, min(item) FROM orders", nil))
+		require.Nil(t, runGroup(t, "SELECT city, count(*) FROM orders GROUP BY city", nil))
+	})
+
+	t.Run("group_by", func(t *testing.T) {
+		require.Equal(t, []string{
+			`{"city":"SF","n":2,"total":15}`,
+			`{"city":"NY","n":1,"total":2.5}`,
+			`{"city":"LA","n":1,"total":null}`,
+			`{"city":null,"n":1,"total":1}`,
+		}, runGroup(t, "SELECT city, count(*) AS n, sum(amount) AS total FROM orders GROUP BY city", orders))
+	})
+
+	t.Run("order_limit_offset", func(t *testing.T) {
+		require.Equal(t, []string{
+			`{"city":null,"total":1}`,
+			`{"city":"LA","total":null}`,
+		}, runGroup(t, "SELECT city, sum(amount) AS total FROM orders GROUP BY city ORDER BY total DESC LIMIT 2 OFFSET 2", orders))
+		require.Equal(t, []string{
+			`{"city":"LA"}`,
+			`{"city":"NY"}`,
+			`{"city":"SF"}`,
+			`{"city":null}`,
+		}, runGroup(t, "SELECT city FROM orders GROUP BY city ORDER BY city", orders))
+		require.Nil(t, runGroup(t, "SELECT city FROM orders GROUP BY city LIMIT 1 OFFSET 10", orders))
+		require.Equal(t, []string{
+			`{"address.city":"SF","count":2}`,
+			`{"address.city":"NY","count":1}`,
+		}, runGroup(t, "SELECT address.city, count(*) FROM orders GROUP BY address.city ORDER BY count DESC", []string{
+			`{"address": {"city": "NY"}}`,
+			`{"address": {"city": "SF"}}`,
+			`{"address": {"city": "SF"}}`,
+		}))
+	})
+
+	t.Run("decimal", func(t *testing.T) {
+		ledger := []string{
+			`{"account": "a", "amount": "0.10"}`,
+			`{"account": "a", "amount": "0.20"}`,
+			`{"account": "b", "amount": "9.99"}`,
+			`{"account": "b", "amount": "10.00"}`,
+			`{"account": "b", "amount": "0.02"}`,
+			`{"account": "c"}`,
+		}
+
+		q, err := Translate("SELECT account, sum(amount) AS total, avg(amount), min(amount), max(amount), count(amount) " +
+			"FROM ledger GROUP BY account ORDER BY total DESC")
+		require.NoError(t, err)
+
+		g := NewGrouper(q).WithDecimalFields(map[string]int32{"amount": 2})
+		for _, d := range ledger {
+			require.NoError(t, g.Add([]byte(d)))
+		}
+		rows, err := g.Result()
+		require.NoError(t, err)
+
+		var result []string
+		for _, r := range rows {
+			result = append(result, string(r))
+		}
+		require.Equal(t, []string{
+			`{"account":"b","total":"20.01","avg_amount":"6.67","min_amount":"0.02","max_amount":"10.00","count_amount":3}`,
+			`{"account":"a","total":"0.30","avg_amount":"0.15","min_amount":"0.10","max_amount":"0.20","count_amount":2}`,
+			`{"account":"c","total":null,"avg_amount":null,"min_amount":null,"max_amount":null,"count_amount":0}`,
+		}, result)
+
+		require.ErrorContains(t, g.Add([]byte(`{"account": "a", "amount": "abc"}`)), "is not a valid decimal")
+	})
+
+	t.Run("int64", func(t *testing.T) {
+		large := []string{
+			`{"id": 9007199254740993}`,
+			`{"id": 9007199254740992}`,
+			`{"id": 9007199254740994}`,
+		}
+		require.Equal(t, []string{
+			`{"min_id":9007199254740992,"max_id":9007199254740994,"sum_id":27021597764222979}`,
+		}, runGroup(t, "SELECT min(id), max(id), sum(id) FROM orders", large))
+
+		q, err := Translate("SELECT sum(id), avg(id) FROM orders")
+		require.NoError(t, err)
+		g := NewGrouper(q)
+		require.NoError(t, g.Add([]byte(`{"id": 9223372036854775807}`)))
+		require.ErrorContains(t, g.Add([]byte(`{"id": 1}`)), "SUM of field 'id' overflows the 64-bit integer range")
+	})
+
+	t.Run("non_numeric_sum", func(t *testing.T) {
+		q, err := Translate("SELECT sum(item) FROM orders")
+		require.NoError(t, err)
+		require.ErrorContains(t, NewGrouper(q).Add([]byte(orders[0])), "SUM is only supported on numeric fields")
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/tigrisdata/tigris/errors"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdent
+	tokenKeyword
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenComma
+	tokenLParen
+	tokenRParen
+	tokenStar
+)
+
+var keywords = map[string]struct{}{
+	"SELECT": {},
+	"FROM":   {},
+	"WHERE":  {},
+	"AND":    {},
+	"OR":     {},
+	"NOT":    {},
+	"IN":     {},
+	"LIKE":   {},
+	"AS":     {},
+	"GROUP":  {},
+	"ORDER":  {},
+	"BY":     {},
+	"ASC":    {},
+	"DESC":   {},
+	"LIMIT":  {},
+	"OFFSET": {},
+	"TRUE":   {},
+	"FALSE":  {},
+	"NULL":   {},
+}
+
+type token struct {
+	typ tokenType
+	// value is the upper-cased keyword, the unquoted identifier or string, or the raw number/operator.
+	value string
+	pos   int
+}
+
+// tokenize splits the query into tokens. Identifiers can be quoted using double quotes or backticks when they
+// clash with a keyword, strings are single-quoted and a single quote is escaped by doubling it.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == ',':
+			tokens = append(tokens, token{typ: tokenComma, value: ",", pos: i})
+			i++
+		case r == '(':
+			tokens = append(tokens, token{typ: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{typ: tokenRParen, value: ")", pos: i})
+			i++
+		case r == '*':
+			tokens = append(tokens, token{typ: tokenStar, value: "*", pos: i})
+			i++
+		case r == ';':
+			// a trailing semicolon is allowed, anything after it is not
+			for j := i + 1; j < len(runes); j++ {
+				if !unicode.IsSpace(runes[j]) {
+					return nil, errors.InvalidArgument("only a single statement is allowed in a query")
+				}
+			}
+			i = len(runes)
+		case r == '=' || r == '<' || r == '>' || r == '!':
+			start := i
+			i++
+			if i < len(runes) && (runes[i] == '=' || (r == '<' && runes[i] == '>')) {
+				i++
+			}
+			op := string(runes[start:i])
+			if op == "!" {
+				return nil, errors.InvalidArgument("unexpected character '!' at position %d", start)
+			}
+			tokens = append(tokens, token{typ: tokenOperator, value: op, pos: start})
+		case r == '\'':
+			start := i
+			var sb strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						_, _ = sb.WriteRune('\'')
+						i++
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				_, _ = sb.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, errors.InvalidArgument("unterminated string starting at position %d", start)
+			}
+			tokens = append(tokens, token{typ: tokenString, value: sb.String(), pos: start})
+		case r == '"' || r == '`':
+			start := i
+			end := -1
+			for j := i + 1; j < len(runes); j++ {
+				if runes[j] == r {
+					end = j
+					break
+				}
+			}
+			if end <= start+1 {
+				return nil, errors.InvalidArgument("invalid quoted identifier at position %d", start)
+			}
+			tokens = append(tokens, token{typ: tokenIdent, value: string(runes[start+1 : end]), pos: start})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.')) || r == '.':
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				((runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, token{typ: tokenNumber, value: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(r) || r == '_' || r == '$':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '$' || runes[i] == '.') {
+				i++
+			}
+			word := string(runes[start:i])
+			if _, ok := keywords[strings.ToUpper(word)]; ok {
+				tokens = append(tokens, token{typ: tokenKeyword, value: strings.ToUpper(word), pos: start})
+			} else {
+				tokens = append(tokens, token{typ: tokenIdent, value: word, pos: start})
+			}
+		default:
+			return nil, errors.InvalidArgument("unexpected character '%c' at position %d", r, i)
+		}
+	}
+
+	return append(tokens, token{typ: tokenEOF, pos: len(runes)}), nil
+}
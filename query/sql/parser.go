@@ -0,0 +1,495 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+// Supported aggregate functions.
+const (
+	FuncCount = "COUNT"
+	FuncSum   = "SUM"
+	FuncAvg   = "AVG"
+	FuncMin   = "MIN"
+	FuncMax   = "MAX"
+)
+
+// Statement is a parsed SELECT statement.
+type Statement struct {
+	Projections []*Projection
+	From        string
+	Where       Expr
+	GroupBy     []string
+	OrderBy     []*OrderBy
+	Limit       int64
+	Offset      int64
+}
+
+// Projection is a single entry of the select list. Either Star is set, or Field is set with an optional aggregate
+// function. Field is "*" for COUNT(*).
+type Projection struct {
+	Star  bool
+	Func  string
+	Field string
+	Alias string
+}
+
+// Name returns the key under which the projection is returned.
+func (p *Projection) Name() string {
+	switch {
+	case len(p.Alias) > 0:
+		return p.Alias
+	case len(p.Func) == 0:
+		return p.Field
+	case p.Field == "*":
+		return strings.ToLower(p.Func)
+	default:
+		return strings.ToLower(p.Func) + "_" + strings.ReplaceAll(p.Field, ".", "_")
+	}
+}
+
+type OrderBy struct {
+	Field     string
+	Ascending bool
+}
+
+// Expr is a node of the WHERE clause.
+type Expr interface {
+	expr()
+}
+
+// LogicalExpr is an AND/OR of two or more expressions.
+type LogicalExpr struct {
+	Op    string
+	Exprs []Expr
+}
+
+// ComparisonExpr compares a field with a literal using one of =, !=, <, <=, >, >=.
+type ComparisonExpr struct {
+	Field string
+	Op    string
+	Value jsoniter.RawMessage
+}
+
+// InExpr is "field [NOT] IN (v1, v2, ...)".
+type InExpr struct {
+	Field  string
+	Values []jsoniter.RawMessage
+	Not    bool
+}
+
+// LikeExpr is "field [NOT] LIKE 'pattern'".
+type LikeExpr struct {
+	Field   string
+	Pattern string
+	Not     bool
+}
+
+func (*LogicalExpr) expr()    {}
+func (*ComparisonExpr) expr() {}
+func (*InExpr) expr()         {}
+func (*LikeExpr) expr()       {}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a single SELECT statement. The supported grammar is,
+//
+//	SELECT <* | projection [, projection ...]> FROM <collection>
+//	  [WHERE <condition>]
+//	  [GROUP BY field [, field ...]]
+//	  [ORDER BY field [ASC | DESC] [, ...]]
+//	  [LIMIT n [OFFSET m]]
+//
+// where a projection is either a field or one of COUNT/SUM/AVG/MIN/MAX with an optional alias.
+func Parse(query string) (*Statement, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().typ != tokenEOF {
+		return nil, p.unexpected()
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.typ == tokenKeyword && t.value == keyword
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.expected(keyword)
+	}
+	return nil
+}
+
+func (p *parser) expect(typ tokenType, what string) (token, error) {
+	if p.peek().typ != typ {
+		return token{}, p.expected(what)
+	}
+	return p.next(), nil
+}
+
+func (p *parser) expected(what string) error {
+	t := p.peek()
+	if t.typ == tokenEOF {
+		return errors.InvalidArgument("expected %s but reached the end of the query", what)
+	}
+	return errors.InvalidArgument("expected %s at position %d but found '%s'", what, t.pos, t.value)
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	return errors.InvalidArgument("unexpected '%s' at position %d", t.value, t.pos)
+}
+
+func (p *parser) parseSelect() (*Statement, error) {
+	var err error
+	if err = p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+
+	stmt := &Statement{}
+	if stmt.Projections, err = p.parseProjections(); err != nil {
+		return nil, err
+	}
+
+	if err = p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	from, err := p.expect(tokenIdent, "collection name")
+	if err != nil {
+		return nil, err
+	}
+	stmt.From = from.value
+
+	if p.acceptKeyword("WHERE") {
+		if stmt.Where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err = p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			field, err := p.expect(tokenIdent, "field name")
+			if err != nil {
+				return nil, err
+			}
+			stmt.GroupBy = append(stmt.GroupBy, field.value)
+			if p.peek().typ != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err = p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			field, err := p.expect(tokenIdent, "field name")
+			if err != nil {
+				return nil, err
+			}
+			order := &OrderBy{Field: field.value, Ascending: true}
+			if p.acceptKeyword("DESC") {
+				order.Ascending = false
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			stmt.OrderBy = append(stmt.OrderBy, order)
+			if p.peek().typ != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		if stmt.Limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+		if p.acceptKeyword("OFFSET") {
+			if stmt.Offset, err = p.parseCount("OFFSET"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return stmt, nil
+}
+
+func (p *parser) parseCount(clause string) (int64, error) {
+	t, err := p.expect(tokenNumber, "number")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseInt(t.value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, errors.InvalidArgument("%s needs to be a non-negative integer", clause)
+	}
+	return n, nil
+}
+
+func (p *parser) parseProjections() ([]*Projection, error) {
+	if p.peek().typ == tokenStar {
+		p.next()
+		return []*Projection{{Star: true}}, nil
+	}
+
+	var projections []*Projection
+	for {
+		proj, err := p.parseProjection()
+		if err != nil {
+			return nil, err
+		}
+		projections = append(projections, proj)
+
+		if p.peek().typ != tokenComma {
+			return projections, nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseProjection() (*Projection, error) {
+	t, err := p.expect(tokenIdent, "field name or aggregate function")
+	if err != nil {
+		return nil, err
+	}
+
+	proj := &Projection{Field: t.value}
+	if p.peek().typ == tokenLParen {
+		p.next()
+		proj.Func = strings.ToUpper(t.value)
+		switch proj.Func {
+		case FuncCount, FuncSum, FuncAvg, FuncMin, FuncMax:
+		default:
+			return nil, errors.InvalidArgument("unsupported function '%s'", t.value)
+		}
+
+		switch p.peek().typ {
+		case tokenStar:
+			if proj.Func != FuncCount {
+				return nil, errors.InvalidArgument("'*' is only allowed in COUNT")
+			}
+			p.next()
+			proj.Field = "*"
+		case tokenIdent:
+			proj.Field = p.next().value
+		default:
+			return nil, p.expected("field name")
+		}
+
+		if _, err = p.expect(tokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("AS") {
+		alias, err := p.expect(tokenIdent, "alias")
+		if err != nil {
+			return nil, err
+		}
+		proj.Alias = alias.value
+	}
+
+	return proj, nil
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	return p.parseLogical("OR", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	return p.parseLogical("AND", p.parsePrimary)
+}
+
+func (p *parser) parseLogical(op string, operand func() (Expr, error)) (Expr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []Expr{first}
+	for p.acceptKeyword(op) {
+		e, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+
+	if len(exprs) == 1 {
+		return first, nil
+	}
+	return &LogicalExpr{Op: op, Exprs: exprs}, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	if p.peek().typ == tokenLParen {
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = p.expect(tokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+
+	field, err := p.expect(tokenIdent, "field name")
+	if err != nil {
+		return nil, err
+	}
+
+	not := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("IN"):
+		return p.parseIn(field.value, not)
+	case p.acceptKeyword("LIKE"):
+		pattern, err := p.expect(tokenString, "pattern")
+		if err != nil {
+			return nil, err
+		}
+		return &LikeExpr{Field: field.value, Pattern: pattern.value, Not: not}, nil
+	case not:
+		return nil, p.expected("IN or LIKE")
+	}
+
+	op, err := p.expect(tokenOperator, "comparison operator")
+	if err != nil {
+		return nil, err
+	}
+	if op.value == "<>" {
+		op.value = "!="
+	}
+
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ComparisonExpr{Field: field.value, Op: op.value, Value: value}, nil
+}
+
+func (p *parser) parseIn(field string, not bool) (Expr, error) {
+	if _, err := p.expect(tokenLParen, "'('"); err != nil {
+		return nil, err
+	}
+
+	in := &InExpr{Field: field, Not: not}
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		in.Values = append(in.Values, value)
+
+		if p.peek().typ != tokenComma {
+			break
+		}
+		p.next()
+	}
+
+	if _, err := p.expect(tokenRParen, "')'"); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// parseLiteral returns the literal as a JSON value so that it can be used as is in the filter.
+func (p *parser) parseLiteral() (jsoniter.RawMessage, error) {
+	t := p.peek()
+	switch t.typ {
+	case tokenString:
+		p.next()
+		return jsoniter.Marshal(t.value)
+	case tokenNumber:
+		p.next()
+		if _, err := strconv.ParseFloat(t.value, 64); err != nil {
+			return nil, errors.InvalidArgument("invalid number '%s' at position %d", t.value, t.pos)
+		}
+		return normalizeNumber(t.value), nil
+	case tokenKeyword:
+		if t.value == "TRUE" || t.value == "FALSE" || t.value == "NULL" {
+			p.next()
+			return jsoniter.RawMessage(strings.ToLower(t.value)), nil
+		}
+	}
+
+	return nil, p.expected("literal value")
+}
+
+// normalizeNumber converts the SQL number to a valid JSON number i.e. ".5" to "0.5" and "1." to "1.0".
+func normalizeNumber(n string) jsoniter.RawMessage {
+	neg := strings.HasPrefix(n, "-")
+	n = strings.TrimPrefix(n, "-")
+	if strings.HasPrefix(n, ".") {
+		n = "0" + n
+	}
+	n = strings.Replace(n, ".e", ".0e", 1)
+	n = strings.Replace(n, ".E", ".0E", 1)
+	if strings.HasSuffix(n, ".") {
+		n += "0"
+	}
+	if neg {
+		n = "-" + n
+	}
+
+	return jsoniter.RawMessage(n)
+}
@@ -0,0 +1,345 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/query/filter"
+	"github.com/tigrisdata/tigris/query/sort"
+)
+
+// Query is the SQL statement translated to the Tigris read request. The Filter, Fields and Sort are in the same
+// format as the corresponding fields of the read request, so the query is planned and executed the same way as a
+// read. In case of an aggregate query, the Sort and the Limit/Offset are applied on the groups by the Grouper
+// instead of being part of the read.
+type Query struct {
+	Collection  string
+	Filter      jsoniter.RawMessage
+	Fields      jsoniter.RawMessage
+	Sort        jsoniter.RawMessage
+	Limit       int64
+	Offset      int64
+	GroupBy     []string
+	Projections []*Projection
+	OrderBy     []*OrderBy
+}
+
+// Translate parses the SQL query and translates it to the Tigris query.
+func Translate(query string) (*Query, error) {
+	stmt, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compile(stmt)
+}
+
+// Compile translates the parsed statement to the Tigris query.
+func Compile(stmt *Statement) (*Query, error) {
+	q := &Query{
+		Collection:  stmt.From,
+		Limit:       stmt.Limit,
+		Offset:      stmt.Offset,
+		GroupBy:     stmt.GroupBy,
+		Projections: stmt.Projections,
+		OrderBy:     stmt.OrderBy,
+	}
+
+	var err error
+	if stmt.Where != nil {
+		if q.Filter, err = compileExpr(stmt.Where); err != nil {
+			return nil, err
+		}
+	}
+
+	if q.IsAggregate() {
+		if err = q.validateAggregate(); err != nil {
+			return nil, err
+		}
+		q.Fields = q.aggregateFields()
+		return q, nil
+	}
+
+	if q.Fields, err = q.projectionFields(); err != nil {
+		return nil, err
+	}
+
+	if len(stmt.OrderBy) > 0 {
+		var buf bytes.Buffer
+		_ = buf.WriteByte('[')
+		for i, o := range stmt.OrderBy {
+			if i > 0 {
+				_ = buf.WriteByte(',')
+			}
+			order := sort.ASC
+			if !o.Ascending {
+				order = sort.DESC
+			}
+			writeKey(&buf, o.Field)
+			_, _ = buf.WriteString(`"` + order + `"}`)
+		}
+		_ = buf.WriteByte(']')
+		q.Sort = buf.Bytes()
+	}
+
+	return q, nil
+}
+
+// IsAggregate returns true if the query has GROUP BY or any of the projections is an aggregate function.
+func (q *Query) IsAggregate() bool {
+	if len(q.GroupBy) > 0 {
+		return true
+	}
+	for _, p := range q.Projections {
+		if len(p.Func) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (q *Query) validateAggregate() error {
+	grouped := make(map[string]struct{}, len(q.GroupBy))
+	for _, g := range q.GroupBy {
+		grouped[g] = struct{}{}
+	}
+
+	names := make(map[string]struct{}, len(q.Projections))
+	for _, p := range q.Projections {
+		if p.Star {
+			return errors.InvalidArgument("'*' can't be selected in an aggregate query")
+		}
+		if _, ok := grouped[p.Field]; len(p.Func) == 0 && !ok {
+			return errors.InvalidArgument("field '%s' needs to be in GROUP BY or used in an aggregate function", p.Field)
+		}
+		if _, ok := names[p.Name()]; ok {
+			return errors.InvalidArgument("duplicate column '%s' in the select list", p.Name())
+		}
+		names[p.Name()] = struct{}{}
+	}
+
+	for _, o := range q.OrderBy {
+		if _, ok := names[o.Field]; !ok {
+			return errors.InvalidArgument("ORDER BY '%s' needs to refer to a column of the select list", o.Field)
+		}
+	}
+
+	return nil
+}
+
+// aggregateFields returns the fields that need to be read for the aggregate query, this is the group by fields
+// along with the fields used by the aggregate functions.
+func (q *Query) aggregateFields() jsoniter.RawMessage {
+	var fields []string
+	seen := make(map[string]struct{})
+	add := func(f string) {
+		if _, ok := seen[f]; !ok && f != "*" {
+			seen[f] = struct{}{}
+			fields = append(fields, f)
+		}
+	}
+
+	for _, g := range q.GroupBy {
+		add(g)
+	}
+	for _, p := range q.Projections {
+		add(p.Field)
+	}
+
+	return includeFields(fields)
+}
+
+func (q *Query) projectionFields() (jsoniter.RawMessage, error) {
+	var fields []string
+	for _, p := range q.Projections {
+		if p.Star {
+			return nil, nil
+		}
+		if len(p.Alias) > 0 && p.Alias != p.Field {
+			return nil, errors.InvalidArgument("alias is only supported for aggregate functions")
+		}
+		fields = append(fields, p.Field)
+	}
+
+	return includeFields(fields), nil
+}
+
+func includeFields(fields []string) jsoniter.RawMessage {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	_ = buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			_ = buf.WriteByte(',')
+		}
+		key, _ := jsoniter.Marshal(f)
+		_, _ = buf.Write(key)
+		_, _ = buf.WriteString(`:true`)
+	}
+	_ = buf.WriteByte('}')
+
+	return buf.Bytes()
+}
+
+func compileExpr(e Expr) (jsoniter.RawMessage, error) {
+	switch e := e.(type) {
+	case *LogicalExpr:
+		exprs := make([]jsoniter.RawMessage, 0, len(e.Exprs))
+		for _, child := range e.Exprs {
+			compiled, err := compileExpr(child)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, compiled)
+		}
+		if e.Op == "AND" {
+			return logical(filter.AndOP, exprs), nil
+		}
+		return logical(filter.OrOP, exprs), nil
+	case *ComparisonExpr:
+		return compileComparison(e.Field, e.Op, e.Value)
+	case *InExpr:
+		exprs := make([]jsoniter.RawMessage, 0, len(e.Values))
+		for _, v := range e.Values {
+			op := "="
+			if e.Not {
+				op = "!="
+			}
+			compiled, err := compileComparison(e.Field, op, v)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, compiled)
+		}
+		if e.Not {
+			return logical(filter.AndOP, exprs), nil
+		}
+		return logical(filter.OrOP, exprs), nil
+	case *LikeExpr:
+		return compileLike(e)
+	default:
+		return nil, errors.InvalidArgument("unsupported expression")
+	}
+}
+
+func compileComparison(field string, op string, value jsoniter.RawMessage) (jsoniter.RawMessage, error) {
+	switch op {
+	case "=":
+		return selector(field, filter.EQ, value), nil
+	case "<":
+		return selector(field, filter.LT, value), nil
+	case "<=":
+		return selector(field, filter.LTE, value), nil
+	case ">":
+		return selector(field, filter.GT, value), nil
+	case ">=":
+		return selector(field, filter.GTE, value), nil
+	case "!=":
+		return selector(field, filter.NE, value), nil
+	default:
+		return nil, errors.InvalidArgument("unsupported operator '%s'", op)
+	}
+}
+
+// compileLike converts the LIKE pattern to the filter. A pattern of the form '%text%' is converted to "$contains"
+// and its negation to "$not", any other pattern is converted to an anchored "$regex".
+func compileLike(e *LikeExpr) (jsoniter.RawMessage, error) {
+	if inner, ok := containsPattern(e.Pattern); ok {
+		value, _ := jsoniter.Marshal(inner)
+		if e.Not {
+			return selector(e.Field, filter.NOT, value), nil
+		}
+		return selector(e.Field, filter.CONTAINS, value), nil
+	}
+
+	if e.Not {
+		return nil, errors.InvalidArgument("NOT LIKE is only supported for patterns of the form '%%text%%'")
+	}
+
+	var sb strings.Builder
+	_ = sb.WriteByte('^')
+	for _, r := range e.Pattern {
+		switch r {
+		case '%':
+			_, _ = sb.WriteString(".*")
+		case '_':
+			_ = sb.WriteByte('.')
+		default:
+			_, _ = sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	_ = sb.WriteByte('$')
+
+	value, _ := jsoniter.Marshal(sb.String())
+	return selector(e.Field, filter.REGEX, value), nil
+}
+
+func containsPattern(pattern string) (string, bool) {
+	if len(pattern) < 3 || !strings.HasPrefix(pattern, "%") || !strings.HasSuffix(pattern, "%") {
+		return "", false
+	}
+
+	inner := pattern[1 : len(pattern)-1]
+	if strings.ContainsAny(inner, "%_") {
+		return "", false
+	}
+
+	return inner, true
+}
+
+func selector(field string, op string, value jsoniter.RawMessage) jsoniter.RawMessage {
+	var buf bytes.Buffer
+	writeKey(&buf, field)
+	_, _ = buf.WriteString(`{"` + op + `":`)
+	_, _ = buf.Write(value)
+	_, _ = buf.WriteString(`}}`)
+
+	return buf.Bytes()
+}
+
+func logical(op filter.LogicalOP, exprs []jsoniter.RawMessage) jsoniter.RawMessage {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+
+	var buf bytes.Buffer
+	_, _ = buf.WriteString(`{"` + string(op) + `":[`)
+	for i, e := range exprs {
+		if i > 0 {
+			_ = buf.WriteByte(',')
+		}
+		_, _ = buf.Write(e)
+	}
+	_, _ = buf.WriteString(`]}`)
+
+	return buf.Bytes()
+}
+
+// writeKey writes the opening of an object with the field as the key i.e. {"field":.
+func writeKey(buf *bytes.Buffer, field string) {
+	key, _ := jsoniter.Marshal(field)
+	_ = buf.WriteByte('{')
+	_, _ = buf.Write(key)
+	_ = buf.WriteByte(':')
+}
@@ -0,0 +1,122 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslate(t *testing.T) {
+	cases := []struct {
+		query  string
+		filter string
+		fields string
+		sort   string
+		limit  int64
+		offset int64
+	}{
+		{
+			"SELECT * FROM users",
+			``, ``, ``, 0, 0,
+		}, {
+			"select name, age from users where age >= 18;",
+			`{"age":{"$gte":18}}`, `{"name":true,"age":true}`, ``, 0, 0,
+		}, {
+			"SELECT * FROM users WHERE name = 'O''Brien' AND (age < 10 OR age > 60)",
+			`{"$and":[{"name":{"$eq":"O'Brien"}},{"$or":[{"age":{"$lt":10}},{"age":{"$gt":60}}]}]}`, ``, ``, 0, 0,
+		}, {
+			"SELECT * FROM users WHERE status IN ('active', 'new')",
+			`{"$or":[{"status":{"$eq":"active"}},{"status":{"$eq":"new"}}]}`, ``, ``, 0, 0,
+		}, {
+			"SELECT * FROM users WHERE status IN ('active')",
+			`{"status":{"$eq":"active"}}`, ``, ``, 0, 0,
+		}, {
+			"SELECT * FROM users WHERE age <> 5",
+			`{"age":{"$ne":5}}`, ``, ``, 0, 0,
+		}, {
+			"SELECT * FROM users WHERE status NOT IN ('active', 'new')",
+			`{"$and":[{"status":{"$ne":"active"}},{"status":{"$ne":"new"}}]}`, ``, ``, 0, 0,
+		}, {
+			"SELECT * FROM users WHERE name LIKE '%bob%' AND email NOT LIKE '%spam%'",
+			`{"$and":[{"name":{"$contains":"bob"}},{"email":{"$not":"spam"}}]}`, ``, ``, 0, 0,
+		}, {
+			"SELECT * FROM users WHERE name LIKE 'b_b%.com'",
+			`{"name":{"$regex":"^b.b.*\\.com$"}}`, ``, ``, 0, 0,
+		}, {
+			"SELECT * FROM users WHERE address.city = 'SF' AND active = true AND score > -.5",
+			`{"$and":[{"address.city":{"$eq":"SF"}},{"active":{"$eq":true}},{"score":{"$gt":-0.5}}]}`, ``, ``, 0, 0,
+		}, {
+			"SELECT id FROM users ORDER BY age DESC, name LIMIT 10 OFFSET 20",
+			``, `{"id":true}`, `[{"age":"$desc"},{"name":"$asc"}]`, 10, 20,
+		}, {
+			"SELECT city, count(*), SUM(amount) AS total FROM orders GROUP BY city",
+			``, `{"city":true,"amount":true}`, ``, 0, 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			q, err := Translate(c.query)
+			require.NoError(t, err)
+			require.Equal(t, c.filter, string(q.Filter))
+			require.Equal(t, c.fields, string(q.Fields))
+			require.Equal(t, c.sort, string(q.Sort))
+			require.Equal(t, c.limit, q.Limit)
+			require.Equal(t, c.offset, q.Offset)
+		})
+	}
+}
+
+func TestTranslateErrors(t *testing.T) {
+	cases := []struct {
+		query string
+		err   string
+	}{
+		{"DELETE FROM users", "expected SELECT at position 0 but found 'DELETE'"},
+		{"SELECT * FROM", "expected collection name but reached the end of the query"},
+		{"SELECT * FROM users WHERE", "expected field name but reached the end of the query"},
+		{"SELECT * FROM users WHERE a = b", "expected literal value at position 30 but found 'b'"},
+		{"SELECT * FROM users WHERE a = 'x", "unterminated string starting at position 30"},
+		{"SELECT * FROM users LIMIT -1", "LIMIT needs to be a non-negative integer"},
+		{"SELECT * FROM users; SELECT * FROM orders", "only a single statement is allowed in a query"},
+		{"SELECT * FROM users WHERE a NOT LIKE 'x%'", "NOT LIKE is only supported for patterns of the form '%text%'"},
+		{"SELECT upper(name) FROM users", "unsupported function 'upper'"},
+		{"SELECT sum(*) FROM users", "'*' is only allowed in COUNT"},
+		{"SELECT name AS n FROM users", "alias is only supported for aggregate functions"},
+		{"SELECT name, count(*) FROM users", "field 'name' needs to be in GROUP BY or used in an aggregate function"},
+		{"SELECT * FROM users GROUP BY name", "'*' can't be selected in an aggregate query"},
+		{"SELECT city, count(*) FROM users GROUP BY city ORDER BY age", "ORDER BY 'age' needs to refer to a column of the select list"},
+		{"SELECT count(*), count(*) FROM users", "duplicate column 'count' in the select list"},
+		{"SELECT * FROM users ORDER BY", "expected field name but reached the end of the query"},
+		{"SELECT * FROM users extra", "unexpected 'extra' at position 20"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			_, err := Translate(c.query)
+			require.ErrorContains(t, err, c.err)
+		})
+	}
+}
+
+func TestQuotedIdentifiers(t *testing.T) {
+	q, err := Translate("SELECT \"order\", `select` FROM \"my-coll\" WHERE \"limit\" = 1")
+	require.NoError(t, err)
+	require.Equal(t, "my-coll", q.Collection)
+	require.Equal(t, `{"limit":{"$eq":1}}`, string(q.Filter))
+	require.Equal(t, `{"order":true,"select":true}`, string(q.Fields))
+}
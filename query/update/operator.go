@@ -22,6 +22,7 @@ import (
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/decimal"
 	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/util/log"
 )
@@ -265,7 +266,8 @@ func (factory *FieldOperatorFactory) buildKeysForObjectsInternal(parent string,
 
 func (*FieldOperatorFactory) atomicOperations(collection *schema.DefaultCollection, existingDoc jsoniter.RawMessage, operator *FieldOperator) (jsoniter.RawMessage, bool, error) {
 	var output []byte = existingDoc
-	var atomicInput map[string]float64
+	// the values are parsed later based on the type of the field so that the decimal fields are not rounded to float
+	var atomicInput map[string]jsoniter.RawMessage
 	if err := jsoniter.Unmarshal(operator.Input, &atomicInput); err != nil {
 		return nil, false, errors.InvalidArgument("invalid input '%s'", string(operator.Input))
 	}
@@ -291,7 +293,16 @@ func (*FieldOperatorFactory) atomicOperations(collection *schema.DefaultCollecti
 			primaryKeyMutation = isPrimaryKeyMutation(collection, keys[0])
 		}
 
-		newValue, err := operator.apply(field.DataType, existingVal, value)
+		var newValue []byte
+		if field.DataType == schema.DecimalType {
+			newValue, err = operator.applyDecimal(field, existingVal, value)
+		} else {
+			var inputValue float64
+			if err = jsoniter.Unmarshal(value, &inputValue); err != nil {
+				return nil, false, errors.InvalidArgument("invalid input '%s'", string(operator.Input))
+			}
+			newValue, err = operator.apply(field.DataType, existingVal, inputValue)
+		}
 		if err != nil {
 			return nil, false, err
 		}
@@ -368,3 +379,46 @@ func (operator *FieldOperator) apply(fieldType schema.FieldType, existingVal []b
 
 	return nil, errors.InvalidArgument("field type '%s' not supporting atomic operation", schema.FieldNames[fieldType])
 }
+
+// applyDecimal applies the operator using the exact decimal arithmetic. The input can be either a number or a string
+// and the result is rounded to the scale of the field.
+func (operator *FieldOperator) applyDecimal(field *schema.QueryableField, existingVal []byte, inputValue []byte) ([]byte, error) {
+	var raw string
+	if err := jsoniter.Unmarshal(inputValue, &raw); err != nil {
+		raw = string(inputValue)
+	}
+	input, err := decimal.Parse(raw)
+	if err != nil {
+		return nil, errors.InvalidArgument("invalid decimal input '%s' for field '%s'", raw, field.Name())
+	}
+
+	output := decimal.New(0, 0)
+	if existingVal != nil {
+		if output, err = decimal.Parse(string(existingVal)); err != nil {
+			return nil, errors.InvalidArgument("unsupported value type: '%s' is not a valid decimal", existingVal)
+		}
+	}
+
+	scale := int32(field.Scale)
+	switch operator.Op {
+	case Increment:
+		output = output.Add(input)
+	case Decrement:
+		output = output.Sub(input)
+	case Multiply:
+		output = output.Mul(input)
+	case Divide:
+		if output, err = output.Quo(input, scale); err == decimal.ErrDivisionByZero {
+			return nil, errors.InvalidArgument("division by 0 is not allowed")
+		}
+	default:
+		return nil, errors.InvalidArgument("unsupported operator '%s' for atomic operation", operator.Op)
+	}
+
+	output = output.Round(scale)
+	if err = schema.CheckDecimal(output, field.Precision, field.Scale); err != nil {
+		return nil, err
+	}
+
+	return jsoniter.Marshal(output.String())
+}
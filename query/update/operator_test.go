@@ -181,6 +181,26 @@ func TestMergeAndGet_Atomic(t *testing.T) {
 			[]byte(`{"f_32": 2, "f_num": 2}`),
 			[]byte(`{"f_32": 0, "f_num": 0.50}`),
 			Divide,
+		}, {
+			[]byte(`{"f_dec": "0.20"}`),
+			[]byte(`{"f_dec": "0.10"}`),
+			[]byte(`{"f_dec": "0.30"}`),
+			Increment,
+		}, {
+			[]byte(`{"f_dec": 0.1}`),
+			[]byte(`{"f_dec": "100"}`),
+			[]byte(`{"f_dec": "99.90"}`),
+			Decrement,
+		}, {
+			[]byte(`{"f_dec": "1.005"}`),
+			[]byte(`{"f_dec": "10.50"}`),
+			[]byte(`{"f_dec": "10.55"}`),
+			Multiply,
+		}, {
+			[]byte(`{"f_dec": "3"}`),
+			[]byte(`{"f_dec": "20.00"}`),
+			[]byte(`{"f_dec": "6.67"}`),
+			Divide,
 		},
 	}
 	for _, c := range cases {
@@ -213,6 +233,21 @@ func TestMergeAndGet_AtomicErrors(t *testing.T) {
 			[]byte(`{"f_32": 1, "f_str": "foo", "f_num": 1.01, "f_obj": {"f_64": 22}}`),
 			errors.InvalidArgument("division by 0 is not allowed"),
 			Divide,
+		}, {
+			[]byte(`{"f_dec": "0.00"}`),
+			[]byte(`{"f_dec": "1.00"}`),
+			errors.InvalidArgument("division by 0 is not allowed"),
+			Divide,
+		}, {
+			[]byte(`{"f_dec": "1000"}`),
+			[]byte(`{"f_dec": "999999.00"}`),
+			errors.InvalidArgument("'1000999.00' has more than 6 digits before the decimal point"),
+			Increment,
+		}, {
+			[]byte(`{"f_dec": "abc"}`),
+			[]byte(`{"f_dec": "1.00"}`),
+			errors.InvalidArgument("invalid decimal input 'abc' for field 'f_dec'"),
+			Increment,
 		},
 	}
 	for _, c := range cases {
@@ -548,6 +583,12 @@ func testCollection(t *testing.T) *schema.DefaultCollection {
 		"f_num": {
 			"type": "number"
 		},
+		"f_dec": {
+			"type": "string",
+			"format": "decimal",
+			"precision": 8,
+			"scale": 2
+		},
 		"f_arr": {
 			"type": "array",
 			"items": {
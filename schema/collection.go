@@ -25,7 +25,8 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/tigrisdata/tigris/errors"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+	"github.com/tigrisdata/tigris/lib/decimal"
+	"github.com/tigrisdata/tigris/lib/geo"
 )
 
 const (
@@ -67,6 +68,9 @@ type DefaultCollection struct {
 	// search indexes are indexes that are explicitly created by the user and tagged Tigris as source. Collection will be
 	// responsible for ensuring these indexes are in sync when any mutation happens to this collection.
 	SearchIndexes map[string]*SearchIndex
+	// LookupSearchIndexes are the search indexes tagged Tigris as source that look up documents of this collection, a
+	// mutation of this collection updates the documents of these indexes that reference the mutated document.
+	LookupSearchIndexes map[string]*SearchIndex
 	// QueryableFields are similar to Fields but these are flattened forms of fields. For instance, a simple field
 	// will be one to one mapped to queryable field but complex fields like object type field there may be more than
 	// one queryableFields. As queryableFields represent a flattened state these can be used as-is to index in memory.
@@ -77,7 +81,7 @@ type DefaultCollection struct {
 	// obj.fieldName so that caller can easily navigate to this field.
 	int64FieldsPath *int64PathBuilder
 	// This is the existing fields in search
-	FieldsInSearch []tsApi.Field
+	FieldsInSearch []StoreField
 
 	fieldsWithInsertDefaults map[string]struct{}
 	fieldsWithUpdateDefaults map[string]struct{}
@@ -161,6 +165,7 @@ func NewDefaultCollection(id uint32, schVer uint32, factory *Factory, schemas Ve
 	url := factory.Name + ".json"
 	compiler := jsonschema.NewCompiler()
 	compiler.Draft = jsonschema.Draft7 // Format is only working for draft7
+	compiler.RegisterExtension(FieldNames[DecimalType], decimalMeta, decimalCompiler{})
 	if err := compiler.AddResource(url, bytes.NewReader(factory.Schema)); err != nil {
 		return nil, err
 	}
@@ -174,7 +179,7 @@ func NewDefaultCollection(id uint32, schVer uint32, factory *Factory, schemas Ve
 	}
 	disableAdditionalPropertiesAndAllowNullable(validator.Required, validator.Properties)
 
-	var prevVersionInSearch []tsApi.Field
+	var prevVersionInSearch []StoreField
 	if implicitSearchIndex != nil {
 		prevVersionInSearch = implicitSearchIndex.prevVersionInSearch
 	}
@@ -202,6 +207,7 @@ func NewDefaultCollection(id uint32, schVer uint32, factory *Factory, schemas Ve
 		fieldsWithInsertDefaults: make(map[string]struct{}),
 		fieldsWithUpdateDefaults: make(map[string]struct{}),
 		SearchIndexes:            make(map[string]*SearchIndex),
+		LookupSearchIndexes:      make(map[string]*SearchIndex),
 		SchemaDeltas:             schemaDeltas,
 		FieldVersions:            fieldVersions,
 		int64FieldsPath:          buildInt64Path(factory.Fields),
@@ -217,6 +223,10 @@ func (d *DefaultCollection) AddSearchIndex(index *SearchIndex) {
 	d.SearchIndexes[index.Name] = index
 }
 
+func (d *DefaultCollection) AddLookupSearchIndex(index *SearchIndex) {
+	d.LookupSearchIndexes[index.Name] = index
+}
+
 func (d *DefaultCollection) GetName() string {
 	return d.Name
 }
@@ -410,6 +420,48 @@ func init() {
 		_, err := parseInt(i)
 		return err == nil
 	}
+	jsonschema.Formats[FieldNames[GeoPointType]] = func(i any) bool {
+		if i == nil {
+			return true
+		}
+
+		obj, ok := i.(map[string]any)
+		if !ok || len(obj) != 2 {
+			return false
+		}
+
+		lat, err := parseFloat(obj["lat"])
+		if err != nil {
+			return false
+		}
+		lon, err := parseFloat(obj["lon"])
+		if err != nil {
+			return false
+		}
+
+		return geo.Point{Lat: lat, Lon: lon}.Valid()
+	}
+	jsonschema.Formats[FieldNames[DecimalType]] = func(i any) bool {
+		if i == nil {
+			return true
+		}
+
+		v, ok := i.(string)
+		if !ok {
+			return false
+		}
+
+		_, err := decimal.Parse(v)
+		return err == nil
+	}
+}
+
+func parseFloat(i any) (float64, error) {
+	switch i.(type) {
+	case json.Number, float64, int, int32, int64:
+		return strconv.ParseFloat(fmt.Sprint(i), 64)
+	}
+	return 0, errors.InvalidArgument("expected number but found %T", i)
 }
 
 func parseInt(i any) (int64, error) {
@@ -399,6 +399,205 @@ func TestCollection_Object(t *testing.T) {
 	}
 }
 
+func TestCollection_GeoPoint(t *testing.T) {
+	reqSchema := []byte(`{
+		"title": "t1",
+		"properties": {
+			"id": {
+				"type": "integer"
+			},
+			"loc": {
+				"type": "object",
+				"format": "geopoint",
+				"index": true,
+				"searchIndex": true
+			}
+		},
+		"primary_key": ["id"]
+	}`)
+
+	schFactory, err := NewFactoryBuilder(true).Build("t1", reqSchema)
+	require.NoError(t, err)
+	coll, err := NewDefaultCollection(1, 1, schFactory, nil, nil)
+	require.NoError(t, err)
+
+	field, err := coll.GetQueryableField("loc")
+	require.NoError(t, err)
+	require.Equal(t, GeoPointType, field.DataType)
+	require.Equal(t, "geopoint", field.SearchType)
+	require.True(t, field.DoNotFlatten)
+	require.True(t, field.Sortable)
+	require.True(t, field.ShouldPack())
+
+	cases := []struct {
+		document []byte
+		valid    bool
+	}{
+		{[]byte(`{"id": 1, "loc": {"lat": 37.77, "lon": -122.41}}`), true},
+		{[]byte(`{"id": 1, "loc": {"lat": -90, "lon": 180}}`), true},
+		{[]byte(`{"id": 1, "loc": null}`), true},
+		{[]byte(`{"id": 1, "loc": {"lat": 91, "lon": -122.41}}`), false},
+		{[]byte(`{"id": 1, "loc": {"lat": 37.77}}`), false},
+		{[]byte(`{"id": 1, "loc": {"lat": 37.77, "lon": "-122.41"}}`), false},
+		{[]byte(`{"id": 1, "loc": {"lat": 37.77, "lon": -122.41, "alt": 10}}`), false},
+	}
+	for _, c := range cases {
+		dec := jsoniter.NewDecoder(bytes.NewReader(c.document))
+		dec.UseNumber()
+		var v any
+		require.NoError(t, dec.Decode(&v))
+		if c.valid {
+			require.NoError(t, coll.Validate(v), string(c.document))
+		} else {
+			require.Error(t, coll.Validate(v), string(c.document))
+		}
+	}
+}
+
+func TestCollection_Decimal(t *testing.T) {
+	reqSchema := []byte(`{
+		"title": "t1",
+		"properties": {
+			"id": {
+				"type": "integer"
+			},
+			"amount": {
+				"type": "string",
+				"format": "decimal",
+				"precision": 6,
+				"scale": 2,
+				"index": true
+			},
+			"nested": {
+				"type": "object",
+				"properties": {
+					"rate": { "type": "string", "format": "decimal", "precision": 4, "scale": 4 }
+				}
+			}
+		},
+		"primary_key": ["id"]
+	}`)
+
+	schFactory, err := NewFactoryBuilder(true).Build("t1", reqSchema)
+	require.NoError(t, err)
+	coll, err := NewDefaultCollection(1, 1, schFactory, nil, nil)
+	require.NoError(t, err)
+
+	field, err := coll.GetQueryableField("amount")
+	require.NoError(t, err)
+	require.Equal(t, DecimalType, field.DataType)
+	require.Equal(t, "float", field.SearchType)
+	require.Equal(t, 6, field.Precision)
+	require.Equal(t, 2, field.Scale)
+	require.True(t, field.Indexed)
+	require.True(t, field.ShouldPack())
+
+	cases := []struct {
+		document []byte
+		valid    bool
+	}{
+		{[]byte(`{"id": 1, "amount": "1234.50"}`), true},
+		{[]byte(`{"id": 1, "amount": "-9999.99"}`), true},
+		{[]byte(`{"id": 1, "amount": "12.500"}`), true},
+		{[]byte(`{"id": 1, "amount": null, "nested": {"rate": "0.0125"}}`), true},
+		{[]byte(`{"id": 1, "amount": "12.345"}`), false},
+		{[]byte(`{"id": 1, "amount": "12345.5"}`), false},
+		{[]byte(`{"id": 1, "amount": "abc"}`), false},
+		{[]byte(`{"id": 1, "amount": 12.5}`), false},
+		{[]byte(`{"id": 1, "nested": {"rate": "1.5"}}`), false},
+	}
+	for _, c := range cases {
+		dec := jsoniter.NewDecoder(bytes.NewReader(c.document))
+		dec.UseNumber()
+		var v any
+		require.NoError(t, dec.Decode(&v))
+		if c.valid {
+			require.NoError(t, coll.Validate(v), string(c.document))
+		} else {
+			require.Error(t, coll.Validate(v), string(c.document))
+		}
+	}
+}
+
+func TestCollection_Embedding(t *testing.T) {
+	reqSchema := []byte(`{
+		"title": "t1",
+		"properties": {
+			"id": { "type": "integer" },
+			"title": { "type": "string" },
+			"info": { "type": "object", "properties": { "summary": { "type": "string" } } },
+			"title_vec": { "type": "array", "format": "vector", "dimensions": 8, "embedding": { "source": "title", "provider": "hash" } },
+			"summary_vec": { "type": "array", "format": "vector", "dimensions": 8, "embedding": { "source": "info.summary", "provider": "hash" } },
+			"plain_vec": { "type": "array", "format": "vector", "dimensions": 8 }
+		},
+		"primary_key": ["id"]
+	}`)
+
+	schFactory, err := NewFactoryBuilder(true).Build("t1", reqSchema)
+	require.NoError(t, err)
+	coll, err := NewDefaultCollection(1, 1, schFactory, nil, nil)
+	require.NoError(t, err)
+
+	fields := coll.EmbeddingFields()
+	require.Len(t, fields, 2)
+	require.Equal(t, "title_vec", fields[0].FieldName)
+	require.Equal(t, &FieldEmbedding{Source: "info.summary", Provider: "hash"}, fields[1].Embedding)
+
+	field, err := coll.GetQueryableField("title_vec")
+	require.NoError(t, err)
+	require.Equal(t, &FieldEmbedding{Source: "title", Provider: "hash"}, field.Embedding)
+
+	updated := []byte(`{
+		"title": "t1",
+		"properties": {
+			"id": { "type": "integer" },
+			"title": { "type": "string" },
+			"info": { "type": "object", "properties": { "summary": { "type": "string" } } },
+			"title_vec": { "type": "array", "format": "vector", "dimensions": 8, "embedding": { "source": "title", "provider": "hash" } },
+			"summary_vec": { "type": "array", "format": "vector", "dimensions": 8, "embedding": { "source": "title", "provider": "hash" } },
+			"plain_vec": { "type": "array", "format": "vector", "dimensions": 8, "embedding": { "source": "title", "provider": "hash" } }
+		},
+		"primary_key": ["id"]
+	}`)
+	schFactory, err = NewFactoryBuilder(true).Build("t1", updated)
+	require.NoError(t, err)
+	updatedColl, err := NewDefaultCollection(1, 2, schFactory, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"summary_vec", "plain_vec"}, ChangedEmbeddingFields(coll, updatedColl))
+	require.Empty(t, ChangedEmbeddingFields(updatedColl, updatedColl))
+
+	cases := []struct {
+		properties  string
+		expErrorMsg string
+	}{
+		{
+			`"v": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"source": "title", "provider": "unknown"}}`,
+			"Embedding of field 'v' has an unknown provider 'unknown'",
+		}, {
+			`"v": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"provider": "hash"}}`,
+			"Embedding of field 'v' is missing the source field",
+		}, {
+			`"v": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"source": "missing", "provider": "hash"}}`,
+			"Embedding source 'missing' of field 'v' is not in the schema",
+		}, {
+			`"v": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"source": "id", "provider": "hash"}}`,
+			"Embedding source 'id' of field 'v' needs to be a string",
+		}, {
+			`"v": {"type": "string", "embedding": {"source": "title", "provider": "hash"}}`,
+			"Embedding is only supported on vector fields, field 'v' is of type 'string'",
+		}, {
+			`"o": {"type": "object", "properties": {"v": {"type": "array", "format": "vector", "dimensions": 4, "embedding": {"source": "title", "provider": "hash"}}}}`,
+			"Embedding is only supported on top level vector fields 'v'",
+		},
+	}
+	for _, c := range cases {
+		sch := []byte(`{"title": "t1", "properties": {"id": {"type": "integer"}, "title": {"type": "string"}, ` + c.properties + `}, "primary_key": ["id"]}`)
+		_, err = NewFactoryBuilder(true).Build("t1", sch)
+		require.Error(t, err, c.properties)
+		require.Contains(t, err.Error(), c.expErrorMsg)
+	}
+}
+
 func TestCollection_Int64(t *testing.T) {
 	reqSchema := []byte(`{
 		"title": "t1",
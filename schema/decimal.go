@@ -0,0 +1,111 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/decimal"
+)
+
+// decimalMeta is the meta schema of the "precision" and "scale" keywords of a decimal field.
+var decimalMeta = jsonschema.MustCompileString("decimal.json", `{
+	"properties": {
+		"precision": {"type": "integer", "minimum": 1},
+		"scale": {"type": "integer", "minimum": 0}
+	}
+}`)
+
+// decimalCompiler is the JSON schema extension that validates the decimal values against the precision and the scale
+// of the field. The "decimal" format only checks if the value can be parsed, as formats don't have access to the rest
+// of the field schema.
+type decimalCompiler struct{}
+
+func (decimalCompiler) Compile(_ jsonschema.CompilerContext, m map[string]any) (jsonschema.ExtSchema, error) {
+	if m["format"] != jsonSpecFormatDecimal {
+		return nil, nil
+	}
+
+	s := decimalSchema{precision: decimal.MaxPrecision}
+	if p, ok := m["precision"].(json.Number); ok {
+		n, err := p.Int64()
+		if err != nil {
+			return nil, err
+		}
+		s.precision = int(n)
+	}
+	if p, ok := m["scale"].(json.Number); ok {
+		n, err := p.Int64()
+		if err != nil {
+			return nil, err
+		}
+		s.scale = int(n)
+	}
+
+	return s, nil
+}
+
+type decimalSchema struct {
+	precision int
+	scale     int
+}
+
+func (s decimalSchema) Validate(ctx jsonschema.ValidationContext, v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return nil
+	}
+
+	d, err := decimal.Parse(str)
+	if err != nil {
+		// reported by the format
+		return nil
+	}
+
+	if err = CheckDecimal(d, s.precision, s.scale); err != nil {
+		return ctx.Error("precision", "%s", err.Error())
+	}
+
+	return nil
+}
+
+// CheckDecimal returns an error if the decimal doesn't fit in the precision and the scale.
+func CheckDecimal(d decimal.Decimal, precision int, scale int) error {
+	if int(d.Normalize().Scale()) > scale {
+		return errors.InvalidArgument("'%s' has more than %d digits after the decimal point", d.String(), scale)
+	}
+	if d.IntegerDigits() > precision-scale {
+		return errors.InvalidArgument("'%s' has more than %d digits before the decimal point", d.String(), precision-scale)
+	}
+
+	return nil
+}
+
+// DecimalToSearch converts the decimal string to the float that is indexed by the search backend.
+func DecimalToSearch(value any) (any, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, errors.InvalidArgument("decimal needs to be a string")
+	}
+
+	d, err := decimal.Parse(str)
+	if err != nil {
+		return nil, errors.InvalidArgument("'%s' is not a valid decimal", str)
+	}
+
+	return d.Float64(), nil
+}
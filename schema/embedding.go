@@ -0,0 +1,98 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"strings"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/embedding"
+)
+
+// FieldEmbedding is set on a vector field to let the server fill the vector from a string field of the same document
+// using an embedding provider. For example,
+//
+//	"title_vec": {"type": "array", "format": "vector", "dimensions": 384, "embedding": {"source": "title", "provider": "hash"}}
+type FieldEmbedding struct {
+	// Source is the name of the string field, nested fields are referenced using the dot notation.
+	Source string `json:"source"`
+	// Provider is the name of the embedding provider as configured in the server.
+	Provider string `json:"provider"`
+}
+
+// EmbeddingFields returns the vector fields of the collection that are computed by an embedding provider.
+func (d *DefaultCollection) EmbeddingFields() []*Field {
+	var fields []*Field
+	for _, f := range d.Fields {
+		if f.Embedding != nil {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// ChangedEmbeddingFields returns the names of the embedding fields of the current collection that are not in the
+// existing collection or which source or provider has changed. The vectors of these fields need to be recomputed for
+// all the documents.
+func ChangedEmbeddingFields(existing *DefaultCollection, current *DefaultCollection) []string {
+	var changed []string
+	for _, f := range current.EmbeddingFields() {
+		e := existing.GetField(f.FieldName)
+		if e == nil || e.Embedding == nil || *e.Embedding != *f.Embedding {
+			changed = append(changed, f.FieldName)
+		}
+	}
+
+	return changed
+}
+
+// validateEmbeddings checks that the provider of the embeddings is known and that their source is a string field of the
+// schema.
+func validateEmbeddings(fields []*Field) error {
+	for _, f := range fields {
+		if f.Embedding == nil {
+			continue
+		}
+		if len(f.Embedding.Source) == 0 {
+			return errors.InvalidArgument("Embedding of field '%s' is missing the source field", f.FieldName)
+		}
+		if _, err := embedding.Get(f.Embedding.Provider); err != nil {
+			return errors.InvalidArgument("Embedding of field '%s' has an unknown provider '%s'", f.FieldName, f.Embedding.Provider)
+		}
+
+		source := lookupField(fields, f.Embedding.Source)
+		if source == nil {
+			return errors.InvalidArgument("Embedding source '%s' of field '%s' is not in the schema", f.Embedding.Source, f.FieldName)
+		}
+		if source.DataType != StringType {
+			return errors.InvalidArgument("Embedding source '%s' of field '%s' needs to be a string", f.Embedding.Source, f.FieldName)
+		}
+	}
+
+	return nil
+}
+
+func lookupField(fields []*Field, path string) *Field {
+	var f *Field
+	for _, name := range strings.Split(path, ObjFlattenDelimiter) {
+		if f = GetField(fields, name); f == nil {
+			return nil
+		}
+		fields = f.Fields
+	}
+
+	return f
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/tigrisdata/tigris/errors"
 	"github.com/tigrisdata/tigris/lib/container"
+	"github.com/tigrisdata/tigris/lib/decimal"
 	schema "github.com/tigrisdata/tigris/schema/lang"
 	"github.com/tigrisdata/tigris/server/config"
 )
@@ -50,6 +51,11 @@ const (
 	ArrayType
 	ObjectType
 	VectorType
+	// GeoPointType is an object with "lat" and "lon" in degrees, i.e. {"lat": 37.77, "lon": -122.41}.
+	GeoPointType
+	// DecimalType is an exact decimal number with a fixed precision and scale, it is represented as a JSON string
+	// i.e. "12.50" so that no precision is lost by the JSON parsers.
+	DecimalType
 	// For internal querying usage.
 	MaxType
 )
@@ -68,6 +74,8 @@ var FieldNames = [...]string{
 	ArrayType:    "array",
 	ObjectType:   "object",
 	VectorType:   "vector",
+	GeoPointType: "geopoint",
+	DecimalType:  "decimal",
 }
 
 var (
@@ -94,6 +102,8 @@ const (
 	jsonSpecFormatInt32    = "int32"
 	jsonSpecFormatInt64    = "int64"
 	jsonSpecFormatVector   = "vector"
+	jsonSpecFormatGeoPoint = "geopoint"
+	jsonSpecFormatDecimal  = "decimal"
 )
 
 func ToFieldType(jsonType string, encoding string, format string) FieldType {
@@ -137,6 +147,8 @@ func ToFieldType(jsonType string, encoding string, format string) FieldType {
 			return DateTimeType
 		case jsonSpecFormatByte:
 			return ByteType
+		case jsonSpecFormatDecimal:
+			return DecimalType
 		default:
 			if len(format) > 0 {
 				return UnknownType
@@ -150,6 +162,9 @@ func ToFieldType(jsonType string, encoding string, format string) FieldType {
 		}
 		return ArrayType
 	case jsonSpecObject:
+		if format == jsonSpecFormatGeoPoint {
+			return GeoPointType
+		}
 		return ObjectType
 	default:
 		return UnknownType
@@ -167,7 +182,7 @@ func IsValidKeyType(t FieldType) bool {
 
 func IsPrimitiveType(fieldType FieldType) bool {
 	switch fieldType {
-	case BoolType, Int32Type, Int64Type, UUIDType, StringType, DateTimeType, DoubleType:
+	case BoolType, Int32Type, Int64Type, UUIDType, StringType, DateTimeType, DoubleType, DecimalType:
 		return true
 	}
 	return false
@@ -175,7 +190,7 @@ func IsPrimitiveType(fieldType FieldType) bool {
 
 func SupportedIndexableType(fieldType FieldType) bool {
 	switch fieldType {
-	case BoolType, Int32Type, Int64Type, UUIDType, StringType, DateTimeType, DoubleType:
+	case BoolType, Int32Type, Int64Type, UUIDType, StringType, DateTimeType, DoubleType, GeoPointType, DecimalType:
 		return true
 	default:
 		return false
@@ -184,7 +199,8 @@ func SupportedIndexableType(fieldType FieldType) bool {
 
 func SupportedSearchIndexableType(fieldType FieldType, subType FieldType) bool {
 	switch fieldType {
-	case BoolType, Int32Type, Int64Type, UUIDType, StringType, DateTimeType, DoubleType, ArrayType, VectorType:
+	case BoolType, Int32Type, Int64Type, UUIDType, StringType, DateTimeType, DoubleType, ArrayType, VectorType, GeoPointType,
+		DecimalType:
 		return true
 	case ObjectType:
 		return subType == UnknownType
@@ -217,7 +233,7 @@ func DefaultFacetableType(fieldType FieldType) bool {
 // DefaultSortableType are the types for which sorting is automatically enabled.
 func DefaultSortableType(fieldType FieldType) bool {
 	switch fieldType {
-	case Int32Type, Int64Type, DoubleType, DateTimeType, BoolType:
+	case Int32Type, Int64Type, DoubleType, DateTimeType, BoolType, DecimalType:
 		return true
 	default:
 		return false
@@ -234,12 +250,15 @@ func toSearchFieldType(fieldType FieldType, subType FieldType) string {
 		return FieldNames[StringType]
 	case DateTimeType:
 		return FieldNames[Int64Type]
-	case DoubleType:
+	case DoubleType, DecimalType:
+		// the exact decimal is stored in a shadowed key
 		return searchDoubleType
 	case ObjectType:
 		return FieldNames[ObjectType]
 	case VectorType:
 		return searchDoubleType + "[]"
+	case GeoPointType:
+		return FieldNames[GeoPointType]
 	case ArrayType:
 		switch subType {
 		case BoolType:
@@ -287,6 +306,9 @@ var SupportedFieldProperties = container.NewHashSet(
 	"maxItems",
 	"additionalProperties",
 	"dimensions",
+	"precision",
+	"embedding",
+	"scale",
 	"id",
 )
 
@@ -439,6 +461,9 @@ type FieldBuilder struct {
 	ID                   *bool                 `json:"id,omitempty"`
 	SearchIndex          *bool                 `json:"searchIndex,omitempty"`
 	Dimensions           *int                  `json:"dimensions,omitempty"`
+	Precision            *int                  `json:"precision,omitempty"`
+	Scale                *int                  `json:"scale,omitempty"`
+	Embedding            *FieldEmbedding       `json:"embedding,omitempty"`
 	Items                *FieldBuilder         `json:"items,omitempty"`
 	Properties           jsoniter.RawMessage   `json:"properties,omitempty"`
 	Primary              *bool
@@ -483,6 +508,9 @@ func (f *FieldBuilder) Build(setSearchDefaults bool) (*Field, error) {
 		PrimaryKeyField:      f.Primary,
 		AutoGenerated:        f.Auto,
 		Dimensions:           f.Dimensions,
+		Precision:            f.Precision,
+		Scale:                f.Scale,
+		Embedding:            f.Embedding,
 		AdditionalProperties: f.AdditionalProperties,
 		SearchIdField:        f.ID,
 	}
@@ -518,6 +546,9 @@ type Field struct {
 	SearchIndexed   *bool
 	SearchIdField   *bool
 	Dimensions      *int
+	Precision       *int
+	Scale           *int
+	Embedding       *FieldEmbedding
 	// Nested fields are the fields where we know the schema of nested attributes like if properties are
 	Fields               []*Field
 	AdditionalProperties *bool
@@ -578,6 +609,12 @@ func (f *Field) IsCompatible(keyPath string, f1 *Field) error {
 		}
 	}
 
+	if f.DataType == DecimalType && f1.DataType == DecimalType && !config.DefaultConfig.Schema.AllowIncompatible {
+		if f.GetScale() > f1.GetScale() || f.GetPrecision()-f.GetScale() > f1.GetPrecision()-f1.GetScale() {
+			return errors.InvalidArgument("reducing precision or scale of an existing field is not allowed %q", keyPath+f.FieldName)
+		}
+	}
+
 	return nil
 }
 
@@ -610,6 +647,22 @@ func (f *Field) GetDimensions() int {
 	return 0
 }
 
+// GetPrecision returns the maximum number of digits of a decimal field.
+func (f *Field) GetPrecision() int {
+	if f.Precision != nil {
+		return *f.Precision
+	}
+	return decimal.MaxPrecision
+}
+
+// GetScale returns the number of digits after the decimal point of a decimal field.
+func (f *Field) GetScale() int {
+	if f.Scale != nil {
+		return *f.Scale
+	}
+	return 0
+}
+
 func GetField(fields []*Field, name string) *Field {
 	for _, r := range fields {
 		if r.FieldName == name {
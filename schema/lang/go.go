@@ -30,6 +30,10 @@ func getGoStringType(format string) string {
 		return "time.Time"
 	case formatByte:
 		return "[]byte"
+	case formatDecimal:
+		// decimals are transferred as JSON strings so that no precision is lost, converting them to a floating
+		// point type would defeat the purpose of the type
+		return "string"
 	case formatUUID:
 		return "uuid.UUID"
 	default:
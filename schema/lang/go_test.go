@@ -38,6 +38,7 @@ type Product struct {
 	ArrInts []int64 ` + "`" + `json:"arrInts"` + "`" + `
 	Bool bool ` + "`" + `json:"bool"` + "`" + `
 	Byte1 []byte ` + "`" + `json:"byte1"` + "`" + `
+	Dec1 string ` + "`" + `json:"dec1"` + "`" + `
 	Id int32 ` + "`" + `json:"id"` + "`" + `
 	Int64 int64 ` + "`" + `json:"int64"` + "`" + `
 	Int64Ptr *int64 ` + "`" + `json:"int64Ptr"` + "`" + `
@@ -28,6 +28,10 @@ func getJavaStringType(format string) string {
 		return "Date"
 	case formatByte:
 		return "byte[]"
+	case formatDecimal:
+		// decimals are transferred as JSON strings so that no precision is lost, converting them to a floating
+		// point type would defeat the purpose of the type
+		return "String"
 	case formatUUID:
 		return "UUID"
 	default:
@@ -39,6 +39,7 @@ class Product {
     private long[] arrInts;
     private boolean bool;
     private byte[] byte1;
+    private String dec1;
     private int id;
     private long int64;
     private long int64Ptr;
@@ -90,6 +91,14 @@ class Product {
         this.byte1 = byte1;
     }
 
+    public String getDec1() {
+        return dec1;
+    }
+
+    public void setDec1(String dec1) {
+        this.dec1 = dec1;
+    }
+
     public int getId() {
         return id;
     }
@@ -170,6 +179,7 @@ class Product {
         long[] arrInts,
         boolean bool,
         byte[] byte1,
+        String dec1,
         int id,
         long int64,
         long int64Ptr,
@@ -185,6 +195,7 @@ class Product {
         this.arrInts = arrInts;
         this.bool = bool;
         this.byte1 = byte1;
+        this.dec1 = dec1;
         this.id = id;
         this.int64 = int64;
         this.int64Ptr = int64Ptr;
@@ -212,6 +223,7 @@ class Product {
             Arrays.equals(arrInts, other.arrInts) &&
             bool == other.bool &&
             byte1 == other.byte1 &&
+            dec1 == other.dec1 &&
             id == other.id &&
             int64 == other.int64 &&
             int64Ptr == other.int64Ptr &&
@@ -231,6 +243,7 @@ class Product {
             arrInts,
             bool,
             byte1,
+            dec1,
             id,
             int64,
             int64Ptr,
@@ -53,6 +53,7 @@ const (
 	formatByte     = "byte"
 	formatDateTime = "date-time"
 	formatUUID     = "uuid"
+	formatDecimal  = "decimal"
 )
 
 // TODO: This is copy from the Go client schema package, it cannot be imported due to proto file conflict
@@ -69,6 +70,8 @@ type Field struct {
 
 	Default      any  `json:"default,omitempty"`
 	MaxLength    int  `json:"maxLength,omitempty"`
+	Precision    int  `json:"precision,omitempty"`
+	Scale        int  `json:"scale,omitempty"`
 	CreatedAt    bool `json:"createdAt,omitempty"`
 	UpdatedAt    bool `json:"updatedAt,omitempty"`
 	AutoGenerate bool `json:"autoGenerate,omitempty"`
@@ -34,6 +34,7 @@ var (
           "byte1": { "type": "string", "format": "byte"},
           "time1": { "type": "string", "format": "date-time"},
           "uUID1": { "type": "string", "format": "uuid"},
+          "dec1": { "type": "string", "format": "decimal", "precision": 10, "scale": 2},
           "arrInts": { "type": "array", "items" : { "type" : "integer" } },
           "arrIntPtrs": { "type": ["array","null"], "items" : { "type" : "integer" } },
           "arrIntPtrPtrs": { "type": ["array","null"], "items" : { "type" : ["integer","null"] } },
@@ -28,6 +28,10 @@ func getTSStringType(format string) string {
 		return "DATE_TIME"
 	case formatByte:
 		return "BYTE_STRING"
+	case formatDecimal:
+		// decimals are transferred as JSON strings so that no precision is lost, converting them to a floating
+		// point type would defeat the purpose of the type
+		return "STRING"
 	case formatUUID:
 		return "UUID"
 	default:
@@ -49,6 +49,9 @@ export class Product {
   @Field(TigrisDataTypes.BYTE_STRING)
   byte1: string;
 
+  @Field()
+  dec1: string;
+
   @Field(TigrisDataTypes.INT32)
   id: number;
 
@@ -14,11 +14,7 @@
 
 package schema
 
-import (
-	"strings"
-
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
-)
+import "strings"
 
 // QueryableField is internal structure used after flattening the fields i.e. the representation of the queryable field
 // is of the following form "field" OR "parent.field". This allows us to perform look faster by just checking in this
@@ -38,6 +34,9 @@ type QueryableField struct {
 	packThis       bool
 	DoNotFlatten   bool
 	Dimensions     *int
+	Precision      int
+	Scale          int
+	Embedding      *FieldEmbedding
 	SearchIdField  bool
 	// This is not stored in flattened form in search
 	// but will allow filtering on array of objects.
@@ -70,7 +69,11 @@ func (q *QueryableField) ShouldPack() bool {
 	if q.DataType == ArrayType && (q.SubType == ArrayType || q.SubType == UnknownType) {
 		return true
 	}
-	return !q.IsReserved() && q.DataType == DateTimeType
+	if q.DataType == GeoPointType {
+		// geopoint is stored as [lat, lon] in search
+		return true
+	}
+	return !q.IsReserved() && (q.DataType == DateTimeType || q.DataType == DecimalType)
 }
 
 // IsReserved returns true if the queryable field is internal field.
@@ -99,7 +102,7 @@ func NewDynamicQueryableField(name string, unFlattenName string, ft FieldType) *
 	}
 }
 
-func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsInSearch []tsApi.Field) *QueryableField {
+func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsInSearch []StoreField) *QueryableField {
 	var (
 		searchType    string
 		faceted       = f.Faceted
@@ -146,7 +149,12 @@ func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsIn
 		PrimaryIndexed: f.IsPrimaryKey(),
 		SearchIdField:  f.IsSearchId(),
 		Dimensions:     f.Dimensions,
+		Embedding:      f.Embedding,
 		UnFlattenName:  f.Name(),
+		DoNotFlatten:   f.DataType == GeoPointType,
+	}
+	if f.DataType == DecimalType {
+		q.Precision, q.Scale = f.GetPrecision(), f.GetScale()
 	}
 	if !packThis && f.DataType == ArrayType && len(f.Fields) > 0 && f.Fields[0].DataType == ObjectType {
 		// An array of objects stored in search, we need to allow filtering on nested fields inside this object
@@ -180,6 +188,10 @@ func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsIn
 	if sortable != nil && *sortable {
 		q.Sortable = true
 	}
+	if f.DataType == GeoPointType && q.SearchIndexed {
+		// sorting on a geopoint is by the distance from a point
+		q.Sortable = true
+	}
 	if faceted != nil && *faceted {
 		q.Faceted = true
 	}
@@ -192,7 +204,7 @@ func (*QueryableFieldsBuilder) NewQueryableField(name string, f *Field, fieldsIn
 	return q
 }
 
-func (builder *QueryableFieldsBuilder) BuildQueryableFields(fields []*Field, fieldsInSearch []tsApi.Field, indexMetadata bool) []*QueryableField {
+func (builder *QueryableFieldsBuilder) BuildQueryableFields(fields []*Field, fieldsInSearch []StoreField, indexMetadata bool) []*QueryableField {
 	var queryableFields []*QueryableField
 
 	for _, f := range fields {
@@ -230,7 +242,7 @@ func (builder *QueryableFieldsBuilder) BuildQueryableFields(fields []*Field, fie
 	return queryableFields
 }
 
-func (builder *QueryableFieldsBuilder) buildQueryableForObject(parent string, fields []*Field, fieldsInSearch []tsApi.Field) []*QueryableField {
+func (builder *QueryableFieldsBuilder) buildQueryableForObject(parent string, fields []*Field, fieldsInSearch []StoreField) []*QueryableField {
 	var queryable []*QueryableField
 	for _, nested := range fields {
 		if nested.DataType == ObjectType {
@@ -247,7 +259,7 @@ func (builder *QueryableFieldsBuilder) buildQueryableForObject(parent string, fi
 	return queryable
 }
 
-func (builder *QueryableFieldsBuilder) buildQueryableField(parent string, f *Field, fieldsInSearch []tsApi.Field) *QueryableField {
+func (builder *QueryableFieldsBuilder) buildQueryableField(parent string, f *Field, fieldsInSearch []StoreField) *QueryableField {
 	name := f.FieldName
 	if len(parent) > 0 {
 		name = parent + ObjFlattenDelimiter + f.FieldName
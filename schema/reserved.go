@@ -29,16 +29,18 @@ const (
 	DateSearchKeyPrefix
 	SearchArrNullItem
 	SearchNullKeys
+	DecimalSearchKeyPrefix
 )
 
 var ReservedFields = [...]string{
-	CreatedAt:           TigrisFieldsPrefix + "created_at",
-	UpdatedAt:           TigrisFieldsPrefix + "updated_at",
-	Metadata:            TigrisFieldsPrefix + "metadata",
-	IdToSearchKey:       TigrisFieldsPrefix + "id",
-	DateSearchKeyPrefix: TigrisFieldsPrefix + "date_",
-	SearchArrNullItem:   TigrisFieldsPrefix + "null",
-	SearchNullKeys:      TigrisFieldsPrefix + "null_keys",
+	CreatedAt:              TigrisFieldsPrefix + "created_at",
+	UpdatedAt:              TigrisFieldsPrefix + "updated_at",
+	Metadata:               TigrisFieldsPrefix + "metadata",
+	IdToSearchKey:          TigrisFieldsPrefix + "id",
+	DateSearchKeyPrefix:    TigrisFieldsPrefix + "date_",
+	SearchArrNullItem:      TigrisFieldsPrefix + "null",
+	SearchNullKeys:         TigrisFieldsPrefix + "null_keys",
+	DecimalSearchKeyPrefix: TigrisFieldsPrefix + "decimal_",
 }
 
 func IsReservedField(name string) bool {
@@ -60,3 +62,9 @@ func IsSearchID(name string) bool {
 func ToSearchDateKey(key string) string {
 	return ReservedFields[DateSearchKeyPrefix] + key
 }
+
+// ToSearchDecimalKey is the storage field for the search backend where the original decimal strings are persisted, the
+// field itself is indexed as a float.
+func ToSearchDecimalKey(key string) string {
+	return ReservedFields[DecimalSearchKeyPrefix] + key
+}
@@ -17,6 +17,7 @@ package schema
 import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/decimal"
 	"github.com/tigrisdata/tigris/server/config"
 )
 
@@ -293,6 +294,9 @@ func ValidateFieldAttributes(isSearch bool, field *Field) error {
 				return errors.InvalidArgument("Cannot have field '%s' as 'id'. Only string type is supported as 'id' field", field.FieldName)
 			}
 		}
+		if field.Embedding != nil {
+			return errors.InvalidArgument("Embedding is not supported on search index field '%s'", field.FieldName)
+		}
 	} else {
 		if field.IsPrimaryKey() {
 			// validate the primary key types
@@ -329,6 +333,9 @@ func ValidateFieldAttributes(isSearch bool, field *Field) error {
 
 func validateObjectFields(f *Field, notSupported bool) error {
 	for _, nested := range f.Fields {
+		if nested.Embedding != nil {
+			return errors.InvalidArgument("Embedding is only supported on top level vector fields '%s'", nested.Name())
+		}
 		if nested.DataType == ObjectType {
 			if hasIndexingAttributes(nested) {
 				if nested.IsIndexed() {
@@ -421,6 +428,31 @@ func validateFieldAttribute(f *Field, notSupported bool) error {
 			return errors.InvalidArgument("only search index attribute is supported on vector field '%s'", f.FieldName)
 		}
 	}
+	if f.DataType == GeoPointType {
+		if len(f.Fields) > 0 {
+			return errors.InvalidArgument("Cannot have properties on field '%s' of type 'geopoint'", f.FieldName)
+		}
+		if f.IsFaceted() || f.IsSorted() {
+			return errors.InvalidArgument("Cannot have sort or facet attribute on geopoint field '%s', "+
+				"geopoint fields are sorted by the distance using $near", f.FieldName)
+		}
+	}
+	if f.DataType == DecimalType {
+		if f.GetPrecision() < 1 || f.GetPrecision() > decimal.MaxPrecision {
+			return errors.InvalidArgument("Field '%s' of type 'decimal' needs precision between 1 and %d", f.FieldName, decimal.MaxPrecision)
+		}
+		if f.GetScale() < 0 || f.GetScale() > f.GetPrecision() {
+			return errors.InvalidArgument("Field '%s' of type 'decimal' needs scale between 0 and the precision", f.FieldName)
+		}
+	} else if f.Precision != nil || f.Scale != nil {
+		return errors.InvalidArgument("Precision and scale are only supported on field '%s' of type 'decimal'", f.FieldName)
+	}
+	if f.Embedding != nil && f.DataType != VectorType {
+		return errors.InvalidArgument("Embedding is only supported on vector fields, field '%s' is of type '%s'", f.FieldName, FieldNames[f.DataType])
+	}
+	if subType == GeoPointType && hasIndexingAttributes(f) {
+		return errors.InvalidArgument("Cannot enable index or search on an array of geopoints '%s'", f.FieldName)
+	}
 	if f.IsIndexed() && !f.IsIndexable() {
 		return errors.InvalidArgument("Cannot enable index on field '%s' of type '%s'. Only top level non-byte fields can be indexed.", f.FieldName, FieldNames[f.DataType])
 	}
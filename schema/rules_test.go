@@ -330,6 +330,33 @@ func TestIndexAttributeOnFields(t *testing.T) {
 		}, {
 			[]byte(`{"title":"test","properties":{"obj_last":{"type":"object","properties":{"nested_arr_obj":{"type":"array","items":{"type":"object","properties":{"n_id":{"type":"integer","index":true}}}}}}}}`),
 			"Cannot enable index on nested field 'n_id'",
+		}, {
+			[]byte(`{"title":"test","properties":{"loc":{"type":"object","format":"geopoint","index":true,"searchIndex":true}}}`),
+			"",
+		}, {
+			[]byte(`{"title":"test","properties":{"loc":{"type":"object","format":"geopoint","searchIndex":true,"sort":true}}}`),
+			"Cannot have sort or facet attribute on geopoint field 'loc'",
+		}, {
+			[]byte(`{"title":"test","properties":{"loc":{"type":"object","format":"geopoint","properties":{"lat":{"type":"number"}}}}}`),
+			"Cannot have properties on field 'loc' of type 'geopoint'",
+		}, {
+			[]byte(`{"title":"test","properties":{"locs":{"type":"array","items":{"type":"object","format":"geopoint"},"index":true}}}`),
+			"Cannot enable index or search on an array of geopoints 'locs'",
+		}, {
+			[]byte(`{"title":"test","properties":{"amount":{"type":"string","format":"decimal","precision":10,"scale":2,"index":true,"searchIndex":true,"sort":true}}}`),
+			"",
+		}, {
+			[]byte(`{"title":"test","properties":{"amount":{"type":"string","format":"decimal","precision":35}}}`),
+			"Field 'amount' of type 'decimal' needs precision between 1 and 34",
+		}, {
+			[]byte(`{"title":"test","properties":{"amount":{"type":"string","format":"decimal","precision":2,"scale":3}}}`),
+			"Field 'amount' of type 'decimal' needs scale between 0 and the precision",
+		}, {
+			[]byte(`{"title":"test","properties":{"amount":{"type":"number","scale":2}}}`),
+			"Precision and scale are only supported on field 'amount' of type 'decimal'",
+		}, {
+			[]byte(`{"title":"test","properties":{"amount":{"type":"string","format":"decimal","searchIndex":true,"facet":true}}}`),
+			"Cannot enable faceting on field 'amount'",
 		},
 	}
 	for _, c := range cases {
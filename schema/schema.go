@@ -261,7 +261,7 @@ func (*FactoryBuilder) validateSchema(factory *Factory) error {
 		}
 	}
 
-	return nil
+	return validateEmbeddings(factory.Fields)
 }
 
 func setPrimaryKey(reqSchema jsoniter.RawMessage, format string, ifMissing bool) (jsoniter.RawMessage, error) {
@@ -327,8 +327,9 @@ func TestCreateCollectionFromSchema(t *testing.T) {
 					},
 				},
 			},
-			SearchIndexes: make(map[string]*SearchIndex),
-			FieldVersions: make(map[string]*FieldVersions),
+			SearchIndexes:       make(map[string]*SearchIndex),
+			LookupSearchIndexes: make(map[string]*SearchIndex),
+			FieldVersions:       make(map[string]*FieldVersions),
 		}
 
 		require.Equal(t, expColl, coll)
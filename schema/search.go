@@ -20,7 +20,6 @@ import (
 	jsoniter "github.com/json-iterator/go"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
 )
 
 // SearchIndexState represents the search state of collection search.
@@ -58,6 +57,35 @@ type SearchSource struct {
 	CollectionName string `json:"collection,omitempty"`
 	// DatabaseBranch is in case the collection is part of a database branch. Only applicable if Type is Tigris.
 	DatabaseBranch string `json:"branch,omitempty"`
+	// Lookups denormalize the documents of related collections into the documents of the source collection. Only
+	// applicable if Type is Tigris.
+	Lookups []SearchLookup `json:"lookups,omitempty"`
+}
+
+// SearchLookup joins a related collection of the same database branch to the documents of the source collection. The
+// related document is looked up by its primary key, which must be a single field, and is set as an object in the
+// indexed document.
+type SearchLookup struct {
+	// CollectionName is the related collection.
+	CollectionName string `json:"collection"`
+	// LocalField is the top level field of the source document holding the primary key of the related document.
+	LocalField string `json:"local_field"`
+	// As is the object field of the index the related document is set in.
+	As string `json:"as"`
+	// Fields are the top level fields of the related document that are indexed, all if not set.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// GetLookups returns the lookups of the source on the related collection.
+func (s *SearchSource) GetLookups(collection string) []SearchLookup {
+	var lookups []SearchLookup
+	for _, l := range s.Lookups {
+		if l.CollectionName == collection {
+			lookups = append(lookups, l)
+		}
+	}
+
+	return lookups
 }
 
 type SearchJSONSchema struct {
@@ -79,6 +107,31 @@ func (s *SearchSchemaOptions) GetTokenSeparators() []string {
 	return *s.TokenSeparators
 }
 
+// StoreSchema is the schema of an index in the search store. It is derived from the queryable fields and doesn't
+// depend on the engine behind the store.
+type StoreSchema struct {
+	// Name is the name of the index in the search store.
+	Name   string
+	Fields []StoreField
+	// TokenSeparators is a list of symbols or special characters to be used for splitting the text into individual
+	// words in addition to space and new-line characters.
+	TokenSeparators []string
+}
+
+// StoreField is a field of an index in the search store. The attributes that are nil are unknown, for example the
+// search store may not report them for a field that is only dropped.
+type StoreField struct {
+	Name string
+	// Type is the search type of the field.
+	Type       string
+	Facet      *bool
+	Index      *bool
+	Sort       *bool
+	Dimensions *int
+	// Drop is set to remove the field when updating the index.
+	Drop bool
+}
+
 // SearchFactory is used as an intermediate step so that collection can be initialized with properly encoded values.
 type SearchFactory struct {
 	// Name is the index name.
@@ -170,7 +223,16 @@ func (fb *FactoryBuilder) BuildSearch(index string, reqSchema jsoniter.RawMessag
 }
 
 func (*FactoryBuilder) validateSearchSchema(factory *SearchFactory) error {
-	if factory.Source.Type != SearchSourceExternal {
+	switch factory.Source.Type {
+	case SearchSourceExternal:
+		if len(factory.Source.Lookups) > 0 {
+			return errors.InvalidArgument("lookups are only supported for the index source '%s'", SearchSourceTigris)
+		}
+	case SearchSourceTigris:
+		if err := validateSearchSource(factory); err != nil {
+			return err
+		}
+	default:
 		return errors.InvalidArgument("unsupported index source '%s'", factory.Source.Type)
 	}
 
@@ -183,6 +245,31 @@ func (*FactoryBuilder) validateSearchSchema(factory *SearchFactory) error {
 	return nil
 }
 
+func validateSearchSource(factory *SearchFactory) error {
+	if len(factory.Source.CollectionName) == 0 {
+		return errors.InvalidArgument("missing collection of the index source")
+	}
+
+	as := make(map[string]struct{})
+	for _, l := range factory.Source.Lookups {
+		if len(l.CollectionName) == 0 || len(l.LocalField) == 0 || len(l.As) == 0 {
+			return errors.InvalidArgument("lookup requires 'collection', 'local_field' and 'as'")
+		}
+		if GetField(factory.Fields, l.LocalField) == nil {
+			return errors.InvalidArgument("lookup field '%s' is not present in the index", l.LocalField)
+		}
+		if f := GetField(factory.Fields, l.As); f == nil || f.DataType != ObjectType {
+			return errors.InvalidArgument("lookup field '%s' should be an object field of the index", l.As)
+		}
+		if _, ok := as[l.As]; ok {
+			return errors.InvalidArgument("duplicate lookup field '%s'", l.As)
+		}
+		as[l.As] = struct{}{}
+	}
+
+	return nil
+}
+
 // SearchIndex is to manage search index created by the user.
 type SearchIndex struct {
 	// Name is the name of the index.
@@ -194,7 +281,7 @@ type SearchIndex struct {
 	// JSON schema.
 	Schema jsoniter.RawMessage
 	// StoreSchema is the search schema of the underlying search engine.
-	StoreSchema *tsApi.CollectionSchema
+	StoreSchema *StoreSchema
 	// QueryableFields are similar to Fields but these are flattened forms of fields. For instance, a simple field
 	// will be one to one mapped to queryable field but complex fields like object type field there may be more than
 	// one queryableFields. As queryableFields represent a flattened state these can be used as-is to index in memory.
@@ -210,7 +297,7 @@ type SearchIndex struct {
 	int64FieldsPath *int64PathBuilder
 }
 
-func NewSearchIndex(ver uint32, searchStoreName string, factory *SearchFactory, fieldsInSearch []tsApi.Field) *SearchIndex {
+func NewSearchIndex(ver uint32, searchStoreName string, factory *SearchFactory, fieldsInSearch []StoreField) *SearchIndex {
 	queryableFields := NewQueryableFieldsBuilder().BuildQueryableFields(factory.Fields, fieldsInSearch, true)
 
 	var searchIdField *QueryableField
@@ -311,56 +398,59 @@ func (s *SearchIndex) validateLow(value any, keys []string, field *Field) error
 }
 
 func (s *SearchIndex) buildSearchSchema(name string) {
-	ptrTrue, ptrFalse := true, false
-	tsFields := make([]tsApi.Field, 0, len(s.QueryableFields))
+	ptrFalse := false
+	storeFields := make([]StoreField, 0, len(s.QueryableFields))
 	for _, s := range s.QueryableFields {
-		tsFields = append(tsFields, tsApi.Field{
-			Name:     s.Name(),
-			Type:     s.SearchType,
-			Facet:    &s.Faceted,
-			Index:    &s.SearchIndexed,
-			Sort:     &s.Sortable,
-			Optional: &ptrTrue,
-			NumDim:   s.Dimensions,
+		storeFields = append(storeFields, StoreField{
+			Name:       s.Name(),
+			Type:       s.SearchType,
+			Facet:      &s.Faceted,
+			Index:      &s.SearchIndexed,
+			Sort:       &s.Sortable,
+			Dimensions: s.Dimensions,
 		})
 
 		if s.InMemoryName() != s.Name() {
 			// we are storing this field differently in in-memory store
-			tsFields = append(tsFields, tsApi.Field{
-				Name:     s.InMemoryName(),
-				Type:     s.SearchType,
-				Facet:    &s.Faceted,
-				Index:    &s.SearchIndexed,
-				Sort:     &s.Sortable,
-				Optional: &ptrTrue,
+			storeFields = append(storeFields, StoreField{
+				Name:  s.InMemoryName(),
+				Type:  s.SearchType,
+				Facet: &s.Faceted,
+				Index: &s.SearchIndexed,
+				Sort:  &s.Sortable,
 			})
 		}
 
 		// Save original date as string to disk
 		if !s.IsReserved() && s.DataType == DateTimeType {
-			tsFields = append(tsFields, tsApi.Field{
-				Name:     ToSearchDateKey(s.Name()),
-				Type:     toSearchFieldType(StringType, UnknownType),
-				Facet:    &ptrFalse,
-				Index:    &ptrFalse,
-				Sort:     &ptrFalse,
-				Optional: &ptrTrue,
+			storeFields = append(storeFields, StoreField{
+				Name:  ToSearchDateKey(s.Name()),
+				Type:  toSearchFieldType(StringType, UnknownType),
+				Facet: &ptrFalse,
+				Index: &ptrFalse,
+				Sort:  &ptrFalse,
+			})
+		}
+		// Save original decimal as string to disk
+		if !s.IsReserved() && s.DataType == DecimalType {
+			storeFields = append(storeFields, StoreField{
+				Name:  ToSearchDecimalKey(s.Name()),
+				Type:  toSearchFieldType(StringType, UnknownType),
+				Facet: &ptrFalse,
+				Index: &ptrFalse,
+				Sort:  &ptrFalse,
 			})
 		}
 	}
 
-	s.StoreSchema = &tsApi.CollectionSchema{
-		Name:   name,
-		Fields: tsFields,
-	}
-	if len(s.TokenSeparators) > 0 {
-		s.StoreSchema.TokenSeparators = &s.TokenSeparators
+	s.StoreSchema = &StoreSchema{
+		Name:            name,
+		Fields:          storeFields,
+		TokenSeparators: s.TokenSeparators,
 	}
 }
 
-func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fieldsInSearch []tsApi.Field) []tsApi.Field {
-	ptrTrue := true
-
+func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fieldsInSearch []StoreField) []StoreField {
 	incomingQueryable := NewQueryableFieldsBuilder().BuildQueryableFields(s.Fields, fieldsInSearch, true)
 
 	existingFieldMap := make(map[string]*QueryableField)
@@ -368,12 +458,12 @@ func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fie
 		existingFieldMap[f.FieldName] = f
 	}
 
-	fieldsInSearchMap := make(map[string]tsApi.Field)
+	fieldsInSearchMap := make(map[string]StoreField)
 	for _, f := range fieldsInSearch {
 		fieldsInSearchMap[f.Name] = f
 	}
 
-	tsFields := make([]tsApi.Field, 0, len(incomingQueryable))
+	storeFields := make([]StoreField, 0, len(incomingQueryable))
 	for _, f := range incomingQueryable {
 		e := existingFieldMap[f.FieldName]
 		delete(existingFieldMap, f.FieldName)
@@ -384,43 +474,67 @@ func (s *SearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, fie
 
 		// attribute changed, drop the field first
 		if e != nil {
-			tsFields = append(tsFields, tsApi.Field{
+			storeFields = append(storeFields, StoreField{
 				Name: f.FieldName,
-				Drop: &ptrTrue,
+				Drop: true,
 			})
 		} else {
 			// this can happen if update request is timed out on Tigris side but succeed on search
 			if _, found := fieldsInSearchMap[f.FieldName]; found {
-				tsFields = append(tsFields, tsApi.Field{
+				storeFields = append(storeFields, StoreField{
 					Name: f.FieldName,
-					Drop: &ptrTrue,
+					Drop: true,
 				})
 			}
 		}
 
 		// add new field
-		tsFields = append(tsFields, tsApi.Field{
-			Name:     f.FieldName,
-			Type:     f.SearchType,
-			Facet:    &f.Faceted,
-			Index:    &f.SearchIndexed,
-			Sort:     &f.Sortable,
-			Optional: &ptrTrue,
-			NumDim:   f.Dimensions,
+		storeFields = append(storeFields, StoreField{
+			Name:       f.FieldName,
+			Type:       f.SearchType,
+			Facet:      &f.Faceted,
+			Index:      &f.SearchIndexed,
+			Sort:       &f.Sortable,
+			Dimensions: f.Dimensions,
 		})
 	}
 
 	// drop fields non existing in new schema
 	for _, f := range existingFieldMap {
-		tsField := tsApi.Field{
+		storeField := StoreField{
 			Name: f.FieldName,
-			Drop: &ptrTrue,
+			Drop: true,
 		}
 
-		tsFields = append(tsFields, tsField)
+		storeFields = append(storeFields, storeField)
 	}
 
-	return tsFields
+	return storeFields
+}
+
+// CheckInPlaceUpdate returns an error if the delta fields change the attributes of an existing field of the index. The
+// search store drops such a field and adds it back, which loses its values in the documents that are already indexed,
+// and these documents can't be indexed again as only the user has them.
+func (s *SearchIndex) CheckInPlaceUpdate(existingFields []*QueryableField, deltaFields []StoreField) error {
+	existing := make(map[string]struct{}, len(existingFields))
+	for _, f := range existingFields {
+		existing[f.FieldName] = struct{}{}
+	}
+
+	dropped := make(map[string]struct{})
+	for _, f := range deltaFields {
+		if _, ok := existing[f.Name]; !ok {
+			continue
+		}
+
+		if f.Drop {
+			dropped[f.Name] = struct{}{}
+		} else if _, ok := dropped[f.Name]; ok {
+			return errors.InvalidArgument("changing the attributes of the field '%s' of the search index '%s' is not supported, drop the field or create a new index", f.Name, s.Name)
+		}
+	}
+
+	return nil
 }
 
 func (s *SearchIndex) GetInt64FieldsPath() map[string]struct{} {
@@ -433,22 +547,27 @@ type ImplicitSearchIndex struct {
 	// Name is the name of the index.
 	Name string
 	// StoreSchema is the search schema of the underlying search engine.
-	StoreSchema *tsApi.CollectionSchema
+	StoreSchema *StoreSchema
 	// QueryableFields are similar to Fields but these are flattened forms of fields. For instance, a simple field
 	// will be one to one mapped to queryable field but complex fields like object type field there may be more than
 	// one queryableFields. As queryableFields represent a flattened state these can be used as-is to index in memory.
-	QueryableFields     []*QueryableField
-	prevVersionInSearch []tsApi.Field
+	QueryableFields []*QueryableField
+	// Shadow is the next version of the index while it is being rebuilt in the background. The documents are written
+	// to both the indexes until the shadow replaces this index in the search store. It is nil if there is no rebuild.
+	Shadow              *ImplicitSearchIndex
+	fields              []*Field
+	prevVersionInSearch []StoreField
 	// State will start tracking whether collection search index is active or not
 	state SearchIndexState
 }
 
-func NewImplicitSearchIndex(name string, searchStoreName string, fields []*Field, prevVersionInSearch []tsApi.Field) *ImplicitSearchIndex {
+func NewImplicitSearchIndex(name string, searchStoreName string, fields []*Field, prevVersionInSearch []StoreField) *ImplicitSearchIndex {
 	// this is created by collection so the forSearchIndex is false.
 	queryableFields := NewQueryableFieldsBuilder().BuildQueryableFields(fields, prevVersionInSearch, false)
 	index := &ImplicitSearchIndex{
 		Name:                name,
 		QueryableFields:     queryableFields,
+		fields:              fields,
 		prevVersionInSearch: prevVersionInSearch,
 	}
 
@@ -479,9 +598,45 @@ func (s *ImplicitSearchIndex) StoreIndexName() string {
 	return s.StoreSchema.Name
 }
 
+// NewShadow returns a fresh version of this index stored as searchStoreName. The shadow doesn't depend on the fields
+// that are already in the search store, so all the fields get the attributes derived from the current schema.
+func (s *ImplicitSearchIndex) NewShadow(searchStoreName string) *ImplicitSearchIndex {
+	shadow := NewImplicitSearchIndex(s.Name, searchStoreName, s.fields, nil)
+	shadow.SetState(SearchIndexBuilding)
+
+	return shadow
+}
+
+// RequiresRebuild returns true if the search store can't be updated in place with the delta fields without losing the
+// indexed values of the existing documents. This is the case when an existing field is dropped and added back with
+// different attributes, or when a field keeps the type that is in the search store instead of the one in the schema.
+func (s *ImplicitSearchIndex) RequiresRebuild(deltaFields []StoreField) bool {
+	dropped := make(map[string]struct{})
+	for _, f := range deltaFields {
+		if f.Drop {
+			dropped[f.Name] = struct{}{}
+		} else if _, ok := dropped[f.Name]; ok {
+			return true
+		}
+	}
+
+	schemaTypes := make(map[string]string)
+	for _, f := range NewQueryableFieldsBuilder().BuildQueryableFields(s.fields, nil, false) {
+		schemaTypes[f.FieldName] = f.SearchType
+	}
+
+	for _, f := range s.QueryableFields {
+		if searchType, ok := schemaTypes[f.FieldName]; ok && searchType != f.SearchType {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *ImplicitSearchIndex) buildSearchSchema(searchStoreName string) {
-	ptrTrue, ptrFalse := true, false
-	tsFields := make([]tsApi.Field, 0, len(s.QueryableFields))
+	ptrFalse := false
+	storeFields := make([]StoreField, 0, len(s.QueryableFields))
 
 	for _, f := range s.QueryableFields {
 		// the implicit search index by default index all the fields that are indexable and same applies to facet/sort.
@@ -497,49 +652,54 @@ func (s *ImplicitSearchIndex) buildSearchSchema(searchStoreName string) {
 			shouldFacet = true
 		}
 
-		tsFields = append(tsFields, tsApi.Field{
-			Name:     f.Name(),
-			Type:     f.SearchType,
-			Facet:    &shouldFacet,
-			Index:    &shouldIndex,
-			Sort:     &shouldSort,
-			Optional: &ptrTrue,
-			NumDim:   f.Dimensions,
+		storeFields = append(storeFields, StoreField{
+			Name:       f.Name(),
+			Type:       f.SearchType,
+			Facet:      &shouldFacet,
+			Index:      &shouldIndex,
+			Sort:       &shouldSort,
+			Dimensions: f.Dimensions,
 		})
 
 		if f.InMemoryName() != f.Name() {
 			// we are storing this field differently in in-memory store
-			tsFields = append(tsFields, tsApi.Field{
-				Name:     f.InMemoryName(),
-				Type:     f.SearchType,
-				Facet:    &shouldFacet,
-				Index:    &shouldIndex,
-				Sort:     &shouldSort,
-				Optional: &ptrTrue,
+			storeFields = append(storeFields, StoreField{
+				Name:  f.InMemoryName(),
+				Type:  f.SearchType,
+				Facet: &shouldFacet,
+				Index: &shouldIndex,
+				Sort:  &shouldSort,
 			})
 		}
 		// Save original date as string to disk
 		if !f.IsReserved() && f.DataType == DateTimeType {
-			tsFields = append(tsFields, tsApi.Field{
-				Name:     ToSearchDateKey(f.Name()),
-				Type:     toSearchFieldType(StringType, UnknownType),
-				Facet:    &ptrFalse,
-				Index:    &ptrFalse,
-				Sort:     &ptrFalse,
-				Optional: &ptrTrue,
+			storeFields = append(storeFields, StoreField{
+				Name:  ToSearchDateKey(f.Name()),
+				Type:  toSearchFieldType(StringType, UnknownType),
+				Facet: &ptrFalse,
+				Index: &ptrFalse,
+				Sort:  &ptrFalse,
+			})
+		}
+		// Save original decimal as string to disk
+		if !f.IsReserved() && f.DataType == DecimalType {
+			storeFields = append(storeFields, StoreField{
+				Name:  ToSearchDecimalKey(f.Name()),
+				Type:  toSearchFieldType(StringType, UnknownType),
+				Facet: &ptrFalse,
+				Index: &ptrFalse,
+				Sort:  &ptrFalse,
 			})
 		}
 	}
 
-	s.StoreSchema = &tsApi.CollectionSchema{
+	s.StoreSchema = &StoreSchema{
 		Name:   searchStoreName,
-		Fields: tsFields,
+		Fields: storeFields,
 	}
 }
 
-func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, incomingFields []*Field) []tsApi.Field {
-	ptrTrue := true
-
+func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableField, incomingFields []*Field) []StoreField {
 	incomingQueryable := NewQueryableFieldsBuilder().BuildQueryableFields(incomingFields, s.prevVersionInSearch, false)
 
 	existingFieldMap := make(map[string]*QueryableField)
@@ -547,12 +707,12 @@ func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableFi
 		existingFieldMap[f.FieldName] = f
 	}
 
-	fieldsInSearchMap := make(map[string]tsApi.Field)
+	fieldsInSearchMap := make(map[string]StoreField)
 	for _, f := range s.prevVersionInSearch {
 		fieldsInSearchMap[f.Name] = f
 	}
 
-	tsFields := make([]tsApi.Field, 0, len(incomingQueryable))
+	storeFields := make([]StoreField, 0, len(incomingQueryable))
 	for _, f := range incomingQueryable {
 		e := existingFieldMap[f.FieldName]
 		delete(existingFieldMap, f.FieldName)
@@ -587,41 +747,60 @@ func (s *ImplicitSearchIndex) GetSearchDeltaFields(existingFields []*QueryableFi
 
 		// attribute changed, drop the field first
 		if e != nil && stateChanged {
-			tsFields = append(tsFields, tsApi.Field{
+			storeFields = append(storeFields, StoreField{
 				Name: f.FieldName,
-				Drop: &ptrTrue,
+				Drop: true,
 			})
 		} else {
 			// this can happen if update request is timed out on Tigris side but succeed on search
 			if _, found := fieldsInSearchMap[f.FieldName]; found {
-				tsFields = append(tsFields, tsApi.Field{
+				storeFields = append(storeFields, StoreField{
 					Name: f.FieldName,
-					Drop: &ptrTrue,
+					Drop: true,
 				})
 			}
 		}
 
 		// add new field
-		tsFields = append(tsFields, tsApi.Field{
-			Name:     f.FieldName,
-			Type:     f.SearchType,
-			Facet:    &shouldFacet,
-			Index:    &shouldIndex,
-			Sort:     &shouldSort,
-			Optional: &ptrTrue,
-			NumDim:   f.Dimensions,
+		storeFields = append(storeFields, StoreField{
+			Name:       f.FieldName,
+			Type:       f.SearchType,
+			Facet:      &shouldFacet,
+			Index:      &shouldIndex,
+			Sort:       &shouldSort,
+			Dimensions: f.Dimensions,
 		})
 	}
 
 	// drop fields non existing in new schema
 	for _, f := range existingFieldMap {
-		tsField := tsApi.Field{
+		storeField := StoreField{
 			Name: f.FieldName,
-			Drop: &ptrTrue,
+			Drop: true,
 		}
 
-		tsFields = append(tsFields, tsField)
+		storeFields = append(storeFields, storeField)
+	}
+
+	return storeFields
+}
+
+// GeoPointToSearch converts the geopoint from {"lat": <lat>, "lon": <lon>} to [<lat>, <lon>], the format used by the
+// search backend.
+func GeoPointToSearch(value any) (any, error) {
+	point, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.InvalidArgument("geopoint needs to be an object with 'lat' and 'lon'")
+	}
+
+	return []any{point["lat"], point["lon"]}, nil
+}
+
+// GeoPointFromSearch is the inverse of GeoPointToSearch.
+func GeoPointFromSearch(value any) any {
+	if arr, ok := value.([]any); ok && len(arr) == 2 {
+		return map[string]any{"lat": arr[0], "lon": arr[1]}
 	}
 
-	return tsFields
+	return value
 }
@@ -184,6 +184,26 @@ func TestSearchIndex_Schema(t *testing.T) {
 			[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "array", "items": {"type": "integer"}, "id": true}}}`),
 			"Cannot have field 'b' as 'id'. Only string type is supported as 'id' field",
 		},
+		{
+			[]byte(`{"title": "t1", "source": {"type": "tigris", "collection": "products", "lookups": [{"collection": "brands", "local_field": "brand_id", "as": "brand"}]}, "properties": { "brand_id": {"type": "string"}, "brand": {"type": "object", "properties": { "name": {"type": "string"}}}}}`),
+			"",
+		},
+		{
+			[]byte(`{"title": "t1", "source": {"type": "tigris"}, "properties": { "a": {"type": "string"}}}`),
+			"missing collection of the index source",
+		},
+		{
+			[]byte(`{"title": "t1", "source": {"type": "tigris", "collection": "products", "lookups": [{"collection": "brands", "local_field": "brand_id", "as": "brand"}]}, "properties": { "brand_id": {"type": "string"}, "brand": {"type": "string"}}}`),
+			"lookup field 'brand' should be an object field of the index",
+		},
+		{
+			[]byte(`{"title": "t1", "source": {"type": "tigris", "collection": "products", "lookups": [{"collection": "brands", "local_field": "brand_id", "as": "brand"}]}, "properties": { "brand": {"type": "object", "properties": { "name": {"type": "string"}}}}}`),
+			"lookup field 'brand_id' is not present in the index",
+		},
+		{
+			[]byte(`{"title": "t1", "source": {"type": "external", "lookups": [{"collection": "brands", "local_field": "brand_id", "as": "brand"}]}, "properties": { "a": {"type": "string"}}}`),
+			"lookups are only supported for the index source 'tigris'",
+		},
 	}
 	for _, c := range cases {
 		_, err := NewFactoryBuilder(true).BuildSearch("t1", c.schema)
@@ -303,4 +323,66 @@ func TestSearchIndex_Validate(t *testing.T) {
 			require.NoError(t, idx.Validate(mp))
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestSearchIndex_CheckInPlaceUpdate(t *testing.T) {
+	existing, err := NewFactoryBuilder(true).BuildSearch("t1", []byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "integer"}}}`))
+	require.NoError(t, err)
+	index := NewSearchIndex(1, "t1", existing, nil)
+
+	for _, c := range []struct {
+		schema []byte
+		valid  bool
+	}{
+		{[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "integer"}, "c": {"type": "string"}}}`), true},
+		{[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}}}`), true},
+		{[]byte(`{"title": "t1", "properties": { "a": {"type": "string", "sort": true}, "b": {"type": "integer"}}}`), false},
+		{[]byte(`{"title": "t1", "properties": { "a": {"type": "string"}, "b": {"type": "integer", "facet": true}}}`), false},
+	} {
+		factory, err := NewFactoryBuilder(true).BuildSearch("t1", c.schema)
+		require.NoError(t, err)
+
+		updated := NewSearchIndex(2, "t1", factory, nil)
+		err = updated.CheckInPlaceUpdate(index.QueryableFields, updated.GetSearchDeltaFields(index.QueryableFields, nil))
+		if c.valid {
+			require.NoError(t, err, string(c.schema))
+		} else {
+			require.Error(t, err, string(c.schema))
+		}
+	}
+}
+
+func TestImplicitSearchIndex_Rebuild(t *testing.T) {
+	reqSchema := []byte(`{
+	"title": "t1",
+	"properties": {
+		"id": { "type": "integer" },
+		"name": { "type": "string" },
+		"items": { "type": "array", "items": { "type": "string" } }
+	},
+	"primary_key": ["id"]
+}`)
+
+	schFactory, err := NewFactoryBuilder(true).Build("t1", reqSchema)
+	require.NoError(t, err)
+
+	index := NewImplicitSearchIndex("t1", "t1", schFactory.Fields, nil)
+	require.False(t, index.RequiresRebuild(nil))
+	require.False(t, index.RequiresRebuild([]StoreField{{Name: "name", Type: "string"}}))
+	require.False(t, index.RequiresRebuild([]StoreField{{Name: "name", Drop: true}}))
+	require.True(t, index.RequiresRebuild([]StoreField{{Name: "name", Drop: true}, {Name: "name", Type: "string"}}))
+
+	// the array is still packed as a string in the search store
+	legacy := NewImplicitSearchIndex("t1", "t1", schFactory.Fields, []StoreField{{Name: "items", Type: "string"}})
+	require.True(t, legacy.RequiresRebuild(nil))
+
+	shadow := legacy.NewShadow("t1.v1")
+	require.Equal(t, "t1.v1", shadow.StoreIndexName())
+	require.Equal(t, SearchIndexBuilding, shadow.GetState())
+	require.False(t, shadow.RequiresRebuild(nil))
+	for _, f := range shadow.StoreSchema.Fields {
+		if f.Name == "items" {
+			require.Equal(t, "string[]", f.Type)
+		}
+	}
+}
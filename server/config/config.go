@@ -136,6 +136,12 @@ func LoadConfig(config any) {
 		log.Fatal().Err(err).Msg("error unmarshalling config")
 	}
 
+	if c, ok := config.(*Config); ok {
+		if err := c.Validate(); err != nil {
+			log.Fatal().Err(err).Msg("invalid config")
+		}
+	}
+
 	log.Debug().Interface("config", &config).Msg("final")
 	if !util.IsTTY(os.Stdout) {
 		spew.Dump(viper.AllKeys())
@@ -182,6 +188,11 @@ func GetTestFDBConfig(path string) (*FoundationDBConfig, error) {
 func GetTestCacheConfig() *CacheConfig {
 	LoadEnvironment()
 
+	// the tests run without a Redis server with TIGRIS_SERVER_CACHE_BACKEND=memory
+	if backend := os.Getenv("TIGRIS_SERVER_CACHE_BACKEND"); backend != "" {
+		DefaultConfig.Cache.Backend = backend
+	}
+
 	if GetEnvironment() == EnvTest {
 		DefaultConfig.Cache.Host = "tigris_cache"
 		return &DefaultConfig.Cache
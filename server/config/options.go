@@ -15,6 +15,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/auth0/go-jwt-middleware/v2/validator"
@@ -26,6 +27,26 @@ const (
 	RealtimeServerType = "realtime"
 )
 
+const (
+	TypesenseSearchBackend = "typesense"
+	EmbeddedSearchBackend  = "embedded"
+)
+
+const (
+	RedisCacheBackend  = "redis"
+	MemoryCacheBackend = "memory"
+)
+
+// Validate returns an error if a setting has a value the server doesn't know, instead of silently falling back to a
+// default behavior.
+func (c *Config) Validate() error {
+	if err := c.Search.Validate(); err != nil {
+		return err
+	}
+
+	return c.Cache.Validate()
+}
+
 type ServerConfig struct {
 	Host         string
 	Port         int16
@@ -50,7 +71,9 @@ type Config struct {
 	KV              KVConfig             `json:"kv"               yaml:"kv"`
 	SecondaryIndex  SecondaryIndexConfig `json:"secondary_index"  mapstructure:"secondary_index"  yaml:"secondary_index"`
 	Workers         WorkersConfig        `json:"workers"          yaml:"workers"`
+	Embedding       EmbeddingConfig      `json:"embedding"        yaml:"embedding"`
 	Cache           CacheConfig          `json:"cache"            yaml:"cache"`
+	Realtime        RealtimeConfig       `json:"realtime"         yaml:"realtime"`
 	Tracing         TracingConfig        `json:"tracing"          yaml:"tracing"`
 	Metrics         MetricsConfig        `json:"metrics"          yaml:"metrics"`
 	Profiling       ProfilingConfig      `json:"profiling"        yaml:"profiling"`
@@ -177,6 +200,7 @@ type MetricsConfig struct {
 	Auth              AuthMetricsConfig           `json:"auth"                 mapstructure:"auth"                 yaml:"auth"`
 	SecondaryIndex    SecondaryIndexMetricsConfig `json:"secondary_index"      mapstructure:"secondary_index"      yaml:"secondary_index"`
 	Queue             QueueMetricsConfig          `json:"queue"                mapstructure:"queue"                yaml:"queue"`
+	Cache             CacheMetricsConfig          `json:"cache"                mapstructure:"cache"                yaml:"cache"`
 	Metronome         MetronomeMetricsConfig      `json:"metronome"            mapstructure:"metronome"            yaml:"metronome"`
 }
 
@@ -258,12 +282,31 @@ type QueueMetricsConfig struct {
 	Enabled bool `json:"enabled" mapstructure:"enabled" yaml:"enabled"`
 }
 
+type CacheMetricsConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled" yaml:"enabled"`
+}
+
 type WorkersConfig struct {
 	Enabled       bool `json:"enabled"        mapstructure:"enabled"        yaml:"enabled"`
 	Count         uint `json:"count"          mapstructure:"count"          yaml:"count"`
 	SearchEnabled bool `json:"search_enabled" mapstructure:"search_enabled" yaml:"search_enabled"`
 }
 
+// EmbeddingConfig lists the embedding providers that can be referenced by the "embedding" attribute of vector fields.
+// The "hash" provider is always available and doesn't need to be configured.
+type EmbeddingConfig struct {
+	Providers []EmbeddingProviderConfig `json:"providers" mapstructure:"providers" yaml:"providers"`
+}
+
+// EmbeddingProviderConfig is an embedding service speaking the OpenAI compatible embeddings API.
+type EmbeddingProviderConfig struct {
+	Name    string        `json:"name"     mapstructure:"name"     yaml:"name"`
+	URL     string        `json:"url"      mapstructure:"url"      yaml:"url"`
+	AuthKey string        `json:"auth_key" mapstructure:"auth_key" yaml:"auth_key"`
+	Model   string        `json:"model"    mapstructure:"model"    yaml:"model"`
+	Timeout time.Duration `json:"timeout"  mapstructure:"timeout"  yaml:"timeout"`
+}
+
 type ProfilingConfig struct {
 	Enabled         bool `json:"enabled"          mapstructure:"enabled"          yaml:"enabled"`
 	EnableCPU       bool `json:"enable_cpu"       mapstructure:"enable_cpu"       yaml:"enable_cpu"`
@@ -379,6 +422,7 @@ var DefaultConfig = Config{
 		StreamBuffer:   200,
 	},
 	Search: SearchConfig{
+		Backend:           TypesenseSearchBackend,
 		Host:              "localhost",
 		Port:              8108,
 		ReadEnabled:       true,
@@ -388,6 +432,12 @@ var DefaultConfig = Config{
 		Compression:       false,
 		IgnoreExtraFields: false,
 		LogFilter:         false,
+		Analytics: SearchAnalyticsConfig{
+			Enabled:       true,
+			FlushInterval: time.Minute,
+			Retention:     30 * 24 * time.Hour,
+			MaxPending:    10000,
+		},
 	},
 	KV: KVConfig{
 		Chunking:             false,
@@ -400,10 +450,15 @@ var DefaultConfig = Config{
 		MutateEnabled: false,
 	},
 	Cache: CacheConfig{
+		Backend: RedisCacheBackend,
 		Host:    "0.0.0.0",
 		Port:    6379,
 		MaxScan: 500,
 	},
+	Realtime: RealtimeConfig{
+		MaxReplayMessages: 10000,
+		MaxReplayAge:      24 * time.Hour,
+	},
 	Tracing: TracingConfig{
 		Enabled: false,
 		Datadog: DatadogTracingConfig{
@@ -517,6 +572,9 @@ var DefaultConfig = Config{
 		Queue: QueueMetricsConfig{
 			Enabled: true,
 		},
+		Cache: CacheMetricsConfig{
+			Enabled: true,
+		},
 	},
 	Profiling: ProfilingConfig{
 		Enabled:    false,
@@ -555,6 +613,7 @@ var DefaultConfig = Config{
 			DataSizeLimit:   100 * 1024 * 1024,
 			RefreshInterval: 600 * time.Second,
 		},
+		SuggestRate: 50,
 	},
 	Observability: ObservabilityConfig{
 		Enabled:     false,
@@ -614,6 +673,10 @@ type FoundationDBConfig struct {
 }
 
 type SearchConfig struct {
+	// Backend is either "typesense" to use a Typesense server or "embedded" to run the search inside the server
+	// process, in which case the indexes are persisted in the key-value store. Each server reloads all the embedded
+	// indexes when another one changes them, so it is meant for a single server or a low write rate.
+	Backend      string `json:"backend"       mapstructure:"backend"       yaml:"backend"`
 	Host         string `json:"host"          mapstructure:"host"          yaml:"host"`
 	Port         int16  `json:"port"          mapstructure:"port"          yaml:"port"`
 	AuthKey      string `json:"auth_key"      mapstructure:"auth_key"      yaml:"auth_key"`
@@ -631,6 +694,28 @@ type SearchConfig struct {
 	LogFilter         bool `json:"log_filter"          mapstructure:"log_filter"          yaml:"log_filter"`
 
 	DoNotReloadMetadata bool `json:"do_not_reload_metadata" mapstructure:"do_not_reload_metadata" yaml:"do_not_reload_metadata"`
+
+	Analytics SearchAnalyticsConfig `json:"analytics" mapstructure:"analytics" yaml:"analytics"`
+}
+
+func (c *SearchConfig) Validate() error {
+	switch c.Backend {
+	case TypesenseSearchBackend, EmbeddedSearchBackend:
+		return nil
+	default:
+		return fmt.Errorf("unsupported search backend '%s', expected '%s' or '%s'", c.Backend,
+			TypesenseSearchBackend, EmbeddedSearchBackend)
+	}
+}
+
+// SearchAnalyticsConfig controls the recording of the search queries. The statistics are aggregated in memory and
+// flushed to the metadata every FlushInterval, the statistics older than Retention are removed during the flush.
+// MaxPending bounds the number of distinct queries buffered in memory between two flushes.
+type SearchAnalyticsConfig struct {
+	Enabled       bool          `json:"enabled"        mapstructure:"enabled"        yaml:"enabled"`
+	FlushInterval time.Duration `json:"flush_interval" mapstructure:"flush_interval" yaml:"flush_interval"`
+	Retention     time.Duration `json:"retention"      mapstructure:"retention"      yaml:"retention"`
+	MaxPending    int           `json:"max_pending"    mapstructure:"max_pending"    yaml:"max_pending"`
 }
 
 type SecondaryIndexConfig struct {
@@ -640,9 +725,62 @@ type SecondaryIndexConfig struct {
 }
 
 type CacheConfig struct {
+	// Backend is either "redis" to use a Redis server or "memory" to keep the keys and the streams in the server
+	// process, in which case they are lost on restart and not shared with the other servers.
+	Backend string `json:"backend"  mapstructure:"backend"  yaml:"backend"`
 	Host    string `json:"host"     mapstructure:"host"     yaml:"host"`
 	Port    int16  `json:"port"     mapstructure:"port"     yaml:"port"`
 	MaxScan int64  `json:"max_scan" mapstructure:"max_scan" yaml:"max_scan"`
+	// DefaultMaxKeys and DefaultMaxBytes bound the caches created without limits, the existing ones included, their
+	// keys are evicted with the LRU policy. Zero is unbounded.
+	DefaultMaxKeys  int64 `json:"default_max_keys"  mapstructure:"default_max_keys"  yaml:"default_max_keys"`
+	DefaultMaxBytes int64 `json:"default_max_bytes" mapstructure:"default_max_bytes" yaml:"default_max_bytes"`
+	// MaxKeys and MaxBytes cap the limits of every cache, whatever limits it was created with. Zero is uncapped.
+	MaxKeys  int64 `json:"max_keys"  mapstructure:"max_keys"  yaml:"max_keys"`
+	MaxBytes int64 `json:"max_bytes" mapstructure:"max_bytes" yaml:"max_bytes"`
+}
+
+func (c *CacheConfig) Validate() error {
+	switch c.Backend {
+	case RedisCacheBackend, MemoryCacheBackend:
+	default:
+		return fmt.Errorf("unsupported cache backend '%s', expected '%s' or '%s'", c.Backend,
+			RedisCacheBackend, MemoryCacheBackend)
+	}
+
+	if c.DefaultMaxKeys < 0 || c.DefaultMaxBytes < 0 || c.MaxKeys < 0 || c.MaxBytes < 0 {
+		return fmt.Errorf("the limits of the caches can't be negative")
+	}
+	if c.MaxKeys > 0 && c.DefaultMaxKeys > c.MaxKeys {
+		return fmt.Errorf("the default max keys of the caches %d is above their max keys %d", c.DefaultMaxKeys, c.MaxKeys)
+	}
+	if c.MaxBytes > 0 && c.DefaultMaxBytes > c.MaxBytes {
+		return fmt.Errorf("the default max bytes of the caches %d is above their max bytes %d", c.DefaultMaxBytes,
+			c.MaxBytes)
+	}
+
+	return nil
+}
+
+type RealtimeConfig struct {
+	// ChannelBridgeEnabled publishes the committed changes of the collections bridged to a realtime channel. The
+	// messages are written to the cache, so it needs to be reachable from the database servers.
+	ChannelBridgeEnabled bool `json:"channel_bridge_enabled" mapstructure:"channel_bridge_enabled" yaml:"channel_bridge_enabled"`
+	// WebhooksEnabled delivers the events of the realtime channels to the webhooks of the projects from the realtime
+	// servers.
+	WebhooksEnabled bool `json:"webhooks_enabled" mapstructure:"webhooks_enabled" yaml:"webhooks_enabled"`
+	// WebhookAllowHTTP allows the webhooks with a plain http URL, only https is allowed otherwise.
+	WebhookAllowHTTP bool `json:"webhook_allow_http" mapstructure:"webhook_allow_http" yaml:"webhook_allow_http"`
+	// WebhookAllowPrivateNetworks allows the webhooks to post to the loopback, link-local and private addresses, they
+	// are rejected otherwise so that a project can't reach the internal services.
+	WebhookAllowPrivateNetworks bool `json:"webhook_allow_private_networks" mapstructure:"webhook_allow_private_networks" yaml:"webhook_allow_private_networks"`
+	// WebhookAllowedHosts are the webhook hosts allowed whatever their addresses are.
+	WebhookAllowedHosts []string `json:"webhook_allowed_hosts" mapstructure:"webhook_allowed_hosts" yaml:"webhook_allowed_hosts"`
+	// MaxReplayMessages is the largest number of messages a subscription can replay with the "last:<n>" position.
+	MaxReplayMessages int64 `json:"max_replay_messages" mapstructure:"max_replay_messages" yaml:"max_replay_messages"`
+	// MaxReplayAge is the oldest a subscription can replay from with the "time:<time>" and "since:<duration>"
+	// positions.
+	MaxReplayAge time.Duration `json:"max_replay_age" mapstructure:"max_replay_age" yaml:"max_replay_age"`
 }
 
 type LimitsConfig struct {
@@ -716,6 +854,10 @@ type QuotaConfig struct {
 
 	WriteUnitSize int
 	ReadUnitSize  int
+	// SuggestRate is the number of suggest requests per second a namespace can issue on a node when the node or the
+	// namespace quotas are enabled, zero is unlimited. The suggest requests are issued on every keystroke by the
+	// typeahead clients, so they are limited by their own rate instead of consuming the read units.
+	SuggestRate int `json:"suggest_rate" mapstructure:"suggest_rate" yaml:"suggest_rate"`
 }
 
 func (s *SearchConfig) IsReadEnabled() bool {
@@ -0,0 +1,43 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	cfg := DefaultConfig
+	require.NoError(t, cfg.Validate())
+
+	cfg.Search.Backend = EmbeddedSearchBackend
+	require.NoError(t, cfg.Validate())
+
+	for _, backend := range []string{"", "Embedded", "embeded"} {
+		cfg.Search.Backend = backend
+		require.Error(t, cfg.Validate(), backend)
+	}
+
+	cfg.Search.Backend = TypesenseSearchBackend
+	cfg.Cache.Backend = MemoryCacheBackend
+	require.NoError(t, cfg.Validate())
+
+	for _, backend := range []string{"", "Memory", "memcached"} {
+		cfg.Cache.Backend = backend
+		require.Error(t, cfg.Validate(), backend)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/lib/embedding"
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/metrics"
@@ -75,8 +76,6 @@ func mainWithCode() int {
 
 	log.Info().Str("version", util.Version).Msgf("Starting server")
 
-	searchStore := search.NewStore(&defaultConfig.Search, defaultConfig.Metrics.Search.Enabled)
-
 	// creating kv store for search and database independently allows us to enable functionality slowly. This is
 	// temporary as once we have functionality tested then
 	kvStoreForSearch, err := kv.StoreForSearch(defaultConfig)
@@ -84,6 +83,13 @@ func mainWithCode() int {
 		log.Error().Err(err).Msg("error initializing kv store for search")
 		return 1
 	}
+
+	var searchStore search.Store
+	if defaultConfig.Search.Backend == config.EmbeddedSearchBackend {
+		searchStore = search.NewEmbeddedStore(kvStoreForSearch, defaultConfig.Metrics.Search.Enabled)
+	} else {
+		searchStore = search.NewStore(&defaultConfig.Search, defaultConfig.Metrics.Search.Enabled)
+	}
 	kvStoreForDatabase, err := kv.StoreForDatabase(defaultConfig)
 	if err != nil {
 		log.Error().Err(err).Msg("error initializing kv store for database")
@@ -106,6 +112,12 @@ func mainWithCode() int {
 	}
 
 	cfg := &config.DefaultConfig
+	for _, p := range cfg.Embedding.Providers {
+		embedding.Register(p.Name, embedding.NewHTTPProvider(p.URL, p.AuthKey, p.Model, p.Timeout))
+	}
+
+	tenantMgr.GetSearchAnalytics().Start()
+
 	request.Init(tenantMgr)
 	_ = quota.Init(tenantMgr, cfg)
 	defer quota.Cleanup()
@@ -0,0 +1,97 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+// ChannelBridge publishes the committed inserts, updates and deletes of the documents of a collection as messages on
+// a realtime channel. When a filter is set, only the inserted and updated documents matching it are published, the
+// deletes are always published as the deleted document is not known.
+type ChannelBridge struct {
+	Collection string
+	Channel    string
+	Filter     []byte
+}
+
+func (b *ChannelBridge) Validate() error {
+	if len(b.Collection) == 0 {
+		return errors.InvalidArgument("channel bridge collection is required")
+	}
+	if len(b.Channel) == 0 {
+		return errors.InvalidArgument("channel bridge channel is required")
+	}
+
+	return nil
+}
+
+func (b *ChannelBridge) same(collection string, channel string) bool {
+	return b.Collection == collection && b.Channel == channel
+}
+
+// CreateOrUpdateChannelBridge stores the bridge in the metadata of the project, replacing the bridge between the same
+// collection and channel.
+func (tenant *Tenant) CreateOrUpdateChannelBridge(ctx context.Context, tx transaction.Tx, project *Project, bridge *ChannelBridge) error {
+	if err := bridge.Validate(); err != nil {
+		return err
+	}
+
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateProjectMetadata(ctx, tx, project, func(md *ProjectMetadata) error {
+		for i := range md.ChannelBridges {
+			if md.ChannelBridges[i].same(bridge.Collection, bridge.Channel) {
+				md.ChannelBridges[i] = *bridge
+				return nil
+			}
+		}
+
+		md.ChannelBridges = append(md.ChannelBridges, *bridge)
+		return nil
+	})
+}
+
+func (tenant *Tenant) ListChannelBridges(ctx context.Context, tx transaction.Tx, project *Project) ([]ChannelBridge, error) {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name())
+	if err != nil {
+		return nil, errors.Internal("failed to get project metadata for project %s", project.Name())
+	}
+
+	return projMetadata.ChannelBridges, nil
+}
+
+func (tenant *Tenant) DeleteChannelBridge(ctx context.Context, tx transaction.Tx, project *Project, collection string, channel string) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateProjectMetadata(ctx, tx, project, func(md *ProjectMetadata) error {
+		for i := range md.ChannelBridges {
+			if md.ChannelBridges[i].same(collection, channel) {
+				md.ChannelBridges = append(md.ChannelBridges[:i], md.ChannelBridges[i+1:]...)
+				return nil
+			}
+		}
+
+		return errors.NotFound("channel bridge from '%s' to '%s' not found", collection, channel)
+	})
+}
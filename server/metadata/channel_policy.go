@@ -0,0 +1,126 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+const (
+	ChannelActionSubscribe = "subscribe"
+	ChannelActionPublish   = "publish"
+	ChannelActionPresence  = "presence"
+)
+
+// ChannelPolicy allows the realtime clients to perform the actions on the channels whose name matches the pattern. In
+// the pattern, "*" matches any characters and "{sub}", "{namespace}", "{project}" and "{role}" are replaced by the
+// claims of the access token of the caller, for example "user-{sub}-*". The policy only applies to the callers having
+// one of the roles, or to all the callers if no role is set. Once a project has a policy, the actions not allowed by
+// any of its policies are denied.
+type ChannelPolicy struct {
+	Pattern string
+	Actions []string
+	Roles   []string
+}
+
+func (p *ChannelPolicy) Validate() error {
+	if len(p.Pattern) == 0 {
+		return errors.InvalidArgument("channel policy pattern is required")
+	}
+	if len(p.Actions) == 0 {
+		return errors.InvalidArgument("channel policy '%s' has no action", p.Pattern)
+	}
+
+	for _, a := range p.Actions {
+		switch a {
+		case ChannelActionSubscribe, ChannelActionPublish, ChannelActionPresence:
+		default:
+			return errors.InvalidArgument("unsupported channel action '%s'", a)
+		}
+	}
+
+	return nil
+}
+
+// CreateOrUpdateChannelPolicy stores the policy in the metadata of the project, replacing the policy with the same
+// pattern.
+func (tenant *Tenant) CreateOrUpdateChannelPolicy(ctx context.Context, tx transaction.Tx, project *Project, policy *ChannelPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateProjectMetadata(ctx, tx, project, func(md *ProjectMetadata) error {
+		for i := range md.ChannelPolicies {
+			if md.ChannelPolicies[i].Pattern == policy.Pattern {
+				md.ChannelPolicies[i] = *policy
+				return nil
+			}
+		}
+
+		md.ChannelPolicies = append(md.ChannelPolicies, *policy)
+		return nil
+	})
+}
+
+func (tenant *Tenant) ListChannelPolicies(ctx context.Context, tx transaction.Tx, project *Project) ([]ChannelPolicy, error) {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name())
+	if err != nil {
+		return nil, errors.Internal("failed to get project metadata for project %s", project.Name())
+	}
+
+	return projMetadata.ChannelPolicies, nil
+}
+
+func (tenant *Tenant) DeleteChannelPolicy(ctx context.Context, tx transaction.Tx, project *Project, pattern string) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateProjectMetadata(ctx, tx, project, func(md *ProjectMetadata) error {
+		for i := range md.ChannelPolicies {
+			if md.ChannelPolicies[i].Pattern == pattern {
+				md.ChannelPolicies = append(md.ChannelPolicies[:i], md.ChannelPolicies[i+1:]...)
+				return nil
+			}
+		}
+
+		return errors.NotFound("channel policy '%s' not found", pattern)
+	})
+}
+
+func (tenant *Tenant) updateProjectMetadata(ctx context.Context, tx transaction.Tx, project *Project, change func(*ProjectMetadata) error) error {
+	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name())
+	if err != nil {
+		return errors.Internal("failed to get project metadata for project %s", project.Name())
+	}
+
+	if err = change(projMetadata); err != nil {
+		return err
+	}
+
+	if err = tenant.namespaceStore.UpdateProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name(), projMetadata); err != nil {
+		return errors.Internal("failed to update project metadata for project %s", project.Name())
+	}
+
+	return nil
+}
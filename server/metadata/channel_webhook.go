@@ -0,0 +1,207 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+const (
+	WebhookEventMessage  = "message"
+	WebhookEventPresence = "presence"
+)
+
+// ChannelWebhook posts the events of the realtime channels whose name matches the pattern to the URL. The pattern
+// matches any characters with "*", for example "orders-*". Events are the kind of events delivered, the messages
+// published on the channels and/or the presence enter, update and leave events. When the secret is set, the requests
+// are signed with it.
+type ChannelWebhook struct {
+	Name    string
+	Pattern string
+	URL     string
+	Secret  string
+	Events  []string
+}
+
+func (w *ChannelWebhook) Validate() error {
+	if len(w.Name) == 0 {
+		return errors.InvalidArgument("channel webhook name is required")
+	}
+	if len(w.Pattern) == 0 {
+		return errors.InvalidArgument("channel webhook '%s' has no pattern", w.Name)
+	}
+
+	if err := validateWebhookURL(w.URL); err != nil {
+		return errors.InvalidArgument("channel webhook '%s' has an invalid url '%s': %s", w.Name, w.URL, err.Error())
+	}
+
+	if len(w.Events) == 0 {
+		return errors.InvalidArgument("channel webhook '%s' has no event", w.Name)
+	}
+	for _, e := range w.Events {
+		switch e {
+		case WebhookEventMessage, WebhookEventPresence:
+		default:
+			return errors.InvalidArgument("unsupported channel webhook event '%s'", e)
+		}
+	}
+
+	return nil
+}
+
+// validateWebhookURL checks the scheme of the URL and that its host isn't an internal address. The host is resolved to
+// reject the names of internal addresses early, the addresses are checked again when the requests are sent.
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || len(u.Hostname()) == 0 {
+		return errors.InvalidArgument("malformed url")
+	}
+
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if !config.DefaultConfig.Realtime.WebhookAllowHTTP {
+			return errors.InvalidArgument("only https is allowed")
+		}
+	default:
+		return errors.InvalidArgument("unsupported scheme '%s'", u.Scheme)
+	}
+
+	host := u.Hostname()
+	if IsWebhookHostAllowed(host) {
+		return nil
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return errors.InvalidArgument("internal host '%s'", host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return CheckWebhookAddress(ip)
+	}
+
+	// a name that doesn't resolve yet is accepted, the requests fail until it does
+	addrs, _ := net.DefaultResolver.LookupIPAddr(context.TODO(), host)
+	for _, addr := range addrs {
+		if err = CheckWebhookAddress(addr.IP); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsWebhookHostAllowed returns true if the host is explicitly allowed in the config, its addresses aren't checked.
+func IsWebhookHostAllowed(host string) bool {
+	for _, allowed := range config.DefaultConfig.Realtime.WebhookAllowedHosts {
+		if strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// webhookInternalNetworks are the shared address space and the IPv6 NAT64 prefix, not covered by the net.IP checks.
+var webhookInternalNetworks = []*net.IPNet{
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("64:ff9b::/96"),
+}
+
+// CheckWebhookAddress returns an error if the webhooks can't be posted to the address. The loopback, link-local,
+// private, unspecified and multicast addresses are rejected, unless the private networks are allowed in the config,
+// so that a webhook can't reach the services of the internal network or the metadata endpoint of the cloud provider.
+func CheckWebhookAddress(ip net.IP) error {
+	if config.DefaultConfig.Realtime.WebhookAllowPrivateNetworks {
+		return nil
+	}
+
+	internal := ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+	for _, n := range webhookInternalNetworks {
+		internal = internal || n.Contains(ip)
+	}
+	if internal {
+		return errors.InvalidArgument("internal address '%s'", ip.String())
+	}
+
+	return nil
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+
+	return n
+}
+
+// CreateOrUpdateChannelWebhook stores the webhook in the metadata of the project, replacing the webhook with the same
+// name.
+func (tenant *Tenant) CreateOrUpdateChannelWebhook(ctx context.Context, tx transaction.Tx, project *Project, webhook *ChannelWebhook) error {
+	if err := webhook.Validate(); err != nil {
+		return err
+	}
+
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateProjectMetadata(ctx, tx, project, func(md *ProjectMetadata) error {
+		for i := range md.ChannelWebhooks {
+			if md.ChannelWebhooks[i].Name == webhook.Name {
+				md.ChannelWebhooks[i] = *webhook
+				return nil
+			}
+		}
+
+		md.ChannelWebhooks = append(md.ChannelWebhooks, *webhook)
+		return nil
+	})
+}
+
+func (tenant *Tenant) ListChannelWebhooks(ctx context.Context, tx transaction.Tx, project *Project) ([]ChannelWebhook, error) {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name())
+	if err != nil {
+		return nil, errors.Internal("failed to get project metadata for project %s", project.Name())
+	}
+
+	return projMetadata.ChannelWebhooks, nil
+}
+
+func (tenant *Tenant) DeleteChannelWebhook(ctx context.Context, tx transaction.Tx, project *Project, name string) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateProjectMetadata(ctx, tx, project, func(md *ProjectMetadata) error {
+		for i := range md.ChannelWebhooks {
+			if md.ChannelWebhooks[i].Name == name {
+				md.ChannelWebhooks = append(md.ChannelWebhooks[:i], md.ChannelWebhooks[i+1:]...)
+				return nil
+			}
+		}
+
+		return errors.NotFound("channel webhook '%s' not found", name)
+	})
+}
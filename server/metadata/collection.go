@@ -33,6 +33,10 @@ type CollectionMetadata struct {
 	ID          uint32                  `json:"id,omitempty"`
 	Indexes     []*schema.Index         `json:"indexes"`
 	SearchState schema.SearchIndexState `json:"search_state"`
+	// SearchVersion is the version of the search index the collection is aliased to, the version zero is the original
+	// index of the collection. SearchShadowVersion is set while the next version of the search index is being built.
+	SearchVersion       uint32 `json:"search_version,omitempty"`
+	SearchShadowVersion uint32 `json:"search_shadow_version,omitempty"`
 }
 
 // CollectionSubspace is used to store metadata about Tigris collections.
@@ -71,9 +75,9 @@ func (c *CollectionSubspace) Create(ctx context.Context, tx transaction.Tx, ns N
 	}
 
 	meta := &CollectionMetadata{
-		collId,
-		indexes,
-		searchState,
+		ID:          collId,
+		Indexes:     indexes,
+		SearchState: searchState,
 	}
 
 	if err := c.insert(ctx, tx, ns.Id(), db.Id(), collName, meta); err != nil {
@@ -134,6 +138,31 @@ func (c *CollectionSubspace) UpdateSearchStatus(ctx context.Context, tx transact
 	return err
 }
 
+// UpdateSearchAlias points the collection to the version of its search index and sets the version that is being built
+// in the background, if any. The search state is updated along with the alias.
+func (c *CollectionSubspace) UpdateSearchAlias(ctx context.Context, tx transaction.Tx, ns Namespace, db *Database, name string,
+	version uint32, shadowVersion uint32, newSearchState schema.SearchIndexState,
+) (*CollectionMetadata, error) {
+	metadata, err := c.Get(ctx, tx, ns.Id(), db.Id(), name)
+	if err != nil {
+		return nil, err
+	}
+	metadata.SearchVersion = version
+	metadata.SearchShadowVersion = shadowVersion
+	metadata.SearchState = newSearchState
+
+	if err = c.updateMetadata(ctx, tx,
+		c.validateArgs(ns.Id(), db.Id(), name, &metadata),
+		c.getKey(ns.Id(), db.Id(), name),
+		collMetaValueVersion,
+		metadata,
+	); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
 func (c *CollectionSubspace) Update(ctx context.Context, tx transaction.Tx, ns Namespace, db *Database, name string,
 	id uint32, updatedIndexes []*schema.Index, newSearchState schema.SearchIndexState,
 ) (*CollectionMetadata, error) {
@@ -383,6 +383,35 @@ func TestCollectionSubspaceMigrationV1(t *testing.T) {
 	require.Equal(t, &CollectionMetadata{ID: 123, SearchState: schema.SearchIndexActive}, collMeta)
 }
 
+func TestCollectionSubspaceSearchAlias(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, tm, cleanup := initCollectionTest(t, ctx)
+	defer cleanup()
+
+	tx, cleanupTx := initTx(t, ctx, tm)
+	defer cleanupTx()
+
+	ns, db := NsAndDB()
+	require.NoError(t, c.insert(ctx, tx, 1, 1, "name9", &CollectionMetadata{ID: 123, SearchState: schema.SearchIndexActive}))
+
+	meta, err := c.UpdateSearchAlias(ctx, tx, ns, db, "name9", 0, 1, schema.SearchIndexActive)
+	require.NoError(t, err)
+	require.Equal(t, &CollectionMetadata{ID: 123, SearchState: schema.SearchIndexActive, SearchShadowVersion: 1}, meta)
+
+	// schema updates keep the alias
+	_, err = c.Update(ctx, tx, ns, db, "name9", 123, nil, schema.SearchIndexActive)
+	require.NoError(t, err)
+
+	_, err = c.UpdateSearchAlias(ctx, tx, ns, db, "name9", 1, 0, schema.SearchIndexActive)
+	require.NoError(t, err)
+
+	meta, err = c.Get(ctx, tx, 1, 1, "name9")
+	require.NoError(t, err)
+	require.Equal(t, &CollectionMetadata{ID: 123, SearchState: schema.SearchIndexActive, SearchVersion: 1}, meta)
+}
+
 func NsAndDB() (Namespace, *Database) {
 	ns := NewTenantNamespace("ns1", NewNamespaceMetadata(1, "1", "ns1"))
 	db := NewDatabase(1, "db1")
@@ -310,6 +310,9 @@ type Dictionary struct {
 	searchSchemaStore *SearchSchemaSubspace
 
 	queueStore *QueueSubspace
+
+	searchAnalyticsStore    *SearchAnalyticsSubspace
+	searchVerificationStore *SearchVerificationSubspace
 }
 
 func NewMetadataDictionary(mdNameRegistry *NameRegistry) *Dictionary {
@@ -326,6 +329,9 @@ func NewMetadataDictionary(mdNameRegistry *NameRegistry) *Dictionary {
 		schemaStore:       NewSchemaStore(mdNameRegistry),
 		searchSchemaStore: NewSearchSchemaStore(mdNameRegistry),
 		queueStore:        queueStore,
+
+		searchAnalyticsStore:    NewSearchAnalyticsStore(mdNameRegistry),
+		searchVerificationStore: NewSearchVerificationStore(mdNameRegistry),
 	}
 }
 
@@ -361,6 +367,14 @@ func (k *Dictionary) Queue() *QueueSubspace {
 	return k.queueStore
 }
 
+func (k *Dictionary) SearchAnalytics() *SearchAnalyticsSubspace {
+	return k.searchAnalyticsStore
+}
+
+func (k *Dictionary) SearchVerification() *SearchVerificationSubspace {
+	return k.searchVerificationStore
+}
+
 // ReserveNamespace is the first step in the encoding and the mapping is passed the caller. As this is the first encoded
 // integer the caller needs to make sure a unique value is assigned to this namespace.
 func (k *Dictionary) ReserveNamespace(ctx context.Context, tx transaction.Tx, namespaceId string,
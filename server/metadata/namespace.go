@@ -21,6 +21,8 @@ import (
 	"github.com/tigrisdata/tigris/keys"
 	"github.com/tigrisdata/tigris/server/defaults"
 	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/cache"
+	"github.com/tigrisdata/tigris/store/search"
 )
 
 // A Namespace is a logical grouping of databases.
@@ -61,6 +63,12 @@ type ProjectMetadata struct {
 	CachesMetadata []CacheMetadata
 	SearchMetadata []SearchMetadata
 	Limits         *ProjectLimits
+	// ChannelPolicies restrict the realtime channels the clients of the project can use, see ChannelPolicy.
+	ChannelPolicies []ChannelPolicy
+	// ChannelBridges publish the changes of the collections of the project on realtime channels, see ChannelBridge.
+	ChannelBridges []ChannelBridge
+	// ChannelWebhooks deliver the events of the realtime channels of the project over HTTP, see ChannelWebhook.
+	ChannelWebhooks []ChannelWebhook
 }
 
 type ProjectLimits struct {
@@ -72,12 +80,18 @@ type CacheMetadata struct {
 	Name      string
 	Creator   string
 	CreatedAt int64
+	// Limits bound the keys and the memory of the cache, nil for the unbounded caches without default TTL and for the
+	// caches created before the limits existed.
+	Limits *cache.Limits
 }
 
 type SearchMetadata struct {
 	Name      string
 	Creator   string
 	CreatedAt int64
+	// Synonyms and Curations are kept here so that they can be pushed again to the search store on a rebuild.
+	Synonyms  []search.Synonym
+	Curations []search.Curation
 }
 
 // StrId returns id assigned to the namespace.
@@ -75,6 +75,9 @@ const (
 	BUILD_INDEX_QUEUE_TASK TaskType = iota
 	TEST_QUEUE_TASK
 	BUILD_SEARCH_INDEX_TASK
+	EMBEDDING_TASK
+	SEARCH_VERIFY_TASK
+	DROP_SEARCH_INDEX_TASK
 )
 
 type IndexBuildTask struct {
@@ -84,14 +87,50 @@ type IndexBuildTask struct {
 	CollName    string `json:"collection"`
 }
 
+// EmbeddingTask computes the vectors of the embedding fields of a collection. Keys are the packed primary keys of the
+// documents to embed, if there are no keys then all the documents of the collection are embedded. Fields restricts the
+// embedding fields to compute, all of them are computed if it is empty.
+type EmbeddingTask struct {
+	NamespaceId string   `json:"tenantId"`
+	ProjName    string   `json:"projectName"`
+	Branch      string   `json:"branch"`
+	CollName    string   `json:"collection"`
+	Keys        [][]byte `json:"keys,omitempty"`
+	Fields      []string `json:"fields,omitempty"`
+}
+
+// SearchVerifyTask verifies the search index of a collection against its primary rows, and repairs the drifted
+// documents if Repair is set.
+type SearchVerifyTask struct {
+	NamespaceId string `json:"tenantId"`
+	ProjName    string `json:"projectName"`
+	Branch      string `json:"branch"`
+	CollName    string `json:"collection"`
+	Repair      bool   `json:"repair"`
+}
+
+// DropSearchIndexTask drops an index of the search store that has been replaced by a rebuilt one.
+type DropSearchIndexTask struct {
+	NamespaceId string `json:"tenantId"`
+	Index       string `json:"index"`
+}
+
+// TaskProgress is reported by the worker processing a long-running item, Processed is the number of units of work
+// done so far, for example the number of documents indexed.
+type TaskProgress struct {
+	Processed int64     `json:"processed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type QueueItem struct {
-	Id         string    `json:"id"`
-	Priority   int64     `json:"priority"`
-	ErrorCount uint8     `json:"error_count"`
-	LeaseId    string    `json:"lease_id,omitempty"`
-	Data       []byte    `json:"data"`
-	TaskType   TaskType  `json:"task_type"`
-	Vesting    time.Time `json:"vesting_time"`
+	Id         string        `json:"id"`
+	Priority   int64         `json:"priority"`
+	ErrorCount uint8         `json:"error_count"`
+	LeaseId    string        `json:"lease_id,omitempty"`
+	Data       []byte        `json:"data"`
+	TaskType   TaskType      `json:"task_type"`
+	Vesting    time.Time     `json:"vesting_time"`
+	Progress   *TaskProgress `json:"progress,omitempty"`
 }
 
 func NewQueueItem(priority int64, data []byte, taskType TaskType) *QueueItem {
@@ -257,6 +296,16 @@ func (q *QueueSubspace) RenewLease(ctx context.Context, tx transaction.Tx, item
 	return q.Enqueue(ctx, tx, item, leaseTime)
 }
 
+// ReportProgress stores the progress of the item and extends its lease.
+func (q *QueueSubspace) ReportProgress(ctx context.Context, tx transaction.Tx, item *QueueItem, processed int64, leaseTime time.Duration) error {
+	item.Progress = &TaskProgress{
+		Processed: processed,
+		UpdatedAt: time.Now(),
+	}
+
+	return q.RenewLease(ctx, tx, item, leaseTime)
+}
+
 func (q *QueueSubspace) GetAll(ctx context.Context, tx transaction.Tx) ([]*QueueItem, error) {
 	currentTime := time.Now().Add(48 * time.Hour)
 	startKey := q.getKey(minVestingTime, 0, "")
@@ -167,6 +167,30 @@ func TestEnqueueObtainComplete(t *testing.T) {
 	checkQueueEmpty(t, queue, tx)
 }
 
+func TestReportProgress(t *testing.T) {
+	item := NewQueueItem(0, []byte("one-item"), TEST_QUEUE_TASK)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	queue, tx, cleanup := initQueueTest(t)
+	defer cleanup()
+
+	assert.NoError(t, queue.Enqueue(ctx, tx, item, 0))
+	working, err := queue.ObtainLease(ctx, tx, item, 10*time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, queue.ReportProgress(ctx, tx, working, 42, 10*time.Second))
+	checkQueueEmpty(t, queue, tx)
+
+	items, err := queue.GetAll(ctx, tx)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NotNil(t, items[0].Progress)
+	require.Equal(t, int64(42), items[0].Progress.Processed)
+
+	assert.NoError(t, queue.Complete(ctx, tx, working))
+}
+
 func TestErrors(t *testing.T) {
 	item := NewQueueItem(0, []byte("one-item"), TEST_QUEUE_TASK)
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,456 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+	ulog "github.com/tigrisdata/tigris/util/log"
+)
+
+// The statistics of the search queries are aggregated per hour and per normalized query text. The FDB structure is:
+// ["search_analytics", version, namespace id, project id, branch, "index" | "collection", name, hour, query] = stats
+//
+// The branch is empty for the search indexes. The rows older than the configured retention are removed when the
+// buffered statistics of the same index or collection are flushed, and the rows of a project when it is dropped. The
+// rows are keyed by the project id, so a project created again with the same name doesn't see the statistics of the
+// dropped one, even those flushed after the drop.
+
+const (
+	searchAnalyticsValueVersion int32 = 1
+	searchAnalyticsBucket             = time.Hour
+	// popularQueriesWindow is the window the popularity of the queries is computed on.
+	popularQueriesWindow = 7 * 24 * time.Hour
+	// maxSearchAnalyticsFilters caps the number of distinct filter fields kept for a query.
+	maxSearchAnalyticsFilters = 32
+
+	searchAnalyticsIndexKey      = "index"
+	searchAnalyticsCollectionKey = "collection"
+)
+
+// SearchAnalyticsSource identifies the search index, or the collection, the search queries are run on. Branch is
+// only set for the collections, Project is only used in the logs.
+type SearchAnalyticsSource struct {
+	NamespaceId uint32
+	ProjectId   uint32
+	Project     string
+	Branch      string
+	Index       string
+	Collection  string
+}
+
+func (s SearchAnalyticsSource) kindAndName() (string, string) {
+	if len(s.Collection) > 0 {
+		return searchAnalyticsCollectionKey, s.Collection
+	}
+
+	return searchAnalyticsIndexKey, s.Index
+}
+
+// SearchQuery is a single search executed on an index or a collection.
+type SearchQuery struct {
+	Query   string
+	Filters []string
+	Found   int64
+	Latency time.Duration
+	Page    int32
+}
+
+// SearchQueryStats is the aggregated statistics of a normalized search query.
+type SearchQueryStats struct {
+	Query        string        `json:"query"`
+	Count        int64         `json:"count"`
+	ZeroResults  int64         `json:"zero_results"`
+	TotalLatency time.Duration `json:"total_latency"`
+	MaxLatency   time.Duration `json:"max_latency"`
+	MaxPage      int32         `json:"max_page"`
+	Filters      []string      `json:"filters,omitempty"`
+	LastSeen     time.Time     `json:"last_seen"`
+}
+
+// AvgLatency returns the average latency of the query.
+func (s *SearchQueryStats) AvgLatency() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.TotalLatency / time.Duration(s.Count)
+}
+
+func (s *SearchQueryStats) add(q *SearchQuery, at time.Time) {
+	s.merge(&SearchQueryStats{
+		Count:        1,
+		ZeroResults:  zeroResults(q.Found),
+		TotalLatency: q.Latency,
+		MaxLatency:   q.Latency,
+		MaxPage:      q.Page,
+		Filters:      q.Filters,
+		LastSeen:     at,
+	})
+}
+
+func (s *SearchQueryStats) merge(o *SearchQueryStats) {
+	s.Count += o.Count
+	s.ZeroResults += o.ZeroResults
+	s.TotalLatency += o.TotalLatency
+	if o.MaxLatency > s.MaxLatency {
+		s.MaxLatency = o.MaxLatency
+	}
+	if o.MaxPage > s.MaxPage {
+		s.MaxPage = o.MaxPage
+	}
+	if o.LastSeen.After(s.LastSeen) {
+		s.LastSeen = o.LastSeen
+	}
+
+	for _, f := range o.Filters {
+		i := sort.SearchStrings(s.Filters, f)
+		if (i < len(s.Filters) && s.Filters[i] == f) || len(s.Filters) >= maxSearchAnalyticsFilters {
+			continue
+		}
+		s.Filters = append(s.Filters[:i], append([]string{f}, s.Filters[i:]...)...)
+	}
+}
+
+func zeroResults(found int64) int64 {
+	if found == 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// NormalizeSearchQuery returns the query text the statistics are aggregated on, the text is lower-cased and the
+// whitespaces are collapsed.
+func NormalizeSearchQuery(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
+
+// SearchAnalyticsSubspace is the storage of the aggregated statistics of the search queries.
+type SearchAnalyticsSubspace struct {
+	metadataSubspace
+}
+
+func NewSearchAnalyticsStore(nameRegistry *NameRegistry) *SearchAnalyticsSubspace {
+	return &SearchAnalyticsSubspace{
+		metadataSubspace{
+			SubspaceName: nameRegistry.SearchAnalyticsSubspaceName(),
+			KeyVersion:   []byte{byte(searchAnalyticsValueVersion)},
+		},
+	}
+}
+
+func (s *SearchAnalyticsSubspace) getKey(src SearchAnalyticsSource, bucket int64, parts ...any) keys.Key {
+	kind, name := src.kindAndName()
+
+	return keys.NewKey(s.SubspaceName, append([]any{s.KeyVersion, UInt32ToByte(src.NamespaceId),
+		UInt32ToByte(src.ProjectId), src.Branch, kind, name, bucket}, parts...)...)
+}
+
+// Merge adds the statistics to the ones stored for the query in the hour bucket.
+func (s *SearchAnalyticsSubspace) Merge(ctx context.Context, tx transaction.Tx, src SearchAnalyticsSource,
+	bucket time.Time, stats *SearchQueryStats,
+) error {
+	key := s.getKey(src, bucket.Truncate(searchAnalyticsBucket).Unix(), stats.Query)
+
+	var existing SearchQueryStats
+	err := s.getMetadata(ctx, tx, nil, key, &existing)
+	switch {
+	case err == errors.ErrNotFound:
+		existing = SearchQueryStats{Query: stats.Query}
+	case err != nil:
+		return err
+	}
+
+	existing.merge(stats)
+
+	return s.updateMetadata(ctx, tx, nil, key, searchAnalyticsValueVersion, &existing)
+}
+
+// Read returns the statistics of the queries run in the [from, to] window, aggregated per query.
+func (s *SearchAnalyticsSubspace) Read(ctx context.Context, tx transaction.Tx, src SearchAnalyticsSource,
+	from time.Time, to time.Time,
+) (map[string]*SearchQueryStats, error) {
+	result := make(map[string]*SearchQueryStats)
+
+	err := s.scan(ctx, tx, src, from.Truncate(searchAnalyticsBucket).Unix(),
+		to.Truncate(searchAnalyticsBucket).Add(searchAnalyticsBucket).Unix(),
+		func(_ kv.Key, data *internal.TableData) error {
+			var stats SearchQueryStats
+			if err := jsoniter.Unmarshal(data.RawData, &stats); ulog.E(err) {
+				return errors.Internal("failed to unmarshal search analytics")
+			}
+
+			if existing, ok := result[stats.Query]; ok {
+				existing.merge(&stats)
+			} else {
+				result[stats.Query] = &stats
+			}
+
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteBefore removes the statistics of the hour buckets preceding the one of the time.
+func (s *SearchAnalyticsSubspace) DeleteBefore(ctx context.Context, tx transaction.Tx, src SearchAnalyticsSource,
+	before time.Time,
+) error {
+	return tx.DeleteRange(ctx, s.getKey(src, 0), s.getKey(src, before.Truncate(searchAnalyticsBucket).Unix()))
+}
+
+// DeleteProject removes the statistics of the indexes and the collections of the project.
+func (s *SearchAnalyticsSubspace) DeleteProject(ctx context.Context, tx transaction.Tx, namespaceId uint32,
+	projectId uint32,
+) error {
+	return tx.Delete(ctx, keys.NewKey(s.SubspaceName, s.KeyVersion, UInt32ToByte(namespaceId), UInt32ToByte(projectId)))
+}
+
+func (s *SearchAnalyticsSubspace) scan(ctx context.Context, tx transaction.Tx, src SearchAnalyticsSource, from int64,
+	to int64, fn func(kv.Key, *internal.TableData) error,
+) error {
+	iter, err := tx.ReadRange(ctx, s.getKey(src, from), s.getKey(src, to), false, false)
+	if err != nil {
+		return err
+	}
+
+	// Do not count for metadata operations
+	metrics.SetMetadataOperationInContext(ctx)
+
+	var v kv.KeyValue
+	for iter.Next(&v) {
+		if err = fn(v.Key, v.Data); err != nil {
+			return err
+		}
+	}
+
+	return iter.Err()
+}
+
+type searchAnalyticsEntry struct {
+	source SearchAnalyticsSource
+	bucket int64
+	query  string
+}
+
+// SearchAnalytics buffers the statistics of the search queries in memory and periodically merges them into the
+// search analytics subspace. The buffer is bounded, the queries are dropped once it is full until the next flush.
+type SearchAnalytics struct {
+	sync.Mutex
+
+	store   *SearchAnalyticsSubspace
+	txMgr   *transaction.Manager
+	cfg     *config.SearchAnalyticsConfig
+	pending map[searchAnalyticsEntry]*SearchQueryStats
+	popular map[SearchAnalyticsSource]*popularQueries
+}
+
+type popularQueries struct {
+	loadedAt time.Time
+	counts   map[string]int64
+}
+
+func NewSearchAnalytics(store *SearchAnalyticsSubspace, txMgr *transaction.Manager, cfg *config.SearchAnalyticsConfig) *SearchAnalytics {
+	return &SearchAnalytics{
+		store:   store,
+		txMgr:   txMgr,
+		cfg:     cfg,
+		pending: make(map[searchAnalyticsEntry]*SearchQueryStats),
+		popular: make(map[SearchAnalyticsSource]*popularQueries),
+	}
+}
+
+// Start periodically flushes the buffered statistics.
+func (a *SearchAnalytics) Start() {
+	if a.cfg.Enabled {
+		go a.flushLoop()
+	}
+}
+
+func (a *SearchAnalytics) flushLoop() {
+	log.Info().Dur("flush_interval", a.cfg.FlushInterval).Msg("Starting search analytics")
+	t := time.NewTicker(a.cfg.FlushInterval)
+	defer t.Stop()
+	for range t.C {
+		ulog.E(a.Flush(context.Background()))
+	}
+}
+
+// Record adds the search query to the buffered statistics.
+func (a *SearchAnalytics) Record(src SearchAnalyticsSource, q *SearchQuery) {
+	if !a.cfg.Enabled {
+		return
+	}
+
+	now := time.Now().UTC()
+	entry := searchAnalyticsEntry{
+		source: src,
+		bucket: now.Truncate(searchAnalyticsBucket).Unix(),
+		query:  NormalizeSearchQuery(q.Query),
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	stats, ok := a.pending[entry]
+	if !ok {
+		if len(a.pending) >= a.cfg.MaxPending {
+			log.Debug().Str("project", src.Project).Msg("search analytics buffer is full, dropping query")
+			return
+		}
+		stats = &SearchQueryStats{Query: entry.query}
+		a.pending[entry] = stats
+	}
+
+	stats.add(q, now)
+}
+
+// Flush merges the buffered statistics into the search analytics subspace, one transaction per index or collection.
+func (a *SearchAnalytics) Flush(ctx context.Context) error {
+	a.Lock()
+	pending := a.pending
+	a.pending = make(map[searchAnalyticsEntry]*SearchQueryStats)
+	for src, p := range a.popular {
+		if time.Since(p.loadedAt) >= a.cfg.FlushInterval {
+			delete(a.popular, src)
+		}
+	}
+	a.Unlock()
+
+	bySource := make(map[SearchAnalyticsSource][]searchAnalyticsEntry)
+	for entry := range pending {
+		bySource[entry.source] = append(bySource[entry.source], entry)
+	}
+
+	var lastErr error
+	for src, entries := range bySource {
+		if err := a.flushSource(ctx, src, entries, pending); err != nil {
+			log.Err(err).Str("project", src.Project).Str("index", src.Index).Str("collection", src.Collection).
+				Msg("failed to flush search analytics")
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (a *SearchAnalytics) flushSource(ctx context.Context, src SearchAnalyticsSource, entries []searchAnalyticsEntry,
+	pending map[searchAnalyticsEntry]*SearchQueryStats,
+) (err error) {
+	tx, err := a.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err == nil {
+			err = tx.Commit(ctx)
+		} else {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	for _, entry := range entries {
+		if err = a.store.Merge(ctx, tx, src, time.Unix(entry.bucket, 0), pending[entry]); err != nil {
+			return err
+		}
+	}
+
+	return a.store.DeleteBefore(ctx, tx, src, time.Now().Add(-a.cfg.Retention))
+}
+
+// Read returns the statistics of the queries run in the [from, to] window including the ones not flushed yet.
+func (a *SearchAnalytics) Read(ctx context.Context, src SearchAnalyticsSource, from time.Time, to time.Time) ([]*SearchQueryStats, error) {
+	tx, err := a.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	stored, err := a.store.Read(ctx, tx, src, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromBucket, toBucket := from.Truncate(searchAnalyticsBucket).Unix(), to.Truncate(searchAnalyticsBucket).Unix()
+
+	a.Lock()
+	for entry, stats := range a.pending {
+		if entry.source != src || entry.bucket < fromBucket || entry.bucket > toBucket {
+			continue
+		}
+
+		if existing, ok := stored[entry.query]; ok {
+			existing.merge(stats)
+		} else {
+			copied := *stats
+			copied.Filters = append([]string(nil), stats.Filters...)
+			stored[entry.query] = &copied
+		}
+	}
+	a.Unlock()
+
+	result := make([]*SearchQueryStats, 0, len(stored))
+	for _, stats := range stored {
+		result = append(result, stats)
+	}
+
+	return result, nil
+}
+
+// PopularQueries returns the number of runs of the queries of the last week. The counts are cached for a flush
+// interval as they are read on the suggest path.
+func (a *SearchAnalytics) PopularQueries(ctx context.Context, src SearchAnalyticsSource) (map[string]int64, error) {
+	a.Lock()
+	cached, ok := a.popular[src]
+	a.Unlock()
+	if ok && time.Since(cached.loadedAt) < a.cfg.FlushInterval {
+		return cached.counts, nil
+	}
+
+	now := time.Now()
+	stats, err := a.Read(ctx, src, now.Add(-popularQueriesWindow), now)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(stats))
+	for _, s := range stats {
+		counts[s.Query] = s.Count
+	}
+
+	a.Lock()
+	a.popular[src] = &popularQueries{loadedAt: now, counts: counts}
+	a.Unlock()
+
+	return counts, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	require.Equal(t, "red shoes", NormalizeSearchQuery("  Red\t SHOES "))
+	require.Equal(t, "", NormalizeSearchQuery(" "))
+}
+
+func TestSearchAnalytics(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := NewSearchAnalyticsStore(newTestNameRegistry(t))
+	_ = kvStore.DropTable(ctx, store.SubspaceName)
+
+	analytics := NewSearchAnalytics(store, transaction.NewManager(kvStore), &config.SearchAnalyticsConfig{
+		Enabled:    true,
+		Retention:  time.Hour,
+		MaxPending: 2,
+	})
+
+	products := SearchAnalyticsSource{NamespaceId: 1, ProjectId: 2, Project: "p1", Index: "products"}
+	orders := SearchAnalyticsSource{NamespaceId: 1, ProjectId: 2, Project: "p1", Branch: "b1", Collection: "orders"}
+
+	analytics.Record(products, &SearchQuery{Query: "Red Shoes", Filters: []string{"brand"}, Found: 3, Latency: 10 * time.Millisecond, Page: 1})
+	analytics.Record(products, &SearchQuery{Query: "red  shoes", Filters: []string{"price"}, Found: 0, Latency: 30 * time.Millisecond, Page: 2})
+	analytics.Record(orders, &SearchQuery{Query: "late", Found: 0, Latency: time.Millisecond, Page: 1})
+	// the buffer is full
+	analytics.Record(products, &SearchQuery{Query: "boots", Found: 1, Latency: time.Millisecond, Page: 1})
+
+	from, to := time.Now().Add(-time.Hour), time.Now()
+
+	// not flushed yet
+	stats, err := analytics.Read(ctx, products, from, to)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+
+	require.NoError(t, analytics.Flush(ctx))
+	analytics.Record(products, &SearchQuery{Query: "RED SHOES", Found: 1, Latency: 20 * time.Millisecond, Page: 1})
+
+	stats, err = analytics.Read(ctx, products, from, to)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, "red shoes", stats[0].Query)
+	require.Equal(t, int64(3), stats[0].Count)
+	require.Equal(t, int64(1), stats[0].ZeroResults)
+	require.Equal(t, 20*time.Millisecond, stats[0].AvgLatency())
+	require.Equal(t, 30*time.Millisecond, stats[0].MaxLatency)
+	require.Equal(t, int32(2), stats[0].MaxPage)
+	require.Equal(t, []string{"brand", "price"}, stats[0].Filters)
+
+	stats, err = analytics.Read(ctx, orders, from, to)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	require.Equal(t, "late", stats[0].Query)
+
+	// outside the window
+	stats, err = analytics.Read(ctx, orders, from.Add(-48*time.Hour), to.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, stats)
+
+	// retention
+	tx, err := transaction.NewManager(kvStore).StartTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteBefore(ctx, tx, orders, time.Now().Add(2*time.Hour)))
+	require.NoError(t, tx.Commit(ctx))
+
+	stats, err = analytics.Read(ctx, orders, from, to)
+	require.NoError(t, err)
+	require.Empty(t, stats)
+
+	// project drop
+	tx, err = transaction.NewManager(kvStore).StartTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteProject(ctx, tx, products.NamespaceId, products.ProjectId))
+	require.NoError(t, tx.Commit(ctx))
+
+	stats, err = analytics.Read(ctx, products, from, to)
+	require.NoError(t, err)
+	require.Empty(t, stats)
+}
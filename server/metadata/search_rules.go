@@ -0,0 +1,207 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/search"
+)
+
+// CreateOrUpdateSearchSynonym stores the synonym in the metadata of the search index and pushes it to the search store.
+func (tenant *Tenant) CreateOrUpdateSearchSynonym(ctx context.Context, tx transaction.Tx, project *Project, indexName string, synonym *search.Synonym) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateSearchMetadata(ctx, tx, project, indexName, func(index string, md *SearchMetadata) error {
+		replaced := false
+		for i := range md.Synonyms {
+			if md.Synonyms[i].Id == synonym.Id {
+				md.Synonyms[i] = *synonym
+				replaced = true
+			}
+		}
+		if !replaced {
+			md.Synonyms = append(md.Synonyms, *synonym)
+		}
+
+		return tenant.searchStore.UpsertSynonym(ctx, index, synonym)
+	})
+}
+
+func (tenant *Tenant) ListSearchSynonyms(ctx context.Context, tx transaction.Tx, project *Project, indexName string) ([]search.Synonym, error) {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	_, md, err := tenant.getSearchMetadata(ctx, tx, project, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	return md.Synonyms, nil
+}
+
+func (tenant *Tenant) DeleteSearchSynonym(ctx context.Context, tx transaction.Tx, project *Project, indexName string, id string) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateSearchMetadata(ctx, tx, project, indexName, func(index string, md *SearchMetadata) error {
+		found := -1
+		for i := range md.Synonyms {
+			if md.Synonyms[i].Id == id {
+				found = i
+				break
+			}
+		}
+		if found == -1 {
+			return errors.NotFound("synonym '%s' not found", id)
+		}
+		md.Synonyms = append(md.Synonyms[:found], md.Synonyms[found+1:]...)
+
+		if err := tenant.searchStore.DeleteSynonym(ctx, index, id); err != nil && !search.IsErrNotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// CreateOrUpdateSearchCuration stores the curation rule in the metadata of the search index and pushes it to the
+// search store.
+func (tenant *Tenant) CreateOrUpdateSearchCuration(ctx context.Context, tx transaction.Tx, project *Project, indexName string, curation *search.Curation) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateSearchMetadata(ctx, tx, project, indexName, func(index string, md *SearchMetadata) error {
+		replaced := false
+		for i := range md.Curations {
+			if md.Curations[i].Id == curation.Id {
+				md.Curations[i] = *curation
+				replaced = true
+			}
+		}
+		if !replaced {
+			md.Curations = append(md.Curations, *curation)
+		}
+
+		return tenant.searchStore.UpsertCuration(ctx, index, curation)
+	})
+}
+
+func (tenant *Tenant) ListSearchCurations(ctx context.Context, tx transaction.Tx, project *Project, indexName string) ([]search.Curation, error) {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	_, md, err := tenant.getSearchMetadata(ctx, tx, project, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	return md.Curations, nil
+}
+
+func (tenant *Tenant) DeleteSearchCuration(ctx context.Context, tx transaction.Tx, project *Project, indexName string, id string) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	return tenant.updateSearchMetadata(ctx, tx, project, indexName, func(index string, md *SearchMetadata) error {
+		found := -1
+		for i := range md.Curations {
+			if md.Curations[i].Id == id {
+				found = i
+				break
+			}
+		}
+		if found == -1 {
+			return errors.NotFound("curation '%s' not found", id)
+		}
+		md.Curations = append(md.Curations[:found], md.Curations[found+1:]...)
+
+		if err := tenant.searchStore.DeleteCuration(ctx, index, id); err != nil && !search.IsErrNotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// recreateSearchIndex creates the index in the search store if it is missing there, for example after a restart when the
+// embedded store keeps the indexes only in memory, and pushes all the synonyms and the curation rules of the index to it.
+func (tenant *Tenant) recreateSearchIndex(ctx context.Context, index *schema.SearchIndex, md *SearchMetadata) error {
+	if err := tenant.searchStore.CreateCollection(ctx, index.StoreSchema); err != nil {
+		if search.IsErrDuplicateEntity(err) {
+			return nil
+		}
+		return err
+	}
+
+	return tenant.pushSearchRules(ctx, index.StoreIndexName(), md)
+}
+
+func (tenant *Tenant) pushSearchRules(ctx context.Context, storeIndexName string, md *SearchMetadata) error {
+	for i := range md.Synonyms {
+		if err := tenant.searchStore.UpsertSynonym(ctx, storeIndexName, &md.Synonyms[i]); err != nil {
+			return err
+		}
+	}
+	for i := range md.Curations {
+		if err := tenant.searchStore.UpsertCuration(ctx, storeIndexName, &md.Curations[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getSearchMetadata returns the project metadata and the metadata of the search index inside it.
+func (tenant *Tenant) getSearchMetadata(ctx context.Context, tx transaction.Tx, project *Project, indexName string) (*ProjectMetadata, *SearchMetadata, error) {
+	if _, ok := project.search.GetIndex(indexName); !ok {
+		return nil, nil, NewSearchIndexNotFoundErr(indexName)
+	}
+
+	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name())
+	if err != nil {
+		return nil, nil, errors.Internal("failed to get project metadata for project %s", project.Name())
+	}
+
+	for i := range projMetadata.SearchMetadata {
+		if projMetadata.SearchMetadata[i].Name == indexName {
+			return projMetadata, &projMetadata.SearchMetadata[i], nil
+		}
+	}
+
+	return nil, nil, NewSearchIndexNotFoundErr(indexName)
+}
+
+// updateSearchMetadata applies the change on the metadata of the search index and stores it, the change is passed the
+// name of the index in the search store.
+func (tenant *Tenant) updateSearchMetadata(ctx context.Context, tx transaction.Tx, project *Project, indexName string, change func(string, *SearchMetadata) error) error {
+	projMetadata, md, err := tenant.getSearchMetadata(ctx, tx, project, indexName)
+	if err != nil {
+		return err
+	}
+
+	index, _ := project.search.GetIndex(indexName)
+	if err = change(index.StoreIndexName(), md); err != nil {
+		return err
+	}
+
+	if err = tenant.namespaceStore.UpdateProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name(), projMetadata); err != nil {
+		return errors.Internal("failed to update project metadata for search index %s", indexName)
+	}
+
+	return nil
+}
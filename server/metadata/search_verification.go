@@ -0,0 +1,205 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+// The verification of the search index of a collection against its primary rows is scheduled per collection. The
+// FDB structure is:
+// ["search_verification", version, namespace id, database id, collection id] = verification
+
+const (
+	searchVerificationValueVersion int32 = 1
+	// maxDriftedIds caps the number of drifted document ids kept in a report.
+	maxDriftedIds = 100
+)
+
+// SearchVerification is the verification schedule of the search index of a collection and the report of its last
+// run.
+type SearchVerification struct {
+	Repair bool `json:"repair"`
+	// Interval is the time between two runs, the verification runs once if it is not set.
+	Interval time.Duration `json:"interval,omitempty"`
+	// TaskId is the id of the queue item of the scheduled run. A run with a different id belongs to a replaced schedule
+	// and is not rescheduled.
+	TaskId string             `json:"task_id,omitempty"`
+	Report *SearchDriftReport `json:"report,omitempty"`
+}
+
+// SearchDriftReport is the result of a verification run. A document is missing if a primary row has no search
+// document, stale if the search document has not been indexed from the current version of the row and orphaned if
+// the search document has no primary row.
+type SearchDriftReport struct {
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Scanned     int64     `json:"scanned"`
+	Missing     int64     `json:"missing"`
+	Stale       int64     `json:"stale"`
+	Orphaned    int64     `json:"orphaned"`
+	Repaired    int64     `json:"repaired"`
+	// DriftedIds are the search ids of the first drifted documents.
+	DriftedIds []string `json:"drifted_ids,omitempty"`
+}
+
+// AddDrifted counts a drifted document.
+func (r *SearchDriftReport) AddDrifted(id string, missing bool) {
+	if missing {
+		r.Missing++
+	} else {
+		r.Stale++
+	}
+
+	if len(r.DriftedIds) < maxDriftedIds {
+		r.DriftedIds = append(r.DriftedIds, id)
+	}
+}
+
+// AddOrphaned counts an orphaned document.
+func (r *SearchDriftReport) AddOrphaned(id string) {
+	r.Orphaned++
+
+	if len(r.DriftedIds) < maxDriftedIds {
+		r.DriftedIds = append(r.DriftedIds, id)
+	}
+}
+
+type SearchVerificationSubspace struct {
+	metadataSubspace
+}
+
+func NewSearchVerificationStore(nameRegistry *NameRegistry) *SearchVerificationSubspace {
+	return &SearchVerificationSubspace{
+		metadataSubspace{
+			SubspaceName: nameRegistry.SearchVerificationSubspaceName(),
+			KeyVersion:   []byte{byte(searchVerificationValueVersion)},
+		},
+	}
+}
+
+func (s *SearchVerificationSubspace) getKey(nsId uint32, dbId uint32, collId uint32) keys.Key {
+	return keys.NewKey(s.SubspaceName, s.KeyVersion, UInt32ToByte(nsId), UInt32ToByte(dbId), UInt32ToByte(collId))
+}
+
+// Get returns the verification of the collection, nil if it has never been scheduled.
+func (s *SearchVerificationSubspace) Get(ctx context.Context, tx transaction.Tx, nsId uint32, dbId uint32,
+	collId uint32,
+) (*SearchVerification, error) {
+	var verification SearchVerification
+	if err := s.getMetadata(ctx, tx, nil, s.getKey(nsId, dbId, collId), &verification); err != nil {
+		if err == errors.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+func (s *SearchVerificationSubspace) Put(ctx context.Context, tx transaction.Tx, nsId uint32, dbId uint32,
+	collId uint32, verification *SearchVerification,
+) error {
+	return s.updateMetadata(ctx, tx, nil, s.getKey(nsId, dbId, collId), searchVerificationValueVersion, verification)
+}
+
+func (s *SearchVerificationSubspace) Delete(ctx context.Context, tx transaction.Tx, nsId uint32, dbId uint32,
+	collId uint32,
+) error {
+	return s.deleteMetadata(ctx, tx, nil, s.getKey(nsId, dbId, collId))
+}
+
+// ScheduleSearchVerification replaces the verification schedule of the search index of the collection and queues its
+// first run. The run already queued for a replaced schedule still runs but is not rescheduled.
+func (tenant *Tenant) ScheduleSearchVerification(ctx context.Context, tx transaction.Tx, db *Database,
+	coll *schema.DefaultCollection, repair bool, interval time.Duration,
+) error {
+	store := tenant.MetaStore.SearchVerification()
+
+	verification, err := store.Get(ctx, tx, tenant.namespace.Id(), db.Id(), coll.Id)
+	if err != nil {
+		return err
+	}
+	if verification == nil {
+		verification = &SearchVerification{}
+	}
+
+	queueData, err := jsoniter.Marshal(SearchVerifyTask{
+		NamespaceId: tenant.namespace.StrId(),
+		ProjName:    db.DbName(),
+		Branch:      db.BranchName(),
+		CollName:    coll.Name,
+		Repair:      repair,
+	})
+	if err != nil {
+		return err
+	}
+
+	item := NewQueueItem(0, queueData, SEARCH_VERIFY_TASK)
+	if err = tenant.MetaStore.Queue().Enqueue(ctx, tx, item, 0); err != nil {
+		return err
+	}
+
+	verification.Repair = repair
+	verification.Interval = interval
+	verification.TaskId = item.Id
+
+	return store.Put(ctx, tx, tenant.namespace.Id(), db.Id(), coll.Id, verification)
+}
+
+// GetSearchVerification returns the verification schedule of the search index of the collection and the report of its
+// last run, nil if it has never been scheduled.
+func (tenant *Tenant) GetSearchVerification(ctx context.Context, tx transaction.Tx, db *Database,
+	coll *schema.DefaultCollection,
+) (*SearchVerification, error) {
+	return tenant.MetaStore.SearchVerification().Get(ctx, tx, tenant.namespace.Id(), db.Id(), coll.Id)
+}
+
+// CompleteSearchVerification stores the report of a run and, if the run belongs to the current schedule, queues the
+// next run after the interval of the schedule.
+func (tenant *Tenant) CompleteSearchVerification(ctx context.Context, tx transaction.Tx, db *Database,
+	coll *schema.DefaultCollection, item *QueueItem, report *SearchDriftReport,
+) error {
+	store := tenant.MetaStore.SearchVerification()
+
+	verification, err := store.Get(ctx, tx, tenant.namespace.Id(), db.Id(), coll.Id)
+	if err != nil {
+		return err
+	}
+	if verification == nil {
+		verification = &SearchVerification{}
+	}
+
+	verification.Report = report
+	if verification.TaskId == item.Id {
+		verification.TaskId = ""
+		if verification.Interval > 0 {
+			next := NewQueueItem(0, item.Data, SEARCH_VERIFY_TASK)
+			if err = tenant.MetaStore.Queue().Enqueue(ctx, tx, next, verification.Interval); err != nil {
+				return err
+			}
+			verification.TaskId = next.Id
+		}
+	}
+
+	return store.Put(ctx, tx, tenant.namespace.Id(), db.Id(), coll.Id, verification)
+}
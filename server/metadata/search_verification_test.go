@@ -0,0 +1,75 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+func TestSearchDriftReport(t *testing.T) {
+	var report SearchDriftReport
+	for i := 0; i < maxDriftedIds+10; i++ {
+		report.AddDrifted(fmt.Sprint(i), i%2 == 0)
+	}
+
+	require.Equal(t, int64(55), report.Missing)
+	require.Equal(t, int64(55), report.Stale)
+	require.Len(t, report.DriftedIds, maxDriftedIds)
+	require.Equal(t, "0", report.DriftedIds[0])
+}
+
+func TestSearchVerificationSubspace(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := NewSearchVerificationStore(newTestNameRegistry(t))
+	_ = kvStore.DropTable(ctx, store.SubspaceName)
+
+	tm := transaction.NewManager(kvStore)
+	tx, err := tm.StartTx(ctx)
+	require.NoError(t, err)
+
+	verification, err := store.Get(ctx, tx, 1, 2, 3)
+	require.NoError(t, err)
+	require.Nil(t, verification)
+
+	report := &SearchDriftReport{Scanned: 10, Missing: 1, DriftedIds: []string{"5"}}
+	require.NoError(t, store.Put(ctx, tx, 1, 2, 3, &SearchVerification{
+		Repair:   true,
+		Interval: time.Hour,
+		TaskId:   "task",
+		Report:   report,
+	}))
+
+	verification, err = store.Get(ctx, tx, 1, 2, 3)
+	require.NoError(t, err)
+	require.True(t, verification.Repair)
+	require.Equal(t, time.Hour, verification.Interval)
+	require.Equal(t, "task", verification.TaskId)
+	require.Equal(t, report.DriftedIds, verification.Report.DriftedIds)
+
+	require.NoError(t, store.Delete(ctx, tx, 1, 2, 3))
+	verification, err = store.Get(ctx, tx, 1, 2, 3)
+	require.NoError(t, err)
+	require.Nil(t, verification)
+
+	require.NoError(t, tx.Rollback(ctx))
+}
@@ -48,6 +48,11 @@ type NameRegistry struct {
 	ClusterSB   string
 	VersionKey  string
 	QueueSB     string
+	// SearchAnalyticsSB is the name of the table(subspace) where the statistics of the search queries are aggregated.
+	SearchAnalyticsSB string
+	// SearchVerificationSB is the name of the table(subspace) where the search verification of the collections is
+	// scheduled and reported.
+	SearchVerificationSB string
 
 	BaseCounterValue uint32
 }
@@ -62,6 +67,9 @@ var DefaultNameRegistry = &NameRegistry{
 	ClusterSB:   "cluster",
 	QueueSB:     "queue",
 
+	SearchAnalyticsSB:    "search_analytics",
+	SearchVerificationSB: "search_verification",
+
 	BaseCounterValue: reservedBaseValue,
 }
 
@@ -97,6 +105,14 @@ func (d *NameRegistry) QueueSubspaceName() []byte {
 	return []byte(d.QueueSB)
 }
 
+func (d *NameRegistry) SearchAnalyticsSubspaceName() []byte {
+	return []byte(d.SearchAnalyticsSB)
+}
+
+func (d *NameRegistry) SearchVerificationSubspaceName() []byte {
+	return []byte(d.SearchVerificationSB)
+}
+
 func (d *NameRegistry) GetVersionKey() []byte {
 	return []byte(d.VersionKey)
 }
@@ -116,6 +132,9 @@ func newTestNameRegistry(t *testing.T) *NameRegistry {
 		QueueSB:     "test_queue_" + s,
 		VersionKey:  "test_version_key" + s,
 
+		SearchAnalyticsSB:    "test_search_analytics_" + s,
+		SearchVerificationSB: "test_search_verification_" + s,
+
 		BaseCounterValue: r.Uint32(),
 	}
 }
@@ -30,16 +30,19 @@ import (
 	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/cache"
 	"github.com/tigrisdata/tigris/store/kv"
 	"github.com/tigrisdata/tigris/store/search"
 	ulog "github.com/tigrisdata/tigris/util/log"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
 )
 
 type NamespaceType string
 
 const (
 	baseSchemaVersion = uint32(1)
+	// replacedSearchIndexDropDelay is how long a search index replaced by a rebuilt one is kept. The servers reload the
+	// collection on their next request after the promotion, the writes in flight to the replaced index end well before.
+	replacedSearchIndexDropDelay = 10 * time.Minute
 )
 
 // TenantGetter
@@ -115,13 +118,18 @@ type TenantManager struct {
 	// type mismatch then "pack" those values during indexing. For all new collections reloaded there
 	// shouldn't be any mismatch. Also, this logic only triggers during reloading of tenants or
 	// restart where we don't know when the collection was created.
-	searchSchemasSnapshot map[string]*tsApi.CollectionResponse
+	searchSchemasSnapshot map[string]*schema.StoreSchema
+	searchAnalytics       *SearchAnalytics
 }
 
 func (m *TenantManager) GetNamespaceStore() *NamespaceSubspace {
 	return m.metaStore.Namespace()
 }
 
+func (m *TenantManager) GetSearchAnalytics() *SearchAnalytics {
+	return m.searchAnalytics
+}
+
 func (m *TenantManager) GetVersionHandler() *VersionHandler {
 	return m.versionH
 }
@@ -134,14 +142,16 @@ func newTenantManager(kvStore kv.TxStore, searchStore search.Store, mdNameRegist
 	collectionsInSearch, err := searchStore.AllCollections(context.TODO())
 	if err != nil {
 		log.Err(err).Msgf("error starting server: loading schemas from search failed")
-		collectionsInSearch = make(map[string]*tsApi.CollectionResponse)
+		collectionsInSearch = make(map[string]*schema.StoreSchema)
 	}
 
+	metaStore := NewMetadataDictionary(mdNameRegistry)
+
 	return &TenantManager{
 		kvStore:               kvStore,
 		searchStore:           searchStore,
 		encoder:               NewEncoder(),
-		metaStore:             NewMetadataDictionary(mdNameRegistry),
+		metaStore:             metaStore,
 		tenants:               make(map[string]*Tenant),
 		idToTenantMap:         make(map[uint32]string),
 		versionH:              newVersionHandler(mdNameRegistry.GetVersionKey()),
@@ -149,6 +159,7 @@ func newTenantManager(kvStore kv.TxStore, searchStore search.Store, mdNameRegist
 		tableKeyGenerator:     NewTableKeyGenerator(),
 		txMgr:                 txMgr,
 		searchSchemasSnapshot: collectionsInSearch,
+		searchAnalytics:       NewSearchAnalytics(metaStore.SearchAnalytics(), txMgr, &config.DefaultConfig.Search.Analytics),
 	}
 }
 
@@ -652,7 +663,7 @@ func NewTenant(namespace Namespace, kvStore kv.TxStore, searchStore search.Store
 // thread will actually perform reload. This is a blocking API which means if most of the requests detected that the
 // tenant state is stale then they all will block till one of them will reload the tenant state from the database. All
 // the blocking transactions will be restarted to ensure they see the latest view of the tenant.
-func (tenant *Tenant) Reload(ctx context.Context, tx transaction.Tx, version Version, searchSchemasSnapshot map[string]*tsApi.CollectionResponse) error {
+func (tenant *Tenant) Reload(ctx context.Context, tx transaction.Tx, version Version, searchSchemasSnapshot map[string]*schema.StoreSchema) error {
 	if !tenant.shouldReload(version) {
 		return nil
 	}
@@ -680,7 +691,15 @@ func (tenant *Tenant) shouldReload(currentVersion Version) bool {
 // loads all the databases, it loads the resources for each one. Once databases are reloaded then it performs the same
 // logic for search indexes. Once search indexes are loaded it links back the search indexes to the Tigris Collection
 // if the source for these search indexes is Tigris.
-func (tenant *Tenant) reload(ctx context.Context, tx transaction.Tx, currentVersion Version, searchSchemasSnapshot map[string]*tsApi.CollectionResponse) error {
+func (tenant *Tenant) reload(ctx context.Context, tx transaction.Tx, currentVersion Version, searchSchemasSnapshot map[string]*schema.StoreSchema) error {
+	// keep the search indexes of the current view to know which of them have been seen by this server
+	previousSearch := make(map[string]*Search)
+	for name, p := range tenant.projects {
+		if p.search != nil {
+			previousSearch[name] = p.search
+		}
+	}
+
 	// reset
 	tenant.projects = make(map[string]*Project)
 	tenant.idToDatabaseMap = make(map[uint32]*Database)
@@ -720,21 +739,22 @@ func (tenant *Tenant) reload(ctx context.Context, tx transaction.Tx, currentVers
 	// the project object.
 	for _, p := range tenant.projects {
 		var err error
-		if p.search, err = tenant.reloadSearch(ctx, tx, p, searchSchemasSnapshot); err != nil {
+		if p.search, err = tenant.reloadSearch(ctx, tx, p, previousSearch[p.Name()], searchSchemasSnapshot); err != nil {
 			return err
 		}
 		for _, index := range p.search.indexes {
 			// we maintain a back pointer inside the collection object for the indexes that have the source as Tigris.
 			if index.Source.Type == schema.SearchSourceTigris {
-				database := p.database
-				if len(index.Source.DatabaseBranch) > 0 {
-					database = p.databaseBranches[index.Source.DatabaseBranch]
-				}
-
+				database, _ := p.GetDatabase(NewDatabaseNameWithBranch(p.Name(), index.Source.DatabaseBranch))
 				if database != nil {
 					if collection := database.GetCollection(index.Source.CollectionName); collection != nil {
 						collection.AddSearchIndex(index)
 					}
+					for _, l := range index.Source.Lookups {
+						if collection := database.GetCollection(l.CollectionName); collection != nil {
+							collection.AddLookupSearchIndex(index)
+						}
+					}
 				}
 			}
 		}
@@ -748,7 +768,7 @@ func (tenant *Tenant) reload(ctx context.Context, tx transaction.Tx, currentVers
 // reloadDatabase is called by tenant to reload the database state. This also loads all the collections that are part of
 // this database and implicit search index for these collections.
 func (tenant *Tenant) reloadDatabase(ctx context.Context, tx transaction.Tx, dbName string, dbId uint32,
-	searchSchemasSnapshot map[string]*tsApi.CollectionResponse,
+	searchSchemasSnapshot map[string]*schema.StoreSchema,
 ) (*Database, error) {
 	database := NewDatabase(dbId, dbName)
 
@@ -772,8 +792,8 @@ func (tenant *Tenant) reloadDatabase(ctx context.Context, tx transaction.Tx, dbN
 			continue
 		}
 
-		var fieldsInSearch []tsApi.Field
-		searchCollectionName := tenant.getSearchCollName(dbName, coll)
+		var fieldsInSearch []schema.StoreField
+		searchCollectionName := tenant.getSearchCollName(dbName, coll, meta.SearchVersion)
 		if searchSchema, ok := searchSchemasSnapshot[searchCollectionName]; ok {
 			fieldsInSearch = searchSchema.Fields
 		}
@@ -786,6 +806,10 @@ func (tenant *Tenant) reloadDatabase(ctx context.Context, tx transaction.Tx, dbN
 			log.Debug().Err(err).Str("collection", coll).Msg("skipping loading collection")
 			continue
 		}
+		if meta.SearchShadowVersion > 0 {
+			collection.ImplicitSearchIndex.Shadow = collection.ImplicitSearchIndex.NewShadow(
+				tenant.getSearchCollName(dbName, coll, meta.SearchShadowVersion))
+		}
 
 		encName, err := tenant.Encoder.EncodeTableName(tenant.namespace, database, collection)
 		if err != nil {
@@ -806,8 +830,10 @@ func (tenant *Tenant) reloadDatabase(ctx context.Context, tx transaction.Tx, dbN
 	return database, nil
 }
 
-// reloadSearch is responsible for reloading all the search indexes inside a single project.
-func (tenant *Tenant) reloadSearch(ctx context.Context, tx transaction.Tx, project *Project, searchSchemasSnapshot map[string]*tsApi.CollectionResponse) (*Search, error) {
+// reloadSearch is responsible for reloading all the search indexes inside a single project. The indexes that are not
+// in the search store snapshot and weren't in the previous view of the project are recreated in the search store if
+// they are missing there, along with their synonyms and curation rules.
+func (tenant *Tenant) reloadSearch(ctx context.Context, tx transaction.Tx, project *Project, previous *Search, searchSchemasSnapshot map[string]*schema.StoreSchema) (*Search, error) {
 	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project.Name())
 	if err != nil {
 		return nil, errors.Internal("failed to get project metadata for project %s", project.Name())
@@ -831,12 +857,20 @@ func (tenant *Tenant) reloadSearch(ctx context.Context, tx transaction.Tx, proje
 			continue
 		}
 
-		var fieldsInSearchStore []tsApi.Field
+		var fieldsInSearchStore []schema.StoreField
 		searchStoreIndexName := tenant.Encoder.EncodeSearchTableName(tenant.namespace.Id(), project.Id(), searchMD.Name)
-		if searchIndexInStore, ok := searchSchemasSnapshot[searchStoreIndexName]; ok {
+		searchIndexInStore, inStore := searchSchemasSnapshot[searchStoreIndexName]
+		if inStore {
 			fieldsInSearchStore = searchIndexInStore.Fields
 		}
-		searchObj.indexes[searchMD.Name] = schema.NewSearchIndex(schV.Version, searchStoreIndexName, searchFactory, fieldsInSearchStore)
+		index := schema.NewSearchIndex(schV.Version, searchStoreIndexName, searchFactory, fieldsInSearchStore)
+		if !inStore && !previous.hasIndex(searchMD.Name) {
+			md := searchMD
+			if err = tenant.recreateSearchIndex(ctx, index, &md); err != nil {
+				log.Err(err).Str("index", searchMD.Name).Msg("recreating search index failed")
+			}
+		}
+		searchObj.indexes[searchMD.Name] = index
 	}
 
 	return searchObj, nil
@@ -862,6 +896,12 @@ func (tenant *Tenant) CreateSearchIndex(ctx context.Context, tx transaction.Tx,
 }
 
 func (tenant *Tenant) createSearchIndex(ctx context.Context, tx transaction.Tx, project *Project, factory *schema.SearchFactory) error {
+	if factory.Source.Type == schema.SearchSourceTigris {
+		if err := validateSearchSource(project, factory.Source); err != nil {
+			return err
+		}
+	}
+
 	if index, ok := project.search.GetIndex(factory.Name); ok {
 		if tokensEq := reflect.DeepEqual(factory.Options.GetTokenSeparators(), index.TokenSeparators); !tokensEq {
 			return errors.InvalidArgument("`token_separators` cannot be modified, please create a new search index")
@@ -909,6 +949,31 @@ func (tenant *Tenant) createSearchIndex(ctx context.Context, tx transaction.Tx,
 	return nil
 }
 
+// validateSearchSource checks that the collections of an index tagged Tigris as source exist and that the related
+// collections can be looked up by a single primary key field.
+func validateSearchSource(project *Project, source schema.SearchSource) error {
+	database, err := project.GetDatabase(NewDatabaseNameWithBranch(project.Name(), source.DatabaseBranch))
+	if err != nil {
+		return err
+	}
+
+	if database.GetCollection(source.CollectionName) == nil {
+		return errors.NotFound("collection doesn't exist '%s'", source.CollectionName)
+	}
+
+	for _, l := range source.Lookups {
+		collection := database.GetCollection(l.CollectionName)
+		if collection == nil {
+			return errors.NotFound("collection doesn't exist '%s'", l.CollectionName)
+		}
+		if len(collection.GetPrimaryKey().Fields) != 1 {
+			return errors.InvalidArgument("lookup collection '%s' should have a single primary key field", l.CollectionName)
+		}
+	}
+
+	return nil
+}
+
 func (tenant *Tenant) updateSearchIndex(ctx context.Context, tx transaction.Tx, project *Project, factory *schema.SearchFactory, index *schema.SearchIndex) error {
 	// first apply schema change whether it conforms to the backward compatibility rules.
 	if err := schema.ApplySearchIndexBackwardCompatibilityRules(index, factory); err != nil {
@@ -931,9 +996,10 @@ func (tenant *Tenant) updateSearchIndex(ctx context.Context, tx transaction.Tx,
 
 	// update indexing store schema if there is a change
 	if deltaFields := updatedIndex.GetSearchDeltaFields(index.QueryableFields, previousIndexInStore.Fields); len(deltaFields) > 0 {
-		if err := tenant.searchStore.UpdateCollection(ctx, updatedIndex.StoreIndexName(), &tsApi.CollectionUpdateSchema{
-			Fields: deltaFields,
-		}); err != nil {
+		if err := updatedIndex.CheckInPlaceUpdate(index.QueryableFields, deltaFields); err != nil {
+			return err
+		}
+		if err := tenant.searchStore.UpdateCollection(ctx, updatedIndex.StoreIndexName(), deltaFields); err != nil {
 			return err
 		}
 	}
@@ -1030,7 +1096,7 @@ func (tenant *Tenant) ListSearchIndexes(_ context.Context, _ transaction.Tx, pro
 	return indexes, nil
 }
 
-func (tenant *Tenant) CreateCache(ctx context.Context, tx transaction.Tx, project string, cache string, currentSub string) (bool, error) {
+func (tenant *Tenant) CreateCache(ctx context.Context, tx transaction.Tx, project string, cache string, currentSub string, limits *cache.Limits) (bool, error) {
 	tenant.Lock()
 	defer tenant.Unlock()
 
@@ -1050,6 +1116,7 @@ func (tenant *Tenant) CreateCache(ctx context.Context, tx transaction.Tx, projec
 		Name:      cache,
 		Creator:   currentSub,
 		CreatedAt: time.Now().Unix(),
+		Limits:    limits,
 	})
 	err = tenant.namespaceStore.UpdateProjectMetadata(ctx, tx, tenant.namespace.Id(), project, projMetadata)
 	if err != nil {
@@ -1058,7 +1125,7 @@ func (tenant *Tenant) CreateCache(ctx context.Context, tx transaction.Tx, projec
 	return true, nil
 }
 
-func (tenant *Tenant) ListCaches(ctx context.Context, tx transaction.Tx, project string) ([]string, error) {
+func (tenant *Tenant) ListCaches(ctx context.Context, tx transaction.Tx, project string) ([]CacheMetadata, error) {
 	tenant.Lock()
 	defer tenant.Unlock()
 	projMetadata, err := tenant.namespaceStore.GetProjectMetadata(ctx, tx, tenant.namespace.Id(), project)
@@ -1066,13 +1133,9 @@ func (tenant *Tenant) ListCaches(ctx context.Context, tx transaction.Tx, project
 		return nil, errors.Internal("Failed to get project metadata for project %s", project)
 	}
 	if projMetadata.CachesMetadata == nil {
-		return []string{}, nil
+		return []CacheMetadata{}, nil
 	}
-	caches := make([]string, len(projMetadata.CachesMetadata))
-	for i := range projMetadata.CachesMetadata {
-		caches[i] = projMetadata.CachesMetadata[i].Name
-	}
-	return caches, nil
+	return projMetadata.CachesMetadata, nil
 }
 
 func (tenant *Tenant) DeleteCache(ctx context.Context, tx transaction.Tx, project string, cache string) (bool, error) {
@@ -1187,6 +1250,10 @@ func (tenant *Tenant) DeleteProject(ctx context.Context, tx transaction.Tx, proj
 		}
 	}
 
+	if err := tenant.MetaStore.SearchAnalytics().DeleteProject(ctx, tx, tenant.namespace.Id(), proj.Id()); err != nil {
+		return true, err
+	}
+
 	// drop metadata entry
 	if err := tenant.namespaceStore.DeleteProjectMetadata(ctx, tx, tenant.namespace.Id(), projName); err != nil {
 		log.Err(err).Msg("failed to delete project metadata")
@@ -1421,7 +1488,7 @@ func (tenant *Tenant) createCollection(ctx context.Context, tx transaction.Tx, d
 
 	implicitSearchIndex := schema.NewImplicitSearchIndex(
 		schFactory.Name,
-		tenant.getSearchCollName(database.Name(), schFactory.Name),
+		tenant.getSearchCollName(database.Name(), schFactory.Name, 0),
 		schFactory.Fields,
 		nil,
 	)
@@ -1538,7 +1605,7 @@ func (tenant *Tenant) updateCollection(ctx context.Context, tx transaction.Tx, d
 		return err
 	}
 
-	existingSearch := &tsApi.CollectionResponse{}
+	existingSearch := &schema.StoreSchema{}
 	if config.DefaultConfig.Search.WriteEnabled {
 		existingSearch, err = tenant.searchStore.DescribeCollection(ctx, existingCollection.ImplicitSearchIndex.StoreIndexName())
 		if err != nil {
@@ -1615,20 +1682,133 @@ func (tenant *Tenant) updateCollection(ctx context.Context, tx transaction.Tx, d
 	// recreating collection holder is fine because we are working on databaseClone and also has a lock on the tenant
 	database.collections[schFactory.Name] = newCollectionHolder(cHolder.id, schFactory.Name, collection, cHolder.primaryIdxMeta)
 
+	if changed := schema.ChangedEmbeddingFields(existingCollection, collection); len(changed) > 0 && config.DefaultConfig.Workers.Enabled {
+		// the vectors of the new or changed embeddings are computed for the existing documents in the background
+		queueData, err := jsoniter.Marshal(EmbeddingTask{
+			NamespaceId: tenant.namespace.StrId(),
+			ProjName:    database.DbName(),
+			Branch:      database.BranchName(),
+			CollName:    collection.Name,
+			Fields:      changed,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err = tenant.MetaStore.Queue().Enqueue(ctx, tx, NewQueueItem(0, queueData, EMBEDDING_TASK), 0); err != nil {
+			return err
+		}
+	}
+
 	if config.DefaultConfig.Search.WriteEnabled {
 		// update indexing store schema if there is a change
-		if deltaFields := collection.ImplicitSearchIndex.GetSearchDeltaFields(existingCollection.ImplicitSearchIndex.QueryableFields, schFactory.Fields); len(deltaFields) > 0 {
-			if err := tenant.searchStore.UpdateCollection(ctx, collection.ImplicitSearchIndex.StoreIndexName(), &tsApi.CollectionUpdateSchema{
-				Fields: deltaFields,
-			}); err != nil {
+		deltaFields := collection.ImplicitSearchIndex.GetSearchDeltaFields(existingCollection.ImplicitSearchIndex.QueryableFields, schFactory.Fields)
+		if len(deltaFields) > 0 {
+			if err := tenant.searchStore.UpdateCollection(ctx, collection.ImplicitSearchIndex.StoreIndexName(), deltaFields); err != nil {
 				return err
 			}
 		}
+
+		// the in place update leaves the existing documents inconsistent, so the index is rebuilt in the background
+		// and replaces the current one when it is complete. A rebuild in progress is restarted with the new schema.
+		rebuild := newSearchState == schema.SearchIndexActive && collection.ImplicitSearchIndex.RequiresRebuild(deltaFields)
+		if existingCollection.ImplicitSearchIndex.Shadow != nil || (rebuild && config.DefaultConfig.Workers.SearchEnabled) {
+			if err := tenant.rebuildSearchIndex(ctx, tx, database, collection, existingCollection.ImplicitSearchIndex.Shadow); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// rebuildSearchIndex creates a shadow of the collection's search index and enqueues the task to build it. The writes
+// go to both the indexes until the worker promotes the shadow, see PromoteSearchIndex.
+func (tenant *Tenant) rebuildSearchIndex(ctx context.Context, tx transaction.Tx, database *Database, collection *schema.DefaultCollection, prevShadow *schema.ImplicitSearchIndex) error {
+	meta, err := tenant.MetaStore.Collection().Get(ctx, tx, tenant.namespace.Id(), database.id, collection.Name)
+	if err != nil {
+		return err
+	}
+
+	if prevShadow != nil {
+		if err = tenant.searchStore.DropCollection(ctx, prevShadow.StoreIndexName()); err != nil && !search.IsErrNotFound(err) {
+			return err
+		}
+	}
+
+	shadowVersion := meta.SearchVersion + 1
+	if meta.SearchShadowVersion >= shadowVersion {
+		shadowVersion = meta.SearchShadowVersion + 1
+	}
+
+	shadow := collection.ImplicitSearchIndex.NewShadow(tenant.getSearchCollName(database.Name(), collection.Name, shadowVersion))
+	if err = tenant.searchStore.CreateCollection(ctx, shadow.StoreSchema); err != nil && !search.IsErrDuplicateEntity(err) {
+		return err
+	}
+
+	if _, err = tenant.MetaStore.Collection().UpdateSearchAlias(ctx, tx, tenant.namespace, database, collection.Name,
+		meta.SearchVersion, shadowVersion, meta.SearchState); err != nil {
+		return err
+	}
+
+	queueData, err := jsoniter.Marshal(IndexBuildTask{
+		NamespaceId: tenant.namespace.StrId(),
+		ProjName:    database.DbName(),
+		Branch:      database.BranchName(),
+		CollName:    collection.Name,
+	})
+	if err != nil {
+		return err
 	}
 
+	if err = tenant.MetaStore.Queue().Enqueue(ctx, tx, NewQueueItem(0, queueData, BUILD_SEARCH_INDEX_TASK), 0); err != nil {
+		return err
+	}
+
+	collection.ImplicitSearchIndex.Shadow = shadow
 	return nil
 }
 
+// PromoteSearchIndex flips the alias of the collection's search index to the shadow that has been built, and enqueues
+// the drop of the replaced index in the search store. The drop is delayed so that the servers have reloaded the
+// collection and finished the writes to the replaced index by then. Nothing is done if the shadow has been replaced by
+// a newer rebuild in the meantime.
+func (tenant *Tenant) PromoteSearchIndex(ctx context.Context, tx transaction.Tx, db *Database, coll *schema.DefaultCollection) error {
+	tenant.Lock()
+	defer tenant.Unlock()
+
+	meta, err := tenant.MetaStore.Collection().Get(ctx, tx, tenant.namespace.Id(), db.id, coll.Name)
+	if err != nil {
+		return err
+	}
+
+	shadow := coll.ImplicitSearchIndex.Shadow
+	if shadow == nil || meta.SearchShadowVersion == 0 ||
+		shadow.StoreIndexName() != tenant.getSearchCollName(db.Name(), coll.Name, meta.SearchShadowVersion) {
+		return nil
+	}
+
+	if _, err = tenant.MetaStore.Collection().UpdateSearchAlias(ctx, tx, tenant.namespace, db, coll.Name,
+		meta.SearchShadowVersion, 0, schema.SearchIndexActive); err != nil {
+		return err
+	}
+
+	// all the servers need to reload the collection to stop writing to the replaced index
+	if err = tenant.versionH.Increment(ctx, tx); err != nil {
+		return err
+	}
+
+	queueData, err := jsoniter.Marshal(DropSearchIndexTask{
+		NamespaceId: tenant.namespace.StrId(),
+		Index:       tenant.getSearchCollName(db.Name(), coll.Name, meta.SearchVersion),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tenant.MetaStore.Queue().Enqueue(ctx, tx, NewQueueItem(0, queueData, DROP_SEARCH_INDEX_TASK), replacedSearchIndexDropDelay)
+}
+
 func (tenant *Tenant) UpgradeSearchStatus(ctx context.Context, tx transaction.Tx, db *Database, coll *schema.DefaultCollection) error {
 	if coll.GetSearchState() != schema.UnknownSearchState {
 		return nil
@@ -1740,6 +1920,9 @@ func (tenant *Tenant) dropCollection(ctx context.Context, tx transaction.Tx, db
 	if err := tenant.schemaStore.Delete(ctx, tx, tenant.namespace.Id(), db.id, cHolder.id); err != nil {
 		return err
 	}
+	if err := tenant.MetaStore.SearchVerification().Delete(ctx, tx, tenant.namespace.Id(), db.id, cHolder.id); err != nil {
+		return err
+	}
 
 	tableName, err := tenant.Encoder.EncodeTableName(tenant.namespace, db, cHolder.collection)
 	if err != nil {
@@ -1767,13 +1950,25 @@ func (tenant *Tenant) dropCollection(ctx context.Context, tx transaction.Tx, db
 				return err
 			}
 		}
+
+		if shadow := cHolder.collection.ImplicitSearchIndex.Shadow; shadow != nil {
+			if err := tenant.searchStore.DropCollection(ctx, shadow.StoreIndexName()); err != nil && !search.IsErrNotFound(err) {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-func (tenant *Tenant) getSearchCollName(dbName string, collName string) string {
-	return fmt.Sprintf("%s-%s-%s", tenant.namespace.StrId(), dbName, collName)
+// getSearchCollName returns the name of the version of the collection's search index in the search store. The version
+// zero is the original unversioned name, collection names can't have a dot so the versioned names don't collide.
+func (tenant *Tenant) getSearchCollName(dbName string, collName string, version uint32) string {
+	if version == 0 {
+		return fmt.Sprintf("%s-%s-%s", tenant.namespace.StrId(), dbName, collName)
+	}
+
+	return fmt.Sprintf("%s-%s-%s.v%d", tenant.namespace.StrId(), dbName, collName, version)
 }
 
 func (tenant *Tenant) String() string {
@@ -2176,6 +2371,7 @@ func (c *collectionHolder) clone() *collectionHolder {
 	}
 
 	copyC.collection.SchemaDeltas = c.collection.SchemaDeltas
+	copyC.collection.ImplicitSearchIndex.Shadow = implicitIndex.Shadow
 	copyC.collection.EncodedName = c.collection.EncodedName
 	copyC.collection.EncodedTableIndexName = c.collection.EncodedTableIndexName
 
@@ -2209,7 +2405,7 @@ func createCollection(
 	schemas schema.Versions,
 	idxMeta map[string]*PrimaryIndexMetadata,
 	searchCollectionName string,
-	fieldsInSearch []tsApi.Field,
+	fieldsInSearch []schema.StoreField,
 	secondaryIndexes []*schema.Index,
 	searchState schema.SearchIndexState,
 ) (*schema.DefaultCollection, error) {
@@ -2268,6 +2464,16 @@ func (s *Search) GetIndex(name string) (*schema.SearchIndex, bool) {
 	return index, ok
 }
 
+// hasIndex returns true if the index is part of the search, it can be called on a nil search.
+func (s *Search) hasIndex(name string) bool {
+	if s == nil {
+		return false
+	}
+
+	_, ok := s.GetIndex(name)
+	return ok
+}
+
 func (s *Search) GetIndexes() []*schema.SearchIndex {
 	s.RLock()
 	defer s.RUnlock()
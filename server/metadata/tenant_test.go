@@ -15,6 +15,7 @@
 package metadata
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -591,7 +592,88 @@ func TestTenantManager_SearchIndexes(t *testing.T) {
 	require.NoError(t, tenant.DeleteSearchIndex(ctx, tx, proj1, "test_index"))
 	indexesInSearchStore, err = tenant.searchStore.AllCollections(ctx)
 	require.NoError(t, err)
-	require.Nil(t, indexesInSearchStore[tenant.getSearchCollName(proj1.Name(), factory.Name)])
+	require.Nil(t, indexesInSearchStore[tenant.getSearchCollName(proj1.Name(), factory.Name, 0)])
+
+	require.NoError(t, tx.Commit(ctx))
+
+	testClearDictionary(ctx, m.metaStore, m.kvStore)
+}
+
+// rulesRecordingStore records the ids of the synonyms and the curations pushed to the search store.
+type rulesRecordingStore struct {
+	search.Store
+
+	synonyms  []string
+	curations []string
+}
+
+func (s *rulesRecordingStore) UpsertSynonym(ctx context.Context, table string, synonym *search.Synonym) error {
+	s.synonyms = append(s.synonyms, synonym.Id)
+	return s.Store.UpsertSynonym(ctx, table, synonym)
+}
+
+func (s *rulesRecordingStore) UpsertCuration(ctx context.Context, table string, curation *search.Curation) error {
+	s.curations = append(s.curations, curation.Id)
+	return s.Store.UpsertCuration(ctx, table, curation)
+}
+
+func TestTenantManager_RecreateSearchIndexes(t *testing.T) {
+	tm := transaction.NewManager(kvStore)
+	m, ctx, cancel := NewTestTenantMgr(t, kvStore)
+	defer cancel()
+
+	m.searchStore = search.NewEmbeddedStore(nil, false)
+
+	_, err := m.CreateOrGetTenant(ctx, &TenantNamespace{"ns-test1", 2, NewNamespaceMetadata(2, "ns-test1", "ns-test1-display_name")})
+	require.NoError(t, err)
+
+	tenant := m.tenants["ns-test1"]
+	tx, err := tm.StartTx(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tenant.CreateProject(ctx, tx, tenantProj1, nil))
+	require.NoError(t, tenant.reload(ctx, tx, nil, nil))
+
+	proj1, err := tenant.GetProject(tenantProj1)
+	require.NoError(t, err)
+
+	factory, err := schema.NewFactoryBuilder(true).BuildSearch("test_index", []byte(`{
+		"title": "test_index",
+		"properties": {
+			"K1": { "type": "string" }
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, tenant.CreateSearchIndex(ctx, tx, proj1, factory))
+	require.NoError(t, tenant.CreateOrUpdateSearchSynonym(ctx, tx, proj1, "test_index", &search.Synonym{
+		Id:       "s1",
+		Synonyms: []string{"sneakers", "trainers"},
+	}))
+	require.NoError(t, tenant.CreateOrUpdateSearchCuration(ctx, tx, proj1, "test_index", &search.Curation{
+		Id:     "c1",
+		Query:  "shoes",
+		Match:  search.CurationExactMatch,
+		Hidden: []string{"1"},
+	}))
+
+	// the in-memory search store loses the index on a restart
+	store := &rulesRecordingStore{Store: search.NewEmbeddedStore(nil, false)}
+	tenant.searchStore = store
+	tenant.projects = make(map[string]*Project)
+
+	snapshot, err := store.AllCollections(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tenant.reload(ctx, tx, nil, snapshot))
+
+	indexesInSearchStore, err := store.AllCollections(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, indexesInSearchStore[tenant.Encoder.EncodeSearchTableName(tenant.namespace.Id(), proj1.Id(), "test_index")])
+	require.Equal(t, []string{"s1"}, store.synonyms)
+	require.Equal(t, []string{"c1"}, store.curations)
+
+	// the indexes already known by the server aren't pushed again
+	require.NoError(t, tenant.reload(ctx, tx, nil, snapshot))
+	require.Equal(t, []string{"s1"}, store.synonyms)
+	require.Equal(t, []string{"c1"}, store.curations)
 
 	require.NoError(t, tx.Commit(ctx))
 
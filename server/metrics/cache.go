@@ -0,0 +1,44 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/uber-go/tally"
+)
+
+var CacheMetrics tally.Scope
+
+func getCacheTags(namespace string, namespaceName string, projectName string, cacheName string) map[string]string {
+	return map[string]string{
+		"tigris_tenant":      namespace,
+		"tigris_tenant_name": GetTenantNameTagValue(namespace, namespaceName),
+		"project":            projectName,
+		"cache":              cacheName,
+	}
+}
+
+// UpdateCacheUsageMetrics reports the usage of a cache created with limits, the evictions and the rejected writes are
+// totals since the cache was created.
+func UpdateCacheUsageMetrics(namespace string, namespaceName string, projectName string, cacheName string, keys int64, bytes int64, evictions int64, rejectedWrites int64) {
+	if CacheMetrics == nil {
+		return
+	}
+
+	scope := CacheMetrics.Tagged(getCacheTags(namespace, namespaceName, projectName, cacheName))
+	scope.Gauge("keys").Update(float64(keys))
+	scope.Gauge("bytes").Update(float64(bytes))
+	scope.Gauge("evictions").Update(float64(evictions))
+	scope.Gauge("rejected_writes").Update(float64(rejectedWrites))
+}
@@ -156,6 +156,11 @@ func InitializeMetrics() func() {
 				initializeQueueScopes()
 			}
 
+			if cfg.Cache.Enabled {
+				// Usage of the caches
+				CacheMetrics = root.SubScope("cache")
+			}
+
 			// Metrics for Metronome - external billing service
 			MetronomeMetrics = root.SubScope("metronome")
 			initializeMetronomeScopes()
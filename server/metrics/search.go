@@ -23,6 +23,7 @@ var (
 	SearchErrorCount    tally.Scope
 	SearchRespTime      tally.Scope
 	SearchErrorRespTime tally.Scope
+	SearchDrift         tally.Scope
 )
 
 func getSearchOkTagKeys() []string {
@@ -58,6 +59,7 @@ func initializeSearchScopes() {
 	SearchErrorCount = SearchMetrics.SubScope("count")
 	SearchRespTime = SearchMetrics.SubScope("response")
 	SearchErrorRespTime = SearchMetrics.SubScope("error_response")
+	SearchDrift = SearchMetrics.SubScope("drift")
 }
 
 func GetSearchTags(reqMethodName string) map[string]string {
@@ -65,3 +67,30 @@ func GetSearchTags(reqMethodName string) map[string]string {
 		"search_method": reqMethodName,
 	}
 }
+
+func getSearchDriftTags(namespace string, namespaceName string, project string, branch string, collection string) map[string]string {
+	return map[string]string{
+		"tigris_tenant":      namespace,
+		"tigris_tenant_name": GetTenantNameTagValue(namespace, namespaceName),
+		"project":            project,
+		"db":                 project,
+		"branch":             branch,
+		"collection":         collection,
+	}
+}
+
+// UpdateSearchDriftMetrics reports the result of the last verification of the search index of a collection against
+// its primary rows.
+func UpdateSearchDriftMetrics(namespace string, namespaceName string, project string, branch string, collection string,
+	scanned int64, missing int64, stale int64, repaired int64,
+) {
+	if SearchDrift == nil {
+		return
+	}
+
+	scope := SearchDrift.Tagged(getSearchDriftTags(namespace, namespaceName, project, branch, collection))
+	scope.Gauge("scanned").Update(float64(scanned))
+	scope.Gauge("missing").Update(float64(missing))
+	scope.Gauge("stale").Update(float64(stale))
+	scope.Gauge("repaired").Update(float64(repaired))
+}
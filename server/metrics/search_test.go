@@ -51,4 +51,8 @@ func TestSearchMetrics(t *testing.T) {
 		testTimerTags := GetSearchTags("IndexDocuments")
 		defer SearchRespTime.Tagged(testTimerTags).Timer("time").Start().Stop()
 	})
+
+	t.Run("Test search drift gauges", func(t *testing.T) {
+		UpdateSearchDriftMetrics("test_namespace", "test_namespace", "test_project", "main", "test_collection", 10, 2, 1, 3)
+	})
 }
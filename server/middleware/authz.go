@@ -40,10 +40,13 @@ var (
 		api.ReadMethodName,
 		api.CountMethodName,
 		api.ExplainMethodName,
+		api.QueryMethodName,
+		api.ExplainQueryMethodName,
 		api.SearchMethodName,
 		api.ListProjectsMethodName,
 		api.DescribeDatabaseMethodName,
 		api.DescribeCollectionMethodName,
+		api.GetCollectionSearchVerificationMethodName,
 		api.ListBranchesMethodName,
 
 		// auth
@@ -54,6 +57,14 @@ var (
 		api.ListCachesMethodName,
 		api.GetMethodName,
 		api.KeysMethodName,
+		api.HGetMethodName,
+		api.HGetAllMethodName,
+		api.LRangeMethodName,
+		api.SMembersMethodName,
+		api.SIsMemberMethodName,
+		api.ZRangeMethodName,
+		api.ZRangeByScoreMethodName,
+		api.TTLMethodName,
 
 		// health
 		api.HealthMethodName,
@@ -72,12 +83,19 @@ var (
 
 		// realtime
 		api.ReadMessagesMethodName,
+		api.ListChannelPoliciesMethodName,
+		api.ListChannelBridgesMethodName,
+		api.ListChannelWebhooksMethodName,
 
 		// search
 		api.GetIndexMethodName,
 		api.ListIndexesMethodName,
 		api.SearchGetMethodName,
 		api.SearchSearch,
+		api.ListSynonymsMethodName,
+		api.ListCurationsMethodName,
+		api.GetQueryAnalyticsMethodName,
+		api.SuggestMethodName,
 	)
 
 	// editor.
@@ -93,7 +111,10 @@ var (
 		api.ReadMethodName,
 		api.CountMethodName,
 		api.BuildCollectionIndexMethodName,
+		api.VerifyCollectionSearchMethodName,
 		api.ExplainMethodName,
+		api.QueryMethodName,
+		api.ExplainQueryMethodName,
 		api.SearchMethodName,
 		api.ImportMethodName,
 		api.CreateOrUpdateCollectionMethodName,
@@ -106,6 +127,7 @@ var (
 		api.DeleteProjectMethodName,
 		api.DescribeDatabaseMethodName,
 		api.DescribeCollectionMethodName,
+		api.GetCollectionSearchVerificationMethodName,
 		api.CreateBranchMethodName,
 		api.DeleteBranchMethodName,
 		api.ListBranchesMethodName,
@@ -132,6 +154,29 @@ var (
 		api.GetMethodName,
 		api.DelMethodName,
 		api.KeysMethodName,
+		api.HSetMethodName,
+		api.HGetMethodName,
+		api.HGetAllMethodName,
+		api.HDelMethodName,
+		api.LPushMethodName,
+		api.RPushMethodName,
+		api.LPopMethodName,
+		api.RPopMethodName,
+		api.LRangeMethodName,
+		api.SAddMethodName,
+		api.SRemMethodName,
+		api.SMembersMethodName,
+		api.SIsMemberMethodName,
+		api.ZAddMethodName,
+		api.ZRemMethodName,
+		api.ZRangeMethodName,
+		api.ZRangeByScoreMethodName,
+		api.IncrMethodName,
+		api.IncrByMethodName,
+		api.DecrMethodName,
+		api.ExpireMethodName,
+		api.PersistMethodName,
+		api.TTLMethodName,
 
 		// health
 		api.HealthMethodName,
@@ -158,6 +203,19 @@ var (
 		api.ReadMessagesMethodName,
 		api.MessagesMethodName,
 		api.ListSubscriptionsMethodName,
+		api.GetPresenceMethodName,
+		api.CreateOrUpdateChannelPolicyMethodName,
+		api.ListChannelPoliciesMethodName,
+		api.DeleteChannelPolicyMethodName,
+		api.UpdateChannelRetentionMethodName,
+		api.GetChannelRetentionMethodName,
+		api.CreateOrUpdateChannelBridgeMethodName,
+		api.ListChannelBridgesMethodName,
+		api.DeleteChannelBridgeMethodName,
+		api.CreateOrUpdateChannelWebhookMethodName,
+		api.ListChannelWebhooksMethodName,
+		api.DeleteChannelWebhookMethodName,
+		api.ListWebhookDeadLettersMethodName,
 
 		// search
 		api.CreateOrUpdateIndexMethodName,
@@ -171,6 +229,14 @@ var (
 		api.SearchUpdate,
 		api.SearchDeleteByQuery,
 		api.SearchSearch,
+		api.CreateOrUpdateSynonymMethodName,
+		api.ListSynonymsMethodName,
+		api.DeleteSynonymMethodName,
+		api.CreateOrUpdateCurationMethodName,
+		api.ListCurationsMethodName,
+		api.DeleteCurationMethodName,
+		api.GetQueryAnalyticsMethodName,
+		api.SuggestMethodName,
 	)
 
 	ownerMethods = container.NewHashSet(
@@ -185,7 +251,10 @@ var (
 		api.ReadMethodName,
 		api.CountMethodName,
 		api.BuildCollectionIndexMethodName,
+		api.VerifyCollectionSearchMethodName,
 		api.ExplainMethodName,
+		api.QueryMethodName,
+		api.ExplainQueryMethodName,
 		api.SearchMethodName,
 		api.ImportMethodName,
 		api.CreateOrUpdateCollectionMethodName,
@@ -199,6 +268,7 @@ var (
 		api.DeleteProjectMethodName,
 		api.DescribeDatabaseMethodName,
 		api.DescribeCollectionMethodName,
+		api.GetCollectionSearchVerificationMethodName,
 		api.CreateBranchMethodName,
 		api.DeleteBranchMethodName,
 		api.ListBranchesMethodName,
@@ -235,6 +305,29 @@ var (
 		api.GetMethodName,
 		api.DelMethodName,
 		api.KeysMethodName,
+		api.HSetMethodName,
+		api.HGetMethodName,
+		api.HGetAllMethodName,
+		api.HDelMethodName,
+		api.LPushMethodName,
+		api.RPushMethodName,
+		api.LPopMethodName,
+		api.RPopMethodName,
+		api.LRangeMethodName,
+		api.SAddMethodName,
+		api.SRemMethodName,
+		api.SMembersMethodName,
+		api.SIsMemberMethodName,
+		api.ZAddMethodName,
+		api.ZRemMethodName,
+		api.ZRangeMethodName,
+		api.ZRangeByScoreMethodName,
+		api.IncrMethodName,
+		api.IncrByMethodName,
+		api.DecrMethodName,
+		api.ExpireMethodName,
+		api.PersistMethodName,
+		api.TTLMethodName,
 
 		// health
 		api.HealthMethodName,
@@ -261,6 +354,19 @@ var (
 		api.ReadMessagesMethodName,
 		api.MessagesMethodName,
 		api.ListSubscriptionsMethodName,
+		api.GetPresenceMethodName,
+		api.CreateOrUpdateChannelPolicyMethodName,
+		api.ListChannelPoliciesMethodName,
+		api.DeleteChannelPolicyMethodName,
+		api.UpdateChannelRetentionMethodName,
+		api.GetChannelRetentionMethodName,
+		api.CreateOrUpdateChannelBridgeMethodName,
+		api.ListChannelBridgesMethodName,
+		api.DeleteChannelBridgeMethodName,
+		api.CreateOrUpdateChannelWebhookMethodName,
+		api.ListChannelWebhooksMethodName,
+		api.DeleteChannelWebhookMethodName,
+		api.ListWebhookDeadLettersMethodName,
 
 		// search
 		api.CreateOrUpdateIndexMethodName,
@@ -274,6 +380,14 @@ var (
 		api.SearchUpdate,
 		api.SearchDeleteByQuery,
 		api.SearchSearch,
+		api.CreateOrUpdateSynonymMethodName,
+		api.ListSynonymsMethodName,
+		api.DeleteSynonymMethodName,
+		api.CreateOrUpdateCurationMethodName,
+		api.ListCurationsMethodName,
+		api.DeleteCurationMethodName,
+		api.GetQueryAnalyticsMethodName,
+		api.SuggestMethodName,
 	)
 	clusterAdminMethods = container.NewHashSet(
 		// db
@@ -287,7 +401,10 @@ var (
 		api.ReadMethodName,
 		api.CountMethodName,
 		api.BuildCollectionIndexMethodName,
+		api.VerifyCollectionSearchMethodName,
 		api.ExplainMethodName,
+		api.QueryMethodName,
+		api.ExplainQueryMethodName,
 		api.SearchMethodName,
 		api.ImportMethodName,
 		api.CreateOrUpdateCollectionMethodName,
@@ -299,6 +416,7 @@ var (
 		api.DeleteProjectMethodName,
 		api.DescribeDatabaseMethodName,
 		api.DescribeCollectionMethodName,
+		api.GetCollectionSearchVerificationMethodName,
 		api.CreateBranchMethodName,
 		api.DeleteBranchMethodName,
 		api.ListBranchesMethodName,
@@ -329,6 +447,29 @@ var (
 		api.GetMethodName,
 		api.DelMethodName,
 		api.KeysMethodName,
+		api.HSetMethodName,
+		api.HGetMethodName,
+		api.HGetAllMethodName,
+		api.HDelMethodName,
+		api.LPushMethodName,
+		api.RPushMethodName,
+		api.LPopMethodName,
+		api.RPopMethodName,
+		api.LRangeMethodName,
+		api.SAddMethodName,
+		api.SRemMethodName,
+		api.SMembersMethodName,
+		api.SIsMemberMethodName,
+		api.ZAddMethodName,
+		api.ZRemMethodName,
+		api.ZRangeMethodName,
+		api.ZRangeByScoreMethodName,
+		api.IncrMethodName,
+		api.IncrByMethodName,
+		api.DecrMethodName,
+		api.ExpireMethodName,
+		api.PersistMethodName,
+		api.TTLMethodName,
 
 		// health
 		api.HealthMethodName,
@@ -358,6 +499,19 @@ var (
 		api.ReadMessagesMethodName,
 		api.MessagesMethodName,
 		api.ListSubscriptionsMethodName,
+		api.GetPresenceMethodName,
+		api.CreateOrUpdateChannelPolicyMethodName,
+		api.ListChannelPoliciesMethodName,
+		api.DeleteChannelPolicyMethodName,
+		api.UpdateChannelRetentionMethodName,
+		api.GetChannelRetentionMethodName,
+		api.CreateOrUpdateChannelBridgeMethodName,
+		api.ListChannelBridgesMethodName,
+		api.DeleteChannelBridgeMethodName,
+		api.CreateOrUpdateChannelWebhookMethodName,
+		api.ListChannelWebhooksMethodName,
+		api.DeleteChannelWebhookMethodName,
+		api.ListWebhookDeadLettersMethodName,
 
 		// search
 		api.CreateOrUpdateIndexMethodName,
@@ -371,6 +525,14 @@ var (
 		api.SearchUpdate,
 		api.SearchDeleteByQuery,
 		api.SearchSearch,
+		api.CreateOrUpdateSynonymMethodName,
+		api.ListSynonymsMethodName,
+		api.DeleteSynonymMethodName,
+		api.CreateOrUpdateCurationMethodName,
+		api.ListCurationsMethodName,
+		api.DeleteCurationMethodName,
+		api.GetQueryAnalyticsMethodName,
+		api.SuggestMethodName,
 	)
 )
 
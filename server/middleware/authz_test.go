@@ -33,7 +33,10 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.ReadMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.CountMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.BuildCollectionIndexMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.VerifyCollectionSearchMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ExplainMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.QueryMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ExplainQueryMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ImportMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateCollectionMethodName, auth.OwnerRoleName))
@@ -46,6 +49,7 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.DeleteProjectMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeDatabaseMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeCollectionMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.GetCollectionSearchVerificationMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateBranchMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DeleteBranchMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ListBranchesMethodName, auth.OwnerRoleName))
@@ -82,6 +86,12 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.GetMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.DelMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.KeysMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.HSetMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.LPushMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.SAddMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ZAddMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.IncrByMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ExpireMethodName, auth.OwnerRoleName))
 
 	// health
 	require.True(t, isAuthorizedOperation(api.HealthMethodName, auth.OwnerRoleName))
@@ -108,6 +118,13 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.ReadMessagesMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.MessagesMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.ListSubscriptionsMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.GetPresenceMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateOrUpdateChannelPolicyMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteChannelPolicyMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.UpdateChannelRetentionMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateOrUpdateChannelBridgeMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateOrUpdateChannelWebhookMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.ListWebhookDeadLettersMethodName, auth.OwnerRoleName))
 
 	// search
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateIndexMethodName, auth.OwnerRoleName))
@@ -120,6 +137,10 @@ func TestAuthzOwnerRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.SearchCreateOrReplace, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchUpdate, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchDeleteByQuery, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateOrUpdateSynonymMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteCurationMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.GetQueryAnalyticsMethodName, auth.OwnerRoleName))
+	require.True(t, isAuthorizedOperation(api.SuggestMethodName, auth.OwnerRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchSearch, auth.OwnerRoleName))
 
 	// negative
@@ -141,7 +162,10 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.ReadMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.CountMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.BuildCollectionIndexMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.VerifyCollectionSearchMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ExplainMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.QueryMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.ExplainQueryMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ImportMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateCollectionMethodName, auth.EditorRoleName))
@@ -154,6 +178,7 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.DeleteProjectMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeDatabaseMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeCollectionMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.GetCollectionSearchVerificationMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.CreateBranchMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.DeleteBranchMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ListBranchesMethodName, auth.EditorRoleName))
@@ -180,6 +205,10 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.GetMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.DelMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.KeysMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.HDelMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.RPopMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.DecrMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.PersistMethodName, auth.EditorRoleName))
 
 	// health
 	require.True(t, isAuthorizedOperation(api.HealthMethodName, auth.EditorRoleName))
@@ -206,6 +235,12 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.ReadMessagesMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.MessagesMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.ListSubscriptionsMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.GetPresenceMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateOrUpdateChannelPolicyMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteChannelPolicyMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.UpdateChannelRetentionMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateOrUpdateChannelBridgeMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateOrUpdateChannelWebhookMethodName, auth.EditorRoleName))
 
 	// search
 	require.True(t, isAuthorizedOperation(api.CreateOrUpdateIndexMethodName, auth.EditorRoleName))
@@ -218,6 +253,10 @@ func TestAuthzEditorRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.SearchCreateOrReplace, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchUpdate, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchDeleteByQuery, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.CreateOrUpdateSynonymMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.DeleteCurationMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.GetQueryAnalyticsMethodName, auth.EditorRoleName))
+	require.True(t, isAuthorizedOperation(api.SuggestMethodName, auth.EditorRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchSearch, auth.EditorRoleName))
 
 	// negative
@@ -240,10 +279,13 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.ReadMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.CountMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.ExplainMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.QueryMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ExplainQueryMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.ListProjectsMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeDatabaseMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.DescribeCollectionMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.GetCollectionSearchVerificationMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.ListBranchesMethodName, auth.ReadOnlyRoleName))
 
 	// auth
@@ -254,6 +296,11 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 	require.True(t, isAuthorizedOperation(api.ListCachesMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.GetMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.KeysMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.HGetAllMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.LRangeMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.SIsMemberMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ZRangeByScoreMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.TTLMethodName, auth.ReadOnlyRoleName))
 
 	// health
 	require.True(t, isAuthorizedOperation(api.HealthMethodName, auth.ReadOnlyRoleName))
@@ -272,15 +319,26 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 
 	// realtime
 	require.True(t, isAuthorizedOperation(api.ReadMessagesMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListChannelPoliciesMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListChannelBridgesMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListChannelWebhooksMethodName, auth.ReadOnlyRoleName))
 
 	// search
 	require.True(t, isAuthorizedOperation(api.GetIndexMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.ListIndexesMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchGetMethodName, auth.ReadOnlyRoleName))
 	require.True(t, isAuthorizedOperation(api.SearchSearch, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListSynonymsMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.ListCurationsMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.GetQueryAnalyticsMethodName, auth.ReadOnlyRoleName))
+	require.True(t, isAuthorizedOperation(api.SuggestMethodName, auth.ReadOnlyRoleName))
 
 	// negative
 	require.False(t, isAuthorizedOperation(api.BeginTransactionMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.HSetMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.ZAddMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.IncrMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.ExpireMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.CommitTransactionMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.RollbackTransactionMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.InsertMethodName, auth.ReadOnlyRoleName))
@@ -292,6 +350,7 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 	require.False(t, isAuthorizedOperation(api.CreateOrUpdateCollectionsMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.DeleteProjectMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.DropCollectionMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.VerifyCollectionSearchMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.CreateCacheMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.SetMethodName, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.GetSetMethodName, auth.ReadOnlyRoleName))
@@ -327,4 +386,11 @@ func TestAuthzReadOnlyRole(t *testing.T) {
 	require.False(t, isAuthorizedOperation(api.SearchCreateOrReplace, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.SearchUpdate, auth.ReadOnlyRoleName))
 	require.False(t, isAuthorizedOperation(api.SearchDeleteByQuery, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.CreateOrUpdateSynonymMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.CreateOrUpdateChannelPolicyMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.UpdateChannelRetentionMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.DeleteChannelBridgeMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.CreateOrUpdateChannelWebhookMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.ListWebhookDeadLettersMethodName, auth.ReadOnlyRoleName))
+	require.False(t, isAuthorizedOperation(api.DeleteCurationMethodName, auth.ReadOnlyRoleName))
 }
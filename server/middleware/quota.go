@@ -35,7 +35,13 @@ func quotaUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 		ns, _ := request.GetNamespace(ctx)
 
 		if m := info.FullMethod; m != api.HealthMethodName && !request.IsAdminApi(m) {
-			if err := quota.Allow(ctx, ns, proto.Size(req.(proto.Message)), request.IsWrite(ctx)); err != nil {
+			var err error
+			if m == api.SuggestMethodName {
+				err = quota.AllowSuggest(ns)
+			} else {
+				err = quota.Allow(ctx, ns, proto.Size(req.(proto.Message)), request.IsWrite(ctx))
+			}
+			if err != nil {
 				return nil, err
 			}
 		}
@@ -29,6 +29,7 @@ var (
 	ErrWriteUnitsExceeded     = errors.ResourceExhausted("request write rate exceeded")
 	ErrStorageSizeExceeded    = errors.ResourceExhausted("data size limit exceeded")
 	ErrMaxRequestSizeExceeded = errors.ResourceExhausted("maximum request size limit exceeded")
+	ErrSuggestRateExceeded    = errors.ResourceExhausted("request suggest rate exceeded")
 )
 
 type Quota interface {
@@ -59,7 +60,8 @@ func (s *State) Wait(ctx context.Context, size int, isWrite bool) error {
 }
 
 type Manager struct {
-	quota []Quota
+	quota   []Quota
+	suggest *suggest
 }
 
 var mgr Manager
@@ -100,7 +102,12 @@ func initManager(tm *metadata.TenantManager, cfg *config.Config) *Manager {
 		}
 	}
 
-	return &Manager{quota: q}
+	m := &Manager{quota: q}
+	if (cfg.Quota.Node.Enabled || cfg.Quota.Namespace.Enabled) && cfg.Quota.SuggestRate > 0 {
+		m.suggest = initSuggest(&cfg.Quota)
+	}
+
+	return m
 }
 
 func Init(tm *metadata.TenantManager, cfg *config.Config) error {
@@ -159,6 +166,15 @@ func Allow(ctx context.Context, namespace string, size int, isWrite bool) error
 	return nil
 }
 
+// AllowSuggest checks the rate of the suggest requests of the namespace, they don't consume the read units.
+func AllowSuggest(namespace string) error {
+	if mgr.suggest == nil {
+		return nil
+	}
+
+	return mgr.suggest.Allow(namespace)
+}
+
 func Wait(ctx context.Context, namespace string, size int, isWrite bool) error {
 	for _, q := range mgr.quota {
 		if err := q.Wait(ctx, namespace, size, isWrite); err != nil {
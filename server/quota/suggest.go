@@ -0,0 +1,55 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/server/config"
+	"golang.org/x/time/rate"
+)
+
+// This limiter limits the rate of the suggest requests
+// of each namespace on the node. The suggest requests
+// don't consume the read units of the namespace.
+
+type suggest struct {
+	limiters sync.Map
+	cfg      *config.QuotaConfig
+}
+
+func (s *suggest) Allow(namespace string) error {
+	if !s.getLimiter(namespace).Allow() {
+		return ErrSuggestRateExceeded
+	}
+
+	return nil
+}
+
+func (s *suggest) getLimiter(namespace string) *rate.Limiter {
+	l, ok := s.limiters.Load(namespace)
+	if !ok {
+		l, _ = s.limiters.LoadOrStore(namespace, rate.NewLimiter(rate.Limit(s.cfg.SuggestRate), s.cfg.SuggestRate))
+	}
+
+	return l.(*rate.Limiter)
+}
+
+func initSuggest(cfg *config.QuotaConfig) *suggest {
+	log.Debug().Int("suggest_rate", cfg.SuggestRate).Msg("Initializing suggest quota manager")
+
+	return &suggest{cfg: cfg}
+}
@@ -0,0 +1,43 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+func TestSuggestQuota(t *testing.T) {
+	m := initSuggest(&config.QuotaConfig{SuggestRate: 2})
+
+	require.NoError(t, m.Allow("ns1"))
+	require.NoError(t, m.Allow("ns1"))
+	require.Equal(t, ErrSuggestRateExceeded, m.Allow("ns1"))
+
+	// the namespaces have their own rate
+	require.NoError(t, m.Allow("ns2"))
+
+	// only limited with the node or the namespace quotas
+	cfg := config.DefaultConfig
+	cfg.Quota.Storage.Enabled = false
+	cfg.Metrics.Size.Enabled = false
+	cfg.Quota.SuggestRate = 10
+	require.Nil(t, initManager(nil, &cfg).suggest)
+
+	cfg.Quota.Node.Enabled = true
+	require.NotNil(t, initManager(nil, &cfg).suggest)
+}
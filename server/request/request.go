@@ -424,11 +424,14 @@ func isRead(name string) bool {
 	}
 
 	switch name {
-	case api.ReadMethodName, api.SearchMethodName:
+	case api.ReadMethodName, api.SearchMethodName, api.QueryMethodName, api.SuggestMethodName:
 		return true
 	case api.ListCollectionsMethodName, api.ListProjectsMethodName:
 		return true
-	case api.DescribeCollectionMethodName, api.DescribeDatabaseMethodName:
+	case api.DescribeCollectionMethodName, api.DescribeDatabaseMethodName, api.GetCollectionSearchVerificationMethodName:
+		return true
+	case api.HGetMethodName, api.HGetAllMethodName, api.LRangeMethodName, api.SMembersMethodName, api.SIsMemberMethodName,
+		api.ZRangeMethodName, api.ZRangeByScoreMethodName, api.TTLMethodName:
 		return true
 	default:
 		return false
@@ -17,81 +17,67 @@ package search
 import (
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/query/search"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+	searchStore "github.com/tigrisdata/tigris/store/search"
 )
 
 // FacetResponse is a builder utility to convert facets from search backend to tigris response.
 type FacetResponse struct {
 	// count of facet values requested for each field
 	facetSizes map[string]int
+	// range facets don't have stats
+	rangeFacets map[string]struct{}
 }
 
 func NewFacetResponse(query search.Facets) *FacetResponse {
 	facetSizeRequested := map[string]int{}
+	rangeFacets := map[string]struct{}{}
 	for _, f := range query.Fields {
 		facetSizeRequested[f.Name] = f.Size
+		if f.IsRange() {
+			rangeFacets[f.Name] = struct{}{}
+		}
 	}
-	return &FacetResponse{facetSizes: facetSizeRequested}
+	return &FacetResponse{facetSizes: facetSizeRequested, rangeFacets: rangeFacets}
 }
 
 // Build converts search backend response to api.SearchFacet.
-func (fb *FacetResponse) Build(r *[]tsApi.FacetCounts) map[string]*api.SearchFacet {
+func (fb *FacetResponse) Build(facets []searchStore.Facet) map[string]*api.SearchFacet {
 	result := map[string]*api.SearchFacet{}
 
-	// return empty map
-	if r == nil {
-		return result
-	}
-
-	for _, fc := range *r {
-		// skip if no field name
-		if fc.FieldName == nil {
-			continue
-		}
-		fieldName := *fc.FieldName
+	for _, fc := range facets {
 		// skip if this facets for this field name were not requested, likely not to happen
-		if _, ok := fb.facetSizes[fieldName]; !ok {
+		if _, ok := fb.facetSizes[fc.Field]; !ok {
 			continue
 		}
 
 		stats := &api.FacetStats{}
 		if fc.Stats != nil {
-			if fc.Stats.Avg != nil {
-				stats.Avg = fc.Stats.Avg
-			}
-			if fc.Stats.Max != nil {
-				stats.Max = fc.Stats.Max
-			}
-			if fc.Stats.Min != nil {
-				stats.Min = fc.Stats.Min
-			}
-			if fc.Stats.Sum != nil {
-				stats.Sum = fc.Stats.Sum
-			}
-			if fc.Stats.TotalValues != nil {
-				stats.Count = int64(*fc.Stats.TotalValues)
-			}
+			stats.Avg = fc.Stats.Avg
+			stats.Max = fc.Stats.Max
+			stats.Min = fc.Stats.Min
+			stats.Sum = fc.Stats.Sum
+			stats.Count = fc.Stats.Count
 		}
 
 		facet := &api.SearchFacet{
 			Counts: []*api.FacetCount{},
 			Stats:  stats,
 		}
+		if _, ok := fb.rangeFacets[fc.Field]; ok {
+			facet.Stats = nil
+		}
 
-		if fc.Counts != nil {
-			for _, tsCount := range *fc.Counts {
-				// skip null 'value' for a faceted field
-				// skip if the user requested size for a facet field has been met
-				if tsCount.Value == nil || len(facet.Counts) >= fb.facetSizes[fieldName] {
-					continue
-				}
-				facet.Counts = append(facet.Counts, &api.FacetCount{
-					Count: int64(*tsCount.Count),
-					Value: *tsCount.Value,
-				})
+		for _, count := range fc.Counts {
+			// skip if the user requested size for a facet field has been met
+			if len(facet.Counts) >= fb.facetSizes[fc.Field] {
+				break
 			}
+			facet.Counts = append(facet.Counts, &api.FacetCount{
+				Count: count.Count,
+				Value: count.Value,
+			})
 		}
-		result[fieldName] = facet
+		result[fc.Field] = facet
 	}
 
 	return result
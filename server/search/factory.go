@@ -16,7 +16,7 @@ package search
 
 import (
 	"github.com/tigrisdata/tigris/query/search"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+	searchStore "github.com/tigrisdata/tigris/store/search"
 )
 
 // ResponseFactory is used to convert raw hits response to our Iterable that has final order of hits.
@@ -30,7 +30,7 @@ func NewResponseFactory(inputQuery *search.Query) *ResponseFactory {
 	}
 }
 
-func (r *ResponseFactory) GetResponse(response []tsApi.SearchResult) Response {
+func (r *ResponseFactory) GetResponse(response []searchStore.Result) Response {
 	resp := Response{}
 	if r.inputQuery.IsGroupByQuery() {
 		resp.groups = r.GetGroupedHitsIterator(response)
@@ -41,18 +41,16 @@ func (r *ResponseFactory) GetResponse(response []tsApi.SearchResult) Response {
 	return resp
 }
 
-func (*ResponseFactory) GetGroupedHitsIterator(response []tsApi.SearchResult) *Groups {
+func (*ResponseFactory) GetGroupedHitsIterator(response []searchStore.Result) *Groups {
 	groups := NewGroups()
 	for _, r := range response {
-		if r.GroupedHits != nil {
-			for _, g := range *r.GroupedHits {
-				hits := NewHits()
-				for i := range g.Hits {
-					hits.add(NewSearchHit(&g.Hits[i]))
-				}
-
-				groups.add(NewGroup(g.GroupKey, hits.hits))
+		for _, g := range r.Groups {
+			hits := NewHits()
+			for i := range g.Hits {
+				hits.add(NewSearchHit(&g.Hits[i]))
 			}
+
+			groups.add(NewGroup(g.Key, hits.hits))
 		}
 	}
 
@@ -60,14 +58,12 @@ func (*ResponseFactory) GetGroupedHitsIterator(response []tsApi.SearchResult) *G
 }
 
 // GetHitsIterator returns an IHits interface which contains hits results in an order that we need to stream out to the user.
-func (*ResponseFactory) GetHitsIterator(response []tsApi.SearchResult) IHits {
+func (*ResponseFactory) GetHitsIterator(response []searchStore.Result) IHits {
 	var hits IHitsMutable = NewHits()
 
 	for _, r := range response {
-		if r.Hits != nil {
-			for i := range *r.Hits {
-				hits.add(NewSearchHit(&(*r.Hits)[i]))
-			}
+		for i := range r.Hits {
+			hits.add(NewSearchHit(&r.Hits[i]))
 		}
 	}
 
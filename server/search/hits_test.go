@@ -17,7 +17,6 @@ package search
 import (
 	"bytes"
 	"fmt"
-	"sort"
 	"testing"
 	"time"
 
@@ -25,7 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tigrisdata/tigris/lib/date"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+	searchStore "github.com/tigrisdata/tigris/store/search"
 )
 
 func TestNewSearchHit(t *testing.T) {
@@ -33,29 +32,29 @@ func TestNewSearchHit(t *testing.T) {
 	for _, d := range documents {
 		allDocs = append(allDocs, d)
 	}
-	tsHits := generateTsHits(allDocs...)
+	storeHits := generateStoreHits(allDocs...)
 
 	t.Run("with valid input", func(t *testing.T) {
-		searchHits := make([]*Hit, len(tsHits))
-		for i := range tsHits {
-			searchHits[i] = NewSearchHit(&tsHits[i])
+		searchHits := make([]*Hit, len(storeHits))
+		for i := range storeHits {
+			searchHits[i] = NewSearchHit(&storeHits[i])
 		}
 
-		assert.Len(t, searchHits, len(tsHits))
+		assert.Len(t, searchHits, len(storeHits))
 		for i, hit := range searchHits {
 			assert.NotNil(t, hit)
-			assert.Equal(t, fmt.Sprintf("%d", *tsHits[i].TextMatch), hit.Match.Score)
-			assert.Equal(t, *tsHits[i].Document, hit.Document)
+			assert.Equal(t, fmt.Sprintf("%d", *storeHits[i].TextMatch), hit.Match.Score)
+			assert.Equal(t, storeHits[i].Document, hit.Document)
 		}
 	})
 
-	t.Run("with nil ts hit", func(t *testing.T) {
+	t.Run("with nil store hit", func(t *testing.T) {
 		hit := NewSearchHit(nil)
 		assert.Nil(t, hit)
 	})
 
 	t.Run("with nil document", func(t *testing.T) {
-		hit := NewSearchHit(&tsApi.SearchResultHit{
+		hit := NewSearchHit(&searchStore.Hit{
 			Document: nil,
 		})
 		assert.Nil(t, hit)
@@ -63,8 +62,8 @@ func TestNewSearchHit(t *testing.T) {
 
 	t.Run("with empty document", func(t *testing.T) {
 		d := make(map[string]any)
-		hit := NewSearchHit(&tsApi.SearchResultHit{
-			Document: &d,
+		hit := NewSearchHit(&searchStore.Hit{
+			Document: d,
 		})
 		assert.NotNil(t, hit)
 		assert.Empty(t, hit.Document)
@@ -72,8 +71,8 @@ func TestNewSearchHit(t *testing.T) {
 
 	t.Run("with nil text match score", func(t *testing.T) {
 		d := make(map[string]any)
-		hit := NewSearchHit(&tsApi.SearchResultHit{
-			Document:  &d,
+		hit := NewSearchHit(&searchStore.Hit{
+			Document:  d,
 			TextMatch: nil,
 		})
 		assert.Equal(t, "", hit.Match.Score)
@@ -89,8 +88,8 @@ func TestSearchHit_isFieldMissingOrNil(t *testing.T) {
 		"nil_field":         nil,
 		"empty_slice_field": []string{},
 	}
-	tsHit := tsApi.SearchResultHit{Document: &doc}
-	searchHit := NewSearchHit(&tsHit)
+	storeHit := searchStore.Hit{Document: doc}
+	searchHit := NewSearchHit(&storeHit)
 
 	t.Run("when field is absent", func(t *testing.T) {
 		assert.True(t, searchHit.isFieldMissingOrNil("some_field"))
@@ -108,124 +107,34 @@ func TestSearchHit_isFieldMissingOrNil(t *testing.T) {
 	})
 }
 
-func TestMatchedFields(t *testing.T) {
-	cases := []struct {
-		resp       []byte
-		expMatched []string
-	}{
-		{
-			[]byte(`{
-"results": [{
-		"hits": [{
-            "document": {},
-			"highlight": {
-				"arr_obj": [{
-					"domain": { "matched_tokens": [], "snippet": "regional24-7.com"},
-					"arr": [{"matched_tokens": [], "snippet": "Daihatsu"}, {"matched_tokens": [],"snippet": "Chrysler"}]
-				}, {
-					"domain": { "matched_tokens": [], "snippet": "internalorchestrate.name"},
-					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}, {"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
-				}, {
-					"domain": {"matched_tokens": [],"snippet": "nationalincubate.net"},
-					"arr": [{"matched_tokens": [],"snippet": "Daewoo"}, {"matched_tokens": [],"snippet": "Cadillac"}]
-				}]
-			}
-		}, {
-            "document": {},
-			"highlight": {
-				"arr_obj": [{
-					"domain": {"matched_tokens": [],"snippet": "internalorchestrate.name"},
-					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}, {"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
-				}, {
-					"domain": {	"matched_tokens": [],"snippet": "globalstrategic.net"},
-					"arr": [{"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": [],"snippet": "Volkswagen"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
-				}]
-			}
-		}]
-	}]
-}`),
-			[]string{"arr_obj.arr", "arr_obj.arr", "arr_obj.arr"},
-		},
-		{
-			[]byte(`{
-	"results": [{
-		"hits": [{
-            "document": {},
- 			"highlight": {
- 				"nested.address.city": {
- 					"matched_tokens": ["Omaha"],
- 					"snippet": "<mark>Omaha</mark>"
- 				}
- 			}
-		}]
-	}]
-}`),
-			[]string{"nested.address.city"},
-		},
-		{
-			[]byte(`{
-	"results": [{
-		"hits": [{
-            "document": {},
-            "highlight": {
-				"arr_obj": [{
-					"domain": {"matched_tokens": ["regional24-7.com"], "snippet": "<mark>regional24-7.com</mark>"},
-					"arr": [{"matched_tokens": [], "snippet": "Chrysler"}]
-				}, {
-					"domain": {"matched_tokens": [],"snippet": "internalorchestrate.name"},
-					"arr": [{"matched_tokens": [],"snippet": "Dino"}]
-				}] 
-			}
-		}]
-	}]
-}`),
-			[]string{"arr_obj.domain"},
-		},
-		{
-			[]byte(`{
-	"results": [{
-		"hits": [{
-            "document": {},
-            "highlight": {
-				"arr_obj": [{
-					"domain": { "matched_tokens": [], "snippet": "regional24-7.com"},
-					"arr": [{"matched_tokens": [], "snippet": "Daihatsu"}]
-				}, {
-					"domain": { "matched_tokens": [], "snippet": "internalorchestrate.name"},
-					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
-				}],
-				"commands_obj.name": {
-					"matched_tokens": ["dino"],
-					"snippet": "<mark>dino</mark>"
-				},
-				"name": {
-					"matched_tokens": ["dino"],
-					"snippet": "<mark>dino</mark>"
-				}
-			}
-		}]
-	}]
-}`),
-			[]string{"arr_obj.arr", "commands_obj.name", "name"},
-		},
-	}
-	for _, c := range cases {
-		var actualMatched []string
-		var dest tsApi.MultiSearchResult
-		require.NoError(t, jsoniter.Unmarshal(c.resp, &dest))
-		for _, d := range dest.Results {
-			for i := range *d.Hits {
-				h := NewSearchHit(&(*d.Hits)[i])
-				for _, m := range h.Match.Fields {
-					actualMatched = append(actualMatched, m.Name)
-				}
-			}
-		}
+func TestNewSearchHit_MatchedFields(t *testing.T) {
+	hit := NewSearchHit(&searchStore.Hit{
+		Document:      map[string]any{},
+		MatchedFields: []string{"arr_obj.arr", "name"},
+	})
+	require.Len(t, hit.Match.Fields, 2)
+	require.Equal(t, "arr_obj.arr", hit.Match.Fields[0].Name)
+	require.Equal(t, "name", hit.Match.Fields[1].Name)
+}
 
-		sort.Strings(c.expMatched)
-		sort.Strings(actualMatched)
-		require.Equal(t, c.expMatched, actualMatched)
-	}
+func TestNewSearchHit_Highlights(t *testing.T) {
+	hit := NewSearchHit(&searchStore.Hit{Document: map[string]any{}})
+	require.Nil(t, hit.Highlights)
+
+	hit = NewSearchHit(&searchStore.Hit{
+		Document: map[string]any{},
+		Highlights: []searchStore.Highlight{
+			{Field: "tags", Snippets: []string{"<mark>red</mark>"}, MatchedTokens: []string{"red"}},
+			{Field: "title", Snippet: "<mark>Red</mark> shoe", Value: "a <mark>Red</mark> shoe", MatchedTokens: []string{"Red"}},
+		},
+	})
+	require.Len(t, hit.Highlights, 2)
+	require.Equal(t, "tags", hit.Highlights[0].Field)
+	require.Equal(t, []string{"<mark>red</mark>"}, hit.Highlights[0].Snippets)
+	require.Equal(t, "title", hit.Highlights[1].Field)
+	require.Equal(t, "<mark>Red</mark> shoe", hit.Highlights[1].Snippet)
+	require.Equal(t, "a <mark>Red</mark> shoe", hit.Highlights[1].Value)
+	require.Equal(t, []string{"Red"}, hit.Highlights[1].MatchedTokens)
 }
 
 func dateFrom(dateStr string) int64 {
@@ -300,8 +209,8 @@ var documents = map[string]document{
 }
 
 // helper to generate hits.
-func generateTsHits(docs ...document) []tsApi.SearchResultHit {
-	hits := make([]tsApi.SearchResultHit, 0, len(docs))
+func generateStoreHits(docs ...document) []searchStore.Hit {
+	hits := make([]searchStore.Hit, 0, len(docs))
 	for _, doc := range docs {
 		encoded, err := jsoniter.Marshal(doc)
 		if err != nil {
@@ -313,8 +222,8 @@ func generateTsHits(docs ...document) []tsApi.SearchResultHit {
 		var decoded map[string]any
 		_ = decoder.Decode(&decoded)
 		score := doc["_text_match"].(int64)
-		hits = append(hits, tsApi.SearchResultHit{
-			Document:  &decoded,
+		hits = append(hits, searchStore.Hit{
+			Document:  decoded,
 			TextMatch: &score,
 		})
 	}
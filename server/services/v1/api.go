@@ -97,7 +97,15 @@ func newApiService(kv kv.TxStore, searchStore search.Store, tenantMgr *metadata.
 	}
 	if config.DefaultConfig.Search.WriteEnabled {
 		// just for testing so that we can disable it if needed
-		txListeners = append(txListeners, database.NewSearchIndexer(searchStore, tenantMgr))
+		txListeners = append(txListeners, database.NewSearchIndexer(searchStore, tenantMgr, txMgr))
+	}
+	if config.DefaultConfig.Workers.Enabled {
+		// vectors of the embedding fields are computed by the workers
+		txListeners = append(txListeners, database.NewEmbeddingListener(tenantMgr))
+	}
+	if config.DefaultConfig.Realtime.ChannelBridgeEnabled {
+		_, _, channelFactory := getRealtimeChannels()
+		txListeners = append(txListeners, database.NewChannelBridgeListener(tenantMgr, txMgr, channelFactory))
 	}
 
 	if config.DefaultConfig.Tracing.Enabled {
@@ -105,7 +113,7 @@ func newApiService(kv kv.TxStore, searchStore search.Store, tenantMgr *metadata.
 	} else {
 		u.sessions = database.NewSessionManager(u.txMgr, u.tenantMgr, txListeners, metadata.NewCacheTracker(tenantMgr, txMgr))
 	}
-	u.runnerFactory = database.NewQueryRunnerFactory(u.txMgr, u.cdcMgr, u.searchStore)
+	u.runnerFactory = database.NewQueryRunnerFactory(u.txMgr, u.cdcMgr, u.searchStore, tenantMgr.GetSearchAnalytics())
 
 	return u
 }
@@ -376,6 +384,37 @@ func (s *apiService) Explain(ctx context.Context, r *api.ReadRequest) (*api.Expl
 	return resp.Response.(*api.ExplainResponse), nil
 }
 
+// Query executes a SQL SELECT statement. The statement is translated to a read, so it has the same semantics as
+// Read including running in the transaction if one is passed.
+func (s *apiService) Query(r *api.QueryRequest, stream api.Tigris_QueryServer) error {
+	var err error
+	queryMetrics := metrics.StreamingQueryMetrics{}
+	accessToken, _ := request.GetAccessToken(stream.Context())
+
+	if api.GetTransaction(stream.Context()) != nil {
+		_, err = s.sessions.Execute(stream.Context(), s.runnerFactory.GetSQLQueryRunner(r, stream, &queryMetrics, accessToken), database.ReqOptions{
+			TxCtx:              api.GetTransaction(stream.Context()),
+			InstantVerTracking: true,
+		})
+	} else {
+		_, err = s.sessions.ReadOnlyExecute(stream.Context(), s.runnerFactory.GetSQLQueryRunner(r, stream, &queryMetrics, accessToken), database.ReqOptions{})
+	}
+	return err
+}
+
+func (s *apiService) ExplainQuery(ctx context.Context, r *api.QueryRequest) (*api.ExplainResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := s.sessions.Execute(ctx, s.runnerFactory.GetSQLExplainQueryRunner(r, accessToken), database.ReqOptions{
+		TxCtx:              api.GetTransaction(ctx),
+		InstantVerTracking: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.ExplainResponse), nil
+}
+
 func (s *apiService) Search(r *api.SearchRequest, stream api.Tigris_SearchServer) error {
 	queryMetrics := metrics.SearchQueryMetrics{}
 	accessToken, _ := request.GetAccessToken(stream.Context())
@@ -576,6 +615,32 @@ func (s *apiService) DescribeCollection(ctx context.Context, r *api.DescribeColl
 	return resp.Response.(*api.DescribeCollectionResponse), nil
 }
 
+func (s *apiService) VerifyCollectionSearch(ctx context.Context, r *api.VerifyCollectionSearchRequest) (*api.VerifyCollectionSearchResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	runner := s.runnerFactory.GetCollectionQueryRunner(accessToken)
+	runner.SetVerifyCollectionSearchReq(r)
+
+	resp, err := s.sessions.Execute(ctx, runner, database.ReqOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.VerifyCollectionSearchResponse), nil
+}
+
+func (s *apiService) GetCollectionSearchVerification(ctx context.Context, r *api.GetCollectionSearchVerificationRequest) (*api.GetCollectionSearchVerificationResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	runner := s.runnerFactory.GetCollectionQueryRunner(accessToken)
+	runner.SetGetCollectionSearchVerificationReq(r)
+
+	resp, err := s.sessions.Execute(ctx, runner, database.ReqOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.GetCollectionSearchVerificationResponse), nil
+}
+
 func (s *apiService) DescribeDatabase(ctx context.Context, r *api.DescribeDatabaseRequest) (*api.DescribeDatabaseResponse, error) {
 	accessToken, _ := request.GetAccessToken(ctx)
 	runner := s.runnerFactory.GetProjectQueryRunner(accessToken)
@@ -48,7 +48,7 @@ func newCacheService(tenantMgr *metadata.TenantManager, txMgr *transaction.Manag
 	return &cacheService{
 		UnimplementedCacheServer: api.UnimplementedCacheServer{},
 		sessions:                 cacheSessions,
-		runnerFactory:            cache.NewRunnerFactory(metadata.NewCacheEncoder(), cache2.NewCache(&config.DefaultConfig.Cache)),
+		runnerFactory:            cache.NewRunnerFactory(metadata.NewCacheEncoder(), cache2.NewLimitedCache(cache2.NewCache(&config.DefaultConfig.Cache), &config.DefaultConfig.Cache)),
 	}
 }
 
@@ -144,6 +144,277 @@ func (c *cacheService) Keys(req *api.KeysRequest, streaming api.Cache_KeysServer
 	return err
 }
 
+func (c *cacheService) HSet(ctx context.Context, req *api.HSetRequest) (*api.HSetResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetHSetRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.HSetResponse{
+		Status: resp.Status,
+		Count:  resp.Count,
+	}, nil
+}
+
+func (c *cacheService) HGet(ctx context.Context, req *api.HGetRequest) (*api.HGetResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetHGetRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.HGetResponse{
+		Value: resp.Data,
+	}, nil
+}
+
+func (c *cacheService) HGetAll(ctx context.Context, req *api.HGetAllRequest) (*api.HGetAllResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetHGetAllRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.HGetAllResponse{
+		Fields: resp.Fields,
+	}, nil
+}
+
+func (c *cacheService) HDel(ctx context.Context, req *api.HDelRequest) (*api.HDelResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetHDelRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.HDelResponse{
+		Status:       resp.Status,
+		DeletedCount: resp.DeletedCount,
+	}, nil
+}
+
+func (c *cacheService) LPush(ctx context.Context, req *api.PushRequest) (*api.PushResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetPushRunner(req, accessToken, true))
+	if err != nil {
+		return nil, err
+	}
+	return &api.PushResponse{
+		Length: resp.Count,
+	}, nil
+}
+
+func (c *cacheService) RPush(ctx context.Context, req *api.PushRequest) (*api.PushResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetPushRunner(req, accessToken, false))
+	if err != nil {
+		return nil, err
+	}
+	return &api.PushResponse{
+		Length: resp.Count,
+	}, nil
+}
+
+func (c *cacheService) LPop(ctx context.Context, req *api.PopRequest) (*api.PopResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetPopRunner(req, accessToken, true))
+	if err != nil {
+		return nil, err
+	}
+	return &api.PopResponse{
+		Values: resp.Values,
+	}, nil
+}
+
+func (c *cacheService) RPop(ctx context.Context, req *api.PopRequest) (*api.PopResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetPopRunner(req, accessToken, false))
+	if err != nil {
+		return nil, err
+	}
+	return &api.PopResponse{
+		Values: resp.Values,
+	}, nil
+}
+
+func (c *cacheService) LRange(ctx context.Context, req *api.LRangeRequest) (*api.LRangeResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetLRangeRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.LRangeResponse{
+		Values: resp.Values,
+	}, nil
+}
+
+func (c *cacheService) SAdd(ctx context.Context, req *api.SAddRequest) (*api.SAddResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetSAddRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.SAddResponse{
+		Status: resp.Status,
+		Count:  resp.Count,
+	}, nil
+}
+
+func (c *cacheService) SRem(ctx context.Context, req *api.SRemRequest) (*api.SRemResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetSRemRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.SRemResponse{
+		Status:       resp.Status,
+		DeletedCount: resp.DeletedCount,
+	}, nil
+}
+
+func (c *cacheService) SMembers(ctx context.Context, req *api.SMembersRequest) (*api.SMembersResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetSMembersRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.SMembersResponse{
+		Members: resp.Values,
+	}, nil
+}
+
+func (c *cacheService) SIsMember(ctx context.Context, req *api.SIsMemberRequest) (*api.SIsMemberResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetSIsMemberRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.SIsMemberResponse{
+		IsMember: resp.IsMember,
+	}, nil
+}
+
+func (c *cacheService) ZAdd(ctx context.Context, req *api.ZAddRequest) (*api.ZAddResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetZAddRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ZAddResponse{
+		Status: resp.Status,
+		Count:  resp.Count,
+	}, nil
+}
+
+func (c *cacheService) ZRem(ctx context.Context, req *api.ZRemRequest) (*api.ZRemResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetZRemRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ZRemResponse{
+		Status:       resp.Status,
+		DeletedCount: resp.DeletedCount,
+	}, nil
+}
+
+func (c *cacheService) ZRange(ctx context.Context, req *api.ZRangeRequest) (*api.ZRangeResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetZRangeRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ZRangeResponse{
+		Members: resp.Members,
+	}, nil
+}
+
+func (c *cacheService) ZRangeByScore(ctx context.Context, req *api.ZRangeByScoreRequest) (*api.ZRangeByScoreResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetZRangeByScoreRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ZRangeByScoreResponse{
+		Members: resp.Members,
+	}, nil
+}
+
+func (c *cacheService) Incr(ctx context.Context, req *api.IncrRequest) (*api.IncrResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetIncrRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.IncrResponse{
+		Value: resp.Value,
+	}, nil
+}
+
+func (c *cacheService) IncrBy(ctx context.Context, req *api.IncrByRequest) (*api.IncrByResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetIncrByRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.IncrByResponse{
+		Value: resp.Value,
+	}, nil
+}
+
+func (c *cacheService) Decr(ctx context.Context, req *api.DecrRequest) (*api.DecrResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetDecrRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.DecrResponse{
+		Value: resp.Value,
+	}, nil
+}
+
+func (c *cacheService) Expire(ctx context.Context, req *api.ExpireRequest) (*api.ExpireResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetExpireRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+	return &api.ExpireResponse{
+		Status:  resp.Status,
+		Message: "Expiry is set successfully",
+	}, nil
+}
+
+func (c *cacheService) Persist(ctx context.Context, req *api.PersistRequest) (*api.PersistResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetPersistRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	message := "Expiry is removed successfully"
+	if resp.DeletedCount == 0 {
+		message = "Key has no expiry"
+	}
+	return &api.PersistResponse{
+		Status:  resp.Status,
+		Message: message,
+	}, nil
+}
+
+func (c *cacheService) TTL(ctx context.Context, req *api.TTLRequest) (*api.TTLResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+	resp, err := c.sessions.Execute(ctx, c.runnerFactory.GetTTLRunner(req, accessToken))
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := int64(-1)
+	if resp.TTL != cache2.NoExpiry {
+		ttl = resp.TTL.Milliseconds()
+	}
+	return &api.TTLResponse{
+		Ttl: ttl,
+	}, nil
+}
+
 func (c *cacheService) RegisterHTTP(router chi.Router, inproc *inprocgrpc.Channel) error {
 	mux := runtime.NewServeMux(
 		runtime.WithMarshalerOption(runtime.MIMEWildcard, &api.CustomMarshaler{JSONBuiltin: &runtime.JSONBuiltin{}}),
@@ -0,0 +1,548 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/types"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+type HSetRunner struct {
+	*BaseRunner
+
+	req *api.HSetRequest
+}
+
+type HGetRunner struct {
+	*BaseRunner
+
+	req *api.HGetRequest
+}
+
+type HGetAllRunner struct {
+	*BaseRunner
+
+	req *api.HGetAllRequest
+}
+
+type HDelRunner struct {
+	*BaseRunner
+
+	req *api.HDelRequest
+}
+
+// PushRunner pushes the values at the head of the list when left is set, at the tail otherwise.
+type PushRunner struct {
+	*BaseRunner
+
+	req  *api.PushRequest
+	left bool
+}
+
+// PopRunner pops the values from the head of the list when left is set, from the tail otherwise.
+type PopRunner struct {
+	*BaseRunner
+
+	req  *api.PopRequest
+	left bool
+}
+
+type LRangeRunner struct {
+	*BaseRunner
+
+	req *api.LRangeRequest
+}
+
+type SAddRunner struct {
+	*BaseRunner
+
+	req *api.SAddRequest
+}
+
+type SRemRunner struct {
+	*BaseRunner
+
+	req *api.SRemRequest
+}
+
+type SMembersRunner struct {
+	*BaseRunner
+
+	req *api.SMembersRequest
+}
+
+type SIsMemberRunner struct {
+	*BaseRunner
+
+	req *api.SIsMemberRequest
+}
+
+type ZAddRunner struct {
+	*BaseRunner
+
+	req *api.ZAddRequest
+}
+
+type ZRemRunner struct {
+	*BaseRunner
+
+	req *api.ZRemRequest
+}
+
+type ZRangeRunner struct {
+	*BaseRunner
+
+	req *api.ZRangeRequest
+}
+
+type ZRangeByScoreRunner struct {
+	*BaseRunner
+
+	req *api.ZRangeByScoreRequest
+}
+
+func (f *RunnerFactory) GetHSetRunner(r *api.HSetRequest, accessToken *types.AccessToken) *HSetRunner {
+	return &HSetRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetHGetRunner(r *api.HGetRequest, accessToken *types.AccessToken) *HGetRunner {
+	return &HGetRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetHGetAllRunner(r *api.HGetAllRequest, accessToken *types.AccessToken) *HGetAllRunner {
+	return &HGetAllRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetHDelRunner(r *api.HDelRequest, accessToken *types.AccessToken) *HDelRunner {
+	return &HDelRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetPushRunner(r *api.PushRequest, accessToken *types.AccessToken, left bool) *PushRunner {
+	return &PushRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+		left:       left,
+	}
+}
+
+func (f *RunnerFactory) GetPopRunner(r *api.PopRequest, accessToken *types.AccessToken, left bool) *PopRunner {
+	return &PopRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+		left:       left,
+	}
+}
+
+func (f *RunnerFactory) GetLRangeRunner(r *api.LRangeRequest, accessToken *types.AccessToken) *LRangeRunner {
+	return &LRangeRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetSAddRunner(r *api.SAddRequest, accessToken *types.AccessToken) *SAddRunner {
+	return &SAddRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetSRemRunner(r *api.SRemRequest, accessToken *types.AccessToken) *SRemRunner {
+	return &SRemRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetSMembersRunner(r *api.SMembersRequest, accessToken *types.AccessToken) *SMembersRunner {
+	return &SMembersRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetSIsMemberRunner(r *api.SIsMemberRequest, accessToken *types.AccessToken) *SIsMemberRunner {
+	return &SIsMemberRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetZAddRunner(r *api.ZAddRequest, accessToken *types.AccessToken) *ZAddRunner {
+	return &ZAddRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetZRemRunner(r *api.ZRemRequest, accessToken *types.AccessToken) *ZRemRunner {
+	return &ZRemRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetZRangeRunner(r *api.ZRangeRequest, accessToken *types.AccessToken) *ZRangeRunner {
+	return &ZRangeRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetZRangeByScoreRunner(r *api.ZRangeByScoreRequest, accessToken *types.AccessToken) *ZRangeByScoreRunner {
+	return &ZRangeByScoreRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (runner *HSetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if len(runner.req.GetFields()) == 0 {
+		return Response{}, errors.InvalidArgument("at least one field is required")
+	}
+
+	count, err := runner.cacheStore.HSet(ctx, tableName, runner.req.GetKey(), runner.req.GetFields())
+	if err != nil {
+		return Response{}, invokeError("hset", err)
+	}
+
+	return Response{
+		Status: SetStatus,
+		Count:  count,
+	}, nil
+}
+
+func (runner *HGetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	value, err := runner.cacheStore.HGet(ctx, tableName, runner.req.GetKey(), runner.req.GetField())
+	if err != nil {
+		if err == cache.ErrKeyNotFound {
+			return Response{}, errors.NotFound(err.Error())
+		}
+		return Response{}, invokeError("hget", err)
+	}
+
+	return Response{
+		Data: value,
+	}, nil
+}
+
+func (runner *HGetAllRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	fields, err := runner.cacheStore.HGetAll(ctx, tableName, runner.req.GetKey())
+	if err != nil {
+		return Response{}, invokeError("hgetall", err)
+	}
+
+	return Response{
+		Fields: fields,
+	}, nil
+}
+
+func (runner *HDelRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	deletedCount, err := runner.cacheStore.HDel(ctx, tableName, runner.req.GetKey(), runner.req.GetFields()...)
+	if err != nil {
+		return Response{}, invokeError("hdel", err)
+	}
+
+	return Response{
+		Status:       DeletedStatus,
+		DeletedCount: deletedCount,
+	}, nil
+}
+
+func (runner *PushRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if len(runner.req.GetValues()) == 0 {
+		return Response{}, errors.InvalidArgument("at least one value is required")
+	}
+
+	var length int64
+	if runner.left {
+		length, err = runner.cacheStore.LPush(ctx, tableName, runner.req.GetKey(), runner.req.GetValues()...)
+	} else {
+		length, err = runner.cacheStore.RPush(ctx, tableName, runner.req.GetKey(), runner.req.GetValues()...)
+	}
+	if err != nil {
+		return Response{}, invokeError("push", err)
+	}
+
+	return Response{
+		Count: length,
+	}, nil
+}
+
+func (runner *PopRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	count := int(runner.req.GetCount())
+	if count < 0 {
+		return Response{}, errors.InvalidArgument("count can't be negative")
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	var values [][]byte
+	if runner.left {
+		values, err = runner.cacheStore.LPop(ctx, tableName, runner.req.GetKey(), count)
+	} else {
+		values, err = runner.cacheStore.RPop(ctx, tableName, runner.req.GetKey(), count)
+	}
+	if err != nil {
+		return Response{}, invokeError("pop", err)
+	}
+
+	return Response{
+		Values: values,
+	}, nil
+}
+
+func (runner *LRangeRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	values, err := runner.cacheStore.LRange(ctx, tableName, runner.req.GetKey(), runner.req.GetStart(), runner.req.GetStop())
+	if err != nil {
+		return Response{}, invokeError("lrange", err)
+	}
+
+	return Response{
+		Values: values,
+	}, nil
+}
+
+func (runner *SAddRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if len(runner.req.GetMembers()) == 0 {
+		return Response{}, errors.InvalidArgument("at least one member is required")
+	}
+
+	count, err := runner.cacheStore.SAdd(ctx, tableName, runner.req.GetKey(), runner.req.GetMembers()...)
+	if err != nil {
+		return Response{}, invokeError("sadd", err)
+	}
+
+	return Response{
+		Status: SetStatus,
+		Count:  count,
+	}, nil
+}
+
+func (runner *SRemRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	deletedCount, err := runner.cacheStore.SRem(ctx, tableName, runner.req.GetKey(), runner.req.GetMembers()...)
+	if err != nil {
+		return Response{}, invokeError("srem", err)
+	}
+
+	return Response{
+		Status:       DeletedStatus,
+		DeletedCount: deletedCount,
+	}, nil
+}
+
+func (runner *SMembersRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	members, err := runner.cacheStore.SMembers(ctx, tableName, runner.req.GetKey())
+	if err != nil {
+		return Response{}, invokeError("smembers", err)
+	}
+
+	return Response{
+		Values: members,
+	}, nil
+}
+
+func (runner *SIsMemberRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	isMember, err := runner.cacheStore.SIsMember(ctx, tableName, runner.req.GetKey(), runner.req.GetMember())
+	if err != nil {
+		return Response{}, invokeError("sismember", err)
+	}
+
+	return Response{
+		IsMember: isMember,
+	}, nil
+}
+
+func (runner *ZAddRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if len(runner.req.GetMembers()) == 0 {
+		return Response{}, errors.InvalidArgument("at least one member is required")
+	}
+
+	members := make([]cache.ZMember, len(runner.req.GetMembers()))
+	for i, m := range runner.req.GetMembers() {
+		members[i] = cache.ZMember{Score: m.GetScore(), Member: m.GetMember()}
+	}
+
+	count, err := runner.cacheStore.ZAdd(ctx, tableName, runner.req.GetKey(), members...)
+	if err != nil {
+		return Response{}, invokeError("zadd", err)
+	}
+
+	return Response{
+		Status: SetStatus,
+		Count:  count,
+	}, nil
+}
+
+func (runner *ZRemRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	deletedCount, err := runner.cacheStore.ZRem(ctx, tableName, runner.req.GetKey(), runner.req.GetMembers()...)
+	if err != nil {
+		return Response{}, invokeError("zrem", err)
+	}
+
+	return Response{
+		Status:       DeletedStatus,
+		DeletedCount: deletedCount,
+	}, nil
+}
+
+func (runner *ZRangeRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	members, err := runner.cacheStore.ZRange(ctx, tableName, runner.req.GetKey(), runner.req.GetStart(), runner.req.GetStop(), runner.req.GetRev())
+	if err != nil {
+		return Response{}, invokeError("zrange", err)
+	}
+
+	return Response{
+		Members: toAPIZMembers(members),
+	}, nil
+}
+
+func (runner *ZRangeByScoreRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if runner.req.GetOffset() < 0 || runner.req.GetCount() < 0 {
+		return Response{}, errors.InvalidArgument("offset and count can't be negative")
+	}
+
+	min, max := runner.req.GetMin(), runner.req.GetMax()
+	if min == "" {
+		min = "-inf"
+	}
+	if max == "" {
+		max = "+inf"
+	}
+
+	members, err := runner.cacheStore.ZRangeByScore(ctx, tableName, runner.req.GetKey(), min, max, runner.req.GetOffset(), runner.req.GetCount())
+	if err != nil {
+		return Response{}, invokeError("zrangebyscore", err)
+	}
+
+	return Response{
+		Members: toAPIZMembers(members),
+	}, nil
+}
+
+func toAPIZMembers(members []cache.ZMember) []*api.ZMember {
+	converted := make([]*api.ZMember, len(members))
+	for i, m := range members {
+		converted[i] = &api.ZMember{Member: m.Member, Score: m.Score}
+	}
+
+	return converted
+}
+
+// invokeError returns the error of a failed operation, an operation on a key holding a different kind of value is
+// rejected as an invalid argument.
+func invokeError(op string, err error) error {
+	if cache.IsWrongType(err) {
+		return errors.InvalidArgument("Failed to invoke %s, reason %s", op, cache.ErrWrongType.Error())
+	}
+	if err == cache.ErrLimitExceeded {
+		return errors.ResourceExhausted("Failed to invoke %s, reason %s", op, err.Error())
+	}
+
+	return errors.Internal("Failed to invoke %s, reason %s", op, err.Error())
+}
@@ -14,7 +14,11 @@
 
 package cache
 
-import api "github.com/tigrisdata/tigris/api/server/v1"
+import (
+	"time"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+)
 
 const (
 	SetStatus     string = "set"
@@ -32,6 +36,13 @@ type Response struct {
 	DeletedCount int64
 	Caches       []*api.CacheMetadata
 	Cursor       uint64
+	Count        int64
+	Values       [][]byte
+	Fields       map[string][]byte
+	IsMember     bool
+	Members      []*api.ZMember
+	Value        int64
+	TTL          time.Duration
 }
 
 // StreamingKeys is a wrapper interface for passing around for streaming cache keys.
@@ -16,6 +16,7 @@ package cache
 
 import (
 	"context"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	api "github.com/tigrisdata/tigris/api/server/v1"
@@ -23,6 +24,7 @@ import (
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/metrics"
 	"github.com/tigrisdata/tigris/server/request"
 	"github.com/tigrisdata/tigris/server/services/v1/database"
 	"github.com/tigrisdata/tigris/server/transaction"
@@ -43,11 +45,11 @@ type TxRunner interface {
 
 type BaseRunner struct {
 	encoder     metadata.CacheEncoder
-	cacheStore  cache.Cache
+	cacheStore  *cache.LimitedCache
 	accessToken *types.AccessToken
 }
 
-func NewBaseRunner(encoder metadata.CacheEncoder, accessToken *types.AccessToken, cacheStore cache.Cache) *BaseRunner {
+func NewBaseRunner(encoder metadata.CacheEncoder, accessToken *types.AccessToken, cacheStore *cache.LimitedCache) *BaseRunner {
 	return &BaseRunner{
 		encoder:     encoder,
 		accessToken: accessToken,
@@ -103,13 +105,38 @@ type KeysRunner struct {
 	streaming StreamingKeys
 }
 
+// IncrByRunner is used for Incr and Decr too, with an increment of 1 and -1.
+type IncrByRunner struct {
+	*BaseRunner
+
+	req *api.IncrByRequest
+}
+
+type ExpireRunner struct {
+	*BaseRunner
+
+	req *api.ExpireRequest
+}
+
+type PersistRunner struct {
+	*BaseRunner
+
+	req *api.PersistRequest
+}
+
+type TTLRunner struct {
+	*BaseRunner
+
+	req *api.TTLRequest
+}
+
 type RunnerFactory struct {
 	encoder    metadata.CacheEncoder
-	cacheStore cache.Cache
+	cacheStore *cache.LimitedCache
 }
 
 // NewRunnerFactory returns RunnerFactory object.
-func NewRunnerFactory(encoder metadata.CacheEncoder, cacheStore cache.Cache) *RunnerFactory {
+func NewRunnerFactory(encoder metadata.CacheEncoder, cacheStore *cache.LimitedCache) *RunnerFactory {
 	return &RunnerFactory{
 		encoder:    encoder,
 		cacheStore: cacheStore,
@@ -173,16 +200,76 @@ func (f *RunnerFactory) GetKeysRunner(r *api.KeysRequest, accessToken *types.Acc
 	}
 }
 
+func (f *RunnerFactory) GetIncrRunner(r *api.IncrRequest, accessToken *types.AccessToken) *IncrByRunner {
+	return f.GetIncrByRunner(&api.IncrByRequest{
+		Project:   r.GetProject(),
+		Name:      r.GetName(),
+		Key:       r.GetKey(),
+		Increment: 1,
+	}, accessToken)
+}
+
+func (f *RunnerFactory) GetDecrRunner(r *api.DecrRequest, accessToken *types.AccessToken) *IncrByRunner {
+	return f.GetIncrByRunner(&api.IncrByRequest{
+		Project:   r.GetProject(),
+		Name:      r.GetName(),
+		Key:       r.GetKey(),
+		Increment: -1,
+	}, accessToken)
+}
+
+func (f *RunnerFactory) GetIncrByRunner(r *api.IncrByRequest, accessToken *types.AccessToken) *IncrByRunner {
+	return &IncrByRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetExpireRunner(r *api.ExpireRequest, accessToken *types.AccessToken) *ExpireRunner {
+	return &ExpireRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetPersistRunner(r *api.PersistRequest, accessToken *types.AccessToken) *PersistRunner {
+	return &PersistRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
+func (f *RunnerFactory) GetTTLRunner(r *api.TTLRequest, accessToken *types.AccessToken) *TTLRunner {
+	return &TTLRunner{
+		BaseRunner: NewBaseRunner(f.encoder, accessToken, f.cacheStore),
+		req:        r,
+	}
+}
+
 func (runner *CreateCacheRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
 	currentSub, err := request.GetCurrentSub(ctx)
 	if err != nil && config.DefaultConfig.Auth.Enabled {
 		return Response{}, ctx, errors.Internal("Failed to get current sub for the request")
 	}
 
-	_, err = tenant.CreateCache(ctx, tx, runner.req.GetProject(), runner.req.GetName(), currentSub)
+	limits, err := toCacheLimits(runner.req.GetOptions())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	_, err = tenant.CreateCache(ctx, tx, runner.req.GetProject(), runner.req.GetName(), currentSub, limits)
 	if err != nil {
 		return Response{}, ctx, createApiError(err)
 	}
+
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+	if err = runner.cacheStore.SetLimits(ctx, tableName, limits); err != nil {
+		return Response{}, ctx, errors.Internal("Failed to set the cache limits, reason %s", err.Error())
+	}
+
 	return Response{
 		Status: database.CreatedStatus,
 	}, ctx, nil
@@ -206,6 +293,9 @@ func (runner *DeleteCacheRunner) Run(ctx context.Context, tx transaction.Tx, ten
 			log.Warn().Str("cacheTableName", tableName).Str("cacheKey", userKey).Msg("Failed to delete cache key")
 		}
 	}
+	if err = runner.cacheStore.DropLimits(ctx, tableName); err != nil {
+		log.Warn().Str("cacheTableName", tableName).Msg("Failed to delete cache limits")
+	}
 
 	_, err = tenant.DeleteCache(ctx, tx, runner.req.GetProject(), runner.req.GetName())
 	if err != nil {
@@ -226,11 +316,47 @@ func (runner *ListCachesRunner) Run(ctx context.Context, tx transaction.Tx, tena
 	if err != nil {
 		return Response{}, ctx, err
 	}
+	namespace := tenant.GetNamespace()
 	cachesMetadata := make([]*api.CacheMetadata, len(caches))
-	for i, cache := range caches {
+	for i := range caches {
 		cachesMetadata[i] = &api.CacheMetadata{
-			Name: cache,
+			Name:    caches[i].Name,
+			Options: &api.CreateCacheOptions{},
+		}
+
+		// the unbounded caches without default TTL have no limits and no usage
+		limits := caches[i].Limits
+		if limits == nil {
+			continue
+		}
+
+		cachesMetadata[i].Options = &api.CreateCacheOptions{
+			TtlMs:          uint64(limits.DefaultTTL.Milliseconds()),
+			MaxKeys:        limits.MaxKeys,
+			MaxBytes:       limits.MaxBytes,
+			EvictionPolicy: limits.EvictionPolicy,
 		}
+
+		tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), caches[i].Name, runner.encoder)
+		if err != nil {
+			return Response{}, ctx, err
+		}
+		usage, err := runner.cacheStore.Usage(ctx, tableName)
+		if err != nil {
+			return Response{}, ctx, errors.Internal("Failed to get the cache usage, reason %s", err.Error())
+		}
+		if usage == nil {
+			continue
+		}
+
+		cachesMetadata[i].Stats = &api.CacheStats{
+			Keys:           usage.Keys,
+			Bytes:          usage.Bytes,
+			Evictions:      usage.Evictions,
+			RejectedWrites: usage.RejectedWrites,
+		}
+		metrics.UpdateCacheUsageMetrics(namespace.StrId(), namespace.Metadata().Name, runner.req.GetProject(), caches[i].Name,
+			usage.Keys, usage.Bytes, usage.Evictions, usage.RejectedWrites)
 	}
 	return Response{
 		Caches: cachesMetadata,
@@ -251,7 +377,7 @@ func (runner *SetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Resp
 	}
 
 	if err = runner.cacheStore.Set(ctx, tableName, runner.req.GetKey(), internal.NewCacheData(runner.req.GetValue()), options); err != nil {
-		return Response{}, errors.Internal("Failed to invoke set, reason %s", err.Error())
+		return Response{}, invokeError("set", err)
 	}
 
 	return Response{
@@ -267,7 +393,7 @@ func (runner *GetSetRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 
 	oldVal, err := runner.cacheStore.GetSet(ctx, tableName, runner.req.GetKey(), internal.NewCacheData(runner.req.GetValue()))
 	if err != nil {
-		return Response{}, errors.Internal("Failed to invoke set, reason %s", err.Error())
+		return Response{}, invokeError("set", err)
 	}
 
 	result := Response{
@@ -354,6 +480,127 @@ func (runner *KeysRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Res
 	return Response{}, nil
 }
 
+func (runner *IncrByRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	value, err := runner.cacheStore.IncrBy(ctx, tableName, runner.req.GetKey(), runner.req.GetIncrement())
+	if err != nil {
+		switch {
+		case err == cache.ErrNotInteger:
+			return Response{}, errors.InvalidArgument(err.Error())
+		}
+		return Response{}, invokeError("incrby", err)
+	}
+
+	return Response{
+		Value: value,
+	}, nil
+}
+
+func (runner *ExpireRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var ttl time.Duration
+	switch {
+	case runner.req.GetEx() > 0:
+		ttl = time.Duration(runner.req.GetEx()) * time.Second
+	case runner.req.GetPx() > 0:
+		ttl = time.Duration(runner.req.GetPx()) * time.Millisecond
+	default:
+		return Response{}, errors.InvalidArgument("expiry is required, set either ex or px")
+	}
+
+	exists, err := runner.cacheStore.Expire(ctx, tableName, runner.req.GetKey(), ttl)
+	if err != nil {
+		return Response{}, errors.Internal("Failed to invoke expire, reason %s", err.Error())
+	}
+	if !exists {
+		return Response{}, errors.NotFound(cache.ErrKeyNotFound.Error())
+	}
+
+	return Response{
+		Status: SetStatus,
+	}, nil
+}
+
+func (runner *PersistRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	removed, err := runner.cacheStore.Persist(ctx, tableName, runner.req.GetKey())
+	if err != nil {
+		if err == cache.ErrKeyNotFound {
+			return Response{}, errors.NotFound(err.Error())
+		}
+		return Response{}, errors.Internal("Failed to invoke persist, reason %s", err.Error())
+	}
+
+	result := Response{
+		Status: DeletedStatus,
+	}
+	if removed {
+		result.DeletedCount = 1
+	}
+	return result, nil
+}
+
+func (runner *TTLRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tableName, err := getEncodedCacheTableName(ctx, tenant, runner.req.GetProject(), runner.req.GetName(), runner.encoder)
+	if err != nil {
+		return Response{}, err
+	}
+
+	ttl, err := runner.cacheStore.TTL(ctx, tableName, runner.req.GetKey())
+	if err != nil {
+		if err == cache.ErrKeyNotFound {
+			return Response{}, errors.NotFound(err.Error())
+		}
+		return Response{}, errors.Internal("Failed to invoke ttl, reason %s", err.Error())
+	}
+
+	return Response{
+		TTL: ttl,
+	}, nil
+}
+
+// toCacheLimits returns the limits of a new cache. The eviction policy defaults to LRU when the cache is bounded. The
+// limits are nil if the cache is unbounded and has no default TTL, so that its keys aren't tracked.
+func toCacheLimits(options *api.CreateCacheOptions) (*cache.Limits, error) {
+	if options.GetMaxKeys() < 0 || options.GetMaxBytes() < 0 {
+		return nil, errors.InvalidArgument("max keys and max bytes of a cache can't be negative")
+	}
+
+	policy := options.GetEvictionPolicy()
+	switch policy {
+	case "":
+		if options.GetMaxKeys() > 0 || options.GetMaxBytes() > 0 {
+			policy = cache.EvictLRU
+		}
+	case cache.EvictLRU, cache.EvictLFU, cache.EvictTTL, cache.EvictNone:
+	default:
+		return nil, errors.InvalidArgument("unsupported eviction policy '%s'", policy)
+	}
+
+	if options.GetMaxKeys() == 0 && options.GetMaxBytes() == 0 && options.GetTtlMs() == 0 {
+		return nil, nil
+	}
+
+	return &cache.Limits{
+		MaxKeys:        options.GetMaxKeys(),
+		MaxBytes:       options.GetMaxBytes(),
+		EvictionPolicy: policy,
+		DefaultTTL:     time.Duration(options.GetTtlMs()) * time.Millisecond,
+	}, nil
+}
+
 func getEncodedCacheTableName(_ context.Context, tenant *metadata.Tenant, projectName string, cacheName string, encoder metadata.CacheEncoder) (string, error) {
 	project, err := tenant.GetProject(projectName)
 	if err != nil {
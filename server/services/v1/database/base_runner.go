@@ -256,6 +256,12 @@ func (*BaseQueryRunner) getSearchOrdering(coll *schema.DefaultCollection, sortRe
 		if !cf.Sortable {
 			return nil, errors.InvalidArgument("Search results can't be sorted on `%s` field. Enable sorting on this field", sf.Name)
 		}
+		if sf.Origin != nil && cf.DataType != schema.GeoPointType {
+			return nil, errors.InvalidArgument("`%s` sort is only supported on geopoint fields, `%s` is not a geopoint", sort.NEAR, sf.Name)
+		}
+		if sf.Origin == nil && cf.DataType == schema.GeoPointType {
+			return nil, errors.InvalidArgument("geopoint field `%s` can only be sorted by the distance using `%s`", sf.Name, sort.NEAR)
+		}
 	}
 	return ordering, nil
 }
@@ -0,0 +1,232 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/services/v1/realtime"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+// channelBridgesTTL is how long the channel bridges of a project are cached, the changes made through the realtime
+// API are applied after it.
+const channelBridgesTTL = 10 * time.Second
+
+const (
+	// channelBridgePublishTimeout bounds the publishing of the changes of a transaction.
+	channelBridgePublishTimeout = 5 * time.Second
+	// channelBridgeQueueLen is the number of committed transactions waiting to be published, the changes of the
+	// transactions committed while the queue is full are dropped.
+	channelBridgeQueueLen = 1024
+)
+
+type bridgeChanges struct {
+	tenant *metadata.Tenant
+	events []*kv.Event
+}
+
+type cachedBridges struct {
+	bridges  []metadata.ChannelBridge
+	loadedAt time.Time
+}
+
+// ChannelBridgeListener publishes the committed inserts, updates and deletes of the collections bridged to a realtime
+// channel, see metadata.ChannelBridge. The changes are published in the order of the commits, off the request by a
+// single goroutine. Publishing is best effort, a failure is logged and doesn't fail the transaction which is already
+// committed.
+type ChannelBridgeListener struct {
+	sync.RWMutex
+
+	tenantMgr *metadata.TenantManager
+	txMgr     *transaction.Manager
+	factory   *realtime.ChannelFactory
+	bridges   map[string]*cachedBridges
+	queue     chan *bridgeChanges
+}
+
+func NewChannelBridgeListener(tenantMgr *metadata.TenantManager, txMgr *transaction.Manager, factory *realtime.ChannelFactory) *ChannelBridgeListener {
+	l := &ChannelBridgeListener{
+		tenantMgr: tenantMgr,
+		txMgr:     txMgr,
+		factory:   factory,
+		bridges:   make(map[string]*cachedBridges),
+		queue:     make(chan *bridgeChanges, channelBridgeQueueLen),
+	}
+
+	go l.run()
+	return l
+}
+
+func (*ChannelBridgeListener) OnPreCommit(context.Context, *metadata.Tenant, transaction.Tx, kv.EventListener) error {
+	return nil
+}
+
+func (l *ChannelBridgeListener) OnPostCommit(_ context.Context, tenant *metadata.Tenant, eventListener kv.EventListener) error {
+	if tenant == nil || len(eventListener.GetEvents()) == 0 {
+		return nil
+	}
+
+	changes := &bridgeChanges{
+		tenant: tenant,
+		events: append([]*kv.Event(nil), eventListener.GetEvents()...),
+	}
+	select {
+	case l.queue <- changes:
+	default:
+		log.Warn().Str("namespace", tenant.GetNamespace().StrId()).Msg("channel bridges are behind, dropping the changes")
+	}
+
+	return nil
+}
+
+func (l *ChannelBridgeListener) run() {
+	for changes := range l.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), channelBridgePublishTimeout)
+		l.publishChanges(ctx, changes.tenant, changes.events)
+		cancel()
+	}
+}
+
+func (l *ChannelBridgeListener) publishChanges(ctx context.Context, tenant *metadata.Tenant, events []*kv.Event) {
+	for _, event := range events {
+		if event.Key == nil {
+			// event.Key == nil if event comes from drop table
+			continue
+		}
+
+		db, collName, ok := l.tenantMgr.DecodeTableName(event.Table)
+		if !ok || db.IsBranch() {
+			continue
+		}
+
+		project, err := tenant.GetProject(db.DbName())
+		if err != nil {
+			continue
+		}
+
+		bridges, err := l.getBridges(ctx, tenant, project)
+		if err != nil {
+			log.Err(err).Str("project", project.Name()).Msg("loading channel bridges failed")
+			continue
+		}
+
+		for i := range bridges {
+			if bridges[i].Collection != collName {
+				continue
+			}
+
+			if err = l.publish(ctx, tenant, project, db.GetCollection(collName), &bridges[i], event); err != nil {
+				log.Err(err).Str("collection", collName).Str("channel", bridges[i].Channel).Msg("publishing change failed")
+			}
+		}
+	}
+}
+
+func (*ChannelBridgeListener) OnRollback(context.Context, *metadata.Tenant, kv.EventListener) {}
+
+func (l *ChannelBridgeListener) publish(ctx context.Context, tenant *metadata.Tenant, project *metadata.Project, coll *schema.DefaultCollection, bridge *metadata.ChannelBridge, event *kv.Event) error {
+	if coll == nil {
+		return nil
+	}
+
+	eventName := changeEventName(event.Op)
+	var doc []byte
+	if event.Op != kv.DeleteEvent && event.Data != nil {
+		f, err := realtime.NewBridgeFilter(coll, bridge.Filter)
+		if err != nil {
+			return err
+		}
+
+		tsJSON, err := event.Data.TimeStampsToJSON()
+		if err != nil {
+			return err
+		}
+		switch {
+		case f.Matches(event.Data.RawData, tsJSON):
+			doc = event.Data.RawData
+		case event.Op == kv.InsertEvent:
+			return nil
+		default:
+			// the previous version of the document isn't known, it may have matched the filter
+			eventName = realtime.ChangeEventLeave
+		}
+	}
+
+	id, err := CreateSearchKey(event.Key)
+	if err != nil {
+		return err
+	}
+
+	data, err := realtime.NewChangeEventData(eventName, coll.Name, id, doc)
+	if err != nil {
+		return err
+	}
+
+	channel, err := l.factory.GetOrCreateChannel(ctx, tenant.GetNamespace().Id(), project.Id(), bridge.Channel)
+	if err != nil {
+		return err
+	}
+
+	_, err = channel.PublishMessage(ctx, data)
+	return err
+}
+
+func (l *ChannelBridgeListener) getBridges(ctx context.Context, tenant *metadata.Tenant, project *metadata.Project) ([]metadata.ChannelBridge, error) {
+	key := fmt.Sprintf("%d:%d", tenant.GetNamespace().Id(), project.Id())
+
+	l.RLock()
+	cached, ok := l.bridges[key]
+	l.RUnlock()
+	if ok && time.Since(cached.loadedAt) < channelBridgesTTL {
+		return cached.bridges, nil
+	}
+
+	tx, err := l.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	bridges, err := tenant.ListChannelBridges(ctx, tx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Lock()
+	l.bridges[key] = &cachedBridges{bridges: bridges, loadedAt: time.Now()}
+	l.Unlock()
+
+	return bridges, nil
+}
+
+// changeEventName returns the name of the message published for the change, a replace is published as an update.
+func changeEventName(op string) string {
+	switch op {
+	case kv.InsertEvent:
+		return realtime.ChangeEventInsert
+	case kv.DeleteEvent:
+		return realtime.ChangeEventDelete
+	default:
+		return realtime.ChangeEventUpdate
+	}
+}
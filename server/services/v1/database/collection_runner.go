@@ -16,6 +16,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
@@ -26,6 +27,7 @@ import (
 	"github.com/tigrisdata/tigris/server/request"
 	"github.com/tigrisdata/tigris/server/transaction"
 	"github.com/tigrisdata/tigris/store/kv"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type CollectionQueryRunner struct {
@@ -35,6 +37,8 @@ type CollectionQueryRunner struct {
 	listReq           *api.ListCollectionsRequest
 	createOrUpdateReq *api.CreateOrUpdateCollectionRequest
 	describeReq       *api.DescribeCollectionRequest
+	verifySearchReq   *api.VerifyCollectionSearchRequest
+	verificationReq   *api.GetCollectionSearchVerificationRequest
 }
 
 func (runner *CollectionQueryRunner) SetCreateOrUpdateCollectionReq(create *api.CreateOrUpdateCollectionRequest) {
@@ -53,6 +57,14 @@ func (runner *CollectionQueryRunner) SetDescribeCollectionReq(describe *api.Desc
 	runner.describeReq = describe
 }
 
+func (runner *CollectionQueryRunner) SetVerifyCollectionSearchReq(verify *api.VerifyCollectionSearchRequest) {
+	runner.verifySearchReq = verify
+}
+
+func (runner *CollectionQueryRunner) SetGetCollectionSearchVerificationReq(get *api.GetCollectionSearchVerificationRequest) {
+	runner.verificationReq = get
+}
+
 func (runner *CollectionQueryRunner) drop(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
 	db, err := runner.getDatabase(ctx, tx, tenant, runner.dropReq.GetProject(), runner.dropReq.GetBranch())
 	if err != nil {
@@ -266,6 +278,65 @@ func (runner *CollectionQueryRunner) describe(ctx context.Context, tx transactio
 	}, ctx, nil
 }
 
+// verifySearch schedules the verification of the search index of the collection against its primary rows, it
+// replaces the current schedule of the collection.
+func (runner *CollectionQueryRunner) verifySearch(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	req := runner.verifySearchReq
+	db, coll, err := runner.getDBAndCollection(ctx, tx, tenant, req.GetProject(), req.GetCollection(), req.GetBranch())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	if coll.GetSearchState() == schema.NoSearchIndex {
+		return Response{}, ctx, errors.InvalidArgument("collection '%s' has no search index", coll.Name)
+	}
+
+	interval := time.Duration(req.GetIntervalSeconds()) * time.Second
+	if err = tenant.ScheduleSearchVerification(ctx, tx, db, coll, req.GetRepair(), interval); err != nil {
+		return Response{}, ctx, err
+	}
+
+	return Response{
+		Response: &api.VerifyCollectionSearchResponse{
+			Status: ScheduledStatus,
+		},
+	}, ctx, nil
+}
+
+func (runner *CollectionQueryRunner) getSearchVerification(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	req := runner.verificationReq
+	db, coll, err := runner.getDBAndCollection(ctx, tx, tenant, req.GetProject(), req.GetCollection(), req.GetBranch())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	verification, err := tenant.GetSearchVerification(ctx, tx, db, coll)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	resp := &api.GetCollectionSearchVerificationResponse{}
+	if verification != nil {
+		resp.Scheduled = len(verification.TaskId) > 0
+		resp.Repair = verification.Repair
+		resp.IntervalSeconds = int64(verification.Interval / time.Second)
+		if r := verification.Report; r != nil {
+			resp.Report = &api.SearchDriftReport{
+				StartedAt:   timestamppb.New(r.StartedAt),
+				CompletedAt: timestamppb.New(r.CompletedAt),
+				Scanned:     r.Scanned,
+				Missing:     r.Missing,
+				Stale:       r.Stale,
+				Orphaned:    r.Orphaned,
+				Repaired:    r.Repaired,
+				DriftedIds:  r.DriftedIds,
+			}
+		}
+	}
+
+	return Response{Response: resp}, ctx, nil
+}
+
 func (runner *CollectionQueryRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
 	switch {
 	case runner.dropReq != nil:
@@ -276,6 +347,10 @@ func (runner *CollectionQueryRunner) Run(ctx context.Context, tx transaction.Tx,
 		return runner.list(ctx, tx, tenant)
 	case runner.describeReq != nil:
 		return runner.describe(ctx, tx, tenant)
+	case runner.verifySearchReq != nil:
+		return runner.verifySearch(ctx, tx, tenant)
+	case runner.verificationReq != nil:
+		return runner.getSearchVerification(ctx, tx, tenant)
 	}
 
 	return Response{}, ctx, errors.Unknown("unknown request path")
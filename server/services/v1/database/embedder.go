@@ -0,0 +1,409 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/apple/foundationdb/bindings/go/src/fdb/tuple"
+	"github.com/buger/jsonparser"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/lib/container"
+	"github.com/tigrisdata/tigris/lib/embedding"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+// embedBatchSize is the number of documents that are sent to the embedding provider at once.
+const embedBatchSize = 64
+
+// EmbeddingListener queues an embedding task for the documents written in the transaction to collections having
+// embedding fields. The task is queued as part of the user transaction so that it is not lost, the vectors are then
+// computed asynchronously by the workers.
+type EmbeddingListener struct {
+	tenantMgr *metadata.TenantManager
+}
+
+func NewEmbeddingListener(tenantMgr *metadata.TenantManager) *EmbeddingListener {
+	return &EmbeddingListener{
+		tenantMgr: tenantMgr,
+	}
+}
+
+func (l *EmbeddingListener) OnPreCommit(ctx context.Context, tenant *metadata.Tenant, tx transaction.Tx, eventListener kv.EventListener) error {
+	tasks := make(map[string]*metadata.EmbeddingTask)
+	var order []string
+
+	for _, event := range eventListener.GetEvents() {
+		if event.Op == kv.DeleteEvent || event.Key == nil || event.Data == nil {
+			continue
+		}
+
+		db, collName, ok := l.tenantMgr.DecodeTableName(event.Table)
+		if !ok {
+			continue
+		}
+
+		coll := db.GetCollection(collName)
+		if coll == nil || !hasEmbeddingSource(coll, event.Data.RawData) {
+			continue
+		}
+
+		task, ok := tasks[string(event.Table)]
+		if !ok {
+			task = &metadata.EmbeddingTask{
+				NamespaceId: tenant.GetNamespace().StrId(),
+				ProjName:    db.DbName(),
+				Branch:      db.BranchName(),
+				CollName:    collName,
+			}
+			tasks[string(event.Table)] = task
+			order = append(order, string(event.Table))
+		}
+		task.Keys = append(task.Keys, PackEmbeddingKey(event.Key))
+	}
+
+	queue := l.tenantMgr.GetQueue()
+	for _, table := range order {
+		queueData, err := jsoniter.Marshal(tasks[table])
+		if err != nil {
+			return err
+		}
+
+		if err = queue.Enqueue(ctx, tx, metadata.NewQueueItem(0, queueData, metadata.EMBEDDING_TASK), 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (*EmbeddingListener) OnPostCommit(context.Context, *metadata.Tenant, kv.EventListener) error {
+	return nil
+}
+
+func (*EmbeddingListener) OnRollback(context.Context, *metadata.Tenant, kv.EventListener) {}
+
+func hasEmbeddingSource(coll *schema.DefaultCollection, doc []byte) bool {
+	for _, f := range coll.EmbeddingFields() {
+		if _, ok := embeddingSource(doc, f); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// embeddingSource returns the text to embed for the field, it is false if the document doesn't have it.
+func embeddingSource(doc []byte, f *schema.Field) (string, bool) {
+	text, err := jsonparser.GetString(doc, strings.Split(f.Embedding.Source, schema.ObjFlattenDelimiter)...)
+	if err != nil {
+		return "", false
+	}
+
+	return text, true
+}
+
+// PackEmbeddingKey packs the parts of a primary key so that it can be stored in an embedding task.
+func PackEmbeddingKey(key kv.Key) []byte {
+	tp := make(tuple.Tuple, 0, len(key))
+	for _, k := range key {
+		tp = append(tp, k)
+	}
+
+	return tp.Pack()
+}
+
+// Embedder computes the vectors of the embedding fields of a collection and writes them to the documents.
+type Embedder struct {
+	tenant  *metadata.Tenant
+	coll    *schema.DefaultCollection
+	fields  []*schema.Field
+	txMgr   *transaction.Manager
+	indexer *SearchIndexer
+}
+
+// NewEmbedder returns an embedder for the fields of the collection, or for all its embedding fields if no field is
+// passed. The updated documents are indexed in search using the indexer if it is not nil.
+func NewEmbedder(tenant *metadata.Tenant, coll *schema.DefaultCollection, fields []string, txMgr *transaction.Manager, indexer *SearchIndexer) *Embedder {
+	embeddingFields := coll.EmbeddingFields()
+	if len(fields) > 0 {
+		names := container.NewHashSet(fields...)
+		filtered := embeddingFields[:0:0]
+		for _, f := range embeddingFields {
+			if names.Contains(f.FieldName) {
+				filtered = append(filtered, f)
+			}
+		}
+		embeddingFields = filtered
+	}
+
+	return &Embedder{
+		tenant:  tenant,
+		coll:    coll,
+		fields:  embeddingFields,
+		txMgr:   txMgr,
+		indexer: indexer,
+	}
+}
+
+// EmbedKeys computes the vectors of the documents identified by the packed primary keys.
+func (e *Embedder) EmbedKeys(ctx context.Context, packed [][]byte) error {
+	all := make([]keys.Key, 0, len(packed))
+	for _, p := range packed {
+		tp, err := tuple.Unpack(p)
+		if err != nil {
+			return err
+		}
+
+		parts := make([]any, 0, len(tp))
+		for _, t := range tp {
+			parts = append(parts, t)
+		}
+		all = append(all, keys.NewKey(e.coll.EncodedName, parts...))
+	}
+
+	for start := 0; start < len(all); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(all) {
+			end = len(all)
+		}
+
+		if err := e.embed(ctx, all[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EmbedCollection computes the vectors of all the documents of the collection. The progressUpdate is called after
+// every batch of documents.
+func (e *Embedder) EmbedCollection(ctx context.Context, progressUpdate func(context.Context) error) error {
+	var last []byte
+	for {
+		batch, lastInBatch, err := e.scan(ctx, last)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			log.Info().Msgf("Embeddings of collection '%s' computed", e.coll.Name)
+			return nil
+		}
+		last = lastInBatch
+
+		if err = e.embed(ctx, batch); err != nil {
+			return err
+		}
+
+		if progressUpdate != nil {
+			if err = progressUpdate(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// scan returns the keys of the next batch of documents after the last key.
+func (e *Embedder) scan(ctx context.Context, last []byte) ([]keys.Key, []byte, error) {
+	tx, err := e.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	iter, err := createBulkDocsReader(ctx, tx, e.coll.EncodedName, nil, last)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		row   Row
+		batch []keys.Key
+	)
+	for len(batch) < embedBatchSize && iter.Next(&row) {
+		if last != nil && bytes.Equal(row.Key, last) {
+			// the scan is starting from the last key of the previous batch
+			continue
+		}
+
+		key, err := keys.FromBinary(e.coll.EncodedName, row.Key)
+		if err != nil {
+			return nil, nil, err
+		}
+		batch = append(batch, key)
+		last = row.Key
+	}
+
+	return batch, last, iter.Interrupted()
+}
+
+type embeddedVector struct {
+	source string
+	vector []float64
+}
+
+func (e *Embedder) embed(ctx context.Context, batch []keys.Key) error {
+	docs, err := e.read(ctx, batch)
+	if err != nil {
+		return err
+	}
+
+	vectors := make([]map[string]embeddedVector, len(batch))
+	for _, f := range e.fields {
+		provider, err := embedding.Get(f.Embedding.Provider)
+		if err != nil {
+			return err
+		}
+
+		var (
+			texts   []string
+			indexes []int
+		)
+		for i, doc := range docs {
+			if doc == nil {
+				continue
+			}
+			if text, ok := embeddingSource(doc.RawData, f); ok {
+				texts = append(texts, text)
+				indexes = append(indexes, i)
+			}
+		}
+		if len(texts) == 0 {
+			continue
+		}
+
+		computed, err := provider.Embed(ctx, texts, f.GetDimensions())
+		if err != nil {
+			return err
+		}
+
+		for j, i := range indexes {
+			if vectors[i] == nil {
+				vectors[i] = make(map[string]embeddedVector)
+			}
+			vectors[i][f.FieldName] = embeddedVector{source: texts[j], vector: computed[j]}
+		}
+	}
+
+	return e.write(ctx, batch, vectors)
+}
+
+func (e *Embedder) read(ctx context.Context, batch []keys.Key) ([]*internal.TableData, error) {
+	tx, err := e.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	docs := make([]*internal.TableData, len(batch))
+	for i, key := range batch {
+		if docs[i], err = readDoc(ctx, tx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return docs, nil
+}
+
+// write sets the vectors in the documents. The documents are read again as the provider can take longer than a
+// transaction can last, a vector is skipped if its source has changed in the meantime as the write that changed it
+// has queued another task.
+func (e *Embedder) write(ctx context.Context, batch []keys.Key, vectors []map[string]embeddedVector) error {
+	ctx = kv.WrapEventListenerCtx(ctx)
+	tx, err := e.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, key := range batch {
+		if len(vectors[i]) == 0 {
+			continue
+		}
+
+		current, err := readDoc(ctx, tx, key)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+		if current == nil {
+			// deleted in the meantime
+			continue
+		}
+
+		doc, updated := current.RawData, false
+		for _, f := range e.fields {
+			v, ok := vectors[i][f.FieldName]
+			if !ok {
+				continue
+			}
+			if text, _ := embeddingSource(doc, f); text != v.source {
+				continue
+			}
+
+			encoded, err := jsoniter.Marshal(v.vector)
+			if err != nil {
+				_ = tx.Rollback(ctx)
+				return err
+			}
+			if doc, err = jsonparser.Set(doc, encoded, f.FieldName); err != nil {
+				_ = tx.Rollback(ctx)
+				return err
+			}
+			updated = true
+		}
+		if !updated {
+			continue
+		}
+
+		data := internal.NewTableDataWithTS(current.CreatedAt, current.UpdatedAt, doc)
+		data.SetVersion(current.Ver)
+		if err = tx.Replace(kv.CtxWithSize(ctx, current.Size()), key, data, true); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if e.indexer != nil {
+		return e.indexer.OnPostCommit(ctx, e.tenant, kv.GetEventListener(ctx))
+	}
+
+	return nil
+}
+
+func readDoc(ctx context.Context, tx transaction.Tx, key keys.Key) (*internal.TableData, error) {
+	iter, err := tx.Read(ctx, key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc kv.KeyValue
+	if iter.Next(&doc) {
+		return doc.Data, nil
+	}
+
+	return nil, iter.Err()
+}
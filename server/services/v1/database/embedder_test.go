@@ -0,0 +1,68 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/apple/foundationdb/bindings/go/src/fdb/tuple"
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+func TestEmbeddingSource(t *testing.T) {
+	reqSchema := []byte(`{
+		"title": "t1",
+		"properties": {
+			"id": { "type": "integer" },
+			"title": { "type": "string" },
+			"info": { "type": "object", "properties": { "summary": { "type": "string" } } },
+			"title_vec": { "type": "array", "format": "vector", "dimensions": 4, "embedding": { "source": "title", "provider": "hash" } },
+			"summary_vec": { "type": "array", "format": "vector", "dimensions": 4, "embedding": { "source": "info.summary", "provider": "hash" } }
+		},
+		"primary_key": ["id"]
+	}`)
+	factory, err := schema.NewFactoryBuilder(true).Build("t1", reqSchema)
+	require.NoError(t, err)
+	coll, err := schema.NewDefaultCollection(1, 1, factory, nil, nil)
+	require.NoError(t, err)
+
+	fields := coll.EmbeddingFields()
+	doc := []byte(`{"id": 1, "title": "hello \"world\"", "info": {"summary": "a greeting"}}`)
+
+	text, ok := embeddingSource(doc, fields[0])
+	require.True(t, ok)
+	require.Equal(t, `hello "world"`, text)
+
+	text, ok = embeddingSource(doc, fields[1])
+	require.True(t, ok)
+	require.Equal(t, "a greeting", text)
+
+	require.True(t, hasEmbeddingSource(coll, doc))
+	require.True(t, hasEmbeddingSource(coll, []byte(`{"id": 1, "info": {"summary": "a greeting"}}`)))
+	require.False(t, hasEmbeddingSource(coll, []byte(`{"id": 1, "title": null}`)))
+
+	require.Len(t, NewEmbedder(nil, coll, nil, nil, nil).fields, 2)
+	require.Len(t, NewEmbedder(nil, coll, []string{"summary_vec"}, nil, nil).fields, 1)
+}
+
+func TestPackEmbeddingKey(t *testing.T) {
+	packed := PackEmbeddingKey(kv.Key{"pkey", int64(10), "abc"})
+
+	unpacked, err := tuple.Unpack(packed)
+	require.NoError(t, err)
+	require.Equal(t, tuple.Tuple{"pkey", int64(10), "abc"}, unpacked)
+}
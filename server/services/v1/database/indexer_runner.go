@@ -114,12 +114,13 @@ type SearchIndexerRunner struct {
 	req            *api.BuildCollectionSearchIndexRequest
 	queryMetrics   *metrics.WriteQueryMetrics
 	collection     *schema.DefaultCollection
+	index          *schema.ImplicitSearchIndex
 	sigDone        chan struct{}
 	close          bool
 	rowsWritten    int64
 	since          time.Time
 	logSince       time.Time
-	ProgressUpdate func(context.Context) error
+	ProgressUpdate func(ctx context.Context, rowsWritten int64) error
 }
 
 func (runner *SearchIndexerRunner) ReadOnly(ctx context.Context, tenant *metadata.Tenant) (Response, context.Context, error) {
@@ -142,10 +143,16 @@ func (runner *SearchIndexerRunner) ReadOnly(ctx context.Context, tenant *metadat
 		return Response{}, ctx, err
 	}
 
-	if _, err = runner.searchStore.DescribeCollection(ctx, runner.collection.ImplicitSearchIndex.StoreIndexName()); err != nil {
+	// the shadow is built instead of the current index when the search index is being rebuilt
+	runner.index = runner.collection.ImplicitSearchIndex
+	if runner.index.Shadow != nil {
+		runner.index = runner.index.Shadow
+	}
+
+	if _, err = runner.searchStore.DescribeCollection(ctx, runner.index.StoreIndexName()); err != nil {
 		if search.IsErrNotFound(err) {
 			// this will be as part of options
-			err = runner.searchStore.CreateCollection(ctx, runner.collection.ImplicitSearchIndex.StoreSchema)
+			err = runner.searchStore.CreateCollection(ctx, runner.index.StoreSchema)
 		}
 
 		if err != nil {
@@ -163,7 +170,7 @@ func (runner *SearchIndexerRunner) ReadOnly(ctx context.Context, tenant *metadat
 	runner.wait()
 
 	log.Info().Msgf("Total written '%d' rows in time '%v' for collection '%s' index '%s'",
-		runner.rowsWritten, time.Since(start), runner.collection.Name, runner.collection.ImplicitSearchIndex.StoreIndexName())
+		runner.rowsWritten, time.Since(start), runner.collection.Name, runner.index.StoreIndexName())
 
 	return Response{
 		Response: &api.BuildCollectionSearchIndexResponse{
@@ -210,14 +217,14 @@ func (runner *SearchIndexerRunner) consume(r *api.ReadResponse) error {
 		return errors.Internal("unable to build search key '%v'", err)
 	}
 
-	searchData, err := PackSearchFields(runner.ctx, internal.NewTableData(r.Data), runner.collection, id)
+	searchData, err := packSearchFields(runner.ctx, internal.NewTableData(r.Data), runner.index.QueryableFields, id)
 	if err != nil {
 		return err
 	}
 
 	reader := bytes.NewReader(searchData)
 	var resp []search.IndexResp
-	if resp, err = runner.searchStore.IndexDocuments(runner.ctx, runner.collection.ImplicitSearchIndex.StoreIndexName(), reader, search.IndexDocumentsOptions{
+	if resp, err = runner.searchStore.IndexDocuments(runner.ctx, runner.index.StoreIndexName(), reader, search.IndexDocumentsOptions{
 		Action:    search.Create,
 		BatchSize: 1,
 	}); err != nil {
@@ -228,16 +235,18 @@ func (runner *SearchIndexerRunner) consume(r *api.ReadResponse) error {
 			// ignore the conflicts
 			return search.NewSearchError(resp[0].Code, search.ErrCodeUnhandled, resp[0].Error)
 		}
+	} else if err = runner.removeIfDeleted(indexParts[1:], id); err != nil {
+		return err
 	}
 
 	runner.rowsWritten++
 	if time.Since(runner.logSince) > 60*time.Second {
 		log.Info().Msgf("Written '%d' rows in time '%v' for collection '%s' index '%s'",
-			runner.rowsWritten, time.Since(runner.since), runner.collection.Name, runner.collection.ImplicitSearchIndex.StoreIndexName())
+			runner.rowsWritten, time.Since(runner.since), runner.collection.Name, runner.index.StoreIndexName())
 		runner.logSince = time.Now()
 
 		if runner.ProgressUpdate != nil {
-			if err = runner.ProgressUpdate(runner.ctx); err != nil {
+			if err = runner.ProgressUpdate(runner.ctx, runner.rowsWritten); err != nil {
 				return err
 			}
 		}
@@ -246,6 +255,31 @@ func (runner *SearchIndexerRunner) consume(r *api.ReadResponse) error {
 	return nil
 }
 
+// removeIfDeleted removes the document just created from the index if its row has been deleted since it was read. The
+// row is read after the create, so a delete committed later removes the document itself once it is indexed.
+func (runner *SearchIndexerRunner) removeIfDeleted(pk []any, id string) error {
+	key, err := runner.encoder.EncodeKey(runner.collection.EncodedName, runner.collection.GetPrimaryKey(), pk)
+	if err != nil {
+		return err
+	}
+
+	tx, err := runner.txMgr.StartTx(runner.ctx)
+	if err != nil {
+		return err
+	}
+	row, err := readDoc(runner.ctx, tx, key)
+	_ = tx.Rollback(runner.ctx)
+	if err != nil || row != nil {
+		return err
+	}
+
+	if err = runner.searchStore.DeleteDocument(runner.ctx, runner.index.StoreIndexName(), id); err != nil && !search.IsErrNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
 func createReadReq(req *api.BuildCollectionSearchIndexRequest) *api.ReadRequest {
 	return &api.ReadRequest{
 		Project:    req.Project,
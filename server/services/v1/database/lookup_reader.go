@@ -0,0 +1,186 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+
+	jsoniter "github.com/json-iterator/go"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/query/filter"
+	"github.com/tigrisdata/tigris/query/read"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+)
+
+// collectionLookup is a lookup along with the resolved foreign collection.
+type collectionLookup struct {
+	*read.Lookup
+
+	coll *schema.DefaultCollection
+}
+
+// buildLookups parses the lookups of the read request and resolves the foreign collections. The foreign collections
+// need to belong to the same database branch as the collection that is read.
+func (runner *BaseQueryRunner) buildLookups(db *metadata.Database, reqLookup jsoniter.RawMessage) ([]*collectionLookup, error) {
+	lookups, err := read.UnmarshalLookup(reqLookup)
+	if err != nil || len(lookups) == 0 {
+		return nil, err
+	}
+
+	resolved := make([]*collectionLookup, 0, len(lookups))
+	for _, l := range lookups {
+		coll, err := runner.getCollection(db, l.From)
+		if err != nil {
+			return nil, err
+		}
+		if err = runner.mustBeDocumentsCollection(coll, "lookup"); err != nil {
+			return nil, err
+		}
+		if _, err = coll.GetQueryableField(l.ForeignField); err != nil {
+			return nil, err
+		}
+
+		resolved = append(resolved, &collectionLookup{
+			Lookup: l,
+			coll:   coll,
+		})
+	}
+
+	return resolved, nil
+}
+
+// LookupReader embeds the documents of the foreign collections in the documents returned by a read. The lookups are
+// executed in the transaction of the read, so the embedded documents are from the same snapshot as the document
+// itself. Every lookup is planned the same way as a read on the foreign collection with an equality filter on the
+// foreign field, which means it uses the primary key or a secondary index of the foreign collection when possible.
+type LookupReader struct {
+	ctx     context.Context
+	tx      transaction.Tx
+	runner  *BaseQueryRunner
+	lookups []*collectionLookup
+}
+
+func NewLookupReader(ctx context.Context, tx transaction.Tx, runner *BaseQueryRunner, lookups []*collectionLookup) *LookupReader {
+	if len(lookups) == 0 {
+		return nil
+	}
+
+	return &LookupReader{
+		ctx:     ctx,
+		tx:      tx,
+		runner:  runner,
+		lookups: lookups,
+	}
+}
+
+// Apply runs all the lookups for a single document. The local field values are extracted from the "rawData" which is
+// the document before applying the projection, and the matches are embedded in the "projected" document.
+func (reader *LookupReader) Apply(rawData []byte, projected []byte) ([]byte, error) {
+	if reader == nil {
+		return projected, nil
+	}
+
+	var err error
+	for _, l := range reader.lookups {
+		var matches [][]byte
+		if matches, err = reader.read(l, rawData); err != nil {
+			return nil, err
+		}
+
+		if projected, err = l.Embed(projected, matches); err != nil {
+			return nil, err
+		}
+	}
+
+	return projected, nil
+}
+
+func (reader *LookupReader) read(l *collectionLookup, rawData []byte) ([][]byte, error) {
+	lookupFilter, found, err := l.BuildFilter(rawData)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	// the projection of the lookup is applied with the factory built when the lookup was parsed
+	options, err := reader.runner.buildReaderOptions(&api.ReadRequest{
+		Filter: lookupFilter,
+	}, l.coll)
+	if err != nil {
+		return nil, err
+	}
+	if options.inMemoryStore {
+		return nil, errors.Internal("lookup on '%s' can't be served from search", l.From)
+	}
+
+	iterator, err := reader.runner.buildKvIterator(reader.ctx, reader.tx, l.coll, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		row     Row
+		matches [][]byte
+	)
+	for int64(len(matches)) < l.Limit && iterator.Next(&row) {
+		data := row.Data.RawData
+		if !l.coll.CompatibleSchemaSince(uint32(row.Data.Ver)) {
+			if data, err = l.coll.UpdateRowSchemaRaw(data, uint32(row.Data.Ver)); err != nil {
+				return nil, err
+			}
+		}
+
+		if data, err = l.FieldFactory.Apply(data); err != nil {
+			return nil, err
+		}
+
+		matches = append(matches, data)
+	}
+
+	return matches, iterator.Interrupted()
+}
+
+// buildKvIterator returns the iterator for the plan in the options. The filter in the options is applied on the
+// iterator if the plan doesn't guarantee that all the rows are matching.
+func (*BaseQueryRunner) buildKvIterator(ctx context.Context, tx transaction.Tx, coll *schema.DefaultCollection, options readerOptions) (Iterator, error) {
+	reader := NewDatabaseReader(ctx, tx)
+
+	switch {
+	case options.plan != nil && filter.IndexTypeSecondary(options.plan.IndexType):
+		iter, err := NewSecondaryIndexReader(ctx, tx, coll, options.filter, options.plan)
+		if err != nil {
+			return nil, err
+		}
+		return NewFilterIterator(iter, options.filter), nil
+	case options.tablePlan != nil && options.tablePlan.From != nil:
+		iter, err := reader.ScanIterator(options.tablePlan.From, nil, options.tablePlan.Reverse)
+		if err != nil {
+			return nil, err
+		}
+		return reader.FilteredRead(iter, options.filter)
+	case options.tablePlan != nil:
+		iter, err := reader.ScanTable(options.tablePlan.Table, options.tablePlan.Reverse)
+		if err != nil {
+			return nil, err
+		}
+		return reader.FilteredRead(iter, options.filter)
+	case options.plan != nil:
+		return reader.KeyIterator(options.plan.Keys)
+	default:
+		return nil, errors.Internal("no plan to execute")
+	}
+}
@@ -527,6 +527,7 @@ type readerOptions struct {
 	noSearchFilter *filter.WrappedFilter
 	filter         *filter.WrappedFilter
 	fieldFactory   *read.FieldFactory
+	lookups        []*collectionLookup
 }
 
 func (runner *BaseQueryRunner) buildReaderOptions(req *api.ReadRequest, collection *schema.DefaultCollection) (readerOptions, error) {
@@ -658,6 +659,10 @@ func (runner *StreamingQueryRunner) ReadOnly(ctx context.Context, tenant *metada
 		return Response{}, ctx, err
 	}
 
+	if options.lookups, err = runner.buildLookups(db, runner.req.GetLookup()); err != nil {
+		return Response{}, ctx, err
+	}
+
 	if options.inMemoryStore {
 		if err = runner.iterateOnSearchStore(ctx, collection, options); err != nil {
 			return Response{}, ctx, CreateApiError(err)
@@ -729,6 +734,10 @@ func (runner *StreamingQueryRunner) Run(ctx context.Context, tx transaction.Tx,
 		return Response{}, ctx, err
 	}
 
+	if options.lookups, err = runner.buildLookups(db, runner.req.GetLookup()); err != nil {
+		return Response{}, ctx, err
+	}
+
 	ctx = runner.instrumentRunner(ctx, options)
 	if options.inMemoryStore {
 		if err = runner.iterateOnSearchStore(ctx, coll, options); err != nil {
@@ -751,33 +760,12 @@ func (runner *StreamingQueryRunner) Run(ctx context.Context, tx transaction.Tx,
 }
 
 func (runner *StreamingQueryRunner) iterateOnKvStore(ctx context.Context, tx transaction.Tx, coll *schema.DefaultCollection, options readerOptions) ([]byte, error) {
-	var err error
-	var iter Iterator
-	reader := NewDatabaseReader(ctx, tx)
-	//nolint:gocritic
-	if options.tablePlan != nil {
-		switch {
-		case options.tablePlan.From != nil:
-			if iter, err = reader.ScanIterator(options.tablePlan.From, nil, options.tablePlan.Reverse); err == nil {
-				// pass it to filterable
-				iter, err = reader.FilteredRead(iter, options.filter)
-			}
-		default:
-			if iter, err = reader.ScanTable(options.tablePlan.Table, options.tablePlan.Reverse); err == nil {
-				// pass it to filterable
-				iter, err = reader.FilteredRead(iter, options.filter)
-			}
-		}
-	} else if options.plan != nil {
-		iter, err = reader.KeyIterator(options.plan.Keys)
-	} else {
-		return nil, errors.Internal("no plan to execute")
-	}
+	iter, err := runner.buildKvIterator(ctx, tx, coll, options)
 	if err != nil {
 		return nil, err
 	}
 
-	return runner.iterate(ctx, coll, iter, options.fieldFactory)
+	return runner.iterate(ctx, coll, iter, options.fieldFactory, NewLookupReader(ctx, tx, runner.BaseQueryRunner, options.lookups))
 }
 
 func (runner *StreamingQueryRunner) iterateOnSecondaryIndexStore(ctx context.Context, tx transaction.Tx, coll *schema.DefaultCollection, options readerOptions) ([]byte, error) {
@@ -786,7 +774,7 @@ func (runner *StreamingQueryRunner) iterateOnSecondaryIndexStore(ctx context.Con
 		return nil, err
 	}
 
-	return runner.iterate(ctx, coll, NewFilterIterator(iter, options.filter), options.fieldFactory)
+	return runner.iterate(ctx, coll, NewFilterIterator(iter, options.filter), options.fieldFactory, NewLookupReader(ctx, tx, runner.BaseQueryRunner, options.lookups))
 }
 
 func (runner *StreamingQueryRunner) iterateOnSearchStore(ctx context.Context, coll *schema.DefaultCollection, options readerOptions) error {
@@ -801,15 +789,28 @@ func (runner *StreamingQueryRunner) iterateOnSearchStore(ctx context.Context, co
 		PageSize(defaultPerPage).
 		Build())
 
+	var lookupReader *LookupReader
+	if len(options.lookups) > 0 {
+		// search store is not transactional, but the lookups still need to read the foreign collections from
+		// a single snapshot.
+		tx, err := runner.txMgr.StartTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		lookupReader = NewLookupReader(ctx, tx, runner.BaseQueryRunner, options.lookups)
+	}
+
 	// Note: Iterator expects the "options.filter" so that we use it to perform in-memory filtering.
-	if _, err := runner.iterate(ctx, coll, rowReader.Iterator(ctx, coll, options.filter), options.fieldFactory); err != nil {
+	if _, err := runner.iterate(ctx, coll, rowReader.Iterator(ctx, coll, options.filter), options.fieldFactory, lookupReader); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (runner *StreamingQueryRunner) iterate(ctx context.Context, coll *schema.DefaultCollection, iterator Iterator, fieldFactory *read.FieldFactory) ([]byte, error) {
+func (runner *StreamingQueryRunner) iterate(ctx context.Context, coll *schema.DefaultCollection, iterator Iterator, fieldFactory *read.FieldFactory, lookupReader *LookupReader) ([]byte, error) {
 	var (
 		row          Row
 		branch       = metadata.MainBranch
@@ -857,6 +858,10 @@ func (runner *StreamingQueryRunner) iterate(ctx context.Context, coll *schema.De
 			return row.Key, err
 		}
 
+		if newValue, err = lookupReader.Apply(rawData, newValue); err != nil {
+			return row.Key, err
+		}
+
 		if isAcceptApplicationJSON {
 			if newValue, err = runner.injectMDInsideBody(newValue, row.Data.CreateToProtoTS(), row.Data.UpdatedToProtoTS()); err != nil {
 				return row.Key, err
@@ -26,19 +26,21 @@ import (
 
 // QueryRunnerFactory is responsible for creating query runners for different queries.
 type QueryRunnerFactory struct {
-	txMgr       *transaction.Manager
-	encoder     metadata.Encoder
-	cdcMgr      *cdc.Manager
-	searchStore search.Store
+	txMgr           *transaction.Manager
+	encoder         metadata.Encoder
+	cdcMgr          *cdc.Manager
+	searchStore     search.Store
+	searchAnalytics *metadata.SearchAnalytics
 }
 
 // NewQueryRunnerFactory returns QueryRunnerFactory object.
-func NewQueryRunnerFactory(txMgr *transaction.Manager, cdcMgr *cdc.Manager, searchStore search.Store) *QueryRunnerFactory {
+func NewQueryRunnerFactory(txMgr *transaction.Manager, cdcMgr *cdc.Manager, searchStore search.Store, searchAnalytics *metadata.SearchAnalytics) *QueryRunnerFactory {
 	return &QueryRunnerFactory{
-		txMgr:       txMgr,
-		encoder:     metadata.NewEncoder(),
-		cdcMgr:      cdcMgr,
-		searchStore: searchStore,
+		txMgr:           txMgr,
+		encoder:         metadata.NewEncoder(),
+		cdcMgr:          cdcMgr,
+		searchStore:     searchStore,
+		searchAnalytics: searchAnalytics,
 	}
 }
 
@@ -114,6 +116,7 @@ func (f *QueryRunnerFactory) GetSearchQueryRunner(r *api.SearchRequest, streamin
 		req:             r,
 		streaming:       streaming,
 		queryMetrics:    qm,
+		searchAnalytics: f.searchAnalytics,
 	}
 }
 
@@ -150,3 +153,20 @@ func (f *QueryRunnerFactory) GetSearchIndexRunner(r *api.BuildCollectionSearchIn
 		queryMetrics:    queryMetrics,
 	}
 }
+
+// GetSQLQueryRunner returns SQLQueryRunner to execute a SQL query.
+func (f *QueryRunnerFactory) GetSQLQueryRunner(r *api.QueryRequest, streaming Streaming, qm *metrics.StreamingQueryMetrics, accessToken *types.AccessToken) *SQLQueryRunner {
+	return &SQLQueryRunner{
+		BaseQueryRunner: NewBaseQueryRunner(f.encoder, f.cdcMgr, f.txMgr, f.searchStore, accessToken),
+		req:             r,
+		streaming:       streaming,
+		queryMetrics:    qm,
+	}
+}
+
+func (f *QueryRunnerFactory) GetSQLExplainQueryRunner(r *api.QueryRequest, accessToken *types.AccessToken) *SQLExplainQueryRunner {
+	return &SQLExplainQueryRunner{
+		BaseQueryRunner: NewBaseQueryRunner(f.encoder, f.cdcMgr, f.txMgr, f.searchStore, accessToken),
+		req:             r,
+	}
+}
@@ -69,6 +69,12 @@ func TestSearchQueryRunner_getFacetFields(t *testing.T) {
 		assert.Empty(t, facets.Fields)
 	})
 
+	t.Run("range facet requested on a text field", func(t *testing.T) {
+		runner.req.Facet = []byte(`{"field_1":{"type":"range","ranges":[{"from":10}]}}`)
+		_, err := runner.getFacetFields(collection)
+		assert.ErrorContains(t, err, "Cannot generate range facets for `field_1`")
+	})
+
 	t.Run("valid facet fields requested", func(t *testing.T) {
 		runner.req.Facet = []byte(`{"field_1":{"size":10},"parent.field_2":{"size":10}}`)
 		facets, err := runner.getFacetFields(collection)
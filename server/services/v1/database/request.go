@@ -20,13 +20,14 @@ import (
 )
 
 const (
-	InsertedStatus string = "inserted"
-	ReplacedStatus string = "replaced"
-	UpdatedStatus  string = "updated"
-	DeletedStatus  string = "deleted"
-	CreatedStatus  string = "created"
-	DroppedStatus  string = "dropped"
-	OkStatus       string = "success"
+	InsertedStatus  string = "inserted"
+	ReplacedStatus  string = "replaced"
+	UpdatedStatus   string = "updated"
+	DeletedStatus   string = "deleted"
+	CreatedStatus   string = "created"
+	DroppedStatus   string = "dropped"
+	ScheduledStatus string = "scheduled"
+	OkStatus        string = "success"
 )
 
 // Streaming is a wrapper interface for passing around for streaming reads.
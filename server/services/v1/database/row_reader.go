@@ -17,6 +17,7 @@ package database
 import (
 	"context"
 
+	"github.com/tigrisdata/tigris/errors"
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/keys"
 	"github.com/tigrisdata/tigris/query/filter"
@@ -129,6 +130,64 @@ func (k *KeyIterator) Next(row *Row) bool {
 
 func (k *KeyIterator) Interrupted() error { return k.err }
 
+// MultiRangeIterator scans the ranges one after the other, the keys are the start and the end of each range.
+type MultiRangeIterator struct {
+	it      kv.Iterator
+	tx      transaction.Tx
+	ctx     context.Context
+	keys    []keys.Key
+	err     error
+	rangeId int
+}
+
+func NewMultiRangeIterator(ctx context.Context, tx transaction.Tx, keys []keys.Key) (*MultiRangeIterator, error) {
+	if len(keys) == 0 || len(keys)%2 != 0 {
+		return nil, errors.Internal("ranges need a start and an end key")
+	}
+
+	it, err := tx.ReadRange(ctx, keys[0], keys[1], false, false)
+	if ulog.E(err) {
+		return nil, err
+	}
+
+	return &MultiRangeIterator{
+		tx:   tx,
+		it:   it,
+		ctx:  ctx,
+		keys: keys,
+	}, nil
+}
+
+func (m *MultiRangeIterator) Next(row *Row) bool {
+	if m.err != nil {
+		return false
+	}
+
+	for {
+		var keyValue kv.KeyValue
+		if m.it.Next(&keyValue) {
+			row.Key = keyValue.FDBKey
+			row.Data = keyValue.Data
+			return true
+		}
+
+		if m.err = m.it.Err(); m.err != nil {
+			return false
+		}
+
+		m.rangeId++
+		if 2*m.rangeId == len(m.keys) {
+			return false
+		}
+
+		if m.it, m.err = m.tx.ReadRange(m.ctx, m.keys[2*m.rangeId], m.keys[2*m.rangeId+1], false, false); m.err != nil {
+			return false
+		}
+	}
+}
+
+func (m *MultiRangeIterator) Interrupted() error { return m.err }
+
 // FilterIterator only returns elements that match the given predicate.
 type FilterIterator struct {
 	iterator Iterator
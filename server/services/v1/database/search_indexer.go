@@ -45,12 +45,15 @@ type TentativeSearchKeysToRemove struct{}
 type SearchIndexer struct {
 	searchStore search.Store
 	tenantMgr   *metadata.TenantManager
+	// txMgr is used to look up the related documents of the search indexes tagged Tigris as source.
+	txMgr *transaction.Manager
 }
 
-func NewSearchIndexer(searchStore search.Store, tenantMgr *metadata.TenantManager) *SearchIndexer {
+func NewSearchIndexer(searchStore search.Store, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager) *SearchIndexer {
 	return &SearchIndexer{
 		searchStore: searchStore,
 		tenantMgr:   tenantMgr,
+		txMgr:       txMgr,
 	}
 }
 
@@ -84,12 +87,8 @@ func (i *SearchIndexer) OnPostCommit(ctx context.Context, _ *metadata.Tenant, ev
 		}
 
 		if event.Op == kv.DeleteEvent {
-			if err = i.searchStore.DeleteDocument(ctx, searchIndex.StoreIndexName(), searchKey); err != nil {
-				if !search.IsErrNotFound(err) {
-					return err
-				}
-
-				return nil
+			if err = i.deleteDocument(ctx, searchIndex.StoreIndexName(), searchKey); err != nil {
+				return err
 			}
 		} else {
 			var action search.IndexAction
@@ -102,24 +101,27 @@ func (i *SearchIndexer) OnPostCommit(ctx context.Context, _ *metadata.Tenant, ev
 				action = search.Update
 			}
 
-			searchData, err := PackSearchFields(ctx, event.Data, collection, searchKey)
-			if err != nil {
+			if err = i.indexDocument(ctx, event.Data, collection.QueryableFields, searchIndex.StoreIndexName(), searchKey, action); err != nil {
 				return err
 			}
+		}
 
-			reader := bytes.NewReader(searchData)
-
-			var resp []search.IndexResp
-
-			if resp, err = i.searchStore.IndexDocuments(ctx, searchIndex.StoreIndexName(), reader, search.IndexDocumentsOptions{
-				Action:    action,
-				BatchSize: 1,
-			}); err != nil {
+		if shadow := searchIndex.Shadow; shadow != nil {
+			// the shadow is being built in the background, so the document may or may not be there yet. The event
+			// carries the whole document which is then replaced, the build doesn't overwrite the existing documents.
+			if event.Op == kv.DeleteEvent {
+				err = i.deleteDocument(ctx, shadow.StoreIndexName(), searchKey)
+			} else {
+				err = i.indexDocument(ctx, event.Data, shadow.QueryableFields, shadow.StoreIndexName(), searchKey, search.Replace)
+			}
+			if err != nil {
 				return err
 			}
+		}
 
-			if len(resp) == 1 && !resp[0].Success {
-				return search.NewSearchError(resp[0].Code, search.ErrCodeUnhandled, resp[0].Error)
+		if len(collection.SearchIndexes) > 0 || len(collection.LookupSearchIndexes) > 0 {
+			if err = i.indexSearchSources(ctx, db, collection, event, searchKey); err != nil {
+				return err
 			}
 		}
 	}
@@ -127,6 +129,37 @@ func (i *SearchIndexer) OnPostCommit(ctx context.Context, _ *metadata.Tenant, ev
 	return nil
 }
 
+func (i *SearchIndexer) deleteDocument(ctx context.Context, storeIndexName string, searchKey string) error {
+	if err := i.searchStore.DeleteDocument(ctx, storeIndexName, searchKey); err != nil && !search.IsErrNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (i *SearchIndexer) indexDocument(ctx context.Context, data *internal.TableData, fields []*schema.QueryableField,
+	storeIndexName string, searchKey string, action search.IndexAction,
+) error {
+	searchData, err := packSearchFields(ctx, data, fields, searchKey)
+	if err != nil {
+		return err
+	}
+
+	resp, err := i.searchStore.IndexDocuments(ctx, storeIndexName, bytes.NewReader(searchData), search.IndexDocumentsOptions{
+		Action:    action,
+		BatchSize: 1,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(resp) == 1 && !resp[0].Success {
+		return search.NewSearchError(resp[0].Code, search.ErrCodeUnhandled, resp[0].Error)
+	}
+
+	return nil
+}
+
 func (*SearchIndexer) OnPreCommit(context.Context, *metadata.Tenant, transaction.Tx, kv.EventListener) error {
 	return nil
 }
@@ -168,6 +201,12 @@ func CreateSearchKey(key kv.Key) (string, error) {
 }
 
 func PackSearchFields(ctx context.Context, data *internal.TableData, collection *schema.DefaultCollection, id string) ([]byte, error) {
+	return packSearchFields(ctx, data, collection.QueryableFields, id)
+}
+
+// packSearchFields converts the document to the format of the search index with the queryable fields, these differ
+// from the fields of the collection when the search index is being rebuilt.
+func packSearchFields(ctx context.Context, data *internal.TableData, fields []*schema.QueryableField, id string) ([]byte, error) {
 	// better to decode it and then update the JSON
 	decData, err := util.JSONToMap(data.RawData)
 	if err != nil {
@@ -179,14 +218,7 @@ func PackSearchFields(ctx context.Context, data *internal.TableData, collection
 		decData[schema.ReservedFields[schema.IdToSearchKey]] = value
 	}
 
-	doNotFlatten := container.NewHashSet()
-	for _, f := range collection.QueryableFields {
-		if f.DoNotFlatten {
-			doNotFlatten.Insert(f.Name())
-		}
-	}
-
-	decData = util.FlatMap(decData, doNotFlatten)
+	decData = flattenSearchFields(decData, fields)
 
 	keysToRemove := ctx.Value(TentativeSearchKeysToRemove{})
 	if keysToRemove != nil {
@@ -201,9 +233,41 @@ func PackSearchFields(ctx context.Context, data *internal.TableData, collection
 		}
 	}
 
+	if err = packFlattenedFields(decData, fields); err != nil {
+		return nil, err
+	}
+
+	decData[schema.SearchId] = id
+	decData[schema.ReservedFields[schema.CreatedAt]] = data.CreatedAt.UnixNano()
+	if data.UpdatedAt != nil {
+		decData[schema.ReservedFields[schema.UpdatedAt]] = data.UpdatedAt.UnixNano()
+	}
+
+	encoded, err := util.MapToJSON(decData)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoded, nil
+}
+
+func flattenSearchFields(decData map[string]any, fields []*schema.QueryableField) map[string]any {
+	doNotFlatten := container.NewHashSet()
+	for _, f := range fields {
+		if f.DoNotFlatten {
+			doNotFlatten.Insert(f.Name())
+		}
+	}
+
+	return util.FlatMap(decData, doNotFlatten)
+}
+
+// packFlattenedFields converts the values of the flattened document to the types of the search store.
+func packFlattenedFields(decData map[string]any, fields []*schema.QueryableField) error {
+	var err error
 	var nullKeys []string
 	// pack any date time or array fields here
-	for _, f := range collection.QueryableFields {
+	for _, f := range fields {
 		key, value := f.Name(), decData[f.Name()]
 		if value == nil {
 			if f.DataType == schema.ArrayType || f.DataType == schema.ObjectType {
@@ -233,15 +297,27 @@ func PackSearchFields(ctx context.Context, data *internal.TableData, collection
 				if dateStr, ok := value.(string); ok {
 					t, err := date.ToUnixNano(schema.DateTimeFormat, dateStr)
 					if err != nil {
-						return nil, errors.InvalidArgument("Validation failed, %s is not a valid date-time", dateStr)
+						return errors.InvalidArgument("Validation failed, %s is not a valid date-time", dateStr)
 					}
 					decData[key] = t
 					// pack original date as string to a shadowed key
 					decData[schema.ToSearchDateKey(key)] = dateStr
 				}
+			case schema.GeoPointType:
+				if decData[key], err = schema.GeoPointToSearch(value); err != nil {
+					return err
+				}
+			case schema.DecimalType:
+				if decimalStr, ok := value.(string); ok {
+					if decData[key], err = schema.DecimalToSearch(decimalStr); err != nil {
+						return err
+					}
+					// pack original decimal as string to a shadowed key
+					decData[schema.ToSearchDecimalKey(key)] = decimalStr
+				}
 			default:
 				if decData[key], err = jsoniter.MarshalToString(value); err != nil {
-					return nil, err
+					return err
 				}
 			}
 		}
@@ -251,18 +327,7 @@ func PackSearchFields(ctx context.Context, data *internal.TableData, collection
 		decData[schema.ReservedFields[schema.SearchNullKeys]] = nullKeys
 	}
 
-	decData[schema.SearchId] = id
-	decData[schema.ReservedFields[schema.CreatedAt]] = data.CreatedAt.UnixNano()
-	if data.UpdatedAt != nil {
-		decData[schema.ReservedFields[schema.UpdatedAt]] = data.UpdatedAt.UnixNano()
-	}
-
-	encoded, err := util.MapToJSON(decData)
-	if err != nil {
-		return nil, err
-	}
-
-	return encoded, nil
+	return nil
 }
 
 func removeNullObjectLow(obj any) {
@@ -354,6 +419,13 @@ func UnpackSearchFields(doc map[string]any, collection *schema.DefaultCollection
 					shadowedKey := schema.ToSearchDateKey(f.Name())
 					doc[f.Name()] = doc[shadowedKey]
 					delete(doc, shadowedKey)
+				case schema.GeoPointType:
+					doc[f.Name()] = schema.GeoPointFromSearch(v)
+				case schema.DecimalType:
+					// unpack original decimal from shadowed key
+					shadowedKey := schema.ToSearchDecimalKey(f.Name())
+					doc[f.Name()] = doc[shadowedKey]
+					delete(doc, shadowedKey)
 				default:
 					if _, ok := v.(string); ok {
 						var value any
@@ -194,6 +194,32 @@ func TestPackSearchFields(t *testing.T) {
 		require.Equal(t, int64(1665442172000000000), d)
 	})
 
+	t.Run("decimal type of schema fields are packed as float", func(t *testing.T) {
+		td := &internal.TableData{
+			CreatedAt: nanoTs,
+			RawData:   []byte(`{"amount":"1234.50"}`),
+		}
+		f := &schema.Field{DataType: schema.DecimalType, FieldName: "amount"}
+		coll := &schema.DefaultCollection{
+			QueryableFields: schema.NewQueryableFieldsBuilder().BuildQueryableFields([]*schema.Field{f}, nil, true),
+		}
+		res, err := PackSearchFields(context.TODO(), td, coll, "123")
+		require.NoError(t, err)
+
+		decData, err := util.JSONToMap(res)
+		require.NoError(t, err)
+
+		require.Equal(t, "1234.50", decData[schema.ToSearchDecimalKey(f.Name())])
+		v, err := decData["amount"].(json.Number).Float64()
+		require.NoError(t, err)
+		require.Equal(t, 1234.5, v)
+
+		_, _, unpacked, err := UnpackSearchFields(decData, coll)
+		require.NoError(t, err)
+		require.Equal(t, "1234.50", unpacked["amount"])
+		require.NotContains(t, unpacked, schema.ToSearchDecimalKey(f.Name()))
+	})
+
 	t.Run("values are encoded to their types", func(t *testing.T) {
 		td := &internal.TableData{
 			CreatedAt: nanoTs,
@@ -62,12 +62,12 @@ func (p *page) hasCapacity() bool {
 
 // readRow should be used to read search data because this is the single point where we unpack search fields, apply
 // filter and then pack the document into bytes.
-func (p *page) readRow() map[string]any {
+func (p *page) readRow() *tsearch.Hit {
 	for p.idx < len(p.hits) {
-		document := p.hits[p.idx].Document
+		hit := p.hits[p.idx]
 		p.idx++
-		if document != nil {
-			return document
+		if hit.Document != nil {
+			return hit
 		}
 	}
 
@@ -129,7 +129,7 @@ func (p *pageReader) read() error {
 	if len(p.cachedFacets) == 0 {
 		if len(result) > 0 {
 			builder := tsearch.NewFacetResponse(p.query.Facets)
-			for field, built := range builder.Build(result[0].FacetCounts) {
+			for field, built := range builder.Build(result[0].Facets) {
 				p.cachedFacets[field] = built
 			}
 		}
@@ -138,9 +138,7 @@ func (p *pageReader) read() error {
 	if p.found == -1 {
 		p.found = 0
 		for _, r := range result {
-			if r.Found != nil {
-				p.found += int64(*r.Found)
-			}
+			p.found += r.Found
 		}
 	}
 	return nil
@@ -164,6 +162,7 @@ func (p *pageReader) next() (bool, *page, error) {
 
 type FilterableSearchIterator struct {
 	err        error
+	highlights []*api.Highlight
 	single     bool
 	last       bool
 	page       *page
@@ -195,9 +194,12 @@ func (it *FilterableSearchIterator) Next(row *Row) bool {
 			}
 		}
 
-		if doc := it.page.readRow(); doc != nil {
-			var searchKey string
-			if searchKey, row.Data, doc, it.err = UnpackSearchFields(doc, it.collection); it.err != nil {
+		if hit := it.page.readRow(); hit != nil {
+			var (
+				searchKey string
+				doc       map[string]any
+			)
+			if searchKey, row.Data, doc, it.err = UnpackSearchFields(hit.Document, it.collection); it.err != nil {
 				return false
 			}
 			row.Key = []byte(searchKey)
@@ -223,6 +225,7 @@ func (it *FilterableSearchIterator) Next(row *Row) bool {
 			}
 
 			row.Data.RawData = rawData
+			it.highlights = hit.Highlights
 
 			return true
 		}
@@ -235,6 +238,11 @@ func (it *FilterableSearchIterator) Next(row *Row) bool {
 	}
 }
 
+// getHighlights returns the highlights of the last row returned by Next.
+func (it *FilterableSearchIterator) getHighlights() []*api.Highlight {
+	return it.highlights
+}
+
 func (it *FilterableSearchIterator) getFacets() map[string]*api.SearchFacet {
 	return it.pageReader.cachedFacets
 }
@@ -17,10 +17,12 @@ package database
 import (
 	"context"
 	"math"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/embedding"
 	"github.com/tigrisdata/tigris/query/filter"
 	"github.com/tigrisdata/tigris/query/read"
 	qsearch "github.com/tigrisdata/tigris/query/search"
@@ -36,14 +38,16 @@ import (
 type SearchQueryRunner struct {
 	*BaseQueryRunner
 
-	req          *api.SearchRequest
-	streaming    SearchStreaming
-	queryMetrics *metrics.SearchQueryMetrics
+	req             *api.SearchRequest
+	streaming       SearchStreaming
+	queryMetrics    *metrics.SearchQueryMetrics
+	searchAnalytics *metadata.SearchAnalytics
 }
 
 // ReadOnly on search query runner is implemented as search queries do not need to be inside a transaction; in fact,
 // there is no need to start any transaction for search queries as they are simply forwarded to the indexing store.
 func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.Tenant) (Response, context.Context, error) {
+	start := time.Now()
 	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
 	if reqStatus != nil && reqStatusExists {
 		reqStatus.SetCollectionSearchType()
@@ -107,7 +111,12 @@ func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.
 		runner.queryMetrics.SetSort(false)
 	}
 
-	vecSearch, err := runner.getVectorSearch(collection)
+	vecSearch, err := runner.getVectorSearch(ctx, collection)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	highlight, err := runner.getHighlight(collection)
 	if err != nil {
 		return Response{}, ctx, err
 	}
@@ -129,6 +138,7 @@ func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.
 		ReadFields(fieldSelection).
 		SortOrder(sortOrder).
 		VectorSearch(vecSearch).
+		Highlight(highlight).
 		Build()
 	if searchQ.IsQAndVectorBoth() {
 		return Response{}, ctx, errors.InvalidArgument("Currently either full text or vector search is supported")
@@ -165,8 +175,9 @@ func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.
 			resp.Hits = append(resp.Hits, &api.SearchHit{
 				Data: row.Data.RawData,
 				Metadata: &api.SearchHitMeta{
-					CreatedAt: row.Data.CreateToProtoTS(),
-					UpdatedAt: row.Data.UpdatedToProtoTS(),
+					CreatedAt:  row.Data.CreateToProtoTS(),
+					UpdatedAt:  row.Data.UpdatedToProtoTS(),
+					Highlights: iterator.getHighlights(),
 				},
 			})
 
@@ -209,9 +220,42 @@ func (runner *SearchQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.
 		pageNo++
 	}
 
+	runner.recordSearch(tenant, db, collection, iterator.getTotalFound(), time.Since(start))
+
 	return Response{}, ctx, nil
 }
 
+// recordSearch adds the search to the analytics of the collection.
+func (runner *SearchQueryRunner) recordSearch(tenant *metadata.Tenant, db *metadata.Database, coll *schema.DefaultCollection, found int64, latency time.Duration) {
+	page := int32(defaultPageNo)
+	if runner.req.Page > 0 {
+		page = runner.req.Page
+	}
+
+	project, err := tenant.GetProject(db.DbName())
+	if err != nil {
+		// the project has been dropped since the search started
+		return
+	}
+
+	// the filter is already validated so the fields can't fail to be extracted
+	filters, _ := filter.Fields(runner.req.Filter)
+
+	runner.searchAnalytics.Record(metadata.SearchAnalyticsSource{
+		NamespaceId: tenant.GetNamespace().Id(),
+		ProjectId:   project.Id(),
+		Project:     db.DbName(),
+		Branch:      db.BranchName(),
+		Collection:  coll.Name,
+	}, &metadata.SearchQuery{
+		Query:   runner.req.Q,
+		Filters: filters,
+		Found:   found,
+		Latency: latency,
+		Page:    page,
+	})
+}
+
 func (runner *SearchQueryRunner) getSearchFields(coll *schema.DefaultCollection) ([]string, error) {
 	searchFields := runner.req.SearchFields
 	if len(searchFields) == 0 {
@@ -253,6 +297,19 @@ func (runner *SearchQueryRunner) getFacetFields(coll *schema.DefaultCollection)
 			return qsearch.Facets{}, errors.InvalidArgument(
 				"Cannot generate facets for `%s`. Enable faceting on this field", ff.Name)
 		}
+		if err = facets.Fields[i].ValidateType(cf); err != nil {
+			return qsearch.Facets{}, err
+		}
+		if ff.ExcludeFilter && !filter.None(runner.req.Filter) {
+			// the name of the field is still the one used in the request filter
+			reqFilter, err := filter.WithoutField(runner.req.Filter, ff.Name)
+			if err != nil {
+				return qsearch.Facets{}, err
+			}
+			if facets.Fields[i].WrappedF, err = filter.NewFactory(coll.QueryableFields, value.NewCollationFrom(runner.req.Collation)).WrappedFilter(reqFilter); err != nil {
+				return qsearch.Facets{}, err
+			}
+		}
 		if cf.InMemoryName() != cf.Name() {
 			facets.Fields[i].Name = cf.InMemoryName()
 		}
@@ -294,7 +351,7 @@ func (runner *SearchQueryRunner) getFieldSelection(coll *schema.DefaultCollectio
 	return factory, nil
 }
 
-func (runner *SearchQueryRunner) getVectorSearch(coll *schema.DefaultCollection) (qsearch.VectorSearch, error) {
+func (runner *SearchQueryRunner) getVectorSearch(ctx context.Context, coll *schema.DefaultCollection) (qsearch.VectorSearch, error) {
 	vectorSearch, err := qsearch.UnmarshalVectorSearch(runner.req.Vector)
 	if err != nil {
 		return vectorSearch, err
@@ -310,9 +367,49 @@ func (runner *SearchQueryRunner) getVectorSearch(coll *schema.DefaultCollection)
 	if f.DataType != schema.VectorType {
 		return qsearch.VectorSearch{}, errors.InvalidArgument("Cannot perform vector search on non-vector type, field `%s` is not a vector", f.FieldName)
 	}
+	if len(vectorSearch.Text) > 0 {
+		if f.Embedding == nil {
+			return qsearch.VectorSearch{}, errors.InvalidArgument("Cannot perform vector search using text, field `%s` has no embedding", f.FieldName)
+		}
+
+		provider, err := embedding.Get(f.Embedding.Provider)
+		if err != nil {
+			return qsearch.VectorSearch{}, errors.Internal(err.Error())
+		}
+		if err = vectorSearch.Embed(ctx, provider, *f.Dimensions); err != nil {
+			return qsearch.VectorSearch{}, errors.Internal("embedding the query text failed: %s", err.Error())
+		}
+	}
 	if f.Dimensions != nil && *f.Dimensions != len(vectorSearch.VectorV) {
 		return qsearch.VectorSearch{}, errors.InvalidArgument("query vector is not same size as dimensions, expected size: %d", *f.Dimensions)
 	}
 
 	return vectorSearch, nil
 }
+
+func (runner *SearchQueryRunner) getHighlight(coll *schema.DefaultCollection) (*qsearch.Highlight, error) {
+	highlight, err := qsearch.UnmarshalHighlight(runner.req.Highlight)
+	if err != nil || highlight == nil {
+		return nil, err
+	}
+
+	for _, fields := range [][]string{highlight.Fields, highlight.FullFields} {
+		for i, hf := range fields {
+			cf, err := coll.GetQueryableField(hf)
+			if err != nil {
+				return nil, err
+			}
+			if !cf.SearchIndexed || (cf.DataType != schema.StringType && cf.SubType != schema.StringType) {
+				return nil, errors.InvalidArgument("Cannot highlight `%s`. Only searchable string fields can be highlighted", hf)
+			}
+			if cf.InMemoryName() != cf.Name() {
+				fields[i] = cf.InMemoryName()
+			}
+		}
+	}
+	if highlight.SnippetLength != nil && *highlight.SnippetLength < 0 {
+		return nil, errors.InvalidArgument("Highlight snippet length cannot be negative")
+	}
+
+	return highlight, nil
+}
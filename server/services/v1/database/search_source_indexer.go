@@ -0,0 +1,287 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+	"github.com/tigrisdata/tigris/store/search"
+	"github.com/tigrisdata/tigris/util"
+	"github.com/tigrisdata/tigris/value"
+)
+
+// lookupPageSize is the number of documents of a search index read at once when looking for the documents that
+// reference a mutated document of a related collection.
+const lookupPageSize = 250
+
+// indexSearchSources keeps the search indexes tagged Tigris as source in sync with a mutation of the collection. The
+// documents of the indexes sourced from the collection are indexed with their looked up documents, and the documents
+// of the indexes looking up the collection are updated with the mutated document.
+func (i *SearchIndexer) indexSearchSources(ctx context.Context, db *metadata.Database, collection *schema.DefaultCollection,
+	event *kv.Event, searchKey string,
+) error {
+	for _, index := range collection.SearchIndexes {
+		var err error
+		if event.Op == kv.DeleteEvent {
+			err = i.deleteDocument(ctx, index.StoreIndexName(), searchKey)
+		} else {
+			err = i.indexSourceDocument(ctx, db, index, event.Data, searchKey)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, index := range collection.LookupSearchIndexes {
+		for _, l := range index.Source.GetLookups(collection.Name) {
+			if err := i.updateLookups(ctx, index, l, event); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (i *SearchIndexer) indexSourceDocument(ctx context.Context, db *metadata.Database, index *schema.SearchIndex,
+	data *internal.TableData, searchKey string,
+) error {
+	if len(index.Source.Lookups) > 0 {
+		doc, err := util.JSONToMap(data.RawData)
+		if err != nil {
+			return err
+		}
+
+		tx, err := i.txMgr.StartTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+
+		for _, l := range index.Source.Lookups {
+			related, err := i.lookup(ctx, tx, db, l, data.RawData)
+			if err != nil {
+				return err
+			}
+
+			if related != nil {
+				doc[l.As] = related
+			} else {
+				delete(doc, l.As)
+			}
+		}
+
+		raw, err := util.MapToJSON(doc)
+		if err != nil {
+			return err
+		}
+
+		data = internal.NewTableDataWithTS(data.CreatedAt, data.UpdatedAt, raw)
+	}
+
+	// the event carries the whole document so it is always replaced
+	return i.indexDocument(ctx, data, index.QueryableFields, index.StoreIndexName(), searchKey, search.Replace)
+}
+
+// lookup reads the related document referenced by the source document, it returns nil if the source document doesn't
+// reference any or if the related document doesn't exist.
+func (i *SearchIndexer) lookup(ctx context.Context, tx transaction.Tx, db *metadata.Database, l schema.SearchLookup,
+	source []byte,
+) (map[string]any, error) {
+	related := db.GetCollection(l.CollectionName)
+	if related == nil || len(related.GetPrimaryKey().Fields) != 1 {
+		return nil, nil
+	}
+
+	jsonVal, dtp, _, err := jsonparser.Get(source, l.LocalField)
+	if err != nil || dtp == jsonparser.Null {
+		// the field is not set
+		return nil, nil //nolint:nilerr
+	}
+
+	v, err := value.NewValue(related.GetPrimaryKey().Fields[0].Type(), jsonVal)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := i.tenantMgr.GetEncoder().EncodeKey(related.EncodedName, related.GetPrimaryKey(), []any{v.AsInterface()})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readDoc(ctx, tx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	return lookupFields(data.RawData, l.Fields)
+}
+
+// updateLookups updates the documents of the index referencing the mutated document of the related collection.
+func (i *SearchIndexer) updateLookups(ctx context.Context, index *schema.SearchIndex, l schema.SearchLookup, event *kv.Event) error {
+	if len(event.Key) != 2 {
+		// the related collection is looked up by a single primary key field
+		return nil
+	}
+
+	ids, err := i.findReferencing(ctx, index, l, event.Key[1])
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	var related map[string]any
+	if event.Op != kv.DeleteEvent {
+		if related, err = lookupFields(event.Data.RawData, l.Fields); err != nil {
+			return err
+		}
+	}
+
+	partial, err := packLookupFields(index.QueryableFields, l, related)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, id := range ids {
+		partial[schema.SearchId] = id
+
+		encoded, err := util.MapToJSON(partial)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+		buf.WriteByte('\n')
+	}
+
+	resp, err := i.searchStore.IndexDocuments(ctx, index.StoreIndexName(), &buf, search.IndexDocumentsOptions{
+		Action:    search.Update,
+		BatchSize: len(ids),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range resp {
+		// the document may have been deleted concurrently
+		if !r.Success && r.Code != http.StatusNotFound {
+			return search.NewSearchError(r.Code, search.ErrCodeUnhandled, r.Error)
+		}
+	}
+
+	return nil
+}
+
+// findReferencing returns the ids of the documents of the index whose local field is set to the key.
+func (i *SearchIndexer) findReferencing(ctx context.Context, index *schema.SearchIndex, l schema.SearchLookup, key any) ([]string, error) {
+	reqFilter, err := jsoniter.Marshal(map[string]any{l.LocalField: key})
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedF, err := filter.NewFactory(index.QueryableFields, nil).WrappedFilter(reqFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := qsearch.NewBuilder().Filter(wrappedF).PageSize(lookupPageSize).Build()
+
+	var ids []string
+	for page := 1; ; page++ {
+		results, err := i.searchStore.Search(ctx, index.StoreIndexName(), query, page)
+		if err != nil {
+			return nil, err
+		}
+
+		hits := 0
+		for _, r := range results {
+			for _, hit := range r.Hits {
+				if id, ok := hit.Document[schema.SearchId].(string); ok {
+					ids = append(ids, id)
+				}
+			}
+			hits += len(r.Hits)
+		}
+
+		if hits < lookupPageSize {
+			return ids, nil
+		}
+	}
+}
+
+// lookupFields returns the fields of the related document that are set in the documents of the index.
+func lookupFields(raw []byte, fields []string) (map[string]any, error) {
+	doc, err := util.JSONToMap(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) == 0 {
+		return doc, nil
+	}
+
+	projected := make(map[string]any, len(fields))
+	for _, f := range fields {
+		if v, ok := doc[f]; ok {
+			projected[f] = v
+		}
+	}
+
+	return projected, nil
+}
+
+// packLookupFields returns the partial update of the index documents with the related document. The fields of the
+// lookup that are not set in the related document, or all of them if it is deleted, are removed from the documents.
+func packLookupFields(fields []*schema.QueryableField, l schema.SearchLookup, related map[string]any) (map[string]any, error) {
+	prefix := l.As + util.ObjFlattenDelimiter
+
+	var asFields []*schema.QueryableField
+	for _, f := range fields {
+		if strings.HasPrefix(f.Name(), prefix) {
+			asFields = append(asFields, f)
+		}
+	}
+
+	doc := make(map[string]any)
+	if related != nil {
+		doc[l.As] = related
+	}
+
+	doc = flattenSearchFields(doc, asFields)
+	if err := packFlattenedFields(doc, asFields); err != nil {
+		return nil, err
+	}
+	// the null keys marker belongs to the whole document, it can't be partially updated
+	delete(doc, schema.ReservedFields[schema.SearchNullKeys])
+
+	for _, f := range asFields {
+		if _, ok := doc[f.Name()]; !ok {
+			doc[f.Name()] = nil
+		}
+	}
+
+	return doc, nil
+}
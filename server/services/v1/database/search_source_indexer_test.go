@@ -0,0 +1,59 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+func TestLookupFields(t *testing.T) {
+	raw := []byte(`{"id": "b1", "name": "Acme", "country": "US"}`)
+
+	doc, err := lookupFields(raw, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"id": "b1", "name": "Acme", "country": "US"}, doc)
+
+	doc, err = lookupFields(raw, []string{"name", "missing"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "Acme"}, doc)
+}
+
+func TestPackLookupFields(t *testing.T) {
+	fields := []*schema.QueryableField{
+		{FieldName: "name", DataType: schema.StringType},
+		{FieldName: "brand_id", DataType: schema.StringType},
+		{FieldName: "brand.name", DataType: schema.StringType},
+		{FieldName: "brand.country", DataType: schema.StringType},
+		{FieldName: "brand.founded", DataType: schema.DateTimeType},
+	}
+	l := schema.SearchLookup{CollectionName: "brands", LocalField: "brand_id", As: "brand"}
+
+	doc, err := packLookupFields(fields, l, map[string]any{"name": "Acme", "founded": "2020-01-01T00:00:00Z"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"brand.name":                            "Acme",
+		"brand.country":                         nil,
+		"brand.founded":                         int64(1577836800000000000),
+		schema.ToSearchDateKey("brand.founded"): "2020-01-01T00:00:00Z",
+	}, doc)
+
+	// deleted
+	doc, err = packLookupFields(fields, l, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"brand.name": nil, "brand.country": nil, "brand.founded": nil}, doc)
+}
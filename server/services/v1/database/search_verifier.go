@@ -0,0 +1,441 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/apple/foundationdb/bindings/go/src/fdb/tuple"
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/keys"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/kv"
+	"github.com/tigrisdata/tigris/store/search"
+)
+
+// verifyBatchSize is the number of search documents fetched at once to be compared with the primary rows.
+const verifyBatchSize = 100
+
+// SearchVerifier compares the implicit search index of a collection with its primary rows. A search document is
+// missing if the row has no document with its id, stale if the document has not been indexed from the current version
+// of the row, and orphaned if its row doesn't exist. The missing and stale documents are optionally indexed again from
+// the rows and the orphaned ones removed.
+type SearchVerifier struct {
+	coll        *schema.DefaultCollection
+	txMgr       *transaction.Manager
+	searchStore search.Store
+	encoder     metadata.Encoder
+	repair      bool
+}
+
+func NewSearchVerifier(coll *schema.DefaultCollection, txMgr *transaction.Manager, searchStore search.Store, repair bool) *SearchVerifier {
+	return &SearchVerifier{
+		coll:        coll,
+		txMgr:       txMgr,
+		searchStore: searchStore,
+		encoder:     metadata.NewEncoder(),
+		repair:      repair,
+	}
+}
+
+// verifyRow is a primary row of a batch, or the key of the missing row of an orphan, with the id of its search
+// document.
+type verifyRow struct {
+	id   string
+	key  keys.Key
+	data *internal.TableData
+}
+
+// VerifyCollection scans the primary rows of the collection in key order, then the search documents of the collection,
+// and returns the drift report. The progressUpdate is called after every batch of rows or documents.
+func (v *SearchVerifier) VerifyCollection(ctx context.Context, progressUpdate func(context.Context) error) (*metadata.SearchDriftReport, error) {
+	report := &metadata.SearchDriftReport{StartedAt: time.Now().UTC()}
+
+	var last []byte
+	for {
+		batch, lastInBatch, err := v.scan(ctx, last)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		last = lastInBatch
+
+		if err = v.verify(ctx, batch, report); err != nil {
+			return nil, err
+		}
+
+		if progressUpdate != nil {
+			if err = progressUpdate(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := v.verifyOrphans(ctx, report, progressUpdate); err != nil {
+		return nil, err
+	}
+
+	report.CompletedAt = time.Now().UTC()
+	log.Info().Msgf("Search index of collection '%s' verified, %d documents missing, %d stale, %d orphaned, %d repaired",
+		v.coll.Name, report.Missing, report.Stale, report.Orphaned, report.Repaired)
+
+	return report, nil
+}
+
+// scan returns the next batch of rows after the last key.
+func (v *SearchVerifier) scan(ctx context.Context, last []byte) ([]verifyRow, []byte, error) {
+	tx, err := v.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	iter, err := createBulkDocsReader(ctx, tx, v.coll.EncodedName, nil, last)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		row   Row
+		batch []verifyRow
+	)
+	for len(batch) < verifyBatchSize && iter.Next(&row) {
+		if last != nil && bytes.Equal(row.Key, last) {
+			// the scan is starting from the last key of the previous batch
+			continue
+		}
+
+		key, err := keys.FromBinary(v.coll.EncodedName, row.Key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		id, err := CreateSearchKey(kv.BuildKey(key.IndexParts()...))
+		if err != nil {
+			return nil, nil, err
+		}
+		batch = append(batch, verifyRow{id: id, key: key, data: row.Data})
+		last = row.Key
+	}
+
+	return batch, last, iter.Interrupted()
+}
+
+func (v *SearchVerifier) verify(ctx context.Context, batch []verifyRow, report *metadata.SearchDriftReport) error {
+	storeIndex := v.coll.GetImplicitSearchIndex().StoreIndexName()
+
+	ids := make([]string, 0, len(batch))
+	for _, r := range batch {
+		ids = append(ids, r.id)
+	}
+
+	result, err := v.searchStore.GetDocuments(ctx, storeIndex, ids)
+	if err != nil {
+		return err
+	}
+
+	indexed := make(map[string]map[string]any, len(result.Hits))
+	for _, hit := range result.Hits {
+		if id, ok := hit.Document[schema.SearchId].(string); ok {
+			indexed[id] = hit.Document
+		}
+	}
+
+	var drifted []verifyRow
+	for _, r := range batch {
+		report.Scanned++
+
+		doc, found := indexed[r.id]
+		if found && indexedVersion(doc) == rowVersion(r.data) {
+			continue
+		}
+
+		report.AddDrifted(r.id, !found)
+		drifted = append(drifted, r)
+	}
+
+	if !v.repair || len(drifted) == 0 {
+		return nil
+	}
+
+	if drifted, err = v.reread(ctx, drifted); err != nil || len(drifted) == 0 {
+		return err
+	}
+
+	repaired, err := v.reindex(ctx, storeIndex, drifted)
+	report.Repaired += repaired
+
+	return err
+}
+
+// reread reads the drifted rows again in a new transaction right before they are reindexed. The rows that have been
+// deleted or written since the scan are dropped, the writes have updated their search documents and these must not be
+// replaced with the versions read by the scan.
+func (v *SearchVerifier) reread(ctx context.Context, rows []verifyRow) ([]verifyRow, error) {
+	tx, err := v.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	unchanged := rows[:0]
+	for _, r := range rows {
+		data, err := readDoc(ctx, tx, r.key)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil || rowVersion(data) != rowVersion(r.data) {
+			continue
+		}
+		unchanged = append(unchanged, verifyRow{id: r.id, key: r.key, data: data})
+	}
+
+	return unchanged, nil
+}
+
+// reindex replaces the search documents of the rows and returns the number of documents indexed.
+func (v *SearchVerifier) reindex(ctx context.Context, storeIndex string, rows []verifyRow) (int64, error) {
+	var buf bytes.Buffer
+	for _, r := range rows {
+		searchData, err := packSearchFields(ctx, r.data, v.coll.QueryableFields, r.id)
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(searchData)
+		buf.WriteByte('\n')
+	}
+
+	resp, err := v.searchStore.IndexDocuments(ctx, storeIndex, &buf, search.IndexDocumentsOptions{
+		Action:    search.Replace,
+		BatchSize: len(rows),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var repaired int64
+	for _, r := range resp {
+		if !r.Success {
+			return repaired, search.NewSearchError(r.Code, search.ErrCodeUnhandled, r.Error)
+		}
+		repaired++
+	}
+
+	return repaired, nil
+}
+
+// verifyOrphans pages through the search documents of the collection and reports the ones whose row doesn't exist. The
+// orphans are only removed once all the pages are read, so that the removals don't shift the pages.
+func (v *SearchVerifier) verifyOrphans(ctx context.Context, report *metadata.SearchDriftReport, progressUpdate func(context.Context) error) error {
+	storeIndex := v.coll.GetImplicitSearchIndex().StoreIndexName()
+	query := qsearch.NewBuilder().PageSize(verifyBatchSize).Build()
+
+	var orphans []verifyRow
+	for page := 1; ; page++ {
+		results, err := v.searchStore.Search(ctx, storeIndex, query, page)
+		if err != nil {
+			return err
+		}
+
+		var (
+			ids  []string
+			hits int
+		)
+		for _, r := range results {
+			for _, hit := range r.Hits {
+				if id, ok := hit.Document[schema.SearchId].(string); ok {
+					ids = append(ids, id)
+				}
+			}
+			hits += len(r.Hits)
+		}
+
+		found, err := v.orphans(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, o := range found {
+			report.AddOrphaned(o.id)
+		}
+		orphans = append(orphans, found...)
+
+		if progressUpdate != nil {
+			if err = progressUpdate(ctx); err != nil {
+				return err
+			}
+		}
+
+		if hits < verifyBatchSize {
+			break
+		}
+	}
+
+	if !v.repair || len(orphans) == 0 {
+		return nil
+	}
+
+	removed, err := v.removeOrphans(ctx, storeIndex, orphans)
+	report.Repaired += removed
+
+	return err
+}
+
+// orphans returns the search documents of the ids whose row doesn't exist. A document whose id isn't a primary key of
+// the collection is an orphan too, its key is nil.
+func (v *SearchVerifier) orphans(ctx context.Context, ids []string) ([]verifyRow, error) {
+	tx, err := v.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var orphans []verifyRow
+	for _, id := range ids {
+		parts, err := parseSearchKey(v.coll.GetPrimaryKey(), id)
+		if err != nil {
+			orphans = append(orphans, verifyRow{id: id})
+			continue
+		}
+
+		key, err := v.encoder.EncodeKey(v.coll.EncodedName, v.coll.GetPrimaryKey(), parts)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := readDoc(ctx, tx, key)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			orphans = append(orphans, verifyRow{id: id, key: key})
+		}
+	}
+
+	return orphans, nil
+}
+
+// removeOrphans reads the rows of the orphans again in a new transaction and removes the search documents of the rows
+// still missing, the rows written since the scan have been indexed again. It returns the number of documents removed.
+func (v *SearchVerifier) removeOrphans(ctx context.Context, storeIndex string, orphans []verifyRow) (int64, error) {
+	tx, err := v.txMgr.StartTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var removed int64
+	for _, o := range orphans {
+		if o.key != nil {
+			data, err := readDoc(ctx, tx, o.key)
+			if err != nil {
+				return removed, err
+			}
+			if data != nil {
+				continue
+			}
+		}
+
+		if err = v.searchStore.DeleteDocument(ctx, storeIndex, o.id); err != nil && !search.IsErrNotFound(err) {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// parseSearchKey returns the primary key parts of the search id, it reverses CreateSearchKey.
+func parseSearchKey(pk *schema.Index, id string) ([]any, error) {
+	if len(pk.Fields) != 1 {
+		packed, err := base64.StdEncoding.DecodeString(id)
+		if err != nil {
+			return nil, err
+		}
+
+		tp, err := tuple.Unpack(packed)
+		if err != nil {
+			return nil, err
+		}
+
+		parts := make([]any, 0, len(tp))
+		for _, t := range tp {
+			parts = append(parts, t)
+		}
+		return parts, nil
+	}
+
+	switch pk.Fields[0].Type() {
+	case schema.Int32Type, schema.Int64Type:
+		n, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return []any{n}, nil
+	case schema.ByteType:
+		b, err := base64.StdEncoding.DecodeString(id)
+		if err != nil {
+			return nil, err
+		}
+		return []any{b}, nil
+	default:
+		return []any{id}, nil
+	}
+}
+
+// rowVersion returns the time of the last write of the row in nanoseconds, the search documents are indexed with it.
+func rowVersion(data *internal.TableData) int64 {
+	if data.UpdatedAt != nil {
+		return data.UpdatedAt.UnixNano()
+	}
+
+	return data.CreatedAt.UnixNano()
+}
+
+// indexedVersion returns the time of the last write of the row the search document has been indexed from.
+func indexedVersion(doc map[string]any) int64 {
+	if ts, ok := nanoValue(doc[schema.ReservedFields[schema.UpdatedAt]]); ok {
+		return ts
+	}
+
+	ts, _ := nanoValue(doc[schema.ReservedFields[schema.CreatedAt]])
+	return ts
+}
+
+// nanoValue returns the timestamp in nanoseconds. A float64 can't hold it exactly, so it isn't accepted and the document
+// is reported as drifted rather than compared with a rounded value.
+func nanoValue(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case json.Number:
+		ts, err := n.Int64()
+		return ts, err == nil
+	}
+
+	return 0, false
+}
@@ -0,0 +1,78 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+func TestSearchDocumentVersion(t *testing.T) {
+	created := internal.CreateNewTimestamp(1000)
+	updated := internal.CreateNewTimestamp(2000)
+
+	require.Equal(t, int64(1000), rowVersion(internal.NewTableDataWithTS(created, nil, nil)))
+	require.Equal(t, int64(2000), rowVersion(internal.NewTableDataWithTS(created, updated, nil)))
+
+	createdKey, updatedKey := schema.ReservedFields[schema.CreatedAt], schema.ReservedFields[schema.UpdatedAt]
+
+	// both the search stores decode the numbers of the documents as json.Number
+	require.Equal(t, int64(1000), indexedVersion(map[string]any{createdKey: int64(1000)}))
+	require.Equal(t, int64(2000), indexedVersion(map[string]any{createdKey: int64(1000), updatedKey: json.Number("2000")}))
+	require.Equal(t, int64(0), indexedVersion(map[string]any{}))
+
+	nanos := time.Date(2023, 5, 17, 10, 30, 15, 123456789, time.UTC).UnixNano()
+	row := internal.NewTableDataWithTS(internal.CreateNewTimestamp(nanos), nil, nil)
+	require.Equal(t, rowVersion(row), indexedVersion(map[string]any{createdKey: json.Number(strconv.FormatInt(nanos, 10))}))
+
+	// a float64 rounds the timestamp, so the version is unknown and the document is reindexed
+	require.NotEqual(t, nanos, int64(float64(nanos)))
+	require.Equal(t, int64(0), indexedVersion(map[string]any{createdKey: float64(nanos)}))
+}
+
+func TestParseSearchKey(t *testing.T) {
+	field := func(tp schema.FieldType) *schema.Field {
+		return &schema.Field{FieldName: "id", DataType: tp}
+	}
+
+	cases := []struct {
+		fields []*schema.Field
+		parts  []any
+	}{
+		{[]*schema.Field{field(schema.Int64Type)}, []any{int64(-42)}},
+		{[]*schema.Field{field(schema.StringType)}, []any{"a/b c"}},
+		{[]*schema.Field{field(schema.UUIDType)}, []any{"9a4ad1c3-b7d4-4aa1-a2f5-1ba2e4ac4e4f"}},
+		{[]*schema.Field{field(schema.ByteType)}, []any{[]byte{0, 1, 0xff}}},
+		{[]*schema.Field{field(schema.StringType), field(schema.Int64Type)}, []any{"a", int64(1)}},
+	}
+	for _, c := range cases {
+		id, err := CreateSearchKey(kv.BuildKey(append([]any{"pkey"}, c.parts...)...))
+		require.NoError(t, err)
+
+		parts, err := parseSearchKey(&schema.Index{Fields: c.fields}, id)
+		require.NoError(t, err)
+		require.Equal(t, c.parts, parts)
+	}
+
+	_, err := parseSearchKey(&schema.Index{Fields: []*schema.Field{field(schema.Int64Type)}}, "abc")
+	require.Error(t, err)
+}
@@ -73,6 +73,11 @@ func (r *SecondaryIndexReaderImpl) createIter() (*SecondaryIndexReaderImpl, erro
 		if err != nil {
 			return nil, err
 		}
+	case filter.MULTIRANGE:
+		r.kvIter, err = NewMultiRangeIterator(r.ctx, r.tx, r.queryPlan.Keys)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, errors.InvalidArgument("Incorrectly created query key range")
 	}
@@ -116,6 +121,13 @@ func BuildSecondaryIndexKeys(coll *schema.DefaultCollection, queryFilters []filt
 		}
 	}
 
+	if sortQueryPlan == nil {
+		// the documents are returned in the geohash order, so the geo plan can't be combined with a sort
+		if geoPlan, err := filter.QueryPlanFromGeoFilter(queryFilters, indexeableFields, encoder, buildIndexParts, filter.SecondaryIndex); err == nil {
+			return geoPlan, nil
+		}
+	}
+
 	rangKeyBuilder := filter.NewRangeKeyBuilder(filter.NewRangeKeyComposer(encoder, buildIndexParts, filter.SecondaryIndex), filter.SecondaryIndex)
 	rangePlans, err := rangKeyBuilder.Build(queryFilters, indexeableFields)
 	// If we could not find a range query plan then fall back to the sort plan if we have one
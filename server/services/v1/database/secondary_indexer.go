@@ -17,6 +17,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/buger/jsonparser"
@@ -24,6 +25,7 @@ import (
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/keys"
+	"github.com/tigrisdata/tigris/lib/geo"
 	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/metrics"
 	"github.com/tigrisdata/tigris/server/transaction"
@@ -559,6 +561,16 @@ func (q *SecondaryIndexerImpl) indexField(doc []byte, fieldName string, dataType
 		return newNullRow(fieldName, 0), nil
 	}
 
+	if dataType == schema.GeoPointType {
+		// a geopoint is indexed by its geohash so that an area can be read as a few ranges of the index
+		point, err := geo.ParsePoint(val)
+		if err != nil {
+			return nil, err
+		}
+
+		dataType, val = schema.Int64Type, []byte(strconv.FormatInt(geo.Encode(point), 10))
+	}
+
 	row, err := newIndexRow(dataType, q.collation, fieldName, val, pos, false)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,269 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/query/sql"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/metrics"
+	"github.com/tigrisdata/tigris/server/request"
+	"github.com/tigrisdata/tigris/server/transaction"
+	ulog "github.com/tigrisdata/tigris/util/log"
+)
+
+// aggregateTimeLimit is how long an aggregate query reads the documents before it gives up. It is kept below the
+// 5 seconds limit of the FDB transaction so that the query fails with a clear error instead of the transaction
+// being aborted in the middle of the read.
+const aggregateTimeLimit = 4 * time.Second
+
+// toReadRequest converts the translated SQL query to the read request, the read request is then planned the same way
+// as any other read i.e. it uses the primary key or secondary indexes when possible. For an aggregate query, the
+// sort, limit and offset are applied on the groups and not on the documents, so they are not part of the read.
+func toReadRequest(req *api.QueryRequest, q *sql.Query) *api.ReadRequest {
+	readReq := &api.ReadRequest{
+		Project:    req.GetProject(),
+		Branch:     req.GetBranch(),
+		Collection: q.Collection,
+		Filter:     q.Filter,
+		Fields:     q.Fields,
+	}
+
+	if !q.IsAggregate() {
+		readReq.Sort = q.Sort
+		if q.Limit > 0 || q.Offset > 0 {
+			readReq.Options = &api.ReadRequestOptions{
+				Limit: q.Limit,
+				Skip:  q.Offset,
+			}
+		}
+	}
+
+	return readReq
+}
+
+// SQLQueryRunner executes a SQL SELECT statement. A non-aggregate query is translated to a read request and
+// executed by the streaming query runner. An aggregate query reads the matching documents and groups them in
+// memory before streaming one document per group.
+type SQLQueryRunner struct {
+	*BaseQueryRunner
+
+	req          *api.QueryRequest
+	streaming    Streaming
+	queryMetrics *metrics.StreamingQueryMetrics
+}
+
+func (runner *SQLQueryRunner) translate() (*sql.Query, *api.ReadRequest, error) {
+	q, err := sql.Translate(runner.req.GetQuery())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return q, toReadRequest(runner.req, q), nil
+}
+
+func (runner *SQLQueryRunner) readRunner(readReq *api.ReadRequest) *StreamingQueryRunner {
+	return &StreamingQueryRunner{
+		BaseQueryRunner: runner.BaseQueryRunner,
+		req:             readReq,
+		streaming:       runner.streaming,
+		queryMetrics:    runner.queryMetrics,
+	}
+}
+
+// ReadOnly executes the query outside an explicit transaction.
+func (runner *SQLQueryRunner) ReadOnly(ctx context.Context, tenant *metadata.Tenant) (Response, context.Context, error) {
+	q, readReq, err := runner.translate()
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	if !q.IsAggregate() {
+		return runner.readRunner(readReq).ReadOnly(ctx, tenant)
+	}
+
+	tx, err := runner.txMgr.StartTx(ctx)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	return runner.aggregate(ctx, tx, tenant, q, readReq)
+}
+
+// Run executes the query in the transaction started by the session manager.
+func (runner *SQLQueryRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	q, readReq, err := runner.translate()
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	if !q.IsAggregate() {
+		return runner.readRunner(readReq).Run(ctx, tx, tenant)
+	}
+
+	return runner.aggregate(ctx, tx, tenant, q, readReq)
+}
+
+func (runner *SQLQueryRunner) aggregate(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant, q *sql.Query, readReq *api.ReadRequest) (Response, context.Context, error) {
+	db, coll, err := runner.getDBAndCollection(ctx, tx, tenant, readReq.GetProject(), readReq.GetCollection(), readReq.GetBranch())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	ctx = runner.cdcMgr.WrapContext(ctx, db.Name())
+
+	// the documents are grouped and ordered in memory, the plan is built without a sort so that it always reads
+	// from the primary or the secondary indexes and never from search
+	options, err := runner.buildReaderOptions(&api.ReadRequest{
+		Project:    readReq.GetProject(),
+		Branch:     readReq.GetBranch(),
+		Collection: readReq.GetCollection(),
+		Filter:     readReq.GetFilter(),
+		Fields:     readReq.GetFields(),
+	}, coll)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	ctx = runner.readRunner(readReq).instrumentRunner(ctx, options)
+
+	rows, err := runner.group(ctx, tx, coll, q, options)
+	if err != nil {
+		return Response{}, ctx, CreateApiError(err)
+	}
+
+	if request.IsAcceptApplicationJSON(ctx) {
+		buffResponse := make([]jsoniter.RawMessage, 0, len(rows))
+		for _, row := range rows {
+			buffResponse = append(buffResponse, row)
+		}
+
+		marshaled, err := jsoniter.Marshal(buffResponse)
+		if err != nil {
+			return Response{}, ctx, err
+		}
+
+		if err = runner.streaming.Send(&api.ReadResponse{Data: marshaled}); ulog.E(err) {
+			return Response{}, ctx, err
+		}
+		return Response{}, ctx, nil
+	}
+
+	for _, row := range rows {
+		if err = runner.streaming.Send(&api.ReadResponse{Data: row}); ulog.E(err) {
+			return Response{}, ctx, err
+		}
+	}
+
+	return Response{}, ctx, nil
+}
+
+func (runner *SQLQueryRunner) group(ctx context.Context, tx transaction.Tx, coll *schema.DefaultCollection, q *sql.Query, options readerOptions) ([][]byte, error) {
+	iterator, err := runner.buildKvIterator(ctx, tx, coll, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		row     Row
+		scanned int64
+		start   = time.Now()
+		grouper = sql.NewGrouper(q).WithDecimalFields(decimalScales(coll))
+	)
+	for iterator.Next(&row) {
+		if time.Since(start) > aggregateTimeLimit {
+			return nil, errors.DeadlineExceeded("aggregate query read %d documents without finishing within the "+
+				"transaction time limit, narrow the filter to read fewer documents", scanned)
+		}
+		scanned++
+
+		data := row.Data.RawData
+		if !coll.CompatibleSchemaSince(uint32(row.Data.Ver)) {
+			if data, err = coll.UpdateRowSchemaRaw(data, uint32(row.Data.Ver)); err != nil {
+				return nil, err
+			}
+		}
+
+		if data, err = options.fieldFactory.Apply(data); err != nil {
+			return nil, err
+		}
+
+		if err = grouper.Add(data); err != nil {
+			return nil, err
+		}
+	}
+	if err = iterator.Interrupted(); err != nil {
+		return nil, err
+	}
+
+	return grouper.Result()
+}
+
+func decimalScales(coll *schema.DefaultCollection) map[string]int32 {
+	scales := make(map[string]int32)
+	for _, f := range coll.QueryableFields {
+		if f.DataType == schema.DecimalType {
+			scales[f.Name()] = int32(f.Scale)
+		}
+	}
+
+	return scales
+}
+
+// SQLExplainQueryRunner returns the read request the SQL query is translated to along with the plan of the read.
+type SQLExplainQueryRunner struct {
+	*BaseQueryRunner
+
+	req *api.QueryRequest
+}
+
+func (runner *SQLExplainQueryRunner) Run(ctx context.Context, _ transaction.Tx, tenant *metadata.Tenant) (Response, context.Context, error) {
+	q, err := sql.Translate(runner.req.GetQuery())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	readReq := toReadRequest(runner.req, q)
+	db, err := runner.getDatabase(ctx, nil, tenant, readReq.GetProject(), readReq.GetBranch())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	ctx = runner.cdcMgr.WrapContext(ctx, db.Name())
+
+	collection, err := runner.getCollection(db, readReq.GetCollection())
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	options, err := runner.buildReaderOptions(readReq, collection)
+	if err != nil {
+		return Response{}, ctx, err
+	}
+
+	explain := buildExplainResp(options, collection, q.Filter, q.Sort)
+	explain.Fields = string(q.Fields)
+
+	return Response{
+		Response: explain,
+	}, ctx, nil
+}
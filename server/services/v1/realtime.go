@@ -15,14 +15,17 @@
 package v1
 
 import (
+	"bytes"
 	"context"
-	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 
 	"github.com/fullstorydev/grpchan/inprocgrpc"
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/websocket"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/rs/zerolog/log"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
@@ -40,6 +43,26 @@ const (
 	realtimePathPattern = fullProjectPath + "/realtime/*"
 )
 
+var (
+	realtimeChannelsOnce sync.Once
+	realtimeCache        cache.Cache
+	realtimeHeartbeatF   *realtime.HeartbeatFactory
+	realtimeChannelF     *realtime.ChannelFactory
+)
+
+// getRealtimeChannels returns the cache and the channel factory of the server. They are shared by the services of the
+// server, so that the changes of the bridged collections are published through the same channels as the messages.
+func getRealtimeChannels() (cache.Cache, *realtime.HeartbeatFactory, *realtime.ChannelFactory) {
+	realtimeChannelsOnce.Do(func() {
+		encoder := metadata.NewCacheEncoder()
+		realtimeCache = cache.NewCache(&config.DefaultConfig.Cache)
+		realtimeHeartbeatF = realtime.NewHeartbeatFactory(realtimeCache, encoder)
+		realtimeChannelF = realtime.NewChannelFactory(realtimeCache, encoder, realtimeHeartbeatF)
+	})
+
+	return realtimeCache, realtimeHeartbeatF, realtimeChannelF
+}
+
 type realtimeService struct {
 	api.UnimplementedRealtimeServer
 
@@ -49,15 +72,17 @@ type realtimeService struct {
 }
 
 func newRealtimeService(_ kv.TxStore, _ search.Store, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager) *realtimeService {
-	cacheS := cache.NewCache(&config.DefaultConfig.Cache)
+	cacheS, heartbeatF, channelFactory := getRealtimeChannels()
 	encoder := metadata.NewCacheEncoder()
-	heartbeatF := realtime.NewHeartbeatFactory(cacheS, encoder)
-	channelFactory := realtime.NewChannelFactory(cacheS, encoder, heartbeatF)
+	authorizer := realtime.NewChannelAuthorizer(txMgr)
+	if config.DefaultConfig.Realtime.WebhooksEnabled {
+		realtime.NewWebhookDispatcher(cacheS, encoder, tenantMgr, txMgr, channelFactory)
+	}
 
 	return &realtimeService{
 		cache:     cacheS,
-		rtmRunner: realtime.NewRTMRunnerFactory(cacheS, channelFactory),
-		devices:   realtime.NewSessionMgr(cacheS, tenantMgr, txMgr, heartbeatF, channelFactory),
+		rtmRunner: realtime.NewRTMRunnerFactory(cacheS, channelFactory, authorizer, txMgr),
+		devices:   realtime.NewSessionMgr(cacheS, tenantMgr, txMgr, heartbeatF, channelFactory, authorizer),
 	}
 }
 
@@ -75,6 +100,8 @@ func (s *realtimeService) RegisterHTTP(router chi.Router, inproc *inprocgrpc.Cha
 	api.RegisterRealtimeServer(inproc, s)
 
 	router.HandleFunc(apiPathPrefix+"/projects/{project}/realtime", s.DeviceConnectionHandler)
+	router.Get(apiPathPrefix+"/projects/{project}/realtime/sse", s.SSEConnectionHandler)
+	router.Get(apiPathPrefix+"/projects/{project}/realtime/poll", s.PollConnectionHandler)
 	router.HandleFunc(apiPathPrefix+realtimePathPattern, func(w http.ResponseWriter, r *http.Request) {
 		mux.ServeHTTP(w, r)
 	})
@@ -104,6 +131,22 @@ func (*realtimeService) extractConnParams(r *http.Request) realtime.ConnectionPa
 
 	// query params
 	params.SessionId = r.URL.Query().Get("session_id")
+	params.ClientId = r.URL.Query().Get("client_id")
+	params.Position = r.URL.Query().Get("position")
+
+	return params
+}
+
+// extractHTTPConnParams returns the parameters of the SSE and long-poll connections. These transports only push text
+// so the events are always JSON encoded.
+func (s *realtimeService) extractHTTPConnParams(r *http.Request, transport string) realtime.ConnectionParams {
+	params := s.extractConnParams(r)
+	params.Encoding = "json"
+	params.Transport = transport
+	if len(params.SessionId) == 0 {
+		// browsers reconnect an event source with the id of the last event, which is the session id
+		params.SessionId = r.Header.Get("Last-Event-ID")
+	}
 
 	return params
 }
@@ -112,16 +155,15 @@ func (s *realtimeService) DeviceConnectionHandler(w http.ResponseWriter, r *http
 	params := s.extractConnParams(r)
 	conn, err := upgradeToSocket.Upgrade(w, r, nil)
 	if err != nil {
-		// ToDo: Change to WS errors
-		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = w.Write([]byte(fmt.Sprintf(`{"event_type": 2, "event": {"code": 1011, "message": "%s"}}`, err.Error())))
+		// the upgrader has already answered the request with the HTTP error
+		log.Err(err).Msg("upgrading the realtime connection failed")
 		return
 	}
 
 	ctx := r.Context()
 	session, err := s.devices.AddDevice(ctx, conn, params)
 	if err != nil {
-		err = realtime.SendReply(conn, params.ToEncodingType(), api.EventType_error, errors.InternalWS(err.Error()))
+		err = realtime.SendReply(conn, params.ToEncodingType(), api.EventType_error, errors.ToWSError(err))
 		log.Err(err).Msgf("failed to send error msg")
 		_ = conn.Close()
 		return
@@ -138,6 +180,98 @@ func (s *realtimeService) DeviceConnectionHandler(w http.ResponseWriter, r *http
 	_ = session.Start(ctx)
 }
 
+// SSEConnectionHandler pushes the messages of the channels in the "channel" query params as Server-Sent Events. The
+// device publishes using the REST API, and resumes the session on any transport with the session id it is connected
+// with.
+func (s *realtimeService) SSEConnectionHandler(w http.ResponseWriter, r *http.Request) {
+	params := s.extractHTTPConnParams(r, realtime.SSETransport)
+	conn, err := realtime.NewSSEConnection(w)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	session, err := s.devices.AddDevice(ctx, conn, params)
+	if err != nil {
+		err = realtime.SendReply(conn, params.ToEncodingType(), api.EventType_error, errors.ToWSError(err))
+		log.Err(err).Msgf("failed to send error msg")
+		return
+	}
+	defer func() {
+		_ = session.Close()
+		s.devices.RemoveDevice(ctx, session)
+	}()
+
+	session.Stream(ctx, conn, r.URL.Query()["channel"], params.Position)
+}
+
+// PollConnectionHandler returns the messages of the channels in the "channel" query params pushed to the session and
+// not confirmed yet, waiting for new ones if there are none. The response carries the cursor of its last message, the
+// device confirms the messages up to it by passing it in the "cursor" query param of the next poll. Without the param
+// the next poll confirms all the messages returned so far.
+func (s *realtimeService) PollConnectionHandler(w http.ResponseWriter, r *http.Request) {
+	params := s.extractHTTPConnParams(r, realtime.PollTransport)
+
+	var cursor *uint64
+	if c := r.URL.Query().Get("cursor"); len(c) > 0 {
+		parsed, err := strconv.ParseUint(c, 10, 64)
+		if err != nil {
+			writeHTTPError(w, errors.InvalidArgument("invalid cursor '%s'", c))
+			return
+		}
+		cursor = &parsed
+	}
+
+	_, messages, last, err := s.devices.Poll(r.Context(), params, r.URL.Query()["channel"], cursor)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"messages":[`)
+	for i, m := range messages {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(m)
+	}
+	buf.WriteString(`],"cursor":`)
+	buf.WriteString(strconv.FormatUint(last, 10))
+	buf.WriteByte('}')
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeHTTPError answers the SSE and long-poll requests failing before any event is pushed. The body is the error
+// event of the error, and the status is the HTTP status of its code.
+func writeHTTPError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var tErr *api.TigrisError
+	if errors.As(err, &tErr) {
+		status = api.ToHTTPCode(tErr.Code)
+	}
+
+	body, mErr := jsoniter.Marshal(struct {
+		EventType api.EventType   `json:"event_type"`
+		Event     *api.ErrorEvent `json:"event"`
+	}{
+		EventType: api.EventType_error,
+		Event:     errors.ToWSError(err),
+	})
+	if mErr != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
 func (*realtimeService) Ping(_ context.Context, _ *api.HeartbeatEvent) (*api.HeartbeatEvent, error) {
 	return &api.HeartbeatEvent{}, nil
 }
@@ -193,3 +327,146 @@ func (s *realtimeService) ListSubscriptions(ctx context.Context, req *api.ListSu
 	}
 	return resp.Response.(*api.ListSubscriptionResponse), nil
 }
+
+func (s *realtimeService) GetPresence(ctx context.Context, req *api.GetPresenceRequest) (*api.GetPresenceResponse, error) {
+	runner := s.rtmRunner.GetChannelRunner()
+	runner.SetPresenceReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.GetPresenceResponse), nil
+}
+
+func (s *realtimeService) CreateOrUpdateChannelPolicy(ctx context.Context, req *api.CreateOrUpdateChannelPolicyRequest) (*api.CreateOrUpdateChannelPolicyResponse, error) {
+	runner := s.rtmRunner.GetChannelPolicyRunner()
+	runner.SetCreateOrUpdateReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.CreateOrUpdateChannelPolicyResponse), nil
+}
+
+func (s *realtimeService) ListChannelPolicies(ctx context.Context, req *api.ListChannelPoliciesRequest) (*api.ListChannelPoliciesResponse, error) {
+	runner := s.rtmRunner.GetChannelPolicyRunner()
+	runner.SetListReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.ListChannelPoliciesResponse), nil
+}
+
+func (s *realtimeService) DeleteChannelPolicy(ctx context.Context, req *api.DeleteChannelPolicyRequest) (*api.DeleteChannelPolicyResponse, error) {
+	runner := s.rtmRunner.GetChannelPolicyRunner()
+	runner.SetDeleteReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.DeleteChannelPolicyResponse), nil
+}
+
+func (s *realtimeService) UpdateChannelRetention(ctx context.Context, req *api.UpdateChannelRetentionRequest) (*api.UpdateChannelRetentionResponse, error) {
+	runner := s.rtmRunner.GetChannelRunner()
+	runner.SetUpdateRetentionReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.UpdateChannelRetentionResponse), nil
+}
+
+func (s *realtimeService) GetChannelRetention(ctx context.Context, req *api.GetChannelRetentionRequest) (*api.GetChannelRetentionResponse, error) {
+	runner := s.rtmRunner.GetChannelRunner()
+	runner.SetGetRetentionReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.GetChannelRetentionResponse), nil
+}
+
+func (s *realtimeService) CreateOrUpdateChannelBridge(ctx context.Context, req *api.CreateOrUpdateChannelBridgeRequest) (*api.CreateOrUpdateChannelBridgeResponse, error) {
+	runner := s.rtmRunner.GetChannelBridgeRunner()
+	runner.SetCreateOrUpdateReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.CreateOrUpdateChannelBridgeResponse), nil
+}
+
+func (s *realtimeService) ListChannelBridges(ctx context.Context, req *api.ListChannelBridgesRequest) (*api.ListChannelBridgesResponse, error) {
+	runner := s.rtmRunner.GetChannelBridgeRunner()
+	runner.SetListReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.ListChannelBridgesResponse), nil
+}
+
+func (s *realtimeService) DeleteChannelBridge(ctx context.Context, req *api.DeleteChannelBridgeRequest) (*api.DeleteChannelBridgeResponse, error) {
+	runner := s.rtmRunner.GetChannelBridgeRunner()
+	runner.SetDeleteReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.DeleteChannelBridgeResponse), nil
+}
+
+func (s *realtimeService) CreateOrUpdateChannelWebhook(ctx context.Context, req *api.CreateOrUpdateChannelWebhookRequest) (*api.CreateOrUpdateChannelWebhookResponse, error) {
+	runner := s.rtmRunner.GetChannelWebhookRunner()
+	runner.SetCreateOrUpdateReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.CreateOrUpdateChannelWebhookResponse), nil
+}
+
+func (s *realtimeService) ListChannelWebhooks(ctx context.Context, req *api.ListChannelWebhooksRequest) (*api.ListChannelWebhooksResponse, error) {
+	runner := s.rtmRunner.GetChannelWebhookRunner()
+	runner.SetListReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.ListChannelWebhooksResponse), nil
+}
+
+func (s *realtimeService) DeleteChannelWebhook(ctx context.Context, req *api.DeleteChannelWebhookRequest) (*api.DeleteChannelWebhookResponse, error) {
+	runner := s.rtmRunner.GetChannelWebhookRunner()
+	runner.SetDeleteReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.DeleteChannelWebhookResponse), nil
+}
+
+func (s *realtimeService) ListWebhookDeadLetters(ctx context.Context, req *api.ListWebhookDeadLettersRequest) (*api.ListWebhookDeadLettersResponse, error) {
+	runner := s.rtmRunner.GetChannelWebhookRunner()
+	runner.SetDeadLettersReq(req)
+
+	resp, err := s.devices.ExecuteRunner(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Response.(*api.ListWebhookDeadLettersResponse), nil
+}
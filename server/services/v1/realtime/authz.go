@@ -0,0 +1,180 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/server/types"
+	"golang.org/x/exp/slices"
+)
+
+// channelPoliciesTTL is how long the channel policies of a project are cached, the changes made on other servers are
+// applied after it.
+const channelPoliciesTTL = 10 * time.Second
+
+var claimPlaceholder = regexp.MustCompile(`\{(\w+)\}`)
+
+type cachedPolicies struct {
+	policies []metadata.ChannelPolicy
+	loadedAt time.Time
+}
+
+// ChannelAuthorizer checks the channel policies of the project before a realtime client subscribes to, publishes on
+// or enters the presence of a channel.
+type ChannelAuthorizer struct {
+	sync.RWMutex
+
+	txMgr    *transaction.Manager
+	policies map[string]*cachedPolicies
+}
+
+func NewChannelAuthorizer(txMgr *transaction.Manager) *ChannelAuthorizer {
+	return &ChannelAuthorizer{
+		txMgr:    txMgr,
+		policies: make(map[string]*cachedPolicies),
+	}
+}
+
+// Authorize returns an error if the caller is not allowed to perform the action on the channel. Everything is allowed
+// when the project has no policy or when there is no access token, which means authentication is disabled.
+func (a *ChannelAuthorizer) Authorize(ctx context.Context, tenant *metadata.Tenant, project *metadata.Project, token *types.AccessToken, channel string, action string) error {
+	if token == nil {
+		return nil
+	}
+
+	policies, err := a.getPolicies(ctx, tenant, project)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	for i := range policies {
+		if allows(&policies[i], token, channel, action) {
+			return nil
+		}
+	}
+
+	return errors.PermissionDenied("'%s' is not allowed on channel '%s'", action, channel)
+}
+
+// Invalidate drops the cached policies of the project after they have been changed on this server.
+func (a *ChannelAuthorizer) Invalidate(tenant *metadata.Tenant, project *metadata.Project) {
+	a.Lock()
+	defer a.Unlock()
+
+	delete(a.policies, policiesKey(tenant, project))
+}
+
+func (a *ChannelAuthorizer) getPolicies(ctx context.Context, tenant *metadata.Tenant, project *metadata.Project) ([]metadata.ChannelPolicy, error) {
+	key := policiesKey(tenant, project)
+
+	a.RLock()
+	cached, ok := a.policies[key]
+	a.RUnlock()
+	if ok && time.Since(cached.loadedAt) < channelPoliciesTTL {
+		return cached.policies, nil
+	}
+
+	tx, err := a.txMgr.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	policies, err := tenant.ListChannelPolicies(ctx, tx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Lock()
+	a.policies[key] = &cachedPolicies{policies: policies, loadedAt: time.Now()}
+	a.Unlock()
+
+	return policies, nil
+}
+
+func policiesKey(tenant *metadata.Tenant, project *metadata.Project) string {
+	return fmt.Sprintf("%d:%d", tenant.GetNamespace().Id(), project.Id())
+}
+
+func allows(policy *metadata.ChannelPolicy, token *types.AccessToken, channel string, action string) bool {
+	if len(policy.Roles) > 0 && !slices.Contains(policy.Roles, token.Role) {
+		return false
+	}
+	if !slices.Contains(policy.Actions, action) {
+		return false
+	}
+
+	pattern, ok := channelPatternRegex(policy.Pattern, token)
+	return ok && pattern.MatchString(channel)
+}
+
+// channelPatternRegex returns the pattern of the policy with the claims of the token replaced. The pattern doesn't
+// match anything if it references a claim that is empty or unknown.
+func channelPatternRegex(pattern string, token *types.AccessToken) (*regexp.Regexp, bool) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	last := 0
+	for _, loc := range claimPlaceholder.FindAllStringSubmatchIndex(pattern, -1) {
+		sb.WriteString(globToRegex(pattern[last:loc[0]]))
+
+		value := claimValue(token, pattern[loc[2]:loc[3]])
+		if len(value) == 0 {
+			return nil, false
+		}
+		sb.WriteString(regexp.QuoteMeta(value))
+		last = loc[1]
+	}
+	sb.WriteString(globToRegex(pattern[last:]))
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, false
+	}
+
+	return re, true
+}
+
+func globToRegex(glob string) string {
+	return strings.ReplaceAll(regexp.QuoteMeta(glob), `\*`, `.*`)
+}
+
+func claimValue(token *types.AccessToken, claim string) string {
+	switch claim {
+	case "sub":
+		return token.Sub
+	case "namespace":
+		return token.Namespace
+	case "project":
+		return token.Project
+	case "role":
+		return token.Role
+	}
+
+	return ""
+}
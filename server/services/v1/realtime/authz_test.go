@@ -0,0 +1,73 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/types"
+)
+
+func TestChannelPolicy(t *testing.T) {
+	token := &types.AccessToken{Namespace: "ns1", Sub: "u1", Project: "p1", Role: "ro"}
+
+	t.Run("pattern", func(t *testing.T) {
+		cases := []struct {
+			pattern string
+			channel string
+			matches bool
+		}{
+			{"*", "any", true},
+			{"chat", "chat", true},
+			{"chat", "chat-1", false},
+			{"user-{sub}-*", "user-u1-inbox", true},
+			{"user-{sub}-*", "user-u2-inbox", false},
+			{"{project}.{sub}", "p1.u1", true},
+			// the claims and the rest of the pattern are not regular expressions
+			{"{project}.{sub}", "p1xu1", false},
+			{"user-{unknown}", "user-", false},
+		}
+		for _, c := range cases {
+			re, ok := channelPatternRegex(c.pattern, token)
+			require.Equal(t, c.matches, ok && re.MatchString(c.channel), "%s %s", c.pattern, c.channel)
+		}
+
+		_, ok := channelPatternRegex("user-{sub}", &types.AccessToken{Role: "ro"})
+		require.False(t, ok)
+	})
+	t.Run("allows", func(t *testing.T) {
+		policy := &metadata.ChannelPolicy{
+			Pattern: "user-{sub}-*",
+			Actions: []string{metadata.ChannelActionSubscribe, metadata.ChannelActionPresence},
+			Roles:   []string{"ro"},
+		}
+
+		require.True(t, allows(policy, token, "user-u1-inbox", metadata.ChannelActionSubscribe))
+		require.False(t, allows(policy, token, "user-u1-inbox", metadata.ChannelActionPublish))
+		require.False(t, allows(policy, token, "user-u2-inbox", metadata.ChannelActionSubscribe))
+		require.False(t, allows(policy, &types.AccessToken{Sub: "u1", Role: "e"}, "user-u1-inbox", metadata.ChannelActionSubscribe))
+
+		policy.Roles = nil
+		require.True(t, allows(policy, &types.AccessToken{Sub: "u1", Role: "e"}, "user-u1-inbox", metadata.ChannelActionSubscribe))
+	})
+	t.Run("validate", func(t *testing.T) {
+		require.NoError(t, (&metadata.ChannelPolicy{Pattern: "*", Actions: []string{metadata.ChannelActionPublish}}).Validate())
+		require.Error(t, (&metadata.ChannelPolicy{Actions: []string{metadata.ChannelActionPublish}}).Validate())
+		require.Error(t, (&metadata.ChannelPolicy{Pattern: "*"}).Validate())
+		require.Error(t, (&metadata.ChannelPolicy{Pattern: "*", Actions: []string{"attach"}}).Validate())
+	})
+}
@@ -0,0 +1,84 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/query/filter"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+)
+
+// The event names of the messages published on a channel for the changes of a bridged collection.
+const (
+	ChangeEventInsert = "insert"
+	ChangeEventUpdate = "update"
+	ChangeEventDelete = "delete"
+	// ChangeEventLeave is published for an update of a document which doesn't match the filter of the bridge, so that
+	// the subscribers drop the document if it matched the filter before the update.
+	ChangeEventLeave = "leave"
+)
+
+// NewChangeEventData returns the message published on a bridged channel for a committed change of a document. The
+// document is not set for the deletes and the leaves.
+func NewChangeEventData(eventName string, collection string, id string, doc []byte) (*internal.StreamData, error) {
+	event := map[string]any{
+		"collection": collection,
+		"id":         id,
+	}
+
+	if len(doc) > 0 {
+		var decoded any
+		if err := jsoniter.Unmarshal(doc, &decoded); err != nil {
+			return nil, err
+		}
+		event["document"] = decoded
+	}
+
+	data, err := EncodeAsMsgPack(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStreamData(MessageChannelData, internal.MsgpackEncoding, "", "", eventName, data)
+}
+
+// NewBridgeFilter returns the filter the documents of the collection are matched with before being published on the
+// bridged channel.
+func NewBridgeFilter(coll *schema.DefaultCollection, reqFilter []byte) (*filter.WrappedFilter, error) {
+	if len(reqFilter) == 0 {
+		return filter.WrappedEmptyFilter, nil
+	}
+
+	return filter.NewFactory(coll.QueryableFields, nil).WrappedFilter(reqFilter)
+}
+
+func validateBridgeFilter(project *metadata.Project, bridge *api.ChannelBridge) error {
+	db := project.GetMainDatabase()
+	if db == nil {
+		return errors.NotFound("project doesn't exist '%s'", project.Name())
+	}
+
+	coll := db.GetCollection(bridge.Collection)
+	if coll == nil {
+		return errors.NotFound("collection doesn't exist '%s'", bridge.Collection)
+	}
+
+	_, err := NewBridgeFilter(coll, bridge.Filter)
+	return err
+}
@@ -0,0 +1,50 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
+)
+
+func TestChangeEventData(t *testing.T) {
+	t.Run("update", func(t *testing.T) {
+		data, err := NewChangeEventData(ChangeEventUpdate, "users", "1", []byte(`{"id":1,"name":"alice"}`))
+		require.NoError(t, err)
+
+		md, err := DecodeStreamMD(data.Md)
+		require.NoError(t, err)
+		require.Equal(t, MessageChannelData, md.DataType)
+		require.Equal(t, ChangeEventUpdate, md.EventName)
+
+		js, err := SanitizeUserData(internal.JsonEncoding, data)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"collection":"users","id":"1","document":{"id":1,"name":"alice"}}`, string(js))
+	})
+	t.Run("delete", func(t *testing.T) {
+		data, err := NewChangeEventData(ChangeEventDelete, "users", "1", nil)
+		require.NoError(t, err)
+
+		js, err := SanitizeUserData(internal.JsonEncoding, data)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"collection":"users","id":"1"}`, string(js))
+	})
+	t.Run("invalid_document", func(t *testing.T) {
+		_, err := NewChangeEventData(ChangeEventInsert, "users", "1", []byte(`{`))
+		require.Error(t, err)
+	})
+}
@@ -31,6 +31,7 @@ type Channel struct {
 	project  uint32
 	stream   cache.Stream
 	watchers map[string]*ChannelWatcher
+	presence *ChannelPresence
 }
 
 func NewChannel(encName string, stream cache.Stream) *Channel {
@@ -128,6 +129,15 @@ func (ch *Channel) GetWatcher(ctx context.Context, watcherName string, resume st
 	return watcher, nil
 }
 
+// RemoveWatcher removes a watcher which hasn't started watching, along with its consumer group.
+func (ch *Channel) RemoveWatcher(ctx context.Context, watcher string) error {
+	ch.Lock()
+	delete(ch.watchers, watcher)
+	ch.Unlock()
+
+	return ch.stream.RemoveConsumerGroup(ctx, watcher)
+}
+
 // DisconnectWatcher ToDo: call it during leave.
 func (ch *Channel) DisconnectWatcher(watcher string) {
 	ch.Lock()
@@ -158,6 +168,13 @@ func (ch *Channel) Close(ctx context.Context) {
 		delete(ch.watchers, w.name)
 	}
 
+	if ch.presence != nil {
+		ch.presence.Stop()
+		if err := ch.presence.Clear(ctx); err != nil {
+			log.Err(err).Str("channel", ch.encName).Msg("clearing presence failed")
+		}
+	}
+
 	if err := ch.stream.Delete(ctx); err != nil {
 		log.Err(err).Str("channel", ch.encName).Msg("deleting stream failed")
 		return
@@ -16,6 +16,8 @@ package realtime
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,6 +29,7 @@ import (
 	"github.com/tigrisdata/tigris/lib/uuid"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/request"
+	"github.com/tigrisdata/tigris/server/types"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -38,16 +41,22 @@ type Session struct {
 	socketId     string
 	closed       bool
 	encType      internal.UserDataEncType
-	conn         *websocket.Conn
+	transport    string
+	conn         Connection
 	lastReceived time.Time
 	chFactory    *ChannelFactory
 	heartbeat    *HeartbeatTable
 	tenant       *metadata.Tenant
 	project      *metadata.Project
 	watchers     map[string]*ChannelWatcher
+	authorizer   *ChannelAuthorizer
+	// token is the access token the session is connected with, the channel policies are evaluated against it
+	token *types.AccessToken
+	// presence is the presence of the channels the session has entered
+	presence map[string]*ChannelPresence
 }
 
-func (s *Sessions) CreateDeviceSession(ctx context.Context, conn *websocket.Conn, params ConnectionParams) (*Session, error) {
+func (s *Sessions) CreateDeviceSession(ctx context.Context, conn Connection, params ConnectionParams) (*Session, error) {
 	sessionId := params.SessionId
 	if len(sessionId) == 0 {
 		sessionId = uuid.NewUUIDAsString()
@@ -78,26 +87,68 @@ func (s *Sessions) CreateDeviceSession(ctx context.Context, conn *websocket.Conn
 		}
 
 		if proj, err = tenant.GetProject(params.ProjectName); err != nil {
-			return nil, err
+			return nil, errors.NotFound("project '%s' not found", params.ProjectName)
 		}
 	}
 
+	// the token is missing if the authentication is disabled
+	token, _ := request.GetAccessToken(ctx)
+
 	return &Session{
-		id:        sessionId,
-		conn:      conn,
-		tenant:    tenant,
-		project:   proj,
-		encType:   params.ToEncodingType(),
-		chFactory: s.channelFactory,
-		watchers:  make(map[string]*ChannelWatcher),
-		heartbeat: s.heartbeatFactory.GetHeartbeatTable(tenant.GetNamespace().Id(), proj.Id()),
+		id:         sessionId,
+		clientId:   params.ClientId,
+		conn:       conn,
+		tenant:     tenant,
+		project:    proj,
+		encType:    params.ToEncodingType(),
+		transport:  params.ToTransport(),
+		chFactory:  s.channelFactory,
+		watchers:   make(map[string]*ChannelWatcher),
+		presence:   make(map[string]*ChannelPresence),
+		authorizer: s.authorizer,
+		token:      token,
+		heartbeat:  s.heartbeatFactory.GetHeartbeatTable(tenant.GetNamespace().Id(), proj.Id()),
 	}, nil
 }
 
+// sameOwner returns true if the other session is connected to the same namespace and project with the same token
+// subject.
+func (session *Session) sameOwner(other *Session) bool {
+	return session.tenant.GetNamespace().Id() == other.tenant.GetNamespace().Id() &&
+		session.project.Id() == other.project.Id() && session.subject() == other.subject()
+}
+
+// subject returns the subject of the access token of the session, empty if the authentication is disabled.
+func (session *Session) subject() string {
+	if session.token == nil {
+		return ""
+	}
+
+	return session.token.Sub
+}
+
+func (session *Session) isClosed() bool {
+	session.RLock()
+	defer session.RUnlock()
+
+	return session.closed
+}
+
 func (session *Session) IsActive() bool {
+	session.RLock()
+	defer session.RUnlock()
+
 	return time.Since(session.lastReceived) <= 30*time.Second
 }
 
+// received records that the device is still connected.
+func (session *Session) received() {
+	session.Lock()
+	defer session.Unlock()
+
+	session.lastReceived = time.Now()
+}
+
 func (session *Session) OnPong(_ string) error {
 	return session.sendHeartbeat()
 }
@@ -118,13 +169,26 @@ func (session *Session) Close() error {
 	session.Lock()
 	defer session.Unlock()
 
+	return session.close()
+}
+
+func (session *Session) close() error {
 	if session.closed {
 		return nil
 	}
 
 	session.closed = true
-	for _, w := range session.watchers {
-		w.Disconnect()
+	// the consumer groups are kept so that the session resumes from where it was if it reconnects, they are removed
+	// with the channel once the session stops heart-beating
+	channels := make(map[string]struct{})
+	for key := range session.watchers {
+		channels[strings.TrimSuffix(key, presenceWatcherSuffix)] = struct{}{}
+	}
+	for channel := range channels {
+		session.removeWatchers(context.TODO(), channel, false)
+	}
+	for channel := range session.presence {
+		session.leave(context.TODO(), channel)
 	}
 
 	return session.conn.Close()
@@ -132,32 +196,48 @@ func (session *Session) Close() error {
 
 // Start an entry point for handling all the events from a device.
 func (session *Session) Start(ctx context.Context) error {
+	reader, ok := session.conn.(messageReader)
+	if !ok {
+		return errors.Internal("'%s' transport doesn't receive events", session.transport)
+	}
+
 	for {
 		_ = session.heartbeat.Ping(session.id)
-		if session.closed {
+		if session.isClosed() {
 			return nil
 		}
 
-		_, message, err := session.conn.ReadMessage()
+		_, message, err := reader.ReadMessage()
 		if err != nil {
 			return err
 		}
-		session.lastReceived = time.Now()
+		session.received()
 		if errEvent := session.onMessage(ctx, message); errEvent != nil {
-			log.Err(err).Msgf("realtime send error '%s' %s", session.id, errEvent)
-			err = SendReply(session.conn, session.encType, api.EventType_error, errEvent)
-			log.Err(err).Msgf("failed to send error reply message")
+			session.sendError(errEvent)
 		}
 	}
 }
 
-// onMessage is responsible for handling all the messages that are received on websocket.
+func (session *Session) sendError(errEvent *api.ErrorEvent) {
+	log.Error().Msgf("realtime send error '%s' %s", session.id, errEvent)
+	err := SendReply(session.conn, session.encType, api.EventType_error, errEvent)
+	log.Err(err).Msgf("failed to send error reply message")
+}
+
+// onMessage is responsible for handling all the messages that are received on websocket. The messages are handled
+// under the lock of the session, like the subscriptions of the other transports and the close.
 func (session *Session) onMessage(ctx context.Context, message []byte) *api.ErrorEvent {
 	rtm, err := DecodeRealtime(session.encType, message)
 	if err != nil {
 		return errors.InternalWS(err.Error())
 	}
 
+	session.Lock()
+	defer session.Unlock()
+
+	if session.closed {
+		return nil
+	}
 	return session.handleMessage(ctx, rtm)
 }
 
@@ -176,11 +256,10 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 		}
 
 		if len(event.Channel) > 0 {
-			watcher := session.watchers[event.Channel]
-			watcher.Disconnect()
-			delete(session.watchers, event.Channel)
+			session.removeWatchers(ctx, event.Channel, true)
+			session.leave(ctx, event.Channel)
 		} else {
-			if err := session.Close(); err != nil {
+			if err := session.close(); err != nil {
 				return errors.InternalWS(err.Error())
 			}
 		}
@@ -197,6 +276,13 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			return errors.InternalWS("expecting 'attach' event")
 		}
 
+		// attaching is needed for subscribing as well as for publishing
+		if session.authorize(ctx, event.Channel, metadata.ChannelActionSubscribe) != nil {
+			if errEvent := session.authorize(ctx, event.Channel, metadata.ChannelActionPublish); errEvent != nil {
+				return errEvent
+			}
+		}
+
 		// create a channel if it doesn't exist
 		_, err := session.chFactory.GetOrCreateChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
 		if err != nil {
@@ -209,10 +295,8 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			return errors.InternalWS("expecting 'detach' event")
 		}
 
-		if watcher, ok := session.watchers[event.Channel]; ok {
-			watcher.Disconnect()
-			delete(session.watchers, event.Channel)
-		}
+		session.removeWatchers(ctx, event.Channel, true)
+		session.leave(ctx, event.Channel)
 		return nil
 	case api.EventType_unsubscribe:
 		event, ok := decoded.(*api.UnsubscribeEvent)
@@ -220,10 +304,7 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			return errors.InternalWS("expecting 'detach' event")
 		}
 
-		if watcher, ok := session.watchers[event.Channel]; ok {
-			watcher.Stop()
-			delete(session.watchers, event.Channel)
-		}
+		session.removeWatchers(ctx, event.Channel, false)
 		return nil
 	case api.EventType_subscribe:
 		event, ok := decoded.(*api.SubscribeEvent)
@@ -231,33 +312,17 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			return errors.InternalWS("expecting 'subscribe' event")
 		}
 
-		channel, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
-		if err != nil {
-			return errors.InternalWS(err.Error())
-		}
-
-		if _, ok := session.watchers[event.Channel]; ok {
-			// if already watching ignore
-			return nil
-		}
-
-		watcher, err := channel.GetWatcher(ctx, session.id, event.Position)
-		if err != nil {
-			return nil
-		}
-		session.watchers[event.Channel] = watcher
-		watcher.StartWatching(NewDevicePusher(session, event.Channel).Watch)
-		err = SendReply(session.conn, session.encType, api.EventType_subscribed, &api.SubscribedEvent{
-			Channel: event.Channel,
-		})
-		log.Err(err).Msgf("failed to send subscribe message")
-		return nil
+		return session.subscribe(ctx, event)
 	case api.EventType_message:
 		event, ok := decoded.(*api.MessageEvent)
 		if !ok {
 			return errors.InternalWS("expecting message event")
 		}
 
+		if errEvent := session.authorize(ctx, event.Channel, metadata.ChannelActionPublish); errEvent != nil {
+			return errEvent
+		}
+
 		ch, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
 		if err != nil {
 			return errors.InternalWS(err.Error())
@@ -271,31 +336,250 @@ func (session *Session) handleMessage(ctx context.Context, req *api.RealTimeMess
 			return errors.InternalWS(err.Error())
 		}
 		return nil
-	case api.EventType_presence_member:
-		event, ok := decoded.(*api.MessageEvent)
+	case api.EventType_presence:
+		event, ok := decoded.(*api.PresenceEvent)
 		if !ok {
 			return errors.InternalWS("expecting presence event")
 		}
 
-		ch, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
-		if err != nil {
-			return errors.InternalWS(err.Error())
+		if errEvent := session.authorize(ctx, event.Channel, metadata.ChannelActionPresence); errEvent != nil {
+			return errEvent
 		}
 
-		streamData, err := NewPresenceData(session.encType, session.clientId, session.socketId, event.Name, event)
-		if err != nil {
+		if err := session.onPresence(ctx, event); err != nil {
 			return errors.InternalWS(err.Error())
 		}
-		if _, err := ch.PublishPresence(ctx, streamData); err != nil {
-			return errors.InternalWS(err.Error())
+	}
+	return nil
+}
+
+// Subscribe pushes the messages of the channel to the session from the position, or from where the session was if
+// the position is empty. Nothing is subscribed once the session is closed.
+func (session *Session) Subscribe(ctx context.Context, channel string, position string) *api.ErrorEvent {
+	session.Lock()
+	defer session.Unlock()
+
+	if session.closed {
+		return nil
+	}
+	return session.subscribe(ctx, &api.SubscribeEvent{
+		Channel:  channel,
+		Position: position,
+	})
+}
+
+func (session *Session) subscribe(ctx context.Context, event *api.SubscribeEvent) *api.ErrorEvent {
+	action := metadata.ChannelActionSubscribe
+	if event.Name == presenceSubscribeEvent {
+		action = metadata.ChannelActionPresence
+	}
+	if errEvent := session.authorize(ctx, event.Channel, action); errEvent != nil {
+		return errEvent
+	}
+
+	channel, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
+	if err != nil {
+		return errors.InternalWS(err.Error())
+	}
+
+	if event.Name == presenceSubscribeEvent {
+		return session.subscribePresence(ctx, channel, event)
+	}
+
+	if _, ok := session.watchers[event.Channel]; ok {
+		// if already watching ignore
+		return nil
+	}
+
+	pos, err := channel.Position(ctx, event.Position)
+	if err != nil {
+		// an invalid position is an invalid argument
+		return errors.ToWSError(err)
+	}
+
+	watcher, err := channel.GetWatcher(ctx, session.id, pos)
+	if err != nil {
+		return nil
+	}
+	session.watchers[event.Channel] = watcher
+	if session.transport == PollTransport {
+		watcher.ReplayPending()
+	}
+	watcher.StartWatching(NewDevicePusher(session, event.Channel, watcher).Watch)
+	err = SendReply(session.conn, session.encType, api.EventType_subscribed, &api.SubscribedEvent{
+		Channel: event.Channel,
+	})
+	log.Err(err).Msgf("failed to send subscribe message")
+	return nil
+}
+
+// authorize checks the channel policies of the project for the action of the session on the channel.
+func (session *Session) authorize(ctx context.Context, channel string, action string) *api.ErrorEvent {
+	err := session.authorizer.Authorize(ctx, session.tenant, session.project, session.token, channel, action)
+	if err == nil {
+		return nil
+	}
+
+	if ierr, ok := err.(*api.TigrisError); ok && ierr.Code == api.Code_PERMISSION_DENIED {
+		return errors.PolicyViolationWS(ierr.Message)
+	}
+	return errors.InternalWS(err.Error())
+}
+
+// onPresence applies the presence action of the session on the channel.
+func (session *Session) onPresence(ctx context.Context, event *api.PresenceEvent) error {
+	ch, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), event.Channel)
+	if err != nil {
+		return err
+	}
+	presence := session.chFactory.GetPresence(ch)
+
+	switch event.Action {
+	case api.PresenceActions_enter, api.PresenceActions_update:
+		if err = presence.Enter(ctx, session.presenceMember(event.Data), event.Action); err != nil {
+			return err
+		}
+		session.presence[event.Channel] = presence
+	case api.PresenceActions_leave:
+		if err = presence.Leave(ctx, session.presenceMember(nil)); err != nil {
+			return err
 		}
+		delete(session.presence, event.Channel)
 	default:
-		// ToDo: presence channel subscribe by adding a different watcher name
+		return fmt.Errorf("unsupported presence action '%s'", event.Action)
+	}
+
+	return nil
+}
+
+// subscribePresence pushes the presence changes of the channel to the session, starting with the members already
+// present.
+func (session *Session) subscribePresence(ctx context.Context, channel *Channel, event *api.SubscribeEvent) *api.ErrorEvent {
+	key := presenceWatcherKey(event.Channel)
+	if _, ok := session.watchers[key]; ok {
+		// if already watching ignore
+		return nil
+	}
+
+	pos, err := channel.Position(ctx, event.Position)
+	if err != nil {
+		return errors.ToWSError(err)
+	}
+
+	// the watcher fixes its position in the stream before the members are read, so no change is lost between the
+	// snapshot and the first pushed change. A change in between may be both in the snapshot and pushed.
+	watcher, err := channel.GetWatcher(ctx, presenceWatcherName(session.id), pos)
+	if err != nil {
+		return errors.InternalWS(err.Error())
+	}
+
+	// the watcher is removed if the subscription fails before it starts watching
+	fail := func(err error) *api.ErrorEvent {
+		if rErr := channel.RemoveWatcher(ctx, watcher.name); rErr != nil {
+			log.Err(rErr).Str("watcher", watcher.name).Msg("removing presence watcher failed")
+		}
+		return errors.InternalWS(err.Error())
 	}
+
+	members, err := session.chFactory.GetPresence(channel).Members(ctx)
+	if err != nil {
+		return fail(err)
+	}
+	apiMembers := make([]*api.PresenceMember, len(members))
+	for i, m := range members {
+		if apiMembers[i], err = m.ToAPI(session.encType); err != nil {
+			return fail(err)
+		}
+	}
+
+	err = SendReply(session.conn, session.encType, api.EventType_subscribed, &api.SubscribedEvent{
+		Channel: event.Channel,
+	})
+	log.Err(err).Msgf("failed to send subscribe message")
+
+	for _, member := range apiMembers {
+		err = SendReply(session.conn, session.encType, api.EventType_presence, &api.PresenceChangeEvent{
+			Channel: event.Channel,
+			Action:  api.PresenceActions_present,
+			Member:  member,
+		})
+		log.Err(err).Msgf("failed to push presence")
+	}
+
+	session.watchers[key] = watcher
+	if session.transport == PollTransport {
+		watcher.ReplayPending()
+	}
+	watcher.StartWatching(NewPresencePusher(session, event.Channel, watcher).Watch)
+
 	return nil
 }
 
-func SendReply(conn *websocket.Conn, encType internal.UserDataEncType, eventType api.EventType, event proto.Message) error {
+func (session *Session) presenceMember(data []byte) *PresenceMember {
+	clientId := session.clientId
+	if len(clientId) == 0 {
+		clientId = session.id
+	}
+
+	return &PresenceMember{
+		ClientId:  clientId,
+		SessionId: session.id,
+		SocketId:  session.socketId,
+		Encoding:  int32(session.encType),
+		Data:      data,
+	}
+}
+
+// leave removes the session from the members of the channel if it has entered it.
+func (session *Session) leave(ctx context.Context, channel string) {
+	presence, ok := session.presence[channel]
+	if !ok {
+		return
+	}
+
+	delete(session.presence, channel)
+	if err := presence.Leave(ctx, session.presenceMember(nil)); err != nil {
+		log.Err(err).Str("channel", channel).Msg("leaving channel failed")
+	}
+}
+
+// removeWatchers stops pushing the channel to the session. The watchers are removed from the channel so that the next
+// subscription of the session, on any transport, resumes from the position of their consumer group. The consumer
+// groups are removed as well if the session is disconnecting from the channel.
+func (session *Session) removeWatchers(ctx context.Context, channel string, disconnect bool) {
+	ch, err := session.chFactory.GetChannel(ctx, session.tenant.GetNamespace().Id(), session.project.Id(), channel)
+	for _, key := range []string{channel, presenceWatcherKey(channel)} {
+		watcher, ok := session.watchers[key]
+		if !ok {
+			continue
+		}
+		delete(session.watchers, key)
+
+		if err != nil {
+			// the channel is deleted along with its watchers
+			continue
+		}
+		if disconnect {
+			ch.DisconnectWatcher(watcher.name)
+		} else {
+			ch.StopWatcher(watcher.name)
+		}
+	}
+}
+
+// presenceWatcherKey is the key of the presence watcher of the channel in the watchers of the session.
+func presenceWatcherKey(channel string) string {
+	return channel + presenceWatcherSuffix
+}
+
+func SendReply(conn Connection, encType internal.UserDataEncType, eventType api.EventType, event proto.Message) error {
+	msgType, encRealtime := encodeReply(encType, eventType, event)
+
+	return conn.WriteMessage(msgType, encRealtime)
+}
+
+// encodeReply returns the message type and the encoded message of the event.
+func encodeReply(encType internal.UserDataEncType, eventType api.EventType, event proto.Message) (int, []byte) {
 	encEvent, err := EncodeEvent(encType, event)
 	if err != nil {
 		panic(err)
@@ -317,10 +601,7 @@ func SendReply(conn *websocket.Conn, encType internal.UserDataEncType, eventType
 		msgType = websocket.TextMessage
 	}
 
-	return conn.WriteMessage(
-		msgType,
-		encRealtime,
-	)
+	return msgType, encRealtime
 }
 
 func (session *Session) sendHeartbeat() error {
@@ -16,10 +16,12 @@ package realtime
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/errors"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/store/cache"
 )
@@ -52,6 +54,9 @@ func (factory *ChannelFactory) monitorStreams() {
 	defer ticker.Stop()
 	for range ticker.C {
 		for _, c := range factory.channels {
+			if err := factory.applyRetention(context.TODO(), c); err != nil {
+				log.Err(err).Str("channel", c.encName).Msg("trimming channel failed")
+			}
 			_ = factory.deleteChannelIfInactive(c)
 		}
 	}
@@ -64,9 +69,13 @@ func (factory *ChannelFactory) deleteChannelIfInactive(c *Channel) error {
 		return err
 	}
 
-	groupsName := make([]string, len(groups))
-	for i, g := range groups {
-		groupsName[i] = g.Name
+	groupsName := make([]string, 0, len(groups))
+	for _, g := range groups {
+		// the webhooks don't send heartbeats, a channel delivered to a webhook is kept until the webhook is removed
+		if strings.HasPrefix(g.Name, webhookWatcherPrefix) {
+			return nil
+		}
+		groupsName = append(groupsName, g.Name)
 	}
 
 	heartbeat := factory.heartbeatF.GetHeartbeatTable(c.tenant, c.project)
@@ -85,13 +94,34 @@ func (factory *ChannelFactory) getChannel(encStream string) (*Channel, bool) {
 	return ch, ok
 }
 
-func (factory *ChannelFactory) getOrCreateChannelFromCache(ctx context.Context, encStream string) (*Channel, error) {
+func (factory *ChannelFactory) getOrCreateChannelFromCache(ctx context.Context, tenantId uint32, projId uint32, encStream string) (*Channel, error) {
 	stream, err := factory.cache.CreateOrGetStream(ctx, encStream)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewChannel(encStream, stream), nil
+	return factory.newChannel(tenantId, projId, encStream, stream), nil
+}
+
+func (*ChannelFactory) newChannel(tenantId uint32, projId uint32, encStream string, stream cache.Stream) *Channel {
+	ch := NewChannel(encStream, stream)
+	ch.tenant = tenantId
+	ch.project = projId
+
+	return ch
+}
+
+// GetPresence returns the presence of the channel and starts removing its expired members from this server.
+func (factory *ChannelFactory) GetPresence(ch *Channel) *ChannelPresence {
+	ch.Lock()
+	defer ch.Unlock()
+
+	if ch.presence == nil {
+		ch.presence = NewChannelPresence(factory.cache, ch, factory.heartbeatF.GetHeartbeatTable(ch.tenant, ch.project))
+		ch.presence.StartSweeping()
+	}
+
+	return ch.presence
 }
 
 func (factory *ChannelFactory) ListChannels(ctx context.Context, tenantId uint32, projId uint32, prefix string) ([]string, error) {
@@ -105,18 +135,26 @@ func (factory *ChannelFactory) ListChannels(ctx context.Context, tenantId uint32
 		return nil, err
 	}
 
-	channelNames := make([]string, len(streams))
-	for i, s := range streams {
+	// the presence members, the heartbeats and the webhook dead letters are stored under the same prefix as the streams
+	channelNames := make([]string, 0, len(streams))
+	seen := make(map[string]struct{}, len(streams))
+	for _, s := range streams {
 		_, _, ch, cacheStream := factory.encoder.DecodeCacheTableName(s)
-		if cacheStream {
-			channelNames[i] = ch
+		if _, ok := seen[ch]; !cacheStream || ok || ch == heartbeatTable || ch == webhookDeadLettersTable {
+			continue
 		}
+		seen[ch] = struct{}{}
+		channelNames = append(channelNames, ch)
 	}
 
 	return channelNames, nil
 }
 
 func (factory *ChannelFactory) GetChannel(ctx context.Context, tenantId uint32, projId uint32, channelName string) (*Channel, error) {
+	if err := checkChannelName(channelName); err != nil {
+		return nil, err
+	}
+
 	encStream, err := factory.encoder.EncodeCacheTableName(tenantId, projId, channelName)
 	if err != nil {
 		return nil, err
@@ -131,7 +169,7 @@ func (factory *ChannelFactory) GetChannel(ctx context.Context, tenantId uint32,
 		return nil, err
 	}
 
-	ch := NewChannel(encStream, stream)
+	ch := factory.newChannel(tenantId, projId, encStream, stream)
 
 	factory.Lock()
 	factory.channels[encStream] = ch
@@ -140,6 +178,10 @@ func (factory *ChannelFactory) GetChannel(ctx context.Context, tenantId uint32,
 }
 
 func (factory *ChannelFactory) GetOrCreateChannel(ctx context.Context, tenantId uint32, projId uint32, channelName string) (*Channel, error) {
+	if err := checkChannelName(channelName); err != nil {
+		return nil, err
+	}
+
 	encStream, err := factory.encoder.EncodeCacheTableName(tenantId, projId, channelName)
 	if err != nil {
 		return nil, err
@@ -152,7 +194,7 @@ func (factory *ChannelFactory) GetOrCreateChannel(ctx context.Context, tenantId
 	factory.Lock()
 	defer factory.Unlock()
 
-	ch, err := factory.getOrCreateChannelFromCache(ctx, encStream)
+	ch, err := factory.getOrCreateChannelFromCache(ctx, tenantId, projId, encStream)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +205,10 @@ func (factory *ChannelFactory) GetOrCreateChannel(ctx context.Context, tenantId
 
 // CreateChannel will throw an error if stream already exists. Use CreateOrGet to create if not exists primitive.
 func (factory *ChannelFactory) CreateChannel(ctx context.Context, tenantId uint32, projId uint32, channelName string) (*Channel, error) {
+	if err := checkChannelName(channelName); err != nil {
+		return nil, err
+	}
+
 	encStream, err := factory.encoder.EncodeCacheTableName(tenantId, projId, channelName)
 	if err != nil {
 		return nil, err
@@ -179,15 +225,27 @@ func (factory *ChannelFactory) CreateChannel(ctx context.Context, tenantId uint3
 		return nil, err
 	}
 
-	ch := NewChannel(encStream, stream)
+	ch := factory.newChannel(tenantId, projId, encStream, stream)
 	factory.channels[ch.encName] = ch
 	return ch, nil
 }
 
+// checkChannelName rejects the names of the tables stored under the same prefix as the channels.
+func checkChannelName(name string) error {
+	if name == heartbeatTable || name == webhookDeadLettersTable || strings.HasPrefix(name, webhookDeadLettersTable+":") {
+		return errors.InvalidArgument("channel name '%s' is reserved", name)
+	}
+
+	return nil
+}
+
 func (factory *ChannelFactory) DeleteChannel(ctx context.Context, ch *Channel) {
 	factory.Lock()
 	defer factory.Unlock()
 
 	ch.Close(ctx)
+	if _, err := factory.cache.Delete(ctx, ch.encName, retentionKey); err != nil {
+		log.Err(err).Str("channel", ch.encName).Msg("deleting retention failed")
+	}
 	delete(factory.channels, ch.encName)
 }
@@ -54,6 +54,28 @@ func TestFactory(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, channel1, channel3)
 	})
+	t.Run("reserved_names", func(t *testing.T) {
+		for _, name := range []string{heartbeatTable, webhookDeadLettersTable, webhookDeadLettersTable + ":orders"} {
+			_, err := factory.GetOrCreateChannel(ctx, 1, 1, name)
+			require.Error(t, err, name)
+			_, err = factory.CreateChannel(ctx, 1, 1, name)
+			require.Error(t, err, name)
+		}
+	})
+	t.Run("keep_webhook_channel", func(t *testing.T) {
+		channel, err := factory.GetOrCreateChannel(ctx, 1, 1, "webhook")
+		require.NoError(t, err)
+		defer factory.DeleteChannel(ctx, channel)
+
+		_, err = channel.GetWatcher(ctx, webhookWatcherPrefix+"orders", "")
+		require.NoError(t, err)
+		// the sessions of the project have pinged, none of them watches the channel
+		require.NoError(t, factory.heartbeatF.GetHeartbeatTable(1, 1).Ping("other"))
+
+		require.NoError(t, factory.deleteChannelIfInactive(channel))
+		_, ok := factory.getChannel(channel.encName)
+		require.True(t, ok)
+	})
 }
 
 func newFactory(_ *testing.T) *ChannelFactory {
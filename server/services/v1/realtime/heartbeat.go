@@ -59,6 +59,9 @@ func (factory *HeartbeatFactory) GetHeartbeatTable(tenantId uint32, projectId ui
 }
 
 type HeartbeatTable struct {
+	// the lock serializes the pings of the concurrent requests of a session
+	sync.Mutex
+
 	cache         cache.Cache
 	tableName     string
 	lastHeartbeat time.Time
@@ -76,6 +79,9 @@ func (h *HeartbeatTable) timeToPing() bool {
 }
 
 func (h *HeartbeatTable) Ping(sessionId string) error {
+	h.Lock()
+	defer h.Unlock()
+
 	if !h.timeToPing() {
 		return nil
 	}
@@ -92,12 +98,30 @@ func (h *HeartbeatTable) Ping(sessionId string) error {
 }
 
 func (h *HeartbeatTable) GroupsExpired(groupsName []string) bool {
-	if h.lastHeartbeat.IsZero() {
+	h.Lock()
+	pinged := !h.lastHeartbeat.IsZero()
+	h.Unlock()
+	if !pinged {
 		return false
 	}
-	if count, err := h.cache.Exists(context.TODO(), h.tableName, groupsName...); err == nil && count == 0 {
+
+	sessions := make([]string, len(groupsName))
+	for i, g := range groupsName {
+		sessions[i] = watcherSession(g)
+	}
+	if count, err := h.cache.Exists(context.TODO(), h.tableName, sessions...); err == nil && count == 0 {
 		return true
 	}
 
 	return false
 }
+
+// Alive returns true if the session has sent a heartbeat recently.
+func (h *HeartbeatTable) Alive(ctx context.Context, sessionId string) (bool, error) {
+	count, err := h.cache.Exists(ctx, h.tableName, sessionId)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
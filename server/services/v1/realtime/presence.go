@@ -0,0 +1,241 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/store/cache"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// presenceKey is the cache key of the hash keeping the members of a channel by their client id, the key is stored
+	// under the channel.
+	presenceKey = "presence"
+	// presenceWatcherSuffix is appended to the session id to name the watcher pushing the presence changes of a
+	// channel to the session.
+	presenceWatcherSuffix  = ":presence"
+	presenceSweepDuration  = 15 * time.Second
+	presenceSubscribeEvent = "presence"
+)
+
+// PresenceMember is the state of a member of a channel. A member is identified by the client id of its session, or by
+// the session id if the client didn't set one.
+type PresenceMember struct {
+	ClientId  string    `json:"client_id"`
+	SessionId string    `json:"session_id"`
+	SocketId  string    `json:"socket_id,omitempty"`
+	Encoding  int32     `json:"encoding"`
+	Data      []byte    `json:"data,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToAPI returns the member with its data encoded with the encoding type.
+func (m *PresenceMember) ToAPI(encType internal.UserDataEncType) (*api.PresenceMember, error) {
+	member := &api.PresenceMember{
+		ClientId:  m.ClientId,
+		UpdatedAt: timestamppb.New(m.UpdatedAt),
+	}
+
+	if len(m.Data) > 0 {
+		data, err := SanitizeUserData(encType, &internal.StreamData{Encoding: m.Encoding, RawData: m.Data})
+		if err != nil {
+			return nil, err
+		}
+		member.Data = data
+	}
+
+	return member, nil
+}
+
+// ChannelPresence keeps the members of a channel. The members are stored in the cache so that they are shared by all
+// the servers, every change is also published to the channel so that it is pushed to the presence subscribers. The
+// members whose session stopped heart-beating are removed periodically as if they had left.
+type ChannelPresence struct {
+	start sync.Once
+	stop  sync.Once
+
+	cache     cache.Cache
+	channel   *Channel
+	heartbeat *HeartbeatTable
+	sigStop   chan struct{}
+}
+
+func NewChannelPresence(cache cache.Cache, channel *Channel, heartbeat *HeartbeatTable) *ChannelPresence {
+	return &ChannelPresence{
+		cache:     cache,
+		channel:   channel,
+		heartbeat: heartbeat,
+		sigStop:   make(chan struct{}),
+	}
+}
+
+// Enter adds the member to the channel, or updates its data if it is already present.
+func (p *ChannelPresence) Enter(ctx context.Context, member *PresenceMember, action api.PresenceActions) error {
+	member.UpdatedAt = time.Now().UTC()
+
+	encoded, err := jsoniter.Marshal(member)
+	if err != nil {
+		return err
+	}
+
+	if _, err = p.cache.HSet(ctx, p.channel.Name(), presenceKey, map[string][]byte{member.ClientId: encoded}); err != nil {
+		return err
+	}
+
+	return p.publish(ctx, member, action)
+}
+
+// Leave removes the member from the channel if it is present with the session of the member, a client which has
+// entered again from another session stays present. The stored member is only removed if it hasn't changed since it was
+// read, and the leave is only published if the member was removed.
+func (p *ChannelPresence) Leave(ctx context.Context, member *PresenceMember) error {
+	for {
+		current, err := p.cache.HGet(ctx, p.channel.Name(), presenceKey, member.ClientId)
+		if err == cache.ErrKeyNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var stored PresenceMember
+		if err = jsoniter.Unmarshal(current, &stored); err != nil {
+			return err
+		}
+		if stored.SessionId != member.SessionId {
+			return nil
+		}
+
+		removed, err := p.cache.HDelIfEqual(ctx, p.channel.Name(), presenceKey, member.ClientId, current)
+		if err != nil {
+			return err
+		}
+		if removed {
+			return p.publish(ctx, member, api.PresenceActions_leave)
+		}
+		// the session has updated the member in the meantime
+	}
+}
+
+// Members returns the members present in the channel.
+func (p *ChannelPresence) Members(ctx context.Context) ([]*PresenceMember, error) {
+	fields, err := p.cache.HGetAll(ctx, p.channel.Name(), presenceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*PresenceMember, 0, len(fields))
+	for _, data := range fields {
+		var member PresenceMember
+		if err = jsoniter.Unmarshal(data, &member); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+
+	return members, nil
+}
+
+func (p *ChannelPresence) publish(ctx context.Context, member *PresenceMember, action api.PresenceActions) error {
+	md, err := EncodeStreamMD(NewStreamMessageMD(PresenceChannelData, member.ClientId, member.SocketId, action.String()))
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if action != api.PresenceActions_leave {
+		data = member.Data
+	}
+
+	_, err = p.channel.PublishPresence(ctx, internal.NewStreamData(internal.UserDataEncType(member.Encoding), md, data))
+	return err
+}
+
+// StartSweeping starts removing the members whose session has expired, it is only started once.
+func (p *ChannelPresence) StartSweeping() {
+	p.start.Do(func() {
+		go p.sweepMembers()
+	})
+}
+
+func (p *ChannelPresence) Stop() {
+	p.stop.Do(func() {
+		close(p.sigStop)
+	})
+}
+
+func (p *ChannelPresence) sweepMembers() {
+	ticker := time.NewTicker(presenceSweepDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.sigStop:
+			return
+		case <-ticker.C:
+			if err := p.sweep(context.TODO()); err != nil {
+				log.Err(err).Str("channel", p.channel.Name()).Msg("sweeping presence members failed")
+			}
+		}
+	}
+}
+
+func (p *ChannelPresence) sweep(ctx context.Context) error {
+	members, err := p.Members(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range members {
+		alive, err := p.heartbeat.Alive(ctx, m.SessionId)
+		if err != nil {
+			return err
+		}
+		if alive {
+			continue
+		}
+
+		// every server sweeps the channel, only the one deleting the member publishes the leave
+		if err = p.Leave(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Clear removes all the members without publishing anything, the channel is being deleted.
+func (p *ChannelPresence) Clear(ctx context.Context) error {
+	_, err := p.cache.Delete(ctx, p.channel.Name(), presenceKey)
+	return err
+}
+
+func presenceWatcherName(sessionId string) string {
+	return sessionId + presenceWatcherSuffix
+}
+
+// watcherSession returns the id of the session owning the watcher.
+func watcherSession(watcherName string) string {
+	return strings.TrimSuffix(watcherName, presenceWatcherSuffix)
+}
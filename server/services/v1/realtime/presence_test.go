@@ -0,0 +1,83 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/ugorji/go/codec"
+)
+
+func TestPresence(t *testing.T) {
+	t.Run("keys", func(t *testing.T) {
+		require.Equal(t, "s1:presence", presenceWatcherName("s1"))
+		require.Equal(t, "s1", watcherSession(presenceWatcherName("s1")))
+		require.Equal(t, "s1", watcherSession("s1"))
+		require.Equal(t, "test:presence", presenceWatcherKey("test"))
+	})
+	t.Run("member_to_api", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, codec.NewEncoder(&buf, &msgpackHandle).Encode(map[string]any{"status": "online"}))
+
+		updated := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		member := &PresenceMember{
+			ClientId:  "c1",
+			SessionId: "s1",
+			Encoding:  int32(internal.MsgpackEncoding),
+			Data:      buf.Bytes(),
+			UpdatedAt: updated,
+		}
+
+		converted, err := member.ToAPI(internal.JsonEncoding)
+		require.NoError(t, err)
+		require.Equal(t, "c1", converted.ClientId)
+		require.JSONEq(t, `{"status":"online"}`, string(converted.Data))
+		require.Equal(t, updated, converted.UpdatedAt.AsTime())
+
+		converted, err = (&PresenceMember{ClientId: "c2"}).ToAPI(internal.JsonEncoding)
+		require.NoError(t, err)
+		require.Nil(t, converted.Data)
+	})
+	t.Run("leave_other_session", func(t *testing.T) {
+		ctx := context.TODO()
+		factory := newFactory(t)
+		ch, err := factory.GetOrCreateChannel(ctx, 1, 1, "presence_leave")
+		require.NoError(t, err)
+		defer factory.DeleteChannel(ctx, ch)
+		presence := factory.GetPresence(ch)
+
+		first := &PresenceMember{ClientId: "c1", SessionId: "s1"}
+		require.NoError(t, presence.Enter(ctx, first, api.PresenceActions_enter))
+		require.NoError(t, presence.Enter(ctx, &PresenceMember{ClientId: "c1", SessionId: "s2"}, api.PresenceActions_enter))
+
+		// the leave of the expired session doesn't remove the client which has entered again
+		require.NoError(t, presence.Leave(ctx, first))
+		members, err := presence.Members(ctx)
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		require.Equal(t, "s2", members[0].SessionId)
+
+		require.NoError(t, presence.Leave(ctx, &PresenceMember{ClientId: "c1", SessionId: "s2"}))
+		members, err = presence.Members(ctx)
+		require.NoError(t, err)
+		require.Empty(t, members)
+	})
+}
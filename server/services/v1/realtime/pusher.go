@@ -15,11 +15,11 @@
 package realtime
 
 import (
-	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/store/cache"
+	"google.golang.org/protobuf/proto"
 )
 
 type DevicePusher struct {
@@ -27,26 +27,42 @@ type DevicePusher struct {
 	sessionId  string
 	socketId   string
 	encType    internal.UserDataEncType
-	connection *websocket.Conn
+	connection Connection
+	watcher    *ChannelWatcher
+	// dataType is the type of the stream data pushed to the device, the other data of the channel is skipped.
+	dataType string
 }
 
-func NewDevicePusher(session *Session, channel string) *DevicePusher {
+func NewDevicePusher(session *Session, channel string, watcher *ChannelWatcher) *DevicePusher {
+	return newPusher(session, channel, watcher, MessageChannelData)
+}
+
+// NewPresencePusher returns a pusher of the presence changes of the channel.
+func NewPresencePusher(session *Session, channel string, watcher *ChannelWatcher) *DevicePusher {
+	return newPusher(session, channel, watcher, PresenceChannelData)
+}
+
+func newPusher(session *Session, channel string, watcher *ChannelWatcher, dataType string) *DevicePusher {
 	return &DevicePusher{
 		channel:    channel,
 		sessionId:  session.id,
 		socketId:   session.socketId,
 		encType:    session.encType,
 		connection: session.conn,
+		watcher:    watcher,
+		dataType:   dataType,
 	}
 }
 
+// Watch pushes the messages to the device and returns the ids of the messages to acknowledge, the messages pushed to a
+// connection acknowledging them itself are left out.
 func (pusher *DevicePusher) Watch(events *cache.StreamMessages, err error) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
 
-	processed := make([]string, len(events.Messages))
-	for i, m := range events.Messages {
+	processed := make([]string, 0, len(events.Messages))
+	for _, m := range events.Messages {
 		data, err := events.Decode(m)
 		if err != nil {
 			continue
@@ -56,21 +72,30 @@ func (pusher *DevicePusher) Watch(events *cache.StreamMessages, err error) ([]st
 		if err != nil {
 			continue
 		}
-		processed[i] = m.ID
-		if md.ClientId == pusher.sessionId {
+		if md.ClientId == pusher.sessionId || md.DataType != pusher.dataType {
+			processed = append(processed, m.ID)
 			continue
 		}
-		pusher.sendMessage(m.ID, md, data)
+
+		var acked bool
+		if md.DataType == PresenceChannelData {
+			acked = pusher.sendPresence(m.ID, md, data)
+		} else {
+			acked = pusher.sendMessage(m.ID, md, data)
+		}
+		if !acked {
+			processed = append(processed, m.ID)
+		}
 	}
 
 	return processed, nil
 }
 
-func (pusher *DevicePusher) sendMessage(msgId string, md *StreamMessageMD, data *internal.StreamData) {
+func (pusher *DevicePusher) sendMessage(msgId string, md *StreamMessageMD, data *internal.StreamData) bool {
 	rawData, err := SanitizeUserData(pusher.encType, data)
 	if err != nil {
 		log.Err(err).Msgf("sanitizing user data failed")
-		return
+		return false
 	}
 
 	message := &api.MessageEvent{
@@ -80,6 +105,47 @@ func (pusher *DevicePusher) sendMessage(msgId string, md *StreamMessageMD, data
 		Data:    rawData,
 	}
 
-	err = SendReply(pusher.connection, pusher.encType, api.EventType_message, message)
+	acked, err := pusher.send(msgId, api.EventType_message, message)
 	log.Err(err).Msgf("failed to push message")
+	return acked
+}
+
+func (pusher *DevicePusher) sendPresence(msgId string, md *StreamMessageMD, data *internal.StreamData) bool {
+	member := &api.PresenceMember{
+		ClientId: md.ClientId,
+	}
+	if data.CreatedAt != nil {
+		member.UpdatedAt = data.CreatedAt.GetProtoTS()
+	}
+
+	if len(data.RawData) > 0 {
+		rawData, err := SanitizeUserData(pusher.encType, data)
+		if err != nil {
+			log.Err(err).Msgf("sanitizing presence data failed")
+			return false
+		}
+		member.Data = rawData
+	}
+
+	event := &api.PresenceChangeEvent{
+		Channel: pusher.channel,
+		Action:  api.PresenceActions(api.PresenceActions_value[md.EventName]),
+		Member:  member,
+	}
+
+	acked, err := pusher.send(msgId, api.EventType_presence, event)
+	log.Err(err).Msgf("failed to push presence")
+	return acked
+}
+
+// send writes the event to the connection, it returns true if the connection acknowledges the message itself. Such a
+// message is left unacknowledged even if the write fails, so that it is pushed again to the next session.
+func (pusher *DevicePusher) send(msgId string, eventType api.EventType, event proto.Message) (bool, error) {
+	conn, ok := pusher.connection.(streamConnection)
+	if !ok || pusher.watcher == nil {
+		return false, SendReply(pusher.connection, pusher.encType, eventType, event)
+	}
+
+	_, data := encodeReply(pusher.encType, eventType, event)
+	return true, conn.WriteStreamMessage(data, pusher.watcher, msgId)
 }
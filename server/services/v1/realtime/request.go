@@ -21,12 +21,19 @@ const (
 	jsonEncodingStr    = "json"
 )
 
+const (
+	UpdatedStatus string = "updated"
+	DeletedStatus string = "deleted"
+)
+
 // ConnectionParams do we need serial here as well?
 type ConnectionParams struct {
 	ProjectName string
 	SessionId   string
+	ClientId    string
 	Position    string
 	Encoding    string
+	Transport   string
 }
 
 func (params ConnectionParams) ToEncodingType() internal.UserDataEncType {
@@ -37,3 +44,11 @@ func (params ConnectionParams) ToEncodingType() internal.UserDataEncType {
 	// default is msgpack
 	return internal.MsgpackEncoding
 }
+
+func (params ConnectionParams) ToTransport() string {
+	if len(params.Transport) == 0 {
+		return WebsocketTransport
+	}
+
+	return params.Transport
+}
@@ -0,0 +1,164 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+// retentionKey is the key of the retention of a channel, it is stored under the channel.
+const retentionKey = "retention"
+
+const (
+	positionTimePrefix  = "time:"
+	positionSincePrefix = "since:"
+	positionLastPrefix  = "last:"
+)
+
+// ChannelRetention bounds the history of a channel. The messages older than MaxAge, and the oldest messages once the
+// channel has more than MaxLen messages, are trimmed. A zero value doesn't bound the history.
+type ChannelRetention struct {
+	MaxAge time.Duration `json:"max_age,omitempty"`
+	MaxLen int64         `json:"max_len,omitempty"`
+}
+
+func (r *ChannelRetention) IsEmpty() bool {
+	return r.MaxAge <= 0 && r.MaxLen <= 0
+}
+
+// minId returns the lowest message id kept at the time, or empty if the age is not bounded.
+func (r *ChannelRetention) minId(now time.Time) string {
+	if r.MaxAge <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d-0", now.Add(-r.MaxAge).UnixMilli())
+}
+
+// SetRetention stores the retention of the channel and trims its history right away. An empty retention removes the
+// bounds of the channel.
+func (factory *ChannelFactory) SetRetention(ctx context.Context, ch *Channel, retention *ChannelRetention) error {
+	if retention == nil || retention.IsEmpty() {
+		_, err := factory.cache.Delete(ctx, ch.Name(), retentionKey)
+		return err
+	}
+
+	encoded, err := jsoniter.Marshal(retention)
+	if err != nil {
+		return err
+	}
+	if err = factory.cache.Set(ctx, ch.Name(), retentionKey, internal.NewCacheData(encoded), nil); err != nil {
+		return err
+	}
+
+	_, err = ch.stream.Trim(ctx, retention.MaxLen, retention.minId(time.Now()))
+	return err
+}
+
+// GetRetention returns the retention of the channel, or nil if its history is not bounded.
+func (factory *ChannelFactory) GetRetention(ctx context.Context, ch *Channel) (*ChannelRetention, error) {
+	data, err := factory.cache.Get(ctx, ch.Name(), retentionKey, nil)
+	if err == cache.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var retention ChannelRetention
+	if err = jsoniter.Unmarshal(data.RawData, &retention); err != nil {
+		return nil, err
+	}
+
+	return &retention, nil
+}
+
+// applyRetention trims the messages of the channel that are out of its retention.
+func (factory *ChannelFactory) applyRetention(ctx context.Context, ch *Channel) error {
+	retention, err := factory.GetRetention(ctx, ch)
+	if err != nil || retention == nil {
+		return err
+	}
+
+	_, err = ch.stream.Trim(ctx, retention.MaxLen, retention.minId(time.Now()))
+	return err
+}
+
+// Position translates a replay position to a position of the stream of the channel. Besides a message id or "$", the
+// position can be "time:<RFC 3339 time>" to replay the messages published since the time, "since:<duration>" to
+// replay the messages of the last duration, for example "since:5m", or "last:<n>" to replay the last n messages. The
+// replays are bounded by the configured maximums.
+func (ch *Channel) Position(ctx context.Context, pos string) (string, error) {
+	switch {
+	case strings.HasPrefix(pos, positionTimePrefix):
+		start, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(pos, positionTimePrefix))
+		if err != nil {
+			return "", errors.InvalidArgument("invalid start time in position '%s'", pos)
+		}
+		if err = checkReplayAge(pos, time.Since(start)); err != nil {
+			return "", err
+		}
+
+		return cache.PositionAt(start), nil
+	case strings.HasPrefix(pos, positionSincePrefix):
+		since, err := time.ParseDuration(strings.TrimPrefix(pos, positionSincePrefix))
+		if err != nil || since < 0 {
+			return "", errors.InvalidArgument("invalid duration in position '%s'", pos)
+		}
+		if err = checkReplayAge(pos, since); err != nil {
+			return "", err
+		}
+
+		return cache.PositionAt(time.Now().Add(-since)), nil
+	case strings.HasPrefix(pos, positionLastPrefix):
+		last, err := strconv.ParseInt(strings.TrimPrefix(pos, positionLastPrefix), 10, 64)
+		if err != nil || last <= 0 {
+			return "", errors.InvalidArgument("invalid number of messages in position '%s'", pos)
+		}
+		if max := config.DefaultConfig.Realtime.MaxReplayMessages; max > 0 && last > max {
+			return "", errors.InvalidArgument("position '%s' replays more than %d messages", pos, max)
+		}
+
+		id, err := ch.stream.NthLastID(ctx, last)
+		if err != nil {
+			return "", err
+		}
+		if len(id) == 0 {
+			return cache.ConsumerGroupDefaultCurrentPos, nil
+		}
+
+		return cache.PositionBefore(id)
+	}
+
+	return pos, nil
+}
+
+func checkReplayAge(pos string, age time.Duration) error {
+	if max := config.DefaultConfig.Realtime.MaxReplayAge; max > 0 && age > max {
+		return errors.InvalidArgument("position '%s' replays messages older than %s", pos, max)
+	}
+
+	return nil
+}
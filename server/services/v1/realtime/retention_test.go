@@ -0,0 +1,60 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+func TestRetention(t *testing.T) {
+	t.Run("min_id", func(t *testing.T) {
+		now := time.UnixMilli(1675209600000)
+
+		require.Equal(t, "", (&ChannelRetention{MaxLen: 10}).minId(now))
+		require.Equal(t, "1675209300000-0", (&ChannelRetention{MaxAge: 5 * time.Minute}).minId(now))
+		require.True(t, (&ChannelRetention{}).IsEmpty())
+	})
+	t.Run("position", func(t *testing.T) {
+		ctx := context.TODO()
+		ch := &Channel{}
+
+		for _, pos := range []string{"", "$", "1000-1"} {
+			translated, err := ch.Position(ctx, pos)
+			require.NoError(t, err)
+			require.Equal(t, pos, translated)
+		}
+
+		start := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+		translated, err := ch.Position(ctx, "time:"+start.Format(time.RFC3339Nano))
+		require.NoError(t, err)
+		require.Equal(t, cache.PositionAt(start), translated)
+
+		before := time.Now()
+		translated, err = ch.Position(ctx, "since:5m")
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, translated, cache.PositionAt(before.Add(-5*time.Minute)))
+
+		for _, pos := range []string{"time:yesterday", "since:-5m", "since:5", "last:0", "last:x", "last:10001",
+			"since:25h", "time:2023-02-01T00:00:00Z"} {
+			_, err = ch.Position(ctx, pos)
+			require.Error(t, err, pos)
+		}
+	})
+}
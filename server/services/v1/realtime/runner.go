@@ -19,11 +19,14 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	api "github.com/tigrisdata/tigris/api/server/v1"
 	"github.com/tigrisdata/tigris/errors"
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/request"
+	"github.com/tigrisdata/tigris/server/transaction"
 	"github.com/tigrisdata/tigris/store/cache"
 )
 
@@ -33,28 +36,32 @@ type RTMRunner interface {
 }
 
 type RTMRunnerFactory struct {
-	cache   cache.Cache
-	factory *ChannelFactory
+	cache      cache.Cache
+	factory    *ChannelFactory
+	authorizer *ChannelAuthorizer
+	txMgr      *transaction.Manager
 }
 
 // NewRTMRunnerFactory returns RTMRunnerFactory object.
-func NewRTMRunnerFactory(cache cache.Cache, factory *ChannelFactory) *RTMRunnerFactory {
+func NewRTMRunnerFactory(cache cache.Cache, factory *ChannelFactory, authorizer *ChannelAuthorizer, txMgr *transaction.Manager) *RTMRunnerFactory {
 	return &RTMRunnerFactory{
-		cache:   cache,
-		factory: factory,
+		cache:      cache,
+		factory:    factory,
+		authorizer: authorizer,
+		txMgr:      txMgr,
 	}
 }
 
 func (f *RTMRunnerFactory) GetMessagesRunner(r *api.MessagesRequest) *MessagesRunner {
 	return &MessagesRunner{
-		baseRunner: newBaseRunner(f.cache, f.factory),
+		baseRunner: newBaseRunner(f.cache, f.factory, f.authorizer),
 		req:        r,
 	}
 }
 
 func (f *RTMRunnerFactory) GetReadMessagesRunner(r *api.ReadMessagesRequest, streaming Streaming) *ReadMessagesRunner {
 	return &ReadMessagesRunner{
-		baseRunner: newBaseRunner(f.cache, f.factory),
+		baseRunner: newBaseRunner(f.cache, f.factory, f.authorizer),
 		req:        r,
 		streaming:  streaming,
 	}
@@ -62,19 +69,42 @@ func (f *RTMRunnerFactory) GetReadMessagesRunner(r *api.ReadMessagesRequest, str
 
 func (f *RTMRunnerFactory) GetChannelRunner() *ChannelRunner {
 	return &ChannelRunner{
-		baseRunner: newBaseRunner(f.cache, f.factory),
+		baseRunner: newBaseRunner(f.cache, f.factory, f.authorizer),
+	}
+}
+
+func (f *RTMRunnerFactory) GetChannelPolicyRunner() *ChannelPolicyRunner {
+	return &ChannelPolicyRunner{
+		baseRunner: newBaseRunner(f.cache, f.factory, f.authorizer),
+		txMgr:      f.txMgr,
+	}
+}
+
+func (f *RTMRunnerFactory) GetChannelBridgeRunner() *ChannelBridgeRunner {
+	return &ChannelBridgeRunner{
+		baseRunner: newBaseRunner(f.cache, f.factory, f.authorizer),
+		txMgr:      f.txMgr,
+	}
+}
+
+func (f *RTMRunnerFactory) GetChannelWebhookRunner() *ChannelWebhookRunner {
+	return &ChannelWebhookRunner{
+		baseRunner: newBaseRunner(f.cache, f.factory, f.authorizer),
+		txMgr:      f.txMgr,
 	}
 }
 
 type baseRunner struct {
-	cache   cache.Cache
-	factory *ChannelFactory
+	cache      cache.Cache
+	factory    *ChannelFactory
+	authorizer *ChannelAuthorizer
 }
 
-func newBaseRunner(cache cache.Cache, factory *ChannelFactory) *baseRunner {
+func newBaseRunner(cache cache.Cache, factory *ChannelFactory, authorizer *ChannelAuthorizer) *baseRunner {
 	return &baseRunner{
-		cache:   cache,
-		factory: factory,
+		cache:      cache,
+		factory:    factory,
+		authorizer: authorizer,
 	}
 }
 
@@ -86,6 +116,14 @@ func (*baseRunner) getProject(tenant *metadata.Tenant, project string) (*metadat
 	return proj, nil
 }
 
+// authorize checks the channel policies of the project for the action of the caller on the channel.
+func (runner *baseRunner) authorize(ctx context.Context, tenant *metadata.Tenant, project *metadata.Project, channel string, action string) error {
+	// the token is missing if the authentication is disabled
+	token, _ := request.GetAccessToken(ctx)
+
+	return runner.authorizer.Authorize(ctx, tenant, project, token, channel, action)
+}
+
 // MessagesRunner is to publish messages to a channel.
 type MessagesRunner struct {
 	*baseRunner
@@ -99,6 +137,10 @@ func (runner *MessagesRunner) Run(ctx context.Context, tenant *metadata.Tenant)
 		return Response{}, err
 	}
 
+	if err = runner.authorize(ctx, tenant, project, runner.req.Channel, metadata.ChannelActionPublish); err != nil {
+		return Response{}, err
+	}
+
 	channel, err := runner.factory.GetOrCreateChannel(ctx, tenant.GetNamespace().Id(), project.Id(), runner.req.Channel)
 	if err != nil {
 		return Response{}, err
@@ -146,12 +188,19 @@ func (runner *ReadMessagesRunner) Run(ctx context.Context, tenant *metadata.Tena
 		return Response{}, err
 	}
 
+	if err = runner.authorize(ctx, tenant, project, runner.req.Channel, metadata.ChannelActionSubscribe); err != nil {
+		return Response{}, err
+	}
+
 	channel, err := runner.factory.GetChannel(ctx, tenant.GetNamespace().Id(), project.Id(), runner.req.Channel)
 	if err != nil {
 		return Response{}, err
 	}
 
-	pos := runner.req.GetStart()
+	pos, err := channel.Position(ctx, runner.req.GetStart())
+	if err != nil {
+		return Response{}, err
+	}
 	if len(pos) == 0 {
 		pos = "$"
 	}
@@ -215,6 +264,9 @@ type ChannelRunner struct {
 	channelReq        *api.GetRTChannelRequest
 	channelsReq       *api.GetRTChannelsRequest
 	listSubscriptions *api.ListSubscriptionRequest
+	presenceReq       *api.GetPresenceRequest
+	updateRetention   *api.UpdateChannelRetentionRequest
+	getRetention      *api.GetChannelRetentionRequest
 }
 
 func (runner *ChannelRunner) SetChannelReq(req *api.GetRTChannelRequest) {
@@ -229,6 +281,18 @@ func (runner *ChannelRunner) SetListSubscriptionsReq(req *api.ListSubscriptionRe
 	runner.listSubscriptions = req
 }
 
+func (runner *ChannelRunner) SetPresenceReq(req *api.GetPresenceRequest) {
+	runner.presenceReq = req
+}
+
+func (runner *ChannelRunner) SetUpdateRetentionReq(req *api.UpdateChannelRetentionRequest) {
+	runner.updateRetention = req
+}
+
+func (runner *ChannelRunner) SetGetRetentionReq(req *api.GetChannelRetentionRequest) {
+	runner.getRetention = req
+}
+
 func (runner *ChannelRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
 	switch {
 	case runner.listSubscriptions != nil:
@@ -248,6 +312,96 @@ func (runner *ChannelRunner) Run(ctx context.Context, tenant *metadata.Tenant) (
 				Devices: watchers,
 			},
 		}, nil
+	case runner.updateRetention != nil:
+		project, err := runner.getProject(tenant, runner.updateRetention.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		retention := runner.updateRetention.GetRetention()
+		if retention.GetMaxAgeSeconds() < 0 || retention.GetMaxLength() < 0 {
+			return Response{}, errors.InvalidArgument("retention bounds can't be negative")
+		}
+
+		channel, err := runner.factory.GetChannel(ctx, tenant.GetNamespace().Id(), project.Id(), runner.updateRetention.GetChannel())
+		if err != nil {
+			return Response{}, err
+		}
+
+		err = runner.factory.SetRetention(ctx, channel, &ChannelRetention{
+			MaxAge: time.Duration(retention.GetMaxAgeSeconds()) * time.Second,
+			MaxLen: retention.GetMaxLength(),
+		})
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.UpdateChannelRetentionResponse{
+				Status: UpdatedStatus,
+			},
+		}, nil
+	case runner.getRetention != nil:
+		project, err := runner.getProject(tenant, runner.getRetention.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		channel, err := runner.factory.GetChannel(ctx, tenant.GetNamespace().Id(), project.Id(), runner.getRetention.GetChannel())
+		if err != nil {
+			return Response{}, err
+		}
+
+		retention, err := runner.factory.GetRetention(ctx, channel)
+		if err != nil {
+			return Response{}, err
+		}
+
+		resp := &api.GetChannelRetentionResponse{}
+		if retention != nil {
+			resp.Retention = &api.ChannelRetention{
+				MaxAgeSeconds: int64(retention.MaxAge / time.Second),
+				MaxLength:     retention.MaxLen,
+			}
+		}
+
+		return Response{
+			Response: resp,
+		}, nil
+	case runner.presenceReq != nil:
+		project, err := runner.getProject(tenant, runner.presenceReq.Project)
+		if err != nil {
+			return Response{}, err
+		}
+
+		if err = runner.authorize(ctx, tenant, project, runner.presenceReq.Channel, metadata.ChannelActionPresence); err != nil {
+			return Response{}, err
+		}
+
+		channel, err := runner.factory.GetChannel(ctx, tenant.GetNamespace().Id(), project.Id(), runner.presenceReq.Channel)
+		if err != nil {
+			return Response{}, err
+		}
+
+		members, err := runner.factory.GetPresence(channel).Members(ctx)
+		if err != nil {
+			return Response{}, err
+		}
+
+		membersResp := make([]*api.PresenceMember, 0, len(members))
+		for _, m := range members {
+			member, err := m.ToAPI(internal.JsonEncoding)
+			if err != nil {
+				return Response{}, err
+			}
+			membersResp = append(membersResp, member)
+		}
+
+		return Response{
+			Response: &api.GetPresenceResponse{
+				Members: membersResp,
+			},
+		}, nil
 	case runner.channelsReq != nil:
 		project, err := runner.getProject(tenant, runner.channelsReq.Project)
 		if err != nil {
@@ -293,3 +447,378 @@ func (runner *ChannelRunner) Run(ctx context.Context, tenant *metadata.Tenant) (
 		}, nil
 	}
 }
+
+// ChannelPolicyRunner manages the channel policies of a project.
+type ChannelPolicyRunner struct {
+	*baseRunner
+
+	txMgr     *transaction.Manager
+	createReq *api.CreateOrUpdateChannelPolicyRequest
+	listReq   *api.ListChannelPoliciesRequest
+	deleteReq *api.DeleteChannelPolicyRequest
+}
+
+func (runner *ChannelPolicyRunner) SetCreateOrUpdateReq(req *api.CreateOrUpdateChannelPolicyRequest) {
+	runner.createReq = req
+}
+
+func (runner *ChannelPolicyRunner) SetListReq(req *api.ListChannelPoliciesRequest) {
+	runner.listReq = req
+}
+
+func (runner *ChannelPolicyRunner) SetDeleteReq(req *api.DeleteChannelPolicyRequest) {
+	runner.deleteReq = req
+}
+
+func (runner *ChannelPolicyRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tx, err := runner.txMgr.StartTx(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	switch {
+	case runner.createReq != nil:
+		project, err := runner.getProject(tenant, runner.createReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		policy := runner.createReq.GetPolicy()
+		if policy == nil {
+			return Response{}, errors.InvalidArgument("channel policy is required")
+		}
+
+		err = tenant.CreateOrUpdateChannelPolicy(ctx, tx, project, &metadata.ChannelPolicy{
+			Pattern: policy.Pattern,
+			Actions: policy.Actions,
+			Roles:   policy.Roles,
+		})
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return Response{}, err
+		}
+		runner.authorizer.Invalidate(tenant, project)
+
+		return Response{
+			Response: &api.CreateOrUpdateChannelPolicyResponse{
+				Status: UpdatedStatus,
+			},
+		}, nil
+	case runner.deleteReq != nil:
+		project, err := runner.getProject(tenant, runner.deleteReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		if err = tenant.DeleteChannelPolicy(ctx, tx, project, runner.deleteReq.GetPattern()); err != nil {
+			return Response{}, createApiError(err)
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return Response{}, err
+		}
+		runner.authorizer.Invalidate(tenant, project)
+
+		return Response{
+			Response: &api.DeleteChannelPolicyResponse{
+				Status: DeletedStatus,
+			},
+		}, nil
+	default:
+		project, err := runner.getProject(tenant, runner.listReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		policies, err := tenant.ListChannelPolicies(ctx, tx, project)
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		resp := make([]*api.ChannelPolicy, len(policies))
+		for i, p := range policies {
+			resp[i] = &api.ChannelPolicy{
+				Pattern: p.Pattern,
+				Actions: p.Actions,
+				Roles:   p.Roles,
+			}
+		}
+
+		return Response{
+			Response: &api.ListChannelPoliciesResponse{
+				Policies: resp,
+			},
+		}, nil
+	}
+}
+
+// ChannelBridgeRunner manages the collections of a project bridged to realtime channels.
+type ChannelBridgeRunner struct {
+	*baseRunner
+
+	txMgr     *transaction.Manager
+	createReq *api.CreateOrUpdateChannelBridgeRequest
+	listReq   *api.ListChannelBridgesRequest
+	deleteReq *api.DeleteChannelBridgeRequest
+}
+
+func (runner *ChannelBridgeRunner) SetCreateOrUpdateReq(req *api.CreateOrUpdateChannelBridgeRequest) {
+	runner.createReq = req
+}
+
+func (runner *ChannelBridgeRunner) SetListReq(req *api.ListChannelBridgesRequest) {
+	runner.listReq = req
+}
+
+func (runner *ChannelBridgeRunner) SetDeleteReq(req *api.DeleteChannelBridgeRequest) {
+	runner.deleteReq = req
+}
+
+func (runner *ChannelBridgeRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	tx, err := runner.txMgr.StartTx(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	switch {
+	case runner.createReq != nil:
+		project, err := runner.getProject(tenant, runner.createReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		bridge := runner.createReq.GetBridge()
+		if bridge == nil {
+			return Response{}, errors.InvalidArgument("channel bridge is required")
+		}
+		if err = checkChannelName(bridge.Channel); err != nil {
+			return Response{}, err
+		}
+		if err = validateBridgeFilter(project, bridge); err != nil {
+			return Response{}, err
+		}
+
+		err = tenant.CreateOrUpdateChannelBridge(ctx, tx, project, &metadata.ChannelBridge{
+			Collection: bridge.Collection,
+			Channel:    bridge.Channel,
+			Filter:     bridge.Filter,
+		})
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.CreateOrUpdateChannelBridgeResponse{
+				Status: UpdatedStatus,
+			},
+		}, nil
+	case runner.deleteReq != nil:
+		project, err := runner.getProject(tenant, runner.deleteReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		if err = tenant.DeleteChannelBridge(ctx, tx, project, runner.deleteReq.GetCollection(), runner.deleteReq.GetChannel()); err != nil {
+			return Response{}, createApiError(err)
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.DeleteChannelBridgeResponse{
+				Status: DeletedStatus,
+			},
+		}, nil
+	default:
+		project, err := runner.getProject(tenant, runner.listReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		bridges, err := tenant.ListChannelBridges(ctx, tx, project)
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		resp := make([]*api.ChannelBridge, len(bridges))
+		for i, b := range bridges {
+			resp[i] = &api.ChannelBridge{
+				Collection: b.Collection,
+				Channel:    b.Channel,
+				Filter:     b.Filter,
+			}
+		}
+
+		return Response{
+			Response: &api.ListChannelBridgesResponse{
+				Bridges: resp,
+			},
+		}, nil
+	}
+}
+
+// ChannelWebhookRunner manages the webhooks the events of the realtime channels of a project are delivered to.
+type ChannelWebhookRunner struct {
+	*baseRunner
+
+	txMgr          *transaction.Manager
+	createReq      *api.CreateOrUpdateChannelWebhookRequest
+	listReq        *api.ListChannelWebhooksRequest
+	deleteReq      *api.DeleteChannelWebhookRequest
+	deadLettersReq *api.ListWebhookDeadLettersRequest
+}
+
+func (runner *ChannelWebhookRunner) SetCreateOrUpdateReq(req *api.CreateOrUpdateChannelWebhookRequest) {
+	runner.createReq = req
+}
+
+func (runner *ChannelWebhookRunner) SetListReq(req *api.ListChannelWebhooksRequest) {
+	runner.listReq = req
+}
+
+func (runner *ChannelWebhookRunner) SetDeleteReq(req *api.DeleteChannelWebhookRequest) {
+	runner.deleteReq = req
+}
+
+func (runner *ChannelWebhookRunner) SetDeadLettersReq(req *api.ListWebhookDeadLettersRequest) {
+	runner.deadLettersReq = req
+}
+
+func (runner *ChannelWebhookRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	if runner.deadLettersReq != nil {
+		return runner.listDeadLetters(ctx, tenant)
+	}
+
+	tx, err := runner.txMgr.StartTx(ctx)
+	if err != nil {
+		return Response{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	switch {
+	case runner.createReq != nil:
+		project, err := runner.getProject(tenant, runner.createReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		webhook := runner.createReq.GetWebhook()
+		if webhook == nil {
+			return Response{}, errors.InvalidArgument("channel webhook is required")
+		}
+
+		err = tenant.CreateOrUpdateChannelWebhook(ctx, tx, project, &metadata.ChannelWebhook{
+			Name:    webhook.Name,
+			Pattern: webhook.Pattern,
+			URL:     webhook.Url,
+			Secret:  webhook.Secret,
+			Events:  webhook.Events,
+		})
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.CreateOrUpdateChannelWebhookResponse{
+				Status: UpdatedStatus,
+			},
+		}, nil
+	case runner.deleteReq != nil:
+		project, err := runner.getProject(tenant, runner.deleteReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		if err = tenant.DeleteChannelWebhook(ctx, tx, project, runner.deleteReq.GetName()); err != nil {
+			return Response{}, createApiError(err)
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return Response{}, err
+		}
+
+		err = deleteDeadLetters(ctx, runner.cache, runner.factory.encoder, tenant.GetNamespace().Id(), project.Id(), runner.deleteReq.GetName())
+		if err != nil {
+			return Response{}, err
+		}
+
+		return Response{
+			Response: &api.DeleteChannelWebhookResponse{
+				Status: DeletedStatus,
+			},
+		}, nil
+	default:
+		project, err := runner.getProject(tenant, runner.listReq.GetProject())
+		if err != nil {
+			return Response{}, err
+		}
+
+		webhooks, err := tenant.ListChannelWebhooks(ctx, tx, project)
+		if err != nil {
+			return Response{}, createApiError(err)
+		}
+
+		// the secrets are not returned
+		resp := make([]*api.ChannelWebhook, len(webhooks))
+		for i, w := range webhooks {
+			resp[i] = &api.ChannelWebhook{
+				Name:    w.Name,
+				Pattern: w.Pattern,
+				Url:     w.URL,
+				Events:  w.Events,
+			}
+		}
+
+		return Response{
+			Response: &api.ListChannelWebhooksResponse{
+				Webhooks: resp,
+			},
+		}, nil
+	}
+}
+
+func (runner *ChannelWebhookRunner) listDeadLetters(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	project, err := runner.getProject(tenant, runner.deadLettersReq.GetProject())
+	if err != nil {
+		return Response{}, err
+	}
+
+	limit := runner.deadLettersReq.GetLimit()
+	if limit <= 0 || limit > webhookDeadLettersMaxLen {
+		limit = webhookDeadLettersMaxLen
+	}
+
+	messages, err := readDeadLetters(ctx, runner.cache, runner.factory.encoder, tenant.GetNamespace().Id(), project.Id(), runner.deadLettersReq.GetName(), limit)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var decoder cache.StreamMessages
+	letters := make([]*api.WebhookDeadLetter, 0, len(messages))
+	for _, m := range messages {
+		data, err := decoder.Decode(m)
+		if err != nil {
+			return Response{}, err
+		}
+
+		letters = append(letters, &api.WebhookDeadLetter{
+			Id:   m.ID,
+			Data: data.RawData,
+		})
+	}
+
+	return Response{
+		Response: &api.ListWebhookDeadLettersResponse{
+			DeadLetters: letters,
+		},
+	}, nil
+}
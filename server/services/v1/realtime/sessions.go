@@ -19,8 +19,8 @@ import (
 	"sync"
 	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/uuid"
 	"github.com/tigrisdata/tigris/server/metadata"
 	"github.com/tigrisdata/tigris/server/request"
 	"github.com/tigrisdata/tigris/server/transaction"
@@ -41,11 +41,12 @@ type Sessions struct {
 	tenantMgr        *metadata.TenantManager
 	channelFactory   *ChannelFactory
 	heartbeatFactory *HeartbeatFactory
+	authorizer       *ChannelAuthorizer
 	versionH         *metadata.VersionHandler
 	tenantTracker    *metadata.CacheTracker
 }
 
-func NewSessionMgr(cache cache.Cache, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager, heartbeatF *HeartbeatFactory, factory *ChannelFactory) *Sessions {
+func NewSessionMgr(cache cache.Cache, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager, heartbeatF *HeartbeatFactory, factory *ChannelFactory, authorizer *ChannelAuthorizer) *Sessions {
 	return &Sessions{
 		cache:            cache,
 		txMgr:            txMgr,
@@ -53,6 +54,7 @@ func NewSessionMgr(cache cache.Cache, tenantMgr *metadata.TenantManager, txMgr *
 		heartbeatFactory: heartbeatF,
 		devices:          make(map[string]*Session),
 		channelFactory:   factory,
+		authorizer:       authorizer,
 		tenantTracker:    metadata.NewCacheTracker(tenantMgr, txMgr),
 	}
 }
@@ -80,7 +82,8 @@ func (s *Sessions) pingSessions() {
 	defer s.RUnlock()
 
 	for _, d := range s.devices {
-		if !d.IsActive() {
+		// the heartbeats would only pile up in the buffer of the poll sessions
+		if !d.IsActive() && d.transport != PollTransport {
 			_ = d.sendHeartbeat()
 		}
 	}
@@ -92,28 +95,59 @@ func (s *Sessions) destroyInactiveSessions() {
 
 	for _, d := range s.devices {
 		if !d.IsActive() {
-			s.RemoveDevice(context.TODO(), d)
+			if d.transport == PollTransport {
+				// nothing else closes a poll session once the device stops polling
+				_ = d.Close()
+			}
+			s.removeDevice(d)
 		}
 	}
 }
 
 func (s *Sessions) RemoveDevice(_ context.Context, session *Session) {
-	if session, ok := s.devices[session.id]; ok {
-		delete(s.devices, session.id)
-	}
+	s.Lock()
+	defer s.Unlock()
+
+	s.removeDevice(session)
 }
 
-func (s *Sessions) AddDevice(ctx context.Context, conn *websocket.Conn, params ConnectionParams) (*Session, error) {
-	if device, ok := s.devices[params.SessionId]; ok {
-		return device, nil
+func (s *Sessions) removeDevice(session *Session) {
+	// the session may have been taken over by a new connection of the device
+	if device, ok := s.devices[session.id]; ok && device == session {
+		delete(s.devices, session.id)
 	}
+}
 
+// AddDevice creates the session of a new connection. If the device is reconnecting with the id of a session that is
+// still open, on the same or on another transport, the previous connection is closed and the session continues on the
+// new one.
+func (s *Sessions) AddDevice(ctx context.Context, conn Connection, params ConnectionParams) (*Session, error) {
 	sess, err := s.CreateDeviceSession(ctx, conn, params)
 	if err != nil {
 		return nil, err
 	}
+
+	return s.addSession(sess), nil
+}
+
+// addSession registers the session. An open session with the same id is only taken over by a session of the same
+// namespace, project and token subject, any other session gets a new id so that it can't read the events of the open
+// session.
+func (s *Sessions) addSession(sess *Session) *Session {
+	s.Lock()
+	device, ok := s.devices[sess.id]
+	if ok && !device.sameOwner(sess) {
+		sess.id = uuid.NewUUIDAsString()
+		ok = false
+	}
 	s.devices[sess.id] = sess
-	return sess, nil
+	s.Unlock()
+
+	if ok {
+		_ = device.Close()
+	}
+
+	return sess
 }
 
 func (s *Sessions) ExecuteRunner(ctx context.Context, runner RTMRunner) (Response, error) {
@@ -0,0 +1,373 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/errors"
+)
+
+const (
+	WebsocketTransport = "websocket"
+	SSETransport       = "sse"
+	PollTransport      = "poll"
+)
+
+const (
+	sseKeepAliveDuration = 10 * time.Second
+	// PollTimeout is how long a poll request waits for messages before returning an empty response.
+	PollTimeout = 25 * time.Second
+	// pollBufferSize is the maximum number of channel messages kept for a poll session until the device confirms them,
+	// the channels aren't read while it is full.
+	pollBufferSize = 1024
+)
+
+var errConnectionClosed = fmt.Errorf("connection closed")
+
+// Connection is the transport the events are pushed to the device with. A websocket connection is used as is, the
+// SSE and long-poll transports only push events, the device publishes over the REST API.
+type Connection interface {
+	WriteMessage(messageType int, data []byte) error
+	Close() error
+}
+
+// messageReader is implemented by the transports receiving events from the device.
+type messageReader interface {
+	ReadMessage() (int, []byte, error)
+}
+
+// SSEConnection pushes the events as Server-Sent Events on the response of a long-lived HTTP request. Every event
+// carries the session id as its event id so that the browsers reconnect to the same session using the Last-Event-ID
+// header.
+type SSEConnection struct {
+	sync.Mutex
+
+	w         http.ResponseWriter
+	flusher   http.Flusher
+	eventId   string
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewSSEConnection(w http.ResponseWriter) (*SSEConnection, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.Internal("streaming is not supported by the response writer")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disables the response buffering of the proxies
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &SSEConnection{
+		w:       w,
+		flusher: flusher,
+		closed:  make(chan struct{}),
+	}, nil
+}
+
+func (conn *SSEConnection) WriteMessage(_ int, data []byte) error {
+	if len(conn.eventId) > 0 {
+		return conn.write(fmt.Sprintf("id: %s\ndata: %s\n\n", conn.eventId, data))
+	}
+
+	return conn.write(fmt.Sprintf("data: %s\n\n", data))
+}
+
+// KeepAlive writes a comment to stop the proxies from closing an idle connection.
+func (conn *SSEConnection) KeepAlive() error {
+	return conn.write(": keep-alive\n\n")
+}
+
+func (conn *SSEConnection) write(event string) error {
+	conn.Lock()
+	defer conn.Unlock()
+
+	select {
+	case <-conn.closed:
+		return errConnectionClosed
+	default:
+	}
+
+	if _, err := conn.w.Write([]byte(event)); err != nil {
+		return err
+	}
+	conn.flusher.Flush()
+
+	return nil
+}
+
+func (conn *SSEConnection) Close() error {
+	conn.closeOnce.Do(func() {
+		close(conn.closed)
+	})
+
+	return nil
+}
+
+// streamConnection is implemented by the transports acknowledging the channel messages themselves, once the device has
+// received them, rather than as soon as they are written to the connection.
+type streamConnection interface {
+	WriteStreamMessage(data []byte, watcher *ChannelWatcher, id string) error
+}
+
+// pollMessage is an event kept for a poll session. The watcher and the id of the channel message are set for the events
+// read from a channel, the message is acknowledged once the device has received the event.
+type pollMessage struct {
+	seq     uint64
+	data    []byte
+	watcher *ChannelWatcher
+	id      string
+}
+
+// PollConnection keeps the events of a long-poll session until the device confirms it has received them. Every poll
+// returns the cursor of its last event, the next poll passes it back and the events up to it are dropped and their
+// channel messages acknowledged. The events after the cursor are returned again, so the events of a response that never
+// reached the device are not lost. Once the buffer is full the channel messages aren't read until the device polls.
+type PollConnection struct {
+	sync.Mutex
+
+	messages []pollMessage
+	seq      uint64
+	// returned is the sequence of the last event returned by a poll.
+	returned uint64
+	notify   chan struct{}
+	space    *sync.Cond
+	closed   bool
+}
+
+func NewPollConnection() *PollConnection {
+	conn := &PollConnection{
+		notify: make(chan struct{}, 1),
+	}
+	conn.space = sync.NewCond(&conn.Mutex)
+
+	return conn
+}
+
+func (conn *PollConnection) WriteMessage(_ int, data []byte) error {
+	return conn.write(pollMessage{data: data}, false)
+}
+
+// WriteStreamMessage keeps the event of the channel message, waiting while the buffer is full. The message is
+// acknowledged in the channel once the device confirms it has received the event.
+func (conn *PollConnection) WriteStreamMessage(data []byte, watcher *ChannelWatcher, id string) error {
+	return conn.write(pollMessage{data: data, watcher: watcher, id: id}, true)
+}
+
+func (conn *PollConnection) write(message pollMessage, wait bool) error {
+	conn.Lock()
+	defer conn.Unlock()
+
+	for wait && !conn.closed && len(conn.messages) >= pollBufferSize {
+		conn.space.Wait()
+	}
+	if conn.closed {
+		return errConnectionClosed
+	}
+
+	conn.seq++
+	message.seq = conn.seq
+	conn.messages = append(conn.messages, message)
+
+	select {
+	case conn.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Poll confirms the events up to the cursor, or all the events returned so far if the cursor is nil, and returns the
+// events not confirmed yet with the cursor of the last one. It waits until an event is written if there are none, and
+// returns no events when the timeout expires or the context is done.
+func (conn *PollConnection) Poll(ctx context.Context, timeout time.Duration, cursor *uint64) ([][]byte, uint64) {
+	conn.confirm(ctx, cursor)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		if messages, last := conn.unconfirmed(); len(messages) > 0 {
+			return messages, last
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, conn.cursor()
+		case <-timer.C:
+			return nil, conn.cursor()
+		case <-conn.notify:
+		}
+	}
+}
+
+// confirm drops the events received by the device and acknowledges their channel messages. A cursor after the last
+// returned event, from a poll served by another server, only confirms the events returned by this one.
+func (conn *PollConnection) confirm(ctx context.Context, cursor *uint64) {
+	conn.Lock()
+	upTo := conn.returned
+	if cursor != nil && *cursor < upTo {
+		upTo = *cursor
+	}
+
+	n := 0
+	for n < len(conn.messages) && conn.messages[n].seq <= upTo {
+		n++
+	}
+	confirmed := conn.messages[:n]
+	conn.messages = conn.messages[n:]
+	if n > 0 {
+		conn.space.Broadcast()
+	}
+	conn.Unlock()
+
+	ids := make(map[*ChannelWatcher][]string)
+	for _, m := range confirmed {
+		if m.watcher != nil {
+			ids[m.watcher] = append(ids[m.watcher], m.id)
+		}
+	}
+	for watcher, watcherIds := range ids {
+		if err := watcher.ack(ctx, watcherIds); err != nil {
+			log.Err(err).Str("watcher", watcher.name).Msg("acknowledging polled messages failed")
+		}
+	}
+}
+
+func (conn *PollConnection) unconfirmed() ([][]byte, uint64) {
+	conn.Lock()
+	defer conn.Unlock()
+
+	if len(conn.messages) == 0 {
+		return nil, conn.returned
+	}
+
+	messages := make([][]byte, len(conn.messages))
+	for i, m := range conn.messages {
+		messages[i] = m.data
+	}
+	conn.returned = conn.messages[len(conn.messages)-1].seq
+
+	return messages, conn.returned
+}
+
+func (conn *PollConnection) cursor() uint64 {
+	conn.Lock()
+	defer conn.Unlock()
+
+	return conn.returned
+}
+
+func (conn *PollConnection) Close() error {
+	conn.Lock()
+	defer conn.Unlock()
+
+	conn.closed = true
+	conn.space.Broadcast()
+
+	return nil
+}
+
+// Stream subscribes the SSE session to the channels and keeps it alive until the request is done or the session is
+// taken over by another connection.
+func (session *Session) Stream(ctx context.Context, conn *SSEConnection, channels []string, position string) {
+	conn.eventId = session.id
+	_ = session.SendConnSuccess()
+
+	for _, ch := range channels {
+		if errEvent := session.Subscribe(ctx, ch, position); errEvent != nil {
+			session.sendError(errEvent)
+		}
+	}
+
+	ticker := time.NewTicker(sseKeepAliveDuration)
+	defer ticker.Stop()
+	for {
+		_ = session.heartbeat.Ping(session.id)
+		session.received()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-conn.closed:
+			return
+		case <-ticker.C:
+			if err := conn.KeepAlive(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Poll returns the events pushed to the poll session that the device hasn't confirmed with the cursor, along with the
+// cursor of the last event. The session is created on the first poll, or when the device comes back from another
+// transport, and is subscribed to the channels it isn't watching.
+func (s *Sessions) Poll(ctx context.Context, params ConnectionParams, channels []string, cursor *uint64) (*Session, [][]byte, uint64, error) {
+	session, err := s.getPollDevice(ctx, params)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	messages, last := session.Poll(ctx, channels, params.Position, cursor)
+	return session, messages, last, nil
+}
+
+// Poll subscribes the poll session to the channels it isn't watching and returns the events the device hasn't
+// confirmed with the cursor.
+func (session *Session) Poll(ctx context.Context, channels []string, position string, cursor *uint64) ([][]byte, uint64) {
+	_ = session.heartbeat.Ping(session.id)
+	session.received()
+
+	for _, ch := range channels {
+		// the watchers outlive the poll request
+		if errEvent := session.Subscribe(context.TODO(), ch, position); errEvent != nil {
+			session.sendError(errEvent)
+		}
+	}
+
+	return session.conn.(*PollConnection).Poll(ctx, PollTimeout, cursor)
+}
+
+// getPollDevice returns the open poll session of the device, it is only reused by the owner of the session. A new
+// session is created otherwise.
+func (s *Sessions) getPollDevice(ctx context.Context, params ConnectionParams) (*Session, error) {
+	session, err := s.CreateDeviceSession(ctx, NewPollConnection(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.RLock()
+	device, ok := s.devices[session.id]
+	s.RUnlock()
+	if ok && device.transport == PollTransport && !device.isClosed() && device.sameOwner(session) {
+		return device, nil
+	}
+
+	session = s.addSession(session)
+	_ = session.SendConnSuccess()
+
+	return session, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/types"
+	"github.com/tigrisdata/tigris/store/cache"
+)
+
+func TestTransport(t *testing.T) {
+	t.Run("sse", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		conn, err := NewSSEConnection(w)
+		require.NoError(t, err)
+		require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+		require.NoError(t, conn.WriteMessage(0, []byte(`{"event_type":1}`)))
+		conn.eventId = "s1"
+		require.NoError(t, conn.WriteMessage(0, []byte(`{"event_type":2}`)))
+		require.NoError(t, conn.KeepAlive())
+		require.Equal(t, "data: {\"event_type\":1}\n\nid: s1\ndata: {\"event_type\":2}\n\n: keep-alive\n\n", w.Body.String())
+
+		require.NoError(t, conn.Close())
+		require.Equal(t, errConnectionClosed, conn.WriteMessage(0, []byte(`{}`)))
+	})
+	t.Run("poll", func(t *testing.T) {
+		conn := NewPollConnection()
+		messages, cursor := conn.Poll(context.TODO(), 10*time.Millisecond, nil)
+		require.Nil(t, messages)
+		require.Equal(t, uint64(0), cursor)
+
+		require.NoError(t, conn.WriteMessage(0, []byte("1")))
+		require.NoError(t, conn.WriteMessage(0, []byte("2")))
+		messages, cursor = conn.Poll(context.TODO(), time.Second, nil)
+		require.Equal(t, [][]byte{[]byte("1"), []byte("2")}, messages)
+		require.Equal(t, uint64(2), cursor)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = conn.WriteMessage(0, []byte("3"))
+		}()
+		messages, cursor = conn.Poll(context.TODO(), time.Second, nil)
+		require.Equal(t, [][]byte{[]byte("3")}, messages)
+		require.Equal(t, uint64(3), cursor)
+
+		require.NoError(t, conn.Close())
+		require.Equal(t, errConnectionClosed, conn.WriteMessage(0, []byte("4")))
+	})
+	t.Run("poll_cursor", func(t *testing.T) {
+		conn := NewPollConnection()
+		require.NoError(t, conn.WriteMessage(0, []byte("1")))
+		require.NoError(t, conn.WriteMessage(0, []byte("2")))
+		messages, cursor := conn.Poll(context.TODO(), time.Second, nil)
+		require.Len(t, messages, 2)
+
+		// the response was lost, the device polls again with its previous cursor
+		var none uint64
+		require.NoError(t, conn.WriteMessage(0, []byte("3")))
+		messages, cursor = conn.Poll(context.TODO(), time.Second, &none)
+		require.Equal(t, [][]byte{[]byte("1"), []byte("2"), []byte("3")}, messages)
+		require.Equal(t, uint64(3), cursor)
+
+		messages, _ = conn.Poll(context.TODO(), 10*time.Millisecond, &cursor)
+		require.Nil(t, messages)
+
+		// a cursor from another server doesn't confirm the events not returned yet
+		require.NoError(t, conn.WriteMessage(0, []byte("4")))
+		ahead := uint64(100)
+		messages, _ = conn.Poll(context.TODO(), time.Second, &ahead)
+		require.Equal(t, [][]byte{[]byte("4")}, messages)
+	})
+	t.Run("poll_buffer_full", func(t *testing.T) {
+		conn := NewPollConnection()
+		for i := 0; i < pollBufferSize; i++ {
+			require.NoError(t, conn.WriteStreamMessage([]byte(fmt.Sprint(i)), nil, ""))
+		}
+
+		written := make(chan error)
+		go func() {
+			written <- conn.WriteStreamMessage([]byte("last"), nil, "")
+		}()
+
+		messages, cursor := conn.Poll(context.TODO(), time.Second, nil)
+		require.Len(t, messages, pollBufferSize)
+		require.Equal(t, []byte("0"), messages[0])
+		select {
+		case <-written:
+			require.Fail(t, "the write didn't wait for the device to confirm the events")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		messages, _ = conn.Poll(context.TODO(), time.Second, &cursor)
+		require.NoError(t, <-written)
+		require.Equal(t, [][]byte{[]byte("last")}, messages)
+	})
+	t.Run("poll_ack", func(t *testing.T) {
+		ctx := context.TODO()
+		stream, err := cache.NewCache(config.GetTestCacheConfig()).CreateStream(ctx, "poll_ack_test")
+		require.NoError(t, err)
+		defer func() { _ = stream.Delete(ctx) }()
+
+		watcher, err := CreateAndRegisterWatcher(ctx, "poll_ack_session", "", stream)
+		require.NoError(t, err)
+
+		id, err := stream.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte(`{}`)))
+		require.NoError(t, err)
+		read, _, err := stream.ReadGroup(ctx, watcher.name, cache.ReadGroupPosCurrent)
+		require.NoError(t, err)
+		require.Len(t, read.Messages, 1)
+
+		conn := NewPollConnection()
+		require.NoError(t, conn.WriteStreamMessage([]byte("1"), watcher, id))
+		_, cursor := conn.Poll(ctx, time.Second, nil)
+
+		// the message is pending until the device confirms it
+		pending, _, err := stream.ReadGroup(ctx, watcher.name, cache.ReadGroupPosStart)
+		require.NoError(t, err)
+		require.Len(t, pending.Messages, 1)
+
+		_, _ = conn.Poll(ctx, 10*time.Millisecond, &cursor)
+		pending, _, err = stream.ReadGroup(ctx, watcher.name, cache.ReadGroupPosStart)
+		require.NoError(t, err)
+		require.Nil(t, pending)
+	})
+	t.Run("poll_concurrent", func(t *testing.T) {
+		ctx := context.TODO()
+		factory := newFactory(t)
+		for _, name := range []string{"poll_concurrent_1", "poll_concurrent_2"} {
+			ch, err := factory.GetOrCreateChannel(ctx, 1, 1, name)
+			require.NoError(t, err)
+			defer factory.DeleteChannel(ctx, ch)
+		}
+
+		session := newTestSession(factory, "poll_concurrent_session", "")
+		pollCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for _, name := range []string{"poll_concurrent_1", "poll_concurrent_2"} {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				session.Poll(pollCtx, []string{name}, "", nil)
+			}(name)
+		}
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, session.Close())
+		wg.Wait()
+
+		require.Empty(t, session.watchers)
+		require.Nil(t, session.Subscribe(ctx, "poll_concurrent_1", ""))
+		require.Empty(t, session.watchers)
+	})
+	t.Run("take_over", func(t *testing.T) {
+		factory := newFactory(t)
+		sessions := &Sessions{devices: make(map[string]*Session)}
+
+		owner := sessions.addSession(newTestSession(factory, "take_over_session", "owner"))
+		other := sessions.addSession(newTestSession(factory, "take_over_session", "other"))
+		require.NotEqual(t, "take_over_session", other.id)
+		require.False(t, owner.isClosed())
+
+		reconnected := sessions.addSession(newTestSession(factory, "take_over_session", "owner"))
+		require.Equal(t, "take_over_session", reconnected.id)
+		require.True(t, owner.isClosed())
+	})
+	t.Run("transport", func(t *testing.T) {
+		require.Equal(t, WebsocketTransport, ConnectionParams{}.ToTransport())
+		require.Equal(t, SSETransport, ConnectionParams{Transport: SSETransport}.ToTransport())
+	})
+}
+
+// newTestSession returns a poll session of the project 1 of the namespace 1, connected with a token of the subject.
+func newTestSession(factory *ChannelFactory, id string, sub string) *Session {
+	tenant := metadata.NewTenant(metadata.NewTenantNamespace("ns", metadata.NewNamespaceMetadata(1, "ns", "ns")),
+		nil, nil, metadata.NewMetadataDictionary(metadata.DefaultNameRegistry), nil, nil, nil, nil)
+
+	var token *types.AccessToken
+	if len(sub) > 0 {
+		token = &types.AccessToken{Sub: sub}
+	}
+
+	return &Session{
+		id:         id,
+		conn:       NewPollConnection(),
+		encType:    internal.JsonEncoding,
+		transport:  PollTransport,
+		tenant:     tenant,
+		project:    metadata.NewProject(1, "project"),
+		chFactory:  factory,
+		watchers:   make(map[string]*ChannelWatcher),
+		presence:   make(map[string]*ChannelPresence),
+		authorizer: NewChannelAuthorizer(nil),
+		token:      token,
+		heartbeat:  factory.heartbeatF.GetHeartbeatTable(1, 1),
+	}
+}
@@ -35,6 +35,9 @@ type ChannelWatcher struct {
 	stream        cache.Stream
 	sigStop       chan struct{}
 	sigDisconnect chan struct{}
+	// replayFrom is set to read the messages delivered to the consumer group but never acknowledged before the new
+	// ones, it is the position after the last pending message read.
+	replayFrom cache.ReadGroupPos
 }
 
 func CreateWatcher(ctx context.Context, name string, pos string, existingPos string, stream cache.Stream) (*ChannelWatcher, error) {
@@ -83,6 +86,13 @@ func (watcher *ChannelWatcher) StartWatching(watch Watch) {
 	go watcher.watchEvents()
 }
 
+// ReplayPending makes the watcher push again the messages that have been read but not acknowledged, before the new
+// ones. It is used by the poll sessions which only acknowledge the messages received by the device, the messages read
+// by a session on another server are pushed by this one. It needs to be called before StartWatching.
+func (watcher *ChannelWatcher) ReplayPending() {
+	watcher.replayFrom = cache.ReadGroupPosStart
+}
+
 func (watcher *ChannelWatcher) move(ctx context.Context, newPos string) error {
 	if len(newPos) == 0 {
 		return nil
@@ -108,12 +118,24 @@ func (watcher *ChannelWatcher) watchEvents() {
 			_ = watcher.stream.RemoveConsumerGroup(watcher.ctx, watcher.name)
 			return
 		default:
-			resp, hasData, err := watcher.stream.ReadGroup(watcher.ctx, watcher.name, cache.ReadGroupPosCurrent)
+			pos := cache.ReadGroupPosCurrent
+			if len(watcher.replayFrom) > 0 {
+				pos = watcher.replayFrom
+			}
+
+			resp, hasData, err := watcher.stream.ReadGroup(watcher.ctx, watcher.name, pos)
 			if err != nil {
 				continue
 			}
+			if len(watcher.replayFrom) > 0 {
+				// the pending messages are read page by page until there are none left
+				watcher.replayFrom = ""
+				if resp != nil && len(resp.Messages) > 0 {
+					watcher.replayFrom = cache.ReadGroupPos(resp.Messages[len(resp.Messages)-1].ID)
+				}
+			}
 
-			if !hasData {
+			if !hasData || resp == nil {
 				continue
 			}
 
@@ -125,5 +147,9 @@ func (watcher *ChannelWatcher) watchEvents() {
 }
 
 func (watcher *ChannelWatcher) ack(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
 	return watcher.stream.Ack(ctx, watcher.name, ids...)
 }
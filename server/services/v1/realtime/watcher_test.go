@@ -17,9 +17,12 @@ package realtime
 import (
 	"context"
 	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/store/cache"
 )
@@ -58,4 +61,51 @@ func TestWatcher(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, groups, 1)
 	})
+	t.Run("replay_pending", func(t *testing.T) {
+		stream, err := cacheS.CreateStream(ctx, "ch_replay_test")
+		require.NoError(t, err)
+		defer func() {
+			_ = stream.Delete(ctx)
+		}()
+
+		w, err := CreateAndRegisterWatcher(ctx, "device1", "", stream)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err = stream.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte(`{}`)))
+			require.NoError(t, err)
+		}
+		read, _, err := stream.ReadGroup(ctx, w.name, cache.ReadGroupPosCurrent)
+		require.NoError(t, err)
+		require.Len(t, read.Messages, 3)
+		_, err = stream.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte(`{}`)))
+		require.NoError(t, err)
+
+		var (
+			mu  sync.Mutex
+			ids []string
+		)
+		w.ReplayPending()
+		// nothing is acknowledged, like a poll session before the device confirms the messages
+		w.StartWatching(func(messages *cache.StreamMessages, _ error) ([]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, m := range messages.Messages {
+				ids = append(ids, m.ID)
+			}
+			return nil, nil
+		})
+		defer w.Stop()
+
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(ids) >= 4
+		}, 5*time.Second, 10*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, ids, 4)
+		require.Equal(t, read.Messages[0].ID, ids[0])
+	})
 }
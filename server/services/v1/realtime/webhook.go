@@ -0,0 +1,587 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	xredis "github.com/go-redis/redis/v8"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/cache"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	WebhookTimestampHeader = "X-Tigris-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Tigris-Webhook-Signature"
+)
+
+const (
+	// webhookSyncInterval is how often the webhooks of the projects are matched with the channels, the new channels and
+	// the changes of the webhooks are picked up after it.
+	webhookSyncInterval   = 30 * time.Second
+	webhookTimeout        = 10 * time.Second
+	webhookMaxAttempts    = 5
+	webhookInitialBackoff = 1 * time.Second
+	webhookMaxBackoff     = 30 * time.Second
+	// webhookMaxRetries is the number of failed deliveries of a webhook waiting to be retried, the events failing
+	// beyond it are added to the dead letters without being retried.
+	webhookMaxRetries = 100
+	// webhookDeadLettersMaxLen is the number of failed deliveries kept for a webhook, the oldest are dropped first.
+	webhookDeadLettersMaxLen = 1000
+	webhookWatcherPrefix     = "_webhook:"
+	webhookDeadLettersTable  = "webhook_dead_letters"
+)
+
+// WebhookPayload is the body posted to a webhook for an event of a channel.
+type WebhookPayload struct {
+	Project  string              `json:"project"`
+	Channel  string              `json:"channel"`
+	Id       string              `json:"id"`
+	Type     string              `json:"type"`
+	Name     string              `json:"name"`
+	ClientId string              `json:"client_id,omitempty"`
+	Data     jsoniter.RawMessage `json:"data,omitempty"`
+}
+
+// WebhookDeadLetter is an event that couldn't be delivered to a webhook.
+type WebhookDeadLetter struct {
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error"`
+	Event    *WebhookPayload `json:"event"`
+}
+
+type webhookRetry struct {
+	payload  *WebhookPayload
+	attempts int
+}
+
+type webhookWorker struct {
+	webhook  metadata.ChannelWebhook
+	tenantId uint32
+	projId   uint32
+	channel  *Channel
+	watcher  string
+
+	// ctx is cancelled when the worker stops, it ends the deliveries in flight and the retries waiting for the backoff
+	ctx     context.Context
+	cancel  context.CancelFunc
+	retries chan *webhookRetry
+	// pending is the number of retries queued or in flight, the events read while it isn't zero are queued behind
+	// them instead of being posted to a failing webhook
+	pending int32
+}
+
+// WebhookDispatcher delivers the events of the realtime channels to the webhooks of the projects. A webhook reads a
+// channel through its own consumer group, so that an event is delivered once the realtime servers share the work, in
+// order and at least once. A failed delivery is retried with an exponential backoff off the read loop of the channel,
+// the events that still can't be delivered are added to the dead letters of the webhook.
+type WebhookDispatcher struct {
+	sync.Mutex
+
+	cache     cache.Cache
+	encoder   metadata.CacheEncoder
+	tenantMgr *metadata.TenantManager
+	txMgr     *transaction.Manager
+	factory   *ChannelFactory
+	client    *http.Client
+	workers   map[string]*webhookWorker
+}
+
+func NewWebhookDispatcher(cache cache.Cache, encoder metadata.CacheEncoder, tenantMgr *metadata.TenantManager, txMgr *transaction.Manager, factory *ChannelFactory) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		cache:     cache,
+		encoder:   encoder,
+		tenantMgr: tenantMgr,
+		txMgr:     txMgr,
+		factory:   factory,
+		client:    newWebhookClient(),
+		workers:   make(map[string]*webhookWorker),
+	}
+
+	go d.run()
+	return d
+}
+
+func (d *WebhookDispatcher) run() {
+	ticker := time.NewTicker(webhookSyncInterval)
+	defer ticker.Stop()
+	for {
+		d.sync(context.TODO())
+		<-ticker.C
+	}
+}
+
+// sync starts delivering the channels matching the webhooks of the projects and stops the workers of the webhooks
+// that are deleted or whose channels are gone.
+func (d *WebhookDispatcher) sync(ctx context.Context) {
+	active := make(map[string]struct{})
+	for _, tenant := range d.tenantMgr.AllTenants(ctx) {
+		for _, projName := range tenant.ListProjects(ctx) {
+			project, err := tenant.GetProject(projName)
+			if err != nil {
+				continue
+			}
+
+			if err = d.syncProject(ctx, tenant, project, active); err != nil {
+				log.Err(err).Str("project", projName).Msg("syncing channel webhooks failed")
+				d.keepProject(tenant, project, active)
+			}
+		}
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	for key, w := range d.workers {
+		if _, ok := active[key]; !ok {
+			w.cancel()
+			w.channel.DisconnectWatcher(w.watcher)
+			delete(d.workers, key)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) syncProject(ctx context.Context, tenant *metadata.Tenant, project *metadata.Project, active map[string]struct{}) error {
+	tx, err := d.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+	webhooks, err := tenant.ListChannelWebhooks(ctx, tx, project)
+	_ = tx.Rollback(ctx)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	tenantId, projId := tenant.GetNamespace().Id(), project.Id()
+	channels, err := d.factory.ListChannels(ctx, tenantId, projId, "*")
+	if err != nil {
+		return err
+	}
+
+	for i := range webhooks {
+		pattern, err := regexp.Compile("^" + globToRegex(webhooks[i].Pattern) + "$")
+		if err != nil {
+			continue
+		}
+
+		for _, channel := range channels {
+			if !pattern.MatchString(channel) {
+				continue
+			}
+
+			key := webhookWorkerKey(tenantId, projId, webhooks[i].Name, channel)
+			active[key] = struct{}{}
+			if err = d.startWorker(ctx, key, tenantId, project, &webhooks[i], channel); err != nil {
+				log.Err(err).Str("webhook", webhooks[i].Name).Str("channel", channel).Msg("starting webhook failed")
+			}
+		}
+	}
+
+	return nil
+}
+
+// keepProject keeps the workers of the project running when its webhooks couldn't be loaded.
+func (d *WebhookDispatcher) keepProject(tenant *metadata.Tenant, project *metadata.Project, active map[string]struct{}) {
+	d.Lock()
+	defer d.Unlock()
+
+	prefix := fmt.Sprintf("%d:%d:", tenant.GetNamespace().Id(), project.Id())
+	for key := range d.workers {
+		if strings.HasPrefix(key, prefix) {
+			active[key] = struct{}{}
+		}
+	}
+}
+
+func (d *WebhookDispatcher) startWorker(ctx context.Context, key string, tenantId uint32, project *metadata.Project, webhook *metadata.ChannelWebhook, channel string) error {
+	ch, err := d.factory.GetChannel(ctx, tenantId, project.Id(), channel)
+	if err != nil {
+		return err
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	existing, ok := d.workers[key]
+	if ok && existing.channel == ch && reflect.DeepEqual(existing.webhook, *webhook) {
+		return nil
+	}
+	if ok {
+		// the webhook has changed, its consumer group is kept to continue from where it was
+		existing.cancel()
+		existing.channel.StopWatcher(existing.watcher)
+	}
+
+	// the watcher outlives the sync
+	watcher, err := ch.GetWatcher(context.TODO(), webhookWatcherPrefix+webhook.Name, "")
+	if err != nil {
+		return err
+	}
+
+	workerCtx, cancel := context.WithCancel(context.Background())
+	worker := &webhookWorker{
+		webhook:  *webhook,
+		tenantId: tenantId,
+		projId:   project.Id(),
+		channel:  ch,
+		watcher:  watcher.name,
+		ctx:      workerCtx,
+		cancel:   cancel,
+		retries:  make(chan *webhookRetry, webhookMaxRetries),
+	}
+	go d.retry(worker)
+	watcher.StartWatching(d.watch(project.Name(), channel, worker))
+	d.workers[key] = worker
+
+	return nil
+}
+
+func (d *WebhookDispatcher) watch(project string, channel string, w *webhookWorker) Watch {
+	return func(resp *cache.StreamMessages, err error) ([]string, error) {
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]string, 0, len(resp.Messages))
+		for _, m := range resp.Messages {
+			ids = append(ids, m.ID)
+
+			payload, err := newWebhookPayload(resp, m, project, channel, w.webhook.Events)
+			if err != nil {
+				log.Err(err).Str("webhook", w.webhook.Name).Str("id", m.ID).Msg("decoding webhook event failed")
+				continue
+			}
+			if payload == nil {
+				continue
+			}
+
+			if atomic.LoadInt32(&w.pending) > 0 {
+				d.queueRetry(w, &webhookRetry{payload: payload})
+				continue
+			}
+
+			retry, err := d.postPayload(w.ctx, &w.webhook, payload)
+			switch {
+			case err == nil:
+			case retry:
+				d.queueRetry(w, &webhookRetry{payload: payload, attempts: 1})
+			default:
+				d.deadLetter(context.TODO(), w.tenantId, w.projId, &w.webhook, &WebhookDeadLetter{
+					Attempts: 1,
+					Error:    err.Error(),
+					Event:    payload,
+				})
+			}
+		}
+
+		return ids, nil
+	}
+}
+
+// queueRetry queues the event to be retried by the worker, or adds it to the dead letters if too many events are
+// already waiting.
+func (d *WebhookDispatcher) queueRetry(w *webhookWorker, r *webhookRetry) {
+	atomic.AddInt32(&w.pending, 1)
+	select {
+	case w.retries <- r:
+	default:
+		atomic.AddInt32(&w.pending, -1)
+		d.deadLetter(context.TODO(), w.tenantId, w.projId, &w.webhook, &WebhookDeadLetter{
+			Attempts: r.attempts,
+			Error:    "too many failed deliveries waiting to be retried",
+			Event:    r.payload,
+		})
+	}
+}
+
+// retry delivers the failed events of the worker until it stops, the events still waiting then are added to the dead
+// letters.
+func (d *WebhookDispatcher) retry(w *webhookWorker) {
+	for {
+		select {
+		case r := <-w.retries:
+			if attempts, err := d.deliver(w.ctx, &w.webhook, r.payload, r.attempts); err != nil {
+				d.deadLetter(context.TODO(), w.tenantId, w.projId, &w.webhook, &WebhookDeadLetter{
+					Attempts: attempts,
+					Error:    err.Error(),
+					Event:    r.payload,
+				})
+			}
+			atomic.AddInt32(&w.pending, -1)
+		case <-w.ctx.Done():
+			for {
+				select {
+				case r := <-w.retries:
+					d.deadLetter(context.TODO(), w.tenantId, w.projId, &w.webhook, &WebhookDeadLetter{
+						Attempts: r.attempts,
+						Error:    w.ctx.Err().Error(),
+						Event:    r.payload,
+					})
+					atomic.AddInt32(&w.pending, -1)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver posts the event to the webhook after the given number of failed attempts, retrying with an exponential
+// backoff until the context is cancelled. It returns the number of attempts.
+func (d *WebhookDispatcher) deliver(ctx context.Context, webhook *metadata.ChannelWebhook, payload *WebhookPayload, attempted int) (int, error) {
+	body, err := jsoniter.Marshal(payload)
+	if err != nil {
+		return attempted, err
+	}
+
+	backoff := webhookInitialBackoff
+	for i := 1; i < attempted; i++ {
+		if backoff *= 2; backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+	}
+
+	for attempt := attempted + 1; ; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return attempt - 1, ctx.Err()
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > webhookMaxBackoff {
+				backoff = webhookMaxBackoff
+			}
+		}
+
+		retry, err := d.post(ctx, webhook, body)
+		if err == nil {
+			return attempt, nil
+		}
+		if !retry || attempt >= webhookMaxAttempts {
+			return attempt, err
+		}
+	}
+}
+
+func (d *WebhookDispatcher) postPayload(ctx context.Context, webhook *metadata.ChannelWebhook, payload *WebhookPayload) (bool, error) {
+	body, err := jsoniter.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+
+	return d.post(ctx, webhook, body)
+}
+
+// newWebhookClient returns the client posting to the webhooks. The address of every connection is checked once it is
+// resolved, so a host resolving to an internal address at the time of the request is rejected even if it resolved to
+// a public one when the webhook was created. The proxies of the environment are not used, as the connection would be
+// to the proxy, and the redirects are not followed.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(_ string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("unexpected address '%s'", address)
+			}
+
+			return metadata.CheckWebhookAddress(ip)
+		},
+	}
+	unchecked := &net.Dialer{Timeout: webhookTimeout}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(addr); err == nil && metadata.IsWebhookHostAllowed(host) {
+			return unchecked.DialContext(ctx, network, addr)
+		}
+
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// post sends the request to the webhook and returns whether a failed request can be retried.
+func (d *WebhookDispatcher) post(ctx context.Context, webhook *metadata.ChannelWebhook, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(webhook.Secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return retry, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
+
+func (d *WebhookDispatcher) deadLetter(ctx context.Context, tenantId uint32, projId uint32, webhook *metadata.ChannelWebhook, letter *WebhookDeadLetter) {
+	log.Warn().Str("webhook", webhook.Name).Str("channel", letter.Event.Channel).Str("error", letter.Error).
+		Msg("webhook delivery failed, adding the event to the dead letters")
+
+	if err := addDeadLetter(ctx, d.cache, d.encoder, tenantId, projId, webhook.Name, letter); err != nil {
+		log.Err(err).Str("webhook", webhook.Name).Msg("adding webhook dead letter failed")
+	}
+}
+
+// SignWebhook returns the signature of a webhook request, the HMAC-SHA256 of the timestamp and the body joined by a
+// dot, keyed by the secret of the webhook.
+func SignWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookPayload returns the payload of the message, or nil if the webhook doesn't deliver its kind of events.
+func newWebhookPayload(resp *cache.StreamMessages, m xredis.XMessage, project string, channel string, events []string) (*WebhookPayload, error) {
+	data, err := resp.Decode(m)
+	if err != nil {
+		return nil, err
+	}
+
+	md, err := DecodeStreamMD(data.Md)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(events, md.DataType) {
+		return nil, nil
+	}
+
+	payload := &WebhookPayload{
+		Project:  project,
+		Channel:  channel,
+		Id:       m.ID,
+		Type:     md.DataType,
+		Name:     md.EventName,
+		ClientId: md.ClientId,
+	}
+	if len(data.RawData) > 0 {
+		if payload.Data, err = SanitizeUserData(internal.JsonEncoding, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return payload, nil
+}
+
+func addDeadLetter(ctx context.Context, c cache.Cache, encoder metadata.CacheEncoder, tenantId uint32, projId uint32, webhook string, letter *WebhookDeadLetter) error {
+	enc, err := jsoniter.Marshal(letter)
+	if err != nil {
+		return err
+	}
+
+	name, err := deadLettersStreamName(encoder, tenantId, projId, webhook)
+	if err != nil {
+		return err
+	}
+
+	stream, err := c.CreateOrGetStream(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, err = stream.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, enc)); err != nil {
+		return err
+	}
+
+	_, err = stream.Trim(ctx, webhookDeadLettersMaxLen, "")
+	return err
+}
+
+// readDeadLetters returns the last dead letters of the webhook, the newest first.
+func readDeadLetters(ctx context.Context, c cache.Cache, encoder metadata.CacheEncoder, tenantId uint32, projId uint32, webhook string, count int64) ([]xredis.XMessage, error) {
+	name, err := deadLettersStreamName(encoder, tenantId, projId, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.GetStream(ctx, name)
+	if err == cache.ErrStreamNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return stream.ReadLast(ctx, count)
+}
+
+// deleteDeadLetters removes the dead letters of the webhook.
+func deleteDeadLetters(ctx context.Context, c cache.Cache, encoder metadata.CacheEncoder, tenantId uint32, projId uint32, webhook string) error {
+	name, err := deadLettersStreamName(encoder, tenantId, projId, webhook)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteStream(ctx, name)
+}
+
+func deadLettersStreamName(encoder metadata.CacheEncoder, tenantId uint32, projId uint32, webhook string) (string, error) {
+	return encoder.EncodeCacheTableName(tenantId, projId, webhookDeadLettersTable+":"+webhook)
+}
+
+func webhookWorkerKey(tenantId uint32, projId uint32, webhook string, channel string) string {
+	return fmt.Sprintf("%d:%d:%s:%s", tenantId, projId, webhook, channel)
+}
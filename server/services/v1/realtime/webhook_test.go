@@ -0,0 +1,137 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/server/config"
+	"github.com/tigrisdata/tigris/server/metadata"
+)
+
+func TestWebhook(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		valid := metadata.ChannelWebhook{Name: "orders", Pattern: "orders-*", URL: "https://example.com/hook", Events: []string{metadata.WebhookEventMessage}}
+		require.NoError(t, valid.Validate())
+
+		invalidURL := valid
+		invalidURL.URL = "ftp://example.com"
+		require.Error(t, invalidURL.Validate())
+
+		invalidEvent := valid
+		invalidEvent.Events = []string{"unknown"}
+		require.Error(t, invalidEvent.Validate())
+
+		for _, u := range []string{"http://example.com/hook", "https://127.0.0.1/hook", "https://localhost:8080/hook",
+			"https://169.254.169.254/latest/meta-data", "https://10.0.0.1/hook", "https://[::1]/hook", "https://0.0.0.0/hook"} {
+			internal := valid
+			internal.URL = u
+			require.Error(t, internal.Validate(), u)
+		}
+
+		config.DefaultConfig.Realtime.WebhookAllowHTTP = true
+		config.DefaultConfig.Realtime.WebhookAllowedHosts = []string{"localhost"}
+		defer func() {
+			config.DefaultConfig.Realtime.WebhookAllowHTTP = false
+			config.DefaultConfig.Realtime.WebhookAllowedHosts = nil
+		}()
+		allowed := valid
+		allowed.URL = "http://localhost:8080/hook"
+		require.NoError(t, allowed.Validate())
+	})
+	t.Run("reject_internal_address", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		// the loopback address of the test server is only known when the connection is made
+		d := &WebhookDispatcher{client: newWebhookClient()}
+		_, err := d.post(context.TODO(), &metadata.ChannelWebhook{URL: srv.URL}, []byte(`{}`))
+		require.ErrorContains(t, err, "internal address")
+		require.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+		config.DefaultConfig.Realtime.WebhookAllowPrivateNetworks = true
+		defer func() { config.DefaultConfig.Realtime.WebhookAllowPrivateNetworks = false }()
+		_, err = d.post(context.TODO(), &metadata.ChannelWebhook{URL: srv.URL}, []byte(`{}`))
+		require.NoError(t, err)
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+	t.Run("sign", func(t *testing.T) {
+		sig := SignWebhook("secret", "1675209600", []byte(`{"id":"1-0"}`))
+		require.Equal(t, sig, SignWebhook("secret", "1675209600", []byte(`{"id":"1-0"}`)))
+		require.NotEqual(t, sig, SignWebhook("other", "1675209600", []byte(`{"id":"1-0"}`)))
+		require.Len(t, sig, len("sha256=")+64)
+	})
+	t.Run("deliver", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			require.Equal(t, SignWebhook("secret", r.Header.Get(WebhookTimestampHeader), body), r.Header.Get(WebhookSignatureHeader))
+
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		d := &WebhookDispatcher{client: srv.Client()}
+		webhook := &metadata.ChannelWebhook{Name: "orders", URL: srv.URL, Secret: "secret"}
+		attempts, err := d.deliver(context.TODO(), webhook, &WebhookPayload{Channel: "orders-1", Id: "1-0"}, 0)
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+	})
+	t.Run("no_retry", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		d := &WebhookDispatcher{client: srv.Client()}
+		attempts, err := d.deliver(context.TODO(), &metadata.ChannelWebhook{URL: srv.URL}, &WebhookPayload{Id: "1-0"}, 0)
+		require.Error(t, err)
+		require.Equal(t, 1, attempts)
+		require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+	t.Run("cancel_retry", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		d := &WebhookDispatcher{client: srv.Client()}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		attempts, err := d.deliver(ctx, &metadata.ChannelWebhook{URL: srv.URL}, &WebhookPayload{Id: "1-0"}, 1)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, 1, attempts)
+		require.Less(t, time.Since(start), webhookInitialBackoff)
+	})
+}
@@ -51,7 +51,7 @@ func newSearchService(store searchStore.Store, tenantMgr *metadata.TenantManager
 		tenantMgr:     tenantMgr,
 		versionH:      tenantMgr.GetVersionHandler(),
 		sessions:      search.NewSessionManager(txMgr, tenantMgr, metadata.NewCacheTracker(tenantMgr, txMgr)),
-		runnerFactory: search.NewRunnerFactory(store, tenantMgr.GetEncoder(), txMgr),
+		runnerFactory: search.NewRunnerFactory(store, tenantMgr.GetEncoder(), txMgr, tenantMgr.GetSearchAnalytics()),
 	}
 }
 
@@ -252,3 +252,131 @@ func (s *searchService) Search(req *api.SearchIndexRequest, stream api.Search_Se
 
 	return nil
 }
+
+func (s *searchService) CreateOrUpdateSynonym(ctx context.Context, req *api.CreateOrUpdateSynonymRequest) (*api.CreateOrUpdateSynonymResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetRulesRunner(accessToken)
+	runner.SetCreateOrUpdateSynonymReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateOrUpdateSynonymResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) ListSynonyms(ctx context.Context, req *api.ListSynonymsRequest) (*api.ListSynonymsResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetRulesRunner(accessToken)
+	runner.SetListSynonymsReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.ListSynonymsResponse), nil
+}
+
+func (s *searchService) DeleteSynonym(ctx context.Context, req *api.DeleteSynonymRequest) (*api.DeleteSynonymResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetRulesRunner(accessToken)
+	runner.SetDeleteSynonymReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteSynonymResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) CreateOrUpdateCuration(ctx context.Context, req *api.CreateOrUpdateCurationRequest) (*api.CreateOrUpdateCurationResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetRulesRunner(accessToken)
+	runner.SetCreateOrUpdateCurationReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateOrUpdateCurationResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) ListCurations(ctx context.Context, req *api.ListCurationsRequest) (*api.ListCurationsResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetRulesRunner(accessToken)
+	runner.SetListCurationsReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.ListCurationsResponse), nil
+}
+
+func (s *searchService) DeleteCuration(ctx context.Context, req *api.DeleteCurationRequest) (*api.DeleteCurationResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetRulesRunner(accessToken)
+	runner.SetDeleteCurationReq(req)
+
+	resp, err := s.sessions.TxExecute(ctx, runner, search.SessionOptions{
+		IncVersion: false,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DeleteCurationResponse{
+		Status: resp.Status,
+	}, nil
+}
+
+func (s *searchService) GetQueryAnalytics(ctx context.Context, req *api.GetQueryAnalyticsRequest) (*api.GetQueryAnalyticsResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetAnalyticsRunner(req, accessToken)
+	resp, err := s.sessions.Execute(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.GetQueryAnalyticsResponse), nil
+}
+
+func (s *searchService) Suggest(ctx context.Context, req *api.SuggestRequest) (*api.SuggestResponse, error) {
+	accessToken, _ := request.GetAccessToken(ctx)
+
+	runner := s.runnerFactory.GetSuggestRunner(req, accessToken)
+	resp, err := s.sessions.Execute(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response.(*api.SuggestResponse), nil
+}
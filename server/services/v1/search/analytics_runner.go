@@ -0,0 +1,158 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	zeroResultQueries  = "zero_results"
+	slowestQueries     = "slowest"
+	defaultQueryLimit  = 10
+	defaultQueryWindow = 24 * time.Hour
+)
+
+// AnalyticsRunner returns the top, the zero-result or the slowest queries run on a search index, or on a collection,
+// during a time window.
+type AnalyticsRunner struct {
+	*baseRunner
+
+	req       *api.GetQueryAnalyticsRequest
+	analytics *metadata.SearchAnalytics
+}
+
+func (runner *AnalyticsRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	src, err := runner.getSource(tenant)
+	if err != nil {
+		return Response{}, err
+	}
+
+	to := time.Now()
+	if runner.req.GetEndTime() != nil {
+		to = runner.req.GetEndTime().AsTime()
+	}
+	from := to.Add(-defaultQueryWindow)
+	if runner.req.GetStartTime() != nil {
+		from = runner.req.GetStartTime().AsTime()
+	}
+
+	stats, err := runner.analytics.Read(ctx, src, from, to)
+	if err != nil {
+		return Response{}, err
+	}
+
+	limit := int(runner.req.GetLimit())
+	if limit == 0 {
+		limit = defaultQueryLimit
+	}
+
+	resp := &api.GetQueryAnalyticsResponse{}
+	for _, s := range rankQueries(stats, runner.req.GetType(), limit) {
+		resp.Queries = append(resp.Queries, &api.QueryAnalytics{
+			Query:        s.Query,
+			Count:        s.Count,
+			ZeroResults:  s.ZeroResults,
+			AvgLatencyMs: s.AvgLatency().Milliseconds(),
+			MaxLatencyMs: s.MaxLatency.Milliseconds(),
+			MaxPage:      s.MaxPage,
+			Filters:      s.Filters,
+			LastSeen:     timestamppb.New(s.LastSeen),
+		})
+	}
+
+	return Response{Response: resp}, nil
+}
+
+func (runner *AnalyticsRunner) getSource(tenant *metadata.Tenant) (metadata.SearchAnalyticsSource, error) {
+	src := metadata.SearchAnalyticsSource{
+		NamespaceId: tenant.GetNamespace().Id(),
+		Project:     runner.req.GetProject(),
+	}
+
+	project, err := tenant.GetProject(runner.req.GetProject())
+	if err != nil {
+		return src, err
+	}
+	src.ProjectId = project.Id()
+
+	if len(runner.req.GetCollection()) == 0 {
+		if _, err := runner.getIndex(tenant, runner.req.GetProject(), runner.req.GetIndex()); err != nil {
+			return src, err
+		}
+
+		src.Index = runner.req.GetIndex()
+		return src, nil
+	}
+
+	db, err := project.GetDatabase(metadata.NewDatabaseNameWithBranch(runner.req.GetProject(), runner.req.GetBranch()))
+	if err != nil {
+		return src, err
+	}
+
+	if db.GetCollection(runner.req.GetCollection()) == nil {
+		return src, errors.NotFound("collection doesn't exist '%s'", runner.req.GetCollection())
+	}
+
+	src.Branch = db.BranchName()
+	src.Collection = runner.req.GetCollection()
+
+	return src, nil
+}
+
+// rankQueries orders the statistics by the number of runs, the number of runs without results or the average latency
+// and returns the first ones.
+func rankQueries(stats []*metadata.SearchQueryStats, rankBy string, limit int) []*metadata.SearchQueryStats {
+	var less func(a, b *metadata.SearchQueryStats) bool
+	switch rankBy {
+	case zeroResultQueries:
+		filtered := stats[:0]
+		for _, s := range stats {
+			if s.ZeroResults > 0 {
+				filtered = append(filtered, s)
+			}
+		}
+		stats = filtered
+		less = func(a, b *metadata.SearchQueryStats) bool { return a.ZeroResults > b.ZeroResults }
+	case slowestQueries:
+		less = func(a, b *metadata.SearchQueryStats) bool { return a.AvgLatency() > b.AvgLatency() }
+	default:
+		// top queries
+		less = func(a, b *metadata.SearchQueryStats) bool { return a.Count > b.Count }
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if less(stats[i], stats[j]) {
+			return true
+		}
+		if less(stats[j], stats[i]) {
+			return false
+		}
+		return stats[i].Query < stats[j].Query
+	})
+
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+
+	return stats
+}
@@ -0,0 +1,46 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/server/metadata"
+)
+
+func TestRankQueries(t *testing.T) {
+	stats := func() []*metadata.SearchQueryStats {
+		return []*metadata.SearchQueryStats{
+			{Query: "a", Count: 5, ZeroResults: 0, TotalLatency: 5 * time.Millisecond},
+			{Query: "b", Count: 2, ZeroResults: 2, TotalLatency: 40 * time.Millisecond},
+			{Query: "c", Count: 5, ZeroResults: 1, TotalLatency: 50 * time.Millisecond},
+			{Query: "d", Count: 1, ZeroResults: 1, TotalLatency: 2 * time.Millisecond},
+		}
+	}
+
+	queries := func(ranked []*metadata.SearchQueryStats) []string {
+		var names []string
+		for _, s := range ranked {
+			names = append(names, s.Query)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{"a", "c", "b"}, queries(rankQueries(stats(), "top", 3)))
+	require.Equal(t, []string{"b", "c", "d"}, queries(rankQueries(stats(), zeroResultQueries, 10)))
+	require.Equal(t, []string{"b", "c"}, queries(rankQueries(stats(), slowestQueries, 2)))
+}
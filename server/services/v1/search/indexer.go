@@ -144,6 +144,20 @@ func (transformer *transformer) transformEnd(doc map[string]any) (map[string]any
 					// pack original date as string to a shadowed key
 					doc[schema.ToSearchDateKey(key)] = dateStr
 				}
+			case schema.GeoPointType:
+				var err error
+				if doc[key], err = schema.GeoPointToSearch(value); err != nil {
+					return nil, err
+				}
+			case schema.DecimalType:
+				if decimalStr, ok := value.(string); ok {
+					var err error
+					if doc[key], err = schema.DecimalToSearch(decimalStr); err != nil {
+						return nil, err
+					}
+					// pack original decimal as string to a shadowed key
+					doc[schema.ToSearchDecimalKey(key)] = decimalStr
+				}
 			default:
 				var err error
 				if doc[key], err = jsoniter.MarshalToString(value); err != nil {
@@ -205,6 +219,13 @@ func (transformer *transformer) inverseStart(doc map[string]any) (map[string]any
 					shadowedKey := schema.ToSearchDateKey(f.Name())
 					doc[f.Name()] = doc[shadowedKey]
 					delete(doc, shadowedKey)
+				case schema.GeoPointType:
+					doc[f.Name()] = schema.GeoPointFromSearch(v)
+				case schema.DecimalType:
+					// unpack original decimal from shadowed key
+					shadowedKey := schema.ToSearchDecimalKey(f.Name())
+					doc[f.Name()] = doc[shadowedKey]
+					delete(doc, shadowedKey)
 				default:
 					if _, ok := v.(string); ok {
 						var value any
@@ -41,10 +41,11 @@ type ResultRow struct {
 
 // Row represents a single hit. This is used by the runner to finally push the row out.
 type Row struct {
-	CreatedAt *internal.Timestamp
-	UpdatedAt *internal.Timestamp
-	Document  []byte
-	Match     *api.Match
+	CreatedAt  *internal.Timestamp
+	UpdatedAt  *internal.Timestamp
+	Document   []byte
+	Match      *api.Match
+	Highlights []*api.Highlight
 }
 
 type page struct {
@@ -143,7 +144,7 @@ func (p *pageReader) read() error {
 	if len(p.cachedFacets) == 0 {
 		if len(result) > 0 {
 			builder := tsearch.NewFacetResponse(p.query.Facets)
-			for field, built := range builder.Build(result[0].FacetCounts) {
+			for field, built := range builder.Build(result[0].Facets) {
 				p.cachedFacets[field] = built
 			}
 		}
@@ -152,9 +153,7 @@ func (p *pageReader) read() error {
 	if p.found == -1 {
 		p.found = 0
 		for _, r := range result {
-			if r.Found != nil {
-				p.found += int64(*r.Found)
-			}
+			p.found += r.Found
 		}
 	}
 	return nil
@@ -243,10 +242,11 @@ func (it *FilterableSearchIterator) Next(row *ResultRow) bool {
 				}
 
 				rows = append(rows, &Row{
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					Document:  rawData,
-					Match:     hits[i].Match,
+					CreatedAt:  createdAt,
+					UpdatedAt:  updatedAt,
+					Document:   rawData,
+					Match:      hits[i].Match,
+					Highlights: hits[i].Highlights,
 				})
 			}
 
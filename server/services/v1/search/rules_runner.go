@@ -0,0 +1,172 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/server/metadata"
+	"github.com/tigrisdata/tigris/server/services/v1/database"
+	"github.com/tigrisdata/tigris/server/transaction"
+	"github.com/tigrisdata/tigris/store/search"
+)
+
+// RulesRunner manages the synonyms and the curation rules of a search index.
+type RulesRunner struct {
+	*baseRunner
+
+	upsertSynonym  *api.CreateOrUpdateSynonymRequest
+	listSynonyms   *api.ListSynonymsRequest
+	deleteSynonym  *api.DeleteSynonymRequest
+	upsertCuration *api.CreateOrUpdateCurationRequest
+	listCurations  *api.ListCurationsRequest
+	deleteCuration *api.DeleteCurationRequest
+}
+
+func (runner *RulesRunner) SetCreateOrUpdateSynonymReq(req *api.CreateOrUpdateSynonymRequest) {
+	runner.upsertSynonym = req
+}
+
+func (runner *RulesRunner) SetListSynonymsReq(req *api.ListSynonymsRequest) {
+	runner.listSynonyms = req
+}
+
+func (runner *RulesRunner) SetDeleteSynonymReq(req *api.DeleteSynonymRequest) {
+	runner.deleteSynonym = req
+}
+
+func (runner *RulesRunner) SetCreateOrUpdateCurationReq(req *api.CreateOrUpdateCurationRequest) {
+	runner.upsertCuration = req
+}
+
+func (runner *RulesRunner) SetListCurationsReq(req *api.ListCurationsRequest) {
+	runner.listCurations = req
+}
+
+func (runner *RulesRunner) SetDeleteCurationReq(req *api.DeleteCurationRequest) {
+	runner.deleteCuration = req
+}
+
+func (runner *RulesRunner) Run(ctx context.Context, tx transaction.Tx, tenant *metadata.Tenant) (Response, error) {
+	switch {
+	case runner.upsertSynonym != nil:
+		return runner.runOnProject(tenant, runner.upsertSynonym.GetProject(), func(project *metadata.Project) (Response, error) {
+			err := tenant.CreateOrUpdateSearchSynonym(ctx, tx, project, runner.upsertSynonym.GetIndex(), toSynonym(runner.upsertSynonym.GetSynonym()))
+			return Response{Status: database.CreatedStatus}, err
+		})
+	case runner.listSynonyms != nil:
+		return runner.runOnProject(tenant, runner.listSynonyms.GetProject(), func(project *metadata.Project) (Response, error) {
+			synonyms, err := tenant.ListSearchSynonyms(ctx, tx, project, runner.listSynonyms.GetIndex())
+			if err != nil {
+				return Response{}, err
+			}
+
+			resp := &api.ListSynonymsResponse{}
+			for i := range synonyms {
+				resp.Synonyms = append(resp.Synonyms, fromSynonym(&synonyms[i]))
+			}
+			return Response{Response: resp}, nil
+		})
+	case runner.deleteSynonym != nil:
+		return runner.runOnProject(tenant, runner.deleteSynonym.GetProject(), func(project *metadata.Project) (Response, error) {
+			err := tenant.DeleteSearchSynonym(ctx, tx, project, runner.deleteSynonym.GetIndex(), runner.deleteSynonym.GetId())
+			return Response{Status: database.DeletedStatus}, err
+		})
+	case runner.upsertCuration != nil:
+		return runner.runOnProject(tenant, runner.upsertCuration.GetProject(), func(project *metadata.Project) (Response, error) {
+			err := tenant.CreateOrUpdateSearchCuration(ctx, tx, project, runner.upsertCuration.GetIndex(), toCuration(runner.upsertCuration.GetCuration()))
+			return Response{Status: database.CreatedStatus}, err
+		})
+	case runner.listCurations != nil:
+		return runner.runOnProject(tenant, runner.listCurations.GetProject(), func(project *metadata.Project) (Response, error) {
+			curations, err := tenant.ListSearchCurations(ctx, tx, project, runner.listCurations.GetIndex())
+			if err != nil {
+				return Response{}, err
+			}
+
+			resp := &api.ListCurationsResponse{}
+			for i := range curations {
+				resp.Curations = append(resp.Curations, fromCuration(&curations[i]))
+			}
+			return Response{Response: resp}, nil
+		})
+	case runner.deleteCuration != nil:
+		return runner.runOnProject(tenant, runner.deleteCuration.GetProject(), func(project *metadata.Project) (Response, error) {
+			err := tenant.DeleteSearchCuration(ctx, tx, project, runner.deleteCuration.GetIndex(), runner.deleteCuration.GetId())
+			return Response{Status: database.DeletedStatus}, err
+		})
+	}
+
+	return Response{}, nil
+}
+
+func (*RulesRunner) runOnProject(tenant *metadata.Tenant, projName string, fn func(*metadata.Project) (Response, error)) (Response, error) {
+	project, err := tenant.GetProject(projName)
+	if err != nil {
+		return Response{}, createApiError(err)
+	}
+
+	resp, err := fn(project)
+	if err != nil {
+		return Response{}, createApiError(err)
+	}
+
+	return resp, nil
+}
+
+func toSynonym(s *api.SynonymSet) *search.Synonym {
+	return &search.Synonym{
+		Id:       s.GetId(),
+		Root:     s.GetRoot(),
+		Synonyms: s.GetSynonyms(),
+	}
+}
+
+func fromSynonym(s *search.Synonym) *api.SynonymSet {
+	return &api.SynonymSet{
+		Id:       s.Id,
+		Root:     s.Root,
+		Synonyms: s.Synonyms,
+	}
+}
+
+func toCuration(c *api.CurationRule) *search.Curation {
+	curation := &search.Curation{
+		Id:     c.GetId(),
+		Query:  c.GetQuery(),
+		Match:  c.GetMatch(),
+		Hidden: c.GetHidden(),
+	}
+	for _, p := range c.GetPinned() {
+		curation.Pinned = append(curation.Pinned, search.PinnedDocument{Id: p.GetId(), Position: int(p.GetPosition())})
+	}
+
+	return curation
+}
+
+func fromCuration(c *search.Curation) *api.CurationRule {
+	rule := &api.CurationRule{
+		Id:     c.Id,
+		Query:  c.Query,
+		Match:  c.Match,
+		Hidden: c.Hidden,
+	}
+	for _, p := range c.Pinned {
+		rule.Pinned = append(rule.Pinned, &api.PinnedDocument{Id: p.Id, Position: int32(p.Position)})
+	}
+
+	return rule
+}
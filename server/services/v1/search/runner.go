@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
@@ -56,17 +57,19 @@ type TxRunner interface {
 }
 
 type RunnerFactory struct {
-	store   search.Store
-	encoder metadata.Encoder
-	txMgr   *transaction.Manager
+	store     search.Store
+	encoder   metadata.Encoder
+	txMgr     *transaction.Manager
+	analytics *metadata.SearchAnalytics
 }
 
 // NewRunnerFactory returns RunnerFactory object.
-func NewRunnerFactory(store search.Store, encoder metadata.Encoder, txMgr *transaction.Manager) *RunnerFactory {
+func NewRunnerFactory(store search.Store, encoder metadata.Encoder, txMgr *transaction.Manager, analytics *metadata.SearchAnalytics) *RunnerFactory {
 	return &RunnerFactory{
-		store:   store,
-		encoder: encoder,
-		txMgr:   txMgr,
+		store:     store,
+		encoder:   encoder,
+		txMgr:     txMgr,
+		analytics: analytics,
 	}
 }
 
@@ -76,6 +79,12 @@ func (f *RunnerFactory) GetIndexRunner(accessToken *types.AccessToken) *IndexRun
 	}
 }
 
+func (f *RunnerFactory) GetRulesRunner(accessToken *types.AccessToken) *RulesRunner {
+	return &RulesRunner{
+		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
+	}
+}
+
 func (f *RunnerFactory) GetReadRunner(r *api.GetDocumentRequest, accessToken *types.AccessToken) *ReadRunner {
 	return &ReadRunner{
 		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
@@ -88,6 +97,23 @@ func (f *RunnerFactory) GetSearchRunner(r *api.SearchIndexRequest, streaming Str
 		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
 		req:        r,
 		streaming:  streaming,
+		analytics:  f.analytics,
+	}
+}
+
+func (f *RunnerFactory) GetAnalyticsRunner(r *api.GetQueryAnalyticsRequest, accessToken *types.AccessToken) *AnalyticsRunner {
+	return &AnalyticsRunner{
+		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
+		req:        r,
+		analytics:  f.analytics,
+	}
+}
+
+func (f *RunnerFactory) GetSuggestRunner(r *api.SuggestRequest, accessToken *types.AccessToken) *SuggestRunner {
+	return &SuggestRunner{
+		baseRunner: newBaseRunner(f.store, f.encoder, f.txMgr, accessToken),
+		req:        r,
+		analytics:  f.analytics,
 	}
 }
 
@@ -137,10 +163,10 @@ func (runner *ReadRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Res
 		return Response{}, err
 	}
 
-	idToHits := make(map[string]*map[string]any)
-	for _, hit := range *result.Hits {
+	idToHits := make(map[string]map[string]any)
+	for _, hit := range result.Hits {
 		// at this point we can safely rely on accessing "schema.SearchId" because we always inject it as top level key.
-		idToHits[(*hit.Document)[schema.SearchId].(string)] = hit.Document
+		idToHits[hit.Document[schema.SearchId].(string)] = hit.Document
 	}
 
 	transformer := newReadTransformer(index)
@@ -153,7 +179,7 @@ func (runner *ReadRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Res
 			continue
 		}
 
-		doc, created, updated, err := transformer.fromSearch(*outDoc)
+		doc, created, updated, err := transformer.fromSearch(outDoc)
 		if err != nil {
 			return Response{}, err
 		}
@@ -577,9 +603,11 @@ type SearchRunner struct {
 
 	req       *api.SearchIndexRequest
 	streaming Streaming
+	analytics *metadata.SearchAnalytics
 }
 
 func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	start := time.Now()
 	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
 	if reqStatus != nil && reqStatusExists {
 		reqStatus.SetApiSearchType()
@@ -625,6 +653,11 @@ func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 		return Response{}, err
 	}
 
+	highlight, err := runner.getHighlight(index)
+	if err != nil {
+		return Response{}, err
+	}
+
 	pageSize := int(runner.req.PageSize)
 	if pageSize == 0 {
 		pageSize = defaultPerPage
@@ -641,6 +674,7 @@ func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 		SortOrder(sortOrder).
 		GroupBy(groupBy).
 		VectorSearch(vecSearch).
+		Highlight(highlight).
 		Build()
 	if searchQ.IsQAndVectorBoth() {
 		return Response{}, errors.InvalidArgument("Currently either full text or vector search is supported")
@@ -686,6 +720,7 @@ func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 					metadata.UpdatedAt = row.UpdatedAt.GetProtoTS()
 				}
 				metadata.Match = row.Match
+				metadata.Highlights = row.Highlights
 				if metadata.Match != nil {
 					for _, f := range metadata.Match.Fields {
 						if f != nil {
@@ -749,9 +784,41 @@ func (runner *SearchRunner) Run(ctx context.Context, tenant *metadata.Tenant) (R
 		pageNo++
 	}
 
+	runner.recordSearch(tenant, iterator.getTotalFound(), time.Since(start))
+
 	return Response{}, nil
 }
 
+// recordSearch adds the search to the analytics of the index.
+func (runner *SearchRunner) recordSearch(tenant *metadata.Tenant, found int64, latency time.Duration) {
+	page := int32(defaultPageNo)
+	if runner.req.Page > 0 {
+		page = runner.req.Page
+	}
+
+	project, err := tenant.GetProject(runner.req.GetProject())
+	if err != nil {
+		// the project has been dropped since the search started
+		return
+	}
+
+	// the filter is already validated so the fields can't fail to be extracted
+	filters, _ := filter.Fields(runner.req.Filter)
+
+	runner.analytics.Record(metadata.SearchAnalyticsSource{
+		NamespaceId: tenant.GetNamespace().Id(),
+		ProjectId:   project.Id(),
+		Project:     runner.req.GetProject(),
+		Index:       runner.req.GetIndex(),
+	}, &metadata.SearchQuery{
+		Query:   runner.req.Q,
+		Filters: filters,
+		Found:   found,
+		Latency: latency,
+		Page:    page,
+	})
+}
+
 func (runner *SearchRunner) getSearchFields(index *schema.SearchIndex) ([]string, error) {
 	searchFields := runner.req.SearchFields
 	if len(searchFields) == 0 {
@@ -796,6 +863,19 @@ func (runner *SearchRunner) getFacetFields(index *schema.SearchIndex) (qsearch.F
 			return qsearch.Facets{}, errors.InvalidArgument(
 				"Cannot generate facets for `%s`. Faceting is only supported for numeric and text fields", ff.Name)
 		}
+		if err = facets.Fields[i].ValidateType(cf); err != nil {
+			return qsearch.Facets{}, err
+		}
+		if ff.ExcludeFilter && !filter.None(runner.req.Filter) {
+			// the name of the field is still the one used in the request filter
+			reqFilter, err := filter.WithoutField(runner.req.Filter, ff.Name)
+			if err != nil {
+				return qsearch.Facets{}, err
+			}
+			if facets.Fields[i].WrappedF, err = filter.NewFactory(index.QueryableFields, value.NewCollationFrom(runner.req.Collation)).WrappedFilter(reqFilter); err != nil {
+				return qsearch.Facets{}, err
+			}
+		}
 		if cf.InMemoryName() != cf.Name() {
 			facets.Fields[i].Name = cf.InMemoryName()
 		}
@@ -900,6 +980,9 @@ func (runner *SearchRunner) getVectorSearch(index *schema.SearchIndex) (qsearch.
 	if f.DataType != schema.VectorType {
 		return qsearch.VectorSearch{}, errors.InvalidArgument("Cannot perform vector search on non-vector type, field `%s` is not a vector", f.FieldName)
 	}
+	if len(vectorSearch.Text) > 0 {
+		return qsearch.VectorSearch{}, errors.InvalidArgument("Cannot perform vector search using text, field `%s` has no embedding", f.FieldName)
+	}
 	if f.Dimensions != nil && *f.Dimensions != len(vectorSearch.VectorV) {
 		return qsearch.VectorSearch{}, errors.InvalidArgument("query vector is not same size as dimensions, expected size: %d", *f.Dimensions)
 	}
@@ -907,6 +990,33 @@ func (runner *SearchRunner) getVectorSearch(index *schema.SearchIndex) (qsearch.
 	return vectorSearch, nil
 }
 
+func (runner *SearchRunner) getHighlight(index *schema.SearchIndex) (*qsearch.Highlight, error) {
+	highlight, err := qsearch.UnmarshalHighlight(runner.req.Highlight)
+	if err != nil || highlight == nil {
+		return nil, err
+	}
+
+	for _, fields := range [][]string{highlight.Fields, highlight.FullFields} {
+		for i, hf := range fields {
+			cf, err := index.GetQueryableField(hf)
+			if err != nil {
+				return nil, err
+			}
+			if !cf.SearchIndexed || (cf.DataType != schema.StringType && cf.SubType != schema.StringType) {
+				return nil, errors.InvalidArgument("Cannot highlight `%s`. Only searchable string fields can be highlighted", hf)
+			}
+			if cf.InMemoryName() != cf.Name() {
+				fields[i] = cf.InMemoryName()
+			}
+		}
+	}
+	if highlight.SnippetLength != nil && *highlight.SnippetLength < 0 {
+		return nil, errors.InvalidArgument("Highlight snippet length cannot be negative")
+	}
+
+	return highlight, nil
+}
+
 type IndexRunner struct {
 	*baseRunner
 
@@ -0,0 +1,349 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	qsort "github.com/tigrisdata/tigris/query/sort"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/server/metadata"
+)
+
+const (
+	defaultSuggestions = 5
+	// suggestHitsFactor is the number of hits fetched per suggestion, as several hits can complete the prefix with the
+	// same value.
+	suggestHitsFactor = 4
+	maxSuggestHits    = 250
+)
+
+// SuggestRunner returns the completions of a prefix from the values of the string fields of a search index, or of a
+// collection. It is on the keystroke path, so it runs a single search on the search store instead of going through
+// the page reader and doesn't decode the hits back to the user documents.
+type SuggestRunner struct {
+	*baseRunner
+
+	req       *api.SuggestRequest
+	analytics *metadata.SearchAnalytics
+}
+
+// suggestTarget is the index, or the implicit index of a collection, the completions are read from.
+type suggestTarget struct {
+	src        metadata.SearchAnalyticsSource
+	storeIndex string
+	fields     []*schema.QueryableField
+}
+
+func (runner *SuggestRunner) Run(ctx context.Context, tenant *metadata.Tenant) (Response, error) {
+	target, err := runner.getTarget(tenant)
+	if err != nil {
+		return Response{}, err
+	}
+
+	fields, err := suggestFields(target.fields, runner.req.GetFields())
+	if err != nil {
+		return Response{}, err
+	}
+
+	weight, err := weightField(target.fields, runner.req.GetWeightField())
+	if err != nil {
+		return Response{}, err
+	}
+
+	wrappedF, err := filter.NewFactory(target.fields, nil).WrappedFilter(runner.req.GetFilter())
+	if err != nil {
+		return Response{}, err
+	}
+
+	size := int(runner.req.GetSize())
+	if size == 0 {
+		size = defaultSuggestions
+	}
+
+	searchFields := make([]string, 0, len(fields))
+	for _, f := range fields {
+		searchFields = append(searchFields, f.InMemoryName())
+	}
+
+	pageSize := size * suggestHitsFactor
+	if pageSize > maxSuggestHits {
+		pageSize = maxSuggestHits
+	}
+
+	builder := qsearch.NewBuilder().
+		Query(runner.req.GetPrefix()).
+		SearchFields(searchFields).
+		Filter(wrappedF).
+		PageSize(pageSize)
+	if weight != nil {
+		builder.SortOrder(&qsort.Ordering{{Name: weight.InMemoryName(), Ascending: false}})
+	}
+
+	results, err := runner.store.Search(ctx, target.storeIndex, builder.Build(), 1)
+	if err != nil {
+		return Response{}, createApiError(err)
+	}
+
+	var popular map[string]int64
+	if runner.req.GetWeightByAnalytics() {
+		if popular, err = runner.analytics.PopularQueries(ctx, target.src); err != nil {
+			return Response{}, err
+		}
+	}
+
+	s := newSuggester(runner.req.GetPrefix(), popular)
+	for _, r := range results {
+		for _, hit := range r.Hits {
+			var score float64
+			if weight != nil {
+				score = numericValue(hit.Document[weight.InMemoryName()])
+			}
+			for _, f := range fields {
+				s.add(hit.Document[f.InMemoryName()], f.Name(), score)
+			}
+		}
+	}
+	s.addQueries()
+
+	return Response{Response: &api.SuggestResponse{Suggestions: s.top(size)}}, nil
+}
+
+func (runner *SuggestRunner) getTarget(tenant *metadata.Tenant) (*suggestTarget, error) {
+	target := &suggestTarget{
+		src: metadata.SearchAnalyticsSource{
+			NamespaceId: tenant.GetNamespace().Id(),
+			Project:     runner.req.GetProject(),
+		},
+	}
+
+	project, err := tenant.GetProject(runner.req.GetProject())
+	if err != nil {
+		return nil, err
+	}
+	target.src.ProjectId = project.Id()
+
+	if len(runner.req.GetCollection()) == 0 {
+		index, err := runner.getIndex(tenant, runner.req.GetProject(), runner.req.GetIndex())
+		if err != nil {
+			return nil, err
+		}
+
+		target.src.Index = runner.req.GetIndex()
+		target.storeIndex = index.StoreIndexName()
+		target.fields = index.QueryableFields
+		return target, nil
+	}
+
+	db, err := project.GetDatabase(metadata.NewDatabaseNameWithBranch(runner.req.GetProject(), runner.req.GetBranch()))
+	if err != nil {
+		return nil, err
+	}
+
+	coll := db.GetCollection(runner.req.GetCollection())
+	if coll == nil {
+		return nil, errors.NotFound("collection doesn't exist '%s'", runner.req.GetCollection())
+	}
+
+	target.src.Branch = db.BranchName()
+	target.src.Collection = runner.req.GetCollection()
+	target.storeIndex = coll.GetImplicitSearchIndex().StoreIndexName()
+	target.fields = coll.QueryableFields
+
+	return target, nil
+}
+
+func isSuggestField(f *schema.QueryableField) bool {
+	if !f.SearchIndexed || f.IsReserved() {
+		return false
+	}
+
+	return f.DataType == schema.StringType || (f.DataType == schema.ArrayType && f.SubType == schema.StringType)
+}
+
+// suggestFields returns the fields the prefix is completed from, all the searchable string fields if none is requested.
+func suggestFields(queryable []*schema.QueryableField, requested []string) ([]*schema.QueryableField, error) {
+	if len(requested) == 0 {
+		var fields []*schema.QueryableField
+		for _, f := range queryable {
+			if isSuggestField(f) {
+				fields = append(fields, f)
+			}
+		}
+		if len(fields) == 0 {
+			return nil, errors.InvalidArgument("no searchable string field to suggest from")
+		}
+
+		return fields, nil
+	}
+
+	fields := make([]*schema.QueryableField, 0, len(requested))
+	for _, name := range requested {
+		f := findField(queryable, name)
+		if f == nil {
+			return nil, errors.InvalidArgument("Field '%s' is not present in the schema", name)
+		}
+		if !isSuggestField(f) {
+			return nil, errors.InvalidArgument("Field '%s' is not a searchable string field", name)
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// weightField returns the numeric field the completions are ranked by, nil if none is requested.
+func weightField(queryable []*schema.QueryableField, name string) (*schema.QueryableField, error) {
+	if len(name) == 0 {
+		return nil, nil
+	}
+
+	f := findField(queryable, name)
+	if f == nil {
+		return nil, errors.InvalidArgument("Field '%s' is not present in the schema", name)
+	}
+
+	switch f.DataType {
+	case schema.Int32Type, schema.Int64Type, schema.DoubleType:
+	default:
+		return nil, errors.InvalidArgument("Field '%s' is not a numeric field", name)
+	}
+	if !f.Sortable {
+		return nil, errors.InvalidArgument("Field '%s' is not sortable", name)
+	}
+
+	return f, nil
+}
+
+func findField(queryable []*schema.QueryableField, name string) *schema.QueryableField {
+	for _, f := range queryable {
+		if f.Name() == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+func numericValue(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	case int:
+		return float64(n)
+	case json.Number:
+		f, _ := n.Float64()
+		return f
+	}
+
+	return 0
+}
+
+// suggester collects the field values completing a prefix. The completions are deduplicated on their normalized form,
+// the first field a value is found in is kept and the highest score wins.
+type suggester struct {
+	prefix      string
+	popular     map[string]int64
+	suggestions []*api.Suggestion
+	seen        map[string]*api.Suggestion
+}
+
+func newSuggester(prefix string, popular map[string]int64) *suggester {
+	return &suggester{
+		prefix:  metadata.NormalizeSearchQuery(prefix),
+		popular: popular,
+		seen:    make(map[string]*api.Suggestion),
+	}
+}
+
+// completes returns true if one of the words of the normalized text starts with the prefix.
+func (s *suggester) completes(normalized string) bool {
+	return strings.HasPrefix(normalized, s.prefix) || strings.Contains(normalized, " "+s.prefix)
+}
+
+func (s *suggester) add(value any, field string, score float64) {
+	switch v := value.(type) {
+	case string:
+		s.addText(v, field, score)
+	case []any:
+		for _, e := range v {
+			if str, ok := e.(string); ok {
+				s.addText(str, field, score)
+			}
+		}
+	}
+}
+
+func (s *suggester) addText(text string, field string, score float64) {
+	normalized := metadata.NormalizeSearchQuery(text)
+	if len(normalized) == 0 || !s.completes(normalized) {
+		return
+	}
+
+	score += float64(s.popular[normalized])
+	if existing, ok := s.seen[normalized]; ok {
+		if score > existing.Score {
+			existing.Score = score
+		}
+		return
+	}
+
+	suggestion := &api.Suggestion{Text: text, Field: field, Score: score}
+	s.seen[normalized] = suggestion
+	s.suggestions = append(s.suggestions, suggestion)
+}
+
+// addQueries adds the popular past queries completing the prefix, they are not attached to any field.
+func (s *suggester) addQueries() {
+	queries := make([]string, 0, len(s.popular))
+	for q := range s.popular {
+		queries = append(queries, q)
+	}
+	// the map order is random
+	sort.Strings(queries)
+
+	for _, q := range queries {
+		if _, ok := s.seen[q]; ok || !strings.HasPrefix(q, s.prefix) || q == s.prefix {
+			continue
+		}
+
+		suggestion := &api.Suggestion{Text: q, Score: float64(s.popular[q])}
+		s.seen[q] = suggestion
+		s.suggestions = append(s.suggestions, suggestion)
+	}
+}
+
+// top returns the completions with the highest score, the completions with the same score are kept in the order of the
+// hits.
+func (s *suggester) top(size int) []*api.Suggestion {
+	sort.SliceStable(s.suggestions, func(i, j int) bool {
+		return s.suggestions[i].Score > s.suggestions[j].Score
+	})
+
+	if len(s.suggestions) > size {
+		return s.suggestions[:size]
+	}
+
+	return s.suggestions
+}
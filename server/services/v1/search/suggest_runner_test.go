@@ -0,0 +1,76 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	api "github.com/tigrisdata/tigris/api/server/v1"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+func TestSuggester(t *testing.T) {
+	s := newSuggester("Re", map[string]int64{"red boots": 10, "red": 3, "blue": 7})
+	s.add("Red Shoes", "name", 2)
+	s.add([]any{"running", "Blue", "red  shoes"}, "tags", 5)
+	s.add("Bright red", "name", 1)
+	s.add(json.Number("12"), "name", 1)
+	s.addQueries()
+
+	require.Equal(t, []*api.Suggestion{
+		{Text: "red boots", Score: 10},
+		{Text: "Red Shoes", Field: "name", Score: 5},
+		{Text: "red", Score: 3},
+	}, s.top(3))
+	require.Len(t, s.suggestions, 4)
+}
+
+func TestSuggestFields(t *testing.T) {
+	fields := []*schema.QueryableField{
+		{FieldName: "name", InMemoryAlias: "name", DataType: schema.StringType, SearchIndexed: true},
+		{FieldName: "tags", InMemoryAlias: "tags", DataType: schema.ArrayType, SubType: schema.StringType, SearchIndexed: true},
+		{FieldName: "notes", InMemoryAlias: "notes", DataType: schema.StringType},
+		{FieldName: "rank", InMemoryAlias: "rank", DataType: schema.Int64Type, SearchIndexed: true, Sortable: true},
+		{FieldName: "price", InMemoryAlias: "price", DataType: schema.DoubleType, SearchIndexed: true},
+	}
+
+	suggest, err := suggestFields(fields, nil)
+	require.NoError(t, err)
+	require.Equal(t, fields[:2], suggest)
+
+	suggest, err = suggestFields(fields, []string{"tags"})
+	require.NoError(t, err)
+	require.Equal(t, fields[1:2], suggest)
+
+	_, err = suggestFields(fields, []string{"notes"})
+	require.Error(t, err)
+	_, err = suggestFields(fields, []string{"unknown"})
+	require.Error(t, err)
+
+	weight, err := weightField(fields, "rank")
+	require.NoError(t, err)
+	require.Equal(t, fields[3], weight)
+
+	weight, err = weightField(fields, "")
+	require.NoError(t, err)
+	require.Nil(t, weight)
+
+	_, err = weightField(fields, "price")
+	require.Error(t, err)
+	_, err = weightField(fields, "name")
+	require.Error(t, err)
+}
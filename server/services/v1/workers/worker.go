@@ -214,6 +214,12 @@ func (w *Worker) processItem(queueItem *metadata.QueueItem) error {
 		return w.testQueueTask(queueItem)
 	case metadata.BUILD_SEARCH_INDEX_TASK:
 		return w.buildSearchTask(queueItem)
+	case metadata.EMBEDDING_TASK:
+		return w.embeddingTask(queueItem)
+	case metadata.SEARCH_VERIFY_TASK:
+		return w.searchVerifyTask(queueItem)
+	case metadata.DROP_SEARCH_INDEX_TASK:
+		return w.dropSearchIndexTask(queueItem)
 	}
 
 	return fmt.Errorf("unknown job type")
@@ -343,14 +349,14 @@ func (w *Worker) buildSearchTask(queueItem *metadata.QueueItem) error {
 		Project:    task.ProjName,
 	}
 
-	qr := database.NewQueryRunnerFactory(w.txMgr, nil, w.searchStore)
+	qr := database.NewQueryRunnerFactory(w.txMgr, nil, w.searchStore, w.tenantMgr.GetSearchAnalytics())
 	searchIndexer := qr.GetSearchIndexRunner(req, &metrics.WriteQueryMetrics{}, nil)
-	searchIndexer.ProgressUpdate = func(ctx context.Context) error {
+	searchIndexer.ProgressUpdate = func(ctx context.Context, rowsWritten int64) error {
 		tx, err := w.txMgr.StartTx(ctx)
 		if err != nil {
 			return err
 		}
-		if err = w.queue.RenewLease(ctx, tx, queueItem, LEASE_TIME); err != nil {
+		if err = w.queue.ReportProgress(ctx, tx, queueItem, rowsWritten, LEASE_TIME); err != nil {
 			return err
 		}
 		return tx.Commit(ctx)
@@ -366,7 +372,159 @@ func (w *Worker) buildSearchTask(queueItem *metadata.QueueItem) error {
 		return err
 	}
 
-	if err = tenant.UpdateSearchStatus(ctx, tx, db, coll, schema.SearchIndexActive); err != nil {
+	// a rebuilt index replaces the current one, otherwise the current index has been built in place
+	if coll.ImplicitSearchIndex.Shadow != nil {
+		if err = tenant.PromoteSearchIndex(ctx, tx, db, coll); err != nil {
+			return err
+		}
+	} else if err = tenant.UpdateSearchStatus(ctx, tx, db, coll, schema.SearchIndexActive); err != nil {
+		return err
+	}
+
+	if err = w.queue.Complete(ctx, tx, queueItem); ulog.E(err) {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (w *Worker) dropSearchIndexTask(queueItem *metadata.QueueItem) error {
+	var task metadata.DropSearchIndexTask
+	if err := jsoniter.Unmarshal(queueItem.Data, &task); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := w.searchStore.DropCollection(ctx, task.Index); err != nil && !search.IsErrNotFound(err) {
+		return err
+	}
+
+	tx, err := w.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = w.queue.Complete(ctx, tx, queueItem); ulog.E(err) {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (w *Worker) embeddingTask(queueItem *metadata.QueueItem) error {
+	var task metadata.EmbeddingTask
+	if err := jsoniter.Unmarshal(queueItem.Data, &task); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	dbBranch := metadata.NewDatabaseNameWithBranch(task.ProjName, task.Branch)
+	tenant, err := w.tenantMgr.GetTenant(ctx, task.NamespaceId)
+	if err != nil {
+		return err
+	}
+
+	project, err := tenant.GetProject(task.ProjName)
+	if err != nil {
+		return err
+	}
+
+	db, err := project.GetDatabase(dbBranch)
+	if err != nil {
+		return err
+	}
+	coll := db.GetCollection(task.CollName)
+	if coll == nil {
+		return fmt.Errorf("could not find collection \"%s\"", task.CollName)
+	}
+
+	var searchIndexer *database.SearchIndexer
+	if config.DefaultConfig.Search.WriteEnabled {
+		searchIndexer = database.NewSearchIndexer(w.searchStore, w.tenantMgr, w.txMgr)
+	}
+
+	embedder := database.NewEmbedder(tenant, coll, task.Fields, w.txMgr, searchIndexer)
+	if len(task.Keys) > 0 {
+		err = embedder.EmbedKeys(ctx, task.Keys)
+	} else {
+		err = embedder.EmbedCollection(ctx, func(ctx context.Context) error {
+			tx, err := w.txMgr.StartTx(ctx)
+			if err != nil {
+				return err
+			}
+			if err = w.queue.RenewLease(ctx, tx, queueItem, LEASE_TIME); err != nil {
+				return err
+			}
+			return tx.Commit(ctx)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	tx, err := w.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = w.queue.Complete(ctx, tx, queueItem); ulog.E(err) {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (w *Worker) searchVerifyTask(queueItem *metadata.QueueItem) error {
+	var task metadata.SearchVerifyTask
+	if err := jsoniter.Unmarshal(queueItem.Data, &task); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	dbBranch := metadata.NewDatabaseNameWithBranch(task.ProjName, task.Branch)
+	tenant, err := w.tenantMgr.GetTenant(ctx, task.NamespaceId)
+	if err != nil {
+		return err
+	}
+
+	project, err := tenant.GetProject(task.ProjName)
+	if err != nil {
+		return err
+	}
+
+	db, err := project.GetDatabase(dbBranch)
+	if err != nil {
+		return err
+	}
+	coll := db.GetCollection(task.CollName)
+	if coll == nil {
+		return fmt.Errorf("could not find collection \"%s\"", task.CollName)
+	}
+
+	verifier := database.NewSearchVerifier(coll, w.txMgr, w.searchStore, task.Repair)
+	report, err := verifier.VerifyCollection(ctx, func(ctx context.Context) error {
+		tx, err := w.txMgr.StartTx(ctx)
+		if err != nil {
+			return err
+		}
+		if err = w.queue.RenewLease(ctx, tx, queueItem, LEASE_TIME); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics.UpdateSearchDriftMetrics(task.NamespaceId, tenant.GetNamespace().Metadata().Name, db.DbName(), db.BranchName(),
+		coll.Name, report.Scanned, report.Missing, report.Stale, report.Repaired)
+
+	tx, err := w.txMgr.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = tenant.CompleteSearchVerification(ctx, tx, db, coll, queueItem, report); ulog.E(err) {
 		return err
 	}
 
@@ -42,6 +42,7 @@ type BaseTx interface {
 	Insert(ctx context.Context, key keys.Key, data *internal.TableData) error
 	Replace(ctx context.Context, key keys.Key, data *internal.TableData, isUpdate bool) error
 	Delete(ctx context.Context, key keys.Key) error
+	DeleteRange(ctx context.Context, lKey keys.Key, rKey keys.Key) error
 	Read(ctx context.Context, key keys.Key, reverse bool) (kv.Iterator, error)
 	ReadRange(ctx context.Context, lKey keys.Key, rKey keys.Key, isSnapshot bool, reverse bool) (kv.Iterator, error)
 	Get(ctx context.Context, key []byte, isSnapshot bool) (kv.Future, error)
@@ -222,6 +223,19 @@ func (s *TxSession) Delete(ctx context.Context, key keys.Key) error {
 	return s.kTx.Delete(ctx, key.Table(), kv.BuildKey(key.IndexParts()...))
 }
 
+// DeleteRange clears the keys in the [lKey, rKey) range, the size statistics of the table and the event listeners
+// aren't updated, so it is only meant for the metadata.
+func (s *TxSession) DeleteRange(ctx context.Context, lKey keys.Key, rKey keys.Key) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.validateSession(); err != nil {
+		return err
+	}
+
+	return s.kTx.DeleteRange(ctx, lKey.Table(), kv.BuildKey(lKey.IndexParts()...), kv.BuildKey(rKey.IndexParts()...))
+}
+
 func (s *TxSession) Read(ctx context.Context, key keys.Key, reverse bool) (kv.Iterator, error) {
 	s.Lock()
 	defer s.Unlock()
@@ -17,6 +17,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -90,7 +91,7 @@ func (c *cache) GetSet(ctx context.Context, tableName string, key string, value
 		return nil, nil
 	}
 
-	return internal.DecodeCacheData(val)
+	return decodeValue(val)
 }
 
 func (c *cache) Get(ctx context.Context, tableName string, key string, options *GetOptions) (*internal.CacheData, error) {
@@ -115,7 +116,7 @@ func (c *cache) Get(ctx context.Context, tableName string, key string, options *
 		return nil, err
 	}
 
-	return internal.DecodeCacheData(value)
+	return decodeValue(value)
 }
 
 func (c *cache) Delete(ctx context.Context, tableName string, keys ...string) (int64, error) {
@@ -156,6 +157,146 @@ func (c *cache) Scan(ctx context.Context, tableName string, cursor uint64, count
 	return scanCmd.Val()
 }
 
+// maxCounterConversions is how many times the conversion of a counter set with Set is retried when the key is
+// modified concurrently.
+const maxCounterConversions = 10
+
+// IncrBy increments the counter with INCRBY, the counters are stored as native integers. A counter set with Set is
+// encoded like any other value, it is converted to a native integer by its first increment.
+func (c *cache) IncrBy(ctx context.Context, tableName string, key string, increment int64) (int64, error) {
+	cacheKey := encodeToCacheKey(tableName, key)
+
+	value, err := c.Client.IncrBy(ctx, cacheKey, increment).Result()
+	if isNotIntegerErr(err) {
+		return c.convertAndIncrBy(ctx, cacheKey, increment)
+	}
+
+	return value, err
+}
+
+// convertAndIncrBy replaces the encoded counter with a native integer and increments it in a transaction watching the
+// key.
+func (c *cache) convertAndIncrBy(ctx context.Context, cacheKey string, increment int64) (int64, error) {
+	var incr *xredis.IntCmd
+	convert := func(tx *xredis.Tx) error {
+		current, err := tx.Get(ctx, cacheKey).Bytes()
+		if err != nil && err != xredis.Nil {
+			return err
+		}
+
+		var value int64
+		if err == nil {
+			data, err := internal.DecodeCacheData(current)
+			if err != nil {
+				return ErrNotInteger
+			}
+			if value, err = parseCounter(data.GetRawData()); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe xredis.Pipeliner) error {
+			pipe.SetArgs(ctx, cacheKey, value, xredis.SetArgs{KeepTTL: true})
+			incr = pipe.IncrBy(ctx, cacheKey, increment)
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < maxCounterConversions; i++ {
+		err := c.Client.Watch(ctx, convert, cacheKey)
+		if err == xredis.TxFailedErr {
+			continue
+		}
+		if isNotIntegerErr(err) {
+			return 0, ErrNotInteger
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		return incr.Val(), nil
+	}
+
+	return 0, ErrNotInteger
+}
+
+// isNotIntegerErr returns true for the errors of INCRBY on a value which isn't an integer or when the result overflows.
+func isNotIntegerErr(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "not an integer") || strings.Contains(err.Error(), "overflow"))
+}
+
+// decodeValue decodes a value read from the cache. The counters are stored as native integers, they are returned as
+// the raw data of a CacheData like the other values.
+func decodeValue(value []byte) (*internal.CacheData, error) {
+	if len(value) > 0 && value[0] != internal.CacheDataType {
+		if _, err := parseCounter(value); err == nil {
+			return internal.NewCacheData(value), nil
+		}
+	}
+
+	return internal.DecodeCacheData(value)
+}
+
+func (c *cache) Expire(ctx context.Context, tableName string, key string, ttl time.Duration) (bool, error) {
+	return c.Client.PExpire(ctx, encodeToCacheKey(tableName, key), ttl).Result()
+}
+
+func (c *cache) Persist(ctx context.Context, tableName string, key string) (bool, error) {
+	cacheKey := encodeToCacheKey(tableName, key)
+
+	// persist returns false for a key without expiry too, the existence is checked to tell both apart
+	removed, err := c.Client.Persist(ctx, cacheKey).Result()
+	if err != nil || removed {
+		return removed, err
+	}
+
+	exists, err := c.Client.Exists(ctx, cacheKey).Result()
+	if err != nil {
+		return false, err
+	}
+	if exists == 0 {
+		return false, ErrKeyNotFound
+	}
+
+	return false, nil
+}
+
+func (c *cache) TTL(ctx context.Context, tableName string, key string) (time.Duration, error) {
+	ttl, err := c.Client.PTTL(ctx, encodeToCacheKey(tableName, key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// redis returns -2 if the key doesn't exist and -1 if it has no expiry
+	switch ttl {
+	case -2:
+		return 0, ErrKeyNotFound
+	case -1:
+		return NoExpiry, nil
+	}
+
+	return ttl, nil
+}
+
+func (c *cache) MemoryUsage(ctx context.Context, tableName string, key string) (int64, error) {
+	size, err := c.Client.MemoryUsage(ctx, encodeToCacheKey(tableName, key)).Result()
+	if err == xredis.Nil {
+		return 0, ErrKeyNotFound
+	}
+
+	return size, err
+}
+
+func parseCounter(raw []byte) (int64, error) {
+	value, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+
+	return value, nil
+}
+
 func (c *cache) ListStreams(ctx context.Context, streamNamePrefix string) ([]string, error) {
 	return c.Client.Keys(ctx, streamNamePrefix).Result()
 }
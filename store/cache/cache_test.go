@@ -17,15 +17,18 @@ package cache
 import (
 	"context"
 	"fmt"
+	"math"
 	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/tigrisdata/tigris/internal"
 	"github.com/tigrisdata/tigris/server/config"
 )
 
-func dropCacheTable(t *testing.T, c *cache, tableName string) {
+func dropCacheTable(t *testing.T, c Cache, tableName string) {
 	keys, err := c.Keys(context.TODO(), tableName, "*")
 	require.NoError(t, err)
 
@@ -37,7 +40,14 @@ func dropCacheTable(t *testing.T, c *cache, tableName string) {
 }
 
 func TestRedis(t *testing.T) {
-	c := newCache(config.GetTestCacheConfig())
+	testCache(t, newCache(config.GetTestCacheConfig()))
+}
+
+func TestMemory(t *testing.T) {
+	testCache(t, newMemoryCache())
+}
+
+func testCache(t *testing.T, c Cache) {
 	ctx := context.TODO()
 	tableName := "cache_test"
 
@@ -123,6 +133,49 @@ func TestRedis(t *testing.T) {
 		require.Equal(t, []string{encodeToCacheKey(tableName, "key1"), encodeToCacheKey(tableName, "key2")}, keys)
 	})
 
+	t.Run("set_expiry", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		require.NoError(t, c.Set(ctx, tableName, "key1", internal.NewCacheData([]byte(`{"a": "b"}`)), &SetOptions{PX: 50}))
+		_, err := c.Get(ctx, tableName, "key1", nil)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			_, err := c.Get(ctx, tableName, "key1", nil)
+			return err == ErrKeyNotFound
+		}, time.Second, 10*time.Millisecond)
+
+		keys, err := c.Keys(ctx, tableName, "*")
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("keys_pattern", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		s1 := []byte(`{"a": "b"}`)
+		for _, k := range []string{"user1", "user2", "user10", "order1"} {
+			require.NoError(t, c.Set(ctx, tableName, k, internal.NewCacheData(s1), nil))
+		}
+
+		for pattern, expected := range map[string][]string{
+			"user*":      {"user1", "user10", "user2"},
+			"user?":      {"user1", "user2"},
+			"user[^1]":   {"user2"},
+			"?rder[0-9]": {"order1"},
+		} {
+			keys, err := c.Keys(ctx, tableName, pattern)
+			require.NoError(t, err)
+			sort.Strings(keys)
+
+			encoded := make([]string, len(expected))
+			for i, k := range expected {
+				encoded[i] = encodeToCacheKey(tableName, k)
+			}
+			require.Equal(t, encoded, keys, pattern)
+		}
+	})
+
 	t.Run("scan", func(t *testing.T) {
 		defer dropCacheTable(t, c, tableName)
 
@@ -155,4 +208,251 @@ func TestRedis(t *testing.T) {
 			require.Truef(t, contains, "key %s not found", keyToSearch)
 		}
 	})
+
+	t.Run("incr", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		v, err := c.IncrBy(ctx, tableName, "counter", 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), v)
+
+		v, err = c.IncrBy(ctx, tableName, "counter", -5)
+		require.NoError(t, err)
+		require.Equal(t, int64(-4), v)
+
+		g, err := c.Get(ctx, tableName, "counter", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("-4"), g.RawData)
+
+		require.NoError(t, c.Set(ctx, tableName, "counter", internal.NewCacheData([]byte(`10`)), &SetOptions{EX: 100}))
+		v, err = c.IncrBy(ctx, tableName, "counter", 2)
+		require.NoError(t, err)
+		require.Equal(t, int64(12), v)
+
+		// the expiry is kept
+		ttl, err := c.TTL(ctx, tableName, "counter")
+		require.NoError(t, err)
+		require.True(t, ttl > 0)
+
+		require.NoError(t, c.Set(ctx, tableName, "not_counter", internal.NewCacheData([]byte(`{"a": "b"}`)), nil))
+		_, err = c.IncrBy(ctx, tableName, "not_counter", 1)
+		require.Equal(t, ErrNotInteger, err)
+
+		require.NoError(t, c.Set(ctx, tableName, "max", internal.NewCacheData([]byte(`9223372036854775807`)), nil))
+		_, err = c.IncrBy(ctx, tableName, "max", 1)
+		require.Equal(t, ErrNotInteger, err)
+
+		// the values beyond the precision of a double stay exact
+		v, err = c.IncrBy(ctx, tableName, "max", -9223372036854775807)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), v)
+		v, err = c.IncrBy(ctx, tableName, "max", math.MinInt64)
+		require.NoError(t, err)
+		require.Equal(t, int64(math.MinInt64), v)
+		_, err = c.IncrBy(ctx, tableName, "max", -1)
+		require.Equal(t, ErrNotInteger, err)
+		v, err = c.IncrBy(ctx, tableName, "max", 9007199254740993)
+		require.NoError(t, err)
+		require.Equal(t, int64(math.MinInt64+9007199254740993), v)
+
+		require.NoError(t, c.Set(ctx, tableName, "padded", internal.NewCacheData([]byte(` 0999999999 `)), nil))
+		v, err = c.IncrBy(ctx, tableName, "padded", 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000000000), v)
+		g, err = c.Get(ctx, tableName, "padded", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("1000000000"), g.RawData)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					_, err := c.IncrBy(ctx, tableName, "concurrent", 1)
+					require.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+		g, err = c.Get(ctx, tableName, "concurrent", nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("100"), g.RawData)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		_, err := c.TTL(ctx, tableName, "key1")
+		require.Equal(t, ErrKeyNotFound, err)
+
+		exists, err := c.Expire(ctx, tableName, "key1", time.Minute)
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		require.NoError(t, c.Set(ctx, tableName, "key1", internal.NewCacheData([]byte(`{"a": "b"}`)), nil))
+		ttl, err := c.TTL(ctx, tableName, "key1")
+		require.NoError(t, err)
+		require.Equal(t, NoExpiry, ttl)
+
+		exists, err = c.Expire(ctx, tableName, "key1", time.Minute)
+		require.NoError(t, err)
+		require.True(t, exists)
+
+		ttl, err = c.TTL(ctx, tableName, "key1")
+		require.NoError(t, err)
+		require.True(t, ttl > 0 && ttl <= time.Minute)
+
+		removed, err := c.Persist(ctx, tableName, "key1")
+		require.NoError(t, err)
+		require.True(t, removed)
+
+		removed, err = c.Persist(ctx, tableName, "key1")
+		require.NoError(t, err)
+		require.False(t, removed)
+
+		_, err = c.Persist(ctx, tableName, "key2")
+		require.Equal(t, ErrKeyNotFound, err)
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		n, err := c.HSet(ctx, tableName, "h1", map[string][]byte{"f1": []byte("v1"), "f2": []byte("v2")})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), n)
+
+		v, err := c.HGet(ctx, tableName, "h1", "f1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), v)
+
+		_, err = c.HGet(ctx, tableName, "h1", "f3")
+		require.Equal(t, ErrKeyNotFound, err)
+
+		n, err = c.HDel(ctx, tableName, "h1", "f1", "f3")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+
+		fields, err := c.HGetAll(ctx, tableName, "h1")
+		require.NoError(t, err)
+		require.Equal(t, map[string][]byte{"f2": []byte("v2")}, fields)
+
+		removed, err := c.HDelIfEqual(ctx, tableName, "h1", "f2", []byte("v1"))
+		require.NoError(t, err)
+		require.False(t, removed)
+		removed, err = c.HDelIfEqual(ctx, tableName, "h1", "f2", []byte("v2"))
+		require.NoError(t, err)
+		require.True(t, removed)
+		removed, err = c.HDelIfEqual(ctx, tableName, "h1", "f2", []byte("v2"))
+		require.NoError(t, err)
+		require.False(t, removed)
+
+		// a hash operation on a list is rejected
+		_, err = c.RPush(ctx, tableName, "l1", []byte("a"))
+		require.NoError(t, err)
+		_, err = c.HGetAll(ctx, tableName, "l1")
+		require.True(t, IsWrongType(err))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		n, err := c.RPush(ctx, tableName, "l1", []byte("b"), []byte("c"))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), n)
+
+		n, err = c.LPush(ctx, tableName, "l1", []byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, int64(3), n)
+
+		values, err := c.LRange(ctx, tableName, "l1", 0, -1)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, values)
+
+		values, err = c.LPop(ctx, tableName, "l1", 1)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("a")}, values)
+
+		values, err = c.RPop(ctx, tableName, "l1", 5)
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("c"), []byte("b")}, values)
+
+		values, err = c.LPop(ctx, tableName, "l1", 1)
+		require.NoError(t, err)
+		require.Empty(t, values)
+	})
+
+	t.Run("set_members", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		n, err := c.SAdd(ctx, tableName, "s1", []byte("a"), []byte("b"), []byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), n)
+
+		ok, err := c.SIsMember(ctx, tableName, "s1", []byte("a"))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		n, err = c.SRem(ctx, tableName, "s1", []byte("a"), []byte("c"))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+
+		ok, err = c.SIsMember(ctx, tableName, "s1", []byte("a"))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		members, err := c.SMembers(ctx, tableName, "s1")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("b")}, members)
+	})
+
+	t.Run("sorted_set", func(t *testing.T) {
+		defer dropCacheTable(t, c, tableName)
+
+		n, err := c.ZAdd(ctx, tableName, "z1",
+			ZMember{Score: 3, Member: []byte("c")},
+			ZMember{Score: 1, Member: []byte("a")},
+			ZMember{Score: 2, Member: []byte("b")},
+		)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), n)
+
+		members, err := c.ZRange(ctx, tableName, "z1", 0, 1, false)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{Score: 1, Member: []byte("a")}, {Score: 2, Member: []byte("b")}}, members)
+
+		members, err = c.ZRange(ctx, tableName, "z1", 0, 0, true)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{Score: 3, Member: []byte("c")}}, members)
+
+		members, err = c.ZRangeByScore(ctx, tableName, "z1", "(1", "+inf", 1, 0)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{Score: 3, Member: []byte("c")}}, members)
+
+		n, err = c.ZRem(ctx, tableName, "z1", []byte("b"))
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+
+		members, err = c.ZRangeByScore(ctx, tableName, "z1", "-inf", "+inf", 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, []ZMember{{Score: 1, Member: []byte("a")}, {Score: 3, Member: []byte("c")}}, members)
+	})
+}
+
+func TestDecodeValue(t *testing.T) {
+	// the counters are stored as native integers
+	for _, digits := range []string{"0", "-12345678901", "-9223372036854775808"} {
+		data, err := decodeValue([]byte(digits))
+		require.NoError(t, err)
+		require.Equal(t, []byte(digits), data.RawData)
+	}
+
+	enc, err := internal.EncodeCacheData(internal.NewCacheData([]byte(`{"a": "b"}`)))
+	require.NoError(t, err)
+	data, err := decodeValue(enc)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"a": "b"}`), data.RawData)
+
+	_, err = decodeValue([]byte("not a counter"))
+	require.Error(t, err)
 }
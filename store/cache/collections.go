@@ -0,0 +1,220 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+
+	xredis "github.com/go-redis/redis/v8"
+)
+
+// The values of the hashes, lists, sets and sorted sets are stored as is, unlike the values set with Set which are
+// wrapped in a CacheData, so that the members of the sets can be compared by Redis.
+
+func (c *cache) HSet(ctx context.Context, tableName string, key string, fields map[string][]byte) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+
+	values := make([]any, 0, 2*len(fields))
+	for f, v := range fields {
+		values = append(values, f, v)
+	}
+
+	return c.Client.HSet(ctx, encodeToCacheKey(tableName, key), values...).Result()
+}
+
+func (c *cache) HGet(ctx context.Context, tableName string, key string, field string) ([]byte, error) {
+	value, err := c.Client.HGet(ctx, encodeToCacheKey(tableName, key), field).Bytes()
+	if err == xredis.Nil {
+		return nil, ErrKeyNotFound
+	}
+
+	return value, err
+}
+
+func (c *cache) HGetAll(ctx context.Context, tableName string, key string) (map[string][]byte, error) {
+	values, err := c.Client.HGetAll(ctx, encodeToCacheKey(tableName, key)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string][]byte, len(values))
+	for f, v := range values {
+		fields[f] = []byte(v)
+	}
+
+	return fields, nil
+}
+
+func (c *cache) HDel(ctx context.Context, tableName string, key string, fields ...string) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+
+	return c.Client.HDel(ctx, encodeToCacheKey(tableName, key), fields...).Result()
+}
+
+// hDelIfEqualScript removes the field ARGV[1] of the hash KEYS[1] if it holds the value ARGV[2].
+var hDelIfEqualScript = xredis.NewScript(`
+if redis.call('HGET', KEYS[1], ARGV[1]) == ARGV[2] then
+	return redis.call('HDEL', KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+func (c *cache) HDelIfEqual(ctx context.Context, tableName string, key string, field string, value []byte) (bool, error) {
+	removed, err := hDelIfEqualScript.Run(ctx, c.Client, []string{encodeToCacheKey(tableName, key)}, field, value).Int64()
+	return removed > 0, err
+}
+
+func (c *cache) LPush(ctx context.Context, tableName string, key string, values ...[]byte) (int64, error) {
+	if len(values) == 0 {
+		return c.Client.LLen(ctx, encodeToCacheKey(tableName, key)).Result()
+	}
+
+	return c.Client.LPush(ctx, encodeToCacheKey(tableName, key), toRedisValues(values)...).Result()
+}
+
+func (c *cache) RPush(ctx context.Context, tableName string, key string, values ...[]byte) (int64, error) {
+	if len(values) == 0 {
+		return c.Client.LLen(ctx, encodeToCacheKey(tableName, key)).Result()
+	}
+
+	return c.Client.RPush(ctx, encodeToCacheKey(tableName, key), toRedisValues(values)...).Result()
+}
+
+func (c *cache) LPop(ctx context.Context, tableName string, key string, count int) ([][]byte, error) {
+	return fromRedisValues(c.Client.LPopCount(ctx, encodeToCacheKey(tableName, key), count).Result())
+}
+
+func (c *cache) RPop(ctx context.Context, tableName string, key string, count int) ([][]byte, error) {
+	return fromRedisValues(c.Client.RPopCount(ctx, encodeToCacheKey(tableName, key), count).Result())
+}
+
+func (c *cache) LRange(ctx context.Context, tableName string, key string, start int64, stop int64) ([][]byte, error) {
+	return fromRedisValues(c.Client.LRange(ctx, encodeToCacheKey(tableName, key), start, stop).Result())
+}
+
+func (c *cache) SAdd(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	return c.Client.SAdd(ctx, encodeToCacheKey(tableName, key), toRedisValues(members)...).Result()
+}
+
+func (c *cache) SRem(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	return c.Client.SRem(ctx, encodeToCacheKey(tableName, key), toRedisValues(members)...).Result()
+}
+
+func (c *cache) SMembers(ctx context.Context, tableName string, key string) ([][]byte, error) {
+	return fromRedisValues(c.Client.SMembers(ctx, encodeToCacheKey(tableName, key)).Result())
+}
+
+func (c *cache) SIsMember(ctx context.Context, tableName string, key string, member []byte) (bool, error) {
+	return c.Client.SIsMember(ctx, encodeToCacheKey(tableName, key), member).Result()
+}
+
+func (c *cache) ZAdd(ctx context.Context, tableName string, key string, members ...ZMember) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	zs := make([]*xredis.Z, len(members))
+	for i, m := range members {
+		zs[i] = &xredis.Z{Score: m.Score, Member: m.Member}
+	}
+
+	return c.Client.ZAdd(ctx, encodeToCacheKey(tableName, key), zs...).Result()
+}
+
+func (c *cache) ZIncrBy(ctx context.Context, tableName string, key string, increment float64, member []byte) (float64, error) {
+	return c.Client.ZIncrBy(ctx, encodeToCacheKey(tableName, key), increment, string(member)).Result()
+}
+
+func (c *cache) ZRem(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	return c.Client.ZRem(ctx, encodeToCacheKey(tableName, key), toRedisValues(members)...).Result()
+}
+
+func (c *cache) ZRange(ctx context.Context, tableName string, key string, start int64, stop int64, rev bool) ([]ZMember, error) {
+	if rev {
+		return fromRedisZ(c.Client.ZRevRangeWithScores(ctx, encodeToCacheKey(tableName, key), start, stop).Result())
+	}
+
+	return fromRedisZ(c.Client.ZRangeWithScores(ctx, encodeToCacheKey(tableName, key), start, stop).Result())
+}
+
+func (c *cache) ZRangeByScore(ctx context.Context, tableName string, key string, min string, max string, offset int64, count int64) ([]ZMember, error) {
+	opt := &xredis.ZRangeBy{
+		Min:    min,
+		Max:    max,
+		Offset: offset,
+		Count:  count,
+	}
+	if count == 0 && offset > 0 {
+		// a limit needs a count, a negative count returns all the remaining members
+		opt.Count = -1
+	}
+
+	return fromRedisZ(c.Client.ZRangeByScoreWithScores(ctx, encodeToCacheKey(tableName, key), opt).Result())
+}
+
+func toRedisValues(values [][]byte) []any {
+	converted := make([]any, len(values))
+	for i, v := range values {
+		converted[i] = v
+	}
+
+	return converted
+}
+
+func fromRedisValues(values []string, err error) ([][]byte, error) {
+	if err == xredis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([][]byte, len(values))
+	for i, v := range values {
+		converted[i] = []byte(v)
+	}
+
+	return converted, nil
+}
+
+func fromRedisZ(zs []xredis.Z, err error) ([]ZMember, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]ZMember, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		members[i] = ZMember{Score: z.Score, Member: []byte(member)}
+	}
+
+	return members, nil
+}
@@ -16,6 +16,7 @@ package cache
 
 import (
 	"fmt"
+	"strings"
 )
 
 type ErrCode byte
@@ -23,6 +24,7 @@ type ErrCode byte
 const (
 	errStrNoSuchKey              = "ERR no such key"
 	errStrConsGroupAlreadyExists = "Consumer Group name already exists"
+	errStrWrongType              = "WRONGTYPE"
 )
 
 const (
@@ -31,6 +33,9 @@ const (
 	ErrCodeKeyNotFound      ErrCode = 0x03
 	ErrCodeKeyAlreadyExists ErrCode = 0x04
 	ErrCodeEmptyKey         ErrCode = 0x05
+	ErrCodeWrongType        ErrCode = 0x06
+	ErrCodeNotInteger       ErrCode = 0x07
+	ErrCodeLimitExceeded    ErrCode = 0x09
 )
 
 var (
@@ -41,6 +46,10 @@ var (
 	ErrKeyNotFound      = NewCacheError(ErrCodeKeyNotFound, "key not found")
 	ErrKeyAlreadyExists = NewCacheError(ErrCodeKeyAlreadyExists, "key already exists")
 	ErrEmptyKey         = NewCacheError(ErrCodeEmptyKey, "key is empty")
+	ErrWrongType        = NewCacheError(ErrCodeWrongType, "key holds a different kind of value")
+	ErrNotInteger       = NewCacheError(ErrCodeNotInteger, "value is not an integer or out of range")
+	// ErrLimitExceeded is returned when a write doesn't fit in the limits of the table and nothing can be evicted.
+	ErrLimitExceeded = NewCacheError(ErrCodeLimitExceeded, "cache limit exceeded")
 )
 
 type Error struct {
@@ -59,3 +68,9 @@ func (se Error) Error() string {
 func IsStreamAlreadyExists(err error) bool {
 	return err == ErrStreamAlreadyExists
 }
+
+// IsWrongType returns true if the operation was applied to a key holding a different kind of value, like a list
+// operation on a hash.
+func IsWrongType(err error) bool {
+	return err == ErrWrongType || (err != nil && strings.HasPrefix(err.Error(), errStrWrongType))
+}
@@ -0,0 +1,1037 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+// The eviction policies of the tables with limits.
+const (
+	// EvictLRU evicts the least recently used keys.
+	EvictLRU = "lru"
+	// EvictLFU evicts the least frequently used keys.
+	EvictLFU = "lfu"
+	// EvictTTL only evicts the keys having an expiry, the ones closest to expire first.
+	EvictTTL = "ttl"
+	// EvictNone doesn't evict anything, the writes are rejected once a limit is reached.
+	EvictNone = "reject"
+)
+
+// limitsReloadInterval is how long the limits of a table are kept in memory. The limits are only set when a cache is
+// created, so this only delays seeing a table dropped and created again on another server.
+const limitsReloadInterval = 10 * time.Second
+
+// accessFlushInterval is how often the reads of the keys buffered for the LRU and LFU policies are recorded, they are
+// also recorded before a write of the table.
+const accessFlushInterval = time.Second
+
+// usagePrefix is prepended to the name of a table to track its usage outside the keys of the table.
+const usagePrefix = "usage:"
+
+// The keys tracking the usage of a table.
+const (
+	usageLimitsKey    = "limits"
+	usageSizesKey     = "sizes"
+	usageIndexKey     = "index"
+	usageExpiryKey    = "expiry"
+	usageKeysKey      = "keys"
+	usageBytesKey     = "bytes"
+	usageEvictionsKey = "evictions"
+	usageRejectedKey  = "rejected"
+)
+
+// The fields of the limits stored in the usage of a table.
+const (
+	limitsMaxKeysField    = "max_keys"
+	limitsMaxBytesField   = "max_bytes"
+	limitsPolicyField     = "eviction_policy"
+	limitsDefaultTTLField = "default_ttl_ms"
+)
+
+// Limits bound the number of keys and the memory used by a table, a zero MaxKeys or MaxBytes is unbounded. Once a limit
+// is reached, keys are evicted following the EvictionPolicy to make room for the writes, or the writes are rejected
+// with ErrLimitExceeded. A non-zero DefaultTTL is set on the keys written without expiry.
+type Limits struct {
+	MaxKeys        int64
+	MaxBytes       int64
+	EvictionPolicy string
+	DefaultTTL     time.Duration
+}
+
+// Usage of a table, the evictions and the rejected writes are counted since the limits were set.
+type Usage struct {
+	Keys           int64
+	Bytes          int64
+	Evictions      int64
+	RejectedWrites int64
+}
+
+// tableLimits are the limits of a table loaded from the cache, nil if the table has no limits. The lock serializes the
+// writes of the table on this server so that its usage is tracked accurately.
+type tableLimits struct {
+	sync.Mutex
+
+	limits   *Limits
+	loadedAt time.Time
+}
+
+// usageTracker tracks the size, the expiry and the accesses of the keys of the tables with limits, and evicts them. The
+// redis cache does each step with a single script, see limits_redis.go, the other caches are tracked with the
+// operations of the Cache, see cacheUsageTracker.
+type usageTracker interface {
+	// prepareWrite makes room for the write of the key. The size is the estimated size of the data written, which
+	// replaces the current size of the key if replace is set or is added to it. It returns false, counting a rejected
+	// write, if the key doesn't fit.
+	prepareWrite(ctx context.Context, tableName string, limits *Limits, key string, size int64, replace bool) (bool, error)
+	// trackKeys records the size and the expiry of the keys, or stops tracking the ones which don't exist anymore. When
+	// the key has been written, the default TTL is set if it has no expiry, the access is recorded and keys are evicted
+	// again if its size was underestimated.
+	trackKeys(ctx context.Context, tableName string, limits *Limits, written bool, keys ...string) error
+	// touchKeys records the reads of the tracked keys, by the time of the last read for the LRU policy or by the number
+	// of reads for the LFU policy.
+	touchKeys(ctx context.Context, tableName string, limits *Limits, accesses map[string]float64) error
+	// usage stops tracking the keys which have expired and returns the usage of the table.
+	usage(ctx context.Context, tableName string, limits *Limits) (*Usage, error)
+}
+
+// LimitedCache enforces the limits of the tables on top of a Cache. The size and the expiry of the keys, the order in
+// which they are evicted and the usage of a table are tracked in the Cache itself, next to the table, so that all the
+// servers share them. Only the tables whose limits are set with SetLimits are tracked, the others are used as is
+// unless the config has default limits. The max keys and max bytes of the config cap the limits of every table.
+//
+// The writes of a table are serialized on a server but not across the servers, so the usage can drift slightly when a
+// key is written concurrently by several servers. The sizes written are estimated before the write and measured after,
+// so a write can exceed MaxBytes until the next one evicts keys. The reads are buffered and recorded in batches, so
+// they don't write to the Cache.
+type LimitedCache struct {
+	Cache
+
+	sync.Mutex
+	config   *config.CacheConfig
+	tracker  usageTracker
+	tables   map[string]*tableLimits
+	accesses map[string]map[string]float64
+}
+
+func NewLimitedCache(c Cache, cfg *config.CacheConfig) *LimitedCache {
+	tracker, ok := c.(usageTracker)
+	if !ok {
+		tracker = &cacheUsageTracker{Cache: c}
+	}
+
+	lc := &LimitedCache{
+		Cache:    c,
+		config:   cfg,
+		tracker:  tracker,
+		tables:   make(map[string]*tableLimits),
+		accesses: make(map[string]map[string]float64),
+	}
+
+	go lc.flushAccessesPeriodically()
+	return lc
+}
+
+// SetLimits sets the limits of the table and resets its usage, it is called when the table is created. The table isn't
+// tracked if the limits are nil.
+func (c *LimitedCache) SetLimits(ctx context.Context, tableName string, limits *Limits) error {
+	if err := c.DropLimits(ctx, tableName); err != nil || limits == nil {
+		return err
+	}
+
+	_, err := c.Cache.HSet(ctx, usageTableName(tableName), usageLimitsKey, map[string][]byte{
+		limitsMaxKeysField:    []byte(strconv.FormatInt(limits.MaxKeys, 10)),
+		limitsMaxBytesField:   []byte(strconv.FormatInt(limits.MaxBytes, 10)),
+		limitsPolicyField:     []byte(limits.EvictionPolicy),
+		limitsDefaultTTLField: []byte(strconv.FormatInt(limits.DefaultTTL.Milliseconds(), 10)),
+	})
+	return err
+}
+
+// DropLimits removes the limits and the usage of the table, it is called when the table is dropped.
+func (c *LimitedCache) DropLimits(ctx context.Context, tableName string) error {
+	c.Lock()
+	delete(c.tables, tableName)
+	delete(c.accesses, tableName)
+	c.Unlock()
+
+	_, err := c.Cache.Delete(ctx, usageTableName(tableName), usageLimitsKey, usageSizesKey, usageIndexKey,
+		usageExpiryKey, usageKeysKey, usageBytesKey, usageEvictionsKey, usageRejectedKey)
+	return err
+}
+
+// Usage returns the usage of the table, nil if the table has no limits. The keys which have expired are not counted.
+func (c *LimitedCache) Usage(ctx context.Context, tableName string) (*Usage, error) {
+	t, limits, err := c.getLimits(ctx, tableName)
+	if err != nil || limits == nil {
+		return nil, err
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	if err = c.flushAccesses(ctx, tableName, limits); err != nil {
+		return nil, err
+	}
+
+	return c.tracker.usage(ctx, tableName, limits)
+}
+
+func (c *LimitedCache) Set(ctx context.Context, tableName string, key string, value *internal.CacheData, options *SetOptions) error {
+	return c.write(ctx, tableName, key, estimateSize(key, value.RawData), true, func(limits *Limits) error {
+		if limits != nil && limits.DefaultTTL > 0 && (options == nil || (options.EX == 0 && options.PX == 0)) {
+			withTTL := SetOptions{PX: uint64(limits.DefaultTTL.Milliseconds())}
+			if options != nil {
+				withTTL.NX, withTTL.XX = options.NX, options.XX
+			}
+			options = &withTTL
+		}
+
+		return c.Cache.Set(ctx, tableName, key, value, options)
+	})
+}
+
+func (c *LimitedCache) GetSet(ctx context.Context, tableName string, key string, value *internal.CacheData) (*internal.CacheData, error) {
+	var old *internal.CacheData
+	err := c.write(ctx, tableName, key, estimateSize(key, value.RawData), true, func(_ *Limits) (err error) {
+		old, err = c.Cache.GetSet(ctx, tableName, key, value)
+		return
+	})
+
+	return old, err
+}
+
+func (c *LimitedCache) Get(ctx context.Context, tableName string, key string, options *GetOptions) (*internal.CacheData, error) {
+	var data *internal.CacheData
+	if options != nil && (options.GetDelete || options.Expiry > 0) {
+		err := c.update(ctx, tableName, []string{key}, func() (err error) {
+			data, err = c.Cache.Get(ctx, tableName, key, options)
+			return
+		})
+		return data, err
+	}
+
+	data, err := c.Cache.Get(ctx, tableName, key, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, c.accessed(ctx, tableName, key)
+}
+
+func (c *LimitedCache) Delete(ctx context.Context, tableName string, keys ...string) (int64, error) {
+	var deleted int64
+	err := c.update(ctx, tableName, keys, func() (err error) {
+		deleted, err = c.Cache.Delete(ctx, tableName, keys...)
+		return
+	})
+
+	return deleted, err
+}
+
+func (c *LimitedCache) IncrBy(ctx context.Context, tableName string, key string, increment int64) (int64, error) {
+	var value int64
+	err := c.write(ctx, tableName, key, estimateSize(key, nil), true, func(_ *Limits) (err error) {
+		value, err = c.Cache.IncrBy(ctx, tableName, key, increment)
+		return
+	})
+
+	return value, err
+}
+
+func (c *LimitedCache) Expire(ctx context.Context, tableName string, key string, ttl time.Duration) (bool, error) {
+	var exists bool
+	err := c.update(ctx, tableName, []string{key}, func() (err error) {
+		exists, err = c.Cache.Expire(ctx, tableName, key, ttl)
+		return
+	})
+
+	return exists, err
+}
+
+func (c *LimitedCache) Persist(ctx context.Context, tableName string, key string) (bool, error) {
+	var removed bool
+	err := c.update(ctx, tableName, []string{key}, func() (err error) {
+		removed, err = c.Cache.Persist(ctx, tableName, key)
+		return
+	})
+
+	return removed, err
+}
+
+func (c *LimitedCache) HSet(ctx context.Context, tableName string, key string, fields map[string][]byte) (int64, error) {
+	var size int64
+	for f, v := range fields {
+		size += estimateSize(f, v)
+	}
+
+	var added int64
+	err := c.write(ctx, tableName, key, size, false, func(_ *Limits) (err error) {
+		added, err = c.Cache.HSet(ctx, tableName, key, fields)
+		return
+	})
+
+	return added, err
+}
+
+func (c *LimitedCache) HGet(ctx context.Context, tableName string, key string, field string) ([]byte, error) {
+	value, err := c.Cache.HGet(ctx, tableName, key, field)
+	if err != nil {
+		return nil, err
+	}
+
+	return value, c.accessed(ctx, tableName, key)
+}
+
+func (c *LimitedCache) HGetAll(ctx context.Context, tableName string, key string) (map[string][]byte, error) {
+	fields, err := c.Cache.HGetAll(ctx, tableName, key)
+	if err != nil || len(fields) == 0 {
+		return fields, err
+	}
+
+	return fields, c.accessed(ctx, tableName, key)
+}
+
+func (c *LimitedCache) HDel(ctx context.Context, tableName string, key string, fields ...string) (int64, error) {
+	var removed int64
+	err := c.update(ctx, tableName, []string{key}, func() (err error) {
+		removed, err = c.Cache.HDel(ctx, tableName, key, fields...)
+		return
+	})
+
+	return removed, err
+}
+
+func (c *LimitedCache) HDelIfEqual(ctx context.Context, tableName string, key string, field string, value []byte) (bool, error) {
+	var removed bool
+	err := c.update(ctx, tableName, []string{key}, func() (err error) {
+		removed, err = c.Cache.HDelIfEqual(ctx, tableName, key, field, value)
+		return
+	})
+
+	return removed, err
+}
+
+func (c *LimitedCache) LPush(ctx context.Context, tableName string, key string, values ...[]byte) (int64, error) {
+	var length int64
+	err := c.write(ctx, tableName, key, estimateValuesSize(values), false, func(_ *Limits) (err error) {
+		length, err = c.Cache.LPush(ctx, tableName, key, values...)
+		return
+	})
+
+	return length, err
+}
+
+func (c *LimitedCache) RPush(ctx context.Context, tableName string, key string, values ...[]byte) (int64, error) {
+	var length int64
+	err := c.write(ctx, tableName, key, estimateValuesSize(values), false, func(_ *Limits) (err error) {
+		length, err = c.Cache.RPush(ctx, tableName, key, values...)
+		return
+	})
+
+	return length, err
+}
+
+func (c *LimitedCache) LPop(ctx context.Context, tableName string, key string, count int) ([][]byte, error) {
+	var values [][]byte
+	err := c.update(ctx, tableName, []string{key}, func() (err error) {
+		values, err = c.Cache.LPop(ctx, tableName, key, count)
+		return
+	})
+
+	return values, err
+}
+
+func (c *LimitedCache) RPop(ctx context.Context, tableName string, key string, count int) ([][]byte, error) {
+	var values [][]byte
+	err := c.update(ctx, tableName, []string{key}, func() (err error) {
+		values, err = c.Cache.RPop(ctx, tableName, key, count)
+		return
+	})
+
+	return values, err
+}
+
+func (c *LimitedCache) LRange(ctx context.Context, tableName string, key string, start int64, stop int64) ([][]byte, error) {
+	values, err := c.Cache.LRange(ctx, tableName, key, start, stop)
+	if err != nil || len(values) == 0 {
+		return values, err
+	}
+
+	return values, c.accessed(ctx, tableName, key)
+}
+
+func (c *LimitedCache) SAdd(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	var added int64
+	err := c.write(ctx, tableName, key, estimateValuesSize(members), false, func(_ *Limits) (err error) {
+		added, err = c.Cache.SAdd(ctx, tableName, key, members...)
+		return
+	})
+
+	return added, err
+}
+
+func (c *LimitedCache) SRem(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	var removed int64
+	err := c.update(ctx, tableName, []string{key}, func() (err error) {
+		removed, err = c.Cache.SRem(ctx, tableName, key, members...)
+		return
+	})
+
+	return removed, err
+}
+
+func (c *LimitedCache) SMembers(ctx context.Context, tableName string, key string) ([][]byte, error) {
+	members, err := c.Cache.SMembers(ctx, tableName, key)
+	if err != nil || len(members) == 0 {
+		return members, err
+	}
+
+	return members, c.accessed(ctx, tableName, key)
+}
+
+func (c *LimitedCache) SIsMember(ctx context.Context, tableName string, key string, member []byte) (bool, error) {
+	isMember, err := c.Cache.SIsMember(ctx, tableName, key, member)
+	if err != nil || !isMember {
+		return isMember, err
+	}
+
+	return isMember, c.accessed(ctx, tableName, key)
+}
+
+func (c *LimitedCache) ZAdd(ctx context.Context, tableName string, key string, members ...ZMember) (int64, error) {
+	var size int64
+	for _, m := range members {
+		size += estimateSize("", m.Member) + 8
+	}
+
+	var added int64
+	err := c.write(ctx, tableName, key, size, false, func(_ *Limits) (err error) {
+		added, err = c.Cache.ZAdd(ctx, tableName, key, members...)
+		return
+	})
+
+	return added, err
+}
+
+func (c *LimitedCache) ZIncrBy(ctx context.Context, tableName string, key string, increment float64, member []byte) (float64, error) {
+	var score float64
+	err := c.write(ctx, tableName, key, estimateSize("", member)+8, false, func(_ *Limits) (err error) {
+		score, err = c.Cache.ZIncrBy(ctx, tableName, key, increment, member)
+		return
+	})
+
+	return score, err
+}
+
+func (c *LimitedCache) ZRem(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	var removed int64
+	err := c.update(ctx, tableName, []string{key}, func() (err error) {
+		removed, err = c.Cache.ZRem(ctx, tableName, key, members...)
+		return
+	})
+
+	return removed, err
+}
+
+func (c *LimitedCache) ZRange(ctx context.Context, tableName string, key string, start int64, stop int64, rev bool) ([]ZMember, error) {
+	members, err := c.Cache.ZRange(ctx, tableName, key, start, stop, rev)
+	if err != nil || len(members) == 0 {
+		return members, err
+	}
+
+	return members, c.accessed(ctx, tableName, key)
+}
+
+func (c *LimitedCache) ZRangeByScore(ctx context.Context, tableName string, key string, min string, max string, offset int64, count int64) ([]ZMember, error) {
+	members, err := c.Cache.ZRangeByScore(ctx, tableName, key, min, max, offset, count)
+	if err != nil || len(members) == 0 {
+		return members, err
+	}
+
+	return members, c.accessed(ctx, tableName, key)
+}
+
+// write makes room for the write of the key, runs it and tracks the key. The size is the estimated size of the data
+// written, which replaces the current size of the key if replace is set or is added to it.
+func (c *LimitedCache) write(ctx context.Context, tableName string, key string, size int64, replace bool, fn func(limits *Limits) error) error {
+	t, limits, err := c.getLimits(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	if limits == nil {
+		return fn(nil)
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	// the keys are evicted in the order of the reads made so far
+	if err = c.flushAccesses(ctx, tableName, limits); err != nil {
+		return err
+	}
+
+	fits, err := c.tracker.prepareWrite(ctx, tableName, limits, key, size, replace)
+	if err != nil {
+		return err
+	}
+	if !fits {
+		return ErrLimitExceeded
+	}
+
+	if err = fn(limits); err != nil {
+		return err
+	}
+
+	return c.tracker.trackKeys(ctx, tableName, limits, true, key)
+}
+
+// update runs a write which doesn't grow the keys, like a removal or a change of the expiry, and tracks the keys.
+func (c *LimitedCache) update(ctx context.Context, tableName string, keys []string, fn func() error) error {
+	t, limits, err := c.getLimits(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	if limits == nil {
+		return fn()
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	if err = fn(); err != nil {
+		return err
+	}
+
+	return c.tracker.trackKeys(ctx, tableName, limits, false, keys...)
+}
+
+// accessed buffers a read of the key for the LRU and LFU policies.
+func (c *LimitedCache) accessed(ctx context.Context, tableName string, key string) error {
+	_, limits, err := c.getLimits(ctx, tableName)
+	if err != nil || limits == nil || (limits.EvictionPolicy != EvictLRU && limits.EvictionPolicy != EvictLFU) {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	accesses, ok := c.accesses[tableName]
+	if !ok {
+		accesses = make(map[string]float64)
+		c.accesses[tableName] = accesses
+	}
+	if limits.EvictionPolicy == EvictLRU {
+		accesses[key] = float64(time.Now().UnixMilli())
+	} else {
+		accesses[key]++
+	}
+
+	return nil
+}
+
+// flushAccesses records the reads of the table buffered so far.
+func (c *LimitedCache) flushAccesses(ctx context.Context, tableName string, limits *Limits) error {
+	c.Lock()
+	accesses := c.accesses[tableName]
+	delete(c.accesses, tableName)
+	c.Unlock()
+
+	if len(accesses) == 0 {
+		return nil
+	}
+
+	return c.tracker.touchKeys(ctx, tableName, limits, accesses)
+}
+
+func (c *LimitedCache) flushAccessesPeriodically() {
+	ticker := time.NewTicker(accessFlushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.Lock()
+		tableNames := make([]string, 0, len(c.accesses))
+		for tableName := range c.accesses {
+			tableNames = append(tableNames, tableName)
+		}
+		c.Unlock()
+
+		for _, tableName := range tableNames {
+			if err := c.flushTableAccesses(context.TODO(), tableName); err != nil {
+				log.Err(err).Str("table", tableName).Msg("recording the reads of the cache failed")
+			}
+		}
+	}
+}
+
+func (c *LimitedCache) flushTableAccesses(ctx context.Context, tableName string) error {
+	t, limits, err := c.getLimits(ctx, tableName)
+	if err != nil || limits == nil {
+		return err
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	return c.flushAccesses(ctx, tableName, limits)
+}
+
+func (c *LimitedCache) getLimits(ctx context.Context, tableName string) (*tableLimits, *Limits, error) {
+	c.Lock()
+	t, ok := c.tables[tableName]
+	if !ok {
+		t = &tableLimits{}
+		c.tables[tableName] = t
+	}
+	if time.Since(t.loadedAt) < limitsReloadInterval {
+		limits := t.limits
+		c.Unlock()
+		return t, limits, nil
+	}
+	c.Unlock()
+
+	limits, err := c.loadLimits(ctx, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	limits = c.boundLimits(limits)
+
+	c.Lock()
+	t.limits, t.loadedAt = limits, time.Now()
+	c.Unlock()
+
+	return t, limits, nil
+}
+
+func (c *LimitedCache) loadLimits(ctx context.Context, tableName string) (*Limits, error) {
+	fields, err := c.Cache.HGetAll(ctx, usageTableName(tableName), usageLimitsKey)
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+
+	limits := &Limits{
+		EvictionPolicy: string(fields[limitsPolicyField]),
+	}
+	if limits.MaxKeys, err = parseCounter(fields[limitsMaxKeysField]); err != nil {
+		return nil, err
+	}
+	if limits.MaxBytes, err = parseCounter(fields[limitsMaxBytesField]); err != nil {
+		return nil, err
+	}
+
+	ttl, err := parseCounter(fields[limitsDefaultTTLField])
+	if err != nil {
+		return nil, err
+	}
+	limits.DefaultTTL = time.Duration(ttl) * time.Millisecond
+
+	return limits, nil
+}
+
+// boundLimits applies the limits of the config to the limits of a table, the default limits if the table has none and
+// the max limits otherwise.
+func (c *LimitedCache) boundLimits(limits *Limits) *Limits {
+	var bounded Limits
+	switch {
+	case limits != nil:
+		bounded = *limits
+	case c.config.DefaultMaxKeys > 0 || c.config.DefaultMaxBytes > 0:
+		bounded = Limits{
+			MaxKeys:        c.config.DefaultMaxKeys,
+			MaxBytes:       c.config.DefaultMaxBytes,
+			EvictionPolicy: EvictLRU,
+		}
+	default:
+		return nil
+	}
+
+	bounded.MaxKeys = capLimit(bounded.MaxKeys, c.config.MaxKeys)
+	bounded.MaxBytes = capLimit(bounded.MaxBytes, c.config.MaxBytes)
+	if bounded.EvictionPolicy == "" && (bounded.MaxKeys > 0 || bounded.MaxBytes > 0) {
+		bounded.EvictionPolicy = EvictLRU
+	}
+
+	return &bounded
+}
+
+// capLimit returns the limit capped to max, a zero limit is unbounded and a zero max is uncapped.
+func capLimit(limit int64, max int64) int64 {
+	if max > 0 && (limit == 0 || limit > max) {
+		return max
+	}
+	return limit
+}
+
+// cacheUsageTracker tracks the usage of the tables with the operations of the Cache.
+type cacheUsageTracker struct {
+	Cache
+}
+
+func (c *cacheUsageTracker) prepareWrite(ctx context.Context, tableName string, limits *Limits, key string, size int64, replace bool) (bool, error) {
+	current, tracked, err := c.trackedSize(ctx, tableName, key)
+	if err != nil {
+		return false, err
+	}
+
+	after := size
+	if !replace {
+		after += current
+	}
+	if limits.MaxBytes > 0 && after > limits.MaxBytes {
+		return false, c.reject(ctx, tableName)
+	}
+
+	fits, err := c.evict(ctx, tableName, limits, key, func(usage *Usage) bool {
+		return (limits.MaxKeys > 0 && !tracked && usage.Keys >= limits.MaxKeys) ||
+			(limits.MaxBytes > 0 && usage.Bytes-current+after > limits.MaxBytes)
+	})
+	if err != nil || fits {
+		return fits, err
+	}
+
+	return false, c.reject(ctx, tableName)
+}
+
+func (c *cacheUsageTracker) trackKeys(ctx context.Context, tableName string, limits *Limits, written bool, keys ...string) error {
+	for _, key := range keys {
+		if err := c.track(ctx, tableName, limits, key, written); err != nil {
+			return err
+		}
+	}
+	if !written || len(keys) == 0 {
+		return nil
+	}
+
+	// the size of the key is only known once written, keys are evicted again if it was underestimated
+	_, err := c.evict(ctx, tableName, limits, keys[0], func(usage *Usage) bool {
+		return limits.MaxBytes > 0 && usage.Bytes > limits.MaxBytes
+	})
+	return err
+}
+
+func (c *cacheUsageTracker) touchKeys(ctx context.Context, tableName string, limits *Limits, accesses map[string]float64) error {
+	for key, value := range accesses {
+		// a key removed since it was read isn't tracked anymore
+		if _, tracked, err := c.trackedSize(ctx, tableName, key); err != nil || !tracked {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := c.touch(ctx, tableName, limits, key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cacheUsageTracker) usage(ctx context.Context, tableName string, limits *Limits) (*Usage, error) {
+	if err := c.purgeExpired(ctx, tableName, limits); err != nil {
+		return nil, err
+	}
+
+	return c.readUsage(ctx, tableName)
+}
+
+// evict evicts the keys of the table, other than the key being written, following the eviction policy while needed
+// returns true. It returns false if the usage is still above the limits once there is nothing left to evict.
+func (c *cacheUsageTracker) evict(ctx context.Context, tableName string, limits *Limits, key string, needed func(*Usage) bool) (bool, error) {
+	usage, err := c.readUsage(ctx, tableName)
+	if err != nil {
+		return false, err
+	}
+	if !needed(usage) {
+		return true, nil
+	}
+
+	// the keys which have expired are dropped first, they don't count as evictions
+	if err = c.purgeExpired(ctx, tableName, limits); err != nil {
+		return false, err
+	}
+
+	for {
+		if usage, err = c.readUsage(ctx, tableName); err != nil {
+			return false, err
+		}
+		if !needed(usage) {
+			return true, nil
+		}
+		if limits.EvictionPolicy == EvictNone {
+			return false, nil
+		}
+
+		victim, err := c.victim(ctx, tableName, limits, key)
+		if err != nil {
+			return false, err
+		}
+		if len(victim) == 0 {
+			return false, nil
+		}
+
+		if _, err = c.Cache.Delete(ctx, tableName, victim); err != nil {
+			return false, err
+		}
+		if err = c.untrack(ctx, tableName, victim); err != nil {
+			return false, err
+		}
+		if _, err = c.Cache.IncrBy(ctx, usageTableName(tableName), usageEvictionsKey, 1); err != nil {
+			return false, err
+		}
+	}
+}
+
+// victim returns the next key to evict, an empty key if there is none.
+func (c *cacheUsageTracker) victim(ctx context.Context, tableName string, limits *Limits, key string) (string, error) {
+	index := usageIndexKey
+	if limits.EvictionPolicy == EvictTTL {
+		index = usageExpiryKey
+	}
+
+	for {
+		// the two first keys are enough to skip the key being written
+		members, err := c.Cache.ZRange(ctx, usageTableName(tableName), index, 0, 1, false)
+		if err != nil {
+			return "", err
+		}
+
+		var candidate string
+		for _, m := range members {
+			if string(m.Member) != key {
+				candidate = string(m.Member)
+				break
+			}
+		}
+		if len(candidate) == 0 {
+			return "", nil
+		}
+
+		_, tracked, err := c.trackedSize(ctx, tableName, candidate)
+		if err != nil {
+			return "", err
+		}
+		if tracked {
+			return candidate, nil
+		}
+
+		// a read of a key being removed can leave it in the index
+		if _, err = c.Cache.ZRem(ctx, usageTableName(tableName), index, []byte(candidate)); err != nil {
+			return "", err
+		}
+	}
+}
+
+// purgeExpired stops tracking the keys which have expired.
+func (c *cacheUsageTracker) purgeExpired(ctx context.Context, tableName string, limits *Limits) error {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	expired, err := c.Cache.ZRangeByScore(ctx, usageTableName(tableName), usageExpiryKey, "-inf", now, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range expired {
+		if err = c.track(ctx, tableName, limits, string(m.Member), false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// track records the size and the expiry of the key, or stops tracking it if it doesn't exist anymore. When the key has
+// been written, the default TTL is set if it has no expiry and the access is recorded.
+func (c *cacheUsageTracker) track(ctx context.Context, tableName string, limits *Limits, key string, written bool) error {
+	size, err := c.Cache.MemoryUsage(ctx, tableName, key)
+	if err == ErrKeyNotFound {
+		return c.untrack(ctx, tableName, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	ttl, err := c.Cache.TTL(ctx, tableName, key)
+	if err == ErrKeyNotFound {
+		return c.untrack(ctx, tableName, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	if written && ttl == NoExpiry && limits.DefaultTTL > 0 {
+		if _, err = c.Cache.Expire(ctx, tableName, key, limits.DefaultTTL); err != nil {
+			return err
+		}
+		ttl = limits.DefaultTTL
+	}
+
+	current, tracked, err := c.trackedSize(ctx, tableName, key)
+	if err != nil {
+		return err
+	}
+
+	usageTable := usageTableName(tableName)
+	if _, err = c.Cache.HSet(ctx, usageTable, usageSizesKey, map[string][]byte{key: []byte(strconv.FormatInt(size, 10))}); err != nil {
+		return err
+	}
+	if !tracked {
+		if _, err = c.Cache.IncrBy(ctx, usageTable, usageKeysKey, 1); err != nil {
+			return err
+		}
+	}
+	if size != current {
+		if _, err = c.Cache.IncrBy(ctx, usageTable, usageBytesKey, size-current); err != nil {
+			return err
+		}
+	}
+
+	if ttl == NoExpiry {
+		_, err = c.Cache.ZRem(ctx, usageTable, usageExpiryKey, []byte(key))
+	} else {
+		_, err = c.Cache.ZAdd(ctx, usageTable, usageExpiryKey, ZMember{
+			Score:  float64(time.Now().Add(ttl).UnixMilli()),
+			Member: []byte(key),
+		})
+	}
+	if err != nil || !written {
+		return err
+	}
+
+	return c.touch(ctx, tableName, limits, key, writeAccess(limits))
+}
+
+func (c *cacheUsageTracker) untrack(ctx context.Context, tableName string, key string) error {
+	current, tracked, err := c.trackedSize(ctx, tableName, key)
+	if err != nil {
+		return err
+	}
+
+	usageTable := usageTableName(tableName)
+	if tracked {
+		if _, err = c.Cache.HDel(ctx, usageTable, usageSizesKey, key); err != nil {
+			return err
+		}
+		if _, err = c.Cache.IncrBy(ctx, usageTable, usageKeysKey, -1); err != nil {
+			return err
+		}
+		if _, err = c.Cache.IncrBy(ctx, usageTable, usageBytesKey, -current); err != nil {
+			return err
+		}
+	}
+
+	if _, err = c.Cache.ZRem(ctx, usageTable, usageIndexKey, []byte(key)); err != nil {
+		return err
+	}
+	_, err = c.Cache.ZRem(ctx, usageTable, usageExpiryKey, []byte(key))
+	return err
+}
+
+// touch records accesses of the key, the time of the last access for the LRU policy or the number of accesses to add
+// for the LFU policy.
+func (c *cacheUsageTracker) touch(ctx context.Context, tableName string, limits *Limits, key string, value float64) error {
+	var err error
+	switch limits.EvictionPolicy {
+	case EvictLRU:
+		_, err = c.Cache.ZAdd(ctx, usageTableName(tableName), usageIndexKey, ZMember{
+			Score:  value,
+			Member: []byte(key),
+		})
+	case EvictLFU:
+		_, err = c.Cache.ZIncrBy(ctx, usageTableName(tableName), usageIndexKey, value, []byte(key))
+	}
+
+	return err
+}
+
+// reject counts a rejected write.
+func (c *cacheUsageTracker) reject(ctx context.Context, tableName string) error {
+	_, err := c.Cache.IncrBy(ctx, usageTableName(tableName), usageRejectedKey, 1)
+	return err
+}
+
+// trackedSize returns the size of the key recorded by the last write, false if the key isn't tracked.
+func (c *cacheUsageTracker) trackedSize(ctx context.Context, tableName string, key string) (int64, bool, error) {
+	value, err := c.Cache.HGet(ctx, usageTableName(tableName), usageSizesKey, key)
+	if err == ErrKeyNotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	size, err := parseCounter(value)
+	return size, err == nil, err
+}
+
+func (c *cacheUsageTracker) readUsage(ctx context.Context, tableName string) (*Usage, error) {
+	usage := &Usage{}
+	for _, counter := range []struct {
+		key   string
+		value *int64
+	}{
+		{usageKeysKey, &usage.Keys},
+		{usageBytesKey, &usage.Bytes},
+		{usageEvictionsKey, &usage.Evictions},
+		{usageRejectedKey, &usage.RejectedWrites},
+	} {
+		data, err := c.Cache.Get(ctx, usageTableName(tableName), counter.key, nil)
+		if err == ErrKeyNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if *counter.value, err = parseCounter(data.RawData); err != nil {
+			return nil, err
+		}
+	}
+
+	return usage, nil
+}
+
+// writeAccess returns the access recorded for a write, see usageTracker.touchKeys.
+func writeAccess(limits *Limits) float64 {
+	if limits.EvictionPolicy == EvictLFU {
+		return 1
+	}
+
+	return float64(time.Now().UnixMilli())
+}
+
+func usageTableName(tableName string) string {
+	return usagePrefix + tableName
+}
+
+// estimateSize returns the estimated size of a key or a field and its value before it is written.
+func estimateSize(key string, value []byte) int64 {
+	return int64(len(key) + len(value) + memoryOverhead)
+}
+
+func estimateValuesSize(values [][]byte) int64 {
+	var size int64
+	for _, v := range values {
+		size += estimateSize("", v)
+	}
+
+	return size
+}
@@ -0,0 +1,285 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	xredis "github.com/go-redis/redis/v8"
+)
+
+// usageScriptLib is prepended to the scripts tracking the usage of a table. KEYS are the keys of the usage of the
+// table, in the order of usageScriptKeys. ARGV[1] is the prefix of the keys of the table, ARGV[2] to ARGV[5] are the
+// limits and ARGV[6] is the current time in milliseconds, the arguments of the script follow. The keys of the table are
+// accessed by their name, so the table and its usage must be on the same redis node.
+const usageScriptLib = `
+local sizesKey, indexKey, expiryKey, keysKey, bytesKey, evictionsKey, rejectedKey =
+	KEYS[1], KEYS[2], KEYS[3], KEYS[4], KEYS[5], KEYS[6], KEYS[7]
+local prefix = ARGV[1]
+local maxKeys, maxBytes, policy, defaultTTL = tonumber(ARGV[2]), tonumber(ARGV[3]), ARGV[4], tonumber(ARGV[5])
+local now = tonumber(ARGV[6])
+
+local function counter(key)
+	return tonumber(redis.call('GET', key) or '0')
+end
+
+local function touch(key, value)
+	if policy == 'lru' then
+		redis.call('ZADD', indexKey, value, key)
+	elseif policy == 'lfu' then
+		redis.call('ZINCRBY', indexKey, value, key)
+	end
+end
+
+local function untrack(key)
+	local current = redis.call('HGET', sizesKey, key)
+	if current then
+		redis.call('HDEL', sizesKey, key)
+		redis.call('DECRBY', keysKey, 1)
+		redis.call('DECRBY', bytesKey, tonumber(current))
+	end
+	redis.call('ZREM', indexKey, key)
+	redis.call('ZREM', expiryKey, key)
+end
+
+local function track(key, written)
+	local dataKey = prefix .. key
+	local ttl = redis.call('PTTL', dataKey)
+	if ttl == -2 then
+		untrack(key)
+		return
+	end
+	if written and ttl == -1 and defaultTTL > 0 then
+		redis.call('PEXPIRE', dataKey, defaultTTL)
+		ttl = defaultTTL
+	end
+
+	local size = redis.call('MEMORY', 'USAGE', dataKey) or 0
+	local current = redis.call('HGET', sizesKey, key)
+	redis.call('HSET', sizesKey, key, size)
+	if not current then
+		redis.call('INCRBY', keysKey, 1)
+	end
+	local delta = size - tonumber(current or '0')
+	if delta ~= 0 then
+		redis.call('INCRBY', bytesKey, delta)
+	end
+
+	if ttl == -1 then
+		redis.call('ZREM', expiryKey, key)
+	else
+		redis.call('ZADD', expiryKey, now + ttl, key)
+	end
+	if written then
+		if policy == 'lfu' then
+			touch(key, 1)
+		else
+			touch(key, now)
+		end
+	end
+end
+
+local function purgeExpired()
+	for _, key in ipairs(redis.call('ZRANGEBYSCORE', expiryKey, '-inf', now)) do
+		track(key, false)
+	end
+end
+
+-- victim returns the next key to evict other than the key being written, nil if there is none
+local function victim(key)
+	local index = indexKey
+	if policy == 'ttl' then
+		index = expiryKey
+	end
+
+	while true do
+		local candidate
+		for _, member in ipairs(redis.call('ZRANGE', index, 0, 1)) do
+			if member ~= key then
+				candidate = member
+				break
+			end
+		end
+		if not candidate or redis.call('HEXISTS', sizesKey, candidate) == 1 then
+			return candidate
+		end
+		redis.call('ZREM', index, candidate)
+	end
+end
+
+-- evict evicts keys while needed returns true, it returns false if there is nothing left to evict
+local function evict(key, needed)
+	if not needed() then
+		return true
+	end
+
+	-- the keys which have expired are dropped first, they don't count as evictions
+	purgeExpired()
+	while needed() do
+		if policy == 'reject' then
+			return false
+		end
+
+		local candidate = victim(key)
+		if not candidate then
+			return false
+		end
+		redis.call('DEL', prefix .. candidate)
+		untrack(candidate)
+		redis.call('INCRBY', evictionsKey, 1)
+	end
+	return true
+end
+`
+
+// prepareWriteScript makes room for the write of the key ARGV[7] of size ARGV[8], which replaces the current size of
+// the key if ARGV[9] is 1 or is added to it. It returns 0 if the key doesn't fit.
+var prepareWriteScript = xredis.NewScript(usageScriptLib + `
+local key, size = ARGV[7], tonumber(ARGV[8])
+local current = redis.call('HGET', sizesKey, key)
+local tracked = current ~= false
+current = tonumber(current or '0')
+
+local after = size
+if ARGV[9] ~= '1' then
+	after = after + current
+end
+
+local fits = not (maxBytes > 0 and after > maxBytes) and evict(key, function()
+	return (maxKeys > 0 and not tracked and counter(keysKey) >= maxKeys) or
+		(maxBytes > 0 and counter(bytesKey) - current + after > maxBytes)
+end)
+if not fits then
+	redis.call('INCRBY', rejectedKey, 1)
+	return 0
+end
+return 1
+`)
+
+// trackKeysScript tracks the keys ARGV[8] and after, which have been written if ARGV[7] is 1.
+var trackKeysScript = xredis.NewScript(usageScriptLib + `
+local written = ARGV[7] == '1'
+for i = 8, #ARGV do
+	track(ARGV[i], written)
+end
+
+-- the size of the key is only known once written, keys are evicted again if it was underestimated
+if written and #ARGV >= 8 then
+	evict(ARGV[8], function()
+		return maxBytes > 0 and counter(bytesKey) > maxBytes
+	end)
+end
+return 1
+`)
+
+// touchKeysScript records the accesses of the tracked keys, ARGV[7] and after are pairs of a key and its access.
+var touchKeysScript = xredis.NewScript(usageScriptLib + `
+for i = 7, #ARGV, 2 do
+	if redis.call('HEXISTS', sizesKey, ARGV[i]) == 1 then
+		touch(ARGV[i], tonumber(ARGV[i + 1]))
+	end
+end
+return 1
+`)
+
+// usageScript returns the keys, the bytes, the evictions and the rejected writes of the table once the keys which have
+// expired are not tracked anymore.
+var usageScript = xredis.NewScript(usageScriptLib + `
+purgeExpired()
+return {counter(keysKey), counter(bytesKey), counter(evictionsKey), counter(rejectedKey)}
+`)
+
+func (c *cache) prepareWrite(ctx context.Context, tableName string, limits *Limits, key string, size int64, replace bool) (bool, error) {
+	replaceArg := 0
+	if replace {
+		replaceArg = 1
+	}
+
+	fits, err := c.runUsageScript(ctx, prepareWriteScript, tableName, limits, key, size, replaceArg).Int64()
+	return fits == 1, err
+}
+
+func (c *cache) trackKeys(ctx context.Context, tableName string, limits *Limits, written bool, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(keys)+1)
+	if written {
+		args = append(args, 1)
+	} else {
+		args = append(args, 0)
+	}
+	for _, key := range keys {
+		args = append(args, key)
+	}
+
+	return c.runUsageScript(ctx, trackKeysScript, tableName, limits, args...).Err()
+}
+
+func (c *cache) touchKeys(ctx context.Context, tableName string, limits *Limits, accesses map[string]float64) error {
+	args := make([]any, 0, 2*len(accesses))
+	for key, value := range accesses {
+		args = append(args, key, value)
+	}
+
+	return c.runUsageScript(ctx, touchKeysScript, tableName, limits, args...).Err()
+}
+
+func (c *cache) usage(ctx context.Context, tableName string, limits *Limits) (*Usage, error) {
+	counters, err := c.runUsageScript(ctx, usageScript, tableName, limits).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(counters) != 4 {
+		return nil, ErrNotInteger
+	}
+
+	return &Usage{
+		Keys:           counters[0],
+		Bytes:          counters[1],
+		Evictions:      counters[2],
+		RejectedWrites: counters[3],
+	}, nil
+}
+
+func (c *cache) runUsageScript(ctx context.Context, script *xredis.Script, tableName string, limits *Limits, args ...any) *xredis.Cmd {
+	allArgs := append([]any{
+		encodeToCacheKey(tableName, ""),
+		limits.MaxKeys,
+		limits.MaxBytes,
+		limits.EvictionPolicy,
+		limits.DefaultTTL.Milliseconds(),
+		time.Now().UnixMilli(),
+	}, args...)
+
+	return script.Run(ctx, c.Client, usageScriptKeys(tableName), allArgs...)
+}
+
+// usageScriptKeys returns the keys of the usage of the table passed to the scripts.
+func usageScriptKeys(tableName string) []string {
+	usageTable := usageTableName(tableName)
+
+	return []string{
+		encodeToCacheKey(usageTable, usageSizesKey),
+		encodeToCacheKey(usageTable, usageIndexKey),
+		encodeToCacheKey(usageTable, usageExpiryKey),
+		encodeToCacheKey(usageTable, usageKeysKey),
+		encodeToCacheKey(usageTable, usageBytesKey),
+		encodeToCacheKey(usageTable, usageEvictionsKey),
+		encodeToCacheKey(usageTable, usageRejectedKey),
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+func TestRedisLimits(t *testing.T) {
+	testLimits(t, newCache(config.GetTestCacheConfig()))
+}
+
+func TestMemoryLimits(t *testing.T) {
+	testLimits(t, newMemoryCache())
+}
+
+func testLimits(t *testing.T, c Cache) {
+	ctx := context.TODO()
+	tableName := "cache_limits_test"
+	lc := NewLimitedCache(c, &config.CacheConfig{})
+
+	setLimits := func(t *testing.T, limits *Limits) {
+		require.NoError(t, lc.SetLimits(ctx, tableName, limits))
+		t.Cleanup(func() {
+			dropCacheTable(t, c, tableName)
+			require.NoError(t, lc.DropLimits(ctx, tableName))
+		})
+	}
+
+	set := func(key string, value string) error {
+		return lc.Set(ctx, tableName, key, internal.NewCacheData([]byte(value)), &SetOptions{})
+	}
+
+	exists := func(t *testing.T, key string) bool {
+		n, err := lc.Exists(ctx, tableName, key)
+		require.NoError(t, err)
+		return n == 1
+	}
+
+	usage := func(t *testing.T) *Usage {
+		u, err := lc.Usage(ctx, tableName)
+		require.NoError(t, err)
+		return u
+	}
+
+	t.Run("no_limits", func(t *testing.T) {
+		setLimits(t, &Limits{MaxKeys: 2, EvictionPolicy: EvictLRU})
+		setLimits(t, nil)
+
+		require.NoError(t, set("k1", "v1"))
+		u, err := lc.Usage(ctx, tableName)
+		require.NoError(t, err)
+		require.Nil(t, u)
+	})
+
+	t.Run("lru", func(t *testing.T) {
+		setLimits(t, &Limits{MaxKeys: 2, EvictionPolicy: EvictLRU})
+
+		require.NoError(t, set("k1", "v1"))
+		time.Sleep(2 * time.Millisecond)
+		require.NoError(t, set("k2", "v2"))
+		time.Sleep(2 * time.Millisecond)
+		_, err := lc.Get(ctx, tableName, "k1", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, set("k3", "v3"))
+		require.True(t, exists(t, "k1"))
+		require.False(t, exists(t, "k2"))
+		require.True(t, exists(t, "k3"))
+
+		u := usage(t)
+		require.Equal(t, int64(2), u.Keys)
+		require.Equal(t, int64(1), u.Evictions)
+		require.Greater(t, u.Bytes, int64(0))
+
+		// overwriting a key doesn't evict anything
+		require.NoError(t, set("k3", "v4"))
+		require.Equal(t, int64(1), usage(t).Evictions)
+	})
+
+	t.Run("lfu", func(t *testing.T) {
+		setLimits(t, &Limits{MaxKeys: 2, EvictionPolicy: EvictLFU})
+
+		require.NoError(t, set("k1", "v1"))
+		require.NoError(t, set("k2", "v2"))
+		for i := 0; i < 2; i++ {
+			_, err := lc.Get(ctx, tableName, "k1", nil)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, set("k3", "v3"))
+		require.True(t, exists(t, "k1"))
+		require.False(t, exists(t, "k2"))
+		require.True(t, exists(t, "k3"))
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		setLimits(t, &Limits{MaxKeys: 2, EvictionPolicy: EvictTTL})
+
+		require.NoError(t, set("k1", "v1"))
+		require.NoError(t, lc.Set(ctx, tableName, "k2", internal.NewCacheData([]byte("v2")), &SetOptions{EX: 100}))
+
+		require.NoError(t, set("k3", "v3"))
+		require.True(t, exists(t, "k1"))
+		require.False(t, exists(t, "k2"))
+
+		// only the keys with an expiry are evicted
+		require.Equal(t, ErrLimitExceeded, set("k4", "v4"))
+		require.Equal(t, int64(1), usage(t).RejectedWrites)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		setLimits(t, &Limits{MaxKeys: 1, EvictionPolicy: EvictNone})
+
+		require.NoError(t, set("k1", "v1"))
+		require.Equal(t, ErrLimitExceeded, set("k2", "v2"))
+		_, err := lc.HSet(ctx, tableName, "h1", map[string][]byte{"f": []byte("v")})
+		require.Equal(t, ErrLimitExceeded, err)
+		require.NoError(t, set("k1", "v2"))
+
+		_, err = lc.Delete(ctx, tableName, "k1")
+		require.NoError(t, err)
+		require.NoError(t, set("k2", "v2"))
+
+		u := usage(t)
+		require.Equal(t, int64(1), u.Keys)
+		require.Equal(t, int64(0), u.Evictions)
+		require.Equal(t, int64(2), u.RejectedWrites)
+	})
+
+	t.Run("max_bytes", func(t *testing.T) {
+		setLimits(t, &Limits{MaxBytes: 1024, EvictionPolicy: EvictLRU})
+
+		value := string(make([]byte, 200))
+		for i := 0; i < 20; i++ {
+			require.NoError(t, set(fmt.Sprintf("k%d", i), value))
+			require.LessOrEqual(t, usage(t).Bytes, int64(1024))
+		}
+		require.True(t, exists(t, "k19"))
+		require.False(t, exists(t, "k0"))
+		require.Greater(t, usage(t).Evictions, int64(0))
+
+		// a value larger than the limit is rejected without evicting anything
+		require.Equal(t, ErrLimitExceeded, set("large", string(make([]byte, 2048))))
+		require.True(t, exists(t, "k19"))
+	})
+
+	t.Run("collections", func(t *testing.T) {
+		setLimits(t, &Limits{MaxKeys: 10, EvictionPolicy: EvictLRU})
+
+		_, err := lc.HSet(ctx, tableName, "h1", map[string][]byte{"f1": []byte("v1")})
+		require.NoError(t, err)
+		_, err = lc.RPush(ctx, tableName, "l1", []byte("v1"), []byte("v2"))
+		require.NoError(t, err)
+		_, err = lc.SAdd(ctx, tableName, "s1", []byte("m1"))
+		require.NoError(t, err)
+		_, err = lc.ZAdd(ctx, tableName, "z1", ZMember{Score: 1, Member: []byte("m1")})
+		require.NoError(t, err)
+		_, err = lc.IncrBy(ctx, tableName, "c1", 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), usage(t).Keys)
+
+		before := usage(t).Bytes
+		_, err = lc.LPop(ctx, tableName, "l1", 1)
+		require.NoError(t, err)
+		require.Less(t, usage(t).Bytes, before)
+
+		// removing the last element removes the key
+		_, err = lc.SRem(ctx, tableName, "s1", []byte("m1"))
+		require.NoError(t, err)
+		_, err = lc.HDel(ctx, tableName, "h1", "f1")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), usage(t).Keys)
+
+		_, err = lc.Delete(ctx, tableName, "l1", "z1", "c1")
+		require.NoError(t, err)
+		u := usage(t)
+		require.Equal(t, int64(0), u.Keys)
+		require.Equal(t, int64(0), u.Bytes)
+	})
+
+	t.Run("default_ttl", func(t *testing.T) {
+		setLimits(t, &Limits{DefaultTTL: time.Minute, EvictionPolicy: EvictLRU})
+
+		require.NoError(t, set("k1", "v1"))
+		ttl, err := lc.TTL(ctx, tableName, "k1")
+		require.NoError(t, err)
+		require.Greater(t, ttl, time.Duration(0))
+
+		require.NoError(t, lc.Set(ctx, tableName, "k2", internal.NewCacheData([]byte("v2")), &SetOptions{EX: 3600}))
+		ttl, err = lc.TTL(ctx, tableName, "k2")
+		require.NoError(t, err)
+		require.Greater(t, ttl, time.Minute)
+
+		_, err = lc.SAdd(ctx, tableName, "s1", []byte("m1"))
+		require.NoError(t, err)
+		ttl, err = lc.TTL(ctx, tableName, "s1")
+		require.NoError(t, err)
+		require.Greater(t, ttl, time.Duration(0))
+
+		// an explicit persist is kept
+		_, err = lc.Persist(ctx, tableName, "k1")
+		require.NoError(t, err)
+		ttl, err = lc.TTL(ctx, tableName, "k1")
+		require.NoError(t, err)
+		require.Equal(t, NoExpiry, ttl)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		setLimits(t, &Limits{MaxKeys: 2, EvictionPolicy: EvictLRU})
+
+		require.NoError(t, lc.Set(ctx, tableName, "k1", internal.NewCacheData([]byte("v1")), &SetOptions{PX: 10}))
+		require.NoError(t, set("k2", "v2"))
+		time.Sleep(20 * time.Millisecond)
+
+		// the expired key makes room without being evicted
+		require.NoError(t, set("k3", "v3"))
+		require.True(t, exists(t, "k2"))
+
+		u := usage(t)
+		require.Equal(t, int64(2), u.Keys)
+		require.Equal(t, int64(0), u.Evictions)
+	})
+	t.Run("config_limits", func(t *testing.T) {
+		bounded := NewLimitedCache(c, &config.CacheConfig{DefaultMaxKeys: 2, MaxKeys: 3})
+		setBounded := func(key string) error {
+			return bounded.Set(ctx, tableName, key, internal.NewCacheData([]byte("v")), &SetOptions{})
+		}
+
+		// the table without limits gets the default ones
+		setLimits(t, nil)
+		for _, key := range []string{"k1", "k2", "k3"} {
+			require.NoError(t, setBounded(key))
+		}
+		require.False(t, exists(t, "k1"))
+		u, err := bounded.Usage(ctx, tableName)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), u.Keys)
+		require.Equal(t, int64(1), u.Evictions)
+
+		// the limits of the table are capped
+		setLimits(t, &Limits{MaxKeys: 10, EvictionPolicy: EvictNone})
+		bounded = NewLimitedCache(c, &config.CacheConfig{DefaultMaxKeys: 2, MaxKeys: 3})
+		for _, key := range []string{"k1", "k2", "k3"} {
+			require.NoError(t, setBounded(key))
+		}
+		require.Equal(t, ErrLimitExceeded, setBounded("k4"))
+	})
+}
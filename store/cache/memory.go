@@ -0,0 +1,1076 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/server/config"
+)
+
+// memorySweepInterval is how often the expired keys which are not accessed anymore are removed.
+const memorySweepInterval = time.Minute
+
+// memoryOverhead is the estimated overhead of a key and of each element of its value, returned by MemoryUsage on top
+// of the size of the data.
+const memoryOverhead = 16
+
+var (
+	sharedMemoryCache     *memoryCache
+	sharedMemoryCacheOnce sync.Once
+)
+
+// memoryEntry is a key of the in-memory cache. The value is the encoded CacheData of the keys set with Set, or a
+// hash, a list, a set, a sorted set or a stream.
+type memoryEntry struct {
+	value    any
+	expireAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+type (
+	memoryHash    map[string][]byte
+	memorySet     map[string]struct{}
+	memorySortSet map[string]float64
+)
+
+type memoryList struct {
+	values [][]byte
+}
+
+// memoryCache is the in-process implementation of the Cache, the keys and the streams share the same key space like
+// they do in Redis. It is used when no Redis server is available, for a single server or the tests.
+type memoryCache struct {
+	sync.Mutex
+
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+	// added is closed and replaced every time a message is added to a stream to wake up the blocked readers.
+	added chan struct{}
+}
+
+// getSharedMemoryCache returns the in-memory cache of the process, all the services use the same one so that the
+// messages published by one of them are seen by the others.
+func getSharedMemoryCache() *memoryCache {
+	sharedMemoryCacheOnce.Do(func() {
+		sharedMemoryCache = newMemoryCache()
+	})
+
+	return sharedMemoryCache
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{
+		entries:   make(map[string]*memoryEntry),
+		lastSweep: time.Now(),
+		added:     make(chan struct{}),
+	}
+}
+
+// get returns the entry of the key if it exists and hasn't expired, the caller holds the lock.
+func (m *memoryCache) get(key string) *memoryEntry {
+	e, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if e.expired(time.Now()) {
+		delete(m.entries, key)
+		return nil
+	}
+
+	return e
+}
+
+// put stores the value, the expiry is reset like a write in Redis. The expired keys are swept from time to time.
+func (m *memoryCache) put(key string, value any, expireAt time.Time) {
+	m.entries[key] = &memoryEntry{value: value, expireAt: expireAt}
+
+	if now := time.Now(); now.Sub(m.lastSweep) > memorySweepInterval {
+		for k, e := range m.entries {
+			if e.expired(now) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastSweep = now
+	}
+}
+
+// keys returns the sorted keys matching the pattern, the caller holds the lock.
+func (m *memoryCache) keys(pattern string) []string {
+	now := time.Now()
+
+	var keys []string
+	for k, e := range m.entries {
+		if !e.expired(now) && matchPattern(pattern, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (m *memoryCache) Set(_ context.Context, tableName string, key string, value *internal.CacheData, options *SetOptions) error {
+	enc, err := internal.EncodeCacheData(value)
+	if err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	exists := m.get(cacheKey) != nil
+	if options != nil && options.XX && !exists {
+		return ErrKeyNotFound
+	}
+	if options != nil && options.NX && exists {
+		return ErrKeyAlreadyExists
+	}
+
+	var expireAt time.Time
+	if options != nil && options.EX > 0 {
+		expireAt = time.Now().Add(time.Duration(options.EX) * time.Second)
+	} else if options != nil && options.PX > 0 {
+		expireAt = time.Now().Add(time.Duration(options.PX) * time.Millisecond)
+	}
+
+	m.put(cacheKey, enc, expireAt)
+	return nil
+}
+
+func (m *memoryCache) GetSet(_ context.Context, tableName string, key string, value *internal.CacheData) (*internal.CacheData, error) {
+	enc, err := internal.EncodeCacheData(value)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+
+	var old []byte
+	if e := m.get(cacheKey); e != nil {
+		var ok bool
+		if old, ok = e.value.([]byte); !ok {
+			return nil, ErrWrongType
+		}
+	}
+
+	m.put(cacheKey, enc, time.Time{})
+	if len(old) == 0 {
+		return nil, nil
+	}
+
+	return internal.DecodeCacheData(old)
+}
+
+func (m *memoryCache) Get(_ context.Context, tableName string, key string, options *GetOptions) (*internal.CacheData, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	e := m.get(cacheKey)
+	if e == nil {
+		return nil, ErrKeyNotFound
+	}
+
+	value, ok := e.value.([]byte)
+	if !ok {
+		return nil, ErrWrongType
+	}
+
+	switch {
+	case options != nil && options.Expiry > 0:
+		e.expireAt = time.Now().Add(options.Expiry)
+	case options != nil && options.GetDelete:
+		delete(m.entries, cacheKey)
+	}
+
+	return internal.DecodeCacheData(value)
+}
+
+func (m *memoryCache) Delete(_ context.Context, tableName string, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, ErrEmptyKey
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	var deleted int64
+	for _, k := range keys {
+		cacheKey := encodeToCacheKey(tableName, k)
+		if m.get(cacheKey) != nil {
+			delete(m.entries, cacheKey)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (m *memoryCache) Exists(_ context.Context, tableName string, keys ...string) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if len(keys) == 0 {
+		if m.get(tableName) != nil {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	var count int64
+	for _, k := range keys {
+		if m.get(encodeToCacheKey(tableName, k)) != nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (m *memoryCache) Keys(_ context.Context, tableName string, pattern string) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.keys(encodeToCacheKey(tableName, pattern)), nil
+}
+
+// Scan uses the index of the next key in the sorted matching keys as the cursor. Like in Redis, the keys added or
+// removed during the iteration may be missed or returned twice.
+func (m *memoryCache) Scan(_ context.Context, tableName string, cursor uint64, count int64, pattern string) ([]string, uint64) {
+	if count > config.DefaultConfig.Cache.MaxScan {
+		count = config.DefaultConfig.Cache.MaxScan
+	}
+	if count <= 0 {
+		count = 10
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	keys := m.keys(encodeToCacheKey(tableName, pattern))
+	if cursor >= uint64(len(keys)) {
+		return nil, 0
+	}
+
+	end := cursor + uint64(count)
+	if end >= uint64(len(keys)) {
+		return keys[cursor:], 0
+	}
+
+	return keys[cursor:end], end
+}
+
+func (m *memoryCache) IncrBy(_ context.Context, tableName string, key string, increment int64) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+
+	var (
+		value    int64
+		expireAt time.Time
+	)
+	if e := m.get(cacheKey); e != nil {
+		current, ok := e.value.([]byte)
+		if !ok {
+			return 0, ErrWrongType
+		}
+
+		data, err := internal.DecodeCacheData(current)
+		if err != nil {
+			return 0, err
+		}
+		if value, err = parseCounter(data.GetRawData()); err != nil {
+			return 0, err
+		}
+		expireAt = e.expireAt
+	}
+
+	if (increment > 0 && value > math.MaxInt64-increment) || (increment < 0 && value < math.MinInt64-increment) {
+		return 0, ErrNotInteger
+	}
+	value += increment
+
+	enc, err := internal.EncodeCacheData(internal.NewCacheData([]byte(strconv.FormatInt(value, 10))))
+	if err != nil {
+		return 0, err
+	}
+
+	m.put(cacheKey, enc, expireAt)
+	return value, nil
+}
+
+func (m *memoryCache) Expire(_ context.Context, tableName string, key string, ttl time.Duration) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	e := m.get(encodeToCacheKey(tableName, key))
+	if e == nil {
+		return false, nil
+	}
+
+	e.expireAt = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (m *memoryCache) Persist(_ context.Context, tableName string, key string) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	e := m.get(encodeToCacheKey(tableName, key))
+	if e == nil {
+		return false, ErrKeyNotFound
+	}
+	if e.expireAt.IsZero() {
+		return false, nil
+	}
+
+	e.expireAt = time.Time{}
+	return true, nil
+}
+
+func (m *memoryCache) TTL(_ context.Context, tableName string, key string) (time.Duration, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	e := m.get(encodeToCacheKey(tableName, key))
+	if e == nil {
+		return 0, ErrKeyNotFound
+	}
+	if e.expireAt.IsZero() {
+		return NoExpiry, nil
+	}
+
+	return time.Until(e.expireAt), nil
+}
+
+// MemoryUsage estimates the size of the key, the size of the key and of the data of its value with an overhead for the
+// key and each element of the value.
+func (m *memoryCache) MemoryUsage(_ context.Context, tableName string, key string) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	e := m.get(cacheKey)
+	if e == nil {
+		return 0, ErrKeyNotFound
+	}
+
+	size := int64(len(cacheKey) + memoryOverhead)
+	switch v := e.value.(type) {
+	case []byte:
+		size += int64(len(v))
+	case memoryHash:
+		for f, fv := range v {
+			size += int64(len(f) + len(fv) + memoryOverhead)
+		}
+	case *memoryList:
+		for _, lv := range v.values {
+			size += int64(len(lv) + memoryOverhead)
+		}
+	case memorySet:
+		for member := range v {
+			size += int64(len(member) + memoryOverhead)
+		}
+	case memorySortSet:
+		for member := range v {
+			size += int64(len(member) + 8 + memoryOverhead)
+		}
+	case *memoryStreamData:
+		for _, msg := range v.messages {
+			size += int64(len(msg.payload) + memoryOverhead)
+		}
+	}
+
+	return size, nil
+}
+
+// getValue returns the value of the key, nil if the key doesn't exist and ErrWrongType if the key holds a value of
+// another type. The caller holds the lock.
+func getValue[T any](m *memoryCache, cacheKey string) (T, error) {
+	var value T
+
+	e := m.get(cacheKey)
+	if e == nil {
+		return value, nil
+	}
+
+	value, ok := e.value.(T)
+	if !ok {
+		return value, ErrWrongType
+	}
+
+	return value, nil
+}
+
+// update stores the value of a hash, a list, a set or a sorted set. Like in Redis, the expiry is kept and the key is
+// removed once the collection is empty.
+func (m *memoryCache) update(cacheKey string, value any, length int) {
+	if length == 0 {
+		delete(m.entries, cacheKey)
+		return
+	}
+
+	if e := m.get(cacheKey); e != nil {
+		e.value = value
+		return
+	}
+
+	m.put(cacheKey, value, time.Time{})
+}
+
+func (m *memoryCache) HSet(_ context.Context, tableName string, key string, fields map[string][]byte) (int64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	hash, err := getValue[memoryHash](m, cacheKey)
+	if err != nil {
+		return 0, err
+	}
+	if hash == nil {
+		hash = make(memoryHash)
+	}
+
+	var added int64
+	for f, v := range fields {
+		if _, ok := hash[f]; !ok {
+			added++
+		}
+		hash[f] = bytes.Clone(v)
+	}
+
+	m.update(cacheKey, hash, len(hash))
+	return added, nil
+}
+
+func (m *memoryCache) HGet(_ context.Context, tableName string, key string, field string) ([]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	hash, err := getValue[memoryHash](m, encodeToCacheKey(tableName, key))
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := hash[field]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	return bytes.Clone(value), nil
+}
+
+func (m *memoryCache) HGetAll(_ context.Context, tableName string, key string) (map[string][]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	hash, err := getValue[memoryHash](m, encodeToCacheKey(tableName, key))
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string][]byte, len(hash))
+	for f, v := range hash {
+		fields[f] = bytes.Clone(v)
+	}
+
+	return fields, nil
+}
+
+func (m *memoryCache) HDel(_ context.Context, tableName string, key string, fields ...string) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	hash, err := getValue[memoryHash](m, cacheKey)
+	if err != nil || hash == nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, f := range fields {
+		if _, ok := hash[f]; ok {
+			delete(hash, f)
+			deleted++
+		}
+	}
+
+	m.update(cacheKey, hash, len(hash))
+	return deleted, nil
+}
+
+func (m *memoryCache) HDelIfEqual(_ context.Context, tableName string, key string, field string, value []byte) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	hash, err := getValue[memoryHash](m, cacheKey)
+	if err != nil || hash == nil {
+		return false, err
+	}
+
+	current, ok := hash[field]
+	if !ok || !bytes.Equal(current, value) {
+		return false, nil
+	}
+	delete(hash, field)
+
+	m.update(cacheKey, hash, len(hash))
+	return true, nil
+}
+
+func (m *memoryCache) push(tableName string, key string, left bool, values [][]byte) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	list, err := getValue[*memoryList](m, cacheKey)
+	if err != nil {
+		return 0, err
+	}
+	if list == nil {
+		list = &memoryList{}
+	}
+
+	for _, v := range values {
+		if left {
+			list.values = append([][]byte{bytes.Clone(v)}, list.values...)
+		} else {
+			list.values = append(list.values, bytes.Clone(v))
+		}
+	}
+
+	m.update(cacheKey, list, len(list.values))
+	return int64(len(list.values)), nil
+}
+
+func (m *memoryCache) LPush(_ context.Context, tableName string, key string, values ...[]byte) (int64, error) {
+	return m.push(tableName, key, true, values)
+}
+
+func (m *memoryCache) RPush(_ context.Context, tableName string, key string, values ...[]byte) (int64, error) {
+	return m.push(tableName, key, false, values)
+}
+
+func (m *memoryCache) pop(tableName string, key string, left bool, count int) ([][]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	list, err := getValue[*memoryList](m, cacheKey)
+	if err != nil || list == nil {
+		return nil, err
+	}
+
+	if count > len(list.values) {
+		count = len(list.values)
+	}
+
+	popped := make([][]byte, count)
+	for i := 0; i < count; i++ {
+		if left {
+			popped[i] = list.values[i]
+		} else {
+			popped[i] = list.values[len(list.values)-1-i]
+		}
+	}
+
+	if left {
+		list.values = list.values[count:]
+	} else {
+		list.values = list.values[:len(list.values)-count]
+	}
+
+	m.update(cacheKey, list, len(list.values))
+	return popped, nil
+}
+
+func (m *memoryCache) LPop(_ context.Context, tableName string, key string, count int) ([][]byte, error) {
+	return m.pop(tableName, key, true, count)
+}
+
+func (m *memoryCache) RPop(_ context.Context, tableName string, key string, count int) ([][]byte, error) {
+	return m.pop(tableName, key, false, count)
+}
+
+func (m *memoryCache) LRange(_ context.Context, tableName string, key string, start int64, stop int64) ([][]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	list, err := getValue[*memoryList](m, encodeToCacheKey(tableName, key))
+	if err != nil || list == nil {
+		return nil, err
+	}
+
+	start, stop, ok := normalizeRange(start, stop, len(list.values))
+	if !ok {
+		return nil, nil
+	}
+
+	values := make([][]byte, 0, stop-start+1)
+	for _, v := range list.values[start : stop+1] {
+		values = append(values, bytes.Clone(v))
+	}
+
+	return values, nil
+}
+
+func (m *memoryCache) SAdd(_ context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	set, err := getValue[memorySet](m, cacheKey)
+	if err != nil {
+		return 0, err
+	}
+	if set == nil {
+		set = make(memorySet)
+	}
+
+	var added int64
+	for _, member := range members {
+		if _, ok := set[string(member)]; !ok {
+			set[string(member)] = struct{}{}
+			added++
+		}
+	}
+
+	m.update(cacheKey, set, len(set))
+	return added, nil
+}
+
+func (m *memoryCache) SRem(_ context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	set, err := getValue[memorySet](m, cacheKey)
+	if err != nil || set == nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, member := range members {
+		if _, ok := set[string(member)]; ok {
+			delete(set, string(member))
+			removed++
+		}
+	}
+
+	m.update(cacheKey, set, len(set))
+	return removed, nil
+}
+
+func (m *memoryCache) SMembers(_ context.Context, tableName string, key string) ([][]byte, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	set, err := getValue[memorySet](m, encodeToCacheKey(tableName, key))
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+
+	converted := make([][]byte, len(members))
+	for i, member := range members {
+		converted[i] = []byte(member)
+	}
+
+	return converted, nil
+}
+
+func (m *memoryCache) SIsMember(_ context.Context, tableName string, key string, member []byte) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	set, err := getValue[memorySet](m, encodeToCacheKey(tableName, key))
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := set[string(member)]
+	return ok, nil
+}
+
+func (m *memoryCache) ZAdd(_ context.Context, tableName string, key string, members ...ZMember) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	zset, err := getValue[memorySortSet](m, cacheKey)
+	if err != nil {
+		return 0, err
+	}
+	if zset == nil {
+		zset = make(memorySortSet)
+	}
+
+	var added int64
+	for _, member := range members {
+		if _, ok := zset[string(member.Member)]; !ok {
+			added++
+		}
+		zset[string(member.Member)] = member.Score
+	}
+
+	m.update(cacheKey, zset, len(zset))
+	return added, nil
+}
+
+func (m *memoryCache) ZIncrBy(_ context.Context, tableName string, key string, increment float64, member []byte) (float64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	zset, err := getValue[memorySortSet](m, cacheKey)
+	if err != nil {
+		return 0, err
+	}
+	if zset == nil {
+		zset = make(memorySortSet)
+	}
+
+	zset[string(member)] += increment
+
+	m.update(cacheKey, zset, len(zset))
+	return zset[string(member)], nil
+}
+
+func (m *memoryCache) ZRem(_ context.Context, tableName string, key string, members ...[]byte) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	cacheKey := encodeToCacheKey(tableName, key)
+	zset, err := getValue[memorySortSet](m, cacheKey)
+	if err != nil || zset == nil {
+		return 0, err
+	}
+
+	var removed int64
+	for _, member := range members {
+		if _, ok := zset[string(member)]; ok {
+			delete(zset, string(member))
+			removed++
+		}
+	}
+
+	m.update(cacheKey, zset, len(zset))
+	return removed, nil
+}
+
+func (m *memoryCache) ZRange(_ context.Context, tableName string, key string, start int64, stop int64, rev bool) ([]ZMember, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	zset, err := getValue[memorySortSet](m, encodeToCacheKey(tableName, key))
+	if err != nil || zset == nil {
+		return nil, err
+	}
+
+	sorted := zset.sorted()
+	if rev {
+		for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+			sorted[i], sorted[j] = sorted[j], sorted[i]
+		}
+	}
+
+	start, stop, ok := normalizeRange(start, stop, len(sorted))
+	if !ok {
+		return []ZMember{}, nil
+	}
+
+	return sorted[start : stop+1], nil
+}
+
+func (m *memoryCache) ZRangeByScore(_ context.Context, tableName string, key string, min string, max string, offset int64, count int64) ([]ZMember, error) {
+	minScore, minExcl, err := parseScoreBound(min)
+	if err != nil {
+		return nil, err
+	}
+	maxScore, maxExcl, err := parseScoreBound(max)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	zset, err := getValue[memorySortSet](m, encodeToCacheKey(tableName, key))
+	if err != nil {
+		return nil, err
+	}
+
+	members := []ZMember{}
+	for _, member := range zset.sorted() {
+		if member.Score < minScore || (minExcl && member.Score == minScore) {
+			continue
+		}
+		if member.Score > maxScore || (maxExcl && member.Score == maxScore) {
+			break
+		}
+
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if count > 0 && int64(len(members)) == count {
+			break
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// sorted returns the members ordered by score, the members with the same score are ordered lexicographically.
+func (z memorySortSet) sorted() []ZMember {
+	members := make([]ZMember, 0, len(z))
+	for member, score := range z {
+		members = append(members, ZMember{Score: score, Member: []byte(member)})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Score != members[j].Score {
+			return members[i].Score < members[j].Score
+		}
+		return bytes.Compare(members[i].Member, members[j].Member) < 0
+	})
+
+	return members
+}
+
+func (m *memoryCache) CreateStream(_ context.Context, streamName string) (Stream, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	s, err := getValue[*memoryStreamData](m, streamName)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		return nil, ErrStreamAlreadyExists
+	}
+
+	m.putStream(streamName)
+	return newMemoryStream(m, streamName), nil
+}
+
+func (m *memoryCache) CreateOrGetStream(_ context.Context, streamName string) (Stream, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	s, err := getValue[*memoryStreamData](m, streamName)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		m.putStream(streamName)
+	}
+
+	return newMemoryStream(m, streamName), nil
+}
+
+// putStream creates the stream with the default consumer group, like the streams created in Redis.
+func (m *memoryCache) putStream(streamName string) {
+	data := newMemoryStreamData()
+	data.createGroup(DefaultGroup, streamID{})
+
+	m.put(streamName, data, time.Time{})
+}
+
+func (m *memoryCache) GetStream(_ context.Context, streamName string) (Stream, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.get(streamName) == nil {
+		return nil, ErrStreamNotFound
+	}
+
+	return newMemoryStream(m, streamName), nil
+}
+
+func (m *memoryCache) ListStreams(_ context.Context, streamNamePrefix string) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.keys(streamNamePrefix), nil
+}
+
+func (m *memoryCache) DeleteStream(_ context.Context, streamName string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.entries, streamName)
+	return nil
+}
+
+// normalizeRange converts the start and stop indexes, both included and negative from the tail, into indexes of a
+// slice of the length. It returns false if the range is empty.
+func normalizeRange(start int64, stop int64, length int) (int64, int64, bool) {
+	n := int64(length)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+
+	return start, stop, start <= stop && start < n
+}
+
+// parseScoreBound parses a score bound of ZRangeByScore, "(" makes it exclusive.
+func parseScoreBound(bound string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(bound, "(")
+	if exclusive {
+		bound = bound[1:]
+	}
+
+	switch bound {
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	case "+inf", "inf":
+		return math.Inf(1), exclusive, nil
+	}
+
+	score, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("min or max is not a float")
+	}
+
+	return score, exclusive, nil
+}
+
+// matchPattern matches the key with a glob-style pattern like Redis does: "*" matches any characters, "?" a single
+// character, "[...]" a character of the class and "\" escapes the next character.
+func matchPattern(pattern string, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if matchPattern(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+		case '[':
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				if len(key) == 0 || key[0] != '[' {
+					return false
+				}
+				break
+			}
+			if len(key) == 0 || !matchClass(pattern[1:end+1], key[0]) {
+				return false
+			}
+			pattern = pattern[end+1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+		default:
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+		}
+
+		pattern, key = pattern[1:], key[1:]
+	}
+
+	return len(key) == 0
+}
+
+func matchClass(class string, c byte) bool {
+	negate := len(class) > 0 && class[0] == '^'
+	if negate {
+		class = class[1:]
+	}
+
+	matched := false
+	for i := 0; i < len(class); i++ {
+		switch {
+		case class[i] == '\\' && i+1 < len(class):
+			i++
+			matched = matched || class[i] == c
+		case i+2 < len(class) && class[i+1] == '-':
+			lo, hi := class[i], class[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			matched = matched || (c >= lo && c <= hi)
+			i += 2
+		default:
+			matched = matched || class[i] == c
+		}
+	}
+
+	return matched != negate
+}
@@ -0,0 +1,514 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	xredis "github.com/go-redis/redis/v8"
+	"github.com/tigrisdata/tigris/internal"
+)
+
+// streamID is the id of a stream message, the milliseconds when it was added and a sequence number for the messages
+// added in the same millisecond.
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+func parseStreamID(id string) (streamID, error) {
+	switch id {
+	case "-":
+		return streamID{}, nil
+	case "+":
+		return streamID{ms: math.MaxUint64, seq: math.MaxUint64}, nil
+	}
+
+	msStr, seqStr, hasSeq := strings.Cut(id, "-")
+	ms, err := strconv.ParseUint(msStr, 10, 64)
+	if err != nil {
+		return streamID{}, fmt.Errorf("ERR Invalid stream ID specified as stream command argument")
+	}
+
+	var seq uint64
+	if hasSeq {
+		if seq, err = strconv.ParseUint(seqStr, 10, 64); err != nil {
+			return streamID{}, fmt.Errorf("ERR Invalid stream ID specified as stream command argument")
+		}
+	}
+
+	return streamID{ms: ms, seq: seq}, nil
+}
+
+func (id streamID) String() string {
+	return fmt.Sprintf("%d-%d", id.ms, id.seq)
+}
+
+func (id streamID) less(other streamID) bool {
+	return id.ms < other.ms || (id.ms == other.ms && id.seq < other.seq)
+}
+
+type memoryMessage struct {
+	id      streamID
+	payload string
+}
+
+// memoryGroup is a consumer group, it has a single consumer like the groups read with ReadGroup.
+type memoryGroup struct {
+	lastDelivered streamID
+	pending       map[streamID]struct{}
+	consumers     int64
+}
+
+// memoryStreamData is the value of a stream key of the in-memory cache.
+type memoryStreamData struct {
+	messages []memoryMessage
+	lastID   streamID
+	groups   map[string]*memoryGroup
+}
+
+func newMemoryStreamData() *memoryStreamData {
+	return &memoryStreamData{
+		groups: make(map[string]*memoryGroup),
+	}
+}
+
+func (d *memoryStreamData) createGroup(group string, lastDelivered streamID) {
+	d.groups[group] = &memoryGroup{
+		lastDelivered: lastDelivered,
+		pending:       make(map[streamID]struct{}),
+	}
+}
+
+// after returns the messages with an id greater than the id.
+func (d *memoryStreamData) after(id streamID) []xredis.XMessage {
+	i := sort.Search(len(d.messages), func(i int) bool {
+		return id.less(d.messages[i].id)
+	})
+
+	messages := make([]xredis.XMessage, 0, len(d.messages)-i)
+	for _, m := range d.messages[i:] {
+		messages = append(messages, m.toXMessage())
+	}
+
+	return messages
+}
+
+// position returns the id of the position, "$" is the id of the last message.
+func (d *memoryStreamData) position(pos string) (streamID, error) {
+	if pos == ConsumerGroupDefaultCurrentPos {
+		return d.lastID, nil
+	}
+
+	return parseStreamID(pos)
+}
+
+func (m memoryMessage) toXMessage() xredis.XMessage {
+	return xredis.XMessage{
+		ID: m.id.String(),
+		Values: map[string]any{
+			payloadKey: m.payload,
+		},
+	}
+}
+
+type memoryStream struct {
+	cache *memoryCache
+	name  string
+}
+
+func newMemoryStream(cache *memoryCache, streamName string) Stream {
+	return &memoryStream{
+		cache: cache,
+		name:  streamName,
+	}
+}
+
+func (s *memoryStream) Name() string {
+	return s.name
+}
+
+// data returns the stream, nil if it doesn't exist. The caller holds the lock.
+func (s *memoryStream) data() (*memoryStreamData, error) {
+	return getValue[*memoryStreamData](s.cache, s.name)
+}
+
+func (s *memoryStream) noGroupError(group string) error {
+	return fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", s.name, group)
+}
+
+func (s *memoryStream) Add(_ context.Context, value *internal.StreamData) (string, error) {
+	enc, err := internal.EncodeStreamData(value)
+	if err != nil {
+		return "", err
+	}
+
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		data = newMemoryStreamData()
+		s.cache.put(s.name, data, time.Time{})
+	}
+
+	id := streamID{ms: uint64(time.Now().UnixMilli())}
+	if !data.lastID.less(id) {
+		id = streamID{ms: data.lastID.ms, seq: data.lastID.seq + 1}
+	}
+
+	data.messages = append(data.messages, memoryMessage{id: id, payload: string(enc)})
+	data.lastID = id
+
+	close(s.cache.added)
+	s.cache.added = make(chan struct{})
+
+	return id.String(), nil
+}
+
+// wait calls read until it returns messages, waiting for the messages added to the streams in between. It returns
+// false if there is no message after the timeout, a zero timeout waits until the context is done.
+func (s *memoryStream) wait(ctx context.Context, timeout time.Duration, read func(*memoryStreamData) ([]xredis.XMessage, error)) (*StreamMessages, bool, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		s.cache.Lock()
+		data, err := s.data()
+		var messages []xredis.XMessage
+		if err == nil {
+			messages, err = read(data)
+		}
+		added := s.cache.added
+		s.cache.Unlock()
+
+		if err != nil {
+			return nil, true, err
+		}
+		if len(messages) > 0 {
+			return s.toStreamMessages(messages), true, nil
+		}
+
+		select {
+		case <-added:
+		case <-expired:
+			return nil, false, nil
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+}
+
+func (s *memoryStream) toStreamMessages(messages []xredis.XMessage) *StreamMessages {
+	return &StreamMessages{
+		XStream: xredis.XStream{
+			Stream:   s.name,
+			Messages: messages,
+		},
+	}
+}
+
+func (s *memoryStream) Read(ctx context.Context, pos string) (*StreamMessages, bool, error) {
+	s.cache.Lock()
+	data, err := s.data()
+	var after streamID
+	if err == nil && data != nil {
+		after, err = data.position(pos)
+	} else if err == nil && pos != ConsumerGroupDefaultCurrentPos {
+		after, err = parseStreamID(pos)
+	}
+	s.cache.Unlock()
+	if err != nil {
+		return nil, true, err
+	}
+
+	return s.wait(ctx, time.Second, func(data *memoryStreamData) ([]xredis.XMessage, error) {
+		if data == nil {
+			return nil, nil
+		}
+		return data.after(after), nil
+	})
+}
+
+func (s *memoryStream) ReadGroup(ctx context.Context, group string, pos ReadGroupPos) (*StreamMessages, bool, error) {
+	if pos != ReadGroupPosCurrent {
+		return s.readPending(group, string(pos))
+	}
+
+	return s.wait(ctx, BlockReadGroupDuration, func(data *memoryStreamData) ([]xredis.XMessage, error) {
+		if data == nil || data.groups[group] == nil {
+			return nil, s.noGroupError(group)
+		}
+
+		g := data.groups[group]
+		g.consumers = 1
+
+		messages := data.after(g.lastDelivered)
+		for _, m := range messages {
+			id, _ := parseStreamID(m.ID)
+			g.pending[id] = struct{}{}
+			g.lastDelivered = id
+		}
+
+		return messages, nil
+	})
+}
+
+// readPending returns the messages delivered to the group and not acknowledged yet, with an id greater than the
+// position.
+func (s *memoryStream) readPending(group string, pos string) (*StreamMessages, bool, error) {
+	after, err := parseStreamID(pos)
+	if err != nil {
+		return nil, true, err
+	}
+
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil {
+		return nil, true, err
+	}
+	if data == nil || data.groups[group] == nil {
+		return nil, true, s.noGroupError(group)
+	}
+
+	g := data.groups[group]
+	g.consumers = 1
+
+	var messages []xredis.XMessage
+	for _, m := range data.after(after) {
+		id, _ := parseStreamID(m.ID)
+		if _, ok := g.pending[id]; ok {
+			messages = append(messages, m)
+		}
+	}
+	if len(messages) == 0 {
+		return nil, true, nil
+	}
+
+	return s.toStreamMessages(messages), true, nil
+}
+
+func (s *memoryStream) CreateConsumerGroup(_ context.Context, group string, pos string) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("ERR The XGROUP subcommand requires the key to exist")
+	}
+	if _, ok := data.groups[group]; ok {
+		return fmt.Errorf("BUSYGROUP %s", errStrConsGroupAlreadyExists)
+	}
+
+	id, err := data.position(pos)
+	if err != nil {
+		return err
+	}
+
+	data.createGroup(group, id)
+	return nil
+}
+
+func (s *memoryStream) RemoveConsumerGroup(_ context.Context, group string) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return errors.New(errStrNoSuchKey)
+	}
+
+	delete(data.groups, group)
+	return nil
+}
+
+func (s *memoryStream) GetConsumerGroups(_ context.Context) ([]xredis.XInfoGroup, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	groups := make([]xredis.XInfoGroup, 0, len(data.groups))
+	for name, g := range data.groups {
+		groups = append(groups, g.info(name))
+	}
+
+	// redis returns the groups ordered by name
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+
+	return groups, nil
+}
+
+func (s *memoryStream) GetConsumerGroup(_ context.Context, group string) (*xredis.XInfoGroup, bool, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil || data == nil {
+		return nil, false, err
+	}
+
+	g, ok := data.groups[group]
+	if !ok {
+		return nil, false, nil
+	}
+
+	info := g.info(group)
+	return &info, true, nil
+}
+
+func (g *memoryGroup) info(name string) xredis.XInfoGroup {
+	return xredis.XInfoGroup{
+		Name:            name,
+		Consumers:       g.consumers,
+		Pending:         int64(len(g.pending)),
+		LastDeliveredID: g.lastDelivered.String(),
+	}
+}
+
+func (s *memoryStream) SetID(_ context.Context, group string, pos string) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil {
+		return err
+	}
+	if data == nil || data.groups[group] == nil {
+		return s.noGroupError(group)
+	}
+
+	id, err := data.position(pos)
+	if err != nil {
+		return err
+	}
+
+	data.groups[group].lastDelivered = id
+	return nil
+}
+
+func (s *memoryStream) Ack(_ context.Context, group string, ids ...string) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil || data == nil || data.groups[group] == nil {
+		return err
+	}
+
+	for _, id := range ids {
+		parsed, err := parseStreamID(id)
+		if err != nil {
+			return err
+		}
+		delete(data.groups[group].pending, parsed)
+	}
+
+	return nil
+}
+
+func (s *memoryStream) Delete(ctx context.Context) error {
+	return s.cache.DeleteStream(ctx, s.name)
+}
+
+func (s *memoryStream) Trim(_ context.Context, maxLen int64, minId string) (int64, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil || data == nil {
+		return 0, err
+	}
+
+	var trimmed int
+	if maxLen > 0 && int64(len(data.messages)) > maxLen {
+		trimmed = len(data.messages) - int(maxLen)
+	}
+
+	if len(minId) > 0 {
+		min, err := parseStreamID(minId)
+		if err != nil {
+			return 0, err
+		}
+
+		for trimmed < len(data.messages) && data.messages[trimmed].id.less(min) {
+			trimmed++
+		}
+	}
+
+	data.messages = data.messages[trimmed:]
+	return int64(trimmed), nil
+}
+
+func (s *memoryStream) ReadLast(_ context.Context, count int64) ([]xredis.XMessage, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil || data == nil || count <= 0 {
+		return nil, err
+	}
+
+	messages := make([]xredis.XMessage, 0, count)
+	for i := len(data.messages) - 1; i >= 0 && int64(len(messages)) < count; i-- {
+		messages = append(messages, data.messages[i].toXMessage())
+	}
+
+	return messages, nil
+}
+
+func (s *memoryStream) NthLastID(_ context.Context, count int64) (string, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	data, err := s.data()
+	if err != nil || data == nil || len(data.messages) == 0 || count <= 0 {
+		return "", err
+	}
+
+	first := int64(len(data.messages)) - count
+	if first < 0 {
+		first = 0
+	}
+
+	return data.messages[first].toXMessage().ID, nil
+}
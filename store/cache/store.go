@@ -48,6 +48,14 @@ type Stream interface {
 	Ack(ctx context.Context, group string, ids ...string) error
 	// Delete is to delete this stream. it removes all the associated consumer group as well.
 	Delete(ctx context.Context) error
+	// Trim removes the oldest messages so that the stream keeps at most maxLen messages and no message with an ID
+	// lower than minId, a zero maxLen or an empty minId is ignored. It returns the number of messages removed.
+	Trim(ctx context.Context, maxLen int64, minId string) (int64, error)
+	// ReadLast returns the last count messages of the stream, the newest first.
+	ReadLast(ctx context.Context, count int64) ([]xredis.XMessage, error)
+	// NthLastID returns the id of the oldest of the last count messages of the stream without reading the others, it
+	// returns an empty id if the stream has no messages.
+	NthLastID(ctx context.Context, count int64) (string, error)
 }
 
 type SetOptions struct {
@@ -67,6 +75,15 @@ type GetOptions struct {
 	GetDelete bool
 }
 
+// NoExpiry is the TTL returned for a key without expiry.
+const NoExpiry time.Duration = -1
+
+// ZMember is a member of a sorted set with its score.
+type ZMember struct {
+	Score  float64
+	Member []byte
+}
+
 type Cache interface {
 	Set(ctx context.Context, tableName string, key string, value *internal.CacheData, options *SetOptions) error
 	// GetSet is to get the previous value and set the new value
@@ -80,6 +97,69 @@ type Cache interface {
 	Keys(ctx context.Context, tableName string, pattern string) ([]string, error)
 	Scan(ctx context.Context, tableName string, cursor uint64, count int64, pattern string) ([]string, uint64)
 
+	// IncrBy atomically adds the increment, which can be negative, to the integer value of the key and returns the new
+	// value. A missing key is set to the increment, keeping the expiry of an existing key. ErrNotInteger is returned if
+	// the value is not an integer or the result overflows.
+	IncrBy(ctx context.Context, tableName string, key string, increment int64) (int64, error)
+	// Expire sets the time to live of the key, it returns false if the key doesn't exist.
+	Expire(ctx context.Context, tableName string, key string, ttl time.Duration) (bool, error)
+	// Persist removes the expiry of the key, it returns false if the key has no expiry and ErrKeyNotFound if the key
+	// doesn't exist.
+	Persist(ctx context.Context, tableName string, key string) (bool, error)
+	// TTL returns the remaining time to live of the key, NoExpiry if the key has no expiry and ErrKeyNotFound if the key
+	// doesn't exist.
+	TTL(ctx context.Context, tableName string, key string) (time.Duration, error)
+	// MemoryUsage returns the number of bytes used by the key and its value, ErrKeyNotFound is returned if the key
+	// doesn't exist.
+	MemoryUsage(ctx context.Context, tableName string, key string) (int64, error)
+
+	// HSet sets the fields of the hash stored at the key, it returns the number of fields added.
+	HSet(ctx context.Context, tableName string, key string, fields map[string][]byte) (int64, error)
+	// HGet returns the value of the field of the hash, ErrKeyNotFound is returned if the field doesn't exist.
+	HGet(ctx context.Context, tableName string, key string, field string) ([]byte, error)
+	// HGetAll returns all the fields of the hash.
+	HGetAll(ctx context.Context, tableName string, key string) (map[string][]byte, error)
+	// HDel removes the fields from the hash, it returns the number of fields removed.
+	HDel(ctx context.Context, tableName string, key string, fields ...string) (int64, error)
+	// HDelIfEqual removes the field from the hash only if it holds the value, it returns true if the field is removed.
+	HDelIfEqual(ctx context.Context, tableName string, key string, field string, value []byte) (bool, error)
+
+	// LPush inserts the values at the head of the list, it returns the length of the list.
+	LPush(ctx context.Context, tableName string, key string, values ...[]byte) (int64, error)
+	// RPush inserts the values at the tail of the list, it returns the length of the list.
+	RPush(ctx context.Context, tableName string, key string, values ...[]byte) (int64, error)
+	// LPop removes and returns up to count values from the head of the list.
+	LPop(ctx context.Context, tableName string, key string, count int) ([][]byte, error)
+	// RPop removes and returns up to count values from the tail of the list.
+	RPop(ctx context.Context, tableName string, key string, count int) ([][]byte, error)
+	// LRange returns the values of the list between the start and stop indexes, both included. Negative indexes are
+	// counted from the tail.
+	LRange(ctx context.Context, tableName string, key string, start int64, stop int64) ([][]byte, error)
+
+	// SAdd adds the members to the set, it returns the number of members added.
+	SAdd(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error)
+	// SRem removes the members from the set, it returns the number of members removed.
+	SRem(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error)
+	// SMembers returns all the members of the set.
+	SMembers(ctx context.Context, tableName string, key string) ([][]byte, error)
+	// SIsMember returns true if the member belongs to the set.
+	SIsMember(ctx context.Context, tableName string, key string, member []byte) (bool, error)
+
+	// ZAdd adds the members to the sorted set or updates their score, it returns the number of members added.
+	ZAdd(ctx context.Context, tableName string, key string, members ...ZMember) (int64, error)
+	// ZIncrBy adds the increment to the score of the member of the sorted set, adding the member if needed, and returns
+	// the new score.
+	ZIncrBy(ctx context.Context, tableName string, key string, increment float64, member []byte) (float64, error)
+	// ZRem removes the members from the sorted set, it returns the number of members removed.
+	ZRem(ctx context.Context, tableName string, key string, members ...[]byte) (int64, error)
+	// ZRange returns the members of the sorted set between the start and stop ranks, both included, ordered by score
+	// from the lowest or from the highest when rev is set.
+	ZRange(ctx context.Context, tableName string, key string, start int64, stop int64, rev bool) ([]ZMember, error)
+	// ZRangeByScore returns the members of the sorted set with a score between min and max, ordered by score. The
+	// bounds are inclusive, unless prefixed with "(", and "-inf" and "+inf" are unbounded. A zero count returns all
+	// the members after the offset.
+	ZRangeByScore(ctx context.Context, tableName string, key string, min string, max string, offset int64, count int64) ([]ZMember, error)
+
 	// CreateStream creates and returns a stream object, throws an error if stream already exists
 	CreateStream(ctx context.Context, streamName string) (Stream, error)
 	// CreateOrGetStream creates or returns an existing stream
@@ -92,6 +172,12 @@ type Cache interface {
 	DeleteStream(ctx context.Context, streamName string) error
 }
 
+// NewCache returns the cache of the configured backend. The in-memory cache is shared by all the callers of the
+// process.
 func NewCache(cfg *config.CacheConfig) Cache {
+	if cfg.Backend == config.MemoryCacheBackend {
+		return getSharedMemoryCache()
+	}
+
 	return newCache(cfg)
 }
@@ -17,6 +17,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	xredis "github.com/go-redis/redis/v8"
@@ -188,6 +189,75 @@ func (s *stream) Delete(ctx context.Context) error {
 	return err
 }
 
+func (s *stream) Trim(ctx context.Context, maxLen int64, minId string) (int64, error) {
+	var trimmed int64
+	if maxLen > 0 {
+		n, err := s.cache.Client.XTrimMaxLen(ctx, s.name, maxLen).Result()
+		if err != nil {
+			return trimmed, err
+		}
+		trimmed += n
+	}
+
+	if len(minId) > 0 {
+		n, err := s.cache.Client.XTrimMinID(ctx, s.name, minId).Result()
+		if err != nil {
+			return trimmed, err
+		}
+		trimmed += n
+	}
+
+	return trimmed, nil
+}
+
+func (s *stream) ReadLast(ctx context.Context, count int64) ([]xredis.XMessage, error) {
+	return s.cache.Client.XRevRangeN(ctx, s.name, "+", "-", count).Result()
+}
+
+// nthLastIDScript returns the id of the oldest of the last ARGV[1] messages of the stream, so that only the id is sent
+// back rather than all the messages.
+var nthLastIDScript = xredis.NewScript(`
+local messages = redis.call('XREVRANGE', KEYS[1], '+', '-', 'COUNT', ARGV[1])
+if #messages == 0 then
+	return false
+end
+return messages[#messages][1]
+`)
+
+func (s *stream) NthLastID(ctx context.Context, count int64) (string, error) {
+	id, err := nthLastIDScript.Run(ctx, s.cache.Client, []string{s.name}, count).Text()
+	if err == xredis.Nil {
+		return "", nil
+	}
+
+	return id, err
+}
+
+// PositionAt returns the position of the stream right before the messages added at or after the time. Reading from
+// the position returns these messages.
+func PositionAt(t time.Time) string {
+	pos, _ := PositionBefore(fmt.Sprintf("%d-0", t.UnixMilli()))
+	return pos
+}
+
+// PositionBefore returns the position of the stream right before the message id. Reading from the position returns
+// the message.
+func PositionBefore(id string) (string, error) {
+	var ms, seq uint64
+	if _, err := fmt.Sscanf(id, "%d-%d", &ms, &seq); err != nil {
+		return "", fmt.Errorf("invalid stream id '%s'", id)
+	}
+
+	switch {
+	case seq > 0:
+		return fmt.Sprintf("%d-%d", ms, seq-1), nil
+	case ms > 0:
+		return fmt.Sprintf("%d-%d", ms-1, uint64(math.MaxUint64)), nil
+	default:
+		return string(ReadGroupPosStart), nil
+	}
+}
+
 func encodeToStreamValue(event *internal.StreamData) (map[string]any, error) {
 	enc, err := internal.EncodeStreamData(event)
 	if err != nil {
@@ -29,8 +29,15 @@ import (
 )
 
 func TestStream(t *testing.T) {
+	testStream(t, NewCache(config.GetTestCacheConfig()))
+}
+
+func TestMemoryStream(t *testing.T) {
+	testStream(t, newMemoryCache())
+}
+
+func testStream(t *testing.T, r Cache) {
 	ctx := context.TODO()
-	r := NewCache(config.GetTestCacheConfig())
 
 	t.Run("add_read", func(t *testing.T) {
 		stream, err := r.CreateOrGetStream(context.TODO(), "test")
@@ -70,6 +77,139 @@ func TestStream(t *testing.T) {
 		require.Equal(t, "first", groups[1].Name)
 		require.Equal(t, "second", groups[2].Name)
 	})
+	t.Run("read_group", func(t *testing.T) {
+		stream, err := r.CreateOrGetStream(context.TODO(), "test")
+		require.NoError(t, err)
+		defer func() {
+			_ = stream.Delete(ctx)
+		}()
+
+		require.NoError(t, stream.CreateConsumerGroup(ctx, "watch", ConsumerGroupDefaultCurrentPos))
+		require.Error(t, stream.CreateConsumerGroup(ctx, "watch", "0"))
+
+		var ids []string
+		for i := 0; i < 3; i++ {
+			id, err := stream.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte(fmt.Sprint(i))))
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		messages, hasData, err := stream.ReadGroup(ctx, "watch", ReadGroupPosCurrent)
+		require.NoError(t, err)
+		require.True(t, hasData)
+		require.Len(t, messages.Messages, 3)
+
+		group, exists, err := stream.GetConsumerGroup(ctx, "watch")
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Equal(t, ids[2], group.LastDeliveredID)
+		require.Equal(t, int64(3), group.Pending)
+
+		// the messages not acknowledged are read again from the start
+		require.NoError(t, stream.Ack(ctx, "watch", ids[0], ids[1]))
+		messages, _, err = stream.ReadGroup(ctx, "watch", ReadGroupPosStart)
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 1)
+		require.Equal(t, ids[2], messages.Messages[0].ID)
+
+		// moving the group back delivers the messages again
+		require.NoError(t, stream.SetID(ctx, "watch", ids[0]))
+		messages, _, err = stream.ReadGroup(ctx, "watch", ReadGroupPosCurrent)
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 2)
+		require.Equal(t, ids[1], messages.Messages[0].ID)
+
+		require.NoError(t, stream.RemoveConsumerGroup(ctx, "watch"))
+		_, exists, err = stream.GetConsumerGroup(ctx, "watch")
+		require.NoError(t, err)
+		require.False(t, exists)
+	})
+	t.Run("read_blocking", func(t *testing.T) {
+		stream, err := r.CreateOrGetStream(context.TODO(), "test")
+		require.NoError(t, err)
+		defer func() {
+			_ = stream.Delete(ctx)
+		}()
+
+		read := make(chan *StreamMessages)
+		go func() {
+			messages, _, _ := stream.Read(ctx, ConsumerGroupDefaultCurrentPos)
+			read <- messages
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+		id, err := stream.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte("hello")))
+		require.NoError(t, err)
+
+		messages := <-read
+		require.NotNil(t, messages)
+		require.Len(t, messages.Messages, 1)
+		require.Equal(t, id, messages.Messages[0].ID)
+	})
+	t.Run("trim_read_last", func(t *testing.T) {
+		stream, err := r.CreateOrGetStream(context.TODO(), "test")
+		require.NoError(t, err)
+		defer func() {
+			_ = stream.Delete(ctx)
+		}()
+
+		var ids []string
+		for i := 0; i < 5; i++ {
+			id, err := stream.Add(ctx, internal.NewStreamData(internal.JsonEncoding, nil, []byte(fmt.Sprint(i))))
+			require.NoError(t, err)
+			ids = append(ids, id)
+		}
+
+		last, err := stream.ReadLast(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, last, 2)
+		require.Equal(t, ids[4], last[0].ID)
+		require.Equal(t, ids[3], last[1].ID)
+
+		nth, err := stream.NthLastID(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, ids[3], nth)
+		nth, err = stream.NthLastID(ctx, 100)
+		require.NoError(t, err)
+		require.Equal(t, ids[0], nth)
+
+		pos, err := PositionBefore(ids[3])
+		require.NoError(t, err)
+		messages, _, err := stream.Read(ctx, pos)
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 2)
+
+		trimmed, err := stream.Trim(ctx, 0, ids[2])
+		require.NoError(t, err)
+		require.Equal(t, int64(2), trimmed)
+
+		trimmed, err = stream.Trim(ctx, 1, "")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), trimmed)
+		messages, _, err = stream.Read(ctx, "0")
+		require.NoError(t, err)
+		require.Len(t, messages.Messages, 1)
+		require.Equal(t, ids[4], messages.Messages[0].ID)
+	})
+}
+
+func TestStreamPosition(t *testing.T) {
+	pos, err := PositionBefore("1000-5")
+	require.NoError(t, err)
+	require.Equal(t, "1000-4", pos)
+
+	pos, err = PositionBefore("1000-0")
+	require.NoError(t, err)
+	require.Equal(t, "999-18446744073709551615", pos)
+
+	pos, err = PositionBefore("0-0")
+	require.NoError(t, err)
+	require.Equal(t, "0-0", pos)
+
+	_, err = PositionBefore("$")
+	require.Error(t, err)
+
+	require.Equal(t, "1675209599999-18446744073709551615", PositionAt(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)))
 }
 
 func TestBenchmarkingStreams(t *testing.T) {
@@ -70,6 +70,7 @@ type Tx interface {
 	Rollback(context.Context) error
 	IsRetriable() bool
 	RangeSize(ctx context.Context, table []byte, lkey Key, rkey Key) (int64, error)
+	DeleteRange(ctx context.Context, table []byte, lkey Key, rkey Key) error
 }
 
 type TxStore interface {
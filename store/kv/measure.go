@@ -216,6 +216,14 @@ func (m *TxImplWithMetrics) RangeSize(ctx context.Context, table []byte, lkey Ke
 	return
 }
 
+func (m *TxImplWithMetrics) DeleteRange(ctx context.Context, table []byte, lkey Key, rkey Key) (err error) {
+	m.measure(ctx, "DeleteRange", func() error {
+		err = m.tx.DeleteRange(ctx, table, lkey, rkey)
+		return err
+	})
+	return
+}
+
 func (m *TxImplWithMetrics) Commit(ctx context.Context) (err error) {
 	m.measure(ctx, "Commit", func() error {
 		err = m.tx.Commit(ctx)
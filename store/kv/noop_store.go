@@ -107,3 +107,7 @@ func (*NoopKV) GetMetadata(_ context.Context, _ []byte, _ Key) (*internal.TableD
 func (*NoopKV) RangeSize(_ context.Context, _ []byte, _ Key, _ Key) (int64, error) {
 	return 0, nil
 }
+
+func (*NoopKV) DeleteRange(_ context.Context, _ []byte, _ Key, _ Key) error {
+	return nil
+}
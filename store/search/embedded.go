@@ -0,0 +1,963 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+	"github.com/tigrisdata/tigris/internal"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/store/kv"
+)
+
+const (
+	embeddedSchemaKey   = "schema"
+	embeddedDocKey      = "doc"
+	embeddedPostingKey  = "posting"
+	embeddedSynonymKey  = "synonym"
+	embeddedCurationKey = "curation"
+
+	// embeddedLoadBatch is the number of keys read in a single transaction when loading the indexes.
+	embeddedLoadBatch = 1000
+	// embeddedWriteBatch and embeddedWriteBytes bound the documents written in a single transaction, so that a batch
+	// stays well within the size and the duration limits of a transaction.
+	embeddedWriteBatch = 500
+	embeddedWriteBytes = 2 * 1024 * 1024
+
+	// embeddedVersionCheckInterval is how often the searches check whether the indexes have been changed by another
+	// server, the writes always check it.
+	embeddedVersionCheckInterval = time.Second
+	embeddedVersionKey           = "version"
+)
+
+// embeddedTable is the table of the embedded store, the table names of the standalone search indexes are starting
+// with a number after the prefix, so they don't overlap with it. The keys of the table are,
+//
+//	<index>, "schema" => schema of the index
+//	<index>, "doc", <id> => document in the search format
+//	<index>, "posting", <field>, <term>, <id> => number of occurrences of the term in the field of the document
+//	<index>, "synonym", <id> => synonym of the index
+//	<index>, "curation", <id> => curation rule of the index
+var embeddedTable = append(append([]byte{}, internal.SearchTableKeyPrefix...), []byte("_embedded")...)
+
+// embeddedVersionTable holds the version of the embedded store, which is incremented by every transaction writing to
+// the embedded table.
+var embeddedVersionTable = append(append([]byte{}, internal.SearchTableKeyPrefix...), []byte("_version")...)
+
+// embeddedStore is a search store running inside the server process. The indexes are kept in memory and persisted in
+// the key-value store, they are loaded from it on the first request. If no key-value store is passed then the indexes
+// are only in memory.
+//
+// Several servers can share the key-value store, each server reloads all the indexes when it sees that another one has
+// changed them. The reload is expensive, so the embedded store is meant for a single server, or for a few servers
+// with a low write rate.
+type embeddedStore struct {
+	kv kv.TxStore
+
+	mu        sync.RWMutex
+	loaded    bool
+	version   int64
+	checkedAt time.Time
+	indexes   map[string]*embeddedIndex
+}
+
+func NewEmbeddedStore(kvStore kv.TxStore, withMetrics bool) Store {
+	log.Info().Bool("persisted", kvStore != nil).Msg("initialized embedded search store")
+
+	store := &embeddedStore{
+		kv:      kvStore,
+		indexes: make(map[string]*embeddedIndex),
+	}
+
+	if withMetrics {
+		return &storeImplWithMetrics{
+			store,
+		}
+	}
+
+	return store
+}
+
+// lock acquires the write lock, the caller needs to call unlock even if an error is returned.
+func (s *embeddedStore) lock(ctx context.Context) error {
+	s.mu.Lock()
+	return s.sync(ctx, true)
+}
+
+func (s *embeddedStore) unlock() {
+	s.mu.Unlock()
+}
+
+// rlock acquires the read lock, the lock is released if an error is returned.
+func (s *embeddedStore) rlock(ctx context.Context) error {
+	s.mu.RLock()
+	if s.loaded && time.Since(s.checkedAt) < embeddedVersionCheckInterval {
+		return nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	err := s.sync(ctx, false)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	return nil
+}
+
+// sync loads the indexes if they aren't loaded yet, or reloads them if another server has changed them. The version is
+// only checked once per embeddedVersionCheckInterval unless force is set.
+func (s *embeddedStore) sync(ctx context.Context, force bool) error {
+	if !s.loaded || s.kv == nil || (!force && time.Since(s.checkedAt) < embeddedVersionCheckInterval) {
+		return s.load(ctx)
+	}
+
+	version, err := s.readVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version == s.version {
+		s.checkedAt = time.Now()
+		return nil
+	}
+
+	log.Info().Int64("version", version).Msg("embedded search indexes changed, reloading them")
+	s.loaded = false
+
+	return s.load(ctx)
+}
+
+func (s *embeddedStore) readVersion(ctx context.Context) (int64, error) {
+	tx, err := s.kv.BeginTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	return getVersion(ctx, tx)
+}
+
+// getVersion returns the version of the embedded store, reading it in the transaction makes it conflict with the
+// transactions of the other servers writing to the embedded table.
+func getVersion(ctx context.Context, tx kv.Tx) (int64, error) {
+	it, err := tx.Read(ctx, embeddedVersionTable, kv.BuildKey(embeddedVersionKey), false)
+	if err != nil {
+		return 0, err
+	}
+
+	var row kv.KeyValue
+	if !it.Next(&row) {
+		return 0, it.Err()
+	}
+
+	return strconv.ParseInt(string(row.Data.RawData), 10, 64)
+}
+
+func (s *embeddedStore) load(ctx context.Context) error {
+	if s.loaded {
+		return nil
+	}
+	if s.kv == nil {
+		s.loaded = true
+		return nil
+	}
+
+	// the version is read first, so that the changes made while loading trigger another load
+	version, err := s.readVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.indexes = make(map[string]*embeddedIndex)
+	var from kv.Key
+	for {
+		last, count, err := s.loadBatch(ctx, from)
+		if err != nil {
+			s.indexes = make(map[string]*embeddedIndex)
+			return err
+		}
+		if count < embeddedLoadBatch {
+			break
+		}
+		from = last
+	}
+
+	s.loaded, s.version, s.checkedAt = true, version, time.Now()
+	log.Info().Int("indexes", len(s.indexes)).Msg("loaded embedded search indexes")
+
+	return nil
+}
+
+// loadBatch reads the keys after "from" in a single transaction and returns the last key read.
+func (s *embeddedStore) loadBatch(ctx context.Context, from kv.Key) (kv.Key, int, error) {
+	tx, err := s.kv.BeginTx(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	it, err := tx.ReadRange(ctx, embeddedTable, from, nil, true, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		row   kv.KeyValue
+		count int
+		last  = from
+	)
+	for count < embeddedLoadBatch && it.Next(&row) {
+		// the range is starting from the last key of the previous batch
+		if from != nil && reflect.DeepEqual(row.Key, from) {
+			continue
+		}
+		if err = s.loadRow(&row); err != nil {
+			return nil, 0, err
+		}
+
+		last = row.Key
+		count++
+	}
+
+	return last, count, it.Err()
+}
+
+func (s *embeddedStore) loadRow(row *kv.KeyValue) error {
+	if len(row.Key) < 2 {
+		return NewSearchError(http.StatusInternalServerError, ErrCodeUnhandled, "unexpected key in search table '%v'", row.Key)
+	}
+
+	name, _ := row.Key[0].(string)
+	idx, ok := s.indexes[name]
+	if !ok {
+		// documents are read before the schema, it is set when the schema key is read
+		idx = newEmbeddedIndex(&schema.StoreSchema{Name: name})
+		s.indexes[name] = idx
+	}
+
+	switch kind, _ := row.Key[1].(string); {
+	case kind == embeddedSchemaKey:
+		var sch schema.StoreSchema
+		if err := jsoniter.Unmarshal(row.Data.RawData, &sch); err != nil {
+			return err
+		}
+		idx.setSchema(&sch)
+	case kind == embeddedDocKey && len(row.Key) == 3:
+		id, _ := row.Key[2].(string)
+		fields, err := decodeDoc(row.Data.RawData)
+		if err != nil {
+			return err
+		}
+		idx.docs[id] = &embeddedDoc{raw: row.Data.RawData, fields: fields}
+	case kind == embeddedPostingKey && len(row.Key) == 5:
+		field, _ := row.Key[2].(string)
+		term, _ := row.Key[3].(string)
+		id, _ := row.Key[4].(string)
+		count, err := strconv.Atoi(string(row.Data.RawData))
+		if err != nil {
+			return err
+		}
+		idx.addPosting(field, term, id, count)
+	case kind == embeddedSynonymKey && len(row.Key) == 3:
+		var synonym Synonym
+		if err := jsoniter.Unmarshal(row.Data.RawData, &synonym); err != nil {
+			return err
+		}
+		idx.synonyms[synonym.Id] = &synonym
+	case kind == embeddedCurationKey && len(row.Key) == 3:
+		var curation Curation
+		if err := jsoniter.Unmarshal(row.Data.RawData, &curation); err != nil {
+			return err
+		}
+		idx.curations[curation.Id] = &curation
+	default:
+		return NewSearchError(http.StatusInternalServerError, ErrCodeUnhandled, "unexpected key in search table '%v'", row.Key)
+	}
+
+	return nil
+}
+
+// persist runs the function in a transaction of the key-value store and increments the version of the store. It fails
+// if the indexes have been changed by another server since they were loaded, they are reloaded on the next request.
+func (s *embeddedStore) persist(ctx context.Context, fn func(tx kv.Tx) error) error {
+	if s.kv == nil {
+		return nil
+	}
+
+	tx, err := s.kv.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	version, err := getVersion(ctx, tx)
+	if err == nil && version != s.version {
+		s.loaded = false
+		err = NewSearchError(http.StatusConflict, ErrCodeUnhandled, "search indexes changed concurrently, retry the request")
+	}
+	if err == nil {
+		err = fn(tx)
+	}
+	if err == nil {
+		data := internal.NewTableData([]byte(strconv.FormatInt(version+1, 10)))
+		err = tx.Replace(ctx, embeddedVersionTable, kv.BuildKey(embeddedVersionKey), data, false)
+	}
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	s.version = version + 1
+
+	return nil
+}
+
+// persistDocs writes the documents in as many transactions as needed to stay within the limits of a transaction. The
+// changes of each transaction are applied in memory once it is committed, so that the indexes match the key-value
+// store even if a later transaction fails.
+func (s *embeddedStore) persistDocs(ctx context.Context, idx *embeddedIndex, ids []string, docs map[string]*embeddedDoc,
+	apply func(id string),
+) error {
+	for _, batch := range writeBatches(ids, func(id string) int { return writeSize(idx.docs[id], docs[id]) }) {
+		err := s.persist(ctx, func(tx kv.Tx) error {
+			for _, id := range batch {
+				if err := writeDoc(ctx, tx, idx, id, idx.docs[id], docs[id]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range batch {
+			apply(id)
+		}
+	}
+
+	return nil
+}
+
+// writeBatches splits the ids in batches of at most embeddedWriteBatch ids and about embeddedWriteBytes bytes.
+func writeBatches(ids []string, sizeOf func(string) int) [][]string {
+	var (
+		batches [][]string
+		start   int
+		size    int
+	)
+	for i, id := range ids {
+		n := sizeOf(id)
+		if i > start && (i-start >= embeddedWriteBatch || size+n > embeddedWriteBytes) {
+			batches = append(batches, ids[start:i])
+			start, size = i, 0
+		}
+		size += n
+	}
+	if start < len(ids) {
+		batches = append(batches, ids[start:])
+	}
+
+	return batches
+}
+
+// writeSize estimates the bytes written to replace the existing document by the new one, the postings of a document
+// are about the size of the document.
+func writeSize(existing *embeddedDoc, doc *embeddedDoc) int {
+	size := 0
+	if existing != nil {
+		size += len(existing.raw)
+	}
+	if doc != nil {
+		size += 2 * len(doc.raw)
+	}
+
+	return size
+}
+
+func writeSchema(ctx context.Context, tx kv.Tx, sch *schema.StoreSchema) error {
+	raw, err := jsoniter.Marshal(sch)
+	if err != nil {
+		return err
+	}
+
+	return tx.Replace(ctx, embeddedTable, kv.BuildKey(sch.Name, embeddedSchemaKey), internal.NewTableData(raw), false)
+}
+
+// writeDoc replaces the existing document and its postings by the new document, the document is deleted if the new
+// document is nil.
+func writeDoc(ctx context.Context, tx kv.Tx, idx *embeddedIndex, id string, existing *embeddedDoc, doc *embeddedDoc) error {
+	name := idx.schema.Name
+	if existing != nil {
+		for field, terms := range idx.docTerms(existing.fields) {
+			for term := range terms {
+				if err := tx.Delete(ctx, embeddedTable, kv.BuildKey(name, embeddedPostingKey, field, term, id)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Delete(ctx, embeddedTable, kv.BuildKey(name, embeddedDocKey, id)); err != nil {
+			return err
+		}
+	}
+
+	if doc == nil {
+		return nil
+	}
+
+	if err := tx.Replace(ctx, embeddedTable, kv.BuildKey(name, embeddedDocKey, id), internal.NewTableData(doc.raw), false); err != nil {
+		return err
+	}
+
+	return writePostings(ctx, tx, name, id, idx.docTerms(doc.fields))
+}
+
+func writePostings(ctx context.Context, tx kv.Tx, name string, id string, terms map[string]map[string]int) error {
+	for field, fieldTerms := range terms {
+		for term, count := range fieldTerms {
+			data := internal.NewTableData([]byte(strconv.Itoa(count)))
+			if err := tx.Replace(ctx, embeddedTable, kv.BuildKey(name, embeddedPostingKey, field, term, id), data, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *embeddedStore) index(name string) (*embeddedIndex, error) {
+	idx, ok := s.indexes[name]
+	if !ok {
+		return nil, NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Not Found")
+	}
+
+	return idx, nil
+}
+
+func copySchema(sch *schema.StoreSchema) *schema.StoreSchema {
+	copied := *sch
+	copied.Fields = append([]schema.StoreField{}, sch.Fields...)
+	copied.TokenSeparators = append([]string{}, sch.TokenSeparators...)
+
+	return &copied
+}
+
+func (s *embeddedStore) AllCollections(ctx context.Context) (map[string]*schema.StoreSchema, error) {
+	if err := s.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.RUnlock()
+
+	all := make(map[string]*schema.StoreSchema, len(s.indexes))
+	for name, idx := range s.indexes {
+		all[name] = copySchema(idx.schema)
+	}
+
+	return all, nil
+}
+
+func (s *embeddedStore) DescribeCollection(ctx context.Context, name string) (*schema.StoreSchema, error) {
+	if err := s.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.RUnlock()
+
+	idx, err := s.index(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return copySchema(idx.schema), nil
+}
+
+func (s *embeddedStore) CreateCollection(ctx context.Context, sch *schema.StoreSchema) error {
+	defer s.unlock()
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+
+	if _, ok := s.indexes[sch.Name]; ok {
+		return NewSearchError(http.StatusConflict, ErrCodeDuplicate, "A collection with name `%s` already exists.", sch.Name)
+	}
+
+	sch = copySchema(sch)
+	if err := s.persist(ctx, func(tx kv.Tx) error { return writeSchema(ctx, tx, sch) }); err != nil {
+		return err
+	}
+
+	s.indexes[sch.Name] = newEmbeddedIndex(sch)
+
+	return nil
+}
+
+func (s *embeddedStore) UpdateCollection(ctx context.Context, name string, fields []schema.StoreField) error {
+	defer s.unlock()
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+
+	idx, err := s.index(name)
+	if err != nil {
+		return err
+	}
+
+	updated := copySchema(idx.schema)
+	var dropped, added []string
+	for _, f := range fields {
+		if !f.Drop {
+			continue
+		}
+
+		pos := -1
+		for i, existing := range updated.Fields {
+			if existing.Name == f.Name {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			return NewSearchError(http.StatusBadRequest, ErrCodeInvalid, "Field `%s` is not part of collection schema.", f.Name)
+		}
+		if isTextField(updated.Fields[pos]) {
+			dropped = append(dropped, f.Name)
+		}
+		updated.Fields = append(updated.Fields[:pos], updated.Fields[pos+1:]...)
+	}
+
+	for _, f := range fields {
+		if f.Drop {
+			continue
+		}
+
+		for _, existing := range updated.Fields {
+			if existing.Name == f.Name {
+				return NewSearchError(http.StatusBadRequest, ErrCodeInvalid, "Field `%s` is already part of the schema.", f.Name)
+			}
+		}
+		if isTextField(f) {
+			added = append(added, f.Name)
+		}
+		updated.Fields = append(updated.Fields, f)
+	}
+
+	// the terms of the existing documents for the new string fields
+	addedTerms := make(map[string]map[string]map[string]int)
+	for id, doc := range idx.docs {
+		for _, f := range added {
+			if terms := idx.terms(doc.fields, f); len(terms) > 0 {
+				if addedTerms[id] == nil {
+					addedTerms[id] = make(map[string]map[string]int)
+				}
+				addedTerms[id][f] = terms
+			}
+		}
+	}
+
+	err = s.persist(ctx, func(tx kv.Tx) error {
+		for _, f := range dropped {
+			if err := tx.Delete(ctx, embeddedTable, kv.BuildKey(name, embeddedPostingKey, f)); err != nil {
+				return err
+			}
+		}
+		for id, terms := range addedTerms {
+			if err := writePostings(ctx, tx, name, id, terms); err != nil {
+				return err
+			}
+		}
+
+		return writeSchema(ctx, tx, updated)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range dropped {
+		delete(idx.postings, f)
+	}
+	idx.setSchema(updated)
+	for id, terms := range addedTerms {
+		for field, fieldTerms := range terms {
+			for term, count := range fieldTerms {
+				idx.addPosting(field, term, id, count)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *embeddedStore) DropCollection(ctx context.Context, table string) error {
+	defer s.unlock()
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.index(table); err != nil {
+		return err
+	}
+
+	if err := s.persist(ctx, func(tx kv.Tx) error { return tx.Delete(ctx, embeddedTable, kv.BuildKey(table)) }); err != nil {
+		return err
+	}
+
+	delete(s.indexes, table)
+
+	return nil
+}
+
+func (s *embeddedStore) CreateDocument(ctx context.Context, table string, doc map[string]any) error {
+	raw, err := jsoniter.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.IndexDocuments(ctx, table, bytes.NewReader(raw), IndexDocumentsOptions{Action: Create})
+	if err != nil {
+		return err
+	}
+	if len(resp) == 1 && !resp[0].Success {
+		code := ErrCodeInvalid
+		switch resp[0].Code {
+		case http.StatusConflict:
+			code = ErrCodeDuplicate
+		case http.StatusNotFound:
+			code = ErrCodeNotFound
+		}
+		return NewSearchError(resp[0].Code, code, resp[0].Error)
+	}
+
+	return nil
+}
+
+func (s *embeddedStore) IndexDocuments(ctx context.Context, table string, documents io.Reader, options IndexDocumentsOptions) ([]IndexResp, error) {
+	defer s.unlock()
+	if err := s.lock(ctx); err != nil {
+		return nil, err
+	}
+
+	idx, err := s.index(table)
+	if err != nil {
+		return nil, err
+	}
+
+	// the new state of the documents, later lines of the batch see the changes of the previous lines
+	pending := make(map[string]*embeddedDoc)
+	var order []string
+	current := func(id string) *embeddedDoc {
+		if doc, ok := pending[id]; ok {
+			return doc
+		}
+		return idx.docs[id]
+	}
+
+	var responses []IndexResp
+	reader := bufio.NewReader(documents)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, readErr
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			id, doc, resp := indexDocument(line, options.Action, current)
+			if resp.Success {
+				if _, ok := pending[id]; !ok {
+					order = append(order, id)
+				}
+				pending[id] = doc
+			}
+			responses = append(responses, resp)
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	err = s.persistDocs(ctx, idx, order, pending, func(id string) {
+		idx.put(id, pending[id], idx.docTerms(pending[id].fields))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// indexDocument applies the action on a single line of the batch and returns the new state of the document.
+func indexDocument(line []byte, action IndexAction, current func(string) *embeddedDoc) (string, *embeddedDoc, IndexResp) {
+	failed := func(code int, msg string, args ...any) (string, *embeddedDoc, IndexResp) {
+		return "", nil, IndexResp{
+			Code:     code,
+			Document: string(line),
+			Error:    NewSearchError(code, ErrCodeIndexingDocuments, msg, args...).Error(),
+		}
+	}
+
+	fields, err := decodeDoc(line)
+	if err != nil {
+		return failed(http.StatusBadRequest, "Bad JSON.")
+	}
+
+	id, ok := fields[schema.SearchId].(string)
+	if !ok {
+		return failed(http.StatusBadRequest, "Document's `id` field should be a string.")
+	}
+
+	existing := current(id)
+	switch action {
+	case Create:
+		if existing != nil {
+			return failed(http.StatusConflict, "A document with id %s already exists.", id)
+		}
+	case Update:
+		if existing == nil {
+			return failed(http.StatusNotFound, "Could not find a document with id: %s", id)
+		}
+
+		merged := make(map[string]any, len(existing.fields))
+		for k, v := range existing.fields {
+			merged[k] = v
+		}
+		for k, v := range fields {
+			if v == nil {
+				delete(merged, k)
+			} else {
+				merged[k] = v
+			}
+		}
+		fields = merged
+	}
+
+	raw, err := jsoniter.Marshal(fields)
+	if err != nil {
+		return failed(http.StatusBadRequest, "Bad JSON.")
+	}
+
+	// decode again so that the document doesn't share values with the caller
+	if fields, err = decodeDoc(raw); err != nil {
+		return failed(http.StatusBadRequest, "Bad JSON.")
+	}
+
+	return id, &embeddedDoc{raw: raw, fields: fields}, IndexResp{Success: true}
+}
+
+func (s *embeddedStore) DeleteDocument(ctx context.Context, table string, key string) error {
+	defer s.unlock()
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+
+	idx, err := s.index(table)
+	if err != nil {
+		return err
+	}
+
+	existing, ok := idx.docs[key]
+	if !ok {
+		return NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Could not find a document with id: %s", key)
+	}
+
+	if err = s.persist(ctx, func(tx kv.Tx) error { return writeDoc(ctx, tx, idx, key, existing, nil) }); err != nil {
+		return err
+	}
+
+	idx.remove(key)
+
+	return nil
+}
+
+func (s *embeddedStore) DeleteDocuments(ctx context.Context, table string, filter *filter.WrappedFilter) (int, error) {
+	defer s.unlock()
+	if err := s.lock(ctx); err != nil {
+		return 0, err
+	}
+
+	idx, err := s.index(table)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	for id, doc := range idx.docs {
+		if filter == nil || matchesFilter(filter.Filter, doc.fields) {
+			ids = append(ids, id)
+		}
+	}
+
+	removed := 0
+	err = s.persistDocs(ctx, idx, ids, map[string]*embeddedDoc{}, func(id string) {
+		idx.remove(id)
+		removed++
+	})
+	if err != nil {
+		return removed, err
+	}
+
+	return len(ids), nil
+}
+
+func (s *embeddedStore) Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) ([]Result, error) {
+	if err := s.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.RUnlock()
+
+	idx, err := s.index(table)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := idx.search(query, pageNo)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Result{*result}, nil
+}
+
+func (s *embeddedStore) GetDocuments(ctx context.Context, table string, ids []string) (*Result, error) {
+	if err := s.rlock(ctx); err != nil {
+		return nil, err
+	}
+	defer s.mu.RUnlock()
+
+	idx, err := s.index(table)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, id := range ids {
+		doc, ok := idx.docs[id]
+		if !ok {
+			continue
+		}
+
+		hit, err := (&embeddedHit{id: id, doc: doc}).toHit()
+		if err != nil {
+			return nil, err
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	result.Found = int64(len(result.Hits))
+
+	return result, nil
+}
+
+func (s *embeddedStore) UpsertSynonym(ctx context.Context, table string, synonym *Synonym) error {
+	copied := *synonym
+	copied.Synonyms = append([]string{}, synonym.Synonyms...)
+
+	return upsertRule(ctx, s, table, embeddedSynonymKey, copied.Id, &copied, synonymsOf)
+}
+
+func (s *embeddedStore) DeleteSynonym(ctx context.Context, table string, id string) error {
+	return deleteRule(ctx, s, table, embeddedSynonymKey, id, synonymsOf)
+}
+
+func (s *embeddedStore) UpsertCuration(ctx context.Context, table string, curation *Curation) error {
+	if curation.Match != CurationExactMatch && curation.Match != CurationContainsMatch {
+		return NewSearchError(http.StatusBadRequest, ErrCodeInvalid, "Rule match should be either `exact` or `contains`.")
+	}
+
+	copied := *curation
+	copied.Pinned = append([]PinnedDocument{}, curation.Pinned...)
+	copied.Hidden = append([]string{}, curation.Hidden...)
+
+	return upsertRule(ctx, s, table, embeddedCurationKey, copied.Id, &copied, curationsOf)
+}
+
+func (s *embeddedStore) DeleteCuration(ctx context.Context, table string, id string) error {
+	return deleteRule(ctx, s, table, embeddedCurationKey, id, curationsOf)
+}
+
+func synonymsOf(idx *embeddedIndex) map[string]*Synonym {
+	return idx.synonyms
+}
+
+func curationsOf(idx *embeddedIndex) map[string]*Curation {
+	return idx.curations
+}
+
+// upsertRule persists the synonym or the curation rule and then adds it to the rules of the index.
+func upsertRule[T any](ctx context.Context, s *embeddedStore, table string, kind string, id string, rule *T, rules func(*embeddedIndex) map[string]*T) error {
+	defer s.unlock()
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+
+	idx, err := s.index(table)
+	if err != nil {
+		return err
+	}
+
+	raw, err := jsoniter.Marshal(rule)
+	if err != nil {
+		return err
+	}
+
+	err = s.persist(ctx, func(tx kv.Tx) error {
+		return tx.Replace(ctx, embeddedTable, kv.BuildKey(table, kind, id), internal.NewTableData(raw), false)
+	})
+	if err != nil {
+		return err
+	}
+
+	rules(idx)[id] = rule
+
+	return nil
+}
+
+func deleteRule[T any](ctx context.Context, s *embeddedStore, table string, kind string, id string, rules func(*embeddedIndex) map[string]*T) error {
+	defer s.unlock()
+	if err := s.lock(ctx); err != nil {
+		return err
+	}
+
+	idx, err := s.index(table)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := rules(idx)[id]; !ok {
+		return NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Not Found")
+	}
+
+	if err = s.persist(ctx, func(tx kv.Tx) error { return tx.Delete(ctx, embeddedTable, kv.BuildKey(table, kind, id)) }); err != nil {
+		return err
+	}
+
+	delete(rules(idx), id)
+
+	return nil
+}
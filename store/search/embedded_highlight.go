@@ -0,0 +1,184 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	qsearch "github.com/tigrisdata/tigris/query/search"
+)
+
+// embeddedWord is a word of a text with its position, the token is the word as it is indexed.
+type embeddedWord struct {
+	start int
+	end   int
+	token string
+}
+
+// words splits the text the same way as tokenize but keeps the positions of the words in the text.
+func (idx *embeddedIndex) words(text string) []embeddedWord {
+	var words []embeddedWord
+	start := -1
+	for i := 0; i <= len(text); {
+		r, size := utf8.RuneError, 1
+		if i < len(text) {
+			r, size = utf8.DecodeRuneInString(text[i:])
+		}
+
+		_, isSeparator := idx.separators[r]
+		if i == len(text) || unicode.IsSpace(r) || isSeparator {
+			if start >= 0 {
+				if tokens := idx.tokenize(text[start:i]); len(tokens) > 0 {
+					words = append(words, embeddedWord{start: start, end: i, token: tokens[0]})
+				}
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+		i += size
+	}
+
+	return words
+}
+
+// highlight returns the highlights of the fields having the tokens of the query, the tokens are matched like for
+// the text match i.e. the last token as a prefix and with typos.
+func (idx *embeddedIndex) highlight(query *qsearch.Query, doc map[string]any) []Highlight {
+	if !query.IsHighlighted() || query.Q == "*" {
+		return nil
+	}
+	tokens := idx.tokenize(query.Q)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	fields := append([]string{}, query.Highlight.Fields...)
+	if len(fields) == 0 {
+		fields = append(fields, query.SearchFields...)
+	}
+	if len(fields) == 0 {
+		fields = idx.textFields()
+	}
+	full := make(map[string]struct{})
+	for _, f := range query.Highlight.FullFields {
+		full[f] = struct{}{}
+		fields = mergeFields(fields, []string{f})
+	}
+
+	var highlights []Highlight
+	for _, field := range fields {
+		v, ok := fieldValue(doc, field)
+		if !ok {
+			continue
+		}
+		_, isArray := v.([]any)
+
+		h := Highlight{Field: field}
+		for _, item := range flattenValue(v) {
+			text, ok := item.(string)
+			if !ok {
+				continue
+			}
+
+			words := idx.words(text)
+			matched, first := matchWords(tokens, words)
+			if first < 0 {
+				continue
+			}
+			for i, w := range words {
+				if matched[i] {
+					h.MatchedTokens = mergeFields(h.MatchedTokens, []string{text[w.start:w.end]})
+				}
+			}
+
+			n := query.Highlight.GetSnippetLength()
+			snippet := highlightWords(text, words[maxInt(first-n, 0):minInt(first+n+1, len(words))],
+				matched[maxInt(first-n, 0):], query.Highlight)
+			if isArray {
+				h.Snippets = append(h.Snippets, snippet)
+				continue
+			}
+
+			h.Snippet = snippet
+			if _, ok := full[field]; ok {
+				h.Value = highlightWords(text, words, matched, query.Highlight)
+			}
+		}
+
+		if len(h.MatchedTokens) > 0 {
+			highlights = append(highlights, h)
+		}
+	}
+
+	sort.Slice(highlights, func(i, j int) bool {
+		return highlights[i].Field < highlights[j].Field
+	})
+
+	return highlights
+}
+
+// matchWords returns the words matching any of the tokens and the position of the first one, -1 if none is matching.
+func matchWords(tokens []string, words []embeddedWord) ([]bool, int) {
+	matched, first := make([]bool, len(words)), -1
+	for i, w := range words {
+		for ti, token := range tokens {
+			if _, ok := matchTerm([]rune(token), w.token, ti == len(tokens)-1); ok {
+				matched[i] = true
+				break
+			}
+		}
+		if matched[i] && first < 0 {
+			first = i
+		}
+	}
+
+	return matched, first
+}
+
+// highlightWords returns the part of the text from the first to the last of the words, the matched words are wrapped
+// in the highlight tags.
+func highlightWords(text string, words []embeddedWord, matched []bool, highlight *qsearch.Highlight) string {
+	if len(words) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	pos := words[0].start
+	for i, w := range words {
+		b.WriteString(text[pos:w.start])
+		if matched[i] {
+			b.WriteString(highlight.GetPreTag())
+			b.WriteString(text[w.start:w.end])
+			b.WriteString(highlight.GetPostTag())
+		} else {
+			b.WriteString(text[w.start:w.end])
+		}
+		pos = w.end
+	}
+
+	return b.String()
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,293 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+const (
+	searchStringType      = "string"
+	searchStringArrayType = "string[]"
+)
+
+// embeddedIndex is the in-memory state of an index of the embedded store. The documents are kept in the format they
+// are indexed i.e. with flattened objects, and the string fields are tokenized in an inverted index.
+type embeddedIndex struct {
+	schema     *schema.StoreSchema
+	fields     map[string]schema.StoreField
+	separators map[rune]struct{}
+	// docs is keyed by the document id.
+	docs map[string]*embeddedDoc
+	// postings maps a field to its terms and then to the id of the documents having the term, the value is the number
+	// of occurrences of the term in the field of the document.
+	postings  map[string]map[string]map[string]int
+	synonyms  map[string]*Synonym
+	curations map[string]*Curation
+}
+
+type embeddedDoc struct {
+	raw    []byte
+	fields map[string]any
+}
+
+func newEmbeddedIndex(sch *schema.StoreSchema) *embeddedIndex {
+	idx := &embeddedIndex{
+		docs:      make(map[string]*embeddedDoc),
+		postings:  make(map[string]map[string]map[string]int),
+		synonyms:  make(map[string]*Synonym),
+		curations: make(map[string]*Curation),
+	}
+	idx.setSchema(sch)
+
+	return idx
+}
+
+func (idx *embeddedIndex) setSchema(sch *schema.StoreSchema) {
+	idx.schema = sch
+	idx.fields = make(map[string]schema.StoreField)
+	for _, f := range sch.Fields {
+		idx.fields[f.Name] = f
+	}
+
+	idx.separators = make(map[rune]struct{})
+	for _, sep := range sch.TokenSeparators {
+		for _, r := range sep {
+			idx.separators[r] = struct{}{}
+		}
+	}
+}
+
+// isTextField returns true if the text of the field is in the inverted index.
+func isTextField(f schema.StoreField) bool {
+	return (f.Type == searchStringType || f.Type == searchStringArrayType) && (f.Index == nil || *f.Index)
+}
+
+func (idx *embeddedIndex) textFields() []string {
+	var fields []string
+	for _, f := range idx.schema.Fields {
+		if isTextField(f) {
+			fields = append(fields, f.Name)
+		}
+	}
+
+	return fields
+}
+
+// tokenize splits the text on spaces and on the token separators of the index. The tokens are lower cased and the
+// special characters are removed from them.
+func (idx *embeddedIndex) tokenize(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		if unicode.IsSpace(r) {
+			return true
+		}
+		_, ok := idx.separators[r]
+		return ok
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		token := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, w)
+		if len(token) > 0 {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens
+}
+
+// terms returns the number of occurrences of the terms of the string field of the document.
+func (idx *embeddedIndex) terms(doc map[string]any, field string) map[string]int {
+	v, ok := fieldValue(doc, field)
+	if !ok {
+		return nil
+	}
+
+	terms := make(map[string]int)
+	for _, item := range flattenValue(v) {
+		if str, ok := item.(string); ok {
+			for _, t := range idx.tokenize(str) {
+				terms[t]++
+			}
+		}
+	}
+
+	return terms
+}
+
+// docTerms returns the terms of all the string fields of the document.
+func (idx *embeddedIndex) docTerms(doc map[string]any) map[string]map[string]int {
+	all := make(map[string]map[string]int)
+	for _, f := range idx.textFields() {
+		if terms := idx.terms(doc, f); len(terms) > 0 {
+			all[f] = terms
+		}
+	}
+
+	return all
+}
+
+func (idx *embeddedIndex) put(id string, doc *embeddedDoc, terms map[string]map[string]int) {
+	idx.remove(id)
+
+	idx.docs[id] = doc
+	for field, fieldTerms := range terms {
+		for term, count := range fieldTerms {
+			idx.addPosting(field, term, id, count)
+		}
+	}
+}
+
+func (idx *embeddedIndex) remove(id string) {
+	existing, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for field, fieldTerms := range idx.docTerms(existing.fields) {
+		for term := range fieldTerms {
+			idx.removePosting(field, term, id)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+func (idx *embeddedIndex) addPosting(field string, term string, id string, count int) {
+	fieldPostings, ok := idx.postings[field]
+	if !ok {
+		fieldPostings = make(map[string]map[string]int)
+		idx.postings[field] = fieldPostings
+	}
+
+	ids, ok := fieldPostings[term]
+	if !ok {
+		ids = make(map[string]int)
+		fieldPostings[term] = ids
+	}
+	ids[id] = count
+}
+
+func (idx *embeddedIndex) removePosting(field string, term string, id string) {
+	ids := idx.postings[field][term]
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(idx.postings[field], term)
+	}
+}
+
+// decodeDoc decodes the document the same way as the documents returned by the search store are decoded i.e. the
+// numbers are kept as json.Number.
+func decodeDoc(raw []byte) (map[string]any, error) {
+	decoder := jsoniter.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// fieldValue returns the value of the field. The objects are flattened in the document except the arrays of objects,
+// so the values of a field nested in an array of objects are collected from all the objects.
+func fieldValue(doc map[string]any, name string) (any, bool) {
+	if v, ok := doc[name]; ok {
+		return v, true
+	}
+
+	for i := strings.Index(name, schema.ObjFlattenDelimiter); i > 0; {
+		if arr, ok := doc[name[:i]].([]any); ok {
+			var values []any
+			for _, item := range arr {
+				if obj, ok := item.(map[string]any); ok {
+					if v, ok := fieldValue(obj, name[i+1:]); ok {
+						values = append(values, flattenValue(v)...)
+					}
+				}
+			}
+			return values, len(values) > 0
+		}
+
+		next := strings.Index(name[i+1:], schema.ObjFlattenDelimiter)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	return nil, false
+}
+
+// flattenValue returns the items of an array or the value itself.
+func flattenValue(v any) []any {
+	if arr, ok := v.([]any); ok {
+		return arr
+	}
+
+	return []any{v}
+}
+
+// editDistance returns the number of insertions, deletions, substitutions or transpositions needed to change a into b.
+// Any distance greater than the limit is returned as limit+1.
+func editDistance(a []rune, b []rune, limit int) int {
+	if diff := len(a) - len(b); diff > limit || -diff > limit {
+		return limit + 1
+	}
+
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = minInt(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	if prev[len(b)] > limit {
+		return limit + 1
+	}
+	return prev[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
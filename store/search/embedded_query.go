@@ -0,0 +1,1078 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/tigrisdata/tigris/lib/date"
+	"github.com/tigrisdata/tigris/lib/geo"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	qsort "github.com/tigrisdata/tigris/query/sort"
+	"github.com/tigrisdata/tigris/schema"
+	"github.com/tigrisdata/tigris/value"
+)
+
+const (
+	defaultPerPage    = 10
+	defaultGroupLimit = 3
+
+	exactMatchScore  = 100
+	prefixMatchScore = 80
+	typoPenalty      = 30
+	maxTermCount     = 10
+
+	// maxSynonymVariants limits the number of queries the synonyms can expand to.
+	maxSynonymVariants = 16
+)
+
+type embeddedHit struct {
+	id             string
+	doc            *embeddedDoc
+	textMatch      *int64
+	vectorDistance *float64
+	matchedFields  []string
+}
+
+func (h *embeddedHit) toHit() (Hit, error) {
+	// decode again as the caller can modify the document
+	doc, err := decodeDoc(h.doc.raw)
+	if err != nil {
+		return Hit{}, err
+	}
+
+	return Hit{
+		Document:       doc,
+		TextMatch:      h.textMatch,
+		VectorDistance: h.vectorDistance,
+		MatchedFields:  h.matchedFields,
+	}, nil
+}
+
+func (idx *embeddedIndex) search(query *qsearch.Query, pageNo int) (*Result, error) {
+	hits, err := idx.candidates(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// the facets having their own filter are counted on the candidates
+	candidates := hits
+	if !query.HasNoSearchFilter() {
+		hits = filterHits(query.WrappedF, candidates)
+	}
+
+	// pinned documents are removed here and added back at their position after sorting, the groups are not curated
+	curations := idx.matchingCurations(query.Q)
+	pinning := len(curations) > 0 && !query.IsGroupByQuery()
+	hits = idx.hideCurated(hits, curations, pinning)
+
+	if query.IsVectorSearch() {
+		if hits, err = idx.nearest(query, hits); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Result{}
+	if result.Facets, err = idx.facets(query, hits, func(f *qsearch.FacetField) []*embeddedHit {
+		return idx.hideCurated(filterHits(f.WrappedF, candidates), curations, pinning)
+	}); err != nil {
+		return nil, err
+	}
+
+	if err = idx.sortHits(query, hits); err != nil {
+		return nil, err
+	}
+	if pinning {
+		hits = idx.pinCurated(hits, curations)
+	}
+
+	perPage := query.PageSize
+	if perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	if pageNo <= 0 {
+		pageNo = 1
+	}
+	start, end := (pageNo-1)*perPage, pageNo*perPage
+
+	if query.IsGroupByQuery() {
+		groups, err := idx.group(query, hits)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Found = int64(len(groups))
+		result.Groups = page(groups, start, end)
+		return result, nil
+	}
+
+	result.Found = int64(len(hits))
+	for _, h := range page(hits, start, end) {
+		hit, err := h.toHit()
+		if err != nil {
+			return nil, err
+		}
+		hit.Highlights = idx.highlight(query, h.doc.fields)
+		result.Hits = append(result.Hits, hit)
+	}
+
+	return result, nil
+}
+
+// filterHits returns the hits matching the filter in a new slice.
+func filterHits(wrappedF *filter.WrappedFilter, hits []*embeddedHit) []*embeddedHit {
+	if wrappedF == nil || wrappedF.None() {
+		return hits
+	}
+
+	filtered := make([]*embeddedHit, 0, len(hits))
+	for _, h := range hits {
+		if matchesFilter(wrappedF.Filter, h.doc.fields) {
+			filtered = append(filtered, h)
+		}
+	}
+
+	return filtered
+}
+
+func page[T any](items []T, start int, end int) []T {
+	if start >= len(items) {
+		return nil
+	}
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return items[start:end]
+}
+
+// candidates returns all the documents if the query has no text, otherwise the documents matching the text.
+func (idx *embeddedIndex) candidates(query *qsearch.Query) ([]*embeddedHit, error) {
+	tokens := idx.tokenize(query.Q)
+	if query.Q == "*" || len(tokens) == 0 {
+		hits := make([]*embeddedHit, 0, len(idx.docs))
+		for id, doc := range idx.docs {
+			hits = append(hits, &embeddedHit{id: id, doc: doc})
+		}
+		return hits, nil
+	}
+
+	fields := query.SearchFields
+	if len(fields) == 0 {
+		fields = idx.textFields()
+	}
+	for _, f := range fields {
+		storeField, ok := idx.fields[f]
+		if !ok {
+			return nil, NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Could not find a field named `%s` in the schema.", f)
+		}
+		if !isTextField(storeField) {
+			return nil, NewSearchError(http.StatusBadRequest, ErrCodeInvalid, "Field `%s` should be a string or a string array.", f)
+		}
+	}
+
+	if hits := idx.matchVariants(idx.expandSynonyms(tokens), fields); len(hits) > 0 {
+		return hits, nil
+	}
+
+	// drop the tokens from the end until some documents are matching
+	for n := len(tokens) - 1; n > 0; n-- {
+		if hits := idx.matchTokens(tokens[:n], fields); len(hits) > 0 {
+			return hits, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// expandSynonyms returns the tokens of the query and the variants of it where a synonym is replacing a word or a
+// phrase of the query.
+func (idx *embeddedIndex) expandSynonyms(tokens []string) [][]string {
+	variants := [][]string{tokens}
+	for _, id := range sortedKeys(idx.synonyms) {
+		synonym := idx.synonyms[id]
+
+		targets := make([][]string, 0, len(synonym.Synonyms))
+		for _, s := range synonym.Synonyms {
+			if t := idx.tokenize(s); len(t) > 0 {
+				targets = append(targets, t)
+			}
+		}
+
+		// a multi-way synonym is replacing any of its words, a one-way synonym is only replacing the root
+		sources := targets
+		if len(synonym.Root) > 0 {
+			sources = [][]string{idx.tokenize(synonym.Root)}
+		}
+
+		for _, from := range sources {
+			pos := indexOfPhrase(tokens, from)
+			if pos < 0 {
+				continue
+			}
+
+			for _, to := range targets {
+				if equalTokens(from, to) {
+					continue
+				}
+
+				variant := append(append(append([]string{}, tokens[:pos]...), to...), tokens[pos+len(from):]...)
+				if variants = append(variants, variant); len(variants) >= maxSynonymVariants {
+					return variants
+				}
+			}
+		}
+	}
+
+	return variants
+}
+
+// matchVariants returns the documents matching any of the variants of the query with the best text match.
+func (idx *embeddedIndex) matchVariants(variants [][]string, fields []string) []*embeddedHit {
+	if len(variants) == 1 {
+		return idx.matchTokens(variants[0], fields)
+	}
+
+	var hits []*embeddedHit
+	byId := make(map[string]*embeddedHit)
+	for _, variant := range variants {
+		for _, h := range idx.matchTokens(variant, fields) {
+			existing, ok := byId[h.id]
+			if !ok {
+				byId[h.id] = h
+				hits = append(hits, h)
+				continue
+			}
+
+			if *h.textMatch > *existing.textMatch {
+				*existing.textMatch = *h.textMatch
+			}
+			existing.matchedFields = mergeFields(existing.matchedFields, h.matchedFields)
+		}
+	}
+
+	return hits
+}
+
+// indexOfPhrase returns the position of the phrase in the tokens, or -1 if the tokens don't contain the phrase.
+func indexOfPhrase(tokens []string, phrase []string) int {
+	if len(phrase) == 0 {
+		return -1
+	}
+
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		if equalTokens(tokens[i:i+len(phrase)], phrase) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func equalTokens(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// matchingCurations returns the curation rules applying to the text of the query.
+func (idx *embeddedIndex) matchingCurations(q string) []*Curation {
+	tokens := idx.tokenize(q)
+	if q == "*" || len(tokens) == 0 {
+		return nil
+	}
+
+	var curations []*Curation
+	for _, id := range sortedKeys(idx.curations) {
+		curation := idx.curations[id]
+		ruleTokens := idx.tokenize(curation.Query)
+
+		switch curation.Match {
+		case CurationExactMatch:
+			if equalTokens(tokens, ruleTokens) {
+				curations = append(curations, curation)
+			}
+		case CurationContainsMatch:
+			if indexOfPhrase(tokens, ruleTokens) >= 0 {
+				curations = append(curations, curation)
+			}
+		}
+	}
+
+	return curations
+}
+
+// hideCurated removes the hidden documents of the curation rules, the pinned documents are also removed if they are
+// going to be added back by pinCurated.
+func (*embeddedIndex) hideCurated(hits []*embeddedHit, curations []*Curation, pinning bool) []*embeddedHit {
+	if len(curations) == 0 {
+		return hits
+	}
+
+	removed := make(map[string]struct{})
+	for _, c := range curations {
+		for _, id := range c.Hidden {
+			removed[id] = struct{}{}
+		}
+		if pinning {
+			for _, p := range c.Pinned {
+				removed[p.Id] = struct{}{}
+			}
+		}
+	}
+
+	filtered := hits[:0]
+	for _, h := range hits {
+		if _, ok := removed[h.id]; !ok {
+			filtered = append(filtered, h)
+		}
+	}
+
+	return filtered
+}
+
+// pinCurated adds the pinned documents of the curation rules at their position in the sorted hits.
+func (idx *embeddedIndex) pinCurated(hits []*embeddedHit, curations []*Curation) []*embeddedHit {
+	var pinned []PinnedDocument
+	seen := make(map[string]struct{})
+	for _, c := range curations {
+		for _, p := range c.Pinned {
+			if _, ok := seen[p.Id]; ok {
+				continue
+			}
+			seen[p.Id] = struct{}{}
+			pinned = append(pinned, p)
+		}
+	}
+	sort.SliceStable(pinned, func(i, j int) bool {
+		return pinned[i].Position < pinned[j].Position
+	})
+
+	for _, p := range pinned {
+		doc, ok := idx.docs[p.Id]
+		if !ok {
+			continue
+		}
+
+		pos := p.Position - 1
+		if pos < 0 {
+			pos = 0
+		}
+		if pos > len(hits) {
+			pos = len(hits)
+		}
+
+		hits = append(hits, nil)
+		copy(hits[pos+1:], hits[pos:])
+		hits[pos] = &embeddedHit{id: p.Id, doc: doc}
+	}
+
+	return hits
+}
+
+// matchTokens returns the documents having all the tokens in any of the fields. The last token is matched as a prefix
+// and the tokens are matched with typos depending on their length.
+func (idx *embeddedIndex) matchTokens(tokens []string, fields []string) []*embeddedHit {
+	var matched map[string]*embeddedHit
+	for i, token := range tokens {
+		isLast := i == len(tokens)-1
+		tokenRunes := []rune(token)
+
+		scores := make(map[string]int64)
+		matchedFields := make(map[string][]string)
+		for fi, field := range fields {
+			// the fields are weighted by their order in the query
+			weight := int64(len(fields) - fi)
+			for term, ids := range idx.postings[field] {
+				quality, ok := matchTerm(tokenRunes, term, isLast)
+				if !ok {
+					continue
+				}
+
+				for id, count := range ids {
+					if matched != nil && matched[id] == nil {
+						continue
+					}
+
+					score := int64(quality)*weight + int64(minInt(count, maxTermCount))
+					if score > scores[id] {
+						scores[id] = score
+					}
+					if names := matchedFields[id]; len(names) == 0 || names[len(names)-1] != field {
+						matchedFields[id] = append(names, field)
+					}
+				}
+			}
+		}
+
+		next := make(map[string]*embeddedHit, len(scores))
+		for id, score := range scores {
+			h := matched[id]
+			if h == nil {
+				var zero int64
+				h = &embeddedHit{id: id, doc: idx.docs[id], textMatch: &zero}
+			}
+			*h.textMatch += score
+			h.matchedFields = mergeFields(h.matchedFields, matchedFields[id])
+			next[id] = h
+		}
+		matched = next
+	}
+
+	hits := make([]*embeddedHit, 0, len(matched))
+	for _, h := range matched {
+		hits = append(hits, h)
+	}
+
+	return hits
+}
+
+func mergeFields(existing []string, fields []string) []string {
+	for _, f := range fields {
+		found := false
+		for _, e := range existing {
+			if e == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, f)
+		}
+	}
+
+	return existing
+}
+
+// allowedTypos returns the number of typos tolerated for a token, short tokens need to match exactly.
+func allowedTypos(token []rune) int {
+	switch {
+	case len(token) < 4:
+		return 0
+	case len(token) < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matchTerm returns the quality of the match of the token with the term of the index.
+func matchTerm(token []rune, term string, prefix bool) (int, bool) {
+	if string(token) == term {
+		return exactMatchScore, true
+	}
+
+	typos := allowedTypos(token)
+	termRunes := []rune(term)
+	if d := editDistance(token, termRunes, typos); d <= typos {
+		return exactMatchScore - typoPenalty*d, true
+	}
+	if prefix && len(termRunes) > len(token) {
+		if d := editDistance(token, termRunes[:len(token)], typos); d <= typos {
+			return prefixMatchScore - typoPenalty*d, true
+		}
+	}
+
+	return 0, false
+}
+
+// matchesFilter applies the filter on the document. Only the filters that are sent to the search backend are applied,
+// the other filters are applied by the caller on the returned documents.
+func matchesFilter(f filter.Filter, doc map[string]any) bool {
+	switch conv := f.(type) {
+	case *filter.AndFilter:
+		for _, child := range conv.GetFilters() {
+			if !matchesFilter(child, doc) {
+				return false
+			}
+		}
+		return true
+	case *filter.OrFilter:
+		for _, child := range conv.GetFilters() {
+			if matchesFilter(child, doc) {
+				return true
+			}
+		}
+		return false
+	case *filter.Selector:
+		return matchesSelector(conv, doc)
+	case *filter.GeoFilter:
+		v, ok := fieldValue(doc, conv.Field.InMemoryName())
+		if !ok {
+			return false
+		}
+		p, ok := toPoint(v)
+		return ok && conv.Contains(p)
+	default:
+		return true
+	}
+}
+
+func matchesSelector(s *filter.Selector, doc map[string]any) bool {
+	op := s.Matcher.Type()
+	switch op {
+	case filter.EQ, filter.GT, filter.GTE, filter.LT, filter.LTE:
+	default:
+		return true
+	}
+
+	v, ok := fieldValue(doc, s.Field.InMemoryName())
+	if !ok || v == nil {
+		return false
+	}
+
+	caseInsensitive := s.Collation != nil && s.Collation.IsCaseInsensitive()
+	for _, expected := range selectorValues(s) {
+		found := false
+		for _, item := range flattenValue(v) {
+			cmp, ok := compareValues(item, expected, caseInsensitive)
+			if ok && matchesOp(op, cmp) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selectorValues returns the values of the filter in the format of the indexed documents, all of them need to match.
+func selectorValues(s *filter.Selector) []any {
+	v := s.Matcher.GetValue()
+	switch s.Field.DataType {
+	case schema.DoubleType, schema.DecimalType:
+		return []any{json.Number(v.String())}
+	case schema.DateTimeType:
+		if nsec, err := date.ToUnixNano(schema.DateTimeFormat, v.String()); err == nil {
+			return []any{nsec}
+		}
+	case schema.ArrayType:
+		if _, ok := v.(*value.ArrayValue); ok {
+			return v.AsInterface().([]any)
+		}
+	}
+
+	return []any{v.AsInterface()}
+}
+
+func matchesOp(op string, cmp int) bool {
+	switch op {
+	case filter.GT:
+		return cmp > 0
+	case filter.GTE:
+		return cmp >= 0
+	case filter.LT:
+		return cmp < 0
+	case filter.LTE:
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// compareValues compares the value of a document with a value of the same type, false is returned if the values can't
+// be compared.
+func compareValues(a any, b any, caseInsensitive bool) (int, bool) {
+	if aStr, ok := a.(string); ok {
+		bStr, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		if caseInsensitive {
+			aStr, bStr = strings.ToLower(aStr), strings.ToLower(bStr)
+		}
+		return strings.Compare(aStr, bStr), true
+	}
+
+	if aBool, ok := a.(bool); ok {
+		bBool, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case aBool == bBool:
+			return 0, true
+		case bBool:
+			return -1, true
+		default:
+			return 1, true
+		}
+	}
+
+	aInt, aIsInt := toInt(a)
+	bInt, bIsInt := toInt(b)
+	if aIsInt && bIsInt {
+		switch {
+		case aInt < bInt:
+			return -1, true
+		case aInt > bInt:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	aFloat, aOk := toFloat(a)
+	bFloat, bOk := toFloat(b)
+	if !aOk || !bOk {
+		return 0, false
+	}
+	switch {
+	case aFloat < bFloat:
+		return -1, true
+	case aFloat > bFloat:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func toInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case int64:
+		return n, true
+	case int32:
+		return int64(n), true
+	case int:
+		return int64(n), true
+	}
+
+	return 0, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	}
+
+	if i, ok := toInt(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
+// toPoint converts a geopoint of the indexed document i.e. [<lat>, <lon>].
+func toPoint(v any) (geo.Point, bool) {
+	arr, ok := v.([]any)
+	if !ok || len(arr) != 2 {
+		return geo.Point{}, false
+	}
+
+	lat, latOk := toFloat(arr[0])
+	lon, lonOk := toFloat(arr[1])
+	return geo.Point{Lat: lat, Lon: lon}, latOk && lonOk
+}
+
+// nearest computes the distance of the documents from the vector of the query, the documents without a vector are
+// skipped. Only the top k nearest documents are returned if k is set in the query.
+func (idx *embeddedIndex) nearest(query *qsearch.Query, hits []*embeddedHit) ([]*embeddedHit, error) {
+	if _, ok := idx.fields[query.VectorS.VectorF]; !ok {
+		return nil, NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Field `%s` does not have a vector query index.", query.VectorS.VectorF)
+	}
+
+	withVector := hits[:0]
+	for _, h := range hits {
+		v, ok := fieldValue(h.doc.fields, query.VectorS.VectorF)
+		if !ok {
+			continue
+		}
+
+		distance, ok := cosineDistance(query.VectorS.VectorV, v)
+		if !ok {
+			continue
+		}
+		h.vectorDistance = &distance
+		withVector = append(withVector, h)
+	}
+
+	if k := query.VectorS.TopK; k > 0 && len(withVector) > k {
+		sort.SliceStable(withVector, func(i, j int) bool {
+			return *withVector[i].vectorDistance < *withVector[j].vectorDistance
+		})
+		withVector = withVector[:k]
+	}
+
+	return withVector, nil
+}
+
+func cosineDistance(query []float64, v any) (float64, bool) {
+	arr, ok := v.([]any)
+	if !ok || len(arr) != len(query) {
+		return 0, false
+	}
+
+	var dot, normQ, normV float64
+	for i, item := range arr {
+		f, ok := toFloat(item)
+		if !ok {
+			return 0, false
+		}
+		dot += query[i] * f
+		normQ += query[i] * query[i]
+		normV += f * f
+	}
+	if normQ == 0 || normV == 0 {
+		return 1, true
+	}
+
+	return 1 - dot/(math.Sqrt(normQ)*math.Sqrt(normV)), true
+}
+
+// sortHits orders the hits by the sort fields of the query, then by the vector distance or by the text match, and
+// finally by the id to have a stable order.
+func (idx *embeddedIndex) sortHits(query *qsearch.Query, hits []*embeddedHit) error {
+	var ordering []embeddedSortField
+	if query.SortOrder != nil {
+		for _, f := range *query.SortOrder {
+			storeField, ok := idx.fields[f.Name]
+			if !ok {
+				return NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Could not find a field named `%s` in the schema for sorting.", f.Name)
+			}
+			ordering = append(ordering, embeddedSortField{field: f, storeField: storeField})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		for _, o := range ordering {
+			if cmp := o.compare(a.doc.fields, b.doc.fields); cmp != 0 {
+				return cmp < 0
+			}
+		}
+
+		if a.vectorDistance != nil && b.vectorDistance != nil && *a.vectorDistance != *b.vectorDistance {
+			return *a.vectorDistance < *b.vectorDistance
+		}
+		if a.textMatch != nil && b.textMatch != nil && *a.textMatch != *b.textMatch {
+			return *a.textMatch > *b.textMatch
+		}
+
+		return a.id < b.id
+	})
+
+	return nil
+}
+
+type embeddedSortField struct {
+	field      qsort.SortField
+	storeField schema.StoreField
+}
+
+// compare returns a negative number if the document a comes first.
+func (o embeddedSortField) compare(a map[string]any, b map[string]any) int {
+	if o.field.Origin != nil {
+		da, okA := distanceFrom(*o.field.Origin, a, o.field.Name)
+		db, okB := distanceFrom(*o.field.Origin, b, o.field.Name)
+		return compareMissing(okA, okB, false, func() int { return compareFloat(da, db) })
+	}
+
+	va, okA := fieldValue(a, o.field.Name)
+	vb, okB := fieldValue(b, o.field.Name)
+	okA, okB = okA && va != nil, okB && vb != nil
+
+	return compareMissing(okA, okB, o.field.MissingValuesFirst, func() int {
+		cmp, _ := compareValues(va, vb, false)
+		if !o.field.Ascending {
+			return -cmp
+		}
+		return cmp
+	})
+}
+
+func compareMissing(okA bool, okB bool, missingFirst bool, compare func() int) int {
+	switch {
+	case okA && okB:
+		return compare()
+	case okA == okB:
+		return 0
+	case okA == missingFirst:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func compareFloat(a float64, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func distanceFrom(origin geo.Point, doc map[string]any, field string) (float64, bool) {
+	v, ok := fieldValue(doc, field)
+	if !ok {
+		return 0, false
+	}
+
+	p, ok := toPoint(v)
+	if !ok {
+		return 0, false
+	}
+
+	return origin.DistanceTo(p), true
+}
+
+// facets counts the values of the facet fields of the query in all the matching documents, the facets having their
+// own filter are counted on the hits returned by ownHits.
+func (idx *embeddedIndex) facets(query *qsearch.Query, hits []*embeddedHit, ownHits func(*qsearch.FacetField) []*embeddedHit) ([]Facet, error) {
+	if len(query.Facets.Fields) == 0 {
+		return nil, nil
+	}
+
+	size := query.ToSearchFacetSize()
+	facets := make([]Facet, 0, len(query.Facets.Fields))
+	for i := range query.Facets.Fields {
+		f := &query.Facets.Fields[i]
+		storeField, ok := idx.fields[f.Name]
+		if !ok || storeField.Facet == nil || !*storeField.Facet {
+			return nil, NewSearchError(http.StatusNotFound, ErrCodeNotFound, "Could not find a facet field named `%s` in the schema.", f.Name)
+		}
+
+		facetHits := hits
+		if f.HasOwnFilter() {
+			facetHits = ownHits(f)
+		}
+
+		var facet Facet
+		if f.IsRange() {
+			facet = rangeFacet(f, facetHits)
+		} else {
+			numeric := strings.HasPrefix(storeField.Type, "int") || strings.HasPrefix(storeField.Type, "float")
+			facet = valueFacet(f, facetHits, numeric, size)
+		}
+		facets = append(facets, facet)
+	}
+
+	return facets, nil
+}
+
+// valueFacet counts the documents for each value of the field, the stats of the numeric fields are on the distinct
+// values.
+func valueFacet(f *qsearch.FacetField, hits []*embeddedHit, numeric bool, size int) Facet {
+	counts := make(map[string]int64)
+	var stats *FacetStats
+	if numeric {
+		stats = &FacetStats{}
+	}
+
+	for _, h := range hits {
+		v, ok := fieldValue(h.doc.fields, f.Name)
+		if !ok || v == nil {
+			continue
+		}
+
+		for _, item := range flattenValue(v) {
+			str, ok := facetValue(item)
+			if !ok {
+				continue
+			}
+			if _, found := counts[str]; !found && stats != nil {
+				if n, ok := toFloat(item); ok {
+					stats.add(n)
+				}
+			}
+			counts[str]++
+		}
+	}
+
+	facet := Facet{Field: f.Name, Stats: stats}
+	for str, count := range counts {
+		facet.Counts = append(facet.Counts, FacetCount{Value: str, Count: count})
+	}
+	sort.Slice(facet.Counts, func(i, j int) bool {
+		if facet.Counts[i].Count != facet.Counts[j].Count {
+			return facet.Counts[i].Count > facet.Counts[j].Count
+		}
+		return facet.Counts[i].Value < facet.Counts[j].Value
+	})
+	if len(facet.Counts) > size {
+		facet.Counts = facet.Counts[:size]
+	}
+	if stats != nil {
+		stats.finish()
+	}
+
+	return facet
+}
+
+// rangeFacet counts the documents having a value in each of the ranges of the field, the counts are in the order of
+// the ranges.
+func rangeFacet(f *qsearch.FacetField, hits []*embeddedHit) Facet {
+	facet := Facet{Field: f.Name, Counts: make([]FacetCount, len(f.Ranges))}
+	for i, r := range f.Ranges {
+		facet.Counts[i].Value = r.Key
+	}
+
+	for _, h := range hits {
+		v, ok := fieldValue(h.doc.fields, f.Name)
+		if !ok || v == nil {
+			continue
+		}
+
+		for i, r := range f.Ranges {
+			// a document is counted once in a range even if several items of an array are in the range
+			for _, item := range flattenValue(v) {
+				if n, ok := toFloat(item); ok && (r.From == nil || n >= *r.From) && (r.To == nil || n < *r.To) {
+					facet.Counts[i].Count++
+					break
+				}
+			}
+		}
+	}
+
+	return facet
+}
+
+// add accounts for a distinct value of the field.
+func (s *FacetStats) add(n float64) {
+	if s.Count == 0 {
+		s.Min, s.Max, s.Sum = &n, new(float64), new(float64)
+		*s.Max = n
+	}
+	if n < *s.Min {
+		*s.Min = n
+	}
+	if n > *s.Max {
+		*s.Max = n
+	}
+	*s.Sum += n
+	s.Count++
+}
+
+func (s *FacetStats) finish() {
+	if s.Count > 0 {
+		avg := *s.Sum / float64(s.Count)
+		s.Avg = &avg
+	}
+}
+
+func facetValue(v any) (string, bool) {
+	switch conv := v.(type) {
+	case string:
+		return conv, true
+	case json.Number:
+		return conv.String(), true
+	case bool:
+		return strconv.FormatBool(conv), true
+	case float64:
+		return strconv.FormatFloat(conv, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(conv, 10), true
+	}
+
+	return "", false
+}
+
+// group collects the hits having the same values for the group by fields, the groups are ordered by their first hit.
+func (idx *embeddedIndex) group(query *qsearch.Query, hits []*embeddedHit) ([]Group, error) {
+	for _, f := range query.GroupBy.Fields {
+		storeField, ok := idx.fields[f]
+		if !ok || storeField.Facet == nil || !*storeField.Facet {
+			return nil, NewSearchError(http.StatusBadRequest, ErrCodeInvalid, "Group by field `%s` should be a facet field.", f)
+		}
+	}
+
+	limit := defaultGroupLimit
+	if query.GroupBy.Limit != nil {
+		limit = int(*query.GroupBy.Limit)
+	}
+
+	var groups []Group
+	positions := make(map[string]int)
+	for _, h := range hits {
+		key := make([]string, 0, len(query.GroupBy.Fields))
+		for _, f := range query.GroupBy.Fields {
+			key = append(key, groupKey(h.doc.fields, f))
+		}
+		encoded := strings.Join(key, "\x00")
+
+		pos, ok := positions[encoded]
+		if !ok {
+			pos = len(groups)
+			positions[encoded] = pos
+			groups = append(groups, Group{Key: key})
+		}
+		if len(groups[pos].Hits) >= limit {
+			continue
+		}
+
+		hit, err := h.toHit()
+		if err != nil {
+			return nil, err
+		}
+		hit.Highlights = idx.highlight(query, h.doc.fields)
+		groups[pos].Hits = append(groups[pos].Hits, hit)
+	}
+
+	return groups, nil
+}
+
+func groupKey(doc map[string]any, field string) string {
+	v, ok := fieldValue(doc, field)
+	if !ok || v == nil {
+		return ""
+	}
+	if str, ok := facetValue(v); ok {
+		return str
+	}
+
+	encoded, err := jsoniter.MarshalToString(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return encoded
+}
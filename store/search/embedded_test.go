@@ -0,0 +1,427 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/query/sort"
+	"github.com/tigrisdata/tigris/schema"
+)
+
+func newEmbeddedTestIndex(t *testing.T) (Store, *schema.SearchIndex) {
+	reqSchema := []byte(`{
+	"title": "products",
+	"properties": {
+		"id": {"type": "string"},
+		"title": {"type": "string"},
+		"category": {"type": "string", "facet": true},
+		"tags": {"type": "array", "items": {"type": "string"}, "facet": true},
+		"price": {"type": "number", "sort": true, "facet": true},
+		"rating": {"type": "integer", "sort": true, "facet": true},
+		"vec": {"type": "array", "format": "vector", "dimensions": 2}
+	},
+	"options": {"token_separators": ["-"]}
+}`)
+	factory, err := schema.NewFactoryBuilder(true).BuildSearch("products", reqSchema)
+	require.NoError(t, err)
+
+	index := schema.NewSearchIndex(1, "1:1:products", factory, nil)
+	store := NewEmbeddedStore(nil, false)
+	require.NoError(t, store.CreateCollection(context.TODO(), index.StoreSchema))
+
+	docs := []string{
+		`{"id": "1", "title": "Red running shoes", "category": "shoes", "tags": ["red", "sport"], "price": 50.5, "rating": 4, "vec": [1.0, 0.0]}`,
+		`{"id": "2", "title": "Blue running jacket", "category": "apparel", "tags": ["blue", "sport"], "price": 120, "rating": 5, "vec": [0.0, 1.0]}`,
+		`{"id": "3", "title": "Red t-shirt", "category": "apparel", "tags": ["red"], "price": 20, "rating": 3, "vec": [0.7, 0.7]}`,
+		`{"id": "4", "title": "Hiking boots", "category": "shoes", "tags": ["brown"], "price": 150, "rating": 5}`,
+	}
+	resp, err := store.IndexDocuments(context.TODO(), index.StoreIndexName(), strings.NewReader(strings.Join(docs, "\n")), IndexDocumentsOptions{Action: Create})
+	require.NoError(t, err)
+	require.Len(t, resp, len(docs))
+	for _, r := range resp {
+		require.True(t, r.Success, r.Error)
+	}
+
+	return store, index
+}
+
+func searchIds(t *testing.T, store Store, index *schema.SearchIndex, query *qsearch.Query) []string {
+	res, err := store.Search(context.TODO(), index.StoreIndexName(), query, 1)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+
+	var ids []string
+	for _, h := range res[0].Hits {
+		ids = append(ids, h.Document["id"].(string))
+	}
+
+	return ids
+}
+
+func TestEmbeddedStore_Text(t *testing.T) {
+	store, index := newEmbeddedTestIndex(t)
+
+	cases := []struct {
+		q   string
+		exp []string
+	}{
+		{"*", []string{"1", "2", "3", "4"}},
+		{"running", []string{"1", "2"}},
+		{"red running", []string{"1"}},
+		// typo
+		{"runnign", []string{"1", "2"}},
+		// prefix on the last token
+		{"hik", []string{"4"}},
+		// token separators of the index
+		{"shirt", []string{"3"}},
+		// trailing tokens are dropped if nothing matches
+		{"boots unknown", []string{"4"}},
+		{"unknown", nil},
+	}
+	for _, c := range cases {
+		query := qsearch.NewBuilder().Query(c.q).Build()
+		require.ElementsMatch(t, c.exp, searchIds(t, store, index, query), c.q)
+	}
+
+	res, err := store.Search(context.TODO(), index.StoreIndexName(), qsearch.NewBuilder().Query("red").Build(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res[0].Found)
+	require.NotNil(t, res[0].Hits[0].TextMatch)
+	require.Contains(t, res[0].Hits[0].MatchedFields, "title")
+}
+
+func TestEmbeddedStore_Filter(t *testing.T) {
+	store, index := newEmbeddedTestIndex(t)
+
+	cases := []struct {
+		filter string
+		exp    []string
+	}{
+		{`{"category": "shoes"}`, []string{"1", "4"}},
+		{`{"price": {"$gt": 50}}`, []string{"1", "2", "4"}},
+		{`{"rating": {"$lte": 4}}`, []string{"1", "3"}},
+		{`{"tags": "red"}`, []string{"1", "3"}},
+		{`{"$or": [{"category": "apparel"}, {"rating": 5}]}`, []string{"2", "3", "4"}},
+		{`{"$and": [{"category": "apparel"}, {"rating": 5}]}`, []string{"2"}},
+	}
+	for _, c := range cases {
+		wrapped, err := filter.NewFactory(index.QueryableFields, nil).WrappedFilter([]byte(c.filter))
+		require.NoError(t, err)
+
+		query := qsearch.NewBuilder().Filter(wrapped).Build()
+		require.ElementsMatch(t, c.exp, searchIds(t, store, index, query), c.filter)
+	}
+}
+
+func TestEmbeddedStore_SortAndPage(t *testing.T) {
+	store, index := newEmbeddedTestIndex(t)
+
+	query := qsearch.NewBuilder().SortOrder(&sort.Ordering{{Name: "price", Ascending: true}}).PageSize(3).Build()
+	require.Equal(t, []string{"3", "1", "2"}, searchIds(t, store, index, query))
+
+	res, err := store.Search(context.TODO(), index.StoreIndexName(), query, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), res[0].Found)
+	require.Len(t, res[0].Hits, 1)
+	require.Equal(t, "4", res[0].Hits[0].Document["id"])
+
+	query = qsearch.NewBuilder().SortOrder(&sort.Ordering{{Name: "rating"}, {Name: "price", Ascending: true}}).Build()
+	require.Equal(t, []string{"2", "4", "1", "3"}, searchIds(t, store, index, query))
+}
+
+func TestEmbeddedStore_Facets(t *testing.T) {
+	store, index := newEmbeddedTestIndex(t)
+
+	query := qsearch.NewBuilder().Facets(qsearch.Facets{Fields: []qsearch.FacetField{
+		{Name: "tags", Size: 2},
+		{Name: "rating", Size: 2},
+	}}).Build()
+	res, err := store.Search(context.TODO(), index.StoreIndexName(), query, 1)
+	require.NoError(t, err)
+	require.Len(t, res[0].Facets, 2)
+
+	require.Equal(t, "tags", res[0].Facets[0].Field)
+	require.Equal(t, []FacetCount{{Value: "red", Count: 2}, {Value: "sport", Count: 2}}, res[0].Facets[0].Counts)
+	require.Nil(t, res[0].Facets[0].Stats)
+
+	stats := res[0].Facets[1].Stats
+	require.NotNil(t, stats)
+	require.Equal(t, int64(3), stats.Count)
+	require.Equal(t, 3.0, *stats.Min)
+	require.Equal(t, 5.0, *stats.Max)
+	require.Equal(t, 12.0, *stats.Sum)
+	require.Equal(t, 4.0, *stats.Avg)
+
+	query = qsearch.NewBuilder().Facets(qsearch.Facets{Fields: []qsearch.FacetField{{Name: "title", Size: 10}}}).Build()
+	_, err = store.Search(context.TODO(), index.StoreIndexName(), query, 1)
+	require.Error(t, err)
+}
+
+func TestEmbeddedStore_RangeFacets(t *testing.T) {
+	store, index := newEmbeddedTestIndex(t)
+
+	facets, err := qsearch.UnmarshalFacet([]byte(`{
+		"price": {"type": "range", "ranges": [{"key": "cheap", "to": 50}, {"from": 50, "to": 150}, {"from": 150}]},
+		"category": {"exclude_filter": true}
+	}`))
+	require.NoError(t, err)
+
+	wrapped, err := filter.NewFactory(index.QueryableFields, nil).WrappedFilter([]byte(`{"category": "apparel"}`))
+	require.NoError(t, err)
+	facets.Fields[1].WrappedF = filter.WrappedEmptyFilter
+
+	query := qsearch.NewBuilder().Filter(wrapped).Facets(facets).Build()
+	res, err := store.Search(context.TODO(), index.StoreIndexName(), query, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res[0].Found)
+	require.Len(t, res[0].Facets, 2)
+
+	require.Equal(t, "price", res[0].Facets[0].Field)
+	require.Equal(t, []FacetCount{{Value: "cheap", Count: 1}, {Value: "50-150", Count: 1}, {Value: "150-*", Count: 0}}, res[0].Facets[0].Counts)
+
+	// the category is counted without its own filter
+	require.Equal(t, "category", res[0].Facets[1].Field)
+	require.Equal(t, []FacetCount{{Value: "apparel", Count: 2}, {Value: "shoes", Count: 2}}, res[0].Facets[1].Counts)
+}
+
+func TestEmbeddedStore_GroupBy(t *testing.T) {
+	store, index := newEmbeddedTestIndex(t)
+
+	limit := int64(1)
+	query := qsearch.NewBuilder().
+		SortOrder(&sort.Ordering{{Name: "price", Ascending: true}}).
+		GroupBy(qsearch.GroupBy{Fields: []string{"category"}, Limit: &limit}).
+		Build()
+	res, err := store.Search(context.TODO(), index.StoreIndexName(), query, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res[0].Found)
+	require.Len(t, res[0].Groups, 2)
+	require.Equal(t, []string{"apparel"}, res[0].Groups[0].Key)
+	require.Len(t, res[0].Groups[0].Hits, 1)
+	require.Equal(t, "3", res[0].Groups[0].Hits[0].Document["id"])
+	require.Equal(t, []string{"shoes"}, res[0].Groups[1].Key)
+}
+
+func TestEmbeddedStore_Vector(t *testing.T) {
+	store, index := newEmbeddedTestIndex(t)
+
+	query := qsearch.NewBuilder().VectorSearch(qsearch.VectorSearch{VectorF: "vec", VectorV: []float64{1, 0.1}, TopK: 2}).Build()
+	res, err := store.Search(context.TODO(), index.StoreIndexName(), query, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), res[0].Found)
+	require.Equal(t, "1", res[0].Hits[0].Document["id"])
+	require.Equal(t, "3", res[0].Hits[1].Document["id"])
+	require.Less(t, *res[0].Hits[0].VectorDistance, *res[0].Hits[1].VectorDistance)
+}
+
+func TestEmbeddedStore_Highlight(t *testing.T) {
+	store, index := newEmbeddedTestIndex(t)
+
+	snippetLength := 1
+	query := qsearch.NewBuilder().
+		Query("runnign").
+		SearchFields([]string{"title", "tags"}).
+		Highlight(&qsearch.Highlight{Fields: []string{"title"}, FullFields: []string{"category"}, PreTag: "<b>", PostTag: "</b>", SnippetLength: &snippetLength}).
+		Build()
+	res, err := store.Search(context.TODO(), index.StoreIndexName(), query, 1)
+	require.NoError(t, err)
+	require.Len(t, res[0].Hits, 2)
+	for _, h := range res[0].Hits {
+		require.Len(t, h.Highlights, 1)
+		require.Equal(t, "title", h.Highlights[0].Field)
+		require.Equal(t, []string{"running"}, h.Highlights[0].MatchedTokens)
+	}
+
+	query = qsearch.NewBuilder().
+		Query("red").
+		Highlight(&qsearch.Highlight{FullFields: []string{"title"}}).
+		SortOrder(&sort.Ordering{{Name: "price", Ascending: true}}).
+		Build()
+	res, err = store.Search(context.TODO(), index.StoreIndexName(), query, 1)
+	require.NoError(t, err)
+	require.Len(t, res[0].Hits, 2)
+	require.Equal(t, []Highlight{
+		{Field: "tags", Snippets: []string{"<mark>red</mark>"}, MatchedTokens: []string{"red"}},
+		{Field: "title", Snippet: "<mark>Red</mark> t-shirt", Value: "<mark>Red</mark> t-shirt", MatchedTokens: []string{"Red"}},
+	}, res[0].Hits[0].Highlights)
+
+	// no highlights unless requested
+	res, err = store.Search(context.TODO(), index.StoreIndexName(), qsearch.NewBuilder().Query("red").Build(), 1)
+	require.NoError(t, err)
+	require.Nil(t, res[0].Hits[0].Highlights)
+}
+
+func TestEmbeddedStore_Documents(t *testing.T) {
+	ctx := context.TODO()
+	store, index := newEmbeddedTestIndex(t)
+	name := index.StoreIndexName()
+
+	err := store.CreateDocument(ctx, name, map[string]any{"id": "1", "title": "duplicate"})
+	require.True(t, IsErrDuplicateEntity(err))
+
+	resp, err := store.IndexDocuments(ctx, name, strings.NewReader(`{"id": "1", "title": "Green running shoes"}
+{"id": "5", "price": 10}
+not a json`), IndexDocumentsOptions{Action: Update})
+	require.NoError(t, err)
+	require.Len(t, resp, 3)
+	require.True(t, resp[0].Success)
+	require.Equal(t, 404, resp[1].Code)
+	require.Equal(t, 400, resp[2].Code)
+
+	result, err := store.GetDocuments(ctx, name, []string{"1", "5"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Found)
+	require.Equal(t, "Green running shoes", result.Hits[0].Document["title"])
+	require.Equal(t, json.Number("50.5"), result.Hits[0].Document["price"])
+
+	require.ElementsMatch(t, []string{"1", "3"}, searchIds(t, store, index, qsearch.NewBuilder().Query("red").Build()))
+	require.Equal(t, []string{"1"}, searchIds(t, store, index, qsearch.NewBuilder().Query("green").Build()))
+
+	require.NoError(t, store.DeleteDocument(ctx, name, "1"))
+	require.True(t, IsErrNotFound(store.DeleteDocument(ctx, name, "1")))
+
+	wrapped, err := filter.NewFactory(index.QueryableFields, nil).WrappedFilter([]byte(`{"category": "apparel"}`))
+	require.NoError(t, err)
+	count, err := store.DeleteDocuments(ctx, name, wrapped)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Equal(t, []string{"4"}, searchIds(t, store, index, qsearch.NewBuilder().Build()))
+}
+
+func TestEmbeddedStore_Collections(t *testing.T) {
+	ctx := context.TODO()
+	store, index := newEmbeddedTestIndex(t)
+	name := index.StoreIndexName()
+
+	require.True(t, IsErrDuplicateEntity(store.CreateCollection(ctx, index.StoreSchema)))
+
+	all, err := store.AllCollections(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"-"}, all[name].TokenSeparators)
+
+	// dropping the title removes it from the text search, adding it back indexes the existing documents
+	require.NoError(t, store.UpdateCollection(ctx, name, []schema.StoreField{{Name: "title", Drop: true}}))
+	_, err = store.Search(ctx, name, qsearch.NewBuilder().Query("hiking").SearchFields([]string{"title"}).Build(), 1)
+	require.Error(t, err)
+	require.Empty(t, searchIds(t, store, index, qsearch.NewBuilder().Query("hiking").Build()))
+
+	require.NoError(t, store.UpdateCollection(ctx, name, []schema.StoreField{{Name: "title", Type: "string"}}))
+	require.Equal(t, []string{"4"}, searchIds(t, store, index, qsearch.NewBuilder().Query("hiking").Build()))
+	require.Error(t, store.UpdateCollection(ctx, name, []schema.StoreField{{Name: "title", Type: "string"}}))
+	require.Error(t, store.UpdateCollection(ctx, name, []schema.StoreField{{Name: "unknown", Drop: true}}))
+
+	require.NoError(t, store.DropCollection(ctx, name))
+	require.True(t, IsErrNotFound(store.DropCollection(ctx, name)))
+	_, err = store.DescribeCollection(ctx, name)
+	require.True(t, IsErrNotFound(err))
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		exp  int
+	}{
+		{"shoes", "shoes", 0},
+		{"shoes", "shoe", 1},
+		{"shoes", "shose", 1},
+		{"shoes", "shirts", 3},
+		{"shoes", "s", 3},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.exp, editDistance([]rune(c.a), []rune(c.b), 2), c.a+" "+c.b)
+	}
+}
+
+func TestWriteBatches(t *testing.T) {
+	ids := make([]string, 2*embeddedWriteBatch+1)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+
+	batches := writeBatches(ids, func(string) int { return 1 })
+	require.Len(t, batches, 3)
+	require.Len(t, batches[0], embeddedWriteBatch)
+	require.Len(t, batches[2], 1)
+
+	// a document larger than the limit is written alone
+	batches = writeBatches([]string{"1", "2", "3"}, func(id string) int {
+		if id == "2" {
+			return 2 * embeddedWriteBytes
+		}
+		return embeddedWriteBytes / 2
+	})
+	require.Equal(t, [][]string{{"1"}, {"2"}, {"3"}}, batches)
+
+	require.Empty(t, writeBatches(nil, func(string) int { return 1 }))
+}
+
+func TestEmbeddedStore_Synonyms(t *testing.T) {
+	ctx := context.TODO()
+	store, index := newEmbeddedTestIndex(t)
+	name := index.StoreIndexName()
+
+	require.Empty(t, searchIds(t, store, index, qsearch.NewBuilder().Query("sneakers").Build()))
+
+	require.NoError(t, store.UpsertSynonym(ctx, name, &Synonym{Id: "footwear", Synonyms: []string{"sneakers", "boots"}}))
+	require.Equal(t, []string{"4"}, searchIds(t, store, index, qsearch.NewBuilder().Query("sneakers").Build()))
+
+	// one-way synonym only matches the synonyms of the root
+	require.NoError(t, store.UpsertSynonym(ctx, name, &Synonym{Id: "top", Root: "top", Synonyms: []string{"t-shirt", "jacket"}}))
+	require.ElementsMatch(t, []string{"2", "3"}, searchIds(t, store, index, qsearch.NewBuilder().Query("top").Build()))
+	require.Equal(t, []string{"2"}, searchIds(t, store, index, qsearch.NewBuilder().Query("jacket").Build()))
+
+	require.NoError(t, store.DeleteSynonym(ctx, name, "footwear"))
+	require.Empty(t, searchIds(t, store, index, qsearch.NewBuilder().Query("sneakers").Build()))
+	require.True(t, IsErrNotFound(store.DeleteSynonym(ctx, name, "footwear")))
+}
+
+func TestEmbeddedStore_Curations(t *testing.T) {
+	ctx := context.TODO()
+	store, index := newEmbeddedTestIndex(t)
+	name := index.StoreIndexName()
+
+	require.Error(t, store.UpsertCuration(ctx, name, &Curation{Id: "invalid", Query: "red"}))
+
+	require.NoError(t, store.UpsertCuration(ctx, name, &Curation{
+		Id:     "red",
+		Query:  "red",
+		Match:  CurationExactMatch,
+		Pinned: []PinnedDocument{{Id: "4", Position: 1}},
+		Hidden: []string{"3"},
+	}))
+	require.Equal(t, []string{"4", "1"}, searchIds(t, store, index, qsearch.NewBuilder().Query("red").Build()))
+	// exact rule doesn't apply to other queries
+	require.ElementsMatch(t, []string{"1"}, searchIds(t, store, index, qsearch.NewBuilder().Query("red shoes").Build()))
+
+	require.NoError(t, store.UpsertCuration(ctx, name, &Curation{
+		Id:     "running",
+		Query:  "running",
+		Match:  CurationContainsMatch,
+		Pinned: []PinnedDocument{{Id: "2", Position: 5}},
+	}))
+	require.Equal(t, []string{"1", "2"}, searchIds(t, store, index, qsearch.NewBuilder().Query("red running").Build()))
+
+	require.NoError(t, store.DeleteCuration(ctx, name, "red"))
+	require.ElementsMatch(t, []string{"1", "3"}, searchIds(t, store, index, qsearch.NewBuilder().Query("red").Build()))
+	require.True(t, IsErrNotFound(store.DeleteCuration(ctx, name, "red")))
+}
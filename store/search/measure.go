@@ -23,10 +23,10 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/tigrisdata/tigris/query/filter"
 	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/server/config"
 	"github.com/tigrisdata/tigris/server/metrics"
 	"github.com/tigrisdata/typesense-go/typesense"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
 )
 
 type storeImplWithMetrics struct {
@@ -70,7 +70,7 @@ func (*storeImplWithMetrics) measure(ctx context.Context, name string, f func(ct
 	}
 }
 
-func (m *storeImplWithMetrics) AllCollections(ctx context.Context) (resp map[string]*tsApi.CollectionResponse, err error) {
+func (m *storeImplWithMetrics) AllCollections(ctx context.Context) (resp map[string]*schema.StoreSchema, err error) {
 	m.measure(ctx, "AllCollections", func(ctx context.Context) error {
 		resp, err = m.s.AllCollections(ctx)
 		return err
@@ -78,7 +78,7 @@ func (m *storeImplWithMetrics) AllCollections(ctx context.Context) (resp map[str
 	return
 }
 
-func (m *storeImplWithMetrics) DescribeCollection(ctx context.Context, name string) (resp *tsApi.CollectionResponse, err error) {
+func (m *storeImplWithMetrics) DescribeCollection(ctx context.Context, name string) (resp *schema.StoreSchema, err error) {
 	m.measure(ctx, "DescribeCollection", func(ctx context.Context) error {
 		resp, err = m.s.DescribeCollection(ctx, name)
 		return err
@@ -86,7 +86,7 @@ func (m *storeImplWithMetrics) DescribeCollection(ctx context.Context, name stri
 	return
 }
 
-func (m *storeImplWithMetrics) CreateCollection(ctx context.Context, schema *tsApi.CollectionSchema) (err error) {
+func (m *storeImplWithMetrics) CreateCollection(ctx context.Context, schema *schema.StoreSchema) (err error) {
 	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
 	m.measure(ctx, "CreateCollection", func(ctx context.Context) error {
 		err = m.s.CreateCollection(ctx, schema)
@@ -98,10 +98,10 @@ func (m *storeImplWithMetrics) CreateCollection(ctx context.Context, schema *tsA
 	return
 }
 
-func (m *storeImplWithMetrics) UpdateCollection(ctx context.Context, name string, schema *tsApi.CollectionUpdateSchema) (err error) {
+func (m *storeImplWithMetrics) UpdateCollection(ctx context.Context, name string, fields []schema.StoreField) (err error) {
 	// TODO: measure the bytes written in global status
 	m.measure(ctx, "UpdateCollection", func(ctx context.Context) error {
-		err = m.s.UpdateCollection(ctx, name, schema)
+		err = m.s.UpdateCollection(ctx, name, fields)
 		return err
 	})
 	return
@@ -152,7 +152,7 @@ func (m *storeImplWithMetrics) DeleteDocuments(ctx context.Context, table string
 	return
 }
 
-func (m *storeImplWithMetrics) Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) (result []tsApi.SearchResult, err error) {
+func (m *storeImplWithMetrics) Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) (result []Result, err error) {
 	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
 	m.measure(ctx, "Search", func(ctx context.Context) error {
 		result, err = m.s.Search(ctx, table, query, pageNo)
@@ -169,15 +169,13 @@ func (m *storeImplWithMetrics) Search(ctx context.Context, table string, query *
 			}
 		}
 		for _, res := range result {
-			if res.Hits != nil {
-				reqStatus.AddResultDocs(int64(len(*res.Hits)))
-			}
+			reqStatus.AddResultDocs(int64(len(res.Hits)))
 		}
 	}
 	return
 }
 
-func (m *storeImplWithMetrics) GetDocuments(ctx context.Context, table string, ids []string) (result *tsApi.SearchResult, err error) {
+func (m *storeImplWithMetrics) GetDocuments(ctx context.Context, table string, ids []string) (result *Result, err error) {
 	reqStatus, reqStatusExists := metrics.RequestStatusFromContext(ctx)
 	m.measure(ctx, "Get", func(ctx context.Context) error {
 		result, err = m.s.GetDocuments(ctx, table, ids)
@@ -202,3 +200,35 @@ func (m *storeImplWithMetrics) CreateDocument(ctx context.Context, table string,
 	})
 	return
 }
+
+func (m *storeImplWithMetrics) UpsertSynonym(ctx context.Context, table string, synonym *Synonym) (err error) {
+	m.measure(ctx, "UpsertSynonym", func(ctx context.Context) error {
+		err = m.s.UpsertSynonym(ctx, table, synonym)
+		return err
+	})
+	return
+}
+
+func (m *storeImplWithMetrics) DeleteSynonym(ctx context.Context, table string, id string) (err error) {
+	m.measure(ctx, "DeleteSynonym", func(ctx context.Context) error {
+		err = m.s.DeleteSynonym(ctx, table, id)
+		return err
+	})
+	return
+}
+
+func (m *storeImplWithMetrics) UpsertCuration(ctx context.Context, table string, curation *Curation) (err error) {
+	m.measure(ctx, "UpsertCuration", func(ctx context.Context) error {
+		err = m.s.UpsertCuration(ctx, table, curation)
+		return err
+	})
+	return
+}
+
+func (m *storeImplWithMetrics) DeleteCuration(ctx context.Context, table string, id string) (err error) {
+	m.measure(ctx, "DeleteCuration", func(ctx context.Context) error {
+		err = m.s.DeleteCuration(ctx, table, id)
+		return err
+	})
+	return
+}
@@ -0,0 +1,79 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+// Result is the response of the search store to a search request. It doesn't depend on the engine behind the store.
+type Result struct {
+	// Found is the number of documents matching the query.
+	Found int64
+	// Hits are set for the queries that are not grouped.
+	Hits []Hit
+	// Groups are set for the queries that are grouped.
+	Groups []Group
+	Facets []Facet
+}
+
+// Hit is a document matching the query.
+type Hit struct {
+	Document map[string]any
+	// TextMatch is the text match score of the document, it is nil if the query is not scored on text.
+	TextMatch *int64
+	// VectorDistance is the distance of the document from the vector of the query.
+	VectorDistance *float64
+	// MatchedFields are the names of the fields that have matched the text of the query, nested fields are using the
+	// dot notation.
+	MatchedFields []string
+	// Highlights are only set for the fields having matched tokens when the query is highlighted.
+	Highlights []Highlight
+}
+
+// Highlight is the text of a field with the tokens matching the query wrapped in the highlight tags.
+type Highlight struct {
+	Field string
+	// Snippet is the part of a string field surrounding the matched tokens. Snippets are set instead for the arrays,
+	// one for each of the matching items.
+	Snippet  string
+	Snippets []string
+	// Value is the whole highlighted field, only set for the fields that are highlighted fully.
+	Value         string
+	MatchedTokens []string
+}
+
+// Group is the hits having the same values for the group by fields.
+type Group struct {
+	Key  []string
+	Hits []Hit
+}
+
+// Facet is the facet counts and stats of a field.
+type Facet struct {
+	Field  string
+	Counts []FacetCount
+	// Stats are only set for numeric fields.
+	Stats *FacetStats
+}
+
+type FacetCount struct {
+	Value string
+	Count int64
+}
+
+type FacetStats struct {
+	Avg   *float64
+	Max   *float64
+	Min   *float64
+	Sum   *float64
+	Count int64
+}
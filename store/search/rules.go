@@ -0,0 +1,48 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+const (
+	// CurationExactMatch applies the curation when the query is same as the query of the rule.
+	CurationExactMatch = "exact"
+	// CurationContainsMatch applies the curation when the query contains the query of the rule.
+	CurationContainsMatch = "contains"
+)
+
+// Synonym is a set of words or phrases that are considered equal while searching. If Root is set then the synonym is
+// one-way i.e. searching for the root matches the synonyms but searching for the synonyms doesn't match the root.
+type Synonym struct {
+	Id       string
+	Root     string
+	Synonyms []string
+}
+
+// Curation pins or hides documents in the results of the queries matching the rule.
+type Curation struct {
+	Id string
+	// Query is matched with the text query of the search either exactly or as a phrase contained in it.
+	Query string
+	Match string
+	// Pinned documents are included in the results at the given positions.
+	Pinned []PinnedDocument
+	// Hidden is the list of the document ids that are removed from the results.
+	Hidden []string
+}
+
+// PinnedDocument is a document included by a curation, the position starts from 1.
+type PinnedDocument struct {
+	Id       string
+	Position int
+}
@@ -20,7 +20,7 @@ import (
 
 	"github.com/tigrisdata/tigris/query/filter"
 	qsearch "github.com/tigrisdata/tigris/query/search"
-	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+	"github.com/tigrisdata/tigris/schema"
 )
 
 type IndexAction string
@@ -33,13 +33,13 @@ var (
 
 type Store interface {
 	// AllCollections is to describe all search indexes.
-	AllCollections(ctx context.Context) (map[string]*tsApi.CollectionResponse, error)
+	AllCollections(ctx context.Context) (map[string]*schema.StoreSchema, error)
 	// DescribeCollection is to describe a search index.
-	DescribeCollection(ctx context.Context, name string) (*tsApi.CollectionResponse, error)
+	DescribeCollection(ctx context.Context, name string) (*schema.StoreSchema, error)
 	// CreateCollection is to create a search index.
-	CreateCollection(ctx context.Context, schema *tsApi.CollectionSchema) error
+	CreateCollection(ctx context.Context, schema *schema.StoreSchema) error
 	// UpdateCollection is to update the search index.
-	UpdateCollection(ctx context.Context, name string, schema *tsApi.CollectionUpdateSchema) error
+	UpdateCollection(ctx context.Context, name string, fields []schema.StoreField) error
 	// DropCollection is to drop the search index.
 	DropCollection(ctx context.Context, table string) error
 	// CreateDocument is to create and index a single document
@@ -51,22 +51,30 @@ type Store interface {
 	// DeleteDocuments is to delete multiple documents using filter.
 	DeleteDocuments(ctx context.Context, table string, filter *filter.WrappedFilter) (int, error)
 	// Search is to search using Query.
-	Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) ([]tsApi.SearchResult, error)
+	Search(ctx context.Context, table string, query *qsearch.Query, pageNo int) ([]Result, error)
 	// GetDocuments is to get a single or multiple documents by id.
-	GetDocuments(ctx context.Context, table string, ids []string) (*tsApi.SearchResult, error)
+	GetDocuments(ctx context.Context, table string, ids []string) (*Result, error)
+	// UpsertSynonym is to create or replace a synonym of the search index.
+	UpsertSynonym(ctx context.Context, table string, synonym *Synonym) error
+	// DeleteSynonym is to delete a synonym of the search index using id.
+	DeleteSynonym(ctx context.Context, table string, id string) error
+	// UpsertCuration is to create or replace a curation rule of the search index.
+	UpsertCuration(ctx context.Context, table string, curation *Curation) error
+	// DeleteCuration is to delete a curation rule of the search index using id.
+	DeleteCuration(ctx context.Context, table string, id string) error
 }
 
 type NoopStore struct{}
 
-func (*NoopStore) AllCollections(context.Context) (map[string]*tsApi.CollectionResponse, error) {
+func (*NoopStore) AllCollections(context.Context) (map[string]*schema.StoreSchema, error) {
 	return nil, nil
 }
 
-func (*NoopStore) DescribeCollection(context.Context, string) (*tsApi.CollectionResponse, error) {
-	return &tsApi.CollectionResponse{}, nil
+func (*NoopStore) DescribeCollection(context.Context, string) (*schema.StoreSchema, error) {
+	return &schema.StoreSchema{}, nil
 }
-func (*NoopStore) CreateCollection(context.Context, *tsApi.CollectionSchema) error { return nil }
-func (*NoopStore) UpdateCollection(context.Context, string, *tsApi.CollectionUpdateSchema) error {
+func (*NoopStore) CreateCollection(context.Context, *schema.StoreSchema) error { return nil }
+func (*NoopStore) UpdateCollection(context.Context, string, []schema.StoreField) error {
 	return nil
 }
 func (*NoopStore) DropCollection(context.Context, string) error { return nil }
@@ -78,14 +86,19 @@ func (*NoopStore) DeleteDocuments(context.Context, string, *filter.WrappedFilter
 	return 0, nil
 }
 
-func (*NoopStore) Search(context.Context, string, *qsearch.Query, int) ([]tsApi.SearchResult, error) {
+func (*NoopStore) Search(context.Context, string, *qsearch.Query, int) ([]Result, error) {
 	return nil, nil
 }
 
-func (*NoopStore) GetDocuments(_ context.Context, _ string, _ []string) (*tsApi.SearchResult, error) {
+func (*NoopStore) GetDocuments(_ context.Context, _ string, _ []string) (*Result, error) {
 	return nil, nil
 }
 
 func (*NoopStore) CreateDocument(_ context.Context, _ string, _ map[string]any) error {
 	return nil
 }
+
+func (*NoopStore) UpsertSynonym(context.Context, string, *Synonym) error   { return nil }
+func (*NoopStore) DeleteSynonym(context.Context, string, string) error     { return nil }
+func (*NoopStore) UpsertCuration(context.Context, string, *Curation) error { return nil }
+func (*NoopStore) DeleteCuration(context.Context, string, string) error    { return nil }
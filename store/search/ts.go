@@ -20,11 +20,14 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"sort"
+	"strconv"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/rs/zerolog/log"
 	"github.com/tigrisdata/tigris/query/filter"
 	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
 	"github.com/tigrisdata/tigris/util"
 	ulog "github.com/tigrisdata/tigris/util/log"
 	"github.com/tigrisdata/typesense-go/typesense"
@@ -143,32 +146,204 @@ func (*storeImpl) getBaseSearchParam(table string, query *qsearch.Query, pageNo
 	if vector := query.ToSearchVector(); len(vector) > 0 {
 		baseParam.VectorQuery = &vector
 	}
+	if query.IsHighlighted() {
+		setHighlightParams(&baseParam, query.Highlight)
+	}
 
 	return baseParam
 }
 
-func (s *storeImpl) Search(_ context.Context, table string, query *qsearch.Query, pageNo int) ([]tsApi.SearchResult, error) {
+func setHighlightParams(param *tsApi.MultiSearchCollectionParameters, highlight *qsearch.Highlight) {
+	if fields := highlight.ToSearchFields(); len(fields) > 0 {
+		param.HighlightFields = &fields
+	}
+	if fullFields := highlight.ToSearchFullFields(); len(fullFields) > 0 {
+		param.HighlightFullFields = &fullFields
+	}
+
+	preTag, postTag, snippetLength := highlight.GetPreTag(), highlight.GetPostTag(), highlight.GetSnippetLength()
+	param.HighlightStartTag = &preTag
+	param.HighlightEndTag = &postTag
+	param.HighlightAffixNumTokens = &snippetLength
+
+	// the short fields are otherwise highlighted fully instead of the snippet
+	snippetThreshold := 0
+	param.SnippetThreshold = &snippetThreshold
+}
+
+// facetSearch is a search only counting a facet, it is sent along with the search of the query.
+type facetSearch struct {
+	field *qsearch.FacetField
+	// rangeIdx is the range counted by the search for the range facets.
+	rangeIdx int
+}
+
+// getFacetSearchParams returns the searches counting the range facets, a search per range, and the facets having their
+// own filter.
+func (*storeImpl) getFacetSearchParams(table string, query *qsearch.Query) ([]tsApi.MultiSearchCollectionParameters, []facetSearch) {
+	var (
+		params   []tsApi.MultiSearchCollectionParameters
+		searches []facetSearch
+	)
+	for i := range query.Facets.Fields {
+		f := &query.Facets.Fields[i]
+		if !f.IsRange() && !f.HasOwnFilter() {
+			continue
+		}
+
+		searchFilter := query.WrappedF.SearchFilter()
+		if query.HasNoSearchFilter() {
+			searchFilter = ""
+		}
+		if f.HasOwnFilter() {
+			searchFilter = f.WrappedF.SearchFilter()
+		}
+
+		if !f.IsRange() {
+			param := getFacetSearchParam(table, query, searchFilter)
+			param.FacetBy = &f.Name
+			size := f.Size
+			param.MaxFacetValues = &size
+			params = append(params, param)
+			searches = append(searches, facetSearch{field: f})
+			continue
+		}
+
+		for ri, r := range f.Ranges {
+			rangeFilter := searchFilter
+			for _, bound := range []struct {
+				op    string
+				value *float64
+			}{{":>=", r.From}, {":<", r.To}} {
+				if bound.value == nil {
+					continue
+				}
+				if len(rangeFilter) > 0 {
+					rangeFilter += "&&"
+				}
+				rangeFilter += f.Name + bound.op + strconv.FormatFloat(*bound.value, 'f', -1, 64)
+			}
+			params = append(params, getFacetSearchParam(table, query, rangeFilter))
+			searches = append(searches, facetSearch{field: f, rangeIdx: ri})
+		}
+	}
+
+	return params, searches
+}
+
+// getFacetSearchParam returns a search with the text of the query not returning any hit.
+func getFacetSearchParam(table string, query *qsearch.Query, searchFilter string) tsApi.MultiSearchCollectionParameters {
+	pageNo, perPage := 1, 0
+	param := tsApi.MultiSearchCollectionParameters{
+		Q:          &query.Q,
+		Collection: table,
+		Page:       &pageNo,
+		PerPage:    &perPage,
+	}
+	if fields := query.ToSearchFields(); len(fields) > 0 {
+		param.QueryBy = &fields
+	}
+	if len(searchFilter) > 0 {
+		param.FilterBy = &searchFilter
+	}
+	if vector := query.ToSearchVector(); len(vector) > 0 {
+		param.VectorQuery = &vector
+	}
+
+	return param
+}
+
+// mergeFacets adds the facets counted by the facet searches to the result of the query, the facets are in the order of
+// the query.
+func mergeFacets(query *qsearch.Query, result *Result, searches []facetSearch, facetResults []Result) {
+	facets := make(map[string]Facet)
+	for _, f := range result.Facets {
+		facets[f.Field] = f
+	}
+
+	for i, search := range searches {
+		f := search.field
+		if !f.IsRange() {
+			for _, fc := range facetResults[i].Facets {
+				if fc.Field == f.Name {
+					facets[f.Name] = fc
+				}
+			}
+			continue
+		}
+
+		facet, ok := facets[f.Name]
+		if !ok {
+			facet = Facet{Field: f.Name, Counts: make([]FacetCount, len(f.Ranges))}
+			for ri, r := range f.Ranges {
+				facet.Counts[ri].Value = r.Key
+			}
+		}
+		facet.Counts[search.rangeIdx].Count = facetResults[i].Found
+		facets[f.Name] = facet
+	}
+
+	result.Facets = make([]Facet, 0, len(query.Facets.Fields))
+	for _, f := range query.Facets.Fields {
+		if facet, ok := facets[f.Name]; ok {
+			result.Facets = append(result.Facets, facet)
+		}
+	}
+}
+
+func (s *storeImpl) Search(_ context.Context, table string, query *qsearch.Query, pageNo int) ([]Result, error) {
 	var params []tsApi.MultiSearchCollectionParameters
 	params = append(params, s.getBaseSearchParam(table, query, pageNo))
 
+	facetParams, facetSearches := s.getFacetSearchParams(table, query)
+	params = append(params, facetParams...)
+
+	dest, err := s.multiSearch(params)
+	if err != nil {
+		log.Error().Err(err).Interface("query", query).Msg("search error")
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(dest.Results))
+	for i := range dest.Results {
+		results = append(results, toResult(&dest.Results[i]))
+	}
+	if len(facetSearches) > 0 && len(results) == len(params) {
+		mergeFacets(query, &results[0], facetSearches, results[1:])
+		results = results[:1]
+	}
+
+	return results, nil
+}
+
+// multiSearch performs the searches in a single request. The response is decoded with UseNumber so that the large
+// integers in the documents, like the timestamps in nanoseconds, keep their precision.
+func (s *storeImpl) multiSearch(params []tsApi.MultiSearchCollectionParameters) (*tsApi.MultiSearchResult, error) {
 	res, err := s.client.MultiSearch.PerformWithContentType(&tsApi.MultiSearchParams{
 		MaxCandidates: &maxCandidates,
 	}, tsApi.MultiSearchSearchesParameter{
 		Searches: params,
 	}, StreamContentType)
 	if err != nil {
-		log.Error().Err(err).Interface("query", query).Msg("search error")
 		return nil, s.convertToInternalError(err)
 	}
 
-	reader := bytes.NewReader(res.Body)
-	decoder := jsoniter.NewDecoder(reader)
+	dest, err := decodeMultiSearch(res.Body)
+	if err != nil {
+		return nil, s.convertToInternalError(err)
+	}
+
+	return dest, nil
+}
+
+// decodeMultiSearch decodes the response of a multi search, the numbers in the documents are decoded as json.Number.
+func decodeMultiSearch(body []byte) (*tsApi.MultiSearchResult, error) {
+	decoder := jsoniter.NewDecoder(bytes.NewReader(body))
 	decoder.UseNumber()
 
 	var dest tsApi.MultiSearchResult
 	if err := decoder.Decode(&dest); err != nil {
-		log.Error().Err(err).Interface("query", query).Msg("search error")
-		return nil, s.convertToInternalError(err)
+		return nil, err
 	}
 	for _, each := range dest.Results {
 		if each.Hits == nil && each.GroupedHits == nil {
@@ -182,47 +357,45 @@ func (s *storeImpl) Search(_ context.Context, table string, query *qsearch.Query
 			}
 
 			var errorsRes errorsResult
-			if err = jsoniter.Unmarshal(res.Body, &errorsRes); err == nil && len(errorsRes.Res) > 0 {
-				log.Error().Err(err).Interface("query", query).Msg("search error")
+			if err := jsoniter.Unmarshal(body, &errorsRes); err == nil && len(errorsRes.Res) > 0 {
 				return nil, NewSearchError(errorsRes.Res[0].Code, ErrCodeUnhandled, errorsRes.Res[0].Message)
 			}
 		}
 	}
 
-	return dest.Results, nil
+	return &dest, nil
 }
 
-func (s *storeImpl) AllCollections(_ context.Context) (map[string]*tsApi.CollectionResponse, error) {
+func (s *storeImpl) AllCollections(_ context.Context) (map[string]*schema.StoreSchema, error) {
 	resp, err := s.client.Collections().Retrieve()
 	if err != nil {
 		return nil, s.convertToInternalError(err)
 	}
 
-	respMap := make(map[string]*tsApi.CollectionResponse)
+	respMap := make(map[string]*schema.StoreSchema)
 	for _, r := range resp {
-		respMap[r.Name] = r
+		respMap[r.Name] = toStoreSchema(r)
 	}
 	return respMap, nil
 }
 
-func (s *storeImpl) DescribeCollection(_ context.Context, name string) (*tsApi.CollectionResponse, error) {
+func (s *storeImpl) DescribeCollection(_ context.Context, name string) (*schema.StoreSchema, error) {
 	resp, err := s.client.Collection(name).Retrieve()
 	if err != nil {
 		return nil, s.convertToInternalError(err)
 	}
-	return resp, nil
+	return toStoreSchema(resp), nil
 }
 
-func (s *storeImpl) CreateCollection(_ context.Context, schema *tsApi.CollectionSchema) error {
-	ptrTrue := true
-	schema.EnableNestedFields = &ptrTrue
-
-	_, err := s.client.Collections().Create(schema)
+func (s *storeImpl) CreateCollection(_ context.Context, sch *schema.StoreSchema) error {
+	_, err := s.client.Collections().Create(fromStoreSchema(sch))
 	return s.convertToInternalError(err)
 }
 
-func (s *storeImpl) UpdateCollection(_ context.Context, name string, schema *tsApi.CollectionUpdateSchema) error {
-	_, err := s.client.Collection(name).Update(schema)
+func (s *storeImpl) UpdateCollection(_ context.Context, name string, fields []schema.StoreField) error {
+	_, err := s.client.Collection(name).Update(&tsApi.CollectionUpdateSchema{
+		Fields: fromStoreFields(fields),
+	})
 	return s.convertToInternalError(err)
 }
 
@@ -231,7 +404,63 @@ func (s *storeImpl) DropCollection(_ context.Context, table string) error {
 	return s.convertToInternalError(err)
 }
 
-func (s *storeImpl) GetDocuments(_ context.Context, table string, ids []string) (*tsApi.SearchResult, error) {
+func (s *storeImpl) UpsertSynonym(_ context.Context, table string, synonym *Synonym) error {
+	var root *string
+	if len(synonym.Root) > 0 {
+		root = &synonym.Root
+	}
+
+	_, err := s.client.Collection(table).Synonyms().Upsert(synonym.Id, &tsApi.SearchSynonymSchema{
+		Root:     root,
+		Synonyms: synonym.Synonyms,
+	})
+	return s.convertToInternalError(err)
+}
+
+func (s *storeImpl) DeleteSynonym(_ context.Context, table string, id string) error {
+	_, err := s.client.Collection(table).Synonym(id).Delete()
+	return s.convertToInternalError(err)
+}
+
+func (s *storeImpl) UpsertCuration(_ context.Context, table string, curation *Curation) error {
+	_, err := s.client.Collection(table).Overrides().Upsert(curation.Id, fromCuration(curation))
+	return s.convertToInternalError(err)
+}
+
+func (s *storeImpl) DeleteCuration(_ context.Context, table string, id string) error {
+	_, err := s.client.Collection(table).Override(id).Delete()
+	return s.convertToInternalError(err)
+}
+
+// fromCuration converts the curation to an override of Typesense.
+func fromCuration(curation *Curation) *tsApi.SearchOverrideSchema {
+	override := &tsApi.SearchOverrideSchema{
+		Rule: tsApi.SearchOverrideRule{
+			Query: curation.Query,
+			Match: tsApi.SearchOverrideRuleMatch(curation.Match),
+		},
+	}
+
+	if len(curation.Pinned) > 0 {
+		includes := make([]tsApi.SearchOverrideInclude, 0, len(curation.Pinned))
+		for _, p := range curation.Pinned {
+			includes = append(includes, tsApi.SearchOverrideInclude{Id: p.Id, Position: p.Position})
+		}
+		override.Includes = &includes
+	}
+
+	if len(curation.Hidden) > 0 {
+		excludes := make([]tsApi.SearchOverrideExclude, 0, len(curation.Hidden))
+		for _, id := range curation.Hidden {
+			excludes = append(excludes, tsApi.SearchOverrideExclude{Id: id})
+		}
+		override.Excludes = &excludes
+	}
+
+	return override
+}
+
+func (s *storeImpl) GetDocuments(_ context.Context, table string, ids []string) (*Result, error) {
 	filterBy := "id: ["
 	for i, id := range ids {
 		if i != 0 {
@@ -241,10 +470,355 @@ func (s *storeImpl) GetDocuments(_ context.Context, table string, ids []string)
 	}
 	filterBy += "]"
 
-	res, err := s.client.Collection(table).Documents().Search(&tsApi.SearchCollectionParams{
-		Q:        "*",
-		FilterBy: &filterBy,
+	// the default page would only return the first documents of a batch
+	q, perPage := "*", len(ids)
+	dest, err := s.multiSearch([]tsApi.MultiSearchCollectionParameters{{
+		Collection: table,
+		Q:          &q,
+		FilterBy:   &filterBy,
+		PerPage:    &perPage,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result Result
+	if len(dest.Results) > 0 {
+		result = toResult(&dest.Results[0])
+	}
+	return &result, nil
+}
+
+const matchedTokensKey = "matched_tokens"
+
+func toStoreSchema(resp *tsApi.CollectionResponse) *schema.StoreSchema {
+	sch := &schema.StoreSchema{
+		Name:   resp.Name,
+		Fields: toStoreFields(resp.Fields),
+	}
+	if resp.TokenSeparators != nil {
+		sch.TokenSeparators = *resp.TokenSeparators
+	}
+
+	return sch
+}
+
+func toStoreFields(fields []tsApi.Field) []schema.StoreField {
+	storeFields := make([]schema.StoreField, 0, len(fields))
+	for _, f := range fields {
+		storeFields = append(storeFields, schema.StoreField{
+			Name:       f.Name,
+			Type:       f.Type,
+			Facet:      f.Facet,
+			Index:      f.Index,
+			Sort:       f.Sort,
+			Dimensions: f.NumDim,
+			Drop:       f.Drop != nil && *f.Drop,
+		})
+	}
+
+	return storeFields
+}
+
+func fromStoreSchema(sch *schema.StoreSchema) *tsApi.CollectionSchema {
+	ptrTrue := true
+	tsSchema := &tsApi.CollectionSchema{
+		Name:               sch.Name,
+		Fields:             fromStoreFields(sch.Fields),
+		EnableNestedFields: &ptrTrue,
+	}
+	if len(sch.TokenSeparators) > 0 {
+		tsSchema.TokenSeparators = &sch.TokenSeparators
+	}
+
+	return tsSchema
+}
+
+// fromStoreFields converts the fields to the search backend fields, all the fields that are added are optional as
+// documents are not required to have them.
+func fromStoreFields(fields []schema.StoreField) []tsApi.Field {
+	ptrTrue := true
+	tsFields := make([]tsApi.Field, 0, len(fields))
+	for _, f := range fields {
+		if f.Drop {
+			tsFields = append(tsFields, tsApi.Field{
+				Name: f.Name,
+				Drop: &ptrTrue,
+			})
+			continue
+		}
+
+		tsFields = append(tsFields, tsApi.Field{
+			Name:     f.Name,
+			Type:     f.Type,
+			Facet:    f.Facet,
+			Index:    f.Index,
+			Sort:     f.Sort,
+			Optional: &ptrTrue,
+			NumDim:   f.Dimensions,
+		})
+	}
+
+	return tsFields
+}
+
+func toResult(r *tsApi.SearchResult) Result {
+	var result Result
+	if r == nil {
+		return result
+	}
+
+	if r.Found != nil {
+		result.Found = int64(*r.Found)
+	}
+	if r.Hits != nil {
+		result.Hits = toHits(*r.Hits)
+	}
+	if r.GroupedHits != nil {
+		for _, g := range *r.GroupedHits {
+			result.Groups = append(result.Groups, Group{
+				Key:  g.GroupKey,
+				Hits: toHits(g.Hits),
+			})
+		}
+	}
+	if r.FacetCounts != nil {
+		result.Facets = toFacets(*r.FacetCounts)
+	}
+
+	return result
+}
+
+func toHits(tsHits []tsApi.SearchResultHit) []Hit {
+	hits := make([]Hit, 0, len(tsHits))
+	for i := range tsHits {
+		hits = append(hits, toHit(&tsHits[i]))
+	}
+
+	return hits
+}
+
+func toHit(tsHit *tsApi.SearchResultHit) Hit {
+	hit := Hit{
+		TextMatch:      tsHit.TextMatch,
+		VectorDistance: tsHit.VectorDistance,
+	}
+	if tsHit.Document != nil {
+		hit.Document = *tsHit.Document
+	}
+
+	if tsHit.Highlight != nil {
+		// check first in highlight
+		hit.MatchedFields = fromHighlight(*tsHit.Highlight)
+		hit.Highlights = toHighlights(*tsHit.Highlight)
+	} else if tsHit.Highlights != nil {
+		hit.MatchedFields = fromHighlights(*tsHit.Highlights)
+		hit.Highlights = toLegacyHighlights(*tsHit.Highlights)
+	}
+
+	return hit
+}
+
+// toHighlights converts the highlights of the string and string array fields having matched tokens, the fields are
+// sorted by name.
+func toHighlights(highlight map[string]any) []Highlight {
+	var highlights []Highlight
+	for name, value := range highlight {
+		h := Highlight{Field: name}
+		switch v := value.(type) {
+		case map[string]any:
+			if !isMatchedToken(v) {
+				continue
+			}
+			h.Snippet, _ = v["snippet"].(string)
+			h.Value, _ = v["value"].(string)
+			h.MatchedTokens = matchedTokens(v, nil)
+		case []any:
+			for _, each := range v {
+				if mp, ok := each.(map[string]any); ok && isMatchedToken(mp) {
+					snippet, _ := mp["snippet"].(string)
+					h.Snippets = append(h.Snippets, snippet)
+					h.MatchedTokens = matchedTokens(mp, h.MatchedTokens)
+				}
+			}
+			if len(h.Snippets) == 0 {
+				continue
+			}
+		default:
+			continue
+		}
+
+		highlights = append(highlights, h)
+	}
+
+	sort.Slice(highlights, func(i, j int) bool {
+		return highlights[i].Field < highlights[j].Field
 	})
 
-	return res, err
+	return highlights
+}
+
+// matchedTokens appends the matched tokens that are not in the tokens yet, the tokens of the arrays are nested.
+func matchedTokens(mp map[string]any, tokens []string) []string {
+	var add func(matched any)
+	add = func(matched any) {
+		switch m := matched.(type) {
+		case string:
+			for _, t := range tokens {
+				if t == m {
+					return
+				}
+			}
+			tokens = append(tokens, m)
+		case []any:
+			for _, each := range m {
+				add(each)
+			}
+		}
+	}
+	add(mp[matchedTokensKey])
+
+	return tokens
+}
+
+func toLegacyHighlights(tsHighlights []tsApi.SearchHighlight) []Highlight {
+	highlights := make([]Highlight, 0, len(tsHighlights))
+	for _, tsHighlight := range tsHighlights {
+		var h Highlight
+		if tsHighlight.Field != nil {
+			h.Field = *tsHighlight.Field
+		}
+		if tsHighlight.Snippet != nil {
+			h.Snippet = *tsHighlight.Snippet
+		}
+		if tsHighlight.Snippets != nil {
+			h.Snippets = *tsHighlight.Snippets
+		}
+		if tsHighlight.MatchedTokens != nil {
+			h.MatchedTokens = matchedTokens(map[string]any{matchedTokensKey: *tsHighlight.MatchedTokens}, nil)
+		}
+		highlights = append(highlights, h)
+	}
+
+	return highlights
+}
+
+func fromHighlights(highlights []tsApi.SearchHighlight) []string {
+	var fields []string
+	for _, f := range highlights {
+		name := ""
+		if f.Field != nil {
+			name = *f.Field
+		}
+		fields = append(fields, name)
+	}
+
+	return fields
+}
+
+func fromHighlight(highlight map[string]any) []string {
+	var fields []string
+	for name, value := range highlight {
+		if mp, ok := value.(map[string]any); ok {
+			// any non array match should just fall in this "if"
+			if isMatchedToken(mp) {
+				fields = append(fields, name)
+			}
+			continue
+		}
+
+		if mArr, ok := value.([]any); ok {
+			for _, each := range mArr {
+				if mp, ok := each.(map[string]any); ok {
+					if isMatchedToken(mp) {
+						// simple array
+						fields = append(fields, name)
+						break
+					}
+
+					// now this means we can have an array of objects, we iterate one level
+					// to build matched fields
+					fields = append(fields, matchedForArrObjects(name, mp)...)
+				}
+			}
+		}
+	}
+
+	return fields
+}
+
+func matchedForArrObjects(parent string, obj map[string]any) []string {
+	var fields []string
+	for k, v := range obj {
+		if mpNested, ok := v.(map[string]any); ok {
+			// nested element in an array of object is not an array
+			if isMatchedToken(mpNested) {
+				fields = append(fields, parent+"."+k)
+			}
+		} else if mpNestedArr, ok := v.([]any); ok {
+			// nested element in an array of object is an array
+			for _, eachNestedArr := range mpNestedArr {
+				if eachNested, ok := eachNestedArr.(map[string]any); ok {
+					if isMatchedToken(eachNested) {
+						fields = append(fields, parent+"."+k)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	return fields
+}
+
+func isMatchedToken(mp map[string]any) bool {
+	if matched, found := mp[matchedTokensKey]; found {
+		if matchedSlice, ok := matched.([]any); ok {
+			return len(matchedSlice) > 0
+		}
+	}
+
+	return false
+}
+
+func toFacets(facetCounts []tsApi.FacetCounts) []Facet {
+	facets := make([]Facet, 0, len(facetCounts))
+	for _, fc := range facetCounts {
+		// skip if no field name
+		if fc.FieldName == nil {
+			continue
+		}
+
+		facet := Facet{
+			Field: *fc.FieldName,
+		}
+		if fc.Stats != nil {
+			facet.Stats = &FacetStats{
+				Avg: fc.Stats.Avg,
+				Max: fc.Stats.Max,
+				Min: fc.Stats.Min,
+				Sum: fc.Stats.Sum,
+			}
+			if fc.Stats.TotalValues != nil {
+				facet.Stats.Count = int64(*fc.Stats.TotalValues)
+			}
+		}
+		if fc.Counts != nil {
+			for _, c := range *fc.Counts {
+				// skip null 'value' for a faceted field
+				if c.Value == nil {
+					continue
+				}
+
+				count := FacetCount{Value: *c.Value}
+				if c.Count != nil {
+					count.Count = int64(*c.Count)
+				}
+				facet.Counts = append(facet.Counts, count)
+			}
+		}
+		facets = append(facets, facet)
+	}
+
+	return facets
 }
@@ -0,0 +1,346 @@
+// Copyright 2022-2023 Tigris Data, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris/query/filter"
+	qsearch "github.com/tigrisdata/tigris/query/search"
+	"github.com/tigrisdata/tigris/schema"
+	tsApi "github.com/tigrisdata/typesense-go/typesense/api"
+)
+
+func TestStoreSchema(t *testing.T) {
+	ptrTrue, ptrFalse, dim := true, false, 4
+	sch := &schema.StoreSchema{
+		Name: "t1",
+		Fields: []schema.StoreField{
+			{Name: "title", Type: "string", Facet: &ptrFalse, Index: &ptrTrue, Sort: &ptrFalse},
+			{Name: "vec", Type: "float[]", Facet: &ptrFalse, Index: &ptrTrue, Sort: &ptrFalse, Dimensions: &dim},
+		},
+		TokenSeparators: []string{"-"},
+	}
+
+	tsSchema := fromStoreSchema(sch)
+	require.Equal(t, "t1", tsSchema.Name)
+	require.Equal(t, &ptrTrue, tsSchema.EnableNestedFields)
+	require.Equal(t, &[]string{"-"}, tsSchema.TokenSeparators)
+	require.Equal(t, []tsApi.Field{
+		{Name: "title", Type: "string", Facet: &ptrFalse, Index: &ptrTrue, Sort: &ptrFalse, Optional: &ptrTrue},
+		{Name: "vec", Type: "float[]", Facet: &ptrFalse, Index: &ptrTrue, Sort: &ptrFalse, Optional: &ptrTrue, NumDim: &dim},
+	}, tsSchema.Fields)
+
+	require.Nil(t, fromStoreSchema(&schema.StoreSchema{Name: "t2", TokenSeparators: []string{}}).TokenSeparators)
+
+	require.Equal(t, sch, toStoreSchema(&tsApi.CollectionResponse{
+		Name:            tsSchema.Name,
+		Fields:          tsSchema.Fields,
+		TokenSeparators: tsSchema.TokenSeparators,
+	}))
+
+	require.Equal(t, []tsApi.Field{{Name: "title", Drop: &ptrTrue}}, fromStoreFields([]schema.StoreField{
+		{Name: "title", Type: "string", Drop: true},
+	}))
+}
+
+func TestToResult(t *testing.T) {
+	var dest tsApi.MultiSearchResult
+	require.NoError(t, jsoniter.Unmarshal([]byte(`{
+	"results": [{
+		"found": 2,
+		"hits": [
+			{"document": {"id": "1"}, "text_match": 100},
+			{"document": {"id": "2"}, "vector_distance": 0.25}
+		]
+	}, {
+		"found": 1,
+		"grouped_hits": [{"group_key": ["a"], "hits": [{"document": {"id": "3"}}]}]
+	}]
+}`), &dest))
+
+	textMatch, distance := int64(100), 0.25
+	require.Equal(t, Result{
+		Found: 2,
+		Hits: []Hit{
+			{Document: map[string]any{"id": "1"}, TextMatch: &textMatch},
+			{Document: map[string]any{"id": "2"}, VectorDistance: &distance},
+		},
+	}, toResult(&dest.Results[0]))
+
+	require.Equal(t, Result{
+		Found:  1,
+		Groups: []Group{{Key: []string{"a"}, Hits: []Hit{{Document: map[string]any{"id": "3"}}}}},
+	}, toResult(&dest.Results[1]))
+
+	require.Equal(t, Result{}, toResult(nil))
+}
+
+func TestDecodeMultiSearch(t *testing.T) {
+	dest, err := decodeMultiSearch([]byte(`{
+	"results": [{
+		"found": 1,
+		"hits": [{"document": {"id": "1", "_tigris_created_at": 1666054267528106123}}]
+	}]
+}`))
+	require.NoError(t, err)
+
+	// as float64 the timestamp in nanoseconds would lose its last digits
+	ts, err := toResult(&dest.Results[0]).Hits[0].Document["_tigris_created_at"].(json.Number).Int64()
+	require.NoError(t, err)
+	require.Equal(t, int64(1666054267528106123), ts)
+
+	_, err = decodeMultiSearch([]byte(`{"results": [{"code": 404, "error": "Not found."}]}`))
+	require.Equal(t, NewSearchError(404, ErrCodeUnhandled, "Not found."), err)
+}
+
+func TestToFacets(t *testing.T) {
+	var dest []tsApi.FacetCounts
+	require.NoError(t, jsoniter.Unmarshal([]byte(`[
+	{"field_name": "a", "counts": [{"count": 20, "value": "value_1"}, {"count": 10, "value": null}]},
+	{"field_name": null, "counts": [{"count": 20, "value": "value_1"}]},
+	{"field_name": "c", "counts": [{"count": 0, "value": "value_1"}], "stats": {"total_values": 3, "max": 453.12, "sum": 0}}
+]`), &dest))
+
+	maxC, sumC := 453.12, float64(0)
+	require.Equal(t, []Facet{
+		{Field: "a", Counts: []FacetCount{{Value: "value_1", Count: 20}}},
+		{Field: "c", Counts: []FacetCount{{Value: "value_1", Count: 0}}, Stats: &FacetStats{Max: &maxC, Sum: &sumC, Count: 3}},
+	}, toFacets(dest))
+}
+
+func TestMatchedFields(t *testing.T) {
+	cases := []struct {
+		resp       []byte
+		expMatched []string
+	}{
+		{
+			[]byte(`{
+"results": [{
+		"hits": [{
+            "document": {},
+			"highlight": {
+				"arr_obj": [{
+					"domain": { "matched_tokens": [], "snippet": "regional24-7.com"},
+					"arr": [{"matched_tokens": [], "snippet": "Daihatsu"}, {"matched_tokens": [],"snippet": "Chrysler"}]
+				}, {
+					"domain": { "matched_tokens": [], "snippet": "internalorchestrate.name"},
+					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}, {"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
+				}, {
+					"domain": {"matched_tokens": [],"snippet": "nationalincubate.net"},
+					"arr": [{"matched_tokens": [],"snippet": "Daewoo"}, {"matched_tokens": [],"snippet": "Cadillac"}]
+				}]
+			}
+		}, {
+            "document": {},
+			"highlight": {
+				"arr_obj": [{
+					"domain": {"matched_tokens": [],"snippet": "internalorchestrate.name"},
+					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}, {"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
+				}, {
+					"domain": {	"matched_tokens": [],"snippet": "globalstrategic.net"},
+					"arr": [{"matched_tokens": [],"snippet": "Skoda"}, {"matched_tokens": [],"snippet": "Volkswagen"}, {"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
+				}]
+			}
+		}]
+	}]
+}`),
+			[]string{"arr_obj.arr", "arr_obj.arr", "arr_obj.arr"},
+		},
+		{
+			[]byte(`{
+	"results": [{
+		"hits": [{
+            "document": {},
+ 			"highlight": {
+ 				"nested.address.city": {
+ 					"matched_tokens": ["Omaha"],
+ 					"snippet": "<mark>Omaha</mark>"
+ 				}
+ 			}
+		}]
+	}]
+}`),
+			[]string{"nested.address.city"},
+		},
+		{
+			[]byte(`{
+	"results": [{
+		"hits": [{
+            "document": {},
+            "highlight": {
+				"arr_obj": [{
+					"domain": {"matched_tokens": ["regional24-7.com"], "snippet": "<mark>regional24-7.com</mark>"},
+					"arr": [{"matched_tokens": [], "snippet": "Chrysler"}]
+				}, {
+					"domain": {"matched_tokens": [],"snippet": "internalorchestrate.name"},
+					"arr": [{"matched_tokens": [],"snippet": "Dino"}]
+				}] 
+			}
+		}]
+	}]
+}`),
+			[]string{"arr_obj.domain"},
+		},
+		{
+			[]byte(`{
+	"results": [{
+		"hits": [{
+            "document": {},
+            "highlight": {
+				"arr_obj": [{
+					"domain": { "matched_tokens": [], "snippet": "regional24-7.com"},
+					"arr": [{"matched_tokens": [], "snippet": "Daihatsu"}]
+				}, {
+					"domain": { "matched_tokens": [], "snippet": "internalorchestrate.name"},
+					"arr": [{"matched_tokens": ["Dino"],"snippet": "<mark>Dino</mark>"}]
+				}],
+				"commands_obj.name": {
+					"matched_tokens": ["dino"],
+					"snippet": "<mark>dino</mark>"
+				},
+				"name": {
+					"matched_tokens": ["dino"],
+					"snippet": "<mark>dino</mark>"
+				}
+			}
+		}]
+	}]
+}`),
+			[]string{"arr_obj.arr", "commands_obj.name", "name"},
+		},
+	}
+	for _, c := range cases {
+		var actualMatched []string
+		var dest tsApi.MultiSearchResult
+		require.NoError(t, jsoniter.Unmarshal(c.resp, &dest))
+		for i := range dest.Results {
+			for _, h := range toResult(&dest.Results[i]).Hits {
+				actualMatched = append(actualMatched, h.MatchedFields...)
+			}
+		}
+
+		sort.Strings(c.expMatched)
+		sort.Strings(actualMatched)
+		require.Equal(t, c.expMatched, actualMatched)
+	}
+}
+
+func TestFromCuration(t *testing.T) {
+	override := fromCuration(&Curation{
+		Id:     "c1",
+		Query:  "apple",
+		Match:  CurationContainsMatch,
+		Pinned: []PinnedDocument{{Id: "1", Position: 1}},
+		Hidden: []string{"2"},
+	})
+	require.Equal(t, "apple", override.Rule.Query)
+	require.Equal(t, tsApi.SearchOverrideRuleMatch("contains"), override.Rule.Match)
+	require.Equal(t, &[]tsApi.SearchOverrideInclude{{Id: "1", Position: 1}}, override.Includes)
+	require.Equal(t, &[]tsApi.SearchOverrideExclude{{Id: "2"}}, override.Excludes)
+
+	override = fromCuration(&Curation{Id: "c2", Query: "apple", Match: CurationExactMatch})
+	require.Nil(t, override.Includes)
+	require.Nil(t, override.Excludes)
+}
+
+func TestToHighlights(t *testing.T) {
+	var hit tsApi.SearchResultHit
+	require.NoError(t, jsoniter.Unmarshal([]byte(`{
+	"document": {},
+	"highlight": {
+		"title": {"matched_tokens": ["Shoe"], "snippet": "red <mark>Shoe</mark>", "value": "a red <mark>Shoe</mark>"},
+		"brand": {"matched_tokens": [], "snippet": "Nike"},
+		"tags": [
+			{"matched_tokens": ["shoe"], "snippet": "<mark>shoe</mark>"},
+			{"matched_tokens": [], "snippet": "red"},
+			{"matched_tokens": ["shoes"], "snippet": "<mark>shoes</mark>"}
+		]
+	}
+}`), &hit))
+
+	require.Equal(t, []Highlight{
+		{Field: "tags", Snippets: []string{"<mark>shoe</mark>", "<mark>shoes</mark>"}, MatchedTokens: []string{"shoe", "shoes"}},
+		{Field: "title", Snippet: "red <mark>Shoe</mark>", Value: "a red <mark>Shoe</mark>", MatchedTokens: []string{"Shoe"}},
+	}, toHit(&hit).Highlights)
+
+	var legacy tsApi.SearchResultHit
+	require.NoError(t, jsoniter.Unmarshal([]byte(`{
+	"document": {},
+	"highlights": [
+		{"field": "tags", "matched_tokens": [["shoe"], ["shoe"]], "snippets": ["<mark>shoe</mark>", "<mark>shoe</mark> box"]}
+	]
+}`), &legacy))
+
+	require.Equal(t, []Highlight{
+		{Field: "tags", Snippets: []string{"<mark>shoe</mark>", "<mark>shoe</mark> box"}, MatchedTokens: []string{"shoe"}},
+	}, toHit(&legacy).Highlights)
+}
+
+func TestSetHighlightParams(t *testing.T) {
+	length := 2
+	var param tsApi.MultiSearchCollectionParameters
+	setHighlightParams(&param, &qsearch.Highlight{
+		Fields:        []string{"title", "tags"},
+		FullFields:    []string{"title"},
+		PreTag:        "<b>",
+		SnippetLength: &length,
+	})
+
+	require.Equal(t, "title,tags", *param.HighlightFields)
+	require.Equal(t, "title", *param.HighlightFullFields)
+	require.Equal(t, "<b>", *param.HighlightStartTag)
+	require.Equal(t, "</mark>", *param.HighlightEndTag)
+	require.Equal(t, 2, *param.HighlightAffixNumTokens)
+	require.Equal(t, 0, *param.SnippetThreshold)
+}
+
+func TestFacetSearches(t *testing.T) {
+	facets, err := qsearch.UnmarshalFacet([]byte(`{
+		"brand": {"size": 5},
+		"price": {"type": "range", "ranges": [{"to": 10}, {"from": 10, "to": 100.5}]},
+		"category": {"exclude_filter": true}
+	}`))
+	require.NoError(t, err)
+	facets.Fields[2].WrappedF = filter.WrappedEmptyFilter
+
+	query := qsearch.NewBuilder().Query("shoe").Filter(filter.WrappedEmptyFilter).Facets(facets).Build()
+	require.Equal(t, "brand", query.ToSearchFacets())
+
+	params, searches := (&storeImpl{}).getFacetSearchParams("products", query)
+	require.Len(t, params, 3)
+	require.Len(t, searches, 3)
+	require.Equal(t, "price:<10", *params[0].FilterBy)
+	require.Equal(t, "price:>=10&&price:<100.5", *params[1].FilterBy)
+	require.Equal(t, 0, *params[1].PerPage)
+	require.Nil(t, params[2].FilterBy)
+	require.Equal(t, "category", *params[2].FacetBy)
+
+	result := Result{Facets: []Facet{{Field: "brand", Counts: []FacetCount{{Value: "nike", Count: 3}}}}}
+	mergeFacets(query, &result, searches, []Result{
+		{Found: 1},
+		{Found: 4},
+		{Facets: []Facet{{Field: "category", Counts: []FacetCount{{Value: "shoes", Count: 5}}}}},
+	})
+	require.Equal(t, []Facet{
+		{Field: "brand", Counts: []FacetCount{{Value: "nike", Count: 3}}},
+		{Field: "price", Counts: []FacetCount{{Value: "*-10", Count: 1}, {Value: "10-100.5", Count: 4}}},
+		{Field: "category", Counts: []FacetCount{{Value: "shoes", Count: 5}}},
+	}, result.Facets)
+}
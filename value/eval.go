@@ -16,6 +16,8 @@ package value
 
 import (
 	"strings"
+
+	"github.com/tigrisdata/tigris/lib/decimal"
 )
 
 func anyToInt(element any) (int64, bool) {
@@ -70,6 +72,12 @@ func AnyCompare(element any, v Value) int {
 		if e, ok := element.(string); ok {
 			return strings.Compare(e, converted.Value)
 		}
+	case *DecimalValue:
+		if e, ok := element.(string); ok {
+			if d, err := decimal.Parse(e); err == nil {
+				return d.Cmp(converted.Value)
+			}
+		}
 	case *BoolValue:
 		if e, ok := element.(bool); ok {
 			ev := BoolValue(e)
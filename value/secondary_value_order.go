@@ -53,7 +53,7 @@ func ToSecondaryOrder(dataType schema.FieldType, val Value) int {
 	switch dataType {
 	case schema.NullType:
 		return 5
-	case schema.DoubleType, schema.Int32Type, schema.Int64Type:
+	case schema.DoubleType, schema.Int32Type, schema.Int64Type, schema.DecimalType:
 		return 10
 	case schema.StringType, schema.UUIDType:
 		return 15
@@ -24,6 +24,7 @@ import (
 	"github.com/buger/jsonparser"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/tigrisdata/tigris/errors"
+	"github.com/tigrisdata/tigris/lib/decimal"
 	"github.com/tigrisdata/tigris/schema"
 )
 
@@ -101,6 +102,9 @@ func NewValue(fieldType schema.FieldType, value []byte) (Value, error) {
 		return NewStringValue(parsed, nil), nil
 	case schema.DateTimeType:
 		return NewDateTimeValue(string(value)), nil
+	case schema.DecimalType:
+		// decimals are stored as strings but numbers are also accepted in the filters
+		return NewDecimalValue(string(value))
 	case schema.ByteType:
 		if decoded, err := base64.StdEncoding.DecodeString(string(value)); err == nil {
 			// when we match the value or build the key we first decode the base64 data
@@ -282,6 +286,50 @@ func (d *DoubleValue) String() string {
 	return d.asString
 }
 
+// DecimalValue is an exact decimal, the comparison is numeric i.e. "1.50" is equal to "1.5".
+type DecimalValue struct {
+	Value decimal.Decimal
+}
+
+func NewDecimalValue(raw string) (*DecimalValue, error) {
+	d, err := decimal.Parse(raw)
+	if err != nil {
+		return nil, errors.InvalidArgument("unsupported value type: '%s' is not a valid decimal", raw)
+	}
+
+	return &DecimalValue{Value: d}, nil
+}
+
+func (d *DecimalValue) CompareTo(v Value) (int, error) {
+	if isNullValue(v) {
+		return 1, nil
+	}
+
+	converted, ok := v.(*DecimalValue)
+	if !ok {
+		return -2, fmt.Errorf("wrong type compared ")
+	}
+
+	return d.Value.Cmp(converted.Value), nil
+}
+
+// AsInterface returns the order preserving encoding of the decimal as it is used in building the index keys.
+func (d *DecimalValue) AsInterface() any {
+	return d.Value.Encode()
+}
+
+func (*DecimalValue) DataType() schema.FieldType {
+	return schema.DecimalType
+}
+
+func (d *DecimalValue) String() string {
+	if d == nil {
+		return ""
+	}
+
+	return d.Value.String()
+}
+
 type StringValue struct {
 	Value     string
 	Collation *Collation
@@ -191,6 +191,41 @@ func TestFloatingPoint(t *testing.T) {
 	}
 }
 
+func TestDecimal(t *testing.T) {
+	cases := []struct {
+		v1  []byte
+		v2  []byte
+		exp int
+	}{
+		{[]byte(`0.1`), []byte(`0.10`), 0},
+		{[]byte(`0.30000000000000001`), []byte(`0.3`), 1},
+		{[]byte(`-12.50`), []byte(`-12.5`), 0},
+		{[]byte(`-12.51`), []byte(`-12.5`), -1},
+		{[]byte(`1e2`), []byte(`99.99`), 1},
+		{[]byte(`1234567890123456789012345678.901234`), []byte(`1234567890123456789012345678.901233`), 1},
+	}
+	for _, c := range cases {
+		v1, err := NewValue(schema.DecimalType, c.v1)
+		require.NoError(t, err)
+
+		v2, err := NewValue(schema.DecimalType, c.v2)
+		require.NoError(t, err)
+
+		r, err := v1.CompareTo(v2)
+		require.NoError(t, err)
+		require.Equal(t, c.exp, r)
+		require.Equal(t, c.exp, AnyCompare(string(c.v1), v2))
+	}
+
+	_, err := NewValue(schema.DecimalType, []byte(`abc`))
+	require.Error(t, err)
+
+	v, err := NewValue(schema.DecimalType, []byte(`12.50`))
+	require.NoError(t, err)
+	require.Equal(t, "12.50", v.String())
+	require.Equal(t, 10, ToSecondaryOrder(schema.DecimalType, v))
+}
+
 func TestStringCollation(t *testing.T) {
 	t.Run("case insensitive", func(t *testing.T) {
 		v1 := NewStringValue("abc", NewCollationFrom(&api.Collation{Case: "ci"}))